@@ -47,6 +47,8 @@ const RepofeedPage = lazy(() => import('./routes/RepofeedPage'));
 const TimelapsePage = lazy(() => import('./routes/TimelapsePage'));
 const TimelapsePlayerPage = lazy(() => import('./routes/TimelapsePlayerPage'));
 const EnvironmentPage = lazy(() => import('./routes/EnvironmentPage'));
+const PipelinesPage = lazy(() => import('./routes/PipelinesPage'));
+const CostsPage = lazy(() => import('./routes/CostsPage'));
 const BranchesPage = lazy(() => import('./routes/BranchesPage'));
 
 export default function App() {
@@ -102,6 +104,8 @@ export default function App() {
                                 <Route path="/config" element={<ConfigPage />} />
                                 <Route path="/logs" element={<LogsPage />} />
                                 <Route path="/environment" element={<EnvironmentPage />} />
+                                <Route path="/pipelines" element={<PipelinesPage />} />
+                                <Route path="/costs" element={<CostsPage />} />
                                 <Route path="/branches" element={<BranchesPage />} />
                                 <Route path="/overlays" element={<OverlayPage />} />
                                 <Route
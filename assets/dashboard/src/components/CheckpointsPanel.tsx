@@ -0,0 +1,131 @@
+import { useCallback, useEffect, useState } from 'react';
+import { createCheckpoint, getCheckpoints, getErrorMessage, restoreCheckpoint } from '../lib/api';
+import type { Checkpoint } from '../lib/types.generated';
+import { formatRelativeTime } from '../lib/utils';
+import { useModal } from './ModalProvider';
+import { useToast } from './ToastProvider';
+import styles from './CheckpointsPanel.module.css';
+
+interface CheckpointsPanelProps {
+  workspaceId: string;
+  /** File selected in the diff view; enables per-file restore. */
+  selectedPath?: string;
+}
+
+/** Timeline of a workspace's automatic and manual checkpoints, with
+ *  whole-workspace and single-file restore. The diff reloads on its own
+ *  once the restore changes the workspace's git stats. */
+export default function CheckpointsPanel({ workspaceId, selectedPath }: CheckpointsPanelProps) {
+  const { confirm } = useModal();
+  const { success: toastSuccess, error: toastError } = useToast();
+  const [open, setOpen] = useState(false);
+  const [checkpoints, setCheckpoints] = useState<Checkpoint[]>([]);
+  const [busy, setBusy] = useState(false);
+
+  const load = useCallback(async () => {
+    try {
+      const resp = await getCheckpoints(workspaceId);
+      setCheckpoints(resp.checkpoints);
+    } catch (err) {
+      toastError(getErrorMessage(err, 'Failed to fetch checkpoints'));
+    }
+  }, [workspaceId, toastError]);
+
+  useEffect(() => {
+    if (open) load();
+  }, [open, load]);
+
+  const handleCreate = async () => {
+    setBusy(true);
+    try {
+      const cp = await createCheckpoint(workspaceId);
+      toastSuccess(`Checkpoint ${cp.seq} saved`);
+      await load();
+    } catch (err) {
+      toastError(getErrorMessage(err, 'Failed to create checkpoint'));
+    } finally {
+      setBusy(false);
+    }
+  };
+
+  const handleRestore = async (cp: Checkpoint, path?: string) => {
+    const target = path || 'the workspace';
+    const confirmed = await confirm(`Restore ${target} to checkpoint ${cp.seq}?`, {
+      danger: true,
+      detailedMessage:
+        'Uncommitted changes are replaced. The current state is saved as a new checkpoint first.',
+    });
+    if (!confirmed) return;
+    setBusy(true);
+    try {
+      const resp = await restoreCheckpoint(workspaceId, cp.seq, path);
+      toastSuccess(
+        `Restored ${target} to checkpoint ${cp.seq}` +
+          (resp.backup ? ` (previous state is checkpoint ${resp.backup.seq})` : '')
+      );
+      await load();
+    } catch (err) {
+      toastError(getErrorMessage(err, 'Failed to restore checkpoint'));
+    } finally {
+      setBusy(false);
+    }
+  };
+
+  return (
+    <div className={styles.panel} data-testid="checkpoints-panel">
+      <div className={styles.header}>
+        <span className={styles.title}>Checkpoints</span>
+        <button
+          className="btn btn--sm btn--ghost btn--bordered"
+          onClick={handleCreate}
+          disabled={busy}
+        >
+          Checkpoint now
+        </button>
+        <button className="btn btn--sm btn--ghost" onClick={() => setOpen(!open)}>
+          {open ? 'Hide' : 'Show'}
+        </button>
+      </div>
+      {open && (
+        <ul className={styles.list}>
+          {checkpoints.length === 0 && (
+            <li className={`${styles.row} ${styles.muted}`}>No checkpoints yet.</li>
+          )}
+          {checkpoints.map((cp) => (
+            <li key={cp.seq} className={styles.row}>
+              <span>#{cp.seq}</span>
+              <span className={styles.muted} title={new Date(cp.created_at).toLocaleString()}>
+                {formatRelativeTime(cp.created_at)}
+              </span>
+              <span>
+                {cp.files_changed} files{' '}
+                <span className="text-success">+{cp.lines_added}</span>{' '}
+                <span className="text-error">-{cp.lines_removed}</span>
+              </span>
+              <span className={styles.muted}>{cp.reason}</span>
+              <span className={styles.actions}>
+                {selectedPath && (
+                  <button
+                    className="btn btn--sm btn--ghost"
+                    onClick={() => handleRestore(cp, selectedPath)}
+                    disabled={busy}
+                    title={`Restore ${selectedPath} only`}
+                  >
+                    Restore file
+                  </button>
+                )}
+                <button
+                  className="btn btn--sm btn--ghost btn--bordered"
+                  onClick={() => handleRestore(cp)}
+                  disabled={busy}
+                >
+                  Restore
+                </button>
+              </span>
+            </li>
+          ))}
+        </ul>
+      )}
+    </div>
+  );
+}
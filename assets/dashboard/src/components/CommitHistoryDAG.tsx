@@ -75,7 +75,8 @@ export default function CommitHistoryDAG({ workspaceId }: CommitHistoryDAGProps)
   const ws = workspaces.find((w) => w.id === workspaceId);
   const lockState = workspaceLockStates[workspaceId];
   const isSyncing = syncing || !!lockState?.locked;
-  const isSapling = ws?.vcs === 'sapling';
+  // Sapling and jj graphs are read-only: no commit, push or uncommit actions.
+  const isReadOnlyVCS = ws?.vcs === 'sapling' || ws?.vcs === 'jj';
   const isLocalRepo = ws?.repo?.startsWith('local:') ?? false;
   const gitFingerprint = ws
     ? `${ws.ahead}:${ws.behind}:${ws.files_changed}:${ws.lines_added}:${ws.lines_removed}`
@@ -475,7 +476,7 @@ export default function CommitHistoryDAG({ workspaceId }: CommitHistoryDAGProps)
       return (
         <div key={ln.hash} className="commit-dag__row" style={{ height: lay.rowHeight }}>
           <span className="commit-dag__you-are-here">You are here</span>
-          {!isSapling &&
+          {!isReadOnlyVCS &&
             showPushToDefault &&
             (pushToDefaultDisabled ? (
               <Tooltip content={pushToDefaultTooltip}>
@@ -484,7 +485,7 @@ export default function CommitHistoryDAG({ workspaceId }: CommitHistoryDAGProps)
             ) : (
               pushToDefaultButton
             ))}
-          {!isSapling &&
+          {!isReadOnlyVCS &&
             showPushToBranch &&
             (pushToBranchDisabled ? (
               <Tooltip content={pushToBranchTooltip}>
@@ -496,8 +497,8 @@ export default function CommitHistoryDAG({ workspaceId }: CommitHistoryDAGProps)
         </div>
       );
     }
-    if (ln.nodeType === 'commit-actions' && isSapling) {
-      // Sapling: show line count summary but no action buttons
+    if (ln.nodeType === 'commit-actions' && isReadOnlyVCS) {
+      // Sapling/jj: show line count summary but no action buttons
       return (
         <div
           key={ln.hash}
@@ -623,8 +624,8 @@ export default function CommitHistoryDAG({ workspaceId }: CommitHistoryDAGProps)
       );
     }
     if (ln.nodeType === 'commit-footer') {
-      // Sapling remote workspaces: commit/amend not supported via tmux control mode
-      if (isSapling) return null;
+      // Sapling/jj: commit/amend not supported
+      if (isReadOnlyVCS) return null;
       const canAmend = (ws?.ahead ?? 0) > 0;
       const commitDisabled = !commitMessageConfigured || selectedFiles.size === 0 || isCommitting;
 
@@ -777,7 +778,7 @@ export default function CommitHistoryDAG({ workspaceId }: CommitHistoryDAGProps)
     const isHeadCommit = ln.node.is_head.includes(ws?.branch || '');
     const canUncommit = isHeadCommit && (ws?.ahead ?? 0) > 0;
     const canPushCommit =
-      !isSapling && !isLocalRepo && localSet.has(ln.node.hash) && !originMainSet.has(ln.node.hash);
+      !isReadOnlyVCS && !isLocalRepo && localSet.has(ln.node.hash) && !originMainSet.has(ln.node.hash);
 
     return (
       <div
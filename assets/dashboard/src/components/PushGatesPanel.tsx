@@ -0,0 +1,63 @@
+import { useState } from 'react';
+import type { PushGateResult, PushGateRun } from '../lib/types.generated';
+import { formatRelativeTime } from '../lib/utils';
+import styles from './PushGatesPanel.module.css';
+
+interface PushGatesPanelProps {
+  run: PushGateRun;
+}
+
+const statusClass: Record<string, string> = {
+  passed: 'text-success',
+  failed: 'text-error',
+  running: 'text-warning',
+};
+
+function gateDetail(g: PushGateResult): string {
+  if (g.error) return g.error;
+  if (g.status === 'failed') return `exit ${g.exit_code ?? 0}`;
+  if (g.duration_ms) return `${(g.duration_ms / 1000).toFixed(1)}s`;
+  return '';
+}
+
+/** The workspace's most recent pre-push gate run. Output streams in over
+ *  the sessions WebSocket while a gate runs; the running or failing gate's
+ *  output is shown by default. */
+export default function PushGatesPanel({ run }: PushGatesPanelProps) {
+  const [expanded, setExpanded] = useState<string | null>(null);
+  const focus = run.gates.find((g) => g.status === 'running' || g.status === 'failed');
+  const shown = expanded ?? focus?.name ?? null;
+
+  return (
+    <div className={styles.panel} data-testid="push-gates-panel">
+      <div className={styles.header}>
+        <span className={styles.title}>Push gates</span>
+        <span className={statusClass[run.status] || 'text-muted'}>
+          {run.status}
+          {run.overridden && ' (overridden)'}
+        </span>
+        <span className="text-muted">
+          {run.head_sha?.slice(0, 7)} · {formatRelativeTime(run.started_at)}
+        </span>
+      </div>
+      <ul className={styles.list}>
+        {run.gates.map((g) => (
+          <li key={g.name}>
+            <button
+              className={styles.row}
+              onClick={() => setExpanded(shown === g.name ? '' : g.name)}
+              disabled={!g.output}
+            >
+              <span>{g.name}</span>
+              <span className={statusClass[g.status] || 'text-muted'}>
+                {g.status === 'running' && <span className="spinner spinner--small" />} {g.status}
+              </span>
+              <span className="text-muted">{gateDetail(g)}</span>
+            </button>
+            {shown === g.name && g.output && <pre className={styles.output}>{g.output}</pre>}
+          </li>
+        ))}
+      </ul>
+    </div>
+  );
+}
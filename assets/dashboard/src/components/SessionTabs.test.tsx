@@ -290,6 +290,36 @@ describe('SessionTabs', () => {
     });
   });
 
+  describe('acceptance preview', () => {
+    it('shows running acceptance checks over the completed nudge', async () => {
+      mockMatchMediaMobile();
+      const sessions = [
+        makeSession('s1', {
+          nudge_state: 'Completed',
+          acceptance: { status: 'running', max_retries: 2 },
+        }),
+      ];
+      await renderTabs(sessions);
+
+      expect(screen.getByText('🧪 Running acceptance checks')).toBeInTheDocument();
+    });
+
+    it('shows the acceptance nudge once the run fails', async () => {
+      mockMatchMediaMobile();
+      const sessions = [
+        makeSession('s1', {
+          nudge_state: 'Error',
+          nudge_summary: 'Acceptance check "tests" failed; sent back to the agent (1 of 2 retries)',
+          acceptance: { status: 'failed', failures: 1, max_retries: 2, failed_check: 'tests' },
+        }),
+      ];
+      await renderTabs(sessions);
+
+      expect(screen.queryByText('🧪 Running acceptance checks')).not.toBeInTheDocument();
+      expect(screen.getByText(/Acceptance check "tests" failed/)).toBeInTheDocument();
+    });
+  });
+
   describe('resolve-conflict tab badges', () => {
     it('shows in-progress spinner from workspace resolve_conflicts state', async () => {
       mockMatchMediaMobile();
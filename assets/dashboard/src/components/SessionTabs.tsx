@@ -539,6 +539,10 @@ export default function SessionTabs({
           ? `${nudgeEmoji} ${nudgeSummary}`
           : `${nudgeEmoji} ${sess.nudge_state}`;
       }
+      // The agent said completed; acceptance decides whether it sticks.
+      if (sess.acceptance?.status === 'running') {
+        nudgePreviewElement = '🧪 Running acceptance checks';
+      }
     }
 
     // Show "Stopped" for stopped sessions, otherwise show last activity time
@@ -208,6 +208,29 @@ export default function ToolsSection({
         </svg>
       ),
     },
+    {
+      to: '/pipelines',
+      label: 'Pipelines',
+      icon: (
+        <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="2">
+          <circle cx="5" cy="6" r="2"></circle>
+          <circle cx="5" cy="18" r="2"></circle>
+          <circle cx="19" cy="12" r="2"></circle>
+          <path d="M7 6h4a2 2 0 0 1 2 2v8a2 2 0 0 1-2 2H7"></path>
+          <path d="M13 12h4"></path>
+        </svg>
+      ),
+    },
+    {
+      to: '/costs',
+      label: 'Costs',
+      icon: (
+        <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="2">
+          <line x1="12" y1="2" x2="12" y2="22"></line>
+          <path d="M17 5H9.5a3.5 3.5 0 0 0 0 7h5a3.5 3.5 0 0 1 0 7H6"></path>
+        </svg>
+      ),
+    },
     {
       to: '/environment',
       label: 'Environment',
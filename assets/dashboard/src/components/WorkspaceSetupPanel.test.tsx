@@ -0,0 +1,50 @@
+import { render, screen } from '@testing-library/react';
+import { describe, it, expect, vi, beforeEach } from 'vitest';
+import type { WorkspaceSetupRun } from '../lib/types.generated';
+
+const mockUseSetupOutput = vi.fn((_workspaceId: string | null) => '');
+vi.mock('../hooks/useSetupOutput', () => ({
+  default: (workspaceId: string | null) => mockUseSetupOutput(workspaceId),
+}));
+import WorkspaceSetupPanel from './WorkspaceSetupPanel';
+
+const startedAt = '2026-10-16T12:00:00Z';
+
+describe('WorkspaceSetupPanel', () => {
+  beforeEach(() => mockUseSetupOutput.mockReset().mockReturnValue(''));
+
+  it('streams live output while setup runs', () => {
+    mockUseSetupOutput.mockReturnValue('==> deps: npm ci\nadded 812 packages\n');
+    const run: WorkspaceSetupRun = {
+      status: 'running',
+      cache_key: 'abc',
+      started_at: startedAt,
+      commands: [
+        { name: 'deps', status: 'running' },
+        { name: 'codegen', status: 'pending' },
+      ],
+    };
+    render(<WorkspaceSetupPanel workspaceId="web-001" run={run} />);
+
+    expect(mockUseSetupOutput).toHaveBeenCalledWith('web-001');
+    expect(screen.getByText(/added 812 packages/)).toBeInTheDocument();
+  });
+
+  it('shows the failing command output once setup has failed', () => {
+    const run: WorkspaceSetupRun = {
+      status: 'failed',
+      cache_key: 'abc',
+      started_at: startedAt,
+      finished_at: startedAt,
+      commands: [
+        { name: 'deps', status: 'failed', exit_code: 1, output: 'ERR! lockfile out of date' },
+        { name: 'codegen', status: 'skipped' },
+      ],
+    };
+    render(<WorkspaceSetupPanel workspaceId="web-001" run={run} />);
+
+    expect(mockUseSetupOutput).toHaveBeenCalledWith(null);
+    expect(screen.getByText('exit 1')).toBeInTheDocument();
+    expect(screen.getByText('ERR! lockfile out of date')).toBeInTheDocument();
+  });
+});
@@ -0,0 +1,65 @@
+import { useState } from 'react';
+import type { WorkspaceSetupResult, WorkspaceSetupRun } from '../lib/types.generated';
+import { formatRelativeTime } from '../lib/utils';
+import useSetupOutput from '../hooks/useSetupOutput';
+import styles from './WorkspaceSetupPanel.module.css';
+
+interface WorkspaceSetupPanelProps {
+  workspaceId: string;
+  run: WorkspaceSetupRun;
+}
+
+const statusClass: Record<string, string> = {
+  passed: 'text-success',
+  failed: 'text-error',
+  running: 'text-warning',
+};
+
+function commandDetail(c: WorkspaceSetupResult): string {
+  if (c.error) return c.error;
+  if (c.status === 'failed') return `exit ${c.exit_code ?? 0}`;
+  if (c.duration_ms) return `${(c.duration_ms / 1000).toFixed(1)}s`;
+  return '';
+}
+
+/** The workspace's most recent setup run. While it runs, the combined
+ *  output streams live from the provisioning WebSocket; afterwards each
+ *  command's stored output is shown, the failing one by default. */
+export default function WorkspaceSetupPanel({ workspaceId, run }: WorkspaceSetupPanelProps) {
+  const running = run.status === 'running';
+  const live = useSetupOutput(running ? workspaceId : null);
+  const [expanded, setExpanded] = useState<string | null>(null);
+  const focus = run.commands.find((c) => c.status === 'failed');
+  const shown = expanded ?? focus?.name ?? null;
+
+  return (
+    <div className={styles.panel} data-testid="workspace-setup-panel">
+      <div className={styles.header}>
+        <span className={styles.title}>Workspace setup</span>
+        <span className={statusClass[run.status] || 'text-muted'}>{run.status}</span>
+        <span className="text-muted">{formatRelativeTime(run.started_at)}</span>
+      </div>
+      <ul className={styles.list}>
+        {run.commands.map((c) => (
+          <li key={c.name}>
+            <button
+              className={styles.row}
+              onClick={() => setExpanded(shown === c.name ? '' : c.name)}
+              disabled={running || !c.output}
+            >
+              <span>{c.name}</span>
+              <span className={statusClass[c.status] || 'text-muted'}>
+                {c.status === 'running' && <span className="spinner spinner--small" />} {c.status}
+              </span>
+              <span className="text-muted">{commandDetail(c)}</span>
+            </button>
+            {!running && shown === c.name && c.output && (
+              <pre className={styles.output}>{c.output}</pre>
+            )}
+          </li>
+        ))}
+      </ul>
+      {running && live && <pre className={styles.output}>{live}</pre>}
+    </div>
+  );
+}
@@ -0,0 +1,40 @@
+import { useEffect, useState } from 'react';
+import { transport } from '../lib/transport';
+
+// Keep about as much as the server's setup stream does.
+const SETUP_OUTPUT_LIMIT = 256 * 1024;
+
+// useSetupOutput tails a workspace's running setup commands over
+// /ws/provision/setup-{workspaceId}: the output so far, then live, as one
+// string. Messages are binary and may split a UTF-8 sequence, so they go
+// through a streaming decoder. Pass null to stay disconnected (setup not
+// running). The server closes the socket when setup finishes; the stored run
+// then has each command's output.
+export default function useSetupOutput(workspaceId: string | null): string {
+  const [output, setOutput] = useState('');
+
+  useEffect(() => {
+    setOutput('');
+    if (!workspaceId) return;
+    const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+    const ws = transport.createWebSocket(
+      `${protocol}//${window.location.host}/ws/provision/setup-${workspaceId}`
+    );
+    ws.binaryType = 'arraybuffer';
+    const decoder = new TextDecoder();
+    let active = true;
+
+    ws.onmessage = (event) => {
+      if (!active || !(event.data instanceof ArrayBuffer)) return;
+      const text = decoder.decode(event.data, { stream: true });
+      setOutput((prev) => (prev + text).slice(-SETUP_OUTPUT_LIMIT));
+    };
+
+    return () => {
+      active = false;
+      ws.close();
+    };
+  }, [workspaceId]);
+
+  return output;
+}
@@ -221,6 +221,65 @@ describe('handlePushToBranch', () => {
   });
 });
 
+describe('push gates', () => {
+  const failedRun = {
+    trigger: 'push_to_branch',
+    status: 'failed',
+    started_at: '2026-01-01T00:00:00Z',
+    gates: [{ name: 'tests', status: 'failed', exit_code: 1, output: 'FAIL: TestFoo' }],
+  };
+
+  it('shows the failing gate output and pushes anyway when overridden', async () => {
+    pushToBranch
+      .mockResolvedValueOnce({
+        success: false,
+        message: 'push blocked: gate "tests" failed (exit 1)',
+        push_gates: failedRun,
+      })
+      .mockResolvedValueOnce({ success: true });
+    show.mockResolvedValue(true);
+    renderSync();
+
+    await sync.handlePushToBranch('ws-1', 'feature/foo');
+
+    expect(show).toHaveBeenCalledWith(
+      'Push gates failed',
+      expect.stringContaining('"tests"'),
+      expect.objectContaining({ confirmText: 'Push anyway', detailedMessage: 'FAIL: TestFoo' })
+    );
+    expect(pushToBranch).toHaveBeenLastCalledWith('ws-1', { override_gates: true });
+    expect(toastSuccess).toHaveBeenCalledWith('Pushed to origin/feature/foo');
+  });
+
+  it('does not push when the override is declined', async () => {
+    pushToBranch.mockResolvedValue({ success: false, push_gates: failedRun });
+    show.mockResolvedValue(false);
+    renderSync();
+
+    await sync.handlePushToBranch('ws-1', 'feature/foo');
+
+    expect(pushToBranch).toHaveBeenCalledTimes(1);
+    expect(alert).not.toHaveBeenCalled();
+  });
+
+  it('carries the override through the force-push confirmation for push-commits', async () => {
+    pushCommits
+      .mockResolvedValueOnce({ success: false, reason: 'gates_failed', push_gates: failedRun })
+      .mockResolvedValueOnce({ success: false, needs_confirm: true, diverged_commits: [] })
+      .mockResolvedValueOnce(successResult());
+    show.mockResolvedValue(true);
+    renderSync();
+
+    await sync.handlePushCommits('ws-1', { ...baseOpts, headCommit: false });
+
+    expect(pushCommits).toHaveBeenCalledTimes(3);
+    expect(pushCommits).toHaveBeenLastCalledWith(
+      'ws-1',
+      expect.objectContaining({ confirm: true, override_gates: true })
+    );
+  });
+});
+
 // Build a context literal for tests. `over` lets each case tweak one field.
 const ctx = (over: Record<string, unknown> = {}) => ({
   workspaceId: 'ws-1',
@@ -17,6 +17,7 @@ import { useToast } from '../components/ToastProvider';
 import { useSyncState } from '../contexts/SyncContext';
 import { usePendingNavigation } from '../lib/navigation';
 import type { WorkspaceResponse } from '../lib/types';
+import type { PushGateRun } from '../lib/types.generated';
 
 /** Everything the post-push cleanup prompt needs about a workspace. */
 export interface DisposeSuggestionContext {
@@ -182,10 +183,31 @@ export function useSync() {
     [confirm, confirmWithCheckbox, navigate, toastSuccess]
   );
 
+  // A push blocked by a failing push gate: show the gate's output and let
+  // the user push anyway. Resolves true when they chose to override.
+  const confirmGateOverride = useCallback(
+    async (run: PushGateRun | undefined, message?: string): Promise<boolean> => {
+      const failed = run?.gates.find((g) => g.status === 'failed');
+      const confirmed = await show('Push gates failed', message || 'A push gate failed.', {
+        confirmText: 'Push anyway',
+        cancelText: 'Cancel',
+        danger: true,
+        detailedMessage: failed?.error || failed?.output || '',
+        wide: true,
+      });
+      return confirmed ?? false;
+    },
+    [show]
+  );
+
   const handleLinearSyncToMain = useCallback(
     async (ctx: DisposeSuggestionContext): Promise<void> => {
       try {
-        const result = await linearSyncToMain(ctx.workspaceId);
+        let result = await linearSyncToMain(ctx.workspaceId);
+        if (!result.success && result.push_gates?.status === 'failed') {
+          if (!(await confirmGateOverride(result.push_gates, result.message))) return;
+          result = await linearSyncToMain(ctx.workspaceId, { override_gates: true });
+        }
         if (result.success) {
           const branch = ctx.defaultBranch || result.branch || 'main';
           const count = result.success_count ?? 0;
@@ -200,13 +222,17 @@ export function useSync() {
         await alert('Error', getErrorMessage(err, 'Failed to sync or dispose'));
       }
     },
-    [alert, suggestDisposeAfterPush]
+    [alert, confirmGateOverride, suggestDisposeAfterPush]
   );
 
   const handlePushToBranch = useCallback(
     async (workspaceId: string, branchName?: string): Promise<void> => {
       try {
-        const result = await pushToBranch(workspaceId);
+        let result = await pushToBranch(workspaceId);
+        if (!result.success && result.push_gates?.status === 'failed') {
+          if (!(await confirmGateOverride(result.push_gates, result.message))) return;
+          result = await pushToBranch(workspaceId, { override_gates: true });
+        }
         if (result.success) {
           const branch = branchName || 'current branch';
           toastSuccess(`Pushed to origin/${branch}`);
@@ -227,7 +253,7 @@ export function useSync() {
         await alert('Error', getErrorMessage(err, 'Failed to push to branch'));
       }
     },
-    [alert, toastSuccess]
+    [alert, confirmGateOverride, toastSuccess]
   );
 
   const handlePushCommits = useCallback(
@@ -246,6 +272,7 @@ export function useSync() {
     ): Promise<boolean> => {
       const { hash, target, perCommit, targetBranchName } = opts;
       try {
+        let overrideGates = false;
         let result = await pushCommits(workspaceId, {
           hash,
           target,
@@ -253,6 +280,18 @@ export function useSync() {
           confirm: false,
         });
 
+        if (result.reason === 'gates_failed') {
+          if (!(await confirmGateOverride(result.push_gates, result.message))) return false;
+          overrideGates = true;
+          result = await pushCommits(workspaceId, {
+            hash,
+            target,
+            per_commit: perCommit,
+            confirm: false,
+            override_gates: true,
+          });
+        }
+
         if (result.needs_confirm) {
           const confirmed = await show(
             'Force push required',
@@ -270,6 +309,7 @@ export function useSync() {
             target,
             per_commit: perCommit,
             confirm: true,
+            override_gates: overrideGates,
           });
         }
 
@@ -310,7 +350,7 @@ export function useSync() {
         return false;
       }
     },
-    [alert, show, toastSuccess, suggestDisposeAfterPush]
+    [alert, show, confirmGateOverride, toastSuccess, suggestDisposeAfterPush]
   );
 
   // Smart sync: chooses clean or conflict resolution based on workspace state
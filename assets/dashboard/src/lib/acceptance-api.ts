@@ -0,0 +1,22 @@
+import { csrfHeaders } from './csrf';
+import { parseErrorResponse } from './api';
+import type { SessionAcceptance } from './types.generated';
+
+function acceptanceURL(sessionID: string): string {
+  return `/api/sessions/${encodeURIComponent(sessionID)}/acceptance`;
+}
+
+export async function getSessionAcceptance(sessionID: string): Promise<SessionAcceptance> {
+  const response = await fetch(acceptanceURL(sessionID));
+  if (!response.ok) await parseErrorResponse(response, 'Failed to fetch acceptance checks');
+  return response.json();
+}
+
+export async function runSessionAcceptance(sessionID: string): Promise<SessionAcceptance> {
+  const response = await fetch(`${acceptanceURL(sessionID)}/run`, {
+    method: 'POST',
+    headers: { ...csrfHeaders() },
+  });
+  if (!response.ok) await parseErrorResponse(response, 'Failed to run acceptance checks');
+  return response.json();
+}
@@ -46,6 +46,9 @@ import type {
   Model,
 } from './types';
 import type {
+  Checkpoint,
+  CheckpointRestoreResponse,
+  CheckpointsResponse,
   CreateSpawnEntryRequest,
   DependenciesResponse,
   Persona,
@@ -717,10 +720,14 @@ export async function linearSyncFromMain(
   return response.json();
 }
 
-export async function linearSyncToMain(workspaceId: string): Promise<LinearSyncResponse> {
+export async function linearSyncToMain(
+  workspaceId: string,
+  opts?: { override_gates?: boolean }
+): Promise<LinearSyncResponse> {
   const response = await apiFetch(`/api/workspaces/${workspaceId}/linear-sync-to-main`, {
     method: 'POST',
     headers: { 'Content-Type': 'application/json', ...csrfHeaders() },
+    body: JSON.stringify(opts ?? {}),
   });
   if (!response.ok) {
     await parseErrorResponse(response, 'Failed to sync to main');
@@ -738,7 +745,12 @@ export async function getBranchDivergence(workspaceId: string): Promise<BranchDi
 
 export async function pushToBranch(
   workspaceId: string,
-  opts?: { confirm?: boolean; expected_local?: string; expected_remote?: string }
+  opts?: {
+    confirm?: boolean;
+    expected_local?: string;
+    expected_remote?: string;
+    override_gates?: boolean;
+  }
 ): Promise<LinearSyncResponse> {
   const response = await apiFetch(`/api/workspaces/${workspaceId}/push-to-branch`, {
     method: 'POST',
@@ -753,7 +765,13 @@ export async function pushToBranch(
 
 export async function pushCommits(
   workspaceId: string,
-  req: { hash: string; target: 'default' | 'branch'; per_commit: boolean; confirm: boolean }
+  req: {
+    hash: string;
+    target: 'default' | 'branch';
+    per_commit: boolean;
+    confirm: boolean;
+    override_gates?: boolean;
+  }
 ): Promise<PushCommitsResult> {
   const response = await apiFetch(
     `/api/workspaces/${encodeURIComponent(workspaceId)}/push-commits`,
@@ -1063,6 +1081,41 @@ export async function commitUncommit(
   return response.json();
 }
 
+export async function getCheckpoints(workspaceId: string): Promise<CheckpointsResponse> {
+  const response = await apiFetch(`/api/workspaces/${encodeURIComponent(workspaceId)}/checkpoints`);
+  if (!response.ok) await parseErrorResponse(response, 'Failed to fetch checkpoints');
+  return response.json();
+}
+
+export async function createCheckpoint(workspaceId: string): Promise<Checkpoint> {
+  const response = await apiFetch(
+    `/api/workspaces/${encodeURIComponent(workspaceId)}/checkpoints`,
+    {
+      method: 'POST',
+      headers: { ...csrfHeaders() },
+    }
+  );
+  if (!response.ok) await parseErrorResponse(response, 'Failed to create checkpoint');
+  return response.json();
+}
+
+export async function restoreCheckpoint(
+  workspaceId: string,
+  seq: number,
+  path?: string
+): Promise<CheckpointRestoreResponse> {
+  const response = await apiFetch(
+    `/api/workspaces/${encodeURIComponent(workspaceId)}/checkpoints/${seq}/restore`,
+    {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', ...csrfHeaders() },
+      body: JSON.stringify(path ? { path } : {}),
+    }
+  );
+  if (!response.ok) await parseErrorResponse(response, 'Failed to restore checkpoint');
+  return response.json();
+}
+
 interface CommitPromptResponse {
   prompt: string;
 }
@@ -1690,6 +1743,12 @@ export interface TimelapseRecording {
   InProgress: boolean;
   HasCompressed: boolean;
   Path: string;
+  Chapters: TimelapseChapter[];
+}
+
+export interface TimelapseChapter {
+  Time: number;
+  Label: string;
 }
 
 export async function getTimelapseRecordings(): Promise<TimelapseRecording[]> {
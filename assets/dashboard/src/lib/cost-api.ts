@@ -0,0 +1,11 @@
+import { parseErrorResponse } from './api';
+import type { CostsResponse } from './types.generated';
+
+export type CostGroupBy = 'day' | 'session' | 'workspace' | 'repo' | 'target' | 'feature' | 'model';
+
+export async function getCosts(groupBy: CostGroupBy, days: number): Promise<CostsResponse> {
+  const params = new URLSearchParams({ group_by: groupBy, days: String(days) });
+  const response = await fetch(`/api/costs?${params}`);
+  if (!response.ok) await parseErrorResponse(response, 'Failed to fetch costs');
+  return response.json();
+}
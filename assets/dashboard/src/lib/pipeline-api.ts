@@ -0,0 +1,82 @@
+import { csrfHeaders } from './csrf';
+import { parseErrorResponse } from './api';
+import type {
+  Pipeline,
+  PipelineRun,
+  PipelineRunsResponse,
+  PipelinesResponse,
+} from './types.generated';
+
+function pipelinesURL(repo: string): string {
+  return `/api/spawn/${encodeURIComponent(repo)}/pipelines`;
+}
+
+export async function getPipelines(repo: string): Promise<Pipeline[]> {
+  const response = await fetch(pipelinesURL(repo));
+  if (!response.ok) await parseErrorResponse(response, 'Failed to fetch pipelines');
+  const data: PipelinesResponse = await response.json();
+  return data.pipelines;
+}
+
+export async function createPipeline(repo: string, pipeline: Pipeline): Promise<Pipeline> {
+  const response = await fetch(pipelinesURL(repo), {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json', ...csrfHeaders() },
+    body: JSON.stringify(pipeline),
+  });
+  if (!response.ok) await parseErrorResponse(response, 'Failed to create pipeline');
+  return response.json();
+}
+
+export async function updatePipeline(
+  repo: string,
+  id: string,
+  pipeline: Pipeline
+): Promise<Pipeline> {
+  const response = await fetch(`${pipelinesURL(repo)}/${encodeURIComponent(id)}`, {
+    method: 'PUT',
+    headers: { 'Content-Type': 'application/json', ...csrfHeaders() },
+    body: JSON.stringify(pipeline),
+  });
+  if (!response.ok) await parseErrorResponse(response, 'Failed to update pipeline');
+  return response.json();
+}
+
+export async function deletePipeline(repo: string, id: string): Promise<void> {
+  const response = await fetch(`${pipelinesURL(repo)}/${encodeURIComponent(id)}`, {
+    method: 'DELETE',
+    headers: { ...csrfHeaders() },
+  });
+  if (!response.ok) await parseErrorResponse(response, 'Failed to delete pipeline');
+}
+
+export async function runPipeline(repo: string, id: string): Promise<PipelineRun> {
+  const response = await fetch(`${pipelinesURL(repo)}/${encodeURIComponent(id)}/run`, {
+    method: 'POST',
+    headers: { ...csrfHeaders() },
+  });
+  if (!response.ok) await parseErrorResponse(response, 'Failed to start pipeline');
+  return response.json();
+}
+
+export async function getPipelineRuns(): Promise<PipelineRun[]> {
+  const response = await fetch('/api/pipeline-runs');
+  if (!response.ok) await parseErrorResponse(response, 'Failed to fetch pipeline runs');
+  const data: PipelineRunsResponse = await response.json();
+  return data.runs;
+}
+
+export async function getPipelineRun(runID: string): Promise<PipelineRun> {
+  const response = await fetch(`/api/pipeline-runs/${encodeURIComponent(runID)}`);
+  if (!response.ok) await parseErrorResponse(response, 'Failed to fetch pipeline run');
+  return response.json();
+}
+
+export async function cancelPipelineRun(runID: string): Promise<PipelineRun> {
+  const response = await fetch(`/api/pipeline-runs/${encodeURIComponent(runID)}/cancel`, {
+    method: 'POST',
+    headers: { ...csrfHeaders() },
+  });
+  if (!response.ok) await parseErrorResponse(response, 'Failed to cancel pipeline run');
+  return response.json();
+}
@@ -0,0 +1,48 @@
+import { csrfHeaders } from './csrf';
+import { parseErrorResponse } from './api';
+import type { Tournament, TournamentsResponse } from './types.generated';
+
+function tournamentURL(id: string): string {
+  return `/api/tournaments/${encodeURIComponent(id)}`;
+}
+
+export async function getTournaments(): Promise<Tournament[]> {
+  const response = await fetch('/api/tournaments');
+  if (!response.ok) await parseErrorResponse(response, 'Failed to fetch tournaments');
+  const data: TournamentsResponse = await response.json();
+  return data.tournaments;
+}
+
+export async function getTournament(id: string): Promise<Tournament> {
+  const response = await fetch(tournamentURL(id));
+  if (!response.ok) await parseErrorResponse(response, 'Failed to fetch tournament');
+  return response.json();
+}
+
+export async function compareTournament(id: string): Promise<Tournament> {
+  const response = await fetch(`${tournamentURL(id)}/compare`, {
+    method: 'POST',
+    headers: { ...csrfHeaders() },
+  });
+  if (!response.ok) await parseErrorResponse(response, 'Failed to compare tournament');
+  return response.json();
+}
+
+export async function promoteTournament(id: string, sessionID?: string): Promise<Tournament> {
+  const response = await fetch(`${tournamentURL(id)}/promote`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json', ...csrfHeaders() },
+    body: JSON.stringify({ session_id: sessionID }),
+  });
+  if (!response.ok) await parseErrorResponse(response, 'Failed to promote candidate');
+  return response.json();
+}
+
+export async function cancelTournament(id: string): Promise<Tournament> {
+  const response = await fetch(`${tournamentURL(id)}/cancel`, {
+    method: 'POST',
+    headers: { ...csrfHeaders() },
+  });
+  if (!response.ok) await parseErrorResponse(response, 'Failed to cancel tournament');
+  return response.json();
+}
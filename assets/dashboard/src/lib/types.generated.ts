@@ -1,5 +1,36 @@
 // Code generated by cmd/gen-types; DO NOT EDIT.
 
+export interface APITokenInfo {
+  id: string;
+  name: string;
+  scopes: string[];
+  created_at: string;
+}
+
+export interface APITokensResponse {
+  tokens: APITokenInfo[];
+}
+
+export interface AcceptanceCheckResult {
+  name: string;
+  status: string;
+  exit_code?: number;
+  duration_ms?: number;
+  output?: string;
+  error?: string;
+}
+
+export interface AcceptanceCommand {
+  name: string;
+  command: string[];
+  timeout_ms?: number;
+}
+
+export interface AcceptanceOptions {
+  commands: AcceptanceCommand[];
+  max_retries?: number;
+}
+
 export interface AccessControl {
   enabled: boolean;
   provider: string;
@@ -12,6 +43,38 @@ export interface AccessControlUpdate {
   session_ttl_minutes?: number;
 }
 
+export interface AgentMessage {
+  id: string;
+  from: string;
+  from_name: string;
+  to: string;
+  recipient?: string;
+  text: string;
+  status: string;
+  error?: string;
+  created_at: string;
+}
+
+export interface AgentMessagesResponse {
+  messages: AgentMessage[];
+}
+
+export interface AutoCleanupCandidate {
+  workspace_id: string;
+  repo: string;
+  branch: string;
+  reason: string;
+  eligible_since?: string;
+  cleanup_at?: string;
+}
+
+export interface AutoCleanupReport {
+  enabled: boolean;
+  dry_run: boolean;
+  grace_hours: number;
+  candidates: AutoCleanupCandidate[];
+}
+
 export interface BranchDivergenceResponse {
   branch: string;
   local_head: string;
@@ -43,6 +106,32 @@ export interface BuildMonitorRepoConfig {
   github_login: string;
 }
 
+export interface Checkpoint {
+  seq: number;
+  ref: string;
+  commit: string;
+  base?: string;
+  reason: string;
+  created_at: string;
+  files_changed: number;
+  lines_added: number;
+  lines_removed: number;
+}
+
+export interface CheckpointRestoreRequest {
+  path?: string;
+}
+
+export interface CheckpointRestoreResponse {
+  restored?: Checkpoint;
+  backup?: Checkpoint;
+  path?: string;
+}
+
+export interface CheckpointsResponse {
+  checkpoints: Checkpoint[];
+}
+
 export interface ClipboardAckRequest {
   action: string;
   requestId: string;
@@ -238,6 +327,50 @@ export interface ConflictResolveUpdate {
   timeout_ms?: number;
 }
 
+export interface CostBudgetStatus {
+  session_usd: number;
+  daily_usd: number;
+  warn_percent: number;
+  today: string;
+  today_usd: number;
+  daily_state: string;
+  sessions?: CostSessionBudget[];
+}
+
+export interface CostRow {
+  key: string;
+  input_tokens: number;
+  output_tokens: number;
+  cache_read_tokens: number;
+  cache_write_tokens: number;
+  cost_usd: number;
+  unpriced?: boolean;
+}
+
+export interface CostSessionBudget {
+  session_id: string;
+  cost_usd: number;
+  state: string;
+}
+
+export interface CostsResponse {
+  group_by: string;
+  since: string;
+  rows: CostRow[];
+  total: CostRow;
+  budget: CostBudgetStatus;
+}
+
+export interface CreateAPITokenRequest {
+  name: string;
+  scopes: string[];
+}
+
+export interface CreateAPITokenResponse {
+  token: string;
+  info: APITokenInfo;
+}
+
 export interface CreateSpawnEntryRequest {
   name: string;
   type: string;
@@ -319,6 +452,14 @@ export interface DiffResponse {
   files: DiffFileSummary[];
 }
 
+export interface DiskUsage {
+  total_bytes: number;
+  quota_bytes: number;
+  recordings_bytes: number;
+  workspaces: WorkspaceDiskUsage[];
+  repo_bases: RepoBaseDiskUsage[];
+}
+
 export interface DisposeWorkspaceAllRequest {
   delete_remote_branch?: boolean;
 }
@@ -534,6 +675,10 @@ export interface OneshotLogRecord {
   elapsed_ms?: number;
   ok: boolean;
   error?: string;
+  input_tokens?: number;
+  output_tokens?: number;
+  cache_read_tokens?: number;
+  cache_write_tokens?: number;
 }
 
 export interface OneshotTarget {
@@ -579,6 +724,58 @@ export interface PersonaUpdateRequest {
   expectations?: string;
 }
 
+export interface Pipeline {
+  id: string;
+  name: string;
+  repo?: string;
+  branch?: string;
+  stages: PipelineStage[];
+  created_at: string;
+  updated_at: string;
+}
+
+export interface PipelineRun {
+  id: string;
+  pipeline_id: string;
+  name: string;
+  repo: string;
+  status: string;
+  stages: PipelineStageRun[];
+  pipeline: Pipeline;
+  created_at: string;
+  finished_at?: string;
+}
+
+export interface PipelineRunsResponse {
+  runs: PipelineRun[];
+}
+
+export interface PipelineStage {
+  id: string;
+  name?: string;
+  spawn: SpawnRequest;
+  after?: string[];
+  succeed_on?: string[];
+  on_failure?: string;
+  reuse_workspace?: boolean;
+}
+
+export interface PipelineStageRun {
+  stage_id: string;
+  name?: string;
+  status: string;
+  session_ids?: string[];
+  workspace_id?: string;
+  done?: string[];
+  error?: string;
+  started_at?: string;
+  finished_at?: string;
+}
+
+export interface PipelinesResponse {
+  pipelines: Pipeline[];
+}
+
 export interface PrReview {
   target: string;
 }
@@ -636,6 +833,26 @@ export interface PushCommitsResult {
   message?: string;
   needs_confirm?: boolean;
   diverged_commits?: string[];
+  push_gates?: PushGateRun;
+}
+
+export interface PushGateResult {
+  name: string;
+  status: string;
+  exit_code?: number;
+  duration_ms?: number;
+  output?: string;
+  error?: string;
+}
+
+export interface PushGateRun {
+  trigger: string;
+  status: string;
+  head_sha?: string;
+  started_at: string;
+  finished_at?: string;
+  overridden?: boolean;
+  gates: PushGateResult[];
 }
 
 export interface QuickLaunch {
@@ -754,6 +971,13 @@ export interface Repo {
   vcs?: string;
 }
 
+export interface RepoBaseDiskUsage {
+  repo: string;
+  path: string;
+  bytes: number;
+  measured_at?: string;
+}
+
 export interface RepoConfig {
   quick_launch?: QuickLaunch[];
   fence?: RepoFence;
@@ -851,12 +1075,60 @@ export interface SaplingCommandsUpdate {
   list_workspaces?: string[];
 }
 
+export interface SearchResponse {
+  results: SearchResult[];
+  truncated: boolean;
+}
+
+export interface SearchResult {
+  session_id: string;
+  nickname?: string;
+  workspace_id: string;
+  repo?: string;
+  target: string;
+  kind: string;
+  seq?: number;
+  event_type?: string;
+  time: string;
+  text: string;
+}
+
+export interface SessionAcceptance {
+  session_id: string;
+  workspace_id?: string;
+  options?: AcceptanceOptions;
+  status: string;
+  failures?: number;
+  max_retries: number;
+  checks?: AcceptanceCheckResult[];
+  started_at?: string;
+  finished_at?: string;
+}
+
+export interface SessionAcceptanceInfo {
+  status: string;
+  failures?: number;
+  max_retries: number;
+  failed_check?: string;
+}
+
 export interface SessionModelInfo {
   context_window?: number;
   cost_input_per_mtok?: number;
   cost_output_per_mtok?: number;
 }
 
+export interface SessionPipelineInfo {
+  run_id: string;
+  pipeline_name: string;
+  stage_id: string;
+  stage_name?: string;
+  stage_index: number;
+  stage_count: number;
+  run_status: string;
+  stage_status: string;
+}
+
 export interface SessionResponseItem {
   id: string;
   target: string;
@@ -871,6 +1143,7 @@ export interface SessionResponseItem {
   attach_cmd: string;
   tmux_socket?: string;
   tmux_session?: string;
+  backend?: string;
   nudge_state?: string;
   nudge_summary?: string;
   nudge_seq?: number;
@@ -886,6 +1159,20 @@ export interface SessionResponseItem {
   style_id?: string;
   fence?: boolean;
   resume_id?: string;
+  pipeline?: SessionPipelineInfo;
+  tournament?: SessionTournamentInfo;
+  acceptance?: SessionAcceptanceInfo;
+  queue_position?: number;
+  queue_eta?: string;
+  cost_usd?: number;
+}
+
+export interface SessionTournamentInfo {
+  tournament_id: string;
+  status: string;
+  rank?: number;
+  candidates: number;
+  winner?: boolean;
 }
 
 export interface Sessions {
@@ -978,6 +1265,10 @@ export interface SpawnRequest {
   image_attachments?: string[];
   intent_shared?: boolean;
   fence?: boolean;
+  priority?: number;
+  backend?: string;
+  tournament?: TournamentOptions;
+  acceptance?: AcceptanceOptions;
 }
 
 export interface Style {
@@ -1079,6 +1370,73 @@ export interface TimelapseUpdate {
   max_total_storage_mb?: number;
 }
 
+export interface Tournament {
+  id: string;
+  repo: string;
+  branch?: string;
+  prompt: string;
+  options: TournamentOptions;
+  status: string;
+  candidates: TournamentCandidate[];
+  judgement?: TournamentJudgement;
+  winner_session_id?: string;
+  created_at: string;
+  compared_at?: string;
+  finished_at?: string;
+}
+
+export interface TournamentCandidate {
+  session_id: string;
+  workspace_id: string;
+  target: string;
+  nickname?: string;
+  branch?: string;
+  state: string;
+  files?: string[];
+  lines_added: number;
+  lines_removed: number;
+  test?: TournamentTestResult;
+  rank?: number;
+  rationale?: string;
+  error?: string;
+}
+
+export interface TournamentJudgement {
+  target: string;
+  winner_session_id?: string;
+  summary?: string;
+  error?: string;
+}
+
+export interface TournamentOptions {
+  test_command?: string[];
+  judge_target?: string;
+  criteria?: string;
+}
+
+export interface TournamentPromoteRequest {
+  session_id?: string;
+}
+
+export interface TournamentTestResult {
+  passed: boolean;
+  exit_code: number;
+  duration_ms: number;
+  output?: string;
+}
+
+export interface TournamentsResponse {
+  tournaments: Tournament[];
+}
+
+export interface TriggerSpawnRequest {
+  repo: string;
+  branch?: string;
+  quick_launch: string;
+  variables?: Record<string, string>;
+  nickname?: string;
+}
+
 export interface UpdateSpawnEntryRequest {
   name?: string;
   command?: string;
@@ -1086,6 +1444,47 @@ export interface UpdateSpawnEntryRequest {
   target?: string;
 }
 
+export interface WebhookDeliveriesResponse {
+  deliveries: WebhookDelivery[];
+}
+
+export interface WebhookDelivery {
+  id: string;
+  endpoint: string;
+  event_id: string;
+  event_type: string;
+  status: string;
+  attempts: number;
+  status_code?: number;
+  error?: string;
+  created_at: string;
+  finished_at?: string;
+}
+
+export interface WebhookEndpointInfo {
+  name: string;
+  url: string;
+  events?: string[];
+  repos?: string[];
+  states?: string[];
+  disabled?: boolean;
+  has_secret: boolean;
+}
+
+export interface WebhooksResponse {
+  endpoints: WebhookEndpointInfo[];
+}
+
+export interface WorkspaceDiskUsage {
+  workspace_id: string;
+  repo: string;
+  branch: string;
+  status: string;
+  bytes: number;
+  measured_at?: string;
+  last_used_at?: string;
+}
+
 export interface WorkspaceResponseItem {
   id: string;
   repo: string;
@@ -1122,11 +1521,31 @@ export interface WorkspaceResponseItem {
   previews?: PreviewResponse[];
   tabs: Tab[];
   resolve_conflicts?: ResolveConflict[];
+  push_gates?: PushGateRun;
+  setup?: WorkspaceSetupRun;
   status?: string;
   backburner?: boolean;
+  protected?: boolean;
   intent_shared?: boolean;
 }
 
+export interface WorkspaceSetupResult {
+  name: string;
+  status: string;
+  exit_code?: number;
+  duration_ms?: number;
+  output?: string;
+  error?: string;
+}
+
+export interface WorkspaceSetupRun {
+  status: string;
+  cache_key: string;
+  started_at: string;
+  finished_at?: string;
+  commands: WorkspaceSetupResult[];
+}
+
 export interface Xterm {
   query_timeout_ms: number;
   operation_timeout_ms: number;
@@ -29,6 +29,15 @@ export interface SessionResponse {
   fence?: boolean;
   // Harness-native conversation id; when present, the session can be restarted
   resume_id?: string;
+  // Set when the session was spawned by a pipeline stage
+  pipeline?: SessionPipelineInfo;
+  // Set when the session is a candidate in a best-of-N tournament
+  tournament?: SessionTournamentInfo;
+  // Set once acceptance commands apply to the session
+  acceptance?: SessionAcceptanceInfo;
+  // Set while status is "queued" behind concurrency limits
+  queue_position?: number;
+  queue_eta?: string; // RFC3339 estimated start time
 }
 
 export interface WorkspaceResponse {
@@ -67,6 +76,8 @@ export interface WorkspaceResponse {
   previews?: WorkspacePreview[];
   tabs?: Tab[];
   resolve_conflicts?: ResolveConflictRecordPayload[];
+  push_gates?: PushGateRun; // most recent pre-push gate run
+  setup?: WorkspaceSetupRun; // most recent setup command run
   status?: string;
   backburner?: boolean;
   intent_shared?: boolean;
@@ -130,7 +141,15 @@ export interface BuiltinQuickLaunchCookbook {
   prompt: string;
 }
 
-import type { PullRequest, Tab } from './types.generated';
+import type {
+  PullRequest,
+  PushGateRun,
+  SessionAcceptanceInfo,
+  SessionPipelineInfo,
+  SessionTournamentInfo,
+  Tab,
+  WorkspaceSetupRun,
+} from './types.generated';
 
 export type {
   ConfigResponse,
@@ -314,6 +333,7 @@ export interface LinearSyncResponse {
   pre_commit_error_detail?: string;
   needs_confirm?: boolean;
   diverged_commits?: string[];
+  push_gates?: PushGateRun; // set when the repo has push gates
 }
 
 interface ConflictResolution {
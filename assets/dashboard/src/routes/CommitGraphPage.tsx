@@ -5,6 +5,8 @@ import WorkspaceHeader from '../components/WorkspaceHeader';
 import SessionTabs from '../components/SessionTabs';
 import CommitHistoryDAG from '../components/CommitHistoryDAG';
 import GitHubConnectBanner from '../components/GitHubConnectBanner';
+import PushGatesPanel from '../components/PushGatesPanel';
+import WorkspaceSetupPanel from '../components/WorkspaceSetupPanel';
 
 export default function CommitGraphPage() {
   const { workspaceId } = useParams();
@@ -31,6 +33,10 @@ export default function CommitGraphPage() {
       <WorkspaceHeader workspace={workspace} />
       <SessionTabs sessions={workspace.sessions || []} workspace={workspace} />
       {workspace.repo?.startsWith('local:') && <GitHubConnectBanner workspaceId={workspaceId} />}
+      {workspace.setup && workspace.setup.status !== 'passed' && (
+        <WorkspaceSetupPanel workspaceId={workspaceId} run={workspace.setup} />
+      )}
+      {workspace.push_gates && <PushGatesPanel run={workspace.push_gates} />}
       <CommitHistoryDAG workspaceId={workspaceId} />
     </>
   );
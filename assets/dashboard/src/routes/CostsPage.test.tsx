@@ -0,0 +1,112 @@
+import { describe, it, expect, vi, beforeEach } from 'vitest';
+import { render, screen, waitFor } from '@testing-library/react';
+import userEvent from '@testing-library/user-event';
+import { MemoryRouter } from 'react-router';
+import type { CostsResponse } from '../lib/types.generated';
+
+vi.mock('../lib/cost-api', () => ({
+  getCosts: vi.fn(),
+}));
+
+vi.mock('../lib/api', () => ({
+  getErrorMessage: vi.fn((_err: unknown, fallback: string) => fallback),
+}));
+
+import CostsPage from './CostsPage';
+import { getCosts } from '../lib/cost-api';
+
+const mockGetCosts = vi.mocked(getCosts);
+
+function row(key: string, cost: number) {
+  return {
+    key,
+    input_tokens: 1200,
+    output_tokens: 300,
+    cache_read_tokens: 2_500_000,
+    cache_write_tokens: 0,
+    cost_usd: cost,
+  };
+}
+
+function makeCosts(overrides: Partial<CostsResponse> = {}): CostsResponse {
+  return {
+    group_by: 'day',
+    since: '2026-02-01',
+    rows: [row('2026-03-02', 2.31), row('2026-03-01', 0.1)],
+    total: row('', 2.41),
+    budget: {
+      session_usd: 5,
+      daily_usd: 50,
+      warn_percent: 80,
+      today: '2026-03-02',
+      today_usd: 2.31,
+      daily_state: 'ok',
+    },
+    ...overrides,
+  };
+}
+
+function renderPage() {
+  return render(
+    <MemoryRouter>
+      <CostsPage />
+    </MemoryRouter>
+  );
+}
+
+beforeEach(() => {
+  vi.clearAllMocks();
+  mockGetCosts.mockResolvedValue(makeCosts());
+});
+
+describe('CostsPage', () => {
+  it('shows costs grouped by day by default', async () => {
+    renderPage();
+    await waitFor(() => {
+      expect(screen.getByTestId('cost-row-2026-03-02')).toHaveTextContent('$2.31');
+    });
+    expect(mockGetCosts).toHaveBeenCalledWith('day', 30);
+    expect(screen.getByTestId('cost-total')).toHaveTextContent('$2.41');
+    expect(screen.getByTestId('cost-row-2026-03-02')).toHaveTextContent('2.5M');
+    expect(screen.getByTestId('cost-budget')).toHaveTextContent('of $50.00 daily budget');
+  });
+
+  it('refetches with the chosen group_by and period', async () => {
+    renderPage();
+    await screen.findByTestId('cost-total');
+
+    mockGetCosts.mockResolvedValue(
+      makeCosts({ group_by: 'session', rows: [row('s-1', 2.31), row('', 0.1)] })
+    );
+    await userEvent.selectOptions(screen.getByLabelText('Group by'), 'session');
+    await waitFor(() => {
+      expect(mockGetCosts).toHaveBeenLastCalledWith('session', 30);
+    });
+    expect(await screen.findByRole('link', { name: 's-1' })).toHaveAttribute(
+      'href',
+      '/sessions/s-1'
+    );
+    expect(screen.getByText('oneshot calls')).toBeInTheDocument();
+
+    await userEvent.selectOptions(screen.getByLabelText('Period'), '7');
+    await waitFor(() => {
+      expect(mockGetCosts).toHaveBeenLastCalledWith('session', 7);
+    });
+  });
+
+  it('shows an empty state when nothing was spent', async () => {
+    mockGetCosts.mockResolvedValue(makeCosts({ rows: [], total: row('', 0) }));
+    renderPage();
+    await waitFor(() => {
+      expect(screen.getByText('No usage recorded')).toBeInTheDocument();
+    });
+  });
+
+  it('shows the error when costs fail to load', async () => {
+    mockGetCosts.mockRejectedValue(new Error('boom'));
+    renderPage();
+    await waitFor(() => {
+      expect(screen.getByText('Failed to load costs')).toBeInTheDocument();
+    });
+  });
+});
@@ -0,0 +1,182 @@
+import { useState, useEffect } from 'react';
+import { Link } from 'react-router';
+import { getCosts, type CostGroupBy } from '../lib/cost-api';
+import { getErrorMessage } from '../lib/api';
+import type { CostRow, CostsResponse } from '../lib/types.generated';
+
+const GROUPS: { id: CostGroupBy; label: string }[] = [
+  { id: 'day', label: 'Day' },
+  { id: 'repo', label: 'Repo' },
+  { id: 'target', label: 'Target' },
+  { id: 'session', label: 'Session' },
+  { id: 'workspace', label: 'Workspace' },
+  { id: 'feature', label: 'Feature' },
+  { id: 'model', label: 'Model' },
+];
+
+const DAYS = [1, 7, 30, 90];
+
+// Rows without the grouping key share the empty key; name what they are.
+function emptyKeyLabel(groupBy: CostGroupBy): string {
+  switch (groupBy) {
+    case 'feature':
+      return 'agent sessions';
+    case 'session':
+    case 'workspace':
+      return 'oneshot calls';
+    default:
+      return 'unknown';
+  }
+}
+
+function formatTokens(n: number): string {
+  if (n >= 1_000_000) return `${(n / 1_000_000).toFixed(1)}M`;
+  if (n >= 1_000) return `${(n / 1_000).toFixed(1)}k`;
+  return String(n);
+}
+
+function formatUSD(row: CostRow): string {
+  return `$${row.cost_usd.toFixed(2)}${row.unpriced ? '*' : ''}`;
+}
+
+function RowKey({ row, groupBy }: { row: CostRow; groupBy: CostGroupBy }) {
+  if (!row.key) return <span className="text-muted">{emptyKeyLabel(groupBy)}</span>;
+  if (groupBy === 'session') return <Link to={`/sessions/${row.key}`}>{row.key}</Link>;
+  return <>{row.key}</>;
+}
+
+function BudgetSummary({ budget }: { budget: CostsResponse['budget'] }) {
+  const state =
+    budget.daily_state === 'exceeded'
+      ? 'badge badge--danger'
+      : budget.daily_state === 'warning'
+        ? 'badge badge--warning'
+        : 'badge badge--success';
+  return (
+    <p className="mb-lg" data-testid="cost-budget">
+      Today: ${budget.today_usd.toFixed(2)}
+      {budget.daily_usd > 0 && (
+        <>
+          {' '}
+          of ${budget.daily_usd.toFixed(2)} daily budget{' '}
+          <span className={state}>{budget.daily_state}</span>
+        </>
+      )}
+    </p>
+  );
+}
+
+export default function CostsPage() {
+  const [groupBy, setGroupBy] = useState<CostGroupBy>('day');
+  const [days, setDays] = useState(30);
+  const [data, setData] = useState<CostsResponse | null>(null);
+  const [error, setError] = useState('');
+
+  useEffect(() => {
+    let cancelled = false;
+    getCosts(groupBy, days)
+      .then((resp) => {
+        if (cancelled) return;
+        setData(resp);
+        setError('');
+      })
+      .catch((err) => {
+        if (!cancelled) setError(getErrorMessage(err, 'Failed to load costs'));
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [groupBy, days]);
+
+  const unpriced = data?.rows.some((r) => r.unpriced);
+
+  return (
+    <div className="page-content">
+      <div className="app-header">
+        <div className="app-header__info">
+          <h1 className="app-header__meta">Costs</h1>
+        </div>
+        <div className="app-header__actions">
+          <select
+            className="select"
+            value={groupBy}
+            onChange={(e) => setGroupBy(e.target.value as CostGroupBy)}
+            aria-label="Group by"
+          >
+            {GROUPS.map((g) => (
+              <option key={g.id} value={g.id}>
+                By {g.label.toLowerCase()}
+              </option>
+            ))}
+          </select>
+          <select
+            className="select"
+            value={days}
+            onChange={(e) => setDays(Number(e.target.value))}
+            aria-label="Period"
+          >
+            {DAYS.map((d) => (
+              <option key={d} value={d}>
+                {d === 1 ? 'Today' : `Last ${d} days`}
+              </option>
+            ))}
+          </select>
+        </div>
+      </div>
+
+      {error ? (
+        <p className="text-danger">{error}</p>
+      ) : !data ? (
+        <p className="text-muted">Loading...</p>
+      ) : (
+        <>
+          <BudgetSummary budget={data.budget} />
+          {data.rows.length === 0 ? (
+            <div className="empty-state">
+              <h3 className="empty-state__title">No usage recorded</h3>
+              <p className="empty-state__description">Nothing was spent since {data.since}.</p>
+            </div>
+          ) : (
+            <table className="session-table cost-table">
+              <thead>
+                <tr>
+                  <th>{GROUPS.find((g) => g.id === groupBy)?.label}</th>
+                  <th>Input</th>
+                  <th>Output</th>
+                  <th>Cache read</th>
+                  <th>Cache write</th>
+                  <th>Cost</th>
+                </tr>
+              </thead>
+              <tbody>
+                {data.rows.map((row) => (
+                  <tr key={row.key} data-testid={`cost-row-${row.key}`}>
+                    <td>
+                      <RowKey row={row} groupBy={groupBy} />
+                    </td>
+                    <td>{formatTokens(row.input_tokens)}</td>
+                    <td>{formatTokens(row.output_tokens)}</td>
+                    <td>{formatTokens(row.cache_read_tokens)}</td>
+                    <td>{formatTokens(row.cache_write_tokens)}</td>
+                    <td>{formatUSD(row)}</td>
+                  </tr>
+                ))}
+              </tbody>
+              <tfoot>
+                <tr data-testid="cost-total">
+                  <th>Total</th>
+                  <th>{formatTokens(data.total.input_tokens)}</th>
+                  <th>{formatTokens(data.total.output_tokens)}</th>
+                  <th>{formatTokens(data.total.cache_read_tokens)}</th>
+                  <th>{formatTokens(data.total.cache_write_tokens)}</th>
+                  <th>{formatUSD(data.total)}</th>
+                </tr>
+              </tfoot>
+            </table>
+          )}
+          {unpriced && <p className="text-muted">* Includes usage of models with no known price.</p>}
+        </>
+      )}
+    </div>
+  );
+}
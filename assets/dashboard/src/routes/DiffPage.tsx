@@ -20,6 +20,7 @@ import useSidebarLayout from '../hooks/useSidebarLayout';
 import WorkspaceHeader from '../components/WorkspaceHeader';
 import SessionTabs from '../components/SessionTabs';
 import Tooltip from '../components/Tooltip';
+import CheckpointsPanel from '../components/CheckpointsPanel';
 import { copyToClipboard, splitPath } from '../lib/utils';
 import type { DiffResponse, DiffFileContentResponse } from '../lib/types';
 
@@ -109,6 +110,9 @@ export default function DiffPage() {
     window.location.hostname !== 'localhost' && window.location.hostname !== '127.0.0.1';
   const isRemoteAccess = isRemoteClient || simulateRemote;
   const externalDiffCommands = config?.external_diff_commands || [];
+  // Checkpoints are local-git only
+  const canCheckpoint =
+    !!workspace && !workspace.remote_host_id && (!workspace.vcs || workspace.vcs === 'git');
 
   // Navigate home if workspace was disposed
   useEffect(() => {
@@ -421,6 +425,10 @@ export default function DiffPage() {
           </div>
         )}
 
+        {workspaceId && canCheckpoint && (
+          <CheckpointsPanel workspaceId={workspaceId} selectedPath={selectedKey || undefined} />
+        )}
+
         <div className="diff-layout" ref={containerRef}>
           <div
             className={`diff-sidebar${keyboardFocus === 'left' ? ' diff-sidebar--focused' : ''} flex-shrink-0`}
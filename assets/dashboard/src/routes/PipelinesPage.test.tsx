@@ -0,0 +1,111 @@
+import { describe, it, expect, vi, beforeEach } from 'vitest';
+import { render, screen, waitFor } from '@testing-library/react';
+import userEvent from '@testing-library/user-event';
+import { MemoryRouter } from 'react-router';
+import type { PipelineRun } from '../lib/types.generated';
+
+const workspaces: never[] = [];
+vi.mock('../contexts/SessionsContext', () => ({
+  useSessions: () => ({ workspaces }),
+}));
+
+vi.mock('../lib/pipeline-api', () => ({
+  getPipelineRuns: vi.fn(),
+  cancelPipelineRun: vi.fn(),
+}));
+
+vi.mock('../lib/api', () => ({
+  getErrorMessage: vi.fn((_err: unknown, fallback: string) => fallback),
+}));
+
+vi.mock('../components/ToastProvider', () => ({
+  useToast: () => ({ success: vi.fn(), error: vi.fn() }),
+}));
+
+vi.mock('../components/ModalProvider', () => ({
+  useModal: () => ({ confirm: vi.fn().mockResolvedValue(true) }),
+}));
+
+import PipelinesPage from './PipelinesPage';
+import { getPipelineRuns, cancelPipelineRun } from '../lib/pipeline-api';
+
+const mockGetRuns = vi.mocked(getPipelineRuns);
+const mockCancel = vi.mocked(cancelPipelineRun);
+
+function makeRun(overrides: Partial<PipelineRun> = {}): PipelineRun {
+  return {
+    id: 'pr-1',
+    pipeline_id: 'pl-1',
+    name: 'implement-and-review',
+    repo: 'schmux',
+    status: 'running',
+    stages: [
+      { stage_id: 'impl', status: 'succeeded', session_ids: ['s-1'] },
+      { stage_id: 'review', name: 'Review', status: 'running', session_ids: ['s-2'] },
+      { stage_id: 'ship', status: 'pending' },
+    ],
+    pipeline: {
+      id: 'pl-1',
+      name: 'implement-and-review',
+      stages: [],
+      created_at: '2026-01-01T00:00:00Z',
+      updated_at: '2026-01-01T00:00:00Z',
+    },
+    created_at: '2026-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+function renderPage() {
+  return render(
+    <MemoryRouter>
+      <PipelinesPage />
+    </MemoryRouter>
+  );
+}
+
+beforeEach(() => {
+  vi.clearAllMocks();
+  mockGetRuns.mockResolvedValue([]);
+});
+
+describe('PipelinesPage', () => {
+  it('shows empty state when there are no runs', async () => {
+    renderPage();
+    await waitFor(() => {
+      expect(screen.getByText('No pipeline runs')).toBeInTheDocument();
+    });
+  });
+
+  it('renders per-stage progress with session links', async () => {
+    mockGetRuns.mockResolvedValue([makeRun()]);
+    renderPage();
+    await waitFor(() => {
+      expect(screen.getByText('implement-and-review')).toBeInTheDocument();
+    });
+    expect(screen.getByText('1/3')).toBeInTheDocument();
+    expect(screen.getByText('Review')).toBeInTheDocument();
+    expect(screen.getByRole('link', { name: 's-2' })).toHaveAttribute('href', '/sessions/s-2');
+    expect(screen.getByTestId('stage-ship')).toHaveTextContent('pending');
+  });
+
+  it('cancels a running run', async () => {
+    mockGetRuns.mockResolvedValue([makeRun()]);
+    mockCancel.mockResolvedValue(makeRun({ status: 'canceled' }));
+    renderPage();
+    const button = await screen.findByText('Cancel');
+    await userEvent.click(button);
+    await waitFor(() => {
+      expect(mockCancel).toHaveBeenCalledWith('pr-1');
+    });
+  });
+
+  it('offers no cancel for finished runs', async () => {
+    mockGetRuns.mockResolvedValue([makeRun({ status: 'completed' })]);
+    renderPage();
+    await waitFor(() => {
+      expect(screen.getByText('completed')).toBeInTheDocument();
+    });
+    expect(screen.queryByText('Cancel')).not.toBeInTheDocument();
+  });
+});
@@ -0,0 +1,171 @@
+import { useState, useEffect, useCallback } from 'react';
+import { Link } from 'react-router';
+import { getPipelineRuns, cancelPipelineRun } from '../lib/pipeline-api';
+import { getErrorMessage } from '../lib/api';
+import { useSessions } from '../contexts/SessionsContext';
+import { useToast } from '../components/ToastProvider';
+import { useModal } from '../components/ModalProvider';
+import type { PipelineRun, PipelineStageRun } from '../lib/types.generated';
+
+function badgeClass(status: string): string {
+  switch (status) {
+    case 'completed':
+    case 'succeeded':
+      return 'badge badge--success';
+    case 'failed':
+      return 'badge badge--danger';
+    case 'running':
+    case 'spawning':
+      return 'badge badge--info';
+    case 'canceled':
+      return 'badge badge--warning';
+    default:
+      return 'badge badge--neutral';
+  }
+}
+
+function stageProgress(run: PipelineRun): string {
+  const done = run.stages.filter((st) => st.status === 'succeeded').length;
+  return `${done}/${run.stages.length}`;
+}
+
+function StageRow({ stage }: { stage: PipelineStageRun }) {
+  return (
+    <li className="pipeline-stage" data-testid={`stage-${stage.stage_id}`}>
+      <span className={badgeClass(stage.status)}>{stage.status}</span>{' '}
+      <span className="pipeline-stage__name">{stage.name || stage.stage_id}</span>
+      {(stage.session_ids || []).map((id) => (
+        <Link key={id} className="pipeline-stage__session" to={`/sessions/${id}`}>
+          {id}
+        </Link>
+      ))}
+      {stage.error && <span className="text-danger"> {stage.error}</span>}
+    </li>
+  );
+}
+
+export default function PipelinesPage() {
+  // Stage progress follows session status, so refetch whenever sessions change.
+  const { workspaces } = useSessions();
+  const [runs, setRuns] = useState<PipelineRun[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState('');
+  const [canceling, setCanceling] = useState<string | null>(null);
+  const { success: toastSuccess, error: toastError } = useToast();
+  const { confirm } = useModal();
+
+  const loadRuns = useCallback(async () => {
+    try {
+      const data = await getPipelineRuns();
+      setRuns(data || []);
+      setError('');
+    } catch (err) {
+      setError(getErrorMessage(err, 'Failed to load pipeline runs'));
+    } finally {
+      setLoading(false);
+    }
+  }, []);
+
+  useEffect(() => {
+    loadRuns();
+  }, [loadRuns, workspaces]);
+
+  const handleCancel = async (run: PipelineRun) => {
+    if (!(await confirm(`Cancel pipeline run "${run.name}"?`, { danger: true }))) return;
+    try {
+      setCanceling(run.id);
+      await cancelPipelineRun(run.id);
+      toastSuccess(`Canceled "${run.name}"`);
+      await loadRuns();
+    } catch (err) {
+      toastError(getErrorMessage(err, 'Failed to cancel pipeline run'));
+    } finally {
+      setCanceling(null);
+    }
+  };
+
+  const header = (
+    <div className="app-header">
+      <div className="app-header__info">
+        <h1 className="app-header__meta">Pipelines</h1>
+      </div>
+    </div>
+  );
+
+  if (loading) {
+    return (
+      <div className="page-content">
+        {header}
+        <p className="text-muted">Loading...</p>
+      </div>
+    );
+  }
+
+  if (error) {
+    return (
+      <div className="page-content">
+        {header}
+        <p className="text-danger">{error}</p>
+      </div>
+    );
+  }
+
+  return (
+    <div className="page-content">
+      {header}
+      {runs.length === 0 ? (
+        <div className="empty-state">
+          <h3 className="empty-state__title">No pipeline runs</h3>
+          <p className="empty-state__description">
+            Start one with <code>schmux pipeline run</code>.
+          </p>
+        </div>
+      ) : (
+        <table className="session-table pipeline-table">
+          <thead>
+            <tr>
+              <th>Run</th>
+              <th>Status</th>
+              <th>Stages</th>
+              <th>Action</th>
+            </tr>
+          </thead>
+          <tbody>
+            {runs.map((run) => (
+              <tr key={run.id} data-testid={`run-${run.id}`}>
+                <td>
+                  <div>{run.name}</div>
+                  <div className="text-muted">
+                    {run.repo} · {new Date(run.created_at).toLocaleString()}
+                  </div>
+                </td>
+                <td>
+                  <span className={badgeClass(run.status)}>{run.status}</span>{' '}
+                  <span className="text-muted">{stageProgress(run)}</span>
+                </td>
+                <td>
+                  <ul className="pipeline-stages">
+                    {run.stages.map((st) => (
+                      <StageRow key={st.stage_id} stage={st} />
+                    ))}
+                  </ul>
+                </td>
+                <td>
+                  {run.status === 'running' && (
+                    <button
+                      className="btn btn--sm btn--danger"
+                      onClick={() => handleCancel(run)}
+                      disabled={canceling === run.id}
+                    >
+                      {canceling === run.id ? 'Canceling...' : 'Cancel'}
+                    </button>
+                  )}
+                </td>
+              </tr>
+            ))}
+          </tbody>
+        </table>
+      )}
+    </div>
+  );
+}
@@ -14,6 +14,8 @@ import {
   exportTimelapseRecording,
 } from '../lib/api';
 import { copyToClipboard, formatRelativeTime, formatTimestamp } from '../lib/utils';
+import { promoteTournament } from '../lib/tournament-api';
+import { runSessionAcceptance } from '../lib/acceptance-api';
 import { useToast } from '../components/ToastProvider';
 import { useModal } from '../components/ModalProvider';
 import { useConfig } from '../contexts/ConfigContext';
@@ -476,6 +478,34 @@ export default function SessionDetailPage() {
     }
   }, [sessionId, sessionData?.nickname, confirm, success, alert]);
 
+  const handlePromote = useCallback(async () => {
+    const tournament = sessionData?.tournament;
+    if (!sessionId || !tournament) return;
+    const accepted = await confirm(
+      `Promote this session and dispose the other ${tournament.candidates - 1} candidate workspace(s)?`,
+      { danger: true }
+    );
+    if (!accepted) return;
+    try {
+      await promoteTournament(tournament.tournament_id, sessionId);
+      success('Candidate promoted');
+    } catch (err) {
+      alert('Promote Failed', `Failed to promote: ${getErrorMessage(err, 'Unknown error')}`);
+    }
+  }, [sessionId, sessionData?.tournament, confirm, success, alert]);
+
+  const handleRunAcceptance = useCallback(async () => {
+    if (!sessionId) return;
+    try {
+      await runSessionAcceptance(sessionId);
+    } catch (err) {
+      alert(
+        'Acceptance Failed',
+        `Failed to run acceptance checks: ${getErrorMessage(err, 'Unknown error')}`
+      );
+    }
+  }, [sessionId, alert]);
+
   // Register keyboard shortcut for resume/scroll to bottom (Down arrow)
   useEffect(() => {
     if (!sessionId) return;
@@ -1181,6 +1211,12 @@ export default function SessionDetailPage() {
                 </span>
               </div>
             ) : null}
+            {sessionData.cost_usd ? (
+              <div className="metadata-field">
+                <span className="metadata-field__label">Spend</span>
+                <span className="metadata-field__value">${sessionData.cost_usd.toFixed(2)}</span>
+              </div>
+            ) : null}
 
             {sessionData.nickname ? (
               <div className="metadata-field" data-testid="session-nickname">
@@ -1261,6 +1297,79 @@ export default function SessionDetailPage() {
               </div>
             )}
 
+            {sessionData.pipeline && (
+              <div className="metadata-field">
+                <span className="metadata-field__label">Pipeline</span>
+                <span className="metadata-field__value">
+                  {sessionData.pipeline.pipeline_name} · stage{' '}
+                  {sessionData.pipeline.stage_index + 1}/{sessionData.pipeline.stage_count} (
+                  {sessionData.pipeline.stage_name || sessionData.pipeline.stage_id}) ·{' '}
+                  {sessionData.pipeline.run_status}
+                </span>
+              </div>
+            )}
+
+            {sessionData.tournament && (
+              <div className="metadata-field">
+                <span className="metadata-field__label">Tournament</span>
+                <span className="metadata-field__value">
+                  {sessionData.tournament.winner
+                    ? 'winner'
+                    : sessionData.tournament.rank
+                      ? `rank ${sessionData.tournament.rank}/${sessionData.tournament.candidates}`
+                      : `${sessionData.tournament.candidates} candidates`}{' '}
+                  · {sessionData.tournament.status}
+                  {(sessionData.tournament.status === 'ready' ||
+                    sessionData.tournament.status === 'running') && (
+                    <button
+                      className="btn btn--sm btn--secondary"
+                      style={{ marginLeft: '8px' }}
+                      onClick={handlePromote}
+                      data-testid="promote-candidate"
+                    >
+                      Promote
+                    </button>
+                  )}
+                </span>
+              </div>
+            )}
+
+            {sessionData.acceptance && (
+              <div className="metadata-field" data-testid="session-acceptance">
+                <span className="metadata-field__label">Acceptance</span>
+                <span className="metadata-field__value">
+                  {sessionData.acceptance.status}
+                  {sessionData.acceptance.failed_check &&
+                    sessionData.acceptance.status !== 'running' &&
+                    sessionData.acceptance.status !== 'passed' &&
+                    ` · ${sessionData.acceptance.failed_check}`}
+                  {!!sessionData.acceptance.failures &&
+                    ` · ${sessionData.acceptance.failures}/${sessionData.acceptance.max_retries + 1} attempts failed`}
+                  {sessionData.acceptance.status !== 'running' && (
+                    <button
+                      className="btn btn--sm btn--secondary"
+                      style={{ marginLeft: '8px' }}
+                      onClick={handleRunAcceptance}
+                      data-testid="run-acceptance"
+                    >
+                      Run now
+                    </button>
+                  )}
+                </span>
+              </div>
+            )}
+
+            {sessionData.status === 'queued' && (
+              <div className="metadata-field">
+                <span className="metadata-field__label">Queue</span>
+                <span className="metadata-field__value">
+                  {sessionData.queue_position ? `#${sessionData.queue_position}` : 'waiting'}
+                  {sessionData.queue_eta &&
+                    ` · eta ${new Date(sessionData.queue_eta).toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' })}`}
+                </span>
+              </div>
+            )}
+
             <div className="metadata-field">
               <span className="metadata-field__label">Created</span>
               <Tooltip content={formatTimestamp(sessionData.created_at)}>
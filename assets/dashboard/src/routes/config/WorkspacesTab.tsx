@@ -110,11 +110,11 @@ export default function WorkspacesTab({
                 <div className="item-list__item-primary">
                   <span className="item-list__item-name">
                     {repo.name}
-                    {(repo.vcs === 'sapling' || repo.vcs === 'git-clone') && (
+                    {(repo.vcs === 'sapling' || repo.vcs === 'jj' || repo.vcs === 'git-clone') && (
                       <span
                         style={{ marginLeft: 'var(--spacing-xs)', fontSize: '0.8em', opacity: 0.7 }}
                       >
-                        [{repo.vcs === 'sapling' ? 'sapling' : 'git clone'}]
+                        [{repo.vcs === 'git-clone' ? 'git clone' : repo.vcs}]
                       </span>
                     )}
                   </span>
@@ -203,6 +203,7 @@ export default function WorkspacesTab({
               <option value="">git worktree</option>
               <option value="git-clone">git clone</option>
               <option value="sapling">sapling</option>
+              <option value="jj">jj</option>
             </select>
           </div>
           <button
@@ -30,6 +30,8 @@ func main() {
 		reflect.TypeOf(contracts.CommitGraphResponse{}),
 		reflect.TypeOf(contracts.CommitDetailResponse{}),
 		reflect.TypeOf(contracts.BranchDivergenceResponse{}),
+		reflect.TypeOf(contracts.DiskUsage{}),
+		reflect.TypeOf(contracts.AutoCleanupReport{}),
 		reflect.TypeOf(contracts.PushCommitsResult{}),
 		reflect.TypeOf(contracts.GitHubStatus{}),
 		reflect.TypeOf(contracts.GitHubConnectStatus{}),
@@ -49,6 +51,23 @@ func main() {
 		reflect.TypeOf(contracts.UpdateSpawnEntryRequest{}),
 		reflect.TypeOf(contracts.SpawnMetadata{}),
 		reflect.TypeOf(contracts.PromptHistoryResponse{}),
+		reflect.TypeOf(contracts.PipelinesResponse{}),
+		reflect.TypeOf(contracts.PipelineRunsResponse{}),
+		reflect.TypeOf(contracts.TournamentsResponse{}),
+		reflect.TypeOf(contracts.TournamentPromoteRequest{}),
+		reflect.TypeOf(contracts.SessionAcceptance{}),
+		reflect.TypeOf(contracts.WebhooksResponse{}),
+		reflect.TypeOf(contracts.WebhookDeliveriesResponse{}),
+		reflect.TypeOf(contracts.APITokensResponse{}),
+		reflect.TypeOf(contracts.CreateAPITokenRequest{}),
+		reflect.TypeOf(contracts.CreateAPITokenResponse{}),
+		reflect.TypeOf(contracts.TriggerSpawnRequest{}),
+		reflect.TypeOf(contracts.CostsResponse{}),
+		reflect.TypeOf(contracts.CheckpointsResponse{}),
+		reflect.TypeOf(contracts.CheckpointRestoreRequest{}),
+		reflect.TypeOf(contracts.CheckpointRestoreResponse{}),
+		reflect.TypeOf(contracts.AgentMessagesResponse{}),
+		reflect.TypeOf(contracts.SearchResponse{}),
 		reflect.TypeOf(contracts.Features{}),
 		reflect.TypeOf(contracts.EnvironmentResponse{}),
 		reflect.TypeOf(contracts.Tab{}),
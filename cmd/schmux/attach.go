@@ -1,12 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"strings"
+	"syscall"
 
+	"github.com/sergeknystautas/schmux/internal/ptyhost"
 	"github.com/sergeknystautas/schmux/pkg/cli"
+	"golang.org/x/term"
 )
 
 // AttachCommand implements the attach command.
@@ -56,6 +61,10 @@ func (cmd *AttachCommand) Run(args []string) error {
 		return fmt.Errorf("session not found: %s", sessionID)
 	}
 
+	if found.Backend == "pty" {
+		return attachPTY(sessionID)
+	}
+
 	// Use structured fields for safe exec.Command construction (no shell injection).
 	tmuxSocket := found.TmuxSocket
 	if tmuxSocket == "" {
@@ -78,6 +87,87 @@ func (cmd *AttachCommand) Run(args []string) error {
 	return tmuxCmd.Run()
 }
 
+// ptyDetachKey (Ctrl-]) detaches from a pty-backed session, leaving it running.
+const ptyDetachKey = 0x1d
+
+// attachPTY connects the terminal to a session on the pty backend by talking
+// to its supervisor socket directly.
+func attachPTY(sessionID string) error {
+	conn, err := ptyhost.Dial(ptyhost.SocketPath(sessionID))
+	if err != nil {
+		return fmt.Errorf("failed to connect to session: %w", err)
+	}
+	defer conn.Close()
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("attach requires a terminal")
+	}
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to set raw mode: %w", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	resize := func() {
+		if cols, rows, err := term.GetSize(fd); err == nil {
+			conn.Resize(cols, rows)
+		}
+	}
+	resize()
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	defer signal.Stop(winch)
+	go func() {
+		for range winch {
+			resize()
+		}
+	}()
+
+	detached := make(chan struct{})
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				return
+			}
+			data := buf[:n]
+			if i := bytes.IndexByte(data, ptyDetachKey); i >= 0 {
+				if i > 0 {
+					conn.Write(data[:i])
+				}
+				close(detached)
+				conn.Close()
+				return
+			}
+			if _, err := conn.Write(data); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		f, err := conn.ReadFrame()
+		if err != nil {
+			select {
+			case <-detached:
+				fmt.Print("\r\n[detached]\r\n")
+				return nil
+			default:
+			}
+			return fmt.Errorf("connection to session lost: %w", err)
+		}
+		switch f.Type {
+		case ptyhost.FrameReplay, ptyhost.FrameOutput:
+			os.Stdout.Write(f.Payload)
+		case ptyhost.FrameExit:
+			fmt.Print("\r\n[session exited]\r\n")
+			return nil
+		}
+	}
+}
+
 // parseTmuxSession extracts the tmux session name from an attach command.
 // Handles both quoted and unquoted session names, stripping the "=" exact-match prefix.
 // Examples:
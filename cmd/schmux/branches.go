@@ -63,6 +63,7 @@ func (cmd *BranchesCommand) Run(args []string) error {
 		Dirty         bool     `json:"dirty"`
 		SessionCount  int      `json:"session_count"`
 		SessionStates []string `json:"session_states"`
+		DiskBytes     int64    `json:"disk_bytes"`
 		Error         string   `json:"error,omitempty"`
 		Disconnected  bool     `json:"disconnected,omitempty"`
 	}
@@ -82,11 +83,11 @@ func (cmd *BranchesCommand) Run(args []string) error {
 	}
 
 	// Table output
-	fmt.Printf("%-18s %-25s %-8s %-12s %-6s %s\n", "Workspace", "Branch", "Main", "Origin", "Dirty", "Sessions")
-	fmt.Printf("%-18s %-25s %-8s %-12s %-6s %s\n", "---------", "------", "----", "------", "-----", "--------")
+	fmt.Printf("%-18s %-25s %-8s %-12s %-6s %-9s %s\n", "Workspace", "Branch", "Main", "Origin", "Dirty", "Disk", "Sessions")
+	fmt.Printf("%-18s %-25s %-8s %-12s %-6s %-9s %s\n", "---------", "------", "----", "------", "-----", "----", "--------")
 	for _, e := range entries {
 		if e.Disconnected {
-			fmt.Printf("%-18s %-25s %-8s %-12s %-6s %s\n", truncate(e.WorkspaceID, 18), "(disconnected)", "", "", "", "")
+			fmt.Printf("%-18s %-25s %-8s %-12s %-6s %-9s %s\n", truncate(e.WorkspaceID, 18), "(disconnected)", "", "", "", "", "")
 			continue
 		}
 
@@ -106,19 +107,74 @@ func (cmd *BranchesCommand) Run(args []string) error {
 			sessCol = "0"
 		}
 
-		fmt.Printf("%-18s %-25s %-8s %-12s %-6s %s\n",
+		fmt.Printf("%-18s %-25s %-8s %-12s %-6s %-9s %s\n",
 			truncate(e.WorkspaceID, 18),
 			truncate(e.Branch, 25),
 			mainCol,
 			originCol,
 			dirtyCol,
+			formatDiskBytes(e.DiskBytes),
 			sessCol,
 		)
 	}
 
+	cmd.printDiskUsage(httpClient)
 	return nil
 }
 
+// printDiskUsage prints the total disk usage line under the table. It is
+// skipped quietly if the daemon does not report disk usage.
+func (cmd *BranchesCommand) printDiskUsage(httpClient *http.Client) {
+	resp, err := httpClient.Get(cmd.client.BaseURL() + "/api/disk-usage")
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+	var usage struct {
+		TotalBytes      int64 `json:"total_bytes"`
+		QuotaBytes      int64 `json:"quota_bytes"`
+		RecordingsBytes int64 `json:"recordings_bytes"`
+		RepoBases       []struct {
+			Bytes int64 `json:"bytes"`
+		} `json:"repo_bases"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&usage); err != nil {
+		return
+	}
+	var repoBases int64
+	for _, rb := range usage.RepoBases {
+		repoBases += rb.Bytes
+	}
+	line := fmt.Sprintf("\nDisk: %s", formatDiskBytes(usage.TotalBytes))
+	if usage.QuotaBytes > 0 {
+		line += fmt.Sprintf(" of %s quota", formatDiskBytes(usage.QuotaBytes))
+	}
+	fmt.Printf("%s (repo bases %s, recordings %s)\n", line, formatDiskBytes(repoBases), formatDiskBytes(usage.RecordingsBytes))
+}
+
+// formatDiskBytes formats a byte count for the Disk column; "-" means not
+// measured yet.
+func formatDiskBytes(bytes int64) string {
+	const (
+		KB = 1024
+		MB = 1024 * KB
+		GB = 1024 * MB
+	)
+	switch {
+	case bytes <= 0:
+		return "-"
+	case bytes >= GB:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/float64(GB))
+	case bytes >= MB:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/float64(MB))
+	default:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/float64(KB))
+	}
+}
+
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
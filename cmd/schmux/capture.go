@@ -68,9 +68,10 @@ func (cmd *CaptureCommand) Run(args []string) error {
 	}
 
 	var result struct {
-		SessionID string `json:"session_id"`
-		Lines     int    `json:"lines"`
-		Output    string `json:"output"`
+		SessionID  string `json:"session_id"`
+		Lines      int    `json:"lines"`
+		Output     string `json:"output"`
+		Redactions int    `json:"redactions,omitempty"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return fmt.Errorf("failed to parse response: %w", err)
@@ -83,5 +84,8 @@ func (cmd *CaptureCommand) Run(args []string) error {
 	}
 
 	fmt.Print(result.Output)
+	if result.Redactions > 0 {
+		fmt.Fprintf(os.Stderr, "(%d secrets redacted)\n", result.Redactions)
+	}
 	return nil
 }
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/pkg/cli"
+)
+
+const checkpointUsage = `usage: schmux checkpoint <subcommand>
+
+Subcommands:
+  list <workspace-id> [--json]      List checkpoints, newest first
+  create <workspace-id> [--json]    Checkpoint the working tree now
+  restore <workspace-id> <seq> [--file <path>] [--json]
+                                    Restore the working tree, or one file,
+                                    to a checkpoint
+
+Flags:
+  --file <path>                     Restore only this path (relative to the workspace)
+  --json                            JSON output`
+
+// CheckpointCommand implements the checkpoint command.
+type CheckpointCommand struct {
+	client cli.DaemonClient
+}
+
+// NewCheckpointCommand creates a new checkpoint command.
+func NewCheckpointCommand(client cli.DaemonClient) *CheckpointCommand {
+	return &CheckpointCommand{client: client}
+}
+
+type checkpointInfo struct {
+	Seq          int       `json:"seq"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
+	FilesChanged int       `json:"files_changed"`
+	LinesAdded   int       `json:"lines_added"`
+	LinesRemoved int       `json:"lines_removed"`
+}
+
+// Run executes the checkpoint command.
+func (cmd *CheckpointCommand) Run(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("%s", checkpointUsage)
+	}
+	sub, rest := args[0], args[1:]
+
+	var (
+		jsonOutput bool
+		file       string
+		positional []string
+	)
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+		switch {
+		case arg == "--json":
+			jsonOutput = true
+		case arg == "--file":
+			if sub != "restore" {
+				return fmt.Errorf("unknown flag: %s", arg)
+			}
+			if i+1 >= len(rest) {
+				return fmt.Errorf("flag %s requires a value", arg)
+			}
+			i++
+			file = rest[i]
+		case strings.HasPrefix(arg, "-"):
+			return fmt.Errorf("unknown flag: %s", arg)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	want := map[string]int{"list": 1, "create": 1, "restore": 2}
+	n, ok := want[sub]
+	if !ok {
+		return fmt.Errorf("unknown checkpoint subcommand: %s\n\n%s", sub, checkpointUsage)
+	}
+	if len(positional) != n {
+		return fmt.Errorf("%s", checkpointUsage)
+	}
+	var seq int
+	if sub == "restore" {
+		var err error
+		if seq, err = strconv.Atoi(positional[1]); err != nil || seq <= 0 {
+			return fmt.Errorf("invalid checkpoint: %s", positional[1])
+		}
+	}
+
+	if !cmd.client.IsRunning() {
+		return fmt.Errorf("daemon is not running. Start it with: schmux start")
+	}
+
+	base := "/api/workspaces/" + url.PathEscape(positional[0]) + "/checkpoints"
+	switch sub {
+	case "list":
+		return cmd.list(base, jsonOutput)
+	case "create":
+		body, err := daemonDo(cmd.client, http.MethodPost, base, nil)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printRawJSON(body)
+		}
+		var cp checkpointInfo
+		if err := json.Unmarshal(body, &cp); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		fmt.Printf("Checkpoint %d (%s)\n", cp.Seq, formatCheckpointStats(cp))
+		return nil
+	case "restore":
+		reqBody, _ := json.Marshal(map[string]string{"path": file})
+		body, err := daemonDo(cmd.client, http.MethodPost, fmt.Sprintf("%s/%d/restore", base, seq), reqBody)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printRawJSON(body)
+		}
+		var resp struct {
+			Backup *checkpointInfo `json:"backup"`
+			Path   string          `json:"path"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		target := "Workspace"
+		if resp.Path != "" {
+			target = resp.Path
+		}
+		fmt.Printf("%s restored to checkpoint %d.\n", target, seq)
+		if resp.Backup != nil {
+			fmt.Printf("Previous state saved as checkpoint %d.\n", resp.Backup.Seq)
+		}
+		return nil
+	}
+	return nil
+}
+
+func (cmd *CheckpointCommand) list(path string, jsonOutput bool) error {
+	body, err := daemonDo(cmd.client, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printRawJSON(body)
+	}
+	var resp struct {
+		Checkpoints []checkpointInfo `json:"checkpoints"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(resp.Checkpoints) == 0 {
+		fmt.Println("No checkpoints.")
+		return nil
+	}
+	fmt.Printf("%-5s %-20s %-22s %s\n", "SEQ", "CREATED", "CHANGES", "REASON")
+	for _, cp := range resp.Checkpoints {
+		fmt.Printf("%-5d %-20s %-22s %s\n", cp.Seq, cp.CreatedAt.Local().Format(time.DateTime), formatCheckpointStats(cp), cp.Reason)
+	}
+	return nil
+}
+
+// formatCheckpointStats renders a checkpoint's diff stats against its base.
+func formatCheckpointStats(cp checkpointInfo) string {
+	return fmt.Sprintf("%d files +%d -%d", cp.FilesChanged, cp.LinesAdded, cp.LinesRemoved)
+}
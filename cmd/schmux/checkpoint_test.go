@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckpointCommand_RunArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		isRunning   bool
+		errContains string
+	}{
+		{"no subcommand", nil, true, "usage:"},
+		{"unknown subcommand", []string{"prune"}, true, "unknown checkpoint subcommand"},
+		{"list missing workspace", []string{"list"}, true, "usage:"},
+		{"restore missing seq", []string{"restore", "ws-1"}, true, "usage:"},
+		{"restore bad seq", []string{"restore", "ws-1", "latest"}, true, "invalid checkpoint"},
+		{"file on list", []string{"list", "ws-1", "--file", "a.go"}, true, "unknown flag"},
+		{"file missing value", []string{"restore", "ws-1", "2", "--file"}, true, "requires a value"},
+		{"daemon not running", []string{"list", "ws-1"}, false, "daemon is not running"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewCheckpointCommand(&MockDaemonClient{isRunning: tt.isRunning}).Run(tt.args)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("error %q does not contain %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestCheckpointCommand_Restore(t *testing.T) {
+	var gotPath, gotFile string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		var req struct {
+			Path string `json:"path"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &req)
+		gotFile = req.Path
+		io.WriteString(w, `{"restored":{"seq":3},"backup":{"seq":7},"path":"main.go"}`)
+	}))
+	defer srv.Close()
+
+	cmd := NewCheckpointCommand(&MockDaemonClient{isRunning: true, baseURL: srv.URL})
+	var err error
+	silenceOutput(t, func() { err = cmd.Run([]string{"restore", "ws-1", "3", "--file", "main.go"}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/api/workspaces/ws-1/checkpoints/3/restore" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotFile != "main.go" {
+		t.Errorf("file = %q, want main.go", gotFile)
+	}
+}
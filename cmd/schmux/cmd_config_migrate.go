@@ -38,6 +38,10 @@ func errExitCode(err error) int {
 	if errors.As(err, &me) {
 		return me.exitCode
 	}
+	var re *runExitError
+	if errors.As(err, &re) {
+		return re.exitCode
+	}
 	return 1
 }
 
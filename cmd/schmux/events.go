@@ -117,6 +117,7 @@ func (cmd *EventsCommand) Run(args []string) error {
 			Error    string `json:"error"`
 			Category string `json:"category"`
 			Text     string `json:"text"`
+			To       string `json:"to"`
 		}
 		if err := json.Unmarshal(raw, &evt); err != nil {
 			continue
@@ -143,6 +144,8 @@ func (cmd *EventsCommand) Run(args []string) error {
 			fmt.Printf("  %s  %-8s %-14s tool=%s error=%q category=%s\n", ts, evt.Type, evt.Category, evt.Tool, evt.Error, evt.Category)
 		case "reflection", "friction":
 			fmt.Printf("  %s  %-8s %q\n", ts, evt.Type, evt.Text)
+		case "message":
+			fmt.Printf("  %s  %-8s to=%s %q\n", ts, evt.Type, evt.To, evt.Text)
 		default:
 			fmt.Printf("  %s  %s\n", ts, string(raw))
 		}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/pkg/cli"
+)
+
+const grepUsage = "usage: schmux grep <text> [--repo R] [--target T] [--workspace W] [--session S] [--kind output,event,prompt] [--since T] [--until T] [--limit N] [--json]"
+
+// GrepCommand implements the grep command.
+type GrepCommand struct {
+	client cli.DaemonClient
+}
+
+// NewGrepCommand creates a new grep command.
+func NewGrepCommand(client cli.DaemonClient) *GrepCommand {
+	return &GrepCommand{client: client}
+}
+
+type searchResult struct {
+	SessionID   string    `json:"session_id"`
+	Nickname    string    `json:"nickname"`
+	WorkspaceID string    `json:"workspace_id"`
+	Kind        string    `json:"kind"`
+	Seq         *uint64   `json:"seq"`
+	EventType   string    `json:"event_type"`
+	Time        time.Time `json:"time"`
+	Text        string    `json:"text"`
+}
+
+// grepFlags maps grep flags to /api/search query parameters.
+var grepFlags = map[string]string{
+	"--repo":      "repo",
+	"--target":    "target",
+	"--workspace": "workspace",
+	"--session":   "session",
+	"--kind":      "kind",
+	"--since":     "since",
+	"--until":     "until",
+	"--limit":     "limit",
+}
+
+// Run executes the grep command.
+func (cmd *GrepCommand) Run(args []string) error {
+	query := url.Values{}
+	var text []string
+	var jsonOutput bool
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if param, ok := grepFlags[arg]; ok {
+			if i+1 >= len(args) {
+				return fmt.Errorf("flag %s requires a value", arg)
+			}
+			if arg == "--limit" {
+				if n, err := strconv.Atoi(args[i+1]); err != nil || n < 0 {
+					return fmt.Errorf("invalid --limit value: %s", args[i+1])
+				}
+			}
+			query.Set(param, args[i+1])
+			i++
+			continue
+		}
+		switch {
+		case arg == "--json":
+			jsonOutput = true
+		case strings.HasPrefix(arg, "--"):
+			return fmt.Errorf("unknown flag: %s", arg)
+		default:
+			text = append(text, arg)
+		}
+	}
+	if len(text) == 0 {
+		return fmt.Errorf("%s", grepUsage)
+	}
+	query.Set("q", strings.Join(text, " "))
+
+	if !cmd.client.IsRunning() {
+		return fmt.Errorf("daemon is not running. Start it with: schmux start")
+	}
+
+	body, err := daemonDo(cmd.client, http.MethodGet, "/api/search?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printRawJSON(body)
+	}
+
+	var resp struct {
+		Results   []searchResult `json:"results"`
+		Truncated bool           `json:"truncated"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(resp.Results) == 0 {
+		fmt.Println("No matches")
+		return nil
+	}
+
+	for _, r := range resp.Results {
+		who := r.SessionID
+		if r.Nickname != "" {
+			who += " (" + r.Nickname + ")"
+		}
+		where := r.Kind
+		switch {
+		case r.Seq != nil:
+			where += "@" + strconv.FormatUint(*r.Seq, 10)
+		case r.EventType != "":
+			where += ":" + r.EventType
+		}
+		ts := "-"
+		if !r.Time.IsZero() {
+			ts = r.Time.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%s  %s  %-14s %s\n", who, ts, where, r.Text)
+	}
+	if resp.Truncated {
+		fmt.Printf("\n(showing the newest %d matches; narrow the search or raise --limit)\n", len(resp.Results))
+	}
+	return nil
+}
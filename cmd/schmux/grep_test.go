@@ -0,0 +1,68 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestGrepCommand_RunArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		isRunning   bool
+		errContains string
+	}{
+		{"no text", nil, true, "usage:"},
+		{"only flags", []string{"--repo", "r"}, true, "usage:"},
+		{"repo without value", []string{"x", "--repo"}, true, "requires a value"},
+		{"invalid limit", []string{"x", "--limit", "many"}, true, "invalid --limit"},
+		{"unknown flag", []string{"x", "--color"}, true, "unknown flag"},
+		{"daemon not running", []string{"x"}, false, "daemon is not running"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewGrepCommand(&MockDaemonClient{isRunning: tt.isRunning}).Run(tt.args)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("error %q does not contain %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestGrepCommand_Query(t *testing.T) {
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/search" {
+			http.NotFound(w, r)
+			return
+		}
+		got = r.URL.Query()
+		io.WriteString(w, `{"results":[{"session_id":"s-1","nickname":"builder","workspace_id":"ws-1","kind":"output","seq":42,"time":"2026-01-01T12:00:00Z","text":"connection refused"}],"truncated":true}`)
+	}))
+	defer srv.Close()
+
+	cmd := NewGrepCommand(&MockDaemonClient{isRunning: true, baseURL: srv.URL})
+	var err error
+	silenceOutput(t, func() {
+		err = cmd.Run([]string{"connection", "--repo", "schmux", "--kind", "output,event", "refused", "--since", "2h", "--limit", "5"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"q": "connection refused", "repo": "schmux", "kind": "output,event", "since": "2h", "limit": "5"}
+	for k, v := range want {
+		if got.Get(k) != v {
+			t.Errorf("query %s = %q, want %q", k, got.Get(k), v)
+		}
+	}
+	if got.Has("target") {
+		t.Errorf("unexpected target param: %q", got.Get("target"))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/sergeknystautas/schmux/pkg/cli"
 )
@@ -83,10 +84,7 @@ func (cmd *ListCommand) outputHuman(sessions []cli.WorkspaceWithSessions) error
 
 		// Sessions
 		for _, sess := range ws.Sessions {
-			status := "stopped"
-			if sess.Running {
-				status = "running"
-			}
+			status := sessionListStatus(sess)
 			name := sess.Target
 			if sess.Nickname != "" {
 				name = sess.Nickname
@@ -98,3 +96,22 @@ func (cmd *ListCommand) outputHuman(sessions []cli.WorkspaceWithSessions) error
 
 	return nil
 }
+
+// sessionListStatus renders a session's status for human output. Queued
+// sessions show their place in the spawn queue and, when known, an ETA.
+func sessionListStatus(sess cli.Session) string {
+	if sess.Status == "queued" {
+		status := "queued"
+		if sess.QueuePosition > 0 {
+			status = fmt.Sprintf("queued #%d", sess.QueuePosition)
+		}
+		if eta, err := time.Parse(time.RFC3339, sess.QueueETA); err == nil {
+			status += fmt.Sprintf(" (eta %s)", eta.Local().Format("15:04"))
+		}
+		return status
+	}
+	if sess.Running {
+		return "running"
+	}
+	return "stopped"
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sergeknystautas/schmux/pkg/cli"
 )
@@ -197,6 +198,28 @@ func TestListOutputHuman(t *testing.T) {
 	}
 }
 
+func TestSessionListStatus(t *testing.T) {
+	eta := time.Date(2026, 1, 10, 14, 30, 0, 0, time.Local)
+	tests := []struct {
+		name string
+		sess cli.Session
+		want string
+	}{
+		{"running", cli.Session{Running: true}, "running"},
+		{"stopped", cli.Session{}, "stopped"},
+		{"queued without position", cli.Session{Status: "queued"}, "queued"},
+		{"queued with position", cli.Session{Status: "queued", QueuePosition: 2}, "queued #2"},
+		{"queued with eta", cli.Session{Status: "queued", QueuePosition: 1, QueueETA: eta.Format(time.RFC3339)}, "queued #1 (eta 14:30)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sessionListStatus(tt.sess); got != tt.want {
+				t.Errorf("sessionListStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestListOutputHumanEmpty(t *testing.T) {
 	cmd := &ListCommand{}
 
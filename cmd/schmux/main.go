@@ -15,6 +15,7 @@ import (
 	"github.com/sergeknystautas/schmux/internal/github"
 	"github.com/sergeknystautas/schmux/internal/repofeed"
 	"github.com/sergeknystautas/schmux/internal/schmuxdir"
+	"github.com/sergeknystautas/schmux/internal/timelapse"
 	"github.com/sergeknystautas/schmux/internal/tunnel"
 	"github.com/sergeknystautas/schmux/internal/update"
 	"github.com/sergeknystautas/schmux/internal/version"
@@ -172,6 +173,14 @@ func main() {
 			os.Exit(1)
 		}
 
+	case "run":
+		client := cli.NewDaemonClient(cli.ResolveURL())
+		cmd := NewRunCommand(client)
+		if err := cmd.Run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(errExitCode(err))
+		}
+
 	case "list":
 		client := cli.NewDaemonClient(cli.ResolveURL())
 		cmd := NewListCommand(client)
@@ -271,6 +280,37 @@ func main() {
 			os.Exit(1)
 		}
 
+	case "messages":
+		client := cli.NewDaemonClient(cli.ResolveURL())
+		cmd := NewMessagesCommand(client)
+		if err := cmd.Run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "grep":
+		client := cli.NewDaemonClient(cli.ResolveURL())
+		cmd := NewGrepCommand(client)
+		if err := cmd.Run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "mcp":
+		client := cli.NewDaemonClient(cli.ResolveURL())
+		cmd := NewMCPCommand(client)
+		if err := cmd.Run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "pty-host":
+		code, err := runPTYHost(os.Args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(code)
+
 	case "capture":
 		client := cli.NewDaemonClient(cli.ResolveURL())
 		cmd := NewCaptureCommand(client)
@@ -295,6 +335,38 @@ func main() {
 			os.Exit(1)
 		}
 
+	case "pipeline":
+		client := cli.NewDaemonClient(cli.ResolveURL())
+		cmd := NewPipelineCommand(client)
+		if err := cmd.Run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "tournament":
+		client := cli.NewDaemonClient(cli.ResolveURL())
+		cmd := NewTournamentCommand(client)
+		if err := cmd.Run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "token":
+		client := cli.NewDaemonClient(cli.ResolveURL())
+		cmd := NewTokenCommand(client)
+		if err := cmd.Run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "checkpoint":
+		client := cli.NewDaemonClient(cli.ResolveURL())
+		cmd := NewCheckpointCommand(client)
+		if err := cmd.Run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
 	case "repofeed":
 		client := cli.NewDaemonClient(cli.ResolveURL())
 		cmd := NewRepofeedCommand(client)
@@ -374,21 +446,31 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Session Commands:")
 	fmt.Println("  spawn           Spawn a new session")
+	fmt.Println("  run             Spawn a session and wait for it to finish")
 	fmt.Println("  list            List sessions")
 	fmt.Println("  attach          Attach to a session")
 	fmt.Println("  dispose         Dispose a session")
 	fmt.Println("  tell            Send a message to a session")
 	fmt.Println("  events          Show session event history")
+	fmt.Println("  messages        Show messages a session sent to or received from other agents")
+	fmt.Println("  grep            Search output, events and prompts across sessions")
 	fmt.Println("  capture         Capture terminal output from a session")
+	if timelapse.IsAvailable() {
+		fmt.Println("  timelapse       List, export, and delete session recordings")
+	}
 	fmt.Println("  branches        Show all workspaces with VCS state")
+	fmt.Println("  pipeline        Define, run, and follow session pipelines")
+	fmt.Println("  tournament      Race several agents on one prompt and keep the best")
 	if repofeed.IsAvailable() {
 		fmt.Println("  repofeed        Show developer activity feed across repos")
 	}
 	fmt.Println("  end-shift       Signal floor manager shift rotation complete")
+	fmt.Println("  mcp             Serve schmux tools to agents over MCP (stdio)")
 	fmt.Println()
 	fmt.Println("Workspace Commands:")
 	fmt.Println("  refresh-overlay Refresh overlay files for a workspace")
 	fmt.Println("  inspect         Inspect VCS state of a workspace")
+	fmt.Println("  checkpoint      List, create, and restore workspace checkpoints")
 	fmt.Println()
 	if tunnel.IsAvailable() {
 		fmt.Println("Remote Commands:")
@@ -411,6 +493,7 @@ func printUsage() {
 		fmt.Println("  auth disable  Disable GitHub auth (lockout recovery)")
 	}
 	fmt.Println("  config migrate  Convert legacy string-form shell commands to argv arrays")
+	fmt.Println("  token           Create, list, and revoke API tokens")
 	fmt.Println("  version     Show version")
 	if update.IsAvailable() {
 		fmt.Println("  update      Update schmux to the latest version")
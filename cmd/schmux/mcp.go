@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sergeknystautas/schmux/internal/mcp"
+	"github.com/sergeknystautas/schmux/internal/version"
+	"github.com/sergeknystautas/schmux/pkg/cli"
+)
+
+const mcpUsage = `usage: schmux mcp [--workspace <id>]
+
+Serve schmux orchestration tools over the Model Context Protocol on stdio.
+Inside a schmux session the tools are scoped to the session's workspace
+($SCHMUX_WORKSPACE_ID); --workspace overrides it, and an empty value
+serves every workspace.`
+
+// MCPCommand implements the mcp command.
+type MCPCommand struct {
+	client cli.DaemonClient
+}
+
+// NewMCPCommand creates a new mcp command.
+func NewMCPCommand(client cli.DaemonClient) *MCPCommand {
+	return &MCPCommand{client: client}
+}
+
+// Run executes the mcp command, serving until stdin closes.
+func (cmd *MCPCommand) Run(args []string) error {
+	return cmd.serve(args, os.Stdin, os.Stdout)
+}
+
+func (cmd *MCPCommand) serve(args []string, in io.Reader, out io.Writer) error {
+	workspaceID := os.Getenv("SCHMUX_WORKSPACE_ID")
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--workspace", "-w":
+			if i+1 >= len(args) {
+				return fmt.Errorf("flag %s requires a value", args[i])
+			}
+			workspaceID = args[i+1]
+			i++
+		case "-h", "--help":
+			return fmt.Errorf("%s", mcpUsage)
+		default:
+			return fmt.Errorf("unknown flag: %s\n\n%s", args[i], mcpUsage)
+		}
+	}
+
+	server := mcp.NewServer("schmux", version.Version, mcp.NewTools(cmd.client, workspaceID))
+	return server.Serve(context.Background(), in, out)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sergeknystautas/schmux/pkg/cli"
+)
+
+const messagesUsage = "usage: schmux messages <session-id> [--last N] [--json]"
+
+// MessagesCommand implements the messages command.
+type MessagesCommand struct {
+	client cli.DaemonClient
+}
+
+// NewMessagesCommand creates a new messages command.
+func NewMessagesCommand(client cli.DaemonClient) *MessagesCommand {
+	return &MessagesCommand{client: client}
+}
+
+type agentMessage struct {
+	From      string    `json:"from"`
+	FromName  string    `json:"from_name"`
+	To        string    `json:"to"`
+	Recipient string    `json:"recipient"`
+	Text      string    `json:"text"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Run executes the messages command.
+func (cmd *MessagesCommand) Run(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("%s", messagesUsage)
+	}
+	sessionID := args[0]
+
+	var lastN int
+	var jsonOutput bool
+	rest := args[1:]
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case "--last":
+			if i+1 >= len(rest) {
+				return fmt.Errorf("flag --last requires a value")
+			}
+			n, err := strconv.Atoi(rest[i+1])
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid --last value: %s", rest[i+1])
+			}
+			lastN = n
+			i++
+		case "--json":
+			jsonOutput = true
+		default:
+			return fmt.Errorf("unknown flag: %s", rest[i])
+		}
+	}
+
+	if !cmd.client.IsRunning() {
+		return fmt.Errorf("daemon is not running. Start it with: schmux start")
+	}
+
+	path := "/api/sessions/" + url.PathEscape(sessionID) + "/messages"
+	if lastN > 0 {
+		path += "?last=" + strconv.Itoa(lastN)
+	}
+	body, err := daemonDo(cmd.client, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printRawJSON(body)
+	}
+
+	var resp struct {
+		Messages []agentMessage `json:"messages"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(resp.Messages) == 0 {
+		fmt.Printf("%s: no messages\n", sessionID)
+		return nil
+	}
+
+	fmt.Printf("%s messages:\n\n", sessionID)
+	for _, m := range resp.Messages {
+		ts := m.CreatedAt.Local().Format("15:04:05")
+		direction := "<- " + m.FromName
+		if m.From == sessionID {
+			direction = "-> " + m.To
+			if m.Recipient != "" && m.Recipient != m.To {
+				direction += " (" + m.Recipient + ")"
+			}
+		}
+		status := m.Status
+		if m.Error != "" {
+			status += ": " + m.Error
+		}
+		fmt.Printf("  %s  %-24s %q  [%s]\n", ts, direction, m.Text, status)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMessagesCommand_RunArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		isRunning   bool
+		errContains string
+	}{
+		{"no session", nil, true, "usage:"},
+		{"last without value", []string{"s-1", "--last"}, true, "requires a value"},
+		{"invalid last", []string{"s-1", "--last", "x"}, true, "invalid --last"},
+		{"unknown flag", []string{"s-1", "--type", "x"}, true, "unknown flag"},
+		{"daemon not running", []string{"s-1"}, false, "daemon is not running"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewMessagesCommand(&MockDaemonClient{isRunning: tt.isRunning}).Run(tt.args)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("error %q does not contain %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestMessagesCommand_List(t *testing.T) {
+	var gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/sessions/s-1/messages" {
+			http.NotFound(w, r)
+			return
+		}
+		gotQuery = r.URL.RawQuery
+		io.WriteString(w, `{"messages":[{"id":"msg-1","from":"s-2","from_name":"reviewer","to":"builder","recipient":"s-1","text":"tests pass","status":"delivered","created_at":"2026-01-01T12:00:00Z"}]}`)
+	}))
+	defer srv.Close()
+
+	cmd := NewMessagesCommand(&MockDaemonClient{isRunning: true, baseURL: srv.URL})
+	var err error
+	silenceOutput(t, func() { err = cmd.Run([]string{"s-1", "--last", "5"}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotQuery != "last=5" {
+		t.Errorf("query = %q, want last=5", gotQuery)
+	}
+}
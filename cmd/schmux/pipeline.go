@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/pkg/cli"
+)
+
+const pipelineUsage = `usage: schmux pipeline <subcommand>
+
+Subcommands:
+  list <repo> [--json]              List pipelines defined for a repo
+  create <repo> -f <file>           Create a pipeline from a JSON definition
+  delete <repo> <pipeline-id>       Delete a pipeline
+  run <repo> <pipeline-id> [--json] Start a pipeline run
+  runs [--json]                     List pipeline runs
+  status <run-id> [--json]          Show per-stage progress of a run
+  cancel <run-id>                   Cancel a running pipeline`
+
+// PipelineCommand implements the pipeline command.
+type PipelineCommand struct {
+	client cli.DaemonClient
+}
+
+// NewPipelineCommand creates a new pipeline command.
+func NewPipelineCommand(client cli.DaemonClient) *PipelineCommand {
+	return &PipelineCommand{client: client}
+}
+
+type pipelineStageRun struct {
+	StageID     string   `json:"stage_id"`
+	Name        string   `json:"name"`
+	Status      string   `json:"status"`
+	SessionIDs  []string `json:"session_ids"`
+	WorkspaceID string   `json:"workspace_id"`
+	Error       string   `json:"error"`
+}
+
+type pipelineRun struct {
+	ID         string             `json:"id"`
+	PipelineID string             `json:"pipeline_id"`
+	Name       string             `json:"name"`
+	Repo       string             `json:"repo"`
+	Status     string             `json:"status"`
+	Stages     []pipelineStageRun `json:"stages"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// Run executes the pipeline command.
+func (cmd *PipelineCommand) Run(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("%s", pipelineUsage)
+	}
+	sub, rest := args[0], args[1:]
+
+	var jsonOutput bool
+	var file string
+	var positional []string
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case "--json":
+			jsonOutput = true
+		case "-f", "--file":
+			if i+1 >= len(rest) {
+				return fmt.Errorf("flag %s requires a value", rest[i])
+			}
+			file = rest[i+1]
+			i++
+		default:
+			if strings.HasPrefix(rest[i], "-") {
+				return fmt.Errorf("unknown flag: %s", rest[i])
+			}
+			positional = append(positional, rest[i])
+		}
+	}
+
+	want := map[string]int{"list": 1, "create": 1, "delete": 2, "run": 2, "runs": 0, "status": 1, "cancel": 1}
+	n, ok := want[sub]
+	if !ok {
+		return fmt.Errorf("unknown pipeline subcommand: %s\n\n%s", sub, pipelineUsage)
+	}
+	if len(positional) != n {
+		return fmt.Errorf("%s", pipelineUsage)
+	}
+	if sub == "create" && file == "" {
+		return fmt.Errorf("required flag -f (--file) not provided")
+	}
+
+	if !cmd.client.IsRunning() {
+		return fmt.Errorf("daemon is not running. Start it with: schmux start")
+	}
+
+	switch sub {
+	case "list":
+		return cmd.list(positional[0], jsonOutput)
+	case "create":
+		return cmd.create(positional[0], file)
+	case "delete":
+		if _, err := cmd.do(http.MethodDelete, pipelinesPath(positional[0])+"/"+url.PathEscape(positional[1]), nil); err != nil {
+			return err
+		}
+		fmt.Printf("Pipeline %s deleted.\n", positional[1])
+		return nil
+	case "run":
+		body, err := cmd.do(http.MethodPost, pipelinesPath(positional[0])+"/"+url.PathEscape(positional[1])+"/run", nil)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printRawJSON(body)
+		}
+		var run pipelineRun
+		if err := json.Unmarshal(body, &run); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		fmt.Printf("Started pipeline %q as run %s.\n", run.Name, run.ID)
+		fmt.Printf("Follow progress with: schmux pipeline status %s\n", run.ID)
+		return nil
+	case "runs":
+		return cmd.runs(jsonOutput)
+	case "status":
+		body, err := cmd.do(http.MethodGet, "/api/pipeline-runs/"+url.PathEscape(positional[0]), nil)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printRawJSON(body)
+		}
+		var run pipelineRun
+		if err := json.Unmarshal(body, &run); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		printPipelineRun(run)
+		return nil
+	case "cancel":
+		if _, err := cmd.do(http.MethodPost, "/api/pipeline-runs/"+url.PathEscape(positional[0])+"/cancel", nil); err != nil {
+			return err
+		}
+		fmt.Printf("Pipeline run %s canceled.\n", positional[0])
+		return nil
+	}
+	return nil
+}
+
+func pipelinesPath(repo string) string {
+	return "/api/spawn/" + url.PathEscape(repo) + "/pipelines"
+}
+
+// do issues a request against the daemon and returns the response body,
+// turning non-2xx responses into errors.
+func (cmd *PipelineCommand) do(method, path string, body []byte) ([]byte, error) {
+	return daemonDo(cmd.client, method, path, body)
+}
+
+// daemonDo issues a JSON request against the daemon API.
+func daemonDo(client cli.DaemonClient, method, path string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, client.BaseURL()+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
+
+func (cmd *PipelineCommand) list(repo string, jsonOutput bool) error {
+	body, err := cmd.do(http.MethodGet, pipelinesPath(repo), nil)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printRawJSON(body)
+	}
+	var data struct {
+		Pipelines []struct {
+			ID     string `json:"id"`
+			Name   string `json:"name"`
+			Stages []struct {
+				ID string `json:"id"`
+			} `json:"stages"`
+		} `json:"pipelines"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(data.Pipelines) == 0 {
+		fmt.Printf("No pipelines defined for %s.\n", repo)
+		return nil
+	}
+	fmt.Printf("%-22s %-30s %s\n", "ID", "NAME", "STAGES")
+	for _, p := range data.Pipelines {
+		ids := make([]string, len(p.Stages))
+		for i, st := range p.Stages {
+			ids[i] = st.ID
+		}
+		fmt.Printf("%-22s %-30s %s\n", p.ID, p.Name, strings.Join(ids, ", "))
+	}
+	return nil
+}
+
+func (cmd *PipelineCommand) create(repo, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("%s is not valid JSON", file)
+	}
+	body, err := cmd.do(http.MethodPost, pipelinesPath(repo), data)
+	if err != nil {
+		return err
+	}
+	var p struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &p); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	fmt.Printf("Created pipeline %q (%s).\n", p.Name, p.ID)
+	return nil
+}
+
+func (cmd *PipelineCommand) runs(jsonOutput bool) error {
+	body, err := cmd.do(http.MethodGet, "/api/pipeline-runs", nil)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printRawJSON(body)
+	}
+	var data struct {
+		Runs []pipelineRun `json:"runs"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(data.Runs) == 0 {
+		fmt.Println("No pipeline runs.")
+		return nil
+	}
+	fmt.Printf("%-22s %-24s %-16s %-10s %-8s %s\n", "RUN", "PIPELINE", "REPO", "STATUS", "STAGES", "STARTED")
+	for _, run := range data.Runs {
+		done := 0
+		for _, st := range run.Stages {
+			if st.Status == "succeeded" {
+				done++
+			}
+		}
+		fmt.Printf("%-22s %-24s %-16s %-10s %-8s %s\n",
+			run.ID, run.Name, run.Repo, run.Status,
+			fmt.Sprintf("%d/%d", done, len(run.Stages)),
+			run.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+func printPipelineRun(run pipelineRun) {
+	fmt.Printf("%s (%s) — %s\n\n", run.Name, run.ID, run.Status)
+	for _, st := range run.Stages {
+		label := st.StageID
+		if st.Name != "" {
+			label = st.Name
+		}
+		fmt.Printf("  %-10s %-24s", st.Status, label)
+		if len(st.SessionIDs) > 0 {
+			fmt.Printf(" %s", strings.Join(st.SessionIDs, ", "))
+		}
+		if st.Error != "" {
+			fmt.Printf("  error: %s", st.Error)
+		}
+		fmt.Println()
+	}
+}
+
+func printRawJSON(body []byte) error {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPipelineCommand_RunArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		isRunning   bool
+		errContains string
+	}{
+		{"no subcommand", nil, true, "usage:"},
+		{"unknown subcommand", []string{"frobnicate"}, true, "unknown pipeline subcommand"},
+		{"list missing repo", []string{"list"}, true, "usage:"},
+		{"run missing pipeline", []string{"run", "schmux"}, true, "usage:"},
+		{"create missing file", []string{"create", "schmux"}, true, "-f (--file)"},
+		{"unknown flag", []string{"runs", "--bogus"}, true, "unknown flag"},
+		{"daemon not running", []string{"runs"}, false, "daemon is not running"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewPipelineCommand(&MockDaemonClient{isRunning: tt.isRunning})
+			err := cmd.Run(tt.args)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("error %q does not contain %q", err, tt.errContains)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/sergeknystautas/schmux/internal/ptyhost"
+)
+
+// runPTYHost implements the internal `schmux pty-host` command: the detached
+// supervisor the daemon launches for sessions on the pty backend. It is not
+// meant to be run by hand.
+func runPTYHost(args []string) (int, error) {
+	opts := ptyhost.Options{}
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			if i+1 >= len(args) {
+				return 1, fmt.Errorf("missing command after --")
+			}
+			opts.Command = args[i+1]
+			break
+		}
+		if i+1 >= len(args) {
+			return 1, fmt.Errorf("flag %s requires a value", args[i])
+		}
+		val := args[i+1]
+		i++
+		switch args[i-1] {
+		case "--session":
+			opts.SessionID = val
+		case "--socket":
+			opts.SocketPath = val
+		case "--dir":
+			opts.Dir = val
+		case "--cols", "--rows":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return 1, fmt.Errorf("invalid %s value: %s", args[i-1], val)
+			}
+			if args[i-1] == "--cols" {
+				opts.Cols = n
+			} else {
+				opts.Rows = n
+			}
+		default:
+			return 1, fmt.Errorf("unknown flag: %s", args[i-1])
+		}
+	}
+	if opts.SessionID == "" || opts.SocketPath == "" || opts.Command == "" {
+		return 1, fmt.Errorf("usage: schmux pty-host --session <id> --socket <path> [--dir <dir>] [--cols N --rows N] -- <command>")
+	}
+
+	// The supervisor must outlive the daemon and any terminal it came from.
+	// Catching (rather than ignoring) keeps the agent's own dispositions at
+	// their defaults, since ignored signals are inherited across exec.
+	signal.Notify(make(chan os.Signal, 1), syscall.SIGHUP, syscall.SIGINT)
+
+	host, err := ptyhost.Start(opts)
+	if err != nil {
+		return 1, err
+	}
+	return host.Wait(), nil
+}
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/pkg/cli"
+)
+
+// Exit codes reported by schmux run, besides 0 (completed) and 1 (error).
+const (
+	runExitNeedsInput = 2
+	runExitTimeout    = 124
+)
+
+// runMaxPollErrors is how many consecutive failed event polls schmux run
+// tolerates before giving up on the session.
+const runMaxPollErrors = 5
+
+// runExitError carries a non-default exit code out of RunCommand.Run.
+type runExitError struct {
+	exitCode int
+	err      error
+}
+
+func (e *runExitError) Error() string { return e.err.Error() }
+func (e *runExitError) Unwrap() error { return e.err }
+
+// RunCommand implements the run command: spawn one session, wait for it to
+// finish, report the result and exit with a matching status.
+type RunCommand struct {
+	client       cli.DaemonClient
+	pollInterval time.Duration
+}
+
+// NewRunCommand creates a new run command.
+func NewRunCommand(client cli.DaemonClient) *RunCommand {
+	return &RunCommand{client: client, pollInterval: 2 * time.Second}
+}
+
+// runDiffFile is one changed file in the run summary.
+type runDiffFile struct {
+	OldPath      string `json:"old_path,omitempty"`
+	NewPath      string `json:"new_path,omitempty"`
+	Status       string `json:"status"`
+	LinesAdded   int    `json:"lines_added"`
+	LinesRemoved int    `json:"lines_removed"`
+	IsBinary     bool   `json:"is_binary,omitempty"`
+}
+
+// runDiff is the subset of the workspace diff response schmux run reports.
+type runDiff struct {
+	Branch string        `json:"branch"`
+	Files  []runDiffFile `json:"files"`
+}
+
+// runSummary is the --json output of schmux run.
+type runSummary struct {
+	SessionID   string        `json:"session_id"`
+	WorkspaceID string        `json:"workspace_id"`
+	Target      string        `json:"target"`
+	State       string        `json:"state"`
+	Message     string        `json:"message,omitempty"`
+	TimedOut    bool          `json:"timed_out"`
+	DurationMs  int64         `json:"duration_ms"`
+	Branch      string        `json:"branch,omitempty"`
+	Files       []runDiffFile `json:"files"`
+	Patch       string        `json:"patch,omitempty"`
+	Disposed    bool          `json:"disposed"`
+	ExitCode    int           `json:"exit_code"`
+}
+
+// runStatusEvent is the subset of a status event schmux run looks at.
+type runStatusEvent struct {
+	State   string `json:"state"`
+	Message string `json:"message"`
+	Intent  string `json:"intent"`
+}
+
+// Run executes the run command.
+func (cmd *RunCommand) Run(args []string) error {
+	var (
+		targetFlag    string
+		promptFlag    string
+		workspaceFlag string
+		repoFlag      string
+		branchFlag    string
+		nicknameFlag  string
+		timeout       time.Duration
+		inputTimeout  time.Duration
+		dispose       bool
+		jsonOutput    bool
+	)
+
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.StringVar(&targetFlag, "t", "", "Run target name (required)")
+	fs.StringVar(&targetFlag, "target", "", "Run target name (required)")
+	fs.StringVar(&promptFlag, "p", "", "Prompt for the agent (required)")
+	fs.StringVar(&promptFlag, "prompt", "", "Prompt for the agent (required)")
+	fs.StringVar(&workspaceFlag, "w", "", "Workspace path (e.g., . or ~/ws/myproject-001)")
+	fs.StringVar(&workspaceFlag, "workspace", "", "Workspace path (e.g., . or ~/ws/myproject-001)")
+	fs.StringVar(&repoFlag, "r", "", "Repo name from config (for new workspace)")
+	fs.StringVar(&repoFlag, "repo", "", "Repo name from config (for new workspace)")
+	fs.StringVar(&branchFlag, "b", "main", "Git branch")
+	fs.StringVar(&branchFlag, "branch", "main", "Git branch")
+	fs.StringVar(&nicknameFlag, "n", "", "Optional session nickname")
+	fs.StringVar(&nicknameFlag, "nickname", "", "Optional session nickname")
+	fs.DurationVar(&timeout, "timeout", 0, "Give up after this long (e.g. 30m); 0 waits forever")
+	fs.DurationVar(&inputTimeout, "needs-input-timeout", 5*time.Minute, "How long the agent may wait for input before the run fails; 0 fails at once")
+	fs.BoolVar(&dispose, "dispose", false, "Dispose the session when it finishes")
+	fs.BoolVar(&jsonOutput, "json", false, "JSON output")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument: %s", fs.Arg(0))
+	}
+	if targetFlag == "" {
+		return fmt.Errorf("required flag -t (--target) not provided")
+	}
+	if promptFlag == "" {
+		return fmt.Errorf("required flag -p (--prompt) not provided")
+	}
+	if timeout < 0 {
+		return fmt.Errorf("--timeout must not be negative")
+	}
+	if inputTimeout < 0 {
+		return fmt.Errorf("--needs-input-timeout must not be negative")
+	}
+
+	if !cmd.client.IsRunning() {
+		return fmt.Errorf("daemon is not running. Start it with: schmux start")
+	}
+
+	cfg, err := cmd.client.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get config: %w", err)
+	}
+	spawner := NewSpawnCommand(cmd.client)
+
+	workspaceID := ""
+	repoURL := ""
+	if workspaceFlag != "" {
+		workspaceID, err = spawner.resolveWorkspace(workspaceFlag, cfg)
+		if err != nil {
+			return err
+		}
+	} else if repoFlag != "" {
+		repo, found := spawner.findRepo(repoFlag, cfg)
+		if !found {
+			return fmt.Errorf("repo not found in config: %s", repoFlag)
+		}
+		repoURL = repo.URL
+	} else {
+		workspaceID, repoURL, err = spawner.autoDetectWorkspace(cfg)
+		if err != nil {
+			return fmt.Errorf("please specify -w (--workspace) or -r (--repo): %w", err)
+		}
+	}
+	if _, found := spawner.findRunTarget(targetFlag, cfg); found {
+		return fmt.Errorf("%s is a command target; schmux run needs an agent that reports its status", targetFlag)
+	}
+
+	start := time.Now()
+	results, err := cmd.client.Spawn(context.Background(), cli.SpawnRequest{
+		Repo:        repoURL,
+		Branch:      branchFlag,
+		Prompt:      promptFlag,
+		Nickname:    nicknameFlag,
+		WorkspaceID: workspaceID,
+		Targets:     map[string]int{targetFlag: 1},
+	})
+	if err != nil {
+		return fmt.Errorf("spawn failed: %w", err)
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("spawn failed: no session started")
+	}
+	if results[0].Error != "" {
+		return fmt.Errorf("spawn failed: %s", results[0].Error)
+	}
+	result := results[0]
+	if !jsonOutput {
+		fmt.Fprintf(os.Stderr, "Started %s in workspace %s; waiting for it to finish...\n", result.SessionID, result.WorkspaceID)
+	}
+
+	summary := runSummary{
+		SessionID:   result.SessionID,
+		WorkspaceID: result.WorkspaceID,
+		Target:      result.Target,
+		Files:       []runDiffFile{},
+	}
+	evt, waitErr := cmd.wait(result.SessionID, timeout, inputTimeout)
+	summary.DurationMs = time.Since(start).Milliseconds()
+	if evt != nil {
+		summary.State = evt.State
+		summary.Message = evt.Message
+		if summary.Message == "" {
+			summary.Message = evt.Intent
+		}
+	}
+	summary.TimedOut = errors.Is(waitErr, context.DeadlineExceeded)
+
+	if diff, err := cmd.fetchDiff(result.WorkspaceID); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to get workspace diff: %v\n", err)
+	} else {
+		summary.Branch = diff.Branch
+		summary.Files = append(summary.Files, diff.Files...)
+	}
+	if len(summary.Files) > 0 {
+		if patch, err := cmd.fetchPatch(result.WorkspaceID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to get workspace patch: %v\n", err)
+		} else {
+			summary.Patch = patch
+		}
+	}
+
+	if dispose {
+		if err := cmd.client.DisposeSession(context.Background(), result.SessionID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to dispose session: %v\n", err)
+		} else {
+			summary.Disposed = true
+		}
+	}
+
+	exitErr := runResultError(summary, waitErr)
+	summary.ExitCode = errExitCode(exitErr)
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(summary); err != nil {
+			return err
+		}
+	} else {
+		printRunSummary(summary)
+	}
+	return exitErr
+}
+
+// wait polls the session's status events until it reaches a terminal state,
+// or has stayed in needs_input for inputTimeout: an agent that asked for input
+// may still carry on by itself, e.g. after a permission prompt times out. It
+// returns the last status event seen, and context.DeadlineExceeded when
+// timeout elapses first.
+func (cmd *RunCommand) wait(sessionID string, timeout, inputTimeout time.Duration) (*runStatusEvent, error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	path := "/api/sessions/" + url.PathEscape(sessionID) + "/events?type=status&last=1"
+
+	var last *runStatusEvent
+	var needsInputSince time.Time
+	pollErrors := 0
+	for {
+		body, err := daemonDo(cmd.client, http.MethodGet, path, nil)
+		if err != nil {
+			pollErrors++
+			if pollErrors >= runMaxPollErrors {
+				return last, fmt.Errorf("lost track of session %s: %w", sessionID, err)
+			}
+		} else {
+			pollErrors = 0
+			var events []runStatusEvent
+			if err := json.Unmarshal(body, &events); err != nil {
+				return last, fmt.Errorf("failed to parse events: %w", err)
+			}
+			if len(events) > 0 {
+				last = &events[len(events)-1]
+				switch {
+				case isRunTerminalState(last.State):
+					return last, nil
+				case last.State == "needs_input":
+					if needsInputSince.IsZero() {
+						needsInputSince = time.Now()
+					}
+					if time.Since(needsInputSince) >= inputTimeout {
+						return last, nil
+					}
+				default:
+					needsInputSince = time.Time{}
+				}
+			}
+		}
+
+		if !deadline.IsZero() && time.Now().Add(cmd.pollInterval).After(deadline) {
+			if d := time.Until(deadline); d > 0 {
+				time.Sleep(d)
+			}
+			return last, context.DeadlineExceeded
+		}
+		time.Sleep(cmd.pollInterval)
+	}
+}
+
+// isRunTerminalState reports whether an agent in state has finished. An
+// agent in needs_input is not finished yet; wait gives it a grace period.
+func isRunTerminalState(state string) bool {
+	switch state {
+	case "completed", "needs_testing", "error":
+		return true
+	}
+	return false
+}
+
+// runResultError maps the outcome of a run to the error main exits with.
+// A nil result means the agent completed.
+func runResultError(s runSummary, waitErr error) error {
+	switch {
+	case s.TimedOut:
+		return &runExitError{exitCode: runExitTimeout, err: fmt.Errorf("timed out waiting for %s (last state: %s)", s.SessionID, stateOrUnknown(s.State))}
+	case waitErr != nil:
+		return waitErr
+	case s.State == "needs_input":
+		return &runExitError{exitCode: runExitNeedsInput, err: fmt.Errorf("%s needs input: %s", s.SessionID, s.Message)}
+	case s.State == "error":
+		return fmt.Errorf("%s reported an error: %s", s.SessionID, s.Message)
+	}
+	return nil
+}
+
+func stateOrUnknown(state string) string {
+	if state == "" {
+		return "none reported"
+	}
+	return state
+}
+
+func (cmd *RunCommand) fetchDiff(workspaceID string) (*runDiff, error) {
+	body, err := daemonDo(cmd.client, http.MethodGet, "/api/diff/"+url.PathEscape(workspaceID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var diff runDiff
+	if err := json.Unmarshal(body, &diff); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &diff, nil
+}
+
+// fetchPatch returns the unified diff of the workspace's branch.
+func (cmd *RunCommand) fetchPatch(workspaceID string) (string, error) {
+	body, err := daemonDo(cmd.client, http.MethodGet, "/api/diff-patch/"+url.PathEscape(workspaceID), nil)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func printRunSummary(s runSummary) {
+	fmt.Printf("Session:   %s\n", s.SessionID)
+	fmt.Printf("Workspace: %s\n", s.WorkspaceID)
+	if s.TimedOut {
+		fmt.Printf("State:     timed out (last state: %s)\n", stateOrUnknown(s.State))
+	} else {
+		fmt.Printf("State:     %s\n", stateOrUnknown(s.State))
+	}
+	if s.Message != "" {
+		fmt.Printf("Message:   %s\n", s.Message)
+	}
+	fmt.Printf("Duration:  %s\n", (time.Duration(s.DurationMs) * time.Millisecond).Round(time.Second))
+	fmt.Println()
+
+	if len(s.Files) == 0 {
+		fmt.Println("No changes.")
+	} else {
+		added, removed := 0, 0
+		for _, f := range s.Files {
+			path := f.NewPath
+			switch {
+			case path == "":
+				path = f.OldPath
+			case f.OldPath != "" && f.OldPath != f.NewPath:
+				path = f.OldPath + " -> " + f.NewPath
+			}
+			stat := fmt.Sprintf("+%d -%d", f.LinesAdded, f.LinesRemoved)
+			if f.IsBinary {
+				stat = "binary"
+			}
+			fmt.Printf("  %-10s %-12s %s\n", f.Status, stat, path)
+			added += f.LinesAdded
+			removed += f.LinesRemoved
+		}
+		fmt.Printf("%d file(s) changed, +%d -%d\n", len(s.Files), added, removed)
+		if s.Patch != "" {
+			fmt.Println()
+			fmt.Print(s.Patch)
+			if !strings.HasSuffix(s.Patch, "\n") {
+				fmt.Println()
+			}
+		}
+	}
+	if s.Disposed {
+		fmt.Println("Session disposed.")
+	}
+}
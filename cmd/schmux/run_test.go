@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/pkg/cli"
+)
+
+func TestRunCommand_RunArgs(t *testing.T) {
+	cfg := &cli.Config{
+		Repos:      []cli.Repo{{Name: "schmux", URL: "https://github.com/user/schmux.git"}},
+		RunTargets: []cli.RunTarget{{Name: "zsh", Command: "zsh"}},
+	}
+	tests := []struct {
+		name        string
+		args        []string
+		isRunning   bool
+		errContains string
+	}{
+		{"missing target", []string{"-r", "schmux", "-p", "fix it"}, true, "-t (--target)"},
+		{"missing prompt", []string{"-r", "schmux", "-t", "claude"}, true, "-p (--prompt)"},
+		{"negative timeout", []string{"-r", "schmux", "-t", "claude", "-p", "fix it", "--timeout", "-1s"}, true, "must not be negative"},
+		{"negative needs-input timeout", []string{"-r", "schmux", "-t", "claude", "-p", "fix it", "--needs-input-timeout", "-1s"}, true, "must not be negative"},
+		{"stray argument", []string{"-t", "claude", "-p", "fix it", "extra"}, true, "unexpected argument"},
+		{"unknown repo", []string{"-r", "nope", "-t", "claude", "-p", "fix it"}, true, "repo not found"},
+		{"command target", []string{"-r", "schmux", "-t", "zsh", "-p", "fix it"}, true, "command target"},
+		{"daemon not running", []string{"-r", "schmux", "-t", "claude", "-p", "fix it"}, false, "daemon is not running"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewRunCommand(&MockDaemonClient{isRunning: tt.isRunning, config: cfg})
+			cmd.pollInterval = time.Millisecond
+			var err error
+			silenceOutput(t, func() { err = cmd.Run(tt.args) })
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("error %q does not contain %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestRunCommand_WaitsForTerminalState(t *testing.T) {
+	tests := []struct {
+		name     string
+		states   []string
+		flags    []string
+		wantCode int
+	}{
+		{"completed", []string{"working", "working", "completed"}, nil, 0},
+		{"needs testing", []string{"working", "needs_testing"}, nil, 0},
+		{"error", []string{"working", "error"}, nil, 1},
+		{"needs input", []string{"needs_input"}, []string{"--needs-input-timeout", "20ms"}, runExitNeedsInput},
+		{"needs input immediately", []string{"needs_input"}, []string{"--needs-input-timeout", "0"}, runExitNeedsInput},
+		{"needs input then completed", []string{"needs_input", "needs_input", "working", "completed"}, []string{"--needs-input-timeout", "1h"}, 0},
+		{"timeout", []string{"working"}, []string{"--timeout", "20ms"}, runExitTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var polls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasPrefix(r.URL.Path, "/api/sessions/s-1/events"):
+					n := int(polls.Add(1)) - 1
+					if n >= len(tt.states) {
+						n = len(tt.states) - 1
+					}
+					fmt.Fprintf(w, `[{"ts":"2026-01-01T00:00:00Z","type":"status","state":%q,"message":"step %d"}]`, tt.states[n], n)
+				case r.URL.Path == "/api/diff/ws-1":
+					fmt.Fprint(w, `{"workspace_id":"ws-1","branch":"fix","files":[{"new_path":"a.go","status":"modified","lines_added":3,"lines_removed":1}]}`)
+				case r.URL.Path == "/api/diff-patch/ws-1":
+					fmt.Fprint(w, "diff --git a/a.go b/a.go\n")
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			client := &MockDaemonClient{
+				isRunning:    true,
+				baseURL:      srv.URL,
+				config:       &cli.Config{Repos: []cli.Repo{{Name: "schmux", URL: "https://github.com/user/schmux.git"}}},
+				spawnResults: []cli.SpawnResult{{SessionID: "s-1", WorkspaceID: "ws-1", Target: "claude"}},
+			}
+			cmd := NewRunCommand(client)
+			cmd.pollInterval = time.Millisecond
+			args := []string{"-r", "schmux", "-t", "claude", "-p", "fix it", "--json"}
+			args = append(args, tt.flags...)
+			var err error
+			silenceOutput(t, func() { err = cmd.Run(args) })
+			if got := errExitCode(err); got != tt.wantCode {
+				t.Errorf("exit code = %d, want %d (err: %v)", got, tt.wantCode, err)
+			}
+			if client.lastSpawn == nil || client.lastSpawn.Targets["claude"] != 1 || client.lastSpawn.Prompt != "fix it" {
+				t.Errorf("unexpected spawn request: %+v", client.lastSpawn)
+			}
+		})
+	}
+}
+
+func TestRunCommand_SpawnError(t *testing.T) {
+	client := &MockDaemonClient{
+		isRunning:    true,
+		config:       &cli.Config{Repos: []cli.Repo{{Name: "schmux", URL: "https://github.com/user/schmux.git"}}},
+		spawnResults: []cli.SpawnResult{{Target: "claude", Error: "no such target"}},
+	}
+	err := NewRunCommand(client).Run([]string{"-r", "schmux", "-t", "claude", "-p", "fix it"})
+	if err == nil || !strings.Contains(err.Error(), "no such target") {
+		t.Fatalf("expected spawn error, got %v", err)
+	}
+	if errExitCode(err) != 1 {
+		t.Errorf("exit code = %d, want 1", errExitCode(err))
+	}
+}
+
+func TestPrintRunSummary_PrintsPatch(t *testing.T) {
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	printRunSummary(runSummary{
+		SessionID: "s-1",
+		State:     "completed",
+		Files:     []runDiffFile{{NewPath: "a.go", Status: "modified", LinesAdded: 1}},
+		Patch:     "diff --git a/a.go b/a.go\n+new line\n",
+	})
+
+	w.Close()
+	out, _ := io.ReadAll(r)
+	os.Stdout = oldStdout
+
+	for _, want := range []string{"1 file(s) changed, +1 -0", "diff --git a/a.go b/a.go", "+new line"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// silenceOutput runs fn with stdout and stderr discarded.
+func silenceOutput(t *testing.T, fn func()) {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = devNull, devNull
+	defer func() { os.Stdout, os.Stderr = oldStdout, oldStderr }()
+	fn()
+}
@@ -31,6 +31,7 @@ func (cmd *SpawnCommand) Run(args []string) error {
 		repoFlag      string
 		branchFlag    string
 		nicknameFlag  string
+		backendFlag   string
 		jsonOutput    bool
 	)
 
@@ -47,6 +48,7 @@ func (cmd *SpawnCommand) Run(args []string) error {
 	fs.StringVar(&branchFlag, "branch", "main", "Git branch")
 	fs.StringVar(&nicknameFlag, "n", "", "Optional session nickname")
 	fs.StringVar(&nicknameFlag, "nickname", "", "Optional session nickname")
+	fs.StringVar(&backendFlag, "backend", "", "Session backend: tmux or pty (default from config)")
 	fs.BoolVar(&jsonOutput, "json", false, "JSON output")
 
 	if err := fs.Parse(args); err != nil {
@@ -58,6 +60,10 @@ func (cmd *SpawnCommand) Run(args []string) error {
 		return fmt.Errorf("required flag -t (--target) not provided")
 	}
 
+	if backendFlag != "" && backendFlag != "tmux" && backendFlag != "pty" {
+		return fmt.Errorf("invalid --backend value: %s (want tmux or pty)", backendFlag)
+	}
+
 	// Check if daemon is running
 	if !cmd.client.IsRunning() {
 		return fmt.Errorf("daemon is not running. Start it with: schmux start")
@@ -109,6 +115,7 @@ func (cmd *SpawnCommand) Run(args []string) error {
 		Nickname:    nicknameFlag,
 		WorkspaceID: workspaceID,
 		Targets:     map[string]int{targetFlag: 1},
+		Backend:     backendFlag,
 	}
 
 	results, err := cmd.client.Spawn(context.Background(), req)
@@ -16,11 +16,13 @@ type MockDaemonClient struct {
 	scanResult        *cli.ScanResult
 	scanErr           error
 	spawnResults      []cli.SpawnResult
+	lastSpawn         *cli.SpawnRequest
 	spawnErr          error
 	disposeErr        error
 	getConfigErr      error
 	getSessionsErr    error
 	refreshOverlayErr error
+	baseURL           string // overrides BaseURL, e.g. with an httptest server
 }
 
 func (m *MockDaemonClient) IsRunning() bool {
@@ -44,6 +46,7 @@ func (m *MockDaemonClient) ScanWorkspaces(ctx context.Context) (*cli.ScanResult,
 }
 
 func (m *MockDaemonClient) Spawn(ctx context.Context, req cli.SpawnRequest) ([]cli.SpawnResult, error) {
+	m.lastSpawn = &req
 	if m.spawnErr != nil {
 		return nil, m.spawnErr
 	}
@@ -64,6 +67,9 @@ func (m *MockDaemonClient) DisposeSession(ctx context.Context, sessionID string)
 }
 
 func (m *MockDaemonClient) BaseURL() string {
+	if m.baseURL != "" {
+		return m.baseURL
+	}
 	return "http://localhost:7337"
 }
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/sergeknystautas/schmux/internal/schmuxdir"
@@ -31,16 +32,26 @@ func (c *TimelapseCommand) Run(args []string) error {
 		return c.list()
 	case "export":
 		if len(args) < 2 {
-			return fmt.Errorf("usage: schmux timelapse export <recording-id> [-o output.timelapse.cast]")
+			return fmt.Errorf("usage: schmux timelapse export <recording-id> [--format cast|svg|text|markdown] [-o output]")
 		}
-		outputPath := ""
+		outputPath, formatName := "", ""
 		for i := 2; i < len(args); i++ {
-			if args[i] == "-o" && i+1 < len(args) {
+			switch {
+			case args[i] == "-o" && i+1 < len(args):
 				outputPath = args[i+1]
-				break
+				i++
+			case args[i] == "--format" && i+1 < len(args):
+				formatName = args[i+1]
+				i++
+			case strings.HasPrefix(args[i], "--format="):
+				formatName = strings.TrimPrefix(args[i], "--format=")
 			}
 		}
-		return c.export(args[1], outputPath)
+		format, err := timelapse.ParseFormat(formatName)
+		if err != nil {
+			return err
+		}
+		return c.export(args[1], outputPath, format)
 	case "delete":
 		if len(args) < 2 {
 			return fmt.Errorf("usage: schmux timelapse delete <recording-id>")
@@ -88,7 +99,7 @@ func (c *TimelapseCommand) list() error {
 	return nil
 }
 
-func (c *TimelapseCommand) export(recordingID, outputPath string) error {
+func (c *TimelapseCommand) export(recordingID, outputPath string, format timelapse.Format) error {
 	dir := recordingsDir()
 	recordingPath := filepath.Join(dir, recordingID+".cast")
 
@@ -97,7 +108,7 @@ func (c *TimelapseCommand) export(recordingID, outputPath string) error {
 	}
 
 	if outputPath == "" {
-		outputPath = recordingID + ".timelapse.cast"
+		outputPath = recordingID + format.Ext()
 	}
 
 	fmt.Fprintf(os.Stderr, "Note: recordings may contain sensitive terminal output. Review before sharing.\n")
@@ -106,6 +117,7 @@ func (c *TimelapseCommand) export(recordingID, outputPath string) error {
 	exp := timelapse.NewExporter(recordingPath, outputPath, func(pct float64) {
 		fmt.Fprintf(os.Stderr, "\rProgress: %.0f%%", pct*100)
 	})
+	exp.SetFormat(format)
 
 	if err := exp.Export(); err != nil {
 		return fmt.Errorf("export failed: %w", err)
@@ -118,14 +130,15 @@ func (c *TimelapseCommand) export(recordingID, outputPath string) error {
 func (c *TimelapseCommand) delete(recordingID string) error {
 	dir := recordingsDir()
 	castPath := filepath.Join(dir, recordingID+".cast")
-	compressedPath := filepath.Join(dir, recordingID+".timelapse.cast")
 
 	if _, err := os.Stat(castPath); os.IsNotExist(err) {
 		return fmt.Errorf("recording not found: %s", recordingID)
 	}
 
 	os.Remove(castPath)
-	os.Remove(compressedPath) // may not exist
+	for _, format := range timelapse.Formats {
+		os.Remove(filepath.Join(dir, recordingID+format.Ext())) // may not exist
+	}
 	fmt.Printf("Deleted recording: %s\n", recordingID)
 	return nil
 }
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sergeknystautas/schmux/pkg/cli"
+)
+
+const tokenUsage = `usage: schmux token <subcommand>
+
+Subcommands:
+  create <name> -s <scope> [-s <scope>] [--json]
+                                    Create an API token (shown once)
+  list [--json]                     List API tokens
+  revoke <id|name>                  Revoke an API token
+
+Scopes:
+  spawn                             Spawn sessions, including POST /api/trigger/spawn
+  tell                              Send messages to running sessions
+  read                              Read-only API access
+
+Flags:
+  -s, --scope <scope[,scope]>       Scope granted to the token; repeatable
+  --json                            JSON output`
+
+// TokenCommand implements the token command.
+type TokenCommand struct {
+	client cli.DaemonClient
+}
+
+// NewTokenCommand creates a new token command.
+func NewTokenCommand(client cli.DaemonClient) *TokenCommand {
+	return &TokenCommand{client: client}
+}
+
+type apiTokenInfo struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// Run executes the token command.
+func (cmd *TokenCommand) Run(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("%s", tokenUsage)
+	}
+	sub, rest := args[0], args[1:]
+
+	var (
+		jsonOutput bool
+		scopes     []string
+		positional []string
+	)
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+		switch {
+		case arg == "--json":
+			jsonOutput = true
+		case arg == "-s" || arg == "--scope":
+			if sub != "create" {
+				return fmt.Errorf("unknown flag: %s", arg)
+			}
+			if i+1 >= len(rest) {
+				return fmt.Errorf("flag %s requires a value", arg)
+			}
+			i++
+			for _, s := range strings.Split(rest[i], ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					scopes = append(scopes, s)
+				}
+			}
+		case strings.HasPrefix(arg, "-"):
+			return fmt.Errorf("unknown flag: %s", arg)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	want := map[string]int{"create": 1, "list": 0, "revoke": 1}
+	n, ok := want[sub]
+	if !ok {
+		return fmt.Errorf("unknown token subcommand: %s\n\n%s", sub, tokenUsage)
+	}
+	if len(positional) != n {
+		return fmt.Errorf("%s", tokenUsage)
+	}
+	if sub == "create" && len(scopes) == 0 {
+		return fmt.Errorf("required flag -s (--scope) not provided")
+	}
+
+	if !cmd.client.IsRunning() {
+		return fmt.Errorf("daemon is not running. Start it with: schmux start")
+	}
+
+	switch sub {
+	case "create":
+		return cmd.create(positional[0], scopes, jsonOutput)
+	case "list":
+		return cmd.list(jsonOutput)
+	case "revoke":
+		if _, err := daemonDo(cmd.client, http.MethodDelete, "/api/tokens/"+url.PathEscape(positional[0]), nil); err != nil {
+			return err
+		}
+		fmt.Printf("Token %s revoked.\n", positional[0])
+		return nil
+	}
+	return nil
+}
+
+func (cmd *TokenCommand) create(name string, scopes []string, jsonOutput bool) error {
+	reqBody, _ := json.Marshal(map[string]any{"name": name, "scopes": scopes})
+	body, err := daemonDo(cmd.client, http.MethodPost, "/api/tokens", reqBody)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printRawJSON(body)
+	}
+	var resp struct {
+		Token string       `json:"token"`
+		Info  apiTokenInfo `json:"info"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	fmt.Printf("Created token %s (%s) with scopes: %s\n\n", resp.Info.Name, resp.Info.ID, strings.Join(resp.Info.Scopes, ", "))
+	fmt.Printf("  %s\n\n", resp.Token)
+	fmt.Println("Store it now; it cannot be shown again. Send it as: Authorization: Bearer <token>")
+	return nil
+}
+
+func (cmd *TokenCommand) list(jsonOutput bool) error {
+	body, err := daemonDo(cmd.client, http.MethodGet, "/api/tokens", nil)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printRawJSON(body)
+	}
+	var data struct {
+		Tokens []apiTokenInfo `json:"tokens"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(data.Tokens) == 0 {
+		fmt.Println("No API tokens.")
+		return nil
+	}
+	fmt.Printf("%-18s %-20s %-18s %s\n", "ID", "NAME", "SCOPES", "CREATED")
+	for _, t := range data.Tokens {
+		fmt.Printf("%-18s %-20s %-18s %s\n", t.ID, t.Name, strings.Join(t.Scopes, ","), t.CreatedAt)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTokenCommand_RunArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		isRunning   bool
+		errContains string
+	}{
+		{"no subcommand", nil, true, "usage:"},
+		{"unknown subcommand", []string{"rotate"}, true, "unknown token subcommand"},
+		{"create missing name", []string{"create", "-s", "spawn"}, true, "usage:"},
+		{"create missing scope", []string{"create", "ci"}, true, "-s (--scope)"},
+		{"scope on list", []string{"list", "-s", "read"}, true, "unknown flag"},
+		{"revoke missing id", []string{"revoke"}, true, "usage:"},
+		{"daemon not running", []string{"list"}, false, "daemon is not running"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewTokenCommand(&MockDaemonClient{isRunning: tt.isRunning}).Run(tt.args)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("error %q does not contain %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestTokenCommand_Create(t *testing.T) {
+	var got struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/tokens" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &got)
+		io.WriteString(w, `{"token":"schmux_abc","info":{"id":"tok-1","name":"ci","scopes":["spawn","tell"]}}`)
+	}))
+	defer srv.Close()
+
+	cmd := NewTokenCommand(&MockDaemonClient{isRunning: true, baseURL: srv.URL})
+	var err error
+	silenceOutput(t, func() { err = cmd.Run([]string{"create", "ci", "-s", "spawn,tell", "--json"}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "ci" || strings.Join(got.Scopes, ",") != "spawn,tell" {
+		t.Errorf("unexpected request: %+v", got)
+	}
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/pkg/cli"
+)
+
+const tournamentUsage = `usage: schmux tournament <subcommand>
+
+Subcommands:
+  start -r <repo> -t <target> -t <target> -p <prompt> [flags]
+                                    Race several agents on one prompt
+  list [--json]                     List tournaments
+  show <id> [--json]                Show candidates, test results and ranking
+  compare <id>                      Compare candidates now, without waiting
+  promote <id> [session-id]         Keep one candidate and dispose the others
+  cancel <id>                       Stop tracking a tournament
+
+Start flags:
+  -r, --repo <name>                 Repo name from config (required)
+  -b, --branch <branch>             Base branch (default: main)
+  -t, --target <name[:count]>       Candidate target; repeat for more targets
+  -p, --prompt <prompt>             Task given to every candidate (required)
+  --test-cmd <command>              Shell command run in each workspace to compare
+  --judge <target>                  Target that ranks the candidates
+  --criteria <text>                 Extra guidance for the judge
+  --json                            JSON output`
+
+// TournamentCommand implements the tournament command.
+type TournamentCommand struct {
+	client cli.DaemonClient
+}
+
+// NewTournamentCommand creates a new tournament command.
+func NewTournamentCommand(client cli.DaemonClient) *TournamentCommand {
+	return &TournamentCommand{client: client}
+}
+
+type tournamentCandidate struct {
+	SessionID    string   `json:"session_id"`
+	Target       string   `json:"target"`
+	Nickname     string   `json:"nickname"`
+	State        string   `json:"state"`
+	Files        []string `json:"files"`
+	LinesAdded   int      `json:"lines_added"`
+	LinesRemoved int      `json:"lines_removed"`
+	Test         *struct {
+		Passed   bool `json:"passed"`
+		ExitCode int  `json:"exit_code"`
+	} `json:"test"`
+	Rank      int    `json:"rank"`
+	Rationale string `json:"rationale"`
+	Error     string `json:"error"`
+}
+
+type tournamentInfo struct {
+	ID         string                `json:"id"`
+	Repo       string                `json:"repo"`
+	Prompt     string                `json:"prompt"`
+	Status     string                `json:"status"`
+	Candidates []tournamentCandidate `json:"candidates"`
+	Judgement  *struct {
+		Target          string `json:"target"`
+		WinnerSessionID string `json:"winner_session_id"`
+		Summary         string `json:"summary"`
+		Error           string `json:"error"`
+	} `json:"judgement"`
+	WinnerSessionID string    `json:"winner_session_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Run executes the tournament command.
+func (cmd *TournamentCommand) Run(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("%s", tournamentUsage)
+	}
+	sub, rest := args[0], args[1:]
+
+	var (
+		jsonOutput bool
+		repo       string
+		branch     = "main"
+		prompt     string
+		testCmd    string
+		judge      string
+		criteria   string
+		targets    = map[string]int{}
+		positional []string
+	)
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+		if arg == "--json" {
+			jsonOutput = true
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") {
+			positional = append(positional, arg)
+			continue
+		}
+		if sub != "start" {
+			return fmt.Errorf("unknown flag: %s", arg)
+		}
+		if i+1 >= len(rest) {
+			return fmt.Errorf("flag %s requires a value", arg)
+		}
+		value := rest[i+1]
+		i++
+		switch arg {
+		case "-r", "--repo":
+			repo = value
+		case "-b", "--branch":
+			branch = value
+		case "-p", "--prompt":
+			prompt = value
+		case "-t", "--target":
+			name, count, err := parseTournamentTarget(value)
+			if err != nil {
+				return err
+			}
+			targets[name] += count
+		case "--test-cmd":
+			testCmd = value
+		case "--judge":
+			judge = value
+		case "--criteria":
+			criteria = value
+		default:
+			return fmt.Errorf("unknown flag: %s", arg)
+		}
+	}
+
+	want := map[string][2]int{"start": {0, 0}, "list": {0, 0}, "show": {1, 1}, "compare": {1, 1}, "promote": {1, 2}, "cancel": {1, 1}}
+	n, ok := want[sub]
+	if !ok {
+		return fmt.Errorf("unknown tournament subcommand: %s\n\n%s", sub, tournamentUsage)
+	}
+	if len(positional) < n[0] || len(positional) > n[1] {
+		return fmt.Errorf("%s", tournamentUsage)
+	}
+	if sub == "start" {
+		if repo == "" {
+			return fmt.Errorf("required flag -r (--repo) not provided")
+		}
+		if strings.TrimSpace(prompt) == "" {
+			return fmt.Errorf("required flag -p (--prompt) not provided")
+		}
+		total := 0
+		for _, c := range targets {
+			total += c
+		}
+		if total < 2 {
+			return fmt.Errorf("a tournament needs at least two candidates (repeat -t, or use -t name:N)")
+		}
+	}
+
+	if !cmd.client.IsRunning() {
+		return fmt.Errorf("daemon is not running. Start it with: schmux start")
+	}
+
+	switch sub {
+	case "start":
+		opts := &cli.TournamentOptions{JudgeTarget: judge, Criteria: criteria}
+		if testCmd != "" {
+			opts.TestCommand = []string{"sh", "-c", testCmd}
+		}
+		return cmd.start(repo, branch, prompt, targets, opts, jsonOutput)
+	case "list":
+		return cmd.list(jsonOutput)
+	case "show":
+		body, err := daemonDo(cmd.client, http.MethodGet, tournamentPath(positional[0]), nil)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printRawJSON(body)
+		}
+		var t tournamentInfo
+		if err := json.Unmarshal(body, &t); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		printTournament(t)
+		return nil
+	case "compare":
+		if _, err := daemonDo(cmd.client, http.MethodPost, tournamentPath(positional[0])+"/compare", nil); err != nil {
+			return err
+		}
+		fmt.Printf("Comparing tournament %s. Check results with: schmux tournament show %s\n", positional[0], positional[0])
+		return nil
+	case "promote":
+		var reqBody []byte
+		if len(positional) == 2 {
+			reqBody, _ = json.Marshal(map[string]string{"session_id": positional[1]})
+		}
+		body, err := daemonDo(cmd.client, http.MethodPost, tournamentPath(positional[0])+"/promote", reqBody)
+		if err != nil {
+			return err
+		}
+		var t tournamentInfo
+		if err := json.Unmarshal(body, &t); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		fmt.Printf("Promoted %s; disposing %d other candidate(s).\n", t.WinnerSessionID, len(t.Candidates)-1)
+		return nil
+	case "cancel":
+		if _, err := daemonDo(cmd.client, http.MethodPost, tournamentPath(positional[0])+"/cancel", nil); err != nil {
+			return err
+		}
+		fmt.Printf("Tournament %s canceled. Candidates keep running.\n", positional[0])
+		return nil
+	}
+	return nil
+}
+
+// parseTournamentTarget splits "name" or "name:count". Target names may
+// themselves contain colons (e.g. "gpt-4o::api"), so only a numeric suffix is
+// taken as the count.
+func parseTournamentTarget(value string) (string, int, error) {
+	name, count := value, 1
+	if idx := strings.LastIndex(value, ":"); idx > 0 {
+		if n, err := strconv.Atoi(value[idx+1:]); err == nil {
+			if n < 1 {
+				return "", 0, fmt.Errorf("invalid target count in %q", value)
+			}
+			name, count = value[:idx], n
+		}
+	}
+	if name == "" {
+		return "", 0, fmt.Errorf("invalid target: %q", value)
+	}
+	return name, count, nil
+}
+
+// oneLine collapses whitespace so a prompt fits in a table cell.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func tournamentPath(id string) string {
+	return "/api/tournaments/" + url.PathEscape(id)
+}
+
+func (cmd *TournamentCommand) start(repoName, branch, prompt string, targets map[string]int, opts *cli.TournamentOptions, jsonOutput bool) error {
+	cfg, err := cmd.client.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get config: %w", err)
+	}
+	repo, found := NewSpawnCommand(cmd.client).findRepo(repoName, cfg)
+	if !found {
+		return fmt.Errorf("repo not found in config: %s", repoName)
+	}
+	results, err := cmd.client.Spawn(context.Background(), cli.SpawnRequest{
+		Repo:       repo.URL,
+		Branch:     branch,
+		Prompt:     prompt,
+		Targets:    targets,
+		Tournament: opts,
+	})
+	if err != nil {
+		return fmt.Errorf("spawn failed: %w", err)
+	}
+	if jsonOutput {
+		data, err := json.Marshal(results)
+		if err != nil {
+			return err
+		}
+		return printRawJSON(data)
+	}
+
+	tournamentID := ""
+	for _, r := range results {
+		if r.TournamentID != "" {
+			tournamentID = r.TournamentID
+		}
+	}
+	if tournamentID == "" {
+		fmt.Println("No candidates started:")
+	} else {
+		fmt.Printf("Started tournament %s:\n", tournamentID)
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Printf("  %s: error: %s\n", r.Target, r.Error)
+		} else {
+			fmt.Printf("  %s: %s (workspace %s)\n", r.Target, r.SessionID, r.WorkspaceID)
+		}
+	}
+	if tournamentID == "" {
+		return fmt.Errorf("tournament did not start")
+	}
+	fmt.Printf("Follow progress with: schmux tournament show %s\n", tournamentID)
+	return nil
+}
+
+func (cmd *TournamentCommand) list(jsonOutput bool) error {
+	body, err := daemonDo(cmd.client, http.MethodGet, "/api/tournaments", nil)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printRawJSON(body)
+	}
+	var data struct {
+		Tournaments []tournamentInfo `json:"tournaments"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(data.Tournaments) == 0 {
+		fmt.Println("No tournaments.")
+		return nil
+	}
+	fmt.Printf("%-20s %-10s %-11s %-16s %s\n", "ID", "STATUS", "CANDIDATES", "STARTED", "PROMPT")
+	for _, t := range data.Tournaments {
+		fmt.Printf("%-20s %-10s %-11d %-16s %s\n", t.ID, t.Status, len(t.Candidates),
+			t.CreatedAt.Local().Format("2006-01-02 15:04"), truncate(oneLine(t.Prompt), 50))
+	}
+	return nil
+}
+
+func printTournament(t tournamentInfo) {
+	fmt.Printf("Tournament %s (%s)\n", t.ID, t.Status)
+	fmt.Printf("Prompt: %s\n", truncate(oneLine(t.Prompt), 80))
+	if j := t.Judgement; j != nil {
+		if j.Error != "" {
+			fmt.Printf("Judge %s failed: %s\n", j.Target, j.Error)
+		} else if j.Summary != "" {
+			fmt.Printf("Judge %s: %s\n", j.Target, j.Summary)
+		}
+	}
+	fmt.Println()
+	fmt.Printf("%-5s %-28s %-14s %-10s %-6s %-12s %s\n", "RANK", "SESSION", "TARGET", "STATE", "FILES", "+/-", "TESTS")
+	for _, c := range t.Candidates {
+		rank := "-"
+		if c.Rank > 0 {
+			rank = strconv.Itoa(c.Rank)
+		}
+		if c.SessionID == t.WinnerSessionID {
+			rank += "*"
+		}
+		tests := "-"
+		if c.Test != nil {
+			tests = "pass"
+			if !c.Test.Passed {
+				tests = fmt.Sprintf("fail (%d)", c.Test.ExitCode)
+			}
+		}
+		fmt.Printf("%-5s %-28s %-14s %-10s %-6d %-12s %s\n", rank, c.SessionID, c.Target, c.State,
+			len(c.Files), fmt.Sprintf("+%d -%d", c.LinesAdded, c.LinesRemoved), tests)
+		if c.Rationale != "" {
+			fmt.Printf("      %s\n", c.Rationale)
+		}
+		if c.Error != "" {
+			fmt.Printf("      error: %s\n", c.Error)
+		}
+	}
+}
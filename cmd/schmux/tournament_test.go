@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/pkg/cli"
+)
+
+func TestTournamentCommand_RunArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		isRunning   bool
+		errContains string
+	}{
+		{"no subcommand", nil, true, "usage:"},
+		{"unknown subcommand", []string{"frobnicate"}, true, "unknown tournament subcommand"},
+		{"show missing id", []string{"show"}, true, "usage:"},
+		{"promote too many args", []string{"promote", "tn-1", "s-1", "s-2"}, true, "usage:"},
+		{"start missing repo", []string{"start", "-t", "claude:2", "-p", "fix it"}, true, "-r (--repo)"},
+		{"start missing prompt", []string{"start", "-r", "schmux", "-t", "claude:2"}, true, "-p (--prompt)"},
+		{"start one candidate", []string{"start", "-r", "schmux", "-t", "claude", "-p", "fix it"}, true, "at least two candidates"},
+		{"start bad count", []string{"start", "-r", "schmux", "-t", "claude:0", "-p", "fix it"}, true, "invalid target count"},
+		{"flag on list", []string{"list", "--judge", "x"}, true, "unknown flag"},
+		{"daemon not running", []string{"list"}, false, "daemon is not running"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewTournamentCommand(&MockDaemonClient{isRunning: tt.isRunning})
+			err := cmd.Run(tt.args)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("error %q does not contain %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestTournamentCommand_Start(t *testing.T) {
+	client := &MockDaemonClient{
+		isRunning: true,
+		config:    &cli.Config{Repos: []cli.Repo{{Name: "schmux", URL: "https://github.com/user/schmux.git"}}},
+		spawnResults: []cli.SpawnResult{
+			{SessionID: "s-1", WorkspaceID: "ws-1", Target: "claude", TournamentID: "tn-1"},
+			{SessionID: "s-2", WorkspaceID: "ws-2", Target: "codex", TournamentID: "tn-1"},
+		},
+	}
+	cmd := NewTournamentCommand(client)
+	err := cmd.Run([]string{"start", "-r", "schmux", "-t", "claude", "-t", "codex", "-p", "fix it",
+		"--test-cmd", "go test ./...", "--judge", "gpt-4o::api"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	req := client.lastSpawn
+	if req == nil {
+		t.Fatal("spawn not called")
+	}
+	if req.Repo != "https://github.com/user/schmux.git" || req.Targets["claude"] != 1 || req.Targets["codex"] != 1 {
+		t.Errorf("unexpected spawn request: %+v", req)
+	}
+	if req.Tournament == nil || req.Tournament.JudgeTarget != "gpt-4o::api" ||
+		strings.Join(req.Tournament.TestCommand, " ") != "sh -c go test ./..." {
+		t.Errorf("unexpected tournament options: %+v", req.Tournament)
+	}
+}
+
+func TestParseTournamentTarget(t *testing.T) {
+	tests := []struct {
+		in    string
+		name  string
+		count int
+	}{
+		{"claude", "claude", 1},
+		{"claude:3", "claude", 3},
+		{"gpt-4o::api", "gpt-4o::api", 1},
+		{"gpt-4o::api:2", "gpt-4o::api", 2},
+	}
+	for _, tt := range tests {
+		name, count, err := parseTournamentTarget(tt.in)
+		if err != nil || name != tt.name || count != tt.count {
+			t.Errorf("parseTournamentTarget(%q) = %q, %d, %v", tt.in, name, count, err)
+		}
+	}
+}
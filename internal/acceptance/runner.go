@@ -0,0 +1,481 @@
+// Package acceptance verifies an agent's claim that it is done. When a
+// session reports completed, the runner runs the session's acceptance
+// commands in its workspace. A failure is told back to the agent and shown
+// as the session's state; once the agent has used up its retries the
+// session is escalated to the user instead.
+package acceptance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/sergeknystautas/schmux/internal/agentmsg"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/events"
+	"github.com/sergeknystautas/schmux/internal/fileutil"
+)
+
+var (
+	ErrNotConfigured = errors.New("acceptance: session has no acceptance commands")
+	ErrRunning       = errors.New("acceptance: run in progress")
+)
+
+// Check statuses within a run.
+const (
+	CheckPending = "pending"
+	CheckRunning = "running"
+	CheckPassed  = "passed"
+	CheckFailed  = "failed"
+	CheckSkipped = "skipped"
+)
+
+const (
+	// maxRecords bounds how many sessions' acceptance state is kept. The
+	// oldest are dropped first.
+	maxRecords = 500
+	// tellLines is how many trailing output lines of a failing command are
+	// told to the agent.
+	tellLines = 15
+)
+
+// Check is one resolved acceptance command.
+type Check struct {
+	Name    string
+	Argv    []string
+	Timeout time.Duration
+}
+
+// Plan is what a session's workspace has to pass.
+type Plan struct {
+	WorkspaceID string
+	Dir         string
+	Checks      []Check
+	MaxRetries  int
+}
+
+// PlanFunc resolves a session's checks. opts are the session's per-spawn
+// options, or nil. ok is false when the session has nothing to check.
+type PlanFunc func(sessionID string, opts *contracts.AcceptanceOptions) (plan Plan, ok bool, err error)
+
+// CheckFunc runs one check in dir.
+type CheckFunc func(ctx context.Context, dir string, c Check) contracts.AcceptanceCheckResult
+
+// DeliverFunc types text into a session, like tell.
+type DeliverFunc func(sessionID, text string) error
+
+// ResultFunc is called when a run finishes, before a failure is told to the
+// agent, so the session's displayed state can follow the result.
+type ResultFunc func(a contracts.SessionAcceptance)
+
+// Runner runs acceptance commands when sessions report completed. It
+// implements events.EventHandler and is registered for "status".
+type Runner struct {
+	mu       sync.Mutex
+	path     string
+	sessions map[string]*contracts.SessionAcceptance
+
+	plan     PlanFunc
+	check    CheckFunc
+	deliver  DeliverFunc
+	onResult ResultFunc
+	onChange func()
+	logger   *log.Logger
+	ctx      context.Context // cancelled on shutdown; stops running checks
+	wg       sync.WaitGroup
+}
+
+// NewRunner creates a runner persisting acceptance state to path. Checks
+// run under ctx; a run cut short by its cancellation is left running on
+// disk, and the next runner marks it failed without counting against the
+// agent's retries.
+func NewRunner(ctx context.Context, path string, logger *log.Logger) *Runner {
+	if logger == nil {
+		logger = log.NewWithOptions(io.Discard, log.Options{})
+	}
+	r := &Runner{
+		path:     path,
+		sessions: make(map[string]*contracts.SessionAcceptance),
+		logger:   logger,
+		ctx:      ctx,
+	}
+	r.load()
+	return r
+}
+
+// SetPlanFunc sets the function used to resolve a session's checks.
+func (r *Runner) SetPlanFunc(fn PlanFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.plan = fn
+}
+
+// SetCheckFunc sets the function used to run one check.
+func (r *Runner) SetCheckFunc(fn CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.check = fn
+}
+
+// SetDeliverFunc sets the function used to tell failures to the agent.
+func (r *Runner) SetDeliverFunc(fn DeliverFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliver = fn
+}
+
+// SetResultFunc sets the callback invoked when a run finishes.
+func (r *Runner) SetResultFunc(fn ResultFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onResult = fn
+}
+
+// SetChangeCallback sets a callback invoked after any session's acceptance
+// state changes.
+func (r *Runner) SetChangeCallback(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onChange = fn
+}
+
+func (r *Runner) load() {
+	if r.path == "" {
+		return
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			r.logger.Warn("failed to read acceptance state", "err", err)
+		}
+		return
+	}
+	var list []contracts.SessionAcceptance
+	if err := json.Unmarshal(data, &list); err != nil {
+		r.logger.Warn("failed to parse acceptance state", "err", err)
+		return
+	}
+	for i := range list {
+		a := &list[i]
+		if a.Status == contracts.AcceptanceRunning {
+			a.Status = contracts.AcceptanceFailed
+			for j := range a.Checks {
+				if c := &a.Checks[j]; c.Status == CheckRunning || c.Status == CheckPending {
+					c.Status = CheckFailed
+					c.Error = "daemon restarted while the command was running"
+				}
+			}
+		}
+		r.sessions[a.SessionID] = a
+	}
+}
+
+// saveLocked persists all sessions' state. Caller must hold r.mu.
+func (r *Runner) saveLocked() {
+	if r.path == "" {
+		return
+	}
+	r.pruneLocked()
+	list := make([]contracts.SessionAcceptance, 0, len(r.sessions))
+	for _, a := range r.sessions {
+		list = append(list, *a)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].SessionID < list[j].SessionID })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		r.logger.Warn("failed to marshal acceptance state", "err", err)
+		return
+	}
+	if err := fileutil.AtomicWriteFile(r.path, data, 0600); err != nil {
+		r.logger.Warn("failed to save acceptance state", "err", err)
+	}
+}
+
+// pruneLocked drops the least recently run sessions beyond maxRecords.
+// Sessions that have not run yet are kept: they hold a spawn's commands.
+func (r *Runner) pruneLocked() {
+	if len(r.sessions) <= maxRecords {
+		return
+	}
+	list := make([]*contracts.SessionAcceptance, 0, len(r.sessions))
+	for _, a := range r.sessions {
+		if a.StartedAt != nil && a.Status != contracts.AcceptanceRunning {
+			list = append(list, a)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].StartedAt.Before(*list[j].StartedAt) })
+	for _, a := range list {
+		if len(r.sessions) <= maxRecords {
+			break
+		}
+		delete(r.sessions, a.SessionID)
+	}
+}
+
+// Register gives a spawned session its own acceptance options.
+func (r *Runner) Register(sessionID, workspaceID string, opts contracts.AcceptanceOptions) {
+	opts.Commands = append([]contracts.AcceptanceCommand(nil), opts.Commands...)
+	r.mu.Lock()
+	r.sessions[sessionID] = &contracts.SessionAcceptance{
+		SessionID:   sessionID,
+		WorkspaceID: workspaceID,
+		Options:     &opts,
+		Status:      contracts.AcceptancePending,
+	}
+	r.saveLocked()
+	r.mu.Unlock()
+	r.notify()
+}
+
+// Get returns a session's acceptance state.
+func (r *Runner) Get(sessionID string) (contracts.SessionAcceptance, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.sessions[sessionID]
+	if !ok {
+		return contracts.SessionAcceptance{}, false
+	}
+	return clone(a), true
+}
+
+// SessionInfo summarizes a session's acceptance state for its card.
+func (r *Runner) SessionInfo(sessionID string) (contracts.SessionAcceptanceInfo, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.sessions[sessionID]
+	if !ok {
+		return contracts.SessionAcceptanceInfo{}, false
+	}
+	info := contracts.SessionAcceptanceInfo{
+		Status:     a.Status,
+		Failures:   a.Failures,
+		MaxRetries: a.MaxRetries,
+	}
+	if c, ok := failedCheck(a); ok {
+		info.FailedCheck = c.Name
+	}
+	return info, true
+}
+
+// Run starts a run for sessionID now, as if it had reported completed.
+func (r *Runner) Run(sessionID string) (contracts.SessionAcceptance, error) {
+	return r.start(sessionID)
+}
+
+// Wait blocks until in-flight runs return. Once the runner's context is
+// cancelled, that is as soon as their running checks are killed.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+// HandleEvent implements events.EventHandler. A completed status starts a
+// run unless one is already in progress.
+func (r *Runner) HandleEvent(_ context.Context, sessionID string, raw events.RawEvent, data []byte) {
+	if raw.Type != "status" {
+		return
+	}
+	var evt events.StatusEvent
+	if err := json.Unmarshal(data, &evt); err != nil || evt.State != "completed" {
+		return
+	}
+	if _, err := r.start(sessionID); err != nil && !errors.Is(err, ErrNotConfigured) && !errors.Is(err, ErrRunning) {
+		r.logger.Warn("failed to start acceptance run", "session", sessionID, "err", err)
+	}
+}
+
+// start resolves sessionID's checks and runs them in the background.
+func (r *Runner) start(sessionID string) (contracts.SessionAcceptance, error) {
+	r.mu.Lock()
+	var opts *contracts.AcceptanceOptions
+	if a, ok := r.sessions[sessionID]; ok {
+		if a.Status == contracts.AcceptanceRunning {
+			r.mu.Unlock()
+			return contracts.SessionAcceptance{}, ErrRunning
+		}
+		opts = a.Options
+	}
+	planFn, checkFn := r.plan, r.check
+	r.mu.Unlock()
+	if planFn == nil || checkFn == nil {
+		return contracts.SessionAcceptance{}, ErrNotConfigured
+	}
+
+	plan, ok, err := planFn(sessionID, opts)
+	if err != nil {
+		return contracts.SessionAcceptance{}, err
+	}
+	if !ok || len(plan.Checks) == 0 {
+		return contracts.SessionAcceptance{}, ErrNotConfigured
+	}
+
+	r.mu.Lock()
+	a, ok := r.sessions[sessionID]
+	if !ok {
+		a = &contracts.SessionAcceptance{SessionID: sessionID}
+		r.sessions[sessionID] = a
+	}
+	if a.Status == contracts.AcceptanceRunning {
+		r.mu.Unlock()
+		return contracts.SessionAcceptance{}, ErrRunning
+	}
+	// A pass, or the user taking over an escalated session, starts a new
+	// round of retries.
+	if a.Status != contracts.AcceptanceFailed {
+		a.Failures = 0
+	}
+	now := time.Now().UTC()
+	a.WorkspaceID = plan.WorkspaceID
+	a.Status = contracts.AcceptanceRunning
+	a.MaxRetries = plan.MaxRetries
+	a.StartedAt = &now
+	a.FinishedAt = nil
+	a.Checks = make([]contracts.AcceptanceCheckResult, len(plan.Checks))
+	for i, c := range plan.Checks {
+		a.Checks[i] = contracts.AcceptanceCheckResult{Name: c.Name, Status: CheckPending}
+	}
+	r.saveLocked()
+	snapshot := clone(a)
+	r.wg.Add(1)
+	r.mu.Unlock()
+	r.logger.Info("acceptance run started", "session", sessionID, "checks", len(plan.Checks), "failures", snapshot.Failures)
+	r.notify()
+
+	go func() {
+		defer r.wg.Done()
+		r.run(sessionID, plan, checkFn)
+	}()
+	return snapshot, nil
+}
+
+// run executes plan's checks in order, stopping at the first failure, and
+// records the outcome.
+func (r *Runner) run(sessionID string, plan Plan, checkFn CheckFunc) {
+	failed := false
+	for i, c := range plan.Checks {
+		if failed {
+			r.updateCheck(sessionID, i, contracts.AcceptanceCheckResult{Name: c.Name, Status: CheckSkipped})
+			continue
+		}
+		r.updateCheck(sessionID, i, contracts.AcceptanceCheckResult{Name: c.Name, Status: CheckRunning})
+		result := checkFn(r.ctx, plan.Dir, c)
+		if r.ctx.Err() != nil {
+			r.logger.Info("acceptance run interrupted by shutdown", "session", sessionID, "check", c.Name)
+			return
+		}
+		result.Name = c.Name
+		r.updateCheck(sessionID, i, result)
+		failed = result.Status != CheckPassed
+	}
+
+	r.mu.Lock()
+	a, ok := r.sessions[sessionID]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	now := time.Now().UTC()
+	a.FinishedAt = &now
+	switch {
+	case !failed:
+		a.Status = contracts.AcceptancePassed
+		a.Failures = 0
+	case a.Failures >= a.MaxRetries:
+		a.Failures++
+		a.Status = contracts.AcceptanceEscalated
+	default:
+		a.Failures++
+		a.Status = contracts.AcceptanceFailed
+	}
+	r.saveLocked()
+	snapshot := clone(a)
+	onResult, deliver := r.onResult, r.deliver
+	r.mu.Unlock()
+	r.logger.Info("acceptance run finished", "session", sessionID, "status", snapshot.Status, "failures", snapshot.Failures)
+
+	if onResult != nil {
+		onResult(snapshot)
+	}
+	if snapshot.Status == contracts.AcceptanceFailed && deliver != nil {
+		if err := deliver(sessionID, TellText(snapshot)); err != nil {
+			r.logger.Warn("failed to tell acceptance failure", "session", sessionID, "err", err)
+		}
+	}
+	r.notify()
+}
+
+// updateCheck stores result as check i of sessionID's run.
+func (r *Runner) updateCheck(sessionID string, i int, result contracts.AcceptanceCheckResult) {
+	r.mu.Lock()
+	a, ok := r.sessions[sessionID]
+	if !ok || i >= len(a.Checks) {
+		r.mu.Unlock()
+		return
+	}
+	a.Checks[i] = result
+	if result.Status != CheckRunning {
+		r.saveLocked()
+	}
+	r.mu.Unlock()
+	r.notify()
+}
+
+// TellText is what a failed run tells the agent: the failing command, the
+// retries left, and the tail of its output, on one line.
+func TellText(a contracts.SessionAcceptance) string {
+	c, _ := failedCheck(&a)
+	reason := fmt.Sprintf("exit %d", c.ExitCode)
+	if c.Error != "" {
+		reason = c.Error
+	}
+	left := a.MaxRetries - a.Failures + 1
+	retries := "retries"
+	if left == 1 {
+		retries = "retry"
+	}
+	text := fmt.Sprintf("[from schmux] Acceptance check %q failed (%s), so the task is not done yet. Fix it and report completed again; %d %s left before this goes to the user.",
+		c.Name, reason, left, retries)
+
+	if tail := agentmsg.OutputTail(c.Output, tellLines); tail != "" {
+		text += " Last output: " + tail
+	}
+	return text
+}
+
+func (r *Runner) notify() {
+	r.mu.Lock()
+	fn := r.onChange
+	r.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// failedCheck returns the check that failed a's latest run.
+func failedCheck(a *contracts.SessionAcceptance) (contracts.AcceptanceCheckResult, bool) {
+	for _, c := range a.Checks {
+		if c.Status == CheckFailed {
+			return c, true
+		}
+	}
+	return contracts.AcceptanceCheckResult{}, false
+}
+
+// clone deep-copies a for callers.
+func clone(a *contracts.SessionAcceptance) contracts.SessionAcceptance {
+	out := *a
+	if a.Options != nil {
+		opts := *a.Options
+		opts.Commands = append([]contracts.AcceptanceCommand(nil), a.Options.Commands...)
+		out.Options = &opts
+	}
+	out.Checks = append([]contracts.AcceptanceCheckResult(nil), a.Checks...)
+	return out
+}
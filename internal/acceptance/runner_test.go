@@ -0,0 +1,293 @@
+package acceptance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/events"
+)
+
+// fakeWorld plans checks from a fixed list and records what the runner
+// tells and reports.
+type fakeWorld struct {
+	mu         sync.Mutex
+	checks     []Check
+	maxRetries int
+	fail       map[string]bool
+	ran        []string
+	planOpts   []*contracts.AcceptanceOptions
+	told       []string
+	results    []contracts.SessionAcceptance
+	block      chan struct{}
+}
+
+func (f *fakeWorld) plan(_ string, opts *contracts.AcceptanceOptions) (Plan, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.planOpts = append(f.planOpts, opts)
+	checks := f.checks
+	maxRetries := f.maxRetries
+	if opts != nil {
+		checks = nil
+		for _, c := range opts.Commands {
+			checks = append(checks, Check{Name: c.Name, Argv: c.Command})
+		}
+		if opts.MaxRetries != nil {
+			maxRetries = *opts.MaxRetries
+		}
+	}
+	if len(checks) == 0 {
+		return Plan{}, false, nil
+	}
+	return Plan{WorkspaceID: "ws-1", Dir: "/tmp/ws-1", Checks: checks, MaxRetries: maxRetries}, true, nil
+}
+
+func (f *fakeWorld) check(_ context.Context, _ string, c Check) contracts.AcceptanceCheckResult {
+	if f.block != nil {
+		<-f.block
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ran = append(f.ran, c.Name)
+	if f.fail[c.Name] {
+		return contracts.AcceptanceCheckResult{Status: CheckFailed, ExitCode: 1, Output: "--- FAIL: TestFoo\nexpected 1, got 2"}
+	}
+	return contracts.AcceptanceCheckResult{Status: CheckPassed}
+}
+
+func (f *fakeWorld) deliver(sessionID, text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.told = append(f.told, sessionID+": "+text)
+	return nil
+}
+
+func (f *fakeWorld) result(a contracts.SessionAcceptance) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.results = append(f.results, a)
+}
+
+func newTestRunner(t *testing.T, path string) (*Runner, *fakeWorld) {
+	t.Helper()
+	f := &fakeWorld{
+		checks:     []Check{{Name: "build"}, {Name: "tests"}, {Name: "lint"}},
+		maxRetries: 2,
+		fail:       map[string]bool{},
+	}
+	if path == "" {
+		path = filepath.Join(t.TempDir(), "acceptance.json")
+	}
+	r := NewRunner(context.Background(), path, nil)
+	r.SetPlanFunc(f.plan)
+	r.SetCheckFunc(f.check)
+	r.SetDeliverFunc(f.deliver)
+	r.SetResultFunc(f.result)
+	return r, f
+}
+
+func status(r *Runner, sessionID, state string) {
+	data := []byte(fmt.Sprintf(`{"ts":"2026-01-01T00:00:00Z","type":"status","state":%q}`, state))
+	r.HandleEvent(context.Background(), sessionID, events.RawEvent{Type: "status"}, data)
+	r.Wait()
+}
+
+func mustGet(t *testing.T, r *Runner, sessionID string) contracts.SessionAcceptance {
+	t.Helper()
+	a, ok := r.Get(sessionID)
+	if !ok {
+		t.Fatalf("no acceptance state for %s", sessionID)
+	}
+	return a
+}
+
+func TestRunner_PassesOnCompleted(t *testing.T) {
+	r, f := newTestRunner(t, "")
+
+	status(r, "s-1", "working")
+	if _, ok := r.Get("s-1"); ok {
+		t.Fatal("working status started a run")
+	}
+	status(r, "s-1", "completed")
+
+	a := mustGet(t, r, "s-1")
+	if a.Status != contracts.AcceptancePassed || a.Failures != 0 || a.StartedAt == nil || a.FinishedAt == nil {
+		t.Fatalf("acceptance = %+v, want passed", a)
+	}
+	if strings.Join(f.ran, ",") != "build,tests,lint" {
+		t.Errorf("ran %v, want every check in order", f.ran)
+	}
+	if len(f.told) != 0 {
+		t.Errorf("told %v on a pass", f.told)
+	}
+	if len(f.results) != 1 || f.results[0].Status != contracts.AcceptancePassed {
+		t.Errorf("results = %+v, want one pass", f.results)
+	}
+}
+
+func TestRunner_FailureTellsAgentThenEscalates(t *testing.T) {
+	r, f := newTestRunner(t, "")
+	f.fail["tests"] = true
+
+	status(r, "s-1", "completed")
+	a := mustGet(t, r, "s-1")
+	if a.Status != contracts.AcceptanceFailed || a.Failures != 1 {
+		t.Fatalf("acceptance = %+v, want first failure", a)
+	}
+	want := []string{CheckPassed, CheckFailed, CheckSkipped}
+	for i, c := range a.Checks {
+		if c.Status != want[i] {
+			t.Errorf("check %s status = %q, want %q", c.Name, c.Status, want[i])
+		}
+	}
+	if len(f.told) != 1 {
+		t.Fatalf("told %d times, want 1", len(f.told))
+	}
+	told := f.told[0]
+	for _, s := range []string{`"tests" failed (exit 1)`, "2 retries left", "--- FAIL: TestFoo | expected 1, got 2"} {
+		if !strings.Contains(told, s) {
+			t.Errorf("tell text missing %q:\n%s", s, told)
+		}
+	}
+	if strings.Contains(told, "\n") {
+		t.Errorf("tell text spans lines:\n%s", told)
+	}
+
+	status(r, "s-1", "completed")
+	if a := mustGet(t, r, "s-1"); a.Status != contracts.AcceptanceFailed || a.Failures != 2 {
+		t.Fatalf("acceptance = %+v, want second failure", a)
+	}
+	status(r, "s-1", "completed")
+	a = mustGet(t, r, "s-1")
+	if a.Status != contracts.AcceptanceEscalated || a.Failures != 3 {
+		t.Fatalf("acceptance = %+v, want escalated after 2 retries", a)
+	}
+	if len(f.told) != 2 {
+		t.Errorf("told %d times, want 2 (no tell on escalation)", len(f.told))
+	}
+	info, _ := r.SessionInfo("s-1")
+	if info.Status != contracts.AcceptanceEscalated || info.FailedCheck != "tests" || info.MaxRetries != 2 {
+		t.Errorf("info = %+v", info)
+	}
+
+	// Once the user steps in, the next completion starts a fresh round.
+	delete(f.fail, "tests")
+	status(r, "s-1", "completed")
+	if a := mustGet(t, r, "s-1"); a.Status != contracts.AcceptancePassed || a.Failures != 0 {
+		t.Errorf("acceptance = %+v, want passed", a)
+	}
+}
+
+func TestRunner_ZeroRetriesEscalatesImmediately(t *testing.T) {
+	r, f := newTestRunner(t, "")
+	f.maxRetries = 0
+	f.fail["build"] = true
+
+	status(r, "s-1", "completed")
+	if a := mustGet(t, r, "s-1"); a.Status != contracts.AcceptanceEscalated {
+		t.Errorf("status = %q, want escalated", a.Status)
+	}
+	if len(f.told) != 0 {
+		t.Errorf("told %v, want nothing", f.told)
+	}
+}
+
+func TestRunner_RegisteredOptionsWin(t *testing.T) {
+	r, f := newTestRunner(t, "")
+	zero := 0
+	r.Register("s-1", "ws-1", contracts.AcceptanceOptions{
+		Commands:   []contracts.AcceptanceCommand{{Name: "smoke", Command: []string{"make", "smoke"}}},
+		MaxRetries: &zero,
+	})
+	if a := mustGet(t, r, "s-1"); a.Status != contracts.AcceptancePending {
+		t.Fatalf("status = %q, want pending", a.Status)
+	}
+
+	status(r, "s-1", "completed")
+	if strings.Join(f.ran, ",") != "smoke" {
+		t.Errorf("ran %v, want the spawn's own command", f.ran)
+	}
+	if len(f.planOpts) != 1 || f.planOpts[0] == nil || f.planOpts[0].Commands[0].Name != "smoke" {
+		t.Errorf("plan options = %+v", f.planOpts)
+	}
+}
+
+func TestRunner_NotConfigured(t *testing.T) {
+	r, f := newTestRunner(t, "")
+	f.checks = nil
+
+	status(r, "s-1", "completed")
+	if _, ok := r.Get("s-1"); ok {
+		t.Error("unconfigured session has acceptance state")
+	}
+	if _, err := r.Run("s-1"); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("Run() err = %v, want ErrNotConfigured", err)
+	}
+}
+
+func TestRunner_RunWhileRunning(t *testing.T) {
+	r, f := newTestRunner(t, "")
+	f.block = make(chan struct{})
+
+	if _, err := r.Run("s-1"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if _, err := r.Run("s-1"); !errors.Is(err, ErrRunning) {
+		t.Errorf("second Run() err = %v, want ErrRunning", err)
+	}
+	close(f.block)
+	r.Wait()
+	if a := mustGet(t, r, "s-1"); a.Status != contracts.AcceptancePassed {
+		t.Errorf("status = %q, want passed", a.Status)
+	}
+}
+
+func TestRunner_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acceptance.json")
+	r, f := newTestRunner(t, path)
+	f.fail["lint"] = true
+	status(r, "s-1", "completed")
+
+	reloaded, _ := newTestRunner(t, path)
+	a := mustGet(t, reloaded, "s-1")
+	if a.Status != contracts.AcceptanceFailed || a.Failures != 1 || len(a.Checks) != 3 {
+		t.Errorf("reloaded acceptance = %+v", a)
+	}
+}
+
+func TestRunner_ShutdownLeavesRunForRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acceptance.json")
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &fakeWorld{checks: []Check{{Name: "build"}, {Name: "tests"}}, maxRetries: 2, fail: map[string]bool{}}
+	f.block = make(chan struct{})
+	r := NewRunner(ctx, path, nil)
+	r.SetPlanFunc(f.plan)
+	r.SetCheckFunc(f.check)
+	r.SetDeliverFunc(f.deliver)
+	r.SetResultFunc(f.result)
+
+	if _, err := r.Run("s-1"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	cancel()
+	close(f.block)
+	r.Wait()
+	if len(f.results) != 0 || len(f.told) != 0 {
+		t.Errorf("interrupted run reported: results %v, told %v", f.results, f.told)
+	}
+
+	reloaded, _ := newTestRunner(t, path)
+	a := mustGet(t, reloaded, "s-1")
+	if a.Status != contracts.AcceptanceFailed || a.Failures != 0 {
+		t.Errorf("reloaded acceptance = %+v, want failed without counting a failure", a)
+	}
+	if a.Checks[0].Status != CheckFailed || !strings.Contains(a.Checks[0].Error, "daemon restarted") {
+		t.Errorf("interrupted check = %+v", a.Checks[0])
+	}
+}
@@ -0,0 +1,314 @@
+// Package agentmsg routes messages agents send each other through their
+// event files. A message addresses its recipients by session ID, nickname
+// or group; each delivery is rate limited per sender and kept in a bounded,
+// persisted log.
+package agentmsg
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/fileutil"
+	"github.com/sergeknystautas/schmux/internal/redact"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// Group addresses. Groups never include the sender.
+const (
+	GroupWorkspace = "@workspace" // sessions in the sender's workspace
+	GroupRepo      = "@repo"      // sessions in any workspace of the sender's repo
+	GroupAll       = "@all"       // every running session
+)
+
+const (
+	// maxMessages bounds the delivery log.
+	maxMessages = 500
+	// maxTextRunes truncates long messages before injection.
+	maxTextRunes = 2000
+	// rateWindow is the window RatePerMinute is counted over.
+	rateWindow = time.Minute
+)
+
+// DeliverFunc types text into a session's agent.
+type DeliverFunc func(sess state.Session, text string) error
+
+// Router resolves, rate limits, delivers and records agent messages.
+type Router struct {
+	mu       sync.Mutex
+	path     string
+	messages []contracts.AgentMessage // oldest first
+	sent     map[string][]time.Time   // sender session ID -> recent delivery times
+
+	state   state.StateStore
+	deliver DeliverFunc
+	rate    func() int
+	now     func() time.Time
+	logger  *log.Logger
+}
+
+// NewRouter creates a router persisting its delivery log to path.
+func NewRouter(st state.StateStore, path string, logger *log.Logger) *Router {
+	if logger == nil {
+		logger = log.NewWithOptions(io.Discard, log.Options{})
+	}
+	r := &Router{
+		path:   path,
+		sent:   make(map[string][]time.Time),
+		state:  st,
+		rate:   func() int { return 0 },
+		now:    time.Now,
+		logger: logger,
+	}
+	r.load()
+	return r
+}
+
+// SetDeliverFunc sets how messages reach a recipient.
+func (r *Router) SetDeliverFunc(fn DeliverFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliver = fn
+}
+
+// SetRateFunc sets the source of the per-sender limit on deliveries per
+// minute (0 = unlimited). It is called for every message, so config reloads
+// take effect immediately.
+func (r *Router) SetRateFunc(fn func() int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rate = fn
+}
+
+func (r *Router) load() {
+	if r.path == "" {
+		return
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			r.logger.Warn("failed to read agent messages", "err", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &r.messages); err != nil {
+		r.logger.Warn("failed to parse agent messages", "err", err)
+	}
+}
+
+// saveLocked persists the delivery log. Caller must hold r.mu.
+func (r *Router) saveLocked() {
+	if r.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(r.messages, "", "  ")
+	if err != nil {
+		r.logger.Warn("failed to marshal agent messages", "err", err)
+		return
+	}
+	if err := fileutil.AtomicWriteFile(r.path, data, 0600); err != nil {
+		r.logger.Warn("failed to write agent messages", "err", err)
+	}
+}
+
+// Send delivers text from session from to every session to resolves to,
+// prefixed "[from <nickname>]", and returns one record per recipient.
+func (r *Router) Send(from, to, text string) []contracts.AgentMessage {
+	sender, ok := r.state.GetSession(from)
+	if !ok {
+		return nil
+	}
+	fromName := sender.ID
+	if sender.Nickname != "" {
+		fromName = Sanitize(sender.Nickname)
+	}
+	text = truncateRunes(Sanitize(text), maxTextRunes)
+	if text == "" {
+		return nil
+	}
+	base := contracts.AgentMessage{From: sender.ID, FromName: fromName, To: to, Text: text}
+
+	recipients := r.resolve(sender, to)
+	if len(recipients) == 0 {
+		msg := base
+		msg.ID = newID()
+		msg.Status = contracts.AgentMessageNoRecipient
+		msg.Error = fmt.Sprintf("no running session matches %q", to)
+		msg.CreatedAt = r.now().UTC()
+		r.record(msg)
+		return []contracts.AgentMessage{msg}
+	}
+
+	out := make([]contracts.AgentMessage, 0, len(recipients))
+	for _, rcpt := range recipients {
+		msg := base
+		msg.ID = newID()
+		msg.Recipient = rcpt.ID
+		msg.CreatedAt = r.now().UTC()
+		if deliver := r.admit(sender.ID); deliver == nil {
+			msg.Status = contracts.AgentMessageRateLimited
+			msg.Error = "sender exceeded its message rate limit"
+		} else if err := deliver(rcpt, fmt.Sprintf("[from %s] %s", fromName, text)); err != nil {
+			msg.Status = contracts.AgentMessageFailed
+			msg.Error = err.Error()
+		} else {
+			msg.Status = contracts.AgentMessageDelivered
+		}
+		if msg.Status != contracts.AgentMessageDelivered {
+			r.logger.Warn("agent message not delivered", "from", sender.ID, "to", rcpt.ID, "status", msg.Status, "err", msg.Error)
+		}
+		r.record(msg)
+		out = append(out, msg)
+	}
+	return out
+}
+
+// admit counts one delivery against sender's rate limit and returns the
+// deliver func, or nil when the sender is over its limit.
+func (r *Router) admit(sender string) DeliverFunc {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deliver := r.deliver
+	if deliver == nil {
+		deliver = func(state.Session, string) error { return fmt.Errorf("message delivery is not available") }
+	}
+	now := r.now()
+	recent := slices.DeleteFunc(r.sent[sender], func(t time.Time) bool { return now.Sub(t) >= rateWindow })
+	if limit := r.rate(); limit > 0 && len(recent) >= limit {
+		r.sent[sender] = recent
+		return nil
+	}
+	r.sent[sender] = append(recent, now)
+	return deliver
+}
+
+func (r *Router) record(msg contracts.AgentMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+	if over := len(r.messages) - maxMessages; over > 0 {
+		r.messages = slices.Delete(r.messages, 0, over)
+	}
+	r.saveLocked()
+}
+
+// resolve returns the running sessions to addresses, excluding the sender.
+// A name that is not a session ID matches every session with that nickname.
+func (r *Router) resolve(sender state.Session, to string) []state.Session {
+	var senderRepo string
+	if to == GroupRepo {
+		if ws, ok := r.state.GetWorkspace(sender.WorkspaceID); ok {
+			senderRepo = ws.Repo
+		}
+	}
+	var byID, byName []state.Session
+	for _, sess := range r.state.GetSessions() {
+		if sess.ID == sender.ID || (sess.Status != "" && sess.Status != state.SessionStatusRunning) {
+			continue
+		}
+		switch to {
+		case GroupAll:
+			byName = append(byName, sess)
+		case GroupWorkspace:
+			if sess.WorkspaceID == sender.WorkspaceID {
+				byName = append(byName, sess)
+			}
+		case GroupRepo:
+			if ws, ok := r.state.GetWorkspace(sess.WorkspaceID); ok && senderRepo != "" && ws.Repo == senderRepo {
+				byName = append(byName, sess)
+			}
+		default:
+			if sess.ID == to {
+				byID = append(byID, sess)
+			} else if sess.Nickname != "" && strings.EqualFold(sess.Nickname, to) {
+				byName = append(byName, sess)
+			}
+		}
+	}
+	if len(byID) > 0 {
+		return byID
+	}
+	return byName
+}
+
+// History returns the messages sessionID sent or received, newest first,
+// at most last of them (0 = all).
+func (r *Router) History(sessionID string, last int) []contracts.AgentMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := []contracts.AgentMessage{}
+	for i := len(r.messages) - 1; i >= 0; i-- {
+		if m := r.messages[i]; m.From == sessionID || m.Recipient == sessionID {
+			out = append(out, m)
+			if last > 0 && len(out) == last {
+				break
+			}
+		}
+	}
+	return out
+}
+
+// ansiEscape matches CSI, OSC and simple ESC sequences.
+var ansiEscape = regexp.MustCompile(`\x1b(?:\[[0-9;?]*[a-zA-Z]|\][^\x07]*(?:\x07|\x1b\\)|[()][A-Z0-9])`)
+
+// Sanitize makes agent-supplied text safe to type into another agent's
+// terminal: escape sequences and control characters are dropped, newlines
+// and tabs become spaces (a newline would submit early).
+func Sanitize(s string) string {
+	s = ansiEscape.ReplaceAllString(s, "")
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+			// skip C0/C1 controls
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// OutputTail summarizes command output for a message typed into an agent:
+// the output is redacted, then its last n non-empty lines are sanitized and
+// joined with " | " on one line. It returns "" when there is no output.
+func OutputTail(output string, n int) string {
+	output, _ = redact.String(redact.SiteTell, output)
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = Sanitize(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, " | ")
+}
+
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+func newID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("msg-%d", time.Now().UnixNano())
+	}
+	return "msg-" + hex.EncodeToString(b)
+}
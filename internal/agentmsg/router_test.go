@@ -0,0 +1,219 @@
+package agentmsg
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+type delivery struct {
+	to   string
+	text string
+}
+
+func newTestRouter(t *testing.T) (*Router, *[]delivery, string) {
+	t.Helper()
+	dir := t.TempDir()
+	st := state.New(filepath.Join(dir, "state.json"), nil)
+	for _, ws := range []state.Workspace{
+		{ID: "ws-1", Repo: "https://github.com/test/a", Branch: "main", Path: filepath.Join(dir, "ws-1")},
+		{ID: "ws-2", Repo: "https://github.com/test/a", Branch: "feat", Path: filepath.Join(dir, "ws-2")},
+		{ID: "ws-3", Repo: "https://github.com/test/b", Branch: "main", Path: filepath.Join(dir, "ws-3")},
+	} {
+		if err := st.AddWorkspace(ws); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, sess := range []state.Session{
+		{ID: "s-alice", WorkspaceID: "ws-1", Nickname: "alice", Status: state.SessionStatusRunning},
+		{ID: "s-bob", WorkspaceID: "ws-1", Nickname: "bob", Status: state.SessionStatusRunning},
+		{ID: "s-carol", WorkspaceID: "ws-2", Nickname: "carol", Status: state.SessionStatusRunning},
+		{ID: "s-dave", WorkspaceID: "ws-3", Nickname: "dave", Status: state.SessionStatusRunning},
+		{ID: "s-gone", WorkspaceID: "ws-1", Nickname: "gone", Status: state.SessionStatusStopped},
+	} {
+		if err := st.AddSession(sess); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := filepath.Join(dir, "agent-messages.json")
+	r := NewRouter(st, path, nil)
+	var got []delivery
+	r.SetDeliverFunc(func(sess state.Session, text string) error {
+		got = append(got, delivery{sess.ID, text})
+		return nil
+	})
+	return r, &got, path
+}
+
+func recipients(msgs []contracts.AgentMessage) []string {
+	var out []string
+	for _, m := range msgs {
+		out = append(out, m.Recipient)
+	}
+	return out
+}
+
+func TestRouterSend_Resolution(t *testing.T) {
+	tests := []struct {
+		name string
+		to   string
+		want []string
+	}{
+		{"session id", "s-bob", []string{"s-bob"}},
+		{"nickname", "Carol", []string{"s-carol"}},
+		{"workspace group", GroupWorkspace, []string{"s-bob"}},
+		{"repo group", GroupRepo, []string{"s-bob", "s-carol"}},
+		{"all group", GroupAll, []string{"s-bob", "s-carol", "s-dave"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _, _ := newTestRouter(t)
+			msgs := r.Send("s-alice", tt.to, "hello")
+			got := recipients(msgs)
+			if len(got) != len(tt.want) {
+				t.Fatalf("recipients = %v, want %v", got, tt.want)
+			}
+			seen := map[string]bool{}
+			for _, id := range got {
+				seen[id] = true
+			}
+			for _, id := range tt.want {
+				if !seen[id] {
+					t.Errorf("recipients = %v, missing %s", got, id)
+				}
+			}
+			for _, m := range msgs {
+				if m.Status != contracts.AgentMessageDelivered {
+					t.Errorf("status = %s, want delivered", m.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestRouterSend_DeliveryText(t *testing.T) {
+	r, got, _ := newTestRouter(t)
+	r.Send("s-alice", "bob", "line one\nline two\x1b[31m red\x07")
+	if len(*got) != 1 {
+		t.Fatalf("deliveries = %v", *got)
+	}
+	if want := "[from alice] line one line two red"; (*got)[0].text != want {
+		t.Errorf("text = %q, want %q", (*got)[0].text, want)
+	}
+}
+
+func TestRouterSend_NoRecipient(t *testing.T) {
+	r, got, _ := newTestRouter(t)
+	for _, to := range []string{"nobody", "gone", "s-alice"} {
+		msgs := r.Send("s-alice", to, "hello")
+		if len(msgs) != 1 || msgs[0].Status != contracts.AgentMessageNoRecipient {
+			t.Errorf("Send to %q = %+v, want no_recipient", to, msgs)
+		}
+	}
+	if len(*got) != 0 {
+		t.Errorf("unexpected deliveries: %v", *got)
+	}
+	if msgs := r.Send("s-unknown", "bob", "hello"); msgs != nil {
+		t.Errorf("unknown sender: got %+v, want nil", msgs)
+	}
+}
+
+func TestRouterSend_DeliveryFailure(t *testing.T) {
+	r, _, _ := newTestRouter(t)
+	r.SetDeliverFunc(func(state.Session, string) error { return errors.New("tmux gone") })
+	msgs := r.Send("s-alice", "bob", "hello")
+	if len(msgs) != 1 || msgs[0].Status != contracts.AgentMessageFailed || msgs[0].Error != "tmux gone" {
+		t.Errorf("msgs = %+v", msgs)
+	}
+}
+
+func TestRouterSend_RateLimit(t *testing.T) {
+	r, got, _ := newTestRouter(t)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	r.SetRateFunc(func() int { return 2 })
+
+	for i := 0; i < 3; i++ {
+		r.Send("s-alice", "bob", "ping")
+	}
+	if len(*got) != 2 {
+		t.Fatalf("deliveries = %d, want 2", len(*got))
+	}
+	if h := r.History("s-alice", 1); h[0].Status != contracts.AgentMessageRateLimited {
+		t.Errorf("third message status = %s, want rate_limited", h[0].Status)
+	}
+
+	// Other senders have their own budget.
+	r.Send("s-bob", "alice", "pong")
+	if len(*got) != 3 {
+		t.Errorf("bob's delivery was limited by alice's rate")
+	}
+
+	// The window slides.
+	now = now.Add(rateWindow)
+	r.Send("s-alice", "bob", "ping")
+	if len(*got) != 4 {
+		t.Errorf("delivery after window was limited")
+	}
+}
+
+func TestRouterHistory(t *testing.T) {
+	r, _, path := newTestRouter(t)
+	r.Send("s-alice", "bob", "one")
+	r.Send("s-bob", "alice", "two")
+	r.Send("s-carol", "dave", "three")
+
+	h := r.History("s-alice", 0)
+	if len(h) != 2 || h[0].Text != "two" || h[1].Text != "one" {
+		t.Errorf("history = %+v", h)
+	}
+	if h := r.History("s-alice", 1); len(h) != 1 || h[0].Text != "two" {
+		t.Errorf("history last=1 = %+v", h)
+	}
+	if h := r.History("s-none", 0); h == nil || len(h) != 0 {
+		t.Errorf("history for unknown session = %+v, want empty", h)
+	}
+
+	// The log survives a restart.
+	reloaded := NewRouter(r.state, path, nil)
+	if h := reloaded.History("s-dave", 0); len(h) != 1 || h[0].Text != "three" {
+		t.Errorf("reloaded history = %+v", h)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"  padded\n", "padded"},
+		{"a\tb\r\nc", "a b  c"},
+		{"\x1b[1;32mgreen\x1b[0m", "green"},
+		{"\x1b]0;title\x07text", "text"},
+		{"bell\x07 and \x03ctrl-c", "bell and ctrl-c"},
+		{"héllo ✓", "héllo ✓"},
+	}
+	for _, tt := range tests {
+		if got := Sanitize(tt.in); got != tt.want {
+			t.Errorf("Sanitize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestOutputTail(t *testing.T) {
+	key := "sk-ant-" + strings.Repeat("a", 30)
+	out := "one\n\x1b[31mtwo\x1b[0m\r\n\n\x1b]0;x\x07three " + key + "\nfour\n"
+	got := OutputTail(out, 3)
+	if want := "two | three [REDACTED:anthropic_key] | four"; got != want {
+		t.Errorf("OutputTail() = %q, want %q", got, want)
+	}
+	if got := OutputTail("\n \n", 3); got != "" {
+		t.Errorf("OutputTail() of blank output = %q, want empty", got)
+	}
+}
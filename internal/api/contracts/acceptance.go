@@ -0,0 +1,75 @@
+package contracts
+
+import "time"
+
+// AcceptanceOptions gives a spawn its own acceptance commands, run in the
+// workspace each time one of its sessions reports completed. They replace
+// the commands configured for the repo.
+type AcceptanceOptions struct {
+	Commands []AcceptanceCommand `json:"commands"`
+	// MaxRetries is how many times the agent is sent back to fix failures
+	// before the session is escalated to the user. Omitted uses
+	// acceptance.max_retries.
+	MaxRetries *int `json:"max_retries,omitempty"`
+}
+
+// AcceptanceCommand is one acceptance check: an argv run in the workspace
+// without a shell. A non-zero exit fails it.
+type AcceptanceCommand struct {
+	Name      string   `json:"name"`
+	Command   []string `json:"command"`
+	TimeoutMs int      `json:"timeout_ms,omitempty"`
+}
+
+// AcceptanceStatus is where a session stands in the verification loop.
+type AcceptanceStatus string
+
+const (
+	// AcceptancePending: commands are set but the agent has not reported
+	// completed yet.
+	AcceptancePending AcceptanceStatus = "pending"
+	AcceptanceRunning AcceptanceStatus = "running"
+	AcceptancePassed  AcceptanceStatus = "passed"
+	// AcceptanceFailed: the failure was told to the agent, which is
+	// expected to fix it and report completed again.
+	AcceptanceFailed AcceptanceStatus = "failed"
+	// AcceptanceEscalated: retries are used up; the session needs the user.
+	AcceptanceEscalated AcceptanceStatus = "escalated"
+)
+
+// AcceptanceCheckResult is one command within a run.
+type AcceptanceCheckResult struct {
+	Name string `json:"name"`
+	// Status is "pending", "running", "passed", "failed" or "skipped".
+	Status     string `json:"status"`
+	ExitCode   int    `json:"exit_code,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	// Output is the tail of the command's combined stdout and stderr.
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"` // set when the command could not run or timed out
+}
+
+// SessionAcceptance is a session's verification state: its latest run and
+// how many runs in a row have failed.
+type SessionAcceptance struct {
+	SessionID   string `json:"session_id"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	// Options are the spawn's own commands; nil uses the repo's.
+	Options    *AcceptanceOptions      `json:"options,omitempty"`
+	Status     AcceptanceStatus        `json:"status"`
+	Failures   int                     `json:"failures,omitempty"`
+	MaxRetries int                     `json:"max_retries"`
+	Checks     []AcceptanceCheckResult `json:"checks,omitempty"`
+	StartedAt  *time.Time              `json:"started_at,omitempty"`
+	FinishedAt *time.Time              `json:"finished_at,omitempty"`
+}
+
+// SessionAcceptanceInfo summarizes a session's verification state for the
+// session card. The full run is at GET /api/sessions/{id}/acceptance.
+type SessionAcceptanceInfo struct {
+	Status     AcceptanceStatus `json:"status"`
+	Failures   int              `json:"failures,omitempty"`
+	MaxRetries int              `json:"max_retries"`
+	// FailedCheck names the command that failed the latest run.
+	FailedCheck string `json:"failed_check,omitempty"`
+}
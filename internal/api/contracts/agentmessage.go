@@ -0,0 +1,38 @@
+package contracts
+
+import "time"
+
+// AgentMessageStatus is the outcome of delivering an agent message to one
+// recipient.
+type AgentMessageStatus string
+
+const (
+	AgentMessageDelivered   AgentMessageStatus = "delivered"
+	AgentMessageFailed      AgentMessageStatus = "failed"
+	AgentMessageRateLimited AgentMessageStatus = "rate_limited"
+	AgentMessageNoRecipient AgentMessageStatus = "no_recipient"
+)
+
+// AgentMessage records one delivery of a message an agent sent through its
+// event file. A group message is recorded once per recipient.
+type AgentMessage struct {
+	ID   string `json:"id"`
+	From string `json:"from"` // sending session ID
+	// FromName is the sender's nickname (or session ID), as shown to the
+	// recipient in the "[from <name>]" prefix.
+	FromName string `json:"from_name"`
+	// To is the address as written by the sender: a session ID, nickname
+	// or group.
+	To        string             `json:"to"`
+	Recipient string             `json:"recipient,omitempty"` // resolved session ID
+	Text      string             `json:"text"`
+	Status    AgentMessageStatus `json:"status"`
+	Error     string             `json:"error,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// AgentMessagesResponse is returned by GET /api/sessions/{id}/messages:
+// messages the session sent or received, newest first.
+type AgentMessagesResponse struct {
+	Messages []AgentMessage `json:"messages"`
+}
@@ -0,0 +1,39 @@
+package contracts
+
+// APITokenInfo describes an API token. The token itself is only returned
+// once, by CreateAPITokenResponse.
+type APITokenInfo struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// APITokensResponse is returned by GET /api/tokens.
+type APITokensResponse struct {
+	Tokens []APITokenInfo `json:"tokens"`
+}
+
+// CreateAPITokenRequest is the body of POST /api/tokens.
+type CreateAPITokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// CreateAPITokenResponse carries a newly created token. Token cannot be
+// retrieved again.
+type CreateAPITokenResponse struct {
+	Token string       `json:"token"`
+	Info  APITokenInfo `json:"info"`
+}
+
+// TriggerSpawnRequest is the body of POST /api/trigger/spawn: a quick launch
+// preset started in a new workspace, with its prompt rendered against
+// Variables.
+type TriggerSpawnRequest struct {
+	Repo        string            `json:"repo"`             // repo name or URL from config
+	Branch      string            `json:"branch,omitempty"` // defaults to the repo's default branch
+	QuickLaunch string            `json:"quick_launch"`
+	Variables   map[string]string `json:"variables,omitempty"`
+	Nickname    string            `json:"nickname,omitempty"`
+}
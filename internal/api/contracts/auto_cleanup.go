@@ -0,0 +1,26 @@
+package contracts
+
+import "time"
+
+// AutoCleanupReport lists the workspaces auto-cleanup would dispose, as of
+// now. It is computed on request, so it can preview the policy before it is
+// enabled.
+type AutoCleanupReport struct {
+	Enabled    bool                   `json:"enabled"`
+	DryRun     bool                   `json:"dry_run"`
+	GraceHours int                    `json:"grace_hours"`
+	Candidates []AutoCleanupCandidate `json:"candidates"`
+}
+
+// AutoCleanupCandidate is a clean, idle workspace whose work has landed.
+// Reason is "merged" (HEAD is contained in the default branch), "pr_merged"
+// or "pr_closed". EligibleSince and CleanupAt are set once the running
+// policy has seen the workspace eligible.
+type AutoCleanupCandidate struct {
+	WorkspaceID   string     `json:"workspace_id"`
+	Repo          string     `json:"repo"`
+	Branch        string     `json:"branch"`
+	Reason        string     `json:"reason"`
+	EligibleSince *time.Time `json:"eligible_since,omitempty"`
+	CleanupAt     *time.Time `json:"cleanup_at,omitempty"`
+}
@@ -0,0 +1,39 @@
+package contracts
+
+import "time"
+
+// Checkpoint is a snapshot of a workspace's working tree, including
+// untracked files, stored as a commit under a hidden ref. Diff stats are
+// against Base, the HEAD the workspace was on when the checkpoint was taken.
+type Checkpoint struct {
+	Seq          int       `json:"seq"`
+	Ref          string    `json:"ref"`
+	Commit       string    `json:"commit"`
+	Base         string    `json:"base,omitempty"` // empty when the branch had no commits
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
+	FilesChanged int       `json:"files_changed"`
+	LinesAdded   int       `json:"lines_added"`
+	LinesRemoved int       `json:"lines_removed"`
+}
+
+// CheckpointsResponse is returned by GET /api/workspaces/{id}/checkpoints,
+// newest first.
+type CheckpointsResponse struct {
+	Checkpoints []Checkpoint `json:"checkpoints"`
+}
+
+// CheckpointRestoreRequest is the body of
+// POST /api/workspaces/{id}/checkpoints/{seq}/restore. An empty Path
+// restores the whole workspace.
+type CheckpointRestoreRequest struct {
+	Path string `json:"path,omitempty"`
+}
+
+// CheckpointRestoreResponse reports a restore. Backup is the checkpoint
+// taken of the working tree just before it was overwritten.
+type CheckpointRestoreResponse struct {
+	Restored *Checkpoint `json:"restored"`
+	Backup   *Checkpoint `json:"backup,omitempty"`
+	Path     string      `json:"path,omitempty"`
+}
@@ -0,0 +1,47 @@
+package contracts
+
+// CostRow is token usage and spend aggregated under one key of
+// GET /api/costs. Entries without the grouping key (e.g. oneshot calls when
+// grouping by session) share the empty key.
+type CostRow struct {
+	Key              string  `json:"key"`
+	InputTokens      int64   `json:"input_tokens"`
+	OutputTokens     int64   `json:"output_tokens"`
+	CacheReadTokens  int64   `json:"cache_read_tokens"`
+	CacheWriteTokens int64   `json:"cache_write_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+	// Unpriced is set when some of the usage had no registry price, so
+	// CostUSD understates the spend.
+	Unpriced bool `json:"unpriced,omitempty"`
+}
+
+// CostSessionBudget is a session at or over the session budget's warning
+// threshold.
+type CostSessionBudget struct {
+	SessionID string  `json:"session_id"`
+	CostUSD   float64 `json:"cost_usd"`
+	State     string  `json:"state"` // "warning" or "exceeded"
+}
+
+// CostBudgetStatus reports the configured budgets and where spend stands
+// against them. Zero limits are off.
+type CostBudgetStatus struct {
+	SessionUSD  float64 `json:"session_usd"`
+	DailyUSD    float64 `json:"daily_usd"`
+	WarnPercent int     `json:"warn_percent"`
+	Today       string  `json:"today"`
+	TodayUSD    float64 `json:"today_usd"`
+	DailyState  string  `json:"daily_state"` // "ok", "warning" or "exceeded"
+	// Sessions lists sessions at or over the warning threshold, costliest
+	// first.
+	Sessions []CostSessionBudget `json:"sessions,omitempty"`
+}
+
+// CostsResponse is returned by GET /api/costs.
+type CostsResponse struct {
+	GroupBy string           `json:"group_by"`
+	Since   string           `json:"since"` // first day included, YYYY-MM-DD
+	Rows    []CostRow        `json:"rows"`  // costliest first
+	Total   CostRow          `json:"total"`
+	Budget  CostBudgetStatus `json:"budget"`
+}
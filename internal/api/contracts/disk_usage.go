@@ -0,0 +1,33 @@
+package contracts
+
+import "time"
+
+// DiskUsage is the disk schmux uses, as last measured. Workspaces and repo
+// bases are re-measured in the background when they change or go stale, so
+// sizes may lag by a poll or two; a nil MeasuredAt means not measured yet.
+type DiskUsage struct {
+	TotalBytes      int64                `json:"total_bytes"`
+	QuotaBytes      int64                `json:"quota_bytes"` // 0 = no quota
+	RecordingsBytes int64                `json:"recordings_bytes"`
+	Workspaces      []WorkspaceDiskUsage `json:"workspaces"`
+	RepoBases       []RepoBaseDiskUsage  `json:"repo_bases"`
+}
+
+// WorkspaceDiskUsage is one workspace directory's size.
+type WorkspaceDiskUsage struct {
+	WorkspaceID string     `json:"workspace_id"`
+	Repo        string     `json:"repo"`
+	Branch      string     `json:"branch"`
+	Status      string     `json:"status"`
+	Bytes       int64      `json:"bytes"`
+	MeasuredAt  *time.Time `json:"measured_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+}
+
+// RepoBaseDiskUsage is one bare clone's size. Worktree workspaces share it.
+type RepoBaseDiskUsage struct {
+	Repo       string     `json:"repo"`
+	Path       string     `json:"path"`
+	Bytes      int64      `json:"bytes"`
+	MeasuredAt *time.Time `json:"measured_at,omitempty"`
+}
@@ -7,10 +7,16 @@ type OneshotLogRecord struct {
 	TS          string `json:"ts"`                     // RFC3339 start time
 	Type        string `json:"type"`                   // schema label, e.g. "commit-message"
 	Transport   string `json:"transport,omitempty"`    // "cli" or "api"
-	Model       string `json:"model,omitempty"`        // model id (targetName, ::api stripped)
+	Model       string `json:"model,omitempty"`        // model id (targetName, ::api stripped; or the model the agent reported)
 	Workspace   string `json:"workspace,omitempty"`    // basename of the call's dir; "" when none
 	PromptChars int    `json:"prompt_chars,omitempty"` // len(prompt)
 	ElapsedMS   int64  `json:"elapsed_ms,omitempty"`
 	OK          bool   `json:"ok"`
 	Error       string `json:"error,omitempty"` // error string on failure
+
+	// Token usage, when the transport reports it.
+	InputTokens      int64 `json:"input_tokens,omitempty"`
+	OutputTokens     int64 `json:"output_tokens,omitempty"`
+	CacheReadTokens  int64 `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int64 `json:"cache_write_tokens,omitempty"`
 }
@@ -0,0 +1,121 @@
+package contracts
+
+import "time"
+
+// PipelineTrigger is a session status that fires a pipeline edge.
+type PipelineTrigger string
+
+const (
+	PipelineTriggerCompleted    PipelineTrigger = "completed"
+	PipelineTriggerNeedsTesting PipelineTrigger = "needs_testing"
+	PipelineTriggerError        PipelineTrigger = "error"
+)
+
+// PipelineStage is one node of a pipeline DAG. Each stage is a spawn request
+// template; Repo and Branch default to the pipeline's when empty.
+type PipelineStage struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Spawn is the request issued when the stage starts.
+	Spawn SpawnRequest `json:"spawn"`
+	// After lists upstream stage IDs that must all succeed before this stage
+	// starts. Stages with no After entries start when the run starts.
+	After []string `json:"after,omitempty"`
+	// SucceedOn lists the statuses that count as success for this stage's
+	// sessions. Defaults to ["completed"].
+	SucceedOn []PipelineTrigger `json:"succeed_on,omitempty"`
+	// OnFailure names an alternate stage to start when this stage reports
+	// error. Alternate stages only run through a failure branch; when one
+	// succeeds, stages downstream of the failed stage proceed as if it had
+	// succeeded. Empty halts the run on failure.
+	OnFailure string `json:"on_failure,omitempty"`
+	// ReuseWorkspace spawns into the workspace of the first upstream stage
+	// (or, for an alternate stage, the failed stage) instead of resolving one
+	// from Repo/Branch.
+	ReuseWorkspace bool `json:"reuse_workspace,omitempty"`
+}
+
+// Pipeline is a named DAG of spawn stages stored per repo.
+type Pipeline struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Repo      string          `json:"repo,omitempty"`
+	Branch    string          `json:"branch,omitempty"`
+	Stages    []PipelineStage `json:"stages"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// PipelineRunStatus is the lifecycle state of a pipeline run.
+type PipelineRunStatus string
+
+const (
+	PipelineRunRunning   PipelineRunStatus = "running"
+	PipelineRunCompleted PipelineRunStatus = "completed"
+	PipelineRunFailed    PipelineRunStatus = "failed"
+	PipelineRunCanceled  PipelineRunStatus = "canceled"
+)
+
+// PipelineStageStatus is the lifecycle state of one stage within a run.
+type PipelineStageStatus string
+
+const (
+	PipelineStagePending   PipelineStageStatus = "pending"
+	PipelineStageSpawning  PipelineStageStatus = "spawning"
+	PipelineStageRunning   PipelineStageStatus = "running"
+	PipelineStageSucceeded PipelineStageStatus = "succeeded"
+	PipelineStageFailed    PipelineStageStatus = "failed"
+	PipelineStageSkipped   PipelineStageStatus = "skipped"
+)
+
+// PipelineStageRun tracks one stage's progress within a run.
+type PipelineStageRun struct {
+	StageID     string              `json:"stage_id"`
+	Name        string              `json:"name,omitempty"`
+	Status      PipelineStageStatus `json:"status"`
+	SessionIDs  []string            `json:"session_ids,omitempty"`
+	WorkspaceID string              `json:"workspace_id,omitempty"`
+	// Done holds session IDs that have reported a success status.
+	Done       []string   `json:"done,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// PipelineRun is one execution of a pipeline. The pipeline definition is
+// snapshotted at start so edits do not affect runs in flight.
+type PipelineRun struct {
+	ID         string             `json:"id"`
+	PipelineID string             `json:"pipeline_id"`
+	Name       string             `json:"name"`
+	Repo       string             `json:"repo"`
+	Status     PipelineRunStatus  `json:"status"`
+	Stages     []PipelineStageRun `json:"stages"`
+	Pipeline   Pipeline           `json:"pipeline"`
+	CreatedAt  time.Time          `json:"created_at"`
+	FinishedAt *time.Time         `json:"finished_at,omitempty"`
+}
+
+// --- API request/response types ---
+
+// PipelinesResponse is the body for GET /api/spawn/{repo}/pipelines.
+type PipelinesResponse struct {
+	Pipelines []Pipeline `json:"pipelines"`
+}
+
+// PipelineRunsResponse is the body for GET /api/pipeline-runs.
+type PipelineRunsResponse struct {
+	Runs []PipelineRun `json:"runs"`
+}
+
+// SessionPipelineInfo links a session to the pipeline stage that spawned it.
+type SessionPipelineInfo struct {
+	RunID        string              `json:"run_id"`
+	PipelineName string              `json:"pipeline_name"`
+	StageID      string              `json:"stage_id"`
+	StageName    string              `json:"stage_name,omitempty"`
+	StageIndex   int                 `json:"stage_index"`
+	StageCount   int                 `json:"stage_count"`
+	RunStatus    PipelineRunStatus   `json:"run_status"`
+	StageStatus  PipelineStageStatus `json:"stage_status"`
+}
@@ -0,0 +1,32 @@
+package contracts
+
+import "time"
+
+// PushGateRun is the most recent run of a repo's push gates in a workspace.
+// It is stored on the workspace and updated while the gates run, so the
+// dashboard sees output as it streams.
+type PushGateRun struct {
+	// Trigger is the push that ran the gates: "push_to_branch",
+	// "sync_to_main" or "push_commits".
+	Trigger string `json:"trigger"`
+	// Status is "running", "passed", "failed" or "skipped" (overridden
+	// without running).
+	Status     string           `json:"status"`
+	HeadSHA    string           `json:"head_sha,omitempty"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+	Overridden bool             `json:"overridden,omitempty"` // pushed despite failing or skipped gates
+	Gates      []PushGateResult `json:"gates"`
+}
+
+// PushGateResult is one gate within a run.
+type PushGateResult struct {
+	Name string `json:"name"`
+	// Status is "pending", "running", "passed", "failed" or "skipped".
+	Status     string `json:"status"`
+	ExitCode   int    `json:"exit_code,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	// Output is the tail of the gate's combined stdout and stderr.
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"` // set when the command could not run or timed out
+}
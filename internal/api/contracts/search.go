@@ -0,0 +1,29 @@
+package contracts
+
+import "time"
+
+// SearchResult is one line that matched a GET /api/search query.
+type SearchResult struct {
+	SessionID   string `json:"session_id"`
+	Nickname    string `json:"nickname,omitempty"`
+	WorkspaceID string `json:"workspace_id"`
+	Repo        string `json:"repo,omitempty"` // configured repo name, or URL if unknown
+	Target      string `json:"target"`
+	// Kind is where the line came from: "output" (terminal output, escape
+	// sequences stripped), "event" (the session's JSONL event file) or
+	// "prompt" (the spawn prompt).
+	Kind string `json:"kind"`
+	// Seq is the output-log sequence number the line starts at. Only set for
+	// output matches; the terminal WebSocket can replay from it.
+	Seq       *uint64   `json:"seq,omitempty"`
+	EventType string    `json:"event_type,omitempty"` // for event matches: status, failure, ...
+	Time      time.Time `json:"time"`
+	Text      string    `json:"text"`
+}
+
+// SearchResponse is returned by GET /api/search, newest matches first.
+type SearchResponse struct {
+	Results []SearchResult `json:"results"`
+	// Truncated is set when more lines matched than the limit allowed.
+	Truncated bool `json:"truncated"`
+}
@@ -15,6 +15,7 @@ type SessionResponseItem struct {
 	AttachCmd    string `json:"attach_cmd"`
 	TmuxSocket   string `json:"tmux_socket,omitempty"`
 	TmuxSession  string `json:"tmux_session,omitempty"`
+	Backend      string `json:"backend,omitempty"` // "pty" for sessions on the pty backend; empty for tmux
 	NudgeState   string `json:"nudge_state,omitempty"`
 	NudgeSummary string `json:"nudge_summary,omitempty"`
 	NudgeSeq     uint64 `json:"nudge_seq,omitempty"`
@@ -37,6 +38,18 @@ type SessionResponseItem struct {
 	// ResumeID is the harness-native conversation id; when present, the session
 	// can be restarted (dispose + resume-by-id).
 	ResumeID string `json:"resume_id,omitempty"`
+	// Pipeline is set when the session was spawned by a pipeline stage.
+	Pipeline *SessionPipelineInfo `json:"pipeline,omitempty"`
+	// Tournament is set when the session competes in a best-of-N tournament.
+	Tournament *SessionTournamentInfo `json:"tournament,omitempty"`
+	// Acceptance is set when the session has acceptance commands.
+	Acceptance *SessionAcceptanceInfo `json:"acceptance,omitempty"`
+	// QueuePosition is the 1-based place in the spawn queue while status is
+	// "queued"; QueueETA is the estimated start time (RFC3339), when known.
+	QueuePosition int    `json:"queue_position,omitempty"`
+	QueueETA      string `json:"queue_eta,omitempty"`
+	// CostUSD is the session's accounted LLM spend so far.
+	CostUSD float64 `json:"cost_usd,omitempty"`
 }
 
 // SessionModelInfo contains model metadata for a session.
@@ -110,7 +123,10 @@ type WorkspaceResponseItem struct {
 	Previews                []PreviewResponse     `json:"previews,omitempty"`
 	Tabs                    []Tab                 `json:"tabs"`
 	ResolveConflicts        []ResolveConflict     `json:"resolve_conflicts,omitempty"`
+	PushGates               *PushGateRun          `json:"push_gates,omitempty"` // most recent pre-push gate run
+	Setup                   *WorkspaceSetupRun    `json:"setup,omitempty"`      // most recent setup command run
 	Status                  string                `json:"status,omitempty"`
 	Backburner              bool                  `json:"backburner,omitempty"`
+	Protected               bool                  `json:"protected,omitempty"` // never disposed by auto-cleanup
 	IntentShared            bool                  `json:"intent_shared,omitempty"`
 }
@@ -22,4 +22,12 @@ type SpawnRequest struct {
 	ImageAttachments []string       `json:"image_attachments,omitempty"` // base64-encoded PNGs, max 5
 	IntentShared     bool           `json:"intent_shared,omitempty"`     // optional: share workspace intent with team via repofeed
 	Fence            bool           `json:"fence,omitempty"`             // OS-level fence sandbox for this spawn (local only). For descriptor-backed harnesses, also enables skip-approvals. Absent/false = off.
+	Priority         int            `json:"priority,omitempty"`          // queue priority when concurrency limits defer the spawn; higher starts first
+	Backend          string         `json:"backend,omitempty"`           // session backend for local spawns: "tmux" or "pty". Empty = config default.
+	// Tournament, when set, groups the spawned sessions into a best-of-N
+	// tournament. Requires two or more target instances and no workspace_id.
+	Tournament *TournamentOptions `json:"tournament,omitempty"`
+	// Acceptance, when set, replaces the repo's acceptance commands for the
+	// spawned sessions.
+	Acceptance *AcceptanceOptions `json:"acceptance,omitempty"`
 }
@@ -0,0 +1,113 @@
+package contracts
+
+import "time"
+
+// TournamentOptions turns a multi-target spawn into a best-of-N tournament:
+// every candidate gets its own workspace, and once all of them report
+// completed the tournament compares their results.
+type TournamentOptions struct {
+	// TestCommand is an argv run in each candidate workspace during
+	// comparison. Empty skips tests.
+	TestCommand []string `json:"test_command,omitempty"`
+	// JudgeTarget is a oneshot target (or "<model>::api" direct-HTTP target)
+	// asked to rank the candidates. Empty skips judging.
+	JudgeTarget string `json:"judge_target,omitempty"`
+	// Criteria is extra guidance for the judge, e.g. "prefer minimal diffs".
+	Criteria string `json:"criteria,omitempty"`
+}
+
+// TournamentStatus is the lifecycle state of a tournament.
+type TournamentStatus string
+
+const (
+	TournamentRunning   TournamentStatus = "running"
+	TournamentComparing TournamentStatus = "comparing"
+	TournamentReady     TournamentStatus = "ready"
+	TournamentPromoted  TournamentStatus = "promoted"
+	TournamentCanceled  TournamentStatus = "canceled"
+)
+
+// TournamentCandidateState is the last state a candidate's session reported.
+type TournamentCandidateState string
+
+const (
+	TournamentCandidateRunning   TournamentCandidateState = "running"
+	TournamentCandidateCompleted TournamentCandidateState = "completed"
+	TournamentCandidateError     TournamentCandidateState = "error"
+	TournamentCandidateDisposed  TournamentCandidateState = "disposed"
+)
+
+// TournamentTestResult is the outcome of the test command in one workspace.
+type TournamentTestResult struct {
+	Passed     bool  `json:"passed"`
+	ExitCode   int   `json:"exit_code"`
+	DurationMs int64 `json:"duration_ms"`
+	// Output is the tail of combined stdout/stderr.
+	Output string `json:"output,omitempty"`
+}
+
+// TournamentCandidate is one sibling session and its comparison results.
+type TournamentCandidate struct {
+	SessionID   string                   `json:"session_id"`
+	WorkspaceID string                   `json:"workspace_id"`
+	Target      string                   `json:"target"`
+	Nickname    string                   `json:"nickname,omitempty"`
+	Branch      string                   `json:"branch,omitempty"`
+	State       TournamentCandidateState `json:"state"`
+	// Files lists paths the candidate touched relative to the fork point.
+	Files        []string              `json:"files,omitempty"`
+	LinesAdded   int                   `json:"lines_added"`
+	LinesRemoved int                   `json:"lines_removed"`
+	Test         *TournamentTestResult `json:"test,omitempty"`
+	// Rank is the judge's 1-based ranking; 0 when unranked.
+	Rank      int    `json:"rank,omitempty"`
+	Rationale string `json:"rationale,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// TournamentJudgement is the outcome of the judge call.
+type TournamentJudgement struct {
+	Target          string `json:"target"`
+	WinnerSessionID string `json:"winner_session_id,omitempty"`
+	Summary         string `json:"summary,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+// Tournament groups the sibling sessions of one best-of-N spawn.
+type Tournament struct {
+	ID         string                `json:"id"`
+	Repo       string                `json:"repo"`
+	Branch     string                `json:"branch,omitempty"`
+	Prompt     string                `json:"prompt"`
+	Options    TournamentOptions     `json:"options"`
+	Status     TournamentStatus      `json:"status"`
+	Candidates []TournamentCandidate `json:"candidates"`
+	Judgement  *TournamentJudgement  `json:"judgement,omitempty"`
+	// WinnerSessionID is set once a candidate is promoted.
+	WinnerSessionID string     `json:"winner_session_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ComparedAt      *time.Time `json:"compared_at,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+}
+
+// --- API request/response types ---
+
+// TournamentsResponse is the body for GET /api/tournaments.
+type TournamentsResponse struct {
+	Tournaments []Tournament `json:"tournaments"`
+}
+
+// TournamentPromoteRequest is the body for POST /api/tournaments/{id}/promote.
+// An empty SessionID promotes the judge's pick.
+type TournamentPromoteRequest struct {
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// SessionTournamentInfo links a session to the tournament it competes in.
+type SessionTournamentInfo struct {
+	TournamentID string           `json:"tournament_id"`
+	Status       TournamentStatus `json:"status"`
+	Rank         int              `json:"rank,omitempty"`
+	Candidates   int              `json:"candidates"`
+	Winner       bool             `json:"winner,omitempty"`
+}
@@ -0,0 +1,58 @@
+package contracts
+
+import "time"
+
+// Webhook event types sent to outbound webhook endpoints.
+const (
+	WebhookEventSessionStatus     = "session.status"
+	WebhookEventWorkspaceCreated  = "workspace.created"
+	WebhookEventWorkspaceDisposed = "workspace.disposed"
+	WebhookEventBuildTransition   = "build.transition"
+	WebhookEventPing              = "ping"
+)
+
+// WebhookDeliveryStatus is the lifecycle state of one webhook delivery.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery records one event sent to one endpoint, across retries.
+type WebhookDelivery struct {
+	ID         string                `json:"id"`
+	Endpoint   string                `json:"endpoint"`
+	EventID    string                `json:"event_id"`
+	EventType  string                `json:"event_type"`
+	Status     WebhookDeliveryStatus `json:"status"`
+	Attempts   int                   `json:"attempts"`
+	StatusCode int                   `json:"status_code,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	CreatedAt  time.Time             `json:"created_at"`
+	FinishedAt *time.Time            `json:"finished_at,omitempty"`
+}
+
+// WebhookEndpointInfo describes a configured endpoint. The signing secret is
+// never returned, only whether one is set.
+type WebhookEndpointInfo struct {
+	Name      string   `json:"name"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events,omitempty"`
+	Repos     []string `json:"repos,omitempty"`
+	States    []string `json:"states,omitempty"`
+	Disabled  bool     `json:"disabled,omitempty"`
+	HasSecret bool     `json:"has_secret"`
+}
+
+// WebhooksResponse is returned by GET /api/webhooks.
+type WebhooksResponse struct {
+	Endpoints []WebhookEndpointInfo `json:"endpoints"`
+}
+
+// WebhookDeliveriesResponse is returned by GET /api/webhooks/deliveries,
+// newest first.
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
@@ -0,0 +1,29 @@
+package contracts
+
+import "time"
+
+// WorkspaceSetupRun is the most recent run of a repo's setup commands in a
+// workspace. It is stored on the workspace so a failed setup keeps its logs;
+// live output streams over /ws/provision/setup-{workspaceID}.
+type WorkspaceSetupRun struct {
+	// Status is "running", "passed" or "failed".
+	Status string `json:"status"`
+	// CacheKey hashes the rendered commands and the cache key files. A
+	// passing run with the same key lets the next setup be skipped.
+	CacheKey   string                 `json:"cache_key"`
+	StartedAt  time.Time              `json:"started_at"`
+	FinishedAt *time.Time             `json:"finished_at,omitempty"`
+	Commands   []WorkspaceSetupResult `json:"commands"`
+}
+
+// WorkspaceSetupResult is one setup command within a run.
+type WorkspaceSetupResult struct {
+	Name string `json:"name"`
+	// Status is "pending", "running", "passed", "failed" or "skipped".
+	Status     string `json:"status"`
+	ExitCode   int    `json:"exit_code,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	// Output is the tail of the command's combined stdout and stderr.
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"` // set when the command could not run or timed out
+}
@@ -54,6 +54,9 @@ const (
 	DefaultPreviewPortBase            = 53000
 	DefaultPreviewPortBlockSize       = 10
 	DefaultDisposeGracePeriodMs       = 5000 // 5 seconds; agents that ignore the pty hangup (codex) never exit on their own, so a long grace only delays SIGTERM
+	DefaultCheckpointDebounceMs       = 30000
+	DefaultCheckpointMaxPerWorkspace  = 50
+	DefaultAgentMessageRatePerMinute  = 10
 
 	// Default auth session TTL in minutes
 	DefaultAuthSessionTTLMinutes = 1440
@@ -121,6 +124,7 @@ type ConfigData struct {
 	BuiltInSkills              map[string]bool             `json:"built_in_skills,omitempty"` // Deprecated: no longer used. Kept for config compatibility.
 	TmuxBinary                 string                      `json:"tmux_binary,omitempty"`
 	TmuxSocketName             string                      `json:"tmux_socket_name,omitempty"`
+	SessionBackend             string                      `json:"session_backend,omitempty"`
 	RecycleWorkspaces          bool                        `json:"recycle_workspaces,omitempty"`
 	LocalEchoRemote            bool                        `json:"local_echo_remote,omitempty"`
 	DebugUI                    bool                        `json:"debug_ui,omitempty"`
@@ -132,6 +136,17 @@ type ConfigData struct {
 	FenceBuildMonitor          bool                        `json:"fence_build_monitor,omitempty"`
 	ClipboardSyncEnabled       *bool                       `json:"clipboard_sync_enabled,omitempty"`
 	Timelapse                  *TimelapseConfig            `json:"timelapse,omitempty"`
+	Webhooks                   []WebhookEndpoint           `json:"webhooks,omitempty"`
+	Budgets                    *BudgetsConfig              `json:"budgets,omitempty"`
+	Checkpoints                *CheckpointsConfig          `json:"checkpoints,omitempty"`
+	AgentMessages              *AgentMessagesConfig        `json:"agent_messages,omitempty"`
+	Redaction                  *RedactionConfig            `json:"redaction,omitempty"`
+	PushGates                  *PushGatesConfig            `json:"push_gates,omitempty"`
+	Acceptance                 *AcceptanceConfig           `json:"acceptance,omitempty"`
+	Setup                      *SetupConfig                `json:"setup,omitempty"`
+	WarmPool                   *WarmPoolConfig             `json:"warm_pool,omitempty"`
+	DiskQuota                  *DiskQuotaConfig            `json:"disk_quota,omitempty"`
+	AutoCleanup                *AutoCleanupConfig          `json:"auto_cleanup,omitempty"`
 
 	// Telemetry settings
 	Telemetry      *TelemetryConfig `json:"telemetry,omitempty"`
@@ -176,7 +191,7 @@ type RemoteFlavor struct {
 	ID            string `json:"id"`             // e.g., "gpu_ml_large" (auto-generated if not provided)
 	Flavor        string `json:"flavor"`         // e.g., "gpu:ml-large" (the flavor/environment identifier)
 	DisplayName   string `json:"display_name"`   // e.g., "GPU ML Large" (shown in UI)
-	VCS           string `json:"vcs"`            // "git" or "sapling"
+	VCS           string `json:"vcs"`            // "git", "sapling" or "jj"
 	WorkspacePath string `json:"workspace_path"` // e.g., "~/workspace" (path on remote host)
 
 	// ConnectCommand is a Go template for the command to connect to a remote host.
@@ -451,6 +466,203 @@ type TimelapseConfig struct {
 	MaxTotalStorageMB *int  `json:"maxTotalStorageMB,omitempty"` // default 500
 }
 
+// WebhookEndpoint is one outbound webhook receiver. Empty filter lists match
+// everything. The HMAC signing secret lives in secrets.json, keyed by Name.
+type WebhookEndpoint struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Events lists event types to send, e.g. "session.status". A trailing
+	// ".*" matches a whole family ("workspace.*").
+	Events []string `json:"events,omitempty"`
+	// Repos lists repo names; events without a repo always pass.
+	Repos []string `json:"repos,omitempty"`
+	// States lists session states (for session.status) or transition kinds
+	// (for build.transition); events without a state always pass.
+	States   []string `json:"states,omitempty"`
+	Disabled bool     `json:"disabled,omitempty"`
+}
+
+// CheckpointsConfig controls automatic workspace checkpoints.
+type CheckpointsConfig struct {
+	Enabled *bool `json:"enabled,omitempty"` // default true
+	// DebounceMs is how long a workspace's status must settle after a
+	// change before it is checkpointed.
+	DebounceMs int `json:"debounce_ms,omitempty"`
+	// MaxPerWorkspace caps the checkpoints kept per workspace; the oldest
+	// are deleted first.
+	MaxPerWorkspace int `json:"max_per_workspace,omitempty"`
+}
+
+// AgentMessagesConfig controls messages agents send each other through
+// their event files.
+type AgentMessagesConfig struct {
+	Enabled *bool `json:"enabled,omitempty"` // default true
+	// RatePerMinute caps the messages one session may deliver per minute;
+	// a group message counts once per recipient.
+	RatePerMinute int `json:"rate_per_minute,omitempty"`
+}
+
+// RedactionConfig controls the masking of secrets in recordings, captures
+// and prompts sent to LLMs.
+type RedactionConfig struct {
+	Enabled *bool `json:"enabled,omitempty"` // default true
+	// Patterns are redacted in addition to the built-in token formats.
+	Patterns []RedactionPattern `json:"patterns,omitempty"`
+}
+
+// RedactionPattern is a user regex to redact. When the regex has a capture
+// group only the first group is masked.
+type RedactionPattern struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex"`
+}
+
+// PushGatesConfig defines the checks a workspace must pass before its
+// commits are pushed to a branch or synced to the default branch.
+type PushGatesConfig struct {
+	// Repos maps a repo name to its gates, run in order.
+	Repos map[string][]PushGate `json:"repos,omitempty"`
+	// TellOnFailure types a summary of a failing gate into the workspace's
+	// agent sessions.
+	TellOnFailure bool `json:"tell_on_failure,omitempty"`
+}
+
+// PushGate is one pre-push check: an argv-array command run in the
+// workspace directory. A non-zero exit fails the gate. Slots may use
+// {{.WorkspacePath}}, {{.WorkspaceID}}, {{.Branch}} and {{.DefaultBranch}}.
+type PushGate struct {
+	Name      string       `json:"name"`
+	Command   ShellCommand `json:"command"`
+	TimeoutMs int          `json:"timeout_ms,omitempty"` // default DefaultPushGateTimeoutMs
+}
+
+// DefaultPushGateTimeoutMs is the default push_gates timeout_ms.
+const DefaultPushGateTimeoutMs = 600000 // 10 minutes
+
+// AcceptanceConfig defines the commands run in a workspace when one of its
+// agents reports completed. A failure is told to the agent, which gets up to
+// MaxRetries more attempts before the session is escalated to the user.
+// A spawn may bring its own commands instead (SpawnRequest.Acceptance).
+type AcceptanceConfig struct {
+	// Repos maps a repo name to its acceptance commands, run in order.
+	Repos      map[string][]AcceptanceCommand `json:"repos,omitempty"`
+	MaxRetries *int                           `json:"max_retries,omitempty"` // default DefaultAcceptanceMaxRetries
+}
+
+// AcceptanceCommand is one acceptance check: an argv-array command run in
+// the workspace directory. A non-zero exit fails it. Slots may use
+// {{.WorkspacePath}}, {{.WorkspaceID}}, {{.SessionID}}, {{.Branch}} and
+// {{.DefaultBranch}}.
+type AcceptanceCommand struct {
+	Name      string       `json:"name"`
+	Command   ShellCommand `json:"command"`
+	TimeoutMs int          `json:"timeout_ms,omitempty"` // default DefaultAcceptanceTimeoutMs
+}
+
+const (
+	// DefaultAcceptanceMaxRetries is the default acceptance.max_retries.
+	DefaultAcceptanceMaxRetries = 2
+	// DefaultAcceptanceTimeoutMs is the default acceptance timeout_ms.
+	DefaultAcceptanceTimeoutMs = 600000 // 10 minutes
+)
+
+// SetupConfig defines the commands that provision a workspace, such as
+// dependency installs and codegen, before its first session starts.
+type SetupConfig struct {
+	// Repos maps a repo name to its setup.
+	Repos map[string]RepoSetup `json:"repos,omitempty"`
+}
+
+// RepoSetup is one repo's setup. The commands run in order after the
+// workspace is created or reused; a failure marks the workspace failed.
+// When the commands and the cache key files are unchanged since the
+// workspace's last passing run, setup is skipped, so a recycled workspace
+// keeps its installed dependencies.
+type RepoSetup struct {
+	Commands []SetupCommand `json:"commands"`
+	// CacheKeyFiles are workspace-relative paths or filepath.Match globs
+	// whose contents key the skip (default DefaultSetupCacheKeyFiles).
+	CacheKeyFiles []string `json:"cache_key_files,omitempty"`
+}
+
+// SetupCommand is one setup step: an argv-array command run in the
+// workspace directory. A non-zero exit fails setup. Slots may use
+// {{.WorkspacePath}}, {{.WorkspaceID}}, {{.Branch}} and {{.DefaultBranch}}.
+type SetupCommand struct {
+	Name      string       `json:"name"`
+	Command   ShellCommand `json:"command"`
+	TimeoutMs int          `json:"timeout_ms,omitempty"` // default DefaultSetupTimeoutMs
+}
+
+// DefaultSetupTimeoutMs is the default setup command timeout_ms.
+const DefaultSetupTimeoutMs = 900000 // 15 minutes
+
+// DefaultSetupCacheKeyFiles are the lockfiles that key the setup skip when
+// a repo does not list its own.
+var DefaultSetupCacheKeyFiles = []string{
+	"package-lock.json",
+	"npm-shrinkwrap.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"bun.lockb",
+	"go.sum",
+	"Cargo.lock",
+	"poetry.lock",
+	"uv.lock",
+	"Pipfile.lock",
+	"requirements.txt",
+	"Gemfile.lock",
+	"composer.lock",
+}
+
+// WarmPoolConfig keeps provisioned workspaces ready so a spawn can take one
+// without waiting for fetch, checkout, overlays and setup. Only git repos
+// are pooled.
+type WarmPoolConfig struct {
+	// Repos maps a repo name to how many ready workspaces to keep.
+	Repos map[string]int `json:"repos,omitempty"`
+}
+
+// MaxWarmPoolSize is the largest warm_pool size allowed for one repo.
+const MaxWarmPoolSize = 10
+
+// DiskQuotaConfig caps the disk schmux uses for workspaces, repo bases and
+// timelapse recordings. Over the quota, schmux frees space before creating a
+// workspace and fails the spawn if it cannot.
+type DiskQuotaConfig struct {
+	MaxGB int `json:"max_gb,omitempty"` // 0 = no quota
+}
+
+// AutoCleanupConfig disposes workspaces whose work has landed upstream once
+// they have stayed clean and idle for the grace period. Disposal recycles
+// the workspace when recycle_workspaces is on.
+type AutoCleanupConfig struct {
+	Enabled    bool `json:"enabled,omitempty"`
+	GraceHours int  `json:"grace_hours,omitempty"` // default DefaultAutoCleanupGraceHours
+	DryRun     bool `json:"dry_run,omitempty"`     // log what would be disposed, dispose nothing
+	// ExcludeRepos lists repo names whose workspaces are never cleaned up.
+	ExcludeRepos []string `json:"exclude_repos,omitempty"`
+}
+
+// DefaultAutoCleanupGraceHours is the default auto_cleanup.grace_hours.
+const DefaultAutoCleanupGraceHours = 24
+
+// BudgetsConfig limits LLM spend as priced by cost accounting. Zero limits
+// are off.
+type BudgetsConfig struct {
+	// SessionUSD stops new spawns into a workspace once one of its sessions
+	// has spent this much.
+	SessionUSD float64 `json:"session_usd,omitempty"`
+	// DailyUSD stops new spawns once today's total spend reaches it.
+	DailyUSD float64 `json:"daily_usd,omitempty"`
+	// WarnPercent is the share of a budget at which a warning is raised.
+	// Defaults to DefaultBudgetWarnPercent.
+	WarnPercent int `json:"warn_percent,omitempty"`
+}
+
+// DefaultBudgetWarnPercent is the default budgets.warn_percent.
+const DefaultBudgetWarnPercent = 80
+
 // BranchSuggestConfig represents configuration for branch name suggestion.
 type BranchSuggestConfig struct {
 	Target string `json:"target,omitempty"`
@@ -580,6 +792,43 @@ type SessionsConfig struct {
 	GitStatusWatchEnabled    *bool `json:"git_status_watch_enabled,omitempty"`
 	GitStatusWatchDebounceMs int   `json:"git_status_watch_debounce_ms,omitempty"`
 	DisposeGracePeriodMs     int   `json:"dispose_grace_period_ms,omitempty"`
+	// MaxConcurrent caps running local sessions across all repos. 0 = unlimited.
+	MaxConcurrent int `json:"max_concurrent,omitempty"`
+	// MaxConcurrentPerRepo caps running sessions per repo name; the "*" key
+	// applies to repos without their own entry.
+	MaxConcurrentPerRepo map[string]int `json:"max_concurrent_per_repo,omitempty"`
+	// MaxConcurrentPerTarget caps running sessions per run target name; the
+	// "*" key applies to targets without their own entry.
+	MaxConcurrentPerTarget map[string]int `json:"max_concurrent_per_target,omitempty"`
+}
+
+// ConcurrencyLimits is a snapshot of the session concurrency caps. Zero
+// limits are unlimited.
+type ConcurrencyLimits struct {
+	Max       int
+	PerRepo   map[string]int
+	PerTarget map[string]int
+}
+
+// Enabled reports whether any limit is configured.
+func (l ConcurrencyLimits) Enabled() bool {
+	return l.Max > 0 || len(l.PerRepo) > 0 || len(l.PerTarget) > 0
+}
+
+// RepoLimit returns the cap for a repo name, falling back to the "*" entry.
+func (l ConcurrencyLimits) RepoLimit(name string) int {
+	if n, ok := l.PerRepo[name]; ok {
+		return n
+	}
+	return l.PerRepo["*"]
+}
+
+// TargetLimit returns the cap for a run target, falling back to the "*" entry.
+func (l ConcurrencyLimits) TargetLimit(name string) int {
+	if n, ok := l.PerTarget[name]; ok {
+		return n
+	}
+	return l.PerTarget["*"]
 }
 
 // XtermConfig represents terminal capture and timeout settings.
@@ -610,6 +859,7 @@ type NetworkConfig struct {
 	TLS                    *TLSConfig         `json:"tls,omitempty"`
 	DashboardSX            *DashboardSXConfig `json:"dashboardsx,omitempty"`
 	DashboardHostname      string             `json:"dashboard_hostname,omitempty"`
+	MetricsListenAddress   string             `json:"metrics_listen_address,omitempty"`
 }
 
 // TLSConfig holds TLS certificate paths.
@@ -1133,6 +1383,36 @@ func (c *Config) validate(strict bool) ([]string, error) {
 	if err := validateCompoundConfig(c.Compound); err != nil {
 		return nil, err
 	}
+	if err := validateWebhooks(c.Webhooks); err != nil {
+		return nil, err
+	}
+	if err := validateMetricsListenAddress(c.Network); err != nil {
+		return nil, err
+	}
+	if err := validateBudgets(c.Budgets); err != nil {
+		return nil, err
+	}
+	if err := validateRedaction(c.Redaction); err != nil {
+		return nil, err
+	}
+	if err := validatePushGates(c.PushGates); err != nil {
+		return nil, err
+	}
+	if err := validateAcceptance(c.Acceptance); err != nil {
+		return nil, err
+	}
+	if err := validateSetup(c.Setup); err != nil {
+		return nil, err
+	}
+	if err := validateWarmPool(c.WarmPool); err != nil {
+		return nil, err
+	}
+	if c.DiskQuota != nil && c.DiskQuota.MaxGB < 0 {
+		return nil, fmt.Errorf("%w: disk_quota.max_gb must not be negative", ErrInvalidConfig)
+	}
+	if c.AutoCleanup != nil && c.AutoCleanup.GraceHours < 0 {
+		return nil, fmt.Errorf("%w: auto_cleanup.grace_hours must not be negative", ErrInvalidConfig)
+	}
 	warnings, err := c.validateAccessControl(strict)
 	if err != nil {
 		return nil, err
@@ -1723,6 +2003,217 @@ func (c *Config) GetFloorManagerDebounceMs() int {
 	return c.FloorManager.DebounceMs
 }
 
+// GetWebhooks returns a copy of the configured webhook endpoints.
+func (c *Config) GetWebhooks() []WebhookEndpoint {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]WebhookEndpoint, len(c.Webhooks))
+	copy(out, c.Webhooks)
+	return out
+}
+
+// GetWebhookEndpoint returns the webhook endpoint with the given name.
+func (c *Config) GetWebhookEndpoint(name string) (WebhookEndpoint, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, ep := range c.Webhooks {
+		if ep.Name == name {
+			return ep, true
+		}
+	}
+	return WebhookEndpoint{}, false
+}
+
+// GetBudgets returns the configured spend budgets with WarnPercent
+// defaulted.
+func (c *Config) GetBudgets() BudgetsConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var b BudgetsConfig
+	if c.Budgets != nil {
+		b = *c.Budgets
+	}
+	if b.WarnPercent <= 0 {
+		b.WarnPercent = DefaultBudgetWarnPercent
+	}
+	return b
+}
+
+// GetCheckpointsEnabled returns whether automatic workspace checkpoints are
+// taken (default true). Manual checkpoints work either way.
+func (c *Config) GetCheckpointsEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Checkpoints == nil || c.Checkpoints.Enabled == nil {
+		return true
+	}
+	return *c.Checkpoints.Enabled
+}
+
+// CheckpointDebounce returns the settle time before a changed workspace is
+// checkpointed (default 30s).
+func (c *Config) CheckpointDebounce() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Checkpoints == nil || c.Checkpoints.DebounceMs <= 0 {
+		return DefaultCheckpointDebounceMs * time.Millisecond
+	}
+	return time.Duration(c.Checkpoints.DebounceMs) * time.Millisecond
+}
+
+// GetCheckpointMaxPerWorkspace returns how many checkpoints are kept per
+// workspace (default 50).
+func (c *Config) GetCheckpointMaxPerWorkspace() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Checkpoints == nil || c.Checkpoints.MaxPerWorkspace <= 0 {
+		return DefaultCheckpointMaxPerWorkspace
+	}
+	return c.Checkpoints.MaxPerWorkspace
+}
+
+// GetAgentMessagesEnabled returns whether agents may message each other
+// (default true).
+func (c *Config) GetAgentMessagesEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.AgentMessages == nil || c.AgentMessages.Enabled == nil {
+		return true
+	}
+	return *c.AgentMessages.Enabled
+}
+
+// GetAgentMessageRatePerMinute returns how many messages a session may
+// deliver per minute (default 10).
+func (c *Config) GetAgentMessageRatePerMinute() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.AgentMessages == nil || c.AgentMessages.RatePerMinute <= 0 {
+		return DefaultAgentMessageRatePerMinute
+	}
+	return c.AgentMessages.RatePerMinute
+}
+
+// GetRedactionEnabled returns whether secrets are redacted (default true).
+func (c *Config) GetRedactionEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Redaction == nil || c.Redaction.Enabled == nil {
+		return true
+	}
+	return *c.Redaction.Enabled
+}
+
+// GetRedactionPatterns returns a copy of the user redaction patterns.
+func (c *Config) GetRedactionPatterns() []RedactionPattern {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Redaction == nil {
+		return nil
+	}
+	return append([]RedactionPattern(nil), c.Redaction.Patterns...)
+}
+
+// GetPushGates returns a copy of the push gates configured for a repo.
+func (c *Config) GetPushGates(repoName string) []PushGate {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.PushGates == nil {
+		return nil
+	}
+	return append([]PushGate(nil), c.PushGates.Repos[repoName]...)
+}
+
+// GetPushGatesTellOnFailure returns whether a failing push gate is told to
+// the workspace's agents.
+func (c *Config) GetPushGatesTellOnFailure() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.PushGates != nil && c.PushGates.TellOnFailure
+}
+
+// GetAcceptanceCommands returns a copy of the acceptance commands configured
+// for a repo.
+func (c *Config) GetAcceptanceCommands(repoName string) []AcceptanceCommand {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Acceptance == nil {
+		return nil
+	}
+	return append([]AcceptanceCommand(nil), c.Acceptance.Repos[repoName]...)
+}
+
+// GetAcceptanceMaxRetries returns how many times an agent is sent back to fix
+// failing acceptance commands before the session is escalated (default 2).
+func (c *Config) GetAcceptanceMaxRetries() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Acceptance == nil || c.Acceptance.MaxRetries == nil {
+		return DefaultAcceptanceMaxRetries
+	}
+	return *c.Acceptance.MaxRetries
+}
+
+// GetWorkspaceSetup returns a copy of the setup configured for a repo, with
+// the default cache key files filled in. It has no commands when the repo
+// has no setup.
+func (c *Config) GetWorkspaceSetup(repoName string) RepoSetup {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Setup == nil {
+		return RepoSetup{}
+	}
+	setup := c.Setup.Repos[repoName]
+	if len(setup.Commands) == 0 {
+		return RepoSetup{}
+	}
+	keyFiles := setup.CacheKeyFiles
+	if len(keyFiles) == 0 {
+		keyFiles = DefaultSetupCacheKeyFiles
+	}
+	return RepoSetup{
+		Commands:      append([]SetupCommand(nil), setup.Commands...),
+		CacheKeyFiles: append([]string(nil), keyFiles...),
+	}
+}
+
+// GetWarmPoolSize returns how many ready workspaces to keep for a repo
+// (default 0, no pool).
+func (c *Config) GetWarmPoolSize(repoName string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.WarmPool == nil {
+		return 0
+	}
+	return c.WarmPool.Repos[repoName]
+}
+
+// GetDiskQuotaBytes returns the disk quota in bytes (default 0, no quota).
+func (c *Config) GetDiskQuotaBytes() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.DiskQuota == nil {
+		return 0
+	}
+	return int64(c.DiskQuota.MaxGB) << 30
+}
+
+// GetAutoCleanup returns a copy of the auto-cleanup policy with the default
+// grace period filled in. It is disabled when not configured.
+func (c *Config) GetAutoCleanup() AutoCleanupConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.AutoCleanup == nil {
+		return AutoCleanupConfig{GraceHours: DefaultAutoCleanupGraceHours}
+	}
+	policy := *c.AutoCleanup
+	policy.ExcludeRepos = append([]string(nil), c.AutoCleanup.ExcludeRepos...)
+	if policy.GraceHours == 0 {
+		policy.GraceHours = DefaultAutoCleanupGraceHours
+	}
+	return policy
+}
+
 // GetTimelapseEnabled returns whether timelapse recording is enabled (default true).
 func (c *Config) GetTimelapseEnabled() bool {
 	c.mu.RLock()
@@ -2797,6 +3288,37 @@ func (c *Config) GetDisposeGracePeriodMs() int {
 	return c.Sessions.DisposeGracePeriodMs
 }
 
+// GetConcurrencyLimits returns a copy of the configured session concurrency
+// limits. Non-positive map entries are dropped.
+func (c *Config) GetConcurrencyLimits() ConcurrencyLimits {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Sessions == nil {
+		return ConcurrencyLimits{}
+	}
+	copyPositive := func(src map[string]int) map[string]int {
+		var dst map[string]int
+		for k, v := range src {
+			if v <= 0 {
+				continue
+			}
+			if dst == nil {
+				dst = make(map[string]int, len(src))
+			}
+			dst[k] = v
+		}
+		return dst
+	}
+	limits := ConcurrencyLimits{
+		PerRepo:   copyPositive(c.Sessions.MaxConcurrentPerRepo),
+		PerTarget: copyPositive(c.Sessions.MaxConcurrentPerTarget),
+	}
+	if c.Sessions.MaxConcurrent > 0 {
+		limits.Max = c.Sessions.MaxConcurrent
+	}
+	return limits
+}
+
 // DisposeGracePeriod returns the dispose grace period as a time.Duration.
 func (c *Config) DisposeGracePeriod() time.Duration {
 	return time.Duration(c.GetDisposeGracePeriodMs()) * time.Millisecond
@@ -2893,6 +3415,22 @@ func (c *Config) GetPort() int {
 	return c.Network.Port
 }
 
+// GetMetricsListenAddress returns the host:port of the separate,
+// unauthenticated /metrics listener, or "" when it is disabled (the default,
+// and always in vendor-locked builds). /metrics is also served on the
+// dashboard port behind its usual authentication.
+func (c *Config) GetMetricsListenAddress() string {
+	if buildflags.VendorLocked {
+		return ""
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Network == nil {
+		return ""
+	}
+	return c.Network.MetricsListenAddress
+}
+
 // GetTmuxSocketName returns the tmux socket name, defaulting to "schmux".
 func (c *Config) GetTmuxSocketName() string {
 	c.mu.RLock()
@@ -2903,6 +3441,17 @@ func (c *Config) GetTmuxSocketName() string {
 	return c.TmuxSocketName
 }
 
+// GetSessionBackend returns the default backend for new local sessions:
+// "tmux" (the default) or "pty". Unknown values fall back to "tmux".
+func (c *Config) GetSessionBackend() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.SessionBackend == "pty" {
+		return "pty"
+	}
+	return "tmux"
+}
+
 // GetPreviewMaxPerWorkspace returns the per-workspace preview limit.
 func (c *Config) GetPreviewMaxPerWorkspace() int {
 	c.mu.RLock()
@@ -3802,6 +4351,180 @@ func (c *Config) RemoveRemoteProfile(id string) error {
 	return fmt.Errorf("%w: remote profile not found: %s", ErrInvalidConfig, id)
 }
 
+// validateWebhooks checks that webhook endpoints have unique names and
+// absolute http(s) URLs.
+func validateWebhooks(endpoints []WebhookEndpoint) error {
+	seen := make(map[string]bool, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.Name == "" {
+			return fmt.Errorf("%w: webhook name is required", ErrInvalidConfig)
+		}
+		if seen[ep.Name] {
+			return fmt.Errorf("%w: duplicate webhook name: %s", ErrInvalidConfig, ep.Name)
+		}
+		seen[ep.Name] = true
+		u, err := url.Parse(ep.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: webhook %s: url must be an absolute http(s) URL", ErrInvalidConfig, ep.Name)
+		}
+	}
+	return nil
+}
+
+// validateRedaction checks that redaction patterns have unique names and
+// compile.
+func validateRedaction(r *RedactionConfig) error {
+	if r == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(r.Patterns))
+	for _, p := range r.Patterns {
+		if p.Name == "" {
+			return fmt.Errorf("%w: redaction pattern name is required", ErrInvalidConfig)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("%w: duplicate redaction pattern name: %s", ErrInvalidConfig, p.Name)
+		}
+		seen[p.Name] = true
+		if _, err := regexp.Compile(p.Regex); err != nil {
+			return fmt.Errorf("%w: redaction pattern %s is not valid regex: %v", ErrInvalidConfig, p.Name, err)
+		}
+	}
+	return nil
+}
+
+// validatePushGates checks that every gate has a unique name within its
+// repo, a command, and a non-negative timeout.
+func validatePushGates(p *PushGatesConfig) error {
+	if p == nil {
+		return nil
+	}
+	for repo, gates := range p.Repos {
+		seen := make(map[string]bool, len(gates))
+		for _, g := range gates {
+			if g.Name == "" {
+				return fmt.Errorf("%w: push_gates.repos.%s: gate name is required", ErrInvalidConfig, repo)
+			}
+			if seen[g.Name] {
+				return fmt.Errorf("%w: push_gates.repos.%s: duplicate gate name: %s", ErrInvalidConfig, repo, g.Name)
+			}
+			seen[g.Name] = true
+			if len(g.Command) == 0 {
+				return fmt.Errorf("%w: push_gates.repos.%s: gate %s has no command", ErrInvalidConfig, repo, g.Name)
+			}
+			if g.TimeoutMs < 0 {
+				return fmt.Errorf("%w: push_gates.repos.%s: gate %s timeout_ms must not be negative", ErrInvalidConfig, repo, g.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateAcceptance checks that every acceptance command has a unique name
+// within its repo, a command, and a non-negative timeout, and that
+// max_retries is not negative.
+func validateAcceptance(a *AcceptanceConfig) error {
+	if a == nil {
+		return nil
+	}
+	if a.MaxRetries != nil && *a.MaxRetries < 0 {
+		return fmt.Errorf("%w: acceptance.max_retries must not be negative", ErrInvalidConfig)
+	}
+	for repo, cmds := range a.Repos {
+		seen := make(map[string]bool, len(cmds))
+		for _, cmd := range cmds {
+			if cmd.Name == "" {
+				return fmt.Errorf("%w: acceptance.repos.%s: command name is required", ErrInvalidConfig, repo)
+			}
+			if seen[cmd.Name] {
+				return fmt.Errorf("%w: acceptance.repos.%s: duplicate command name: %s", ErrInvalidConfig, repo, cmd.Name)
+			}
+			seen[cmd.Name] = true
+			if len(cmd.Command) == 0 {
+				return fmt.Errorf("%w: acceptance.repos.%s: %s has no command", ErrInvalidConfig, repo, cmd.Name)
+			}
+			if cmd.TimeoutMs < 0 {
+				return fmt.Errorf("%w: acceptance.repos.%s: %s timeout_ms must not be negative", ErrInvalidConfig, repo, cmd.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateSetup checks that every setup command has a unique name within
+// its repo, a command, and a non-negative timeout, and that cache key files
+// are valid relative patterns.
+func validateSetup(s *SetupConfig) error {
+	if s == nil {
+		return nil
+	}
+	for repo, setup := range s.Repos {
+		seen := make(map[string]bool, len(setup.Commands))
+		for _, cmd := range setup.Commands {
+			if cmd.Name == "" {
+				return fmt.Errorf("%w: setup.repos.%s: command name is required", ErrInvalidConfig, repo)
+			}
+			if seen[cmd.Name] {
+				return fmt.Errorf("%w: setup.repos.%s: duplicate command name: %s", ErrInvalidConfig, repo, cmd.Name)
+			}
+			seen[cmd.Name] = true
+			if len(cmd.Command) == 0 {
+				return fmt.Errorf("%w: setup.repos.%s: %s has no command", ErrInvalidConfig, repo, cmd.Name)
+			}
+			if cmd.TimeoutMs < 0 {
+				return fmt.Errorf("%w: setup.repos.%s: %s timeout_ms must not be negative", ErrInvalidConfig, repo, cmd.Name)
+			}
+		}
+		for _, pattern := range setup.CacheKeyFiles {
+			if _, err := filepath.Match(pattern, ""); err != nil || filepath.IsAbs(pattern) || strings.HasPrefix(filepath.Clean(pattern), "..") {
+				return fmt.Errorf("%w: setup.repos.%s: invalid cache key file: %s", ErrInvalidConfig, repo, pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// validateWarmPool checks that every pool size is between 0 and
+// MaxWarmPoolSize.
+func validateWarmPool(p *WarmPoolConfig) error {
+	if p == nil {
+		return nil
+	}
+	for repo, size := range p.Repos {
+		if size < 0 || size > MaxWarmPoolSize {
+			return fmt.Errorf("%w: warm_pool.repos.%s must be between 0 and %d", ErrInvalidConfig, repo, MaxWarmPoolSize)
+		}
+	}
+	return nil
+}
+
+// validateMetricsListenAddress checks that network.metrics_listen_address,
+// when set, is a host:port pair.
+func validateMetricsListenAddress(n *NetworkConfig) error {
+	if n == nil || n.MetricsListenAddress == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(n.MetricsListenAddress); err != nil {
+		return fmt.Errorf("%w: network.metrics_listen_address must be host:port: %v", ErrInvalidConfig, err)
+	}
+	return nil
+}
+
+// validateBudgets checks that budgets are non-negative and warn_percent is a
+// percentage.
+func validateBudgets(b *BudgetsConfig) error {
+	if b == nil {
+		return nil
+	}
+	if b.SessionUSD < 0 || b.DailyUSD < 0 {
+		return fmt.Errorf("%w: budgets must not be negative", ErrInvalidConfig)
+	}
+	if b.WarnPercent < 0 || b.WarnPercent > 100 {
+		return fmt.Errorf("%w: budgets.warn_percent must be between 1 and 100", ErrInvalidConfig)
+	}
+	return nil
+}
+
 // validateRemoteProfile validates a remote profile configuration.
 func validateRemoteProfile(p RemoteProfile) error {
 	if p.DisplayName == "" {
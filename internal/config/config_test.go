@@ -430,6 +430,41 @@ func TestGetGitStatusTimeoutMs(t *testing.T) {
 	})
 }
 
+func TestGetConcurrencyLimits(t *testing.T) {
+	t.Run("disabled when not configured", func(t *testing.T) {
+		cfg := &Config{}
+		if cfg.GetConcurrencyLimits().Enabled() {
+			t.Error("expected limits disabled")
+		}
+	})
+
+	t.Run("per-key limits fall back to wildcard", func(t *testing.T) {
+		cfg := &Config{ConfigData: ConfigData{
+			Sessions: &SessionsConfig{
+				MaxConcurrent:          8,
+				MaxConcurrentPerRepo:   map[string]int{"*": 3, "schmux": 5, "ignored": 0},
+				MaxConcurrentPerTarget: map[string]int{"claude": 2},
+			},
+		}}
+		l := cfg.GetConcurrencyLimits()
+		if !l.Enabled() || l.Max != 8 {
+			t.Fatalf("unexpected limits: %+v", l)
+		}
+		if got := l.RepoLimit("schmux"); got != 5 {
+			t.Errorf("RepoLimit(schmux) = %d, want 5", got)
+		}
+		if got := l.RepoLimit("other"); got != 3 {
+			t.Errorf("RepoLimit(other) = %d, want 3", got)
+		}
+		if got := l.RepoLimit("ignored"); got != 3 {
+			t.Errorf("RepoLimit(ignored) = %d, want wildcard 3", got)
+		}
+		if got := l.TargetLimit("codex"); got != 0 {
+			t.Errorf("TargetLimit(codex) = %d, want 0", got)
+		}
+	})
+}
+
 func TestGetDisposeGracePeriodMs(t *testing.T) {
 	t.Run("returns configured value", func(t *testing.T) {
 		cfg := &Config{ConfigData: ConfigData{
@@ -1994,6 +2029,16 @@ func TestValidate_NegativeCases(t *testing.T) {
 			}},
 			wantContains: "target or command is required",
 		},
+		{
+			name:         "negative disk quota",
+			cfg:          &Config{ConfigData: ConfigData{DiskQuota: &DiskQuotaConfig{MaxGB: -1}}},
+			wantContains: "disk_quota.max_gb",
+		},
+		{
+			name:         "negative auto cleanup grace",
+			cfg:          &Config{ConfigData: ConfigData{AutoCleanup: &AutoCleanupConfig{Enabled: true, GraceHours: -1}}},
+			wantContains: "auto_cleanup.grace_hours",
+		},
 	}
 
 	for _, tt := range tests {
@@ -3172,6 +3217,208 @@ func TestGetBindAddress(t *testing.T) {
 	}
 }
 
+func TestValidateMetricsListenAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		network *NetworkConfig
+		wantErr bool
+	}{
+		{"nil network", nil, false},
+		{"unset", &NetworkConfig{}, false},
+		{"host and port", &NetworkConfig{MetricsListenAddress: "127.0.0.1:9337"}, false},
+		{"any host", &NetworkConfig{MetricsListenAddress: ":9337"}, false},
+		{"missing port", &NetworkConfig{MetricsListenAddress: "127.0.0.1"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMetricsListenAddress(tt.network)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMetricsListenAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBudgets(t *testing.T) {
+	tests := []struct {
+		name    string
+		budgets *BudgetsConfig
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"limits", &BudgetsConfig{SessionUSD: 5, DailyUSD: 50, WarnPercent: 90}, false},
+		{"negative daily", &BudgetsConfig{DailyUSD: -1}, true},
+		{"warn over 100", &BudgetsConfig{WarnPercent: 101}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBudgets(tt.budgets)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateBudgets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	cfg := &Config{}
+	if got := cfg.GetBudgets().WarnPercent; got != DefaultBudgetWarnPercent {
+		t.Errorf("default WarnPercent = %d, want %d", got, DefaultBudgetWarnPercent)
+	}
+}
+
+func TestValidateRedaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		redaction *RedactionConfig
+		wantErr   bool
+	}{
+		{"nil", nil, false},
+		{"patterns", &RedactionConfig{Patterns: []RedactionPattern{{Name: "internal", Regex: `corp_[a-z0-9]{32}`}}}, false},
+		{"missing name", &RedactionConfig{Patterns: []RedactionPattern{{Regex: `x`}}}, true},
+		{"duplicate name", &RedactionConfig{Patterns: []RedactionPattern{{Name: "a", Regex: `x`}, {Name: "a", Regex: `y`}}}, true},
+		{"bad regex", &RedactionConfig{Patterns: []RedactionPattern{{Name: "a", Regex: `(`}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRedaction(tt.redaction)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRedaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if !(&Config{}).GetRedactionEnabled() {
+		t.Error("redaction should be enabled by default")
+	}
+}
+
+func TestValidatePushGates(t *testing.T) {
+	gates := func(g ...PushGate) *PushGatesConfig {
+		return &PushGatesConfig{Repos: map[string][]PushGate{"schmux": g}}
+	}
+	test := ShellCommand{"go", "test", "./..."}
+	tests := []struct {
+		name    string
+		gates   *PushGatesConfig
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"gates", gates(PushGate{Name: "test", Command: test}, PushGate{Name: "vet", Command: ShellCommand{"go", "vet", "./..."}, TimeoutMs: 60000}), false},
+		{"missing name", gates(PushGate{Command: test}), true},
+		{"duplicate name", gates(PushGate{Name: "test", Command: test}, PushGate{Name: "test", Command: test}), true},
+		{"missing command", gates(PushGate{Name: "test"}), true},
+		{"negative timeout", gates(PushGate{Name: "test", Command: test, TimeoutMs: -1}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePushGates(tt.gates)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePushGates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAcceptance(t *testing.T) {
+	cmds := func(c ...AcceptanceCommand) *AcceptanceConfig {
+		return &AcceptanceConfig{Repos: map[string][]AcceptanceCommand{"schmux": c}}
+	}
+	test := ShellCommand{"go", "test", "./..."}
+	negative := -1
+	tests := []struct {
+		name       string
+		acceptance *AcceptanceConfig
+		wantErr    bool
+	}{
+		{"nil", nil, false},
+		{"commands", cmds(AcceptanceCommand{Name: "test", Command: test}, AcceptanceCommand{Name: "vet", Command: ShellCommand{"go", "vet", "./..."}, TimeoutMs: 60000}), false},
+		{"missing name", cmds(AcceptanceCommand{Command: test}), true},
+		{"duplicate name", cmds(AcceptanceCommand{Name: "test", Command: test}, AcceptanceCommand{Name: "test", Command: test}), true},
+		{"missing command", cmds(AcceptanceCommand{Name: "test"}), true},
+		{"negative timeout", cmds(AcceptanceCommand{Name: "test", Command: test, TimeoutMs: -1}), true},
+		{"negative retries", &AcceptanceConfig{MaxRetries: &negative}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAcceptance(tt.acceptance)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateAcceptance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSetup(t *testing.T) {
+	setup := func(keyFiles []string, c ...SetupCommand) *SetupConfig {
+		return &SetupConfig{Repos: map[string]RepoSetup{"schmux": {Commands: c, CacheKeyFiles: keyFiles}}}
+	}
+	install := ShellCommand{"npm", "ci"}
+	tests := []struct {
+		name    string
+		setup   *SetupConfig
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"commands", setup([]string{"go.sum", "assets/*/package-lock.json"}, SetupCommand{Name: "npm", Command: install}, SetupCommand{Name: "go", Command: ShellCommand{"go", "mod", "download"}, TimeoutMs: 60000}), false},
+		{"missing name", setup(nil, SetupCommand{Command: install}), true},
+		{"duplicate name", setup(nil, SetupCommand{Name: "npm", Command: install}, SetupCommand{Name: "npm", Command: install}), true},
+		{"missing command", setup(nil, SetupCommand{Name: "npm"}), true},
+		{"negative timeout", setup(nil, SetupCommand{Name: "npm", Command: install, TimeoutMs: -1}), true},
+		{"absolute key file", setup([]string{"/etc/passwd"}, SetupCommand{Name: "npm", Command: install}), true},
+		{"escaping key file", setup([]string{"../go.sum"}, SetupCommand{Name: "npm", Command: install}), true},
+		{"bad key pattern", setup([]string{"[go.sum"}, SetupCommand{Name: "npm", Command: install}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSetup(tt.setup)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSetup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetWorkspaceSetup(t *testing.T) {
+	cfg := &Config{ConfigData: ConfigData{Setup: &SetupConfig{Repos: map[string]RepoSetup{
+		"web":   {Commands: []SetupCommand{{Name: "npm", Command: ShellCommand{"npm", "ci"}}}},
+		"tools": {Commands: []SetupCommand{{Name: "go", Command: ShellCommand{"go", "mod", "download"}}}, CacheKeyFiles: []string{"go.sum"}},
+		"empty": {CacheKeyFiles: []string{"go.sum"}},
+	}}}}
+	if got := cfg.GetWorkspaceSetup("web"); len(got.Commands) != 1 || len(got.CacheKeyFiles) != len(DefaultSetupCacheKeyFiles) {
+		t.Errorf("web setup = %+v, want one command and the default key files", got)
+	}
+	if got := cfg.GetWorkspaceSetup("tools"); len(got.CacheKeyFiles) != 1 || got.CacheKeyFiles[0] != "go.sum" {
+		t.Errorf("tools key files = %v, want [go.sum]", got.CacheKeyFiles)
+	}
+	if got := cfg.GetWorkspaceSetup("empty"); len(got.Commands) != 0 || len(got.CacheKeyFiles) != 0 {
+		t.Errorf("setup without commands = %+v, want empty", got)
+	}
+	if got := (&Config{}).GetWorkspaceSetup("web"); len(got.Commands) != 0 {
+		t.Errorf("unconfigured setup = %+v, want empty", got)
+	}
+}
+
+func TestValidateWarmPool(t *testing.T) {
+	tests := []struct {
+		name    string
+		pool    *WarmPoolConfig
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"sizes", &WarmPoolConfig{Repos: map[string]int{"schmux": 2, "docs": 0}}, false},
+		{"max", &WarmPoolConfig{Repos: map[string]int{"schmux": MaxWarmPoolSize}}, false},
+		{"negative", &WarmPoolConfig{Repos: map[string]int{"schmux": -1}}, true},
+		{"too large", &WarmPoolConfig{Repos: map[string]int{"schmux": MaxWarmPoolSize + 1}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWarmPool(tt.pool)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateWarmPool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestGetNetworkAccess(t *testing.T) {
 	skipUnderVendorlocked(t)
 	t.Parallel()
@@ -18,8 +18,9 @@ func NormalizeBarePaths(cfg *Config, st *state.State) {
 	for i := range cfg.Repos {
 		repo := &cfg.Repos[i]
 
-		// Skip Sapling repos
-		if repo.VCS == "sapling" {
+		// Skip Sapling and jj repos; jj workspaces point at their base by
+		// absolute path, so renaming it would orphan them.
+		if repo.VCS == "sapling" || repo.VCS == "jj" {
 			continue
 		}
 
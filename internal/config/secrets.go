@@ -2,14 +2,19 @@ package config
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/sergeknystautas/schmux/internal/detect"
 	"github.com/sergeknystautas/schmux/internal/fileutil"
@@ -23,6 +28,35 @@ type SecretsFile struct {
 	Variants  ModelSecrets                 `json:"variants,omitempty"` // deprecated, migrated to models
 	Providers map[string]map[string]string `json:"providers,omitempty"`
 	Auth      AuthSecrets                  `json:"auth,omitempty"`
+	Webhooks  map[string]string            `json:"webhooks,omitempty"` // webhook endpoint name -> HMAC signing secret
+	APITokens []APIToken                   `json:"api_tokens,omitempty"`
+}
+
+// API token scopes. A token may hold any combination.
+const (
+	APITokenScopeSpawn = "spawn" // spawn sessions, including templated trigger spawns
+	APITokenScopeTell  = "tell"  // send messages to running sessions
+	APITokenScopeRead  = "read"  // read-only API access
+)
+
+// apiTokenPrefix marks schmux API tokens so they are recognizable in logs
+// and secret scanners.
+const apiTokenPrefix = "schmux_"
+
+// APIToken is a bearer token for external callers of the dashboard API.
+// Only the SHA-256 of the token is stored; the token itself is shown once,
+// at creation.
+type APIToken struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	Hash      string   `json:"hash"`       // hex SHA-256 of the token
+	CreatedAt string   `json:"created_at"` // RFC3339
+}
+
+// HasScope reports whether the token grants scope.
+func (t APIToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
 }
 
 type AuthSecrets struct {
@@ -72,7 +106,7 @@ func LoadSecretsFile() (*SecretsFile, error) {
 		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
 	}
 
-	if _, ok := raw["models"]; ok || raw["auth"] != nil {
+	if _, ok := raw["models"]; ok || raw["auth"] != nil || raw["webhooks"] != nil || raw["api_tokens"] != nil {
 		var secrets SecretsFile
 		if err := json.Unmarshal(data, &secrets); err != nil {
 			return nil, fmt.Errorf("failed to parse secrets file: %w", err)
@@ -586,3 +620,151 @@ func migrateToProviderKeyed(secrets *SecretsFile) bool {
 	}
 	return changed
 }
+
+// GetWebhookSecret returns the HMAC signing secret for a webhook endpoint, or
+// "" when none is set.
+func GetWebhookSecret(name string) (string, error) {
+	secrets, err := LoadSecretsFile()
+	if err != nil {
+		return "", err
+	}
+	return secrets.Webhooks[name], nil
+}
+
+// SaveWebhookSecret stores the HMAC signing secret for a webhook endpoint.
+// An empty secret removes it.
+func SaveWebhookSecret(name, secret string) error {
+	if name == "" {
+		return fmt.Errorf("webhook name is required")
+	}
+	secrets, err := LoadSecretsFile()
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		if _, ok := secrets.Webhooks[name]; !ok {
+			return nil
+		}
+		delete(secrets.Webhooks, name)
+	} else {
+		if secrets.Webhooks == nil {
+			secrets.Webhooks = make(map[string]string)
+		}
+		secrets.Webhooks[name] = secret
+	}
+	return SaveSecretsFile(secrets)
+}
+
+// hashAPIToken returns the stored form of an API token.
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// validateAPITokenScopes rejects empty, unknown and duplicate scopes.
+func validateAPITokenScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		switch scope {
+		case APITokenScopeSpawn, APITokenScopeTell, APITokenScopeRead:
+		default:
+			return fmt.Errorf("unknown token scope %q (valid: spawn, tell, read)", scope)
+		}
+		if seen[scope] {
+			return fmt.Errorf("duplicate token scope %q", scope)
+		}
+		seen[scope] = true
+	}
+	return nil
+}
+
+// CreateAPIToken generates a new API token with the given scopes and stores
+// its hash. The returned token string is the only copy of the secret.
+func CreateAPIToken(name string, scopes []string) (string, APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", APIToken{}, fmt.Errorf("token name is required")
+	}
+	if err := validateAPITokenScopes(scopes); err != nil {
+		return "", APIToken{}, err
+	}
+	secrets, err := LoadSecretsFile()
+	if err != nil {
+		return "", APIToken{}, err
+	}
+	for _, t := range secrets.APITokens {
+		if t.Name == name {
+			return "", APIToken{}, fmt.Errorf("a token named %q already exists", name)
+		}
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", APIToken{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	idBuf := make([]byte, 6)
+	if _, err := rand.Read(idBuf); err != nil {
+		return "", APIToken{}, fmt.Errorf("failed to generate token id: %w", err)
+	}
+	info := APIToken{
+		ID:        "tok-" + hex.EncodeToString(idBuf),
+		Name:      name,
+		Scopes:    slices.Clone(scopes),
+		Hash:      hashAPIToken(token),
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	secrets.APITokens = append(secrets.APITokens, info)
+	if err := SaveSecretsFile(secrets); err != nil {
+		return "", APIToken{}, err
+	}
+	return token, info, nil
+}
+
+// ListAPITokens returns the stored API tokens in creation order.
+func ListAPITokens() ([]APIToken, error) {
+	secrets, err := LoadSecretsFile()
+	if err != nil {
+		return nil, err
+	}
+	return secrets.APITokens, nil
+}
+
+// RevokeAPIToken deletes the token with the given ID or name. It returns
+// false when no token matches.
+func RevokeAPIToken(idOrName string) (bool, error) {
+	secrets, err := LoadSecretsFile()
+	if err != nil {
+		return false, err
+	}
+	n := len(secrets.APITokens)
+	secrets.APITokens = slices.DeleteFunc(secrets.APITokens, func(t APIToken) bool {
+		return t.ID == idOrName || t.Name == idOrName
+	})
+	if len(secrets.APITokens) == n {
+		return false, nil
+	}
+	return true, SaveSecretsFile(secrets)
+}
+
+// LookupAPIToken returns the stored token matching token, or nil when the
+// token is unknown or revoked.
+func LookupAPIToken(token string) (*APIToken, error) {
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		return nil, nil
+	}
+	secrets, err := LoadSecretsFile()
+	if err != nil {
+		return nil, err
+	}
+	hash := hashAPIToken(token)
+	for _, t := range secrets.APITokens {
+		if subtle.ConstantTimeCompare([]byte(t.Hash), []byte(hash)) == 1 {
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
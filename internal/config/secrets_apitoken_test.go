@@ -0,0 +1,55 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAPITokenLifecycle(t *testing.T) {
+	setupSecretsHome(t)
+
+	token, info, err := CreateAPIToken("ci", []string{APITokenScopeSpawn, APITokenScopeRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token, apiTokenPrefix) || info.ID == "" || info.Hash == token {
+		t.Fatalf("unexpected token %q info %+v", token, info)
+	}
+	if _, _, err := CreateAPIToken("ci", []string{APITokenScopeRead}); err == nil {
+		t.Error("duplicate token name accepted")
+	}
+
+	got, err := LookupAPIToken(token)
+	if err != nil || got == nil || got.ID != info.ID {
+		t.Fatalf("LookupAPIToken = %+v, %v", got, err)
+	}
+	if !got.HasScope(APITokenScopeSpawn) || got.HasScope(APITokenScopeTell) {
+		t.Errorf("unexpected scopes: %v", got.Scopes)
+	}
+	if got, _ := LookupAPIToken(token + "x"); got != nil {
+		t.Error("wrong token matched")
+	}
+
+	tokens, err := ListAPITokens()
+	if err != nil || len(tokens) != 1 {
+		t.Fatalf("ListAPITokens = %v, %v", tokens, err)
+	}
+	if ok, err := RevokeAPIToken("ci"); !ok || err != nil {
+		t.Fatalf("RevokeAPIToken = %v, %v", ok, err)
+	}
+	if got, _ := LookupAPIToken(token); got != nil {
+		t.Error("revoked token still matches")
+	}
+	if ok, _ := RevokeAPIToken(info.ID); ok {
+		t.Error("revoking twice reported success")
+	}
+}
+
+func TestCreateAPIToken_InvalidScopes(t *testing.T) {
+	setupSecretsHome(t)
+	for _, scopes := range [][]string{nil, {"admin"}, {"read", "read"}} {
+		if _, _, err := CreateAPIToken("ci", scopes); err == nil {
+			t.Errorf("scopes %v accepted", scopes)
+		}
+	}
+}
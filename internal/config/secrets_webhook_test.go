@@ -0,0 +1,50 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWebhookSecretRoundTrip(t *testing.T) {
+	setupSecretsHome(t)
+	if err := SaveWebhookSecret("ci", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	// A secrets file holding only webhook secrets must not be mistaken for
+	// the legacy model-keyed format.
+	got, err := GetWebhookSecret("ci")
+	if err != nil || got != "s3cret" {
+		t.Fatalf("secret=%q err=%v", got, err)
+	}
+	if err := SaveWebhookSecret("ci", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := GetWebhookSecret("ci"); got != "" {
+		t.Fatalf("secret after delete = %q", got)
+	}
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoints []WebhookEndpoint
+		wantErr   bool
+	}{
+		{"valid", []WebhookEndpoint{{Name: "ci", URL: "https://example.com/hook"}}, false},
+		{"missing name", []WebhookEndpoint{{URL: "https://example.com/hook"}}, true},
+		{"duplicate name", []WebhookEndpoint{{Name: "ci", URL: "http://a/"}, {Name: "ci", URL: "http://b/"}}, true},
+		{"relative url", []WebhookEndpoint{{Name: "ci", URL: "/hook"}}, true},
+		{"bad scheme", []WebhookEndpoint{{Name: "ci", URL: "ftp://example.com/hook"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWebhooks(tt.endpoints)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("err = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
@@ -29,6 +29,9 @@ func WarnVendorLockedIgnoredKeys(c *Config, logger *log.Logger) {
 		if c.Network.DashboardHostname != "" {
 			ignored = append(ignored, "network.dashboard_hostname")
 		}
+		if c.Network.MetricsListenAddress != "" {
+			ignored = append(ignored, "network.metrics_listen_address")
+		}
 		if c.Network.TLS != nil {
 			if c.Network.TLS.CertPath != "" {
 				ignored = append(ignored, "network.tls.cert_path")
@@ -0,0 +1,405 @@
+// Package cost accounts LLM token usage and spend. Agent sessions report
+// cumulative usage read from their transcripts; built-in oneshot features
+// report per-call usage. Both are priced with models.dev registry costs when
+// recorded and aggregated per day in a ledger persisted beside state.json.
+package cost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/sergeknystautas/schmux/internal/fileutil"
+)
+
+// ErrBudgetExceeded is returned by Ledger.CheckSpawn when a budget is spent.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// maxLedgerDays is how many days of entries the ledger keeps.
+const maxLedgerDays = 400
+
+// Usage is a token count. Cache tokens are counted separately from input
+// tokens, as both Anthropic and models.dev do.
+type Usage struct {
+	InputTokens      int64 `json:"input_tokens,omitempty"`
+	OutputTokens     int64 `json:"output_tokens,omitempty"`
+	CacheReadTokens  int64 `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int64 `json:"cache_write_tokens,omitempty"`
+}
+
+// Add returns u + o.
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		InputTokens:      u.InputTokens + o.InputTokens,
+		OutputTokens:     u.OutputTokens + o.OutputTokens,
+		CacheReadTokens:  u.CacheReadTokens + o.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens + o.CacheWriteTokens,
+	}
+}
+
+// since returns the growth from prev to u, clamping fields that shrank (a
+// rewritten transcript) to zero.
+func (u Usage) since(prev Usage) Usage {
+	d := func(a, b int64) int64 { return max(a-b, 0) }
+	return Usage{
+		InputTokens:      d(u.InputTokens, prev.InputTokens),
+		OutputTokens:     d(u.OutputTokens, prev.OutputTokens),
+		CacheReadTokens:  d(u.CacheReadTokens, prev.CacheReadTokens),
+		CacheWriteTokens: d(u.CacheWriteTokens, prev.CacheWriteTokens),
+	}
+}
+
+// IsZero reports whether u counts no tokens.
+func (u Usage) IsZero() bool { return u == Usage{} }
+
+// Price is a model's cost in USD per million tokens.
+type Price struct {
+	Input      float64
+	Output     float64
+	CacheRead  float64
+	CacheWrite float64
+}
+
+// Cost returns the USD cost of u. Cache tokens without a cache price are
+// charged at the input price.
+func (p Price) Cost(u Usage) float64 {
+	cacheRead, cacheWrite := p.CacheRead, p.CacheWrite
+	if cacheRead == 0 {
+		cacheRead = p.Input
+	}
+	if cacheWrite == 0 {
+		cacheWrite = p.Input
+	}
+	return (float64(u.InputTokens)*p.Input +
+		float64(u.OutputTokens)*p.Output +
+		float64(u.CacheReadTokens)*cacheRead +
+		float64(u.CacheWriteTokens)*cacheWrite) / 1e6
+}
+
+// PriceFunc looks up a model's price. ok is false for unknown models.
+type PriceFunc func(model string) (p Price, ok bool)
+
+// Entry is one ledger row: one day's usage of one model by one session or
+// one oneshot feature.
+type Entry struct {
+	Day         string `json:"day"` // YYYY-MM-DD, local time
+	SessionID   string `json:"session_id,omitempty"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	Repo        string `json:"repo,omitempty"`
+	Target      string `json:"target,omitempty"`
+	Feature     string `json:"feature,omitempty"` // oneshot schema label; "" for sessions
+	Model       string `json:"model,omitempty"`
+	Usage
+	CostUSD float64 `json:"cost_usd"`
+	// Unpriced is set when some of the usage had no registry price.
+	Unpriced bool `json:"unpriced,omitempty"`
+}
+
+// Source identifies who used tokens. Exactly one of SessionID and Feature is
+// set.
+type Source struct {
+	SessionID   string
+	WorkspaceID string
+	Repo        string
+	Target      string
+	Feature     string
+}
+
+type entryKey struct{ day, session, feature, model string }
+
+type ledgerFile struct {
+	Entries []Entry `json:"entries"`
+	// Reported is the last cumulative usage per session, transcript and
+	// model, so repeated reports only add their growth.
+	Reported map[string]map[string]map[string]Usage `json:"reported,omitempty"`
+}
+
+// Ledger aggregates usage and cost. Safe for concurrent use.
+type Ledger struct {
+	mu       sync.Mutex
+	path     string
+	price    PriceFunc
+	now      func() time.Time
+	logger   *log.Logger
+	entries  map[entryKey]*Entry
+	reported map[string]map[string]map[string]Usage
+}
+
+// NewLedger loads the ledger at path. An empty path keeps it in memory.
+func NewLedger(path string, price PriceFunc, logger *log.Logger) *Ledger {
+	if logger == nil {
+		logger = log.NewWithOptions(io.Discard, log.Options{})
+	}
+	l := &Ledger{
+		path:     path,
+		price:    price,
+		now:      time.Now,
+		logger:   logger,
+		entries:  make(map[entryKey]*Entry),
+		reported: make(map[string]map[string]map[string]Usage),
+	}
+	l.load()
+	return l
+}
+
+func (l *Ledger) load() {
+	if l.path == "" {
+		return
+	}
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			l.logger.Warn("failed to read cost ledger", "err", err)
+		}
+		return
+	}
+	var f ledgerFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		l.logger.Warn("failed to parse cost ledger", "err", err)
+		return
+	}
+	for i := range f.Entries {
+		e := f.Entries[i]
+		l.entries[entryKey{e.Day, e.SessionID, e.Feature, e.Model}] = &e
+	}
+	if f.Reported != nil {
+		l.reported = f.Reported
+	}
+}
+
+func (l *Ledger) saveLocked() {
+	if l.path == "" {
+		return
+	}
+	cutoff := l.now().AddDate(0, 0, -maxLedgerDays).Format(time.DateOnly)
+	f := ledgerFile{Entries: make([]Entry, 0, len(l.entries)), Reported: l.reported}
+	live := make(map[string]bool)
+	for k, e := range l.entries {
+		if e.Day < cutoff {
+			delete(l.entries, k)
+			continue
+		}
+		live[e.SessionID] = true
+		f.Entries = append(f.Entries, *e)
+	}
+	for id := range l.reported {
+		if !live[id] {
+			delete(l.reported, id)
+		}
+	}
+	sort.Slice(f.Entries, func(i, j int) bool {
+		a, b := f.Entries[i], f.Entries[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.SessionID != b.SessionID {
+			return a.SessionID < b.SessionID
+		}
+		if a.Feature != b.Feature {
+			return a.Feature < b.Feature
+		}
+		return a.Model < b.Model
+	})
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		l.logger.Warn("failed to marshal cost ledger", "err", err)
+		return
+	}
+	if err := fileutil.AtomicWriteFile(l.path, data, 0600); err != nil {
+		l.logger.Warn("failed to save cost ledger", "err", err)
+	}
+}
+
+// addLocked prices u and adds it to today's entry for src and model.
+func (l *Ledger) addLocked(src Source, model string, u Usage) {
+	if u.IsZero() {
+		return
+	}
+	key := entryKey{l.now().Format(time.DateOnly), src.SessionID, src.Feature, model}
+	e, ok := l.entries[key]
+	if !ok {
+		e = &Entry{Day: key.day, SessionID: src.SessionID, Feature: src.Feature, Model: model}
+		l.entries[key] = e
+	}
+	// Metadata can arrive late (a workspace resolved after the first
+	// report); keep the latest non-empty values.
+	if src.WorkspaceID != "" {
+		e.WorkspaceID = src.WorkspaceID
+	}
+	if src.Repo != "" {
+		e.Repo = src.Repo
+	}
+	if src.Target != "" {
+		e.Target = src.Target
+	}
+	e.Usage = e.Usage.Add(u)
+	if p, ok := l.lookupPrice(model); ok {
+		e.CostUSD += p.Cost(u)
+	} else {
+		e.Unpriced = true
+	}
+}
+
+func (l *Ledger) lookupPrice(model string) (Price, bool) {
+	if l.price == nil || model == "" {
+		return Price{}, false
+	}
+	return l.price(model)
+}
+
+// RecordSession records a session's cumulative usage per model as read from
+// one transcript. Only growth since the previous report for the same
+// transcript is added, so reports may repeat.
+func (l *Ledger) RecordSession(src Source, transcript string, totals map[string]Usage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	byTranscript := l.reported[src.SessionID]
+	if byTranscript == nil {
+		byTranscript = make(map[string]map[string]Usage)
+		l.reported[src.SessionID] = byTranscript
+	}
+	prev := byTranscript[transcript]
+	next := make(map[string]Usage, len(totals))
+	for model, u := range totals {
+		l.addLocked(src, model, u.since(prev[model]))
+		next[model] = u
+	}
+	byTranscript[transcript] = next
+	l.saveLocked()
+}
+
+// RecordCall records the usage of one oneshot call.
+func (l *Ledger) RecordCall(src Source, model string, u Usage) {
+	if u.IsZero() {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.addLocked(src, model, u)
+	l.saveLocked()
+}
+
+// Entries returns the entries for days on or after since (YYYY-MM-DD; ""
+// for all).
+func (l *Ledger) Entries(since string) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]Entry, 0, len(l.entries))
+	for _, e := range l.entries {
+		if e.Day >= since {
+			out = append(out, *e)
+		}
+	}
+	return out
+}
+
+// Today returns the local date the ledger files new usage under.
+func (l *Ledger) Today() string {
+	return l.now().Format(time.DateOnly)
+}
+
+// DayCost returns the total cost of a day.
+func (l *Ledger) DayCost(day string) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var total float64
+	for _, e := range l.entries {
+		if e.Day == day {
+			total += e.CostUSD
+		}
+	}
+	return total
+}
+
+// SessionCosts returns the total cost of every session with usage.
+func (l *Ledger) SessionCosts() map[string]float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make(map[string]float64)
+	for _, e := range l.entries {
+		if e.SessionID != "" {
+			out[e.SessionID] += e.CostUSD
+		}
+	}
+	return out
+}
+
+// SessionCost returns the total cost of one session.
+func (l *Ledger) SessionCost(sessionID string) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var total float64
+	for _, e := range l.entries {
+		if e.SessionID == sessionID {
+			total += e.CostUSD
+		}
+	}
+	return total
+}
+
+// Budget limits spend. Zero limits are off.
+type Budget struct {
+	SessionUSD  float64
+	DailyUSD    float64
+	WarnPercent int
+}
+
+// Budget states.
+const (
+	BudgetOK       = "ok"
+	BudgetWarning  = "warning"
+	BudgetExceeded = "exceeded"
+)
+
+// State classifies spent against limit.
+func (b Budget) State(spent, limit float64) string {
+	switch {
+	case limit <= 0:
+		return BudgetOK
+	case spent >= limit:
+		return BudgetExceeded
+	case spent >= limit*float64(b.WarnPercent)/100:
+		return BudgetWarning
+	}
+	return BudgetOK
+}
+
+// CheckSpawn returns an error wrapping ErrBudgetExceeded when today's spend
+// has reached the daily budget, or when a session in workspaceID has reached
+// the session budget.
+func (l *Ledger) CheckSpawn(b Budget, workspaceID string) error {
+	if b.DailyUSD > 0 {
+		if spent := l.DayCost(l.Today()); spent >= b.DailyUSD {
+			return fmt.Errorf("%w: daily budget of $%.2f spent ($%.2f today)", ErrBudgetExceeded, b.DailyUSD, spent)
+		}
+	}
+	if b.SessionUSD <= 0 || workspaceID == "" {
+		return nil
+	}
+	l.mu.Lock()
+	spent := make(map[string]float64)
+	for _, e := range l.entries {
+		if e.SessionID != "" && e.WorkspaceID == workspaceID {
+			spent[e.SessionID] += e.CostUSD
+		}
+	}
+	l.mu.Unlock()
+	ids := make([]string, 0, len(spent))
+	for id := range spent {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if spent[id] >= b.SessionUSD {
+			return fmt.Errorf("%w: session %s spent $%.2f of its $%.2f budget", ErrBudgetExceeded, id, spent[id], b.SessionUSD)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package cost
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testPrices(model string) (Price, bool) {
+	if model == "claude-sonnet-4-5" {
+		return Price{Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75}, true
+	}
+	return Price{}, false
+}
+
+func newTestLedger(t *testing.T) (*Ledger, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "costs.json")
+	l := NewLedger(path, testPrices, nil)
+	l.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local) }
+	return l, path
+}
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestPriceCost(t *testing.T) {
+	p := Price{Input: 3, Output: 15}
+	got := p.Cost(Usage{InputTokens: 1_000_000, OutputTokens: 100_000, CacheReadTokens: 1_000_000})
+	// Cache reads fall back to the input price.
+	if !approx(got, 3+1.5+3) {
+		t.Errorf("Cost() = %v", got)
+	}
+}
+
+func TestLedger_RecordSessionAddsGrowth(t *testing.T) {
+	l, path := newTestLedger(t)
+	src := Source{SessionID: "s-1", WorkspaceID: "ws-1", Repo: "schmux", Target: "claude"}
+
+	l.RecordSession(src, "/t/a.jsonl", map[string]Usage{"claude-sonnet-4-5": {InputTokens: 1_000_000}})
+	l.RecordSession(src, "/t/a.jsonl", map[string]Usage{"claude-sonnet-4-5": {InputTokens: 1_000_000, OutputTokens: 1_000_000}})
+	// A second transcript (after /clear) starts its own totals.
+	l.RecordSession(src, "/t/b.jsonl", map[string]Usage{"claude-sonnet-4-5": {InputTokens: 1_000_000}})
+
+	entries := l.Entries("")
+	if len(entries) != 1 {
+		t.Fatalf("entries = %+v", entries)
+	}
+	e := entries[0]
+	if e.InputTokens != 2_000_000 || e.OutputTokens != 1_000_000 || !approx(e.CostUSD, 6+15) {
+		t.Errorf("entry = %+v", e)
+	}
+
+	reloaded := NewLedger(path, testPrices, nil)
+	reloaded.now = l.now
+	reloaded.RecordSession(src, "/t/a.jsonl", map[string]Usage{"claude-sonnet-4-5": {InputTokens: 1_000_000, OutputTokens: 1_000_000}})
+	if got := reloaded.SessionCosts()["s-1"]; !approx(got, 21) {
+		t.Errorf("cost after reload = %v, want 21 (repeat report must add nothing)", got)
+	}
+}
+
+func TestLedger_UnpricedModel(t *testing.T) {
+	l, _ := newTestLedger(t)
+	l.RecordCall(Source{Feature: "nudgenik"}, "mystery-model", Usage{InputTokens: 10})
+	e := l.Entries("")[0]
+	if !e.Unpriced || e.CostUSD != 0 || e.Feature != "nudgenik" {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+func TestLedger_CheckSpawn(t *testing.T) {
+	l, _ := newTestLedger(t)
+	l.RecordSession(Source{SessionID: "s-1", WorkspaceID: "ws-1"}, "/t/a.jsonl", map[string]Usage{"claude-sonnet-4-5": {OutputTokens: 1_000_000}})
+
+	if err := l.CheckSpawn(Budget{DailyUSD: 20}, "ws-1"); err != nil {
+		t.Errorf("under daily budget: %v", err)
+	}
+	if err := l.CheckSpawn(Budget{DailyUSD: 15}, ""); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("daily budget spent: err = %v", err)
+	}
+	if err := l.CheckSpawn(Budget{SessionUSD: 10}, "ws-1"); err == nil || !strings.Contains(err.Error(), "s-1") {
+		t.Errorf("session budget spent: err = %v", err)
+	}
+	if err := l.CheckSpawn(Budget{SessionUSD: 10}, "ws-2"); err != nil {
+		t.Errorf("other workspace: %v", err)
+	}
+}
+
+func TestBudgetState(t *testing.T) {
+	b := Budget{WarnPercent: 80}
+	for _, tt := range []struct {
+		spent, limit float64
+		want         string
+	}{
+		{5, 0, BudgetOK},
+		{5, 10, BudgetOK},
+		{8, 10, BudgetWarning},
+		{10, 10, BudgetExceeded},
+	} {
+		if got := b.State(tt.spent, tt.limit); got != tt.want {
+			t.Errorf("State(%v, %v) = %q, want %q", tt.spent, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestClaudeTranscriptUsage(t *testing.T) {
+	lines := []string{
+		`{"type":"user","message":{"role":"user","content":"hi"}}`,
+		`{"type":"assistant","message":{"id":"msg_1","model":"claude-sonnet-4-5","usage":{"input_tokens":10,"output_tokens":1,"cache_read_input_tokens":100,"cache_creation_input_tokens":5}}}`,
+		`{"type":"assistant","message":{"id":"msg_1","model":"claude-sonnet-4-5","usage":{"input_tokens":10,"output_tokens":7,"cache_read_input_tokens":100,"cache_creation_input_tokens":5}}}`,
+		`{"type":"assistant","message":{"id":"msg_2","model":"claude-haiku-4-5","usage":{"input_tokens":3,"output_tokens":2}}}`,
+		`{"type":"assistant","message":{"id":"msg_3","model":"<synthetic>","usage":{"input_tokens":0,"output_tokens":0}}}`,
+		`not json`,
+	}
+	path := filepath.Join(t.TempDir(), "t.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ClaudeTranscriptUsage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Usage{
+		"claude-sonnet-4-5": {InputTokens: 10, OutputTokens: 7, CacheReadTokens: 100, CacheWriteTokens: 5},
+		"claude-haiku-4-5":  {InputTokens: 3, OutputTokens: 2},
+	}
+	if len(got) != len(want) || got["claude-sonnet-4-5"] != want["claude-sonnet-4-5"] || got["claude-haiku-4-5"] != want["claude-haiku-4-5"] {
+		t.Errorf("ClaudeTranscriptUsage() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClaudeProjectDir(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", "/cfg")
+	got, err := ClaudeProjectDir("/home/me/ws/schmux-001.v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("/cfg", "projects", "-home-me-ws-schmux-001-v2"); got != want {
+		t.Errorf("ClaudeProjectDir() = %q, want %q", got, want)
+	}
+}
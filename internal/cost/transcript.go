@@ -0,0 +1,92 @@
+package cost
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// maxTranscriptLine bounds one transcript line. Lines holding large tool
+// results can run to megabytes.
+const maxTranscriptLine = 64 << 20
+
+// ClaudeProjectDir returns the directory Claude Code keeps the transcripts
+// of sessions started in dir: ~/.claude/projects/ followed by dir with every
+// character other than an ASCII letter or digit replaced by '-'.
+// CLAUDE_CONFIG_DIR replaces ~/.claude when set.
+func ClaudeProjectDir(dir string) (string, error) {
+	configDir := os.Getenv("CLAUDE_CONFIG_DIR")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configDir = filepath.Join(home, ".claude")
+	}
+	encoded := []byte(dir)
+	for i, c := range encoded {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			encoded[i] = '-'
+		}
+	}
+	return filepath.Join(configDir, "projects", string(encoded)), nil
+}
+
+// ClaudeTranscriptUsage sums the token usage of a Claude Code transcript
+// (JSONL) per model. Claude Code writes one line per content block of an
+// assistant message, each repeating the message's usage, so usage is counted
+// once per message ID.
+func ClaudeTranscriptUsage(path string) (map[string]Usage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	type message struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+		} `json:"usage"`
+	}
+	perMessage := make(map[string]message)
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), maxTranscriptLine)
+	for sc.Scan() {
+		var line struct {
+			Type    string   `json:"type"`
+			Message *message `json:"message"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil || line.Type != "assistant" || line.Message == nil {
+			continue
+		}
+		m := *line.Message
+		// Synthetic messages (API errors, interrupts) carry no real model.
+		if m.ID == "" || m.Model == "" || m.Model == "<synthetic>" {
+			continue
+		}
+		// Later lines of a message carry the final output count.
+		perMessage[m.ID] = m
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read transcript: %w", err)
+	}
+
+	totals := make(map[string]Usage)
+	for _, m := range perMessage {
+		totals[m.Model] = totals[m.Model].Add(Usage{
+			InputTokens:      m.Usage.InputTokens,
+			OutputTokens:     m.Usage.OutputTokens,
+			CacheReadTokens:  m.Usage.CacheReadInputTokens,
+			CacheWriteTokens: m.Usage.CacheCreationInputTokens,
+		})
+	}
+	return totals, nil
+}
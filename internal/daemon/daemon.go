@@ -23,10 +23,13 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/google/uuid"
+	"github.com/sergeknystautas/schmux/internal/acceptance"
+	"github.com/sergeknystautas/schmux/internal/agentmsg"
 	"github.com/sergeknystautas/schmux/internal/api/contracts"
 	"github.com/sergeknystautas/schmux/internal/autolearn"
 	"github.com/sergeknystautas/schmux/internal/compound"
 	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/cost"
 	"github.com/sergeknystautas/schmux/internal/dashboard"
 	"github.com/sergeknystautas/schmux/internal/dashboardsx"
 	"github.com/sergeknystautas/schmux/internal/detect"
@@ -39,19 +42,26 @@ import (
 	"github.com/sergeknystautas/schmux/internal/models"
 	"github.com/sergeknystautas/schmux/internal/nudgenik"
 	"github.com/sergeknystautas/schmux/internal/oneshot"
+	"github.com/sergeknystautas/schmux/internal/pipeline"
+	"github.com/sergeknystautas/schmux/internal/ptyhost"
+	"github.com/sergeknystautas/schmux/internal/redact"
 	"github.com/sergeknystautas/schmux/internal/remote"
 	"github.com/sergeknystautas/schmux/internal/repofeed"
 	"github.com/sergeknystautas/schmux/internal/schema"
 	"github.com/sergeknystautas/schmux/internal/schmuxdir"
+	"github.com/sergeknystautas/schmux/internal/search"
 	"github.com/sergeknystautas/schmux/internal/session"
 	"github.com/sergeknystautas/schmux/internal/spawn"
+	"github.com/sergeknystautas/schmux/internal/spawnlog"
 	"github.com/sergeknystautas/schmux/internal/state"
 	"github.com/sergeknystautas/schmux/internal/telemetry"
 	"github.com/sergeknystautas/schmux/internal/timelapse"
 	"github.com/sergeknystautas/schmux/internal/tmux"
+	"github.com/sergeknystautas/schmux/internal/tournament"
 	"github.com/sergeknystautas/schmux/internal/tunnel"
 	"github.com/sergeknystautas/schmux/internal/update"
 	"github.com/sergeknystautas/schmux/internal/version"
+	"github.com/sergeknystautas/schmux/internal/webhook"
 	"github.com/sergeknystautas/schmux/internal/workspace"
 	"github.com/sergeknystautas/schmux/internal/workspace/ensure"
 )
@@ -84,6 +94,18 @@ type Daemon struct {
 	clipboardReconcile chan struct{}
 
 	githubStatus contracts.GitHubStatus
+
+	// timelapseMarkers routes chapter markers to running recorders. Nil
+	// when timelapse recording is disabled.
+	timelapseMarkers *timelapse.Markers
+
+	// acceptanceRunner runs acceptance checks under shutdownCtx; shutdown
+	// waits for the runs it cuts short.
+	acceptanceRunner *acceptance.Runner
+
+	// pipelineRunner spawns pipeline stages under shutdownCtx; shutdown
+	// waits for the spawns it cuts short.
+	pipelineRunner *pipeline.Runner
 }
 
 // shutdownHandles bundles the handles needed by the shutdown sequence.
@@ -607,6 +629,8 @@ func (d *Daemon) initConfigAndState(devMode bool) (*daemonInit, error) {
 	if cfg.TmuxBinary != "" {
 		logger.Info("using custom tmux binary", "path", cfg.TmuxBinary)
 	}
+	// Recordings, captures and LLM prompts are redacted per the live config.
+	redact.SetConfig(cfg)
 
 	// Construct the TmuxServer that all subsystems will share.
 	tmuxBin := cfg.TmuxBinary
@@ -724,8 +748,14 @@ func (d *Daemon) initManagers(
 	autolearnInstructionsDir := filepath.Join(schmuxDir, "autolearn", "instructions")
 	ensure.SetInstructionStore(autolearn.NewInstructionStore(autolearnInstructionsDir))
 	wm := workspace.New(cfg, st, statePath, workspaceLog)
+	wm.SetShutdownContext(d.shutdownCtx)
 	// SetBroadcastFn will be wired after server creation (see below)
 	sm := session.New(cfg, st, statePath, wm, tmuxServer, sessionLog)
+	sm.SetShutdownContext(d.shutdownCtx)
+
+	// Persist session output so terminal history survives restarts
+	sm.SetScrollbackDir(schmuxdir.ScrollbackDir())
+	sm.PruneScrollback()
 
 	// Wire timelapse recording if enabled
 	if cfg.GetTimelapseEnabled() {
@@ -736,12 +766,17 @@ func (d *Daemon) initManagers(
 			sessionLog.Info(notice)
 		}
 
+		d.timelapseMarkers = timelapse.NewMarkers()
 		sm.SetRecorderFactory(func(sessionID string, outputLog *session.OutputLog, gapCh <-chan session.SourceEvent, width, height int) session.Runnable {
 			rec, err := timelapse.NewRecorder(sessionID, outputLog, gapCh, recordingsDir, maxBytes, width, height)
 			if err != nil {
 				sessionLog.Warn("failed to create timelapse recorder", "session", sessionID, "err", err)
 				return nil
 			}
+			rec.SetMarkers(d.timelapseMarkers)
+			if sess, ok := st.GetSession(sessionID); ok && sess.Fence {
+				rec.WatchFenceLog(filepath.Join(schmuxdir.FenceLaunchDir(sess.WorkspaceID, sessionID), "monitor.log"))
+			}
 			return rec
 		})
 	}
@@ -861,6 +896,18 @@ func (d *Daemon) initDashboard(
 	return server, mm, prDiscovery, nil
 }
 
+// withoutEventHandler returns a copy of handlers with h removed, preserving
+// the order of every other handler registered for the event type.
+func withoutEventHandler(handlers []events.EventHandler, h events.EventHandler) []events.EventHandler {
+	out := make([]events.EventHandler, 0, len(handlers))
+	for _, existing := range handlers {
+		if existing != h {
+			out = append(out, existing)
+		}
+	}
+	return out
+}
+
 // wireCallbacks wires model manager, remote manager, tunnel manager, event
 // handlers, and floor manager into the daemon subsystems. It returns the four
 // values needed by the rest of Run(): remoteManager, tunnelMgr,
@@ -974,6 +1021,69 @@ func (d *Daemon) wireCallbacks(
 	})
 	eventHandlers["resume_id"] = []events.EventHandler{resumeIDHandler}
 
+	// Pipelines: definitions live beside spawn entries; the runner advances
+	// runs as stage sessions report status.
+	pipelineRunner := pipeline.NewRunner(d.shutdownCtx, filepath.Join(filepath.Dir(statePath), "pipeline-runs.json"), logging.Sub(logger, "pipeline"))
+	d.pipelineRunner = pipelineRunner
+	server.SetPipelines(spawn.NewPipelineStore(filepath.Join(filepath.Dir(statePath), "emergence")), pipelineRunner)
+	eventHandlers["status"] = append(eventHandlers["status"], pipelineRunner)
+
+	// Tournaments: candidates are compared once they all report completed.
+	tournamentRunner := tournament.NewRunner(filepath.Join(filepath.Dir(statePath), "tournaments.json"), logging.Sub(logger, "tournament"))
+	server.SetTournaments(tournamentRunner)
+	eventHandlers["status"] = append(eventHandlers["status"], tournamentRunner)
+
+	// Acceptance: configured commands verify a session's work when it
+	// reports completed.
+	d.acceptanceRunner = acceptance.NewRunner(d.shutdownCtx, filepath.Join(filepath.Dir(statePath), "acceptance.json"), logging.Sub(logger, "acceptance"))
+	server.SetAcceptance(d.acceptanceRunner)
+	eventHandlers["status"] = append(eventHandlers["status"], d.acceptanceRunner)
+
+	// Webhooks: accepted status changes (HandleStatusEvent), workspace
+	// lifecycle and build monitor transitions are POSTed to configured endpoints.
+	server.SetWebhooks(webhook.NewDispatcher(filepath.Join(filepath.Dir(statePath), "webhook-deliveries.json"), logging.Sub(logger, "webhook")))
+	wm.SetLifecycleFn(server.HandleWorkspaceLifecycle)
+
+	// Concurrency limits: a completed agent frees its slot, so the queue
+	// drains on status events as well as on dispose.
+	sm.SetQueueCallback(server.BroadcastSessions)
+	eventHandlers["status"] = append(eventHandlers["status"], sm.QueueEventHandler())
+
+	// Workspace checkpoints: snapshot the working tree whenever an agent
+	// changes state; file changes are checkpointed from git status on a debounce.
+	eventHandlers["status"] = append(eventHandlers["status"], wm.CheckpointEventHandler())
+
+	// Timelapse chapters: status changes and tells are marked in recordings.
+	if d.timelapseMarkers != nil {
+		eventHandlers["status"] = append(eventHandlers["status"], d.timelapseMarkers)
+		server.SetTimelapseMarkers(d.timelapseMarkers)
+	}
+
+	// Cost accounting: sessions report their transcript after every turn,
+	// oneshot calls report their own usage; both are priced with registry
+	// costs. Budgets gate new spawns.
+	costLedger := cost.NewLedger(filepath.Join(filepath.Dir(statePath), "costs.json"), mm.PriceFor, logging.Sub(logger, "cost"))
+	server.SetCostLedger(costLedger)
+	oneshot.SetUsageRecorder(server.RecordOneshotUsage)
+	eventHandlers["usage"] = []events.EventHandler{events.NewUsageHandler(server.HandleSessionUsage)}
+	sm.SetSpawnGate(server.CheckSpawnBudget)
+
+	// Agent-to-agent messages: routed by nickname, session ID or group and
+	// typed into the recipient like tell, rate limited per sender.
+	agentRouter := agentmsg.NewRouter(st, filepath.Join(filepath.Dir(statePath), "agent-messages.json"), logging.Sub(logger, "agentmsg"))
+	agentRouter.SetRateFunc(cfg.GetAgentMessageRatePerMinute)
+	agentRouter.SetDeliverFunc(server.DeliverAgentMessage)
+	server.SetAgentRouter(agentRouter)
+	eventHandlers["message"] = []events.EventHandler{events.NewMessageHandler(func(sessionID string, ev events.MessageEvent) {
+		if cfg.GetAgentMessagesEnabled() {
+			agentRouter.Send(sessionID, ev.To, ev.Text)
+		}
+	})}
+
+	// Full-text search over session output, events and spawn prompts.
+	spawnLogPath, _ := spawnlog.SourcePath("spawn")
+	server.SetSearchIndexer(search.NewIndexer(schmuxdir.ScrollbackDir(), spawnLogPath))
+
 	// Monitor handler: always registered, checks debug_ui config per event.
 	// Orthogonal to devMode — debug_ui controls diagnostics independently.
 	monitorHandler := events.NewMonitorHandler(func(sessionID string, raw events.RawEvent, data []byte) {
@@ -981,7 +1091,7 @@ func (d *Daemon) wireCallbacks(
 			server.BroadcastEvent(sessionID, data)
 		}
 	})
-	for _, eventType := range []string{"status", "failure", "reflection", "friction"} {
+	for _, eventType := range []string{"status", "failure", "reflection", "friction", "message"} {
 		eventHandlers[eventType] = append(eventHandlers[eventType], monitorHandler)
 	}
 	sm.SetEventHandlers(eventHandlers)
@@ -1022,7 +1132,7 @@ func (d *Daemon) wireCallbacks(
 		fm.Stop() // kills the tmux session
 		server.SetFloorManager(nil)
 		// Rebuild event handlers without the injector
-		eventHandlers["status"] = []events.EventHandler{dashHandler, monitorHandler}
+		eventHandlers["status"] = withoutEventHandler(eventHandlers["status"], fmInjector)
 		sm.SetEventHandlers(eventHandlers)
 		fm = nil
 		fmInjector = nil
@@ -1039,7 +1149,7 @@ func (d *Daemon) wireCallbacks(
 		fmInjector.Stop()
 		fm.Detach() // keeps the tmux session alive
 		server.SetFloorManager(nil)
-		eventHandlers["status"] = []events.EventHandler{dashHandler, monitorHandler}
+		eventHandlers["status"] = withoutEventHandler(eventHandlers["status"], fmInjector)
 		sm.SetEventHandlers(eventHandlers)
 		fm = nil
 		fmInjector = nil
@@ -1119,8 +1229,13 @@ func (d *Daemon) restoreSessions(
 	gitWatcherLog *log.Logger,
 ) *workspace.GitWatcher {
 	// Start tmux servers for all sockets that restored sessions live on.
+	// PTY sessions have no socket; mapping them to "default" would touch the
+	// user's personal tmux server.
 	activeSocketSet := map[string]bool{cfg.GetTmuxSocketName(): true}
 	for _, sess := range st.GetSessions() {
+		if sess.IsPTYSession() {
+			continue
+		}
 		socket := sess.TmuxSocket
 		if socket == "" {
 			socket = "default"
@@ -1143,7 +1258,10 @@ func (d *Daemon) restoreSessions(
 		timeoutCtx, cancel := context.WithTimeout(d.shutdownCtx, cfg.XtermQueryTimeout())
 		server := sm.ServerForSocket(sess.TmuxSocket)
 		exists := false
-		if server != nil {
+		if sess.IsPTYSession() {
+			// pty supervisors are detached, so they survive daemon restarts.
+			exists = ptyhost.Alive(ptyhost.SocketPath(sess.ID))
+		} else if server != nil {
 			exists = server.SessionExists(timeoutCtx, sess.TmuxSession)
 		}
 		cancel()
@@ -1214,6 +1332,10 @@ func (d *Daemon) restoreSessions(
 		server.BroadcastSessions()
 	}()
 
+	// Resume draining spawns that were queued by concurrency limits before
+	// the restart.
+	sm.StartQueue(d.shutdownCtx)
+
 	// Clear orphaned git lock files left behind by prior daemon incarnations
 	// or killed git processes. Runs before the watcher attaches so our own
 	// fds don't count as owners.
@@ -1224,6 +1346,16 @@ func (d *Daemon) restoreSessions(
 	gitWatcher := workspace.NewGitWatcher(cfg, wm, server.BroadcastSessions, gitWatcherLog)
 	if gitWatcher != nil {
 		wm.SetGitWatcher(gitWatcher)
+		if markers := d.timelapseMarkers; markers != nil {
+			gitWatcher.SetCommitFn(func(workspaceID, hash, subject string) {
+				label := fmt.Sprintf("commit %.7s: %s", hash, subject)
+				for _, sess := range st.GetSessions() {
+					if sess.WorkspaceID == workspaceID {
+						markers.Add(sess.ID, label)
+					}
+				}
+			})
+		}
 		// Add watches for all existing local git workspaces (skip remote and non-git)
 		for _, w := range st.GetWorkspaces() {
 			if w.RemoteHostID == "" && workspace.IsGitVCS(w.VCS) {
@@ -1455,6 +1587,12 @@ func (d *Daemon) startBackgroundJobs(
 			pollWg.Wait()
 			cancel()
 			server.BroadcastSessions()
+			// Refresh and refill warm pools against what was just fetched,
+			// keep disk usage measured and within quota, and clean up
+			// workspaces whose work has landed.
+			go wm.MaintainWarmPools(d.shutdownCtx)
+			go wm.MaintainDiskUsage(d.shutdownCtx)
+			go wm.MaintainAutoCleanup(d.shutdownCtx)
 		}
 		for {
 			select {
@@ -1478,6 +1616,9 @@ func (d *Daemon) startBackgroundJobs(
 				pollWg.Wait()
 				cancel()
 				server.BroadcastSessions()
+				go wm.MaintainWarmPools(d.shutdownCtx)
+				go wm.MaintainDiskUsage(d.shutdownCtx)
+				go wm.MaintainAutoCleanup(d.shutdownCtx)
 			case <-d.shutdownCtx.Done():
 				return
 			}
@@ -1823,6 +1964,18 @@ func (d *Daemon) shutdown(h shutdownHandles) error {
 		h.compounder.Stop()
 	}
 
+	// Kill running acceptance checks and pipeline stage spawns, and wait
+	// for them to return
+	if d.cancelFunc != nil {
+		d.cancelFunc()
+	}
+	if d.acceptanceRunner != nil {
+		d.acceptanceRunner.Wait()
+	}
+	if d.pipelineRunner != nil {
+		d.pipelineRunner.Wait()
+	}
+
 	// Detach floor manager — leave tmux session alive for reconnection on restart
 	h.detachFloorManager()
 
@@ -1865,10 +2018,12 @@ func startNudgeNikChecker(ctx context.Context, cfg *config.Config, st *state.Sta
 		return
 	}
 
+	// Output seq at the last ask that produced no nudge, per session.
+	askedAt := make(map[string]uint64)
 	for {
 		select {
 		case <-ticker.C:
-			checkInactiveSessionsForNudge(ctx, cfg, st, sm, onUpdate, logger)
+			checkInactiveSessionsForNudge(ctx, cfg, st, sm, askedAt, onUpdate, logger)
 		case <-ctx.Done():
 			return
 		}
@@ -1876,7 +2031,9 @@ func startNudgeNikChecker(ctx context.Context, cfg *config.Config, st *state.Sta
 }
 
 // checkInactiveSessionsForNudge checks all sessions for inactivity and asks NudgeNik if needed.
-func checkInactiveSessionsForNudge(ctx context.Context, cfg *config.Config, st *state.State, sm *session.Manager, onUpdate func(), logger *log.Logger) {
+// askedAt remembers where each session's output stood when NudgeNik last found
+// nothing to say, so an idle session is not asked again until its screen changes.
+func checkInactiveSessionsForNudge(ctx context.Context, cfg *config.Config, st *state.State, sm *session.Manager, askedAt map[string]uint64, onUpdate func(), logger *log.Logger) {
 	// Check if nudgenik is enabled (non-empty target)
 	target := cfg.GetNudgenikTarget()
 	if target == "" {
@@ -1886,6 +2043,16 @@ func checkInactiveSessionsForNudge(ctx context.Context, cfg *config.Config, st *
 	now := time.Now()
 	sessions := st.GetSessions()
 
+	live := make(map[string]bool, len(sessions))
+	for _, sess := range sessions {
+		live[sess.ID] = true
+	}
+	for id := range askedAt {
+		if !live[id] {
+			delete(askedAt, id)
+		}
+	}
+
 	for _, sess := range sessions {
 		// Skip if already has a nudge
 		if sess.Nudge != "" {
@@ -1911,40 +2078,47 @@ func checkInactiveSessionsForNudge(ctx context.Context, cfg *config.Config, st *
 			continue
 		}
 
+		tracker, err := sm.GetTracker(sess.ID)
+		if err != nil {
+			logger.Error("failed to get tracker", "session_id", sess.ID, "err", err)
+			continue
+		}
+		if seq, asked := askedAt[sess.ID]; asked && !tracker.ScreenChangedSince(seq) {
+			continue
+		}
+
 		// Session is inactive and has no nudge, ask NudgeNik
 		targetName := cfg.GetNudgenikTarget()
 		logger.Info("asking", "session_id", sess.ID, "target", targetName)
-		nudge := askNudgeNikForSession(ctx, cfg, sess, sm, logger)
-		if nudge != "" {
-			if ok := st.UpdateSessionFunc(sess.ID, func(s *state.Session) { s.Nudge = nudge }); !ok {
-				logger.Error("failed to save nudge (session not found)", "session_id", sess.ID)
-			} else if err := st.Save(); err != nil {
-				logger.Error("failed to persist state", "session_id", sess.ID, "err", err)
-			} else {
-				logger.Info("saved nudge", "session_id", sess.ID)
-				if onUpdate != nil {
-					onUpdate()
-				}
+		seq := tracker.OutputLog().CurrentSeq()
+		nudge := askNudgeNikForSession(ctx, cfg, tracker, logger)
+		if nudge == "" {
+			askedAt[sess.ID] = seq
+			continue
+		}
+		delete(askedAt, sess.ID)
+		if ok := st.UpdateSessionFunc(sess.ID, func(s *state.Session) { s.Nudge = nudge }); !ok {
+			logger.Error("failed to save nudge (session not found)", "session_id", sess.ID)
+		} else if err := st.Save(); err != nil {
+			logger.Error("failed to persist state", "session_id", sess.ID, "err", err)
+		} else {
+			logger.Info("saved nudge", "session_id", sess.ID)
+			if onUpdate != nil {
+				onUpdate()
 			}
 		}
 	}
 }
 
 // askNudgeNikForSession captures the session output and asks NudgeNik for consultation.
-// Captures via SessionRuntime so both local and remote sessions are handled correctly.
-func askNudgeNikForSession(ctx context.Context, cfg *config.Config, sess state.Session, sm *session.Manager, logger *log.Logger) string {
-	// Capture via tracker (handles local and remote sessions via ControlSource)
-	tracker, err := sm.GetTracker(sess.ID)
-	if err != nil {
-		logger.Error("failed to get tracker", "session_id", sess.ID, "err", err)
-		return ""
-	}
-
+// Captures via SessionRuntime so both local and remote sessions are handled correctly;
+// the capture is served from the runtime's screen model.
+func askNudgeNikForSession(ctx context.Context, cfg *config.Config, tracker *session.SessionRuntime, logger *log.Logger) string {
 	captureCtx, cancel := context.WithTimeout(ctx, cfg.XtermOperationTimeout())
 	content, err := tracker.CaptureLastLines(captureCtx, 100)
 	cancel()
 	if err != nil {
-		logger.Error("failed to capture", "session_id", sess.ID, "err", err)
+		logger.Error("failed to capture", "session_id", tracker.ID(), "err", err)
 		return ""
 	}
 
@@ -1954,20 +2128,20 @@ func askNudgeNikForSession(ctx context.Context, cfg *config.Config, sess state.S
 		case errors.Is(err, oneshot.ErrDisabled):
 			// Silently skip - nudgenik is disabled
 		case errors.Is(err, nudgenik.ErrNoResponse):
-			logger.Info("no response extracted", "session_id", sess.ID)
+			logger.Info("no response extracted", "session_id", tracker.ID())
 		case errors.Is(err, oneshot.ErrTargetNotFound):
 			logger.Warn("target not found in config")
 		case errors.Is(err, nudgenik.ErrTargetNoSecrets):
 			logger.Warn("target missing required secrets")
 		default:
-			logger.Error("failed to ask", "session_id", sess.ID, "err", err)
+			logger.Error("failed to ask", "session_id", tracker.ID(), "err", err)
 		}
 		return ""
 	}
 
 	payload, err := json.Marshal(result)
 	if err != nil {
-		logger.Error("failed to serialize result", "session_id", sess.ID, "err", err)
+		logger.Error("failed to serialize result", "session_id", tracker.ID(), "err", err)
 		return ""
 	}
 
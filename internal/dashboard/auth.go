@@ -1,6 +1,7 @@
 package dashboard
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
@@ -56,8 +57,19 @@ func (s *Server) authCookieSecure() bool {
 }
 
 // authMiddleware is a chi-compatible middleware for authentication.
+// A request carrying a bearer API token is authenticated by the token alone,
+// whatever the auth mode, and only for routes within the token's scopes.
 func (s *Server) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := bearerToken(r); ok {
+			tok, status, msg := s.authenticateAPIToken(r, token)
+			if tok == nil {
+				writeJSONError(w, msg, status)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiTokenCtxKey{}, tok)))
+			return
+		}
 		if !s.requiresAuth() {
 			next.ServeHTTP(w, r)
 			return
@@ -78,11 +90,12 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 
 // csrfMiddleware is a chi-compatible middleware for CSRF validation.
 // Used for state-changing endpoints that need cross-site request forgery protection.
-// Local requests (from loopback) are exempt from CSRF checks.
+// Local requests (from loopback) and API token requests, which carry no
+// cookies, are exempt from CSRF checks.
 func (s *Server) csrfMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodOptions {
-			if !s.isTrustedRequest(r) && !s.validateCSRF(r) {
+			if apiTokenFromContext(r.Context()) == nil && !s.isTrustedRequest(r) && !s.validateCSRF(r) {
 				writeJSONError(w, "Forbidden", http.StatusForbidden)
 				return
 			}
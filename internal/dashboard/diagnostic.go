@@ -17,6 +17,7 @@ type DiagnosticCapture struct {
 	Rows        int
 	Counters    map[string]int64
 	TmuxScreen  string
+	ModelScreen string // the runtime's screen model; empty if it could not sync
 	RingBuffer  []byte
 	Findings    []string
 	Verdict     string
@@ -90,6 +91,12 @@ func (d *DiagnosticCapture) WriteToDir(dir string) error {
 	if err := os.WriteFile(filepath.Join(dir, "screen-tmux.txt"), []byte(d.TmuxScreen), 0o600); err != nil {
 		return err
 	}
+	// screen-model.txt
+	if d.ModelScreen != "" {
+		if err := os.WriteFile(filepath.Join(dir, "screen-model.txt"), []byte(d.ModelScreen), 0o600); err != nil {
+			return err
+		}
+	}
 	// tmux-health.json — probe RTT samples for performance trending
 	if len(d.TmuxHealthSamples) > 0 {
 		healthJSON, err := json.MarshalIndent(d.TmuxHealthSamples, "", "  ")
@@ -0,0 +1,214 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sergeknystautas/schmux/internal/acceptance"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/cmdtemplate"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/logging"
+	"github.com/sergeknystautas/schmux/internal/nudgenik"
+	"github.com/sergeknystautas/schmux/internal/workspace"
+)
+
+// handleGetSessionAcceptance returns a session's acceptance state, with the
+// output of its latest run.
+func (s *Server) handleGetSessionAcceptance(w http.ResponseWriter, r *http.Request) {
+	if s.acceptanceRunner == nil {
+		writeJSONError(w, "acceptance not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	a, ok := s.acceptanceRunner.Get(chi.URLParam(r, "sessionID"))
+	if !ok {
+		writeJSONError(w, "session has no acceptance runs", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, a)
+}
+
+// handleRunSessionAcceptance runs a session's acceptance commands now.
+func (s *Server) handleRunSessionAcceptance(w http.ResponseWriter, r *http.Request) {
+	if s.acceptanceRunner == nil {
+		writeJSONError(w, "acceptance not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	sessionID := chi.URLParam(r, "sessionID")
+	if _, ok := s.state.GetSession(sessionID); !ok {
+		writeJSONError(w, "session not found", http.StatusNotFound)
+		return
+	}
+	a, err := s.acceptanceRunner.Run(sessionID)
+	switch {
+	case errors.Is(err, acceptance.ErrRunning):
+		writeJSONError(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, acceptance.ErrNotConfigured):
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+	case err != nil:
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+	default:
+		writeJSON(w, a)
+	}
+}
+
+// validateAcceptanceRequest checks a spawn's acceptance options. Returns an
+// empty string when valid.
+func validateAcceptanceRequest(req SpawnRequest) string {
+	opts := req.Acceptance
+	switch {
+	case req.RemoteProfileID != "" || req.RemoteHostID != "":
+		return "acceptance commands are not supported for remote spawns"
+	case len(opts.Commands) == 0:
+		return "acceptance requires at least one command"
+	case opts.MaxRetries != nil && *opts.MaxRetries < 0:
+		return "acceptance max_retries must not be negative"
+	}
+	seen := make(map[string]bool, len(opts.Commands))
+	for _, c := range opts.Commands {
+		switch {
+		case c.Name == "":
+			return "acceptance command name is required"
+		case seen[c.Name]:
+			return "duplicate acceptance command name: " + c.Name
+		case len(c.Command) == 0:
+			return fmt.Sprintf("acceptance command %s has no command", c.Name)
+		case c.TimeoutMs < 0:
+			return fmt.Sprintf("acceptance command %s timeout_ms must not be negative", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return ""
+}
+
+// planAcceptance is the acceptance.PlanFunc. A spawn's own commands win over
+// the repo's; remote sessions have nothing to check, since the commands run
+// in a local workspace.
+func (s *Server) planAcceptance(sessionID string, opts *contracts.AcceptanceOptions) (acceptance.Plan, bool, error) {
+	sess, ok := s.state.GetSession(sessionID)
+	if !ok || sess.RemoteHostID != "" {
+		return acceptance.Plan{}, false, nil
+	}
+	ws, ok := s.state.GetWorkspace(sess.WorkspaceID)
+	if !ok {
+		return acceptance.Plan{}, false, nil
+	}
+
+	var cmds []config.AcceptanceCommand
+	maxRetries := s.config.GetAcceptanceMaxRetries()
+	if opts != nil {
+		for _, c := range opts.Commands {
+			cmds = append(cmds, config.AcceptanceCommand{Name: c.Name, Command: c.Command, TimeoutMs: c.TimeoutMs})
+		}
+		if opts.MaxRetries != nil {
+			maxRetries = *opts.MaxRetries
+		}
+	} else if repo, found := s.config.FindRepoByURL(ws.Repo); found {
+		cmds = s.config.GetAcceptanceCommands(repo.Name)
+	}
+	if len(cmds) == 0 {
+		return acceptance.Plan{}, false, nil
+	}
+
+	defaultBranch := "main"
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if b, err := s.workspace.GetDefaultBranch(ctx, ws.Repo); err == nil {
+		defaultBranch = b
+	}
+	cancel()
+	vars := map[string]string{
+		"WorkspacePath": ws.Path,
+		"WorkspaceID":   ws.ID,
+		"SessionID":     sess.ID,
+		"Branch":        ws.Branch,
+		"DefaultBranch": defaultBranch,
+	}
+
+	plan := acceptance.Plan{WorkspaceID: ws.ID, Dir: ws.Path, MaxRetries: maxRetries}
+	for _, c := range cmds {
+		argv, err := cmdtemplate.Template(c.Command).Render(vars)
+		if err != nil {
+			return acceptance.Plan{}, false, fmt.Errorf("acceptance command %s: %w", c.Name, err)
+		}
+		timeout := time.Duration(c.TimeoutMs) * time.Millisecond
+		if timeout <= 0 {
+			timeout = config.DefaultAcceptanceTimeoutMs * time.Millisecond
+		}
+		plan.Checks = append(plan.Checks, acceptance.Check{Name: c.Name, Argv: argv, Timeout: timeout})
+	}
+	return plan, true, nil
+}
+
+// runAcceptanceCheck is the acceptance.CheckFunc. Commands run like push
+// gates.
+func (s *Server) runAcceptanceCheck(ctx context.Context, dir string, c acceptance.Check) contracts.AcceptanceCheckResult {
+	check := workspace.RunCheck(ctx, dir, c.Argv, c.Timeout, nil)
+	result := contracts.AcceptanceCheckResult{
+		Name:       c.Name,
+		Status:     acceptance.CheckPassed,
+		ExitCode:   check.ExitCode,
+		DurationMs: check.DurationMs,
+		Output:     check.Output,
+		Error:      check.Error,
+	}
+	if !check.Passed {
+		result.Status = acceptance.CheckFailed
+	}
+	return result
+}
+
+// deliverAcceptanceFailure is the acceptance.DeliverFunc: the failure is
+// typed into the session like tell.
+func (s *Server) deliverAcceptanceFailure(sessionID, text string) error {
+	sess, ok := s.state.GetSession(sessionID)
+	if !ok {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	return s.DeliverAgentMessage(sess, text)
+}
+
+// handleAcceptanceResult is the acceptance.ResultFunc. A failed run flips
+// the session's displayed state from Completed to Error; an escalated one
+// also bumps the nudge sequence so the dashboard notifies the user. A pass
+// leaves the agent's own Completed state alone.
+func (s *Server) handleAcceptanceResult(a contracts.SessionAcceptance) {
+	var failed string
+	for _, c := range a.Checks {
+		if c.Status == acceptance.CheckFailed {
+			failed = c.Name
+			break
+		}
+	}
+	var summary string
+	switch a.Status {
+	case contracts.AcceptanceFailed:
+		summary = fmt.Sprintf("Acceptance check %q failed; sent back to the agent (%d of %d retries)", failed, a.Failures, a.MaxRetries)
+	case contracts.AcceptanceEscalated:
+		summary = fmt.Sprintf("Acceptance check %q still failing after %d retries; needs your review", failed, a.MaxRetries)
+	default:
+		return
+	}
+	s.timelapseMarkers.Add(a.SessionID, "acceptance failed: "+failed)
+
+	payload, err := json.Marshal(nudgenik.Result{State: "Error", Summary: summary, Source: "acceptance"})
+	if err != nil {
+		return
+	}
+	eventsLog := logging.Sub(s.logger, "events")
+	if err := s.state.UpdateSessionNudge(a.SessionID, string(payload)); err != nil {
+		eventsLog.Warn("failed to update acceptance nudge", "session_id", a.SessionID, "err", err)
+		return
+	}
+	if a.Status == contracts.AcceptanceEscalated {
+		s.state.IncrementNudgeSeq(a.SessionID)
+	}
+	if err := s.state.Save(); err != nil {
+		eventsLog.Warn("failed to save state", "session_id", a.SessionID, "err", err)
+	}
+	go s.BroadcastSessions()
+}
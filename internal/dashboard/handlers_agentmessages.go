@@ -0,0 +1,59 @@
+package dashboard
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sergeknystautas/schmux/internal/agentmsg"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// SetAgentRouter sets the agent-to-agent message router whose delivery
+// history GET /api/sessions/{sessionID}/messages serves.
+func (s *Server) SetAgentRouter(r *agentmsg.Router) {
+	s.agentRouter = r
+}
+
+// DeliverAgentMessage types an agent message into sess. It runs the same
+// reachability checks and injection as tell; the router has already added
+// the [from <nickname>] prefix.
+func (s *Server) DeliverAgentMessage(sess state.Session, text string) error {
+	if sess.RemoteHostID != "" {
+		if s.remoteManager == nil {
+			return errors.New("remote manager not available")
+		}
+		if s.remoteManager.GetConnection(sess.RemoteHostID) == nil {
+			return errors.New("remote host not connected")
+		}
+	} else if sess.TmuxSession == "" {
+		return errors.New("session is not running")
+	}
+	return s.injectSessionText(sess.ID, text)
+}
+
+// handleGetSessionMessages returns the agent messages a session sent or
+// received, newest first. ?last=N limits the result.
+func (s *Server) handleGetSessionMessages(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
+
+	lastN := 0
+	if lastStr := r.URL.Query().Get("last"); lastStr != "" {
+		n, err := strconv.Atoi(lastStr)
+		if err != nil || n < 0 {
+			writeJSONError(w, fmt.Sprintf("invalid last: %s", lastStr), http.StatusBadRequest)
+			return
+		}
+		lastN = n
+	}
+
+	resp := contracts.AgentMessagesResponse{Messages: []contracts.AgentMessage{}}
+	if s.agentRouter != nil {
+		resp.Messages = s.agentRouter.History(sessionID, lastN)
+	}
+	writeJSON(w, resp)
+}
@@ -0,0 +1,64 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sergeknystautas/schmux/internal/agentmsg"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func TestHandleGetSessionMessages(t *testing.T) {
+	server, _, st := newTestServer(t)
+	for _, sess := range []state.Session{
+		{ID: "s-a", WorkspaceID: "ws-1", Nickname: "alpha", Status: state.SessionStatusRunning},
+		{ID: "s-b", WorkspaceID: "ws-1", Nickname: "beta", Status: state.SessionStatusRunning},
+	} {
+		if err := st.AddSession(sess); err != nil {
+			t.Fatal(err)
+		}
+	}
+	router := agentmsg.NewRouter(st, filepath.Join(t.TempDir(), "agent-messages.json"), nil)
+	router.SetDeliverFunc(server.DeliverAgentMessage)
+	server.SetAgentRouter(router)
+
+	// s-b has no tmux session, so delivery fails its preflight.
+	router.Send("s-a", "beta", "hello")
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/sessions/s-b/messages"+query, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("sessionID", "s-b")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		server.handleGetSessionMessages(rr, req)
+		return rr
+	}
+
+	rr := get("?last=5")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp contracts.AgentMessagesResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Messages) != 1 {
+		t.Fatalf("messages = %+v", resp.Messages)
+	}
+	m := resp.Messages[0]
+	if m.From != "s-a" || m.FromName != "alpha" || m.Status != contracts.AgentMessageFailed || m.Error != "session is not running" {
+		t.Errorf("message = %+v", m)
+	}
+
+	if rr := get("?last=abc"); rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid last: expected 400, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,154 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/logging"
+)
+
+// apiTokenCtxKey carries the *config.APIToken that authenticated a request.
+type apiTokenCtxKey struct{}
+
+// apiTokenFromContext returns the API token that authenticated the request,
+// or nil for cookie-authenticated and local requests.
+func apiTokenFromContext(ctx context.Context) *config.APIToken {
+	tok, _ := ctx.Value(apiTokenCtxKey{}).(*config.APIToken)
+	return tok
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// apiTokenReadRoutes are the routes the read scope allows, as path.Match
+// patterns. Only side-effect-free GETs belong here: some GET routes connect
+// remote hosts, call paid LLMs, expose config or start scans, and a bearer
+// token skips CSRF.
+var apiTokenReadRoutes = []string{
+	"/metrics",
+	"/api/healthz",
+	"/api/sessions",
+	"/api/sessions/*/events",
+	"/api/sessions/*/acceptance",
+	"/api/sessions/*/messages",
+	"/api/sessions/*/capture",
+	"/api/diff/*",
+	"/api/diff-patch/*",
+	"/api/prs",
+	"/api/pipeline-runs",
+	"/api/pipeline-runs/*",
+	"/api/tournaments",
+	"/api/tournaments/*",
+	"/api/costs",
+	"/api/disk-usage",
+	"/api/workspaces/auto-cleanup",
+	"/api/workspaces/*/checkpoints",
+	"/api/timelapse",
+	"/api/search",
+}
+
+// apiTokenScopeFor returns the scope an API token needs for r, or "" when
+// tokens may not be used for the request at all. Any route not listed here
+// is refused, including token management, auth and dev routes.
+func apiTokenScopeFor(r *http.Request) string {
+	p := strings.TrimSuffix(r.URL.Path, "/")
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		for _, pattern := range apiTokenReadRoutes {
+			if ok, _ := path.Match(pattern, p); ok {
+				return config.APITokenScopeRead
+			}
+		}
+	case http.MethodPost:
+		if p == "/api/spawn" || p == "/api/trigger/spawn" {
+			return config.APITokenScopeSpawn
+		}
+		if ok, _ := path.Match("/api/sessions/*/tell", p); ok {
+			return config.APITokenScopeTell
+		}
+	}
+	return ""
+}
+
+// authenticateAPIToken validates a bearer token and its scope for r. On
+// failure it returns the HTTP status and message to respond with.
+func (s *Server) authenticateAPIToken(r *http.Request, token string) (*config.APIToken, int, string) {
+	tok, err := config.LookupAPIToken(token)
+	if err != nil {
+		logging.Sub(s.logger, "auth").Error("failed to look up API token", "err", err)
+		return nil, http.StatusInternalServerError, "failed to verify token"
+	}
+	if tok == nil {
+		return nil, http.StatusUnauthorized, "Unauthorized"
+	}
+	scope := apiTokenScopeFor(r)
+	if scope == "" || !tok.HasScope(scope) {
+		logging.Sub(s.logger, "auth").Info("API token denied", "token", tok.Name, "method", r.Method, "path", r.URL.Path)
+		return nil, http.StatusForbidden, "token scope does not allow this request"
+	}
+	return tok, 0, ""
+}
+
+func apiTokenInfo(t config.APIToken) contracts.APITokenInfo {
+	return contracts.APITokenInfo{ID: t.ID, Name: t.Name, Scopes: t.Scopes, CreatedAt: t.CreatedAt}
+}
+
+// handleListAPITokens returns the API tokens without their hashes.
+func (s *Server) handleListAPITokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := config.ListAPITokens()
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp := contracts.APITokensResponse{Tokens: []contracts.APITokenInfo{}}
+	for _, t := range tokens {
+		resp.Tokens = append(resp.Tokens, apiTokenInfo(t))
+	}
+	writeJSON(w, resp)
+}
+
+// handleCreateAPIToken creates a token and returns it. This is the only time
+// the token is revealed.
+func (s *Server) handleCreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	var req contracts.CreateAPITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	token, info, err := config.CreateAPIToken(req.Name, req.Scopes)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	logging.Sub(s.logger, "auth").Info("API token created", "token", info.Name, "scopes", info.Scopes)
+	writeJSON(w, contracts.CreateAPITokenResponse{Token: token, Info: apiTokenInfo(info)})
+}
+
+// handleRevokeAPIToken deletes a token by ID or name.
+func (s *Server) handleRevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	ok, err := config.RevokeAPIToken(id)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		writeJSONError(w, "token not found", http.StatusNotFound)
+		return
+	}
+	logging.Sub(s.logger, "auth").Info("API token revoked", "token", id)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,174 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+)
+
+func TestAPITokenScopeFor(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/api/sessions", config.APITokenScopeRead},
+		{http.MethodHead, "/api/healthz", config.APITokenScopeRead},
+		{http.MethodGet, "/api/sessions/s-1/events", config.APITokenScopeRead},
+		{http.MethodGet, "/api/diff-patch/ws-1", config.APITokenScopeRead},
+		{http.MethodGet, "/api/askNudgenik/s-1", ""},
+		{http.MethodGet, "/api/remote/hosts/connect/stream", ""},
+		{http.MethodGet, "/api/config", ""},
+		{http.MethodGet, "/api/repos/scan", ""},
+		{http.MethodGet, "/api/sessions/s-1/events/extra", ""},
+		{http.MethodPost, "/api/sessions/s-1/x/tell", ""},
+		{http.MethodPost, "/api/spawn", config.APITokenScopeSpawn},
+		{http.MethodPost, "/api/trigger/spawn", config.APITokenScopeSpawn},
+		{http.MethodPost, "/api/sessions/s-1/tell", config.APITokenScopeTell},
+		{http.MethodPost, "/api/sessions/s-1/dispose", ""},
+		{http.MethodPut, "/api/config", ""},
+		{http.MethodGet, "/api/tokens", ""},
+		{http.MethodDelete, "/api/tokens/tok-1", ""},
+		{http.MethodGet, "/api/auth/secrets", ""},
+		{http.MethodGet, "/auth/me", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if got := apiTokenScopeFor(r); got != tt.want {
+				t.Errorf("apiTokenScopeFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_APIToken(t *testing.T) {
+	server, cfg, _ := newTestServer(t)
+	// With remote access on, requests from non-loopback addresses are
+	// untrusted and need CSRF unless they carry a token.
+	cfg.RemoteAccess = &config.RemoteAccessConfig{Enabled: boolPtr(true)}
+	spawnToken, _, err := config.CreateAPIToken("ci", []string{config.APITokenScopeSpawn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	readToken, _, err := config.CreateAPIToken("reader", []string{config.APITokenScopeRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotToken *config.APIToken
+	handler := server.authMiddleware(server.csrfMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotToken = apiTokenFromContext(r.Context())
+	})))
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		auth     string
+		wantCode int
+	}{
+		{"scoped request skips csrf", http.MethodPost, "/api/spawn", "Bearer " + spawnToken, http.StatusOK},
+		{"scheme is case-insensitive", http.MethodPost, "/api/trigger/spawn", "bearer " + spawnToken, http.StatusOK},
+		{"missing scope", http.MethodGet, "/api/sessions", "Bearer " + spawnToken, http.StatusForbidden},
+		{"read scope", http.MethodGet, "/api/sessions", "Bearer " + readToken, http.StatusOK},
+		{"read scope refuses get with side effects", http.MethodGet, "/api/askNudgenik/s-1", "Bearer " + readToken, http.StatusForbidden},
+		{"token management denied", http.MethodPost, "/api/tokens", "Bearer " + spawnToken, http.StatusForbidden},
+		{"unknown token", http.MethodPost, "/api/spawn", "Bearer schmux_nope", http.StatusUnauthorized},
+		{"no token still needs csrf", http.MethodPost, "/api/spawn", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotToken = nil
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, r)
+			if rr.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body %s)", rr.Code, tt.wantCode, rr.Body.String())
+			}
+			if tt.wantCode == http.StatusOK && gotToken == nil {
+				t.Errorf("token not in request context: %+v", gotToken)
+			}
+		})
+	}
+}
+
+func TestHandleTriggerSpawn_RequiresToken(t *testing.T) {
+	server, _, _ := newTestServer(t)
+	rr := httptest.NewRecorder()
+	body := `{"repo":"schmux","quick_launch":"fix"}`
+	newTestSpawnHandlers(server).handleTriggerSpawn(rr, httptest.NewRequest(http.MethodPost, "/api/trigger/spawn", strings.NewReader(body)))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rr.Code)
+	}
+}
+
+func TestResolveTriggerSpawn(t *testing.T) {
+	server, cfg, _ := newTestServer(t)
+	prompt := "Fix CI failure {{.run_url}} on {{.branch}}"
+	cfg.Repos = []config.Repo{{Name: "schmux", URL: "https://github.com/user/schmux.git"}}
+	cfg.QuickLaunch = []config.QuickLaunch{
+		{Name: "fix-ci", Target: "claude", Prompt: &prompt},
+		{Name: "lint", Command: "make lint {{.x}}"},
+	}
+	spawnH := newTestSpawnHandlers(server)
+
+	t.Run("renders prompt variables", func(t *testing.T) {
+		got, err := spawnH.resolveTriggerSpawn(t.Context(), contracts.TriggerSpawnRequest{
+			Repo:        "schmux",
+			Branch:      "main",
+			QuickLaunch: "fix-ci",
+			Variables:   map[string]string{"run_url": "https://ci/1", "branch": "main"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Repo != "https://github.com/user/schmux.git" || got.Targets["claude"] != 1 || got.Nickname != "fix-ci" {
+			t.Errorf("unexpected spawn request: %+v", got)
+		}
+		if got.Prompt != "Fix CI failure https://ci/1 on main" {
+			t.Errorf("prompt = %q", got.Prompt)
+		}
+	})
+
+	t.Run("command presets are not templated", func(t *testing.T) {
+		got, err := spawnH.resolveTriggerSpawn(t.Context(), contracts.TriggerSpawnRequest{
+			Repo:        "https://github.com/user/schmux.git",
+			Branch:      "main",
+			QuickLaunch: "lint",
+			Variables:   map[string]string{"x": "; rm -rf /"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Command != "make lint {{.x}}" {
+			t.Errorf("command = %q", got.Command)
+		}
+	})
+
+	errTests := []struct {
+		name string
+		req  contracts.TriggerSpawnRequest
+		want string
+	}{
+		{"missing variable", contracts.TriggerSpawnRequest{Repo: "schmux", Branch: "main", QuickLaunch: "fix-ci", Variables: map[string]string{"run_url": "u"}}, "branch"},
+		{"unknown repo", contracts.TriggerSpawnRequest{Repo: "nope", Branch: "main", QuickLaunch: "fix-ci"}, "repo not found"},
+		{"unknown preset", contracts.TriggerSpawnRequest{Repo: "schmux", Branch: "main", QuickLaunch: "nope"}, "quick launch not found"},
+		{"missing preset", contracts.TriggerSpawnRequest{Repo: "schmux", Branch: "main"}, "quick_launch is required"},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := spawnH.resolveTriggerSpawn(t.Context(), tt.req)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
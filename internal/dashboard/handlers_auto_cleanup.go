@@ -0,0 +1,45 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// handleGetAutoCleanupReport returns the workspaces auto-cleanup would
+// dispose, evaluated now, without disposing any.
+func (h *WorkspaceHandlers) handleGetAutoCleanupReport(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, h.workspace.AutoCleanupReport(r.Context()))
+}
+
+// handleProtectWorkspace sets whether a workspace is protected from
+// auto-cleanup.
+func (h *WorkspaceHandlers) handleProtectWorkspace(w http.ResponseWriter, r *http.Request) {
+	ws, ok := h.requireWorkspace(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	var req struct {
+		Protected bool `json:"protected"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ws.Protected = req.Protected
+	if err := h.state.UpdateWorkspace(ws); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.state.Save(); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.broadcastSessions()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
@@ -3,7 +3,6 @@ package dashboard
 import (
 	"fmt"
 	"net/http"
-	"os/exec"
 	"strings"
 
 	"github.com/sergeknystautas/schmux/internal/vcs"
@@ -19,6 +18,7 @@ type branchEntry struct {
 	Dirty         bool     `json:"dirty"`
 	SessionCount  int      `json:"session_count"`
 	SessionStates []string `json:"session_states"`
+	DiskBytes     int64    `json:"disk_bytes"`
 	Error         string   `json:"error,omitempty"`
 	Disconnected  bool     `json:"disconnected,omitempty"`
 }
@@ -26,11 +26,16 @@ type branchEntry struct {
 func (h *SpawnHandlers) handleGetBranches(w http.ResponseWriter, r *http.Request) {
 	workspaces := h.state.GetWorkspaces()
 	allSessions := h.state.GetSessions()
+	diskBytes := make(map[string]int64)
+	for _, wu := range h.workspace.DiskUsage().Workspaces {
+		diskBytes[wu.WorkspaceID] = wu.Bytes
+	}
 	var entries []branchEntry
 
 	for _, ws := range workspaces {
 		entry := branchEntry{
 			WorkspaceID: ws.ID,
+			DiskBytes:   diskBytes[ws.ID],
 		}
 
 		// Get repo name from config
@@ -79,15 +84,10 @@ func (h *SpawnHandlers) handleGetBranches(w http.ResponseWriter, r *http.Request
 			}
 			populateBranchEntry(run, cb, &entry)
 		} else {
+			shellRun := localShellRun(r.Context(), ws.Path)
 			run := func(cmd string) string {
-				parts := strings.Fields(cmd)
-				if len(parts) == 0 {
-					return ""
-				}
-				c := exec.CommandContext(r.Context(), parts[0], parts[1:]...)
-				c.Dir = ws.Path
-				out, _ := c.Output()
-				return strings.TrimSpace(string(out))
+				out, _ := shellRun(cmd)
+				return out
 			}
 			populateBranchEntry(run, cb, &entry)
 		}
@@ -20,6 +20,7 @@ import (
 	"github.com/sergeknystautas/schmux/internal/logging"
 	"github.com/sergeknystautas/schmux/internal/schmuxdir"
 	"github.com/sergeknystautas/schmux/internal/session"
+	"github.com/sergeknystautas/schmux/internal/webhook"
 )
 
 // buildMonitorStateDir returns the directory for build monitor state files.
@@ -314,6 +315,7 @@ func (s *Server) runBuildMonitorCheckPass(ctx context.Context) (buildMonitorResp
 				changed = true
 			}
 			directives = append(directives, unitDirectives...)
+			s.emitBuildTransitionWebhooks(repo.Name, info.Owner+"/"+info.Repo, branch, events, state)
 		}
 
 		unitResp := buildMonitorUnitResponse{
@@ -339,6 +341,30 @@ func (s *Server) runBuildMonitorCheckPass(ctx context.Context) (buildMonitorResp
 	return response, changed, directives
 }
 
+// emitBuildTransitionWebhooks reports a unit's persisted failure
+// transitions to webhooks, one event per workflow.
+func (s *Server) emitBuildTransitionWebhooks(repoName, repo, branch string, events []buildmonitor.TransitionEvent, st *buildmonitor.UnitState) {
+	for _, ev := range events {
+		data := map[string]any{
+			"repo":         repo,
+			"branch":       branch,
+			"workflow_id":  ev.WorkflowID,
+			"run_id":       ev.RunID,
+			"kind":         ev.Kind,
+			"from_unknown": ev.FromUnknown,
+		}
+		for _, wf := range st.Workflows {
+			if wf.WorkflowID == ev.WorkflowID {
+				data["workflow_name"] = wf.Name
+				data["head_sha"] = wf.HeadSHA
+				data["failed_jobs"] = wf.FailedJobs
+				break
+			}
+		}
+		s.emitWebhook(webhook.Event{Type: contracts.WebhookEventBuildTransition, Repo: repoName, State: ev.Kind, Data: data})
+	}
+}
+
 // BroadcastBuildMonitor sends a build_monitor_updated message to all
 // dashboard WebSocket clients. No payload; clients refetch GET /api/build-monitor.
 func (s *Server) BroadcastBuildMonitor() {
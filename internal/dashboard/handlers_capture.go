@@ -6,12 +6,14 @@ import (
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/sergeknystautas/schmux/internal/redact"
 )
 
 type captureResponse struct {
-	SessionID string `json:"session_id"`
-	Lines     int    `json:"lines"`
-	Output    string `json:"output"`
+	SessionID  string `json:"session_id"`
+	Lines      int    `json:"lines"`
+	Output     string `json:"output"`
+	Redactions int    `json:"redactions,omitempty"`
 }
 
 func (s *Server) handleCaptureSession(w http.ResponseWriter, r *http.Request) {
@@ -59,9 +61,12 @@ func (s *Server) handleCaptureSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	output, redactions := redact.String(redact.SiteCapture, output)
+
 	writeJSON(w, captureResponse{
-		SessionID: sessionID,
-		Lines:     lines,
-		Output:    output,
+		SessionID:  sessionID,
+		Lines:      lines,
+		Output:     output,
+		Redactions: redactions,
 	})
 }
@@ -0,0 +1,112 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/workspace"
+)
+
+// checkpointErrorStatus maps workspace checkpoint errors to HTTP status codes.
+func checkpointErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, workspace.ErrCheckpointsUnsupported), errors.Is(err, workspace.ErrInvalidCheckpointPath):
+		return http.StatusBadRequest
+	case errors.Is(err, workspace.ErrCheckpointNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, workspace.ErrWorkspaceLocked):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// handleListCheckpoints handles GET /api/workspaces/{id}/checkpoints.
+// Returns the workspace's checkpoints, newest first, with diff stats
+// against the commit each was taken on.
+func (h *GitHandlers) handleListCheckpoints(w http.ResponseWriter, r *http.Request) {
+	ws, ok := h.requireWorkspace(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	checkpoints, err := h.workspace.ListCheckpoints(ctx, ws.ID)
+	if err != nil {
+		writeJSONError(w, err.Error(), checkpointErrorStatus(err))
+		return
+	}
+	if checkpoints == nil {
+		checkpoints = []contracts.Checkpoint{}
+	}
+	writeJSON(w, contracts.CheckpointsResponse{Checkpoints: checkpoints})
+}
+
+// handleCreateCheckpoint handles POST /api/workspaces/{id}/checkpoints.
+// Takes a manual checkpoint; returns the latest one if nothing changed.
+func (h *GitHandlers) handleCreateCheckpoint(w http.ResponseWriter, r *http.Request) {
+	ws, ok := h.requireWorkspace(w, r)
+	if !ok {
+		return
+	}
+	if h.workspace.IsWorkspaceLocked(ws.ID) {
+		writeJSONError(w, "workspace is locked by a sync operation", http.StatusConflict)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	cp, err := h.workspace.CreateCheckpoint(ctx, ws.ID, "manual")
+	if err != nil {
+		writeJSONError(w, err.Error(), checkpointErrorStatus(err))
+		return
+	}
+	writeJSON(w, cp)
+}
+
+// handleRestoreCheckpoint handles POST /api/workspaces/{id}/checkpoints/{seq}/restore.
+// Restores the working tree, or only the body's "path", to the checkpoint.
+// The current working tree is checkpointed first and returned as "backup".
+func (h *GitHandlers) handleRestoreCheckpoint(w http.ResponseWriter, r *http.Request) {
+	ws, ok := h.requireWorkspace(w, r)
+	if !ok {
+		return
+	}
+	seq, err := strconv.Atoi(chi.URLParam(r, "seq"))
+	if err != nil || seq <= 0 {
+		writeJSONError(w, "invalid checkpoint", http.StatusBadRequest)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	var req contracts.CheckpointRestoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if h.workspace.IsWorkspaceLocked(ws.ID) {
+		writeJSONError(w, "workspace is locked by a sync operation", http.StatusConflict)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	h.logger.Info("restore checkpoint", "workspace", ws.ID, "seq", seq, "path", req.Path)
+	resp, err := h.workspace.RestoreCheckpoint(ctx, ws.ID, seq, req.Path)
+	if err != nil {
+		writeJSONError(w, err.Error(), checkpointErrorStatus(err))
+		return
+	}
+
+	if _, err := h.workspace.UpdateVCSStatus(ctx, ws.ID); err != nil {
+		h.logger.Warn("failed to update VCS status after checkpoint restore", "err", err)
+	}
+	h.broadcastSessions()
+	writeJSON(w, resp)
+}
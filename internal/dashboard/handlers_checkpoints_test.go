@@ -0,0 +1,114 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func TestHandleCheckpoints(t *testing.T) {
+	server, _, st := newTestServer(t)
+	gitH := newTestGitHandlers(server)
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test"},
+		{"commit", "--allow-empty", "-q", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("draft\n"), 0644)
+
+	ws := state.Workspace{ID: "ws-cp", Repo: "https://github.com/test/repo", Branch: "main", Path: dir}
+	if err := st.AddWorkspace(ws); err != nil {
+		t.Fatalf("failed to add workspace: %v", err)
+	}
+
+	withSeq := func(req *http.Request, seq string) *http.Request {
+		chi.RouteContext(req.Context()).URLParams.Add("seq", seq)
+		return req
+	}
+
+	t.Run("create and list", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		gitH.handleCreateCheckpoint(rr, makeWorkspaceRequest(t, http.MethodPost, "/api/workspaces/ws-cp/checkpoints", "ws-cp", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("create: expected 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		rr = httptest.NewRecorder()
+		gitH.handleListCheckpoints(rr, makeWorkspaceRequest(t, http.MethodGet, "/api/workspaces/ws-cp/checkpoints", "ws-cp", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("list: expected 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp contracts.CheckpointsResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Checkpoints) != 1 || resp.Checkpoints[0].Reason != "manual" || resp.Checkpoints[0].FilesChanged != 1 {
+			t.Errorf("checkpoints = %+v", resp.Checkpoints)
+		}
+	})
+
+	t.Run("restore guards", func(t *testing.T) {
+		tests := []struct {
+			name string
+			seq  string
+			body string
+			want int
+		}{
+			{"invalid seq", "abc", "", http.StatusBadRequest},
+			{"unknown seq", "42", "", http.StatusNotFound},
+			{"path traversal", "1", `{"path":"../outside"}`, http.StatusBadRequest},
+			{"malformed body", "1", `{invalid`, http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			var body []byte
+			if tt.body != "" {
+				body = []byte(tt.body)
+			}
+			req := withSeq(makeWorkspaceRequest(t, http.MethodPost, "/api/workspaces/ws-cp/checkpoints/"+tt.seq+"/restore", "ws-cp", body), tt.seq)
+			rr := httptest.NewRecorder()
+			gitH.handleRestoreCheckpoint(rr, req)
+			if rr.Code != tt.want {
+				t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.want, rr.Code, rr.Body.String())
+			}
+		}
+	})
+
+	t.Run("restore file", func(t *testing.T) {
+		os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("overwritten\n"), 0644)
+		req := withSeq(makeWorkspaceRequest(t, http.MethodPost, "/api/workspaces/ws-cp/checkpoints/1/restore", "ws-cp", []byte(`{"path":"notes.txt"}`)), "1")
+		rr := httptest.NewRecorder()
+		gitH.handleRestoreCheckpoint(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if data, _ := os.ReadFile(filepath.Join(dir, "notes.txt")); string(data) != "draft\n" {
+			t.Errorf("notes.txt = %q, want checkpoint contents", data)
+		}
+	})
+
+	t.Run("workspace not found", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		gitH.handleListCheckpoints(rr, makeWorkspaceRequest(t, http.MethodGet, "/api/workspaces/nonexistent/checkpoints", "nonexistent", nil))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d: %s", rr.Code, rr.Body.String())
+		}
+	})
+}
@@ -509,6 +509,9 @@ func (h *ConfigHandlers) handleConfigUpdate(w http.ResponseWriter, r *http.Reque
 					// so this repo gets its own base instead of the stale one.
 					r.Name = repoNameFromURL(r.URL, cfg.ConflictingBaseNames(r.URL))
 					barePath = r.Name + ".git"
+					if r.VCS == "jj" {
+						barePath = r.Name + ".jj"
+					}
 				}
 			}
 			cfg.Repos[i] = config.Repo{Name: r.Name, URL: r.URL, BarePath: barePath, VCS: r.VCS}
@@ -0,0 +1,251 @@
+package dashboard
+
+import (
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/cost"
+	"github.com/sergeknystautas/schmux/internal/logging"
+)
+
+// defaultCostDays is how many days GET /api/costs covers without ?days=.
+const defaultCostDays = 30
+
+// costGroupKeys maps ?group_by= values to the ledger field rows are keyed by.
+var costGroupKeys = map[string]func(e cost.Entry) string{
+	"day":       func(e cost.Entry) string { return e.Day },
+	"session":   func(e cost.Entry) string { return e.SessionID },
+	"workspace": func(e cost.Entry) string { return e.WorkspaceID },
+	"repo":      func(e cost.Entry) string { return e.Repo },
+	"target":    func(e cost.Entry) string { return e.Target },
+	"feature":   func(e cost.Entry) string { return e.Feature },
+	"model":     func(e cost.Entry) string { return e.Model },
+}
+
+// SetCostLedger sets the token and cost ledger. Sessions report usage through
+// HandleSessionUsage and oneshot calls through RecordOneshotUsage.
+func (s *Server) SetCostLedger(l *cost.Ledger) {
+	s.costLedger = l
+	if s.sessionHandlers != nil {
+		s.sessionHandlers.costLedger = l
+	}
+}
+
+func (s *Server) costBudget() cost.Budget {
+	b := s.config.GetBudgets()
+	return cost.Budget{SessionUSD: b.SessionUSD, DailyUSD: b.DailyUSD, WarnPercent: b.WarnPercent}
+}
+
+// CheckSpawnBudget is the session manager's spawn gate: it refuses new
+// spawns once the daily budget, or the session budget of a session in the
+// target workspace, is spent.
+func (s *Server) CheckSpawnBudget(workspaceID string) error {
+	if s.costLedger == nil {
+		return nil
+	}
+	return s.costLedger.CheckSpawn(s.costBudget(), workspaceID)
+}
+
+// HandleSessionUsage accounts the token usage in a session's transcript.
+// Called from the usage event, which Claude's Stop hook emits after every
+// turn. Transcripts of remote sessions live on the remote host and are
+// skipped. The path comes from the agent, so only files in Claude's
+// transcript directory for the session's workspace are read.
+func (s *Server) HandleSessionUsage(sessionID, transcript string) {
+	if s.costLedger == nil || !filepath.IsAbs(transcript) || filepath.Ext(transcript) != ".jsonl" {
+		return
+	}
+	sess, ok := s.state.GetSession(sessionID)
+	if !ok || sess.RemoteHostID != "" {
+		return
+	}
+	ws, ok := s.state.GetWorkspace(sess.WorkspaceID)
+	if !ok || !inClaudeProjectDir(transcript, ws.Path) {
+		logging.Sub(s.logger, "cost").Warn("ignoring transcript outside the workspace's transcript dir", "session_id", sessionID, "transcript", transcript)
+		return
+	}
+	totals, err := cost.ClaudeTranscriptUsage(transcript)
+	if err != nil {
+		logging.Sub(s.logger, "cost").Debug("failed to read transcript usage", "session_id", sessionID, "err", err)
+		return
+	}
+	src := cost.Source{SessionID: sess.ID, WorkspaceID: sess.WorkspaceID, Target: sess.Target, Repo: s.repoNameForURL(ws.Repo)}
+	s.costLedger.RecordSession(src, transcript, totals)
+	s.warnOnBudget(sess.ID)
+	s.BroadcastSessions()
+}
+
+// inClaudeProjectDir reports whether transcript resolves to a file under
+// Claude's transcript directory for workspacePath. Symlinks are resolved on
+// both sides, so a link cannot point the ledger at another file.
+func inClaudeProjectDir(transcript, workspacePath string) bool {
+	if workspacePath == "" {
+		return false
+	}
+	resolved, err := filepath.EvalSymlinks(transcript)
+	if err != nil {
+		return false
+	}
+	paths := []string{workspacePath}
+	if real, err := filepath.EvalSymlinks(workspacePath); err == nil && real != workspacePath {
+		paths = append(paths, real)
+	}
+	for _, p := range paths {
+		dir, err := cost.ClaudeProjectDir(p)
+		if err != nil {
+			continue
+		}
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			dir = real
+		}
+		if rel, err := filepath.Rel(dir, resolved); err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordOneshotUsage is the oneshot usage recorder: it accounts the tokens
+// of one oneshot call under its schema label.
+func (s *Server) RecordOneshotUsage(rec contracts.OneshotLogRecord) {
+	if s.costLedger == nil {
+		return
+	}
+	u := cost.Usage{
+		InputTokens:      rec.InputTokens,
+		OutputTokens:     rec.OutputTokens,
+		CacheReadTokens:  rec.CacheReadTokens,
+		CacheWriteTokens: rec.CacheWriteTokens,
+	}
+	if u.IsZero() {
+		return
+	}
+	src := cost.Source{Feature: rec.Type, Target: rec.Model}
+	// The record carries the basename of the call's dir, which is the
+	// workspace ID for calls made inside a workspace.
+	if ws, ok := s.state.GetWorkspace(rec.Workspace); ok && rec.Workspace != "" {
+		src.WorkspaceID = ws.ID
+		src.Repo = s.repoNameForURL(ws.Repo)
+	}
+	s.costLedger.RecordCall(src, rec.Model, u)
+	s.warnOnBudget("")
+}
+
+// warnOnBudget logs a warning when today's spend, or sessionID's spend,
+// first reaches the warning threshold or the limit.
+func (s *Server) warnOnBudget(sessionID string) {
+	b := s.costBudget()
+	logger := logging.Sub(s.logger, "cost")
+	day := s.costLedger.Today()
+	spent := s.costLedger.DayCost(day)
+	if state := b.State(spent, b.DailyUSD); s.budgetStateChanged("day:"+day, state) {
+		logger.Warn("daily budget "+state, "day", day, "spent_usd", spent, "budget_usd", b.DailyUSD)
+	}
+	if sessionID == "" {
+		return
+	}
+	spent = s.costLedger.SessionCost(sessionID)
+	if state := b.State(spent, b.SessionUSD); s.budgetStateChanged("session:"+sessionID, state) {
+		logger.Warn("session budget "+state, "session_id", sessionID, "spent_usd", spent, "budget_usd", b.SessionUSD)
+	}
+}
+
+// budgetStateChanged records state under key and reports whether it is a
+// new warning or exceeded state.
+func (s *Server) budgetStateChanged(key, state string) bool {
+	prev, _ := s.costBudgetStates.Swap(key, state)
+	return state != cost.BudgetOK && prev != state
+}
+
+// handleCosts returns token usage and spend grouped by ?group_by= (day,
+// session, workspace, repo, target, feature or model; default day) over the
+// last ?days= days (default 30), with the budget status.
+func (s *Server) handleCosts(w http.ResponseWriter, r *http.Request) {
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = "day"
+	}
+	keyOf, ok := costGroupKeys[groupBy]
+	if !ok {
+		writeJSONError(w, "invalid group_by", http.StatusBadRequest)
+		return
+	}
+	days := defaultCostDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeJSONError(w, "days must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	resp := contracts.CostsResponse{GroupBy: groupBy, Rows: []contracts.CostRow{}}
+	budget := s.costBudget()
+	resp.Budget = contracts.CostBudgetStatus{
+		SessionUSD:  budget.SessionUSD,
+		DailyUSD:    budget.DailyUSD,
+		WarnPercent: budget.WarnPercent,
+		DailyState:  cost.BudgetOK,
+	}
+	if s.costLedger == nil {
+		resp.Since = time.Now().AddDate(0, 0, 1-days).Format(time.DateOnly)
+		resp.Budget.Today = time.Now().Format(time.DateOnly)
+		writeJSON(w, resp)
+		return
+	}
+
+	today, err := time.ParseInLocation(time.DateOnly, s.costLedger.Today(), time.Local)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp.Since = today.AddDate(0, 0, 1-days).Format(time.DateOnly)
+	rows := make(map[string]*contracts.CostRow)
+	for _, e := range s.costLedger.Entries(resp.Since) {
+		key := keyOf(e)
+		row, ok := rows[key]
+		if !ok {
+			row = &contracts.CostRow{Key: key}
+			rows[key] = row
+		}
+		addCostEntry(row, e)
+		addCostEntry(&resp.Total, e)
+	}
+	for _, row := range rows {
+		resp.Rows = append(resp.Rows, *row)
+	}
+	sort.Slice(resp.Rows, func(i, j int) bool {
+		if resp.Rows[i].CostUSD != resp.Rows[j].CostUSD {
+			return resp.Rows[i].CostUSD > resp.Rows[j].CostUSD
+		}
+		return resp.Rows[i].Key < resp.Rows[j].Key
+	})
+
+	resp.Budget.Today = s.costLedger.Today()
+	resp.Budget.TodayUSD = s.costLedger.DayCost(resp.Budget.Today)
+	resp.Budget.DailyState = budget.State(resp.Budget.TodayUSD, budget.DailyUSD)
+	for id, spent := range s.costLedger.SessionCosts() {
+		if state := budget.State(spent, budget.SessionUSD); state != cost.BudgetOK {
+			resp.Budget.Sessions = append(resp.Budget.Sessions, contracts.CostSessionBudget{SessionID: id, CostUSD: spent, State: state})
+		}
+	}
+	sort.Slice(resp.Budget.Sessions, func(i, j int) bool {
+		return resp.Budget.Sessions[i].CostUSD > resp.Budget.Sessions[j].CostUSD
+	})
+	writeJSON(w, resp)
+}
+
+func addCostEntry(row *contracts.CostRow, e cost.Entry) {
+	row.InputTokens += e.InputTokens
+	row.OutputTokens += e.OutputTokens
+	row.CacheReadTokens += e.CacheReadTokens
+	row.CacheWriteTokens += e.CacheWriteTokens
+	row.CostUSD += e.CostUSD
+	row.Unpriced = row.Unpriced || e.Unpriced
+}
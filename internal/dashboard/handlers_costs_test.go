@@ -0,0 +1,96 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/cost"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func TestCostAccounting(t *testing.T) {
+	server, cfg, st := newTestServer(t)
+	cfg.Repos = []config.Repo{{Name: "schmux", URL: "https://github.com/user/schmux.git"}}
+	cfg.Budgets = &config.BudgetsConfig{SessionUSD: 1}
+	wsPath := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	if err := st.AddWorkspace(state.Workspace{ID: "schmux-001", Repo: "https://github.com/user/schmux.git", Branch: "main", Path: wsPath}); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.AddSession(state.Session{ID: "s-1", WorkspaceID: "schmux-001", Target: "claude"}); err != nil {
+		t.Fatal(err)
+	}
+	price := func(model string) (cost.Price, bool) {
+		return cost.Price{Input: 1, Output: 10}, model == "m"
+	}
+	server.SetCostLedger(cost.NewLedger("", price, nil))
+
+	projectDir, err := cost.ClaudeProjectDir(wsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(projectDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	line := `{"type":"assistant","message":{"id":"msg_1","model":"m","usage":{"input_tokens":500000,"output_tokens":100000}}}` + "\n"
+	outside := filepath.Join(t.TempDir(), "t.jsonl")
+	if err := os.WriteFile(outside, []byte(line), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Transcripts outside the workspace's transcript dir are ignored, also
+	// when reached through a symlink inside it.
+	if err := os.Symlink(outside, filepath.Join(projectDir, "link.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	server.HandleSessionUsage("s-1", outside)
+	server.HandleSessionUsage("s-1", filepath.Join(projectDir, "link.jsonl"))
+	if got := server.costLedger.SessionCost("s-1"); got != 0 {
+		t.Fatalf("session cost = %v after transcripts outside the project dir, want 0", got)
+	}
+
+	transcript := filepath.Join(projectDir, "t.jsonl")
+	if err := os.WriteFile(transcript, []byte(line), 0600); err != nil {
+		t.Fatal(err)
+	}
+	server.HandleSessionUsage("s-1", transcript)
+	server.RecordOneshotUsage(contracts.OneshotLogRecord{Type: "commit-message", Model: "m", Workspace: "schmux-001", InputTokens: 1000})
+
+	rr := httptest.NewRecorder()
+	server.handleCosts(rr, httptest.NewRequest(http.MethodGet, "/api/costs?group_by=feature", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp contracts.CostsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Rows) != 2 || resp.Rows[0].Key != "" || resp.Rows[1].Key != "commit-message" {
+		t.Fatalf("rows = %+v, want session row then commit-message", resp.Rows)
+	}
+	if got := resp.Total.CostUSD; got < 1.500999 || got > 1.501001 {
+		t.Errorf("total = %v, want 1.501", got)
+	}
+	if len(resp.Budget.Sessions) != 1 || resp.Budget.Sessions[0].State != cost.BudgetExceeded {
+		t.Errorf("budget sessions = %+v, want s-1 exceeded", resp.Budget.Sessions)
+	}
+
+	if err := server.CheckSpawnBudget("schmux-001"); !errors.Is(err, cost.ErrBudgetExceeded) {
+		t.Errorf("CheckSpawnBudget() = %v, want ErrBudgetExceeded", err)
+	}
+	if err := server.CheckSpawnBudget(""); err != nil {
+		t.Errorf("CheckSpawnBudget(new workspace) = %v, want nil", err)
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleCosts(rr, httptest.NewRequest(http.MethodGet, "/api/costs?group_by=bogus", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("bogus group_by status = %d, want 400", rr.Code)
+	}
+}
@@ -137,6 +137,42 @@ func (h *GitHandlers) handleDiff(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// diffPatchMaxBytes caps the patch served by handleDiffPatch.
+const diffPatchMaxBytes = 1 << 20
+
+// handleDiffPatch serves the workspace branch's unified diff against its fork
+// point from the default branch, untracked files included, as plain text.
+// Only local git workspaces are supported.
+func (h *GitHandlers) handleDiffPatch(w http.ResponseWriter, r *http.Request) {
+	workspaceID := chi.URLParam(r, "*")
+	if workspaceID == "" {
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
+		return
+	}
+	ws, found := h.state.GetWorkspace(workspaceID)
+	if !found {
+		writeJSONError(w, "workspace not found", http.StatusNotFound)
+		return
+	}
+	if ws.RemoteHostID != "" || !workspace.IsGitVCS(ws.VCS) {
+		writeJSONError(w, "patch is only available for local git workspaces", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.config.GetGitStatusTimeoutMs())*time.Millisecond)
+	defer cancel()
+	patch, err := h.workspace.GetBranchPatch(ctx, workspaceID, diffPatchMaxBytes)
+	if err != nil {
+		h.logger.Error("diff patch failed", "workspace_id", workspaceID, "err", err)
+		writeJSONError(w, "diff failed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := io.WriteString(w, patch); err != nil {
+		h.logger.Error("failed to write response", "handler", "diff-patch", "err", err)
+	}
+}
+
 // vcsRunFunc is the function signature for executing a VCS shell command.
 // Returns trimmed output and any error (unlike runFunc which has no error return).
 type vcsRunFunc = func(string) (string, error)
@@ -226,6 +262,19 @@ func parseNameStatusOutput(output, vcsType string) []diffFileSummary {
 			}
 			continue
 		}
+		if vcsType == "jj" && (letter == "R" || letter == "C") {
+			// jj writes renames and copies as one "dir/{old => new}" path.
+			oldPath, newPath := parseRenamePath(parts[1])
+			if newPath == "" {
+				newPath = parts[1]
+			}
+			if letter == "C" {
+				files = append(files, diffFileSummary{Status: "added", NewPath: newPath})
+			} else {
+				files = append(files, diffFileSummary{Status: "renamed", OldPath: oldPath, NewPath: newPath})
+			}
+			continue
+		}
 		switch letter {
 		case "A":
 			files = append(files, diffFileSummary{Status: "added", NewPath: parts[1]})
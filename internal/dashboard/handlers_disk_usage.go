@@ -0,0 +1,9 @@
+package dashboard
+
+import "net/http"
+
+// handleGetDiskUsage returns the last measured disk usage of workspaces,
+// repo bases and recordings, and the configured quota.
+func (h *WorkspaceHandlers) handleGetDiskUsage(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, h.workspace.DiskUsage())
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/charmbracelet/log"
 
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
 	"github.com/sergeknystautas/schmux/internal/config"
 	"github.com/sergeknystautas/schmux/internal/remote"
 	"github.com/sergeknystautas/schmux/internal/session"
@@ -32,6 +33,7 @@ type GitHandlers struct {
 	resumeViteWatch                          func()
 	requireWorkspace                         func(w http.ResponseWriter, r *http.Request) (state.Workspace, bool)
 	vcsTypeForWorkspace                      func(ws state.Workspace) string
+	tellPushGateFailure                      func(workspaceID string, run *contracts.PushGateRun)
 
 	// Linear sync conflict resolution callbacks.
 	getLinearSyncResolveConflictState    func(workspaceID string) *LinearSyncResolveConflictState
@@ -3,7 +3,6 @@ package dashboard
 import (
 	"fmt"
 	"net/http"
-	"os/exec"
 	"strings"
 
 	"github.com/sergeknystautas/schmux/internal/vcs"
@@ -61,15 +60,10 @@ func (h *GitHandlers) handleInspectWorkspace(w http.ResponseWriter, r *http.Requ
 			return strings.TrimSpace(out)
 		}
 	} else {
+		shellRun := localShellRun(r.Context(), ws.Path)
 		run = func(cmd string) string {
-			parts := strings.Fields(cmd)
-			if len(parts) == 0 {
-				return ""
-			}
-			c := exec.CommandContext(r.Context(), parts[0], parts[1:]...)
-			c.Dir = ws.Path
-			out, _ := c.Output()
-			return strings.TrimSpace(string(out))
+			out, _ := shellRun(cmd)
+			return out
 		}
 	}
 
@@ -96,7 +90,7 @@ func (h *GitHandlers) inspect(w http.ResponseWriter, run runFunc, cb vcs.Command
 		resp.RemoteBranch = cb.DefaultBranchRef(resp.Branch)
 	}
 
-	logOutput := run(fmt.Sprintf("git log --oneline %s..HEAD", defaultRef))
+	logOutput := run(cb.LogOneline(defaultRef + "..HEAD"))
 	if logOutput != "" {
 		resp.Commits = strings.Split(logOutput, "\n")
 	} else {
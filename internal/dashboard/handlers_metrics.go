@@ -0,0 +1,258 @@
+package dashboard
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/metrics"
+	"github.com/sergeknystautas/schmux/internal/oneshotlog"
+	"github.com/sergeknystautas/schmux/internal/redact"
+	"github.com/sergeknystautas/schmux/internal/version"
+)
+
+// sessionDiagnosticMetrics maps SessionRuntime.DiagnosticCounters keys to
+// per-session metric families. Keys missing here are not exported.
+var sessionDiagnosticMetrics = []struct {
+	key, name, help string
+	typ             metrics.Type
+}{
+	{"eventsDelivered", "schmux_session_events_delivered_total", "Terminal output events delivered to subscribers.", metrics.TypeCounter},
+	{"bytesDelivered", "schmux_session_bytes_delivered_total", "Terminal output bytes delivered to subscribers.", metrics.TypeCounter},
+	{"controlModeReconnects", "schmux_session_controlmode_reconnects_total", "Control mode reconnects.", metrics.TypeCounter},
+	{"fanOutDrops", "schmux_session_fanout_dropped_total", "Output events dropped fanning out to slow subscribers.", metrics.TypeCounter},
+	{"eventsDropped", "schmux_session_controlmode_events_dropped_total", "Output events dropped by the control mode parser.", metrics.TypeCounter},
+	{"clientFanOutDrops", "schmux_controlmode_fanout_dropped_total", "Output events dropped by the control mode client fan-out.", metrics.TypeCounter},
+	{"wsConnections", "schmux_session_ws_connections_total", "Terminal WebSocket connections opened.", metrics.TypeCounter},
+	{"wsWriteErrors", "schmux_session_ws_write_errors_total", "Terminal WebSocket write errors.", metrics.TypeCounter},
+	{"clipboardDrops", "schmux_session_clipboard_dropped_total", "Clipboard requests dropped.", metrics.TypeCounter},
+	{"clipboardSuppressedAsEcho", "schmux_session_clipboard_echo_suppressed_total", "Clipboard requests suppressed as echoes.", metrics.TypeCounter},
+	{"currentSeq", "schmux_session_output_seq", "Current output log sequence number.", metrics.TypeGauge},
+	{"logTotalBytes", "schmux_session_output_log_bytes", "Bytes held in the output log.", metrics.TypeGauge},
+}
+
+// handleMetrics serves daemon metrics in the Prometheus text format. Values
+// are read from state and the subsystems that already track them; nothing
+// is cached between scrapes.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	if err := metrics.Write(w, s.gatherMetrics()); err != nil {
+		s.logger.Debug("failed to write metrics", "err", err)
+	}
+}
+
+func (s *Server) gatherMetrics() []metrics.Family {
+	buildInfo := metrics.Family{Name: "schmux_build_info", Help: "Daemon version; always 1.", Type: metrics.TypeGauge}
+	buildInfo.Add(1, metrics.Labels{"version": version.Version})
+
+	families := []metrics.Family{buildInfo}
+	families = append(families, s.stateMetrics()...)
+	families = append(families, s.vcsMetrics()...)
+	families = append(families, s.sessionRuntimeMetrics()...)
+	families = append(families, s.remoteMetrics()...)
+	families = append(families, oneshotMetrics()...)
+	families = append(families, redactionMetrics())
+	return families
+}
+
+// countBy adds one sample per distinct label set, counting occurrences.
+func countBy(f *metrics.Family, labelSets []metrics.Labels, keys ...string) {
+	type entry struct {
+		labels metrics.Labels
+		n      int
+	}
+	counts := map[string]*entry{}
+	var order []string
+	for _, ls := range labelSets {
+		id := ""
+		for _, k := range keys {
+			id += ls[k] + "\x00"
+		}
+		e, ok := counts[id]
+		if !ok {
+			e = &entry{labels: ls}
+			counts[id] = e
+			order = append(order, id)
+		}
+		e.n++
+	}
+	sort.Strings(order)
+	for _, id := range order {
+		f.Add(float64(counts[id].n), counts[id].labels)
+	}
+}
+
+func (s *Server) stateMetrics() []metrics.Family {
+	sessions := metrics.Family{Name: "schmux_sessions", Help: "Sessions by lifecycle status, agent state and target.", Type: metrics.TypeGauge}
+	var sessionLabels []metrics.Labels
+	for _, sess := range s.state.GetSessions() {
+		status := sess.Status
+		if status == "" {
+			status = "running"
+		}
+		state, _ := parseNudgeSummary(sess.Nudge)
+		sessionLabels = append(sessionLabels, metrics.Labels{"status": status, "state": state, "target": sess.Target})
+	}
+	countBy(&sessions, sessionLabels, "status", "state", "target")
+
+	workspaces := metrics.Family{Name: "schmux_workspaces", Help: "Workspaces by status and repo.", Type: metrics.TypeGauge}
+	var workspaceLabels []metrics.Labels
+	for _, ws := range s.state.GetWorkspaces() {
+		repo := ws.Repo
+		if cfgRepo, found := s.config.FindRepoByURL(ws.Repo); found {
+			repo = cfgRepo.Name
+		}
+		status := ws.Status
+		if status == "" {
+			status = "ready"
+		}
+		workspaceLabels = append(workspaceLabels, metrics.Labels{"status": status, "repo": repo})
+	}
+	countBy(&workspaces, workspaceLabels, "status", "repo")
+
+	previews := metrics.Family{Name: "schmux_previews", Help: "Preview proxies by status.", Type: metrics.TypeGauge}
+	var previewLabels []metrics.Labels
+	for _, p := range s.state.GetPreviews() {
+		status := p.Status
+		if status == "" {
+			status = "ready"
+		}
+		previewLabels = append(previewLabels, metrics.Labels{"status": status})
+	}
+	countBy(&previews, previewLabels, "status")
+
+	hosts := metrics.Family{Name: "schmux_remote_hosts", Help: "Remote hosts by connection status.", Type: metrics.TypeGauge}
+	var hostLabels []metrics.Labels
+	for _, h := range s.state.GetRemoteHosts() {
+		hostLabels = append(hostLabels, metrics.Labels{"status": h.Status})
+	}
+	countBy(&hosts, hostLabels, "status")
+
+	return []metrics.Family{sessions, workspaces, previews, hosts}
+}
+
+// vcsMetrics exports VCS command durations from IO workspace telemetry. The
+// families are empty unless io_workspace_telemetry is enabled.
+func (s *Server) vcsMetrics() []metrics.Family {
+	provider, ok := s.workspace.(ioWorkspaceTelemetryProvider)
+	if !ok {
+		return nil
+	}
+	snap := provider.IOWorkspaceTelemetrySnapshot(false)
+	duration := metrics.Family{Name: "schmux_vcs_command_duration_seconds", Help: "Time spent in VCS commands run for workspace status, by trigger and command.", Type: metrics.TypeSummary}
+	maxDuration := metrics.Family{Name: "schmux_vcs_command_duration_max_seconds", Help: "Slowest VCS command by trigger and command.", Type: metrics.TypeGauge}
+
+	triggers := make([]string, 0, len(snap.ByTriggerSpans))
+	for trigger := range snap.ByTriggerSpans {
+		triggers = append(triggers, trigger)
+	}
+	sort.Strings(triggers)
+	for _, trigger := range triggers {
+		spans := snap.ByTriggerSpans[trigger]
+		commands := make([]string, 0, len(spans))
+		for command := range spans {
+			commands = append(commands, command)
+		}
+		sort.Strings(commands)
+		for _, command := range commands {
+			st := spans[command]
+			labels := metrics.Labels{"trigger": trigger, "command": command}
+			duration.Samples = append(duration.Samples,
+				metrics.Sample{Suffix: "_sum", Labels: labels, Value: st.TotalMS / 1000},
+				metrics.Sample{Suffix: "_count", Labels: labels, Value: float64(st.Count)},
+			)
+			maxDuration.Add(st.MaxMS/1000, labels)
+		}
+	}
+	return []metrics.Family{duration, maxDuration}
+}
+
+// sessionRuntimeMetrics exports the diagnostic counters of sessions with a
+// running tracker.
+func (s *Server) sessionRuntimeMetrics() []metrics.Family {
+	if s.session == nil {
+		return nil
+	}
+	diags := s.session.RuntimeDiagnostics()
+	ids := make([]string, 0, len(diags))
+	for id := range diags {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	families := make([]metrics.Family, len(sessionDiagnosticMetrics))
+	for i, m := range sessionDiagnosticMetrics {
+		families[i] = metrics.Family{Name: m.name, Help: m.help, Type: m.typ}
+		for _, id := range ids {
+			if v, ok := diags[id][m.key]; ok {
+				families[i].Add(float64(v), metrics.Labels{"session_id": id})
+			}
+		}
+	}
+	return families
+}
+
+func (s *Server) remoteMetrics() []metrics.Family {
+	if s.remoteManager == nil {
+		return nil
+	}
+	drops := metrics.Family{Name: "schmux_remote_controlmode_fanout_dropped_total", Help: "Output events dropped by a remote host's control mode client fan-out.", Type: metrics.TypeCounter}
+	conns := s.remoteManager.GetActiveConnections()
+	sort.Slice(conns, func(i, j int) bool { return conns[i].Host().ID < conns[j].Host().ID })
+	for _, conn := range conns {
+		if client := conn.Client(); client != nil {
+			drops.Add(float64(client.DroppedFanOut()), metrics.Labels{"host_id": conn.Host().ID})
+		}
+	}
+	return []metrics.Family{drops}
+}
+
+func oneshotMetrics() []metrics.Family {
+	calls := metrics.Family{Name: "schmux_oneshot_calls_total", Help: "Oneshot LLM calls by schema type, transport and result.", Type: metrics.TypeCounter}
+	duration := metrics.Family{Name: "schmux_oneshot_duration_seconds", Help: "Oneshot LLM call latency by schema type and transport.", Type: metrics.TypeHistogram}
+	for _, st := range oneshotlog.Stats() {
+		labels := metrics.Labels{"type": st.Type, "transport": st.Transport}
+		calls.Add(float64(st.Calls-st.Errors), metrics.Labels{"type": st.Type, "transport": st.Transport, "result": "ok"})
+		calls.Add(float64(st.Errors), metrics.Labels{"type": st.Type, "transport": st.Transport, "result": "error"})
+		duration.AddHistogram(st.Duration, labels)
+	}
+	return []metrics.Family{calls, duration}
+}
+
+func redactionMetrics() metrics.Family {
+	redactions := metrics.Family{Name: "schmux_redactions_total", Help: "Secrets redacted by site and detector.", Type: metrics.TypeCounter}
+	for _, st := range redact.Stats() {
+		redactions.Add(float64(st.Count), metrics.Labels{"site": st.Site, "detector": st.Detector})
+	}
+	return redactions
+}
+
+// startMetricsListener serves /metrics without authentication on
+// network.metrics_listen_address, for scrapers that cannot send a token.
+// It serves nothing else.
+func (s *Server) startMetricsListener() error {
+	addr := s.config.GetMetricsListenAddress()
+	if addr == "" {
+		return nil
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	s.metricsServer = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+		WriteTimeout:      30 * time.Second,
+	}
+	s.logger.Info("metrics listening", "addr", ln.Addr().String())
+	go func() {
+		if err := s.metricsServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("metrics listener failed", "err", err)
+		}
+	}()
+	return nil
+}
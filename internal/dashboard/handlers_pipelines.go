@@ -0,0 +1,197 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/charmbracelet/log"
+	"github.com/go-chi/chi/v5"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/pipeline"
+	"github.com/sergeknystautas/schmux/internal/spawn"
+)
+
+// PipelineHandlers groups HTTP handlers for pipeline definitions and runs.
+type PipelineHandlers struct {
+	config         *config.Config
+	pipelineStore  *spawn.PipelineStore
+	pipelineRunner *pipeline.Runner
+	logger         *log.Logger
+}
+
+// writePipelineStoreError maps pipeline store errors to HTTP statuses.
+func writePipelineStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, spawn.ErrNotFound):
+		writeJSONError(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, spawn.ErrInvalidPipeline):
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// handleListPipelines returns the pipelines defined for a repo.
+func (h *PipelineHandlers) handleListPipelines(w http.ResponseWriter, r *http.Request) {
+	if h.pipelineStore == nil {
+		writeJSONError(w, "pipelines not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	pipelines, err := h.pipelineStore.List(chi.URLParam(r, "repo"))
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if pipelines == nil {
+		pipelines = []contracts.Pipeline{}
+	}
+	writeJSON(w, contracts.PipelinesResponse{Pipelines: pipelines})
+}
+
+// handleGetPipeline returns one pipeline definition.
+func (h *PipelineHandlers) handleGetPipeline(w http.ResponseWriter, r *http.Request) {
+	if h.pipelineStore == nil {
+		writeJSONError(w, "pipelines not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	p, err := h.pipelineStore.Get(chi.URLParam(r, "repo"), chi.URLParam(r, "id"))
+	if err != nil {
+		writePipelineStoreError(w, err)
+		return
+	}
+	writeJSON(w, p)
+}
+
+// handleCreatePipeline stores a new pipeline definition.
+func (h *PipelineHandlers) handleCreatePipeline(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	if h.pipelineStore == nil {
+		writeJSONError(w, "pipelines not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	var req contracts.Pipeline
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	p, err := h.pipelineStore.Create(chi.URLParam(r, "repo"), req)
+	if err != nil {
+		writePipelineStoreError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(p)
+}
+
+// handleUpdatePipeline replaces a pipeline definition. Runs in flight keep
+// the definition they started with.
+func (h *PipelineHandlers) handleUpdatePipeline(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	if h.pipelineStore == nil {
+		writeJSONError(w, "pipelines not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	var req contracts.Pipeline
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	p, err := h.pipelineStore.Update(chi.URLParam(r, "repo"), chi.URLParam(r, "id"), req)
+	if err != nil {
+		writePipelineStoreError(w, err)
+		return
+	}
+	writeJSON(w, p)
+}
+
+// handleDeletePipeline removes a pipeline definition.
+func (h *PipelineHandlers) handleDeletePipeline(w http.ResponseWriter, r *http.Request) {
+	if h.pipelineStore == nil {
+		writeJSONError(w, "pipelines not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	if err := h.pipelineStore.Delete(chi.URLParam(r, "repo"), chi.URLParam(r, "id")); err != nil {
+		writePipelineStoreError(w, err)
+		return
+	}
+	writeJSON(w, map[string]string{"status": "deleted"})
+}
+
+// handleRunPipeline starts a run of a stored pipeline. Stages without an
+// explicit repo inherit the pipeline's, falling back to the URL of the repo
+// the pipeline is stored under.
+func (h *PipelineHandlers) handleRunPipeline(w http.ResponseWriter, r *http.Request) {
+	if h.pipelineStore == nil || h.pipelineRunner == nil {
+		writeJSONError(w, "pipelines not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	repo := chi.URLParam(r, "repo")
+	p, err := h.pipelineStore.Get(repo, chi.URLParam(r, "id"))
+	if err != nil {
+		writePipelineStoreError(w, err)
+		return
+	}
+	if p.Repo == "" {
+		if repoCfg, found := h.config.FindRepo(repo); found {
+			p.Repo = repoCfg.URL
+		}
+	}
+	run, err := h.pipelineRunner.Start(repo, p)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	h.logger.Info("pipeline run started", "run", run.ID, "pipeline", p.Name, "repo", repo)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(run)
+}
+
+// handleListPipelineRuns returns all tracked pipeline runs, newest first.
+func (h *PipelineHandlers) handleListPipelineRuns(w http.ResponseWriter, r *http.Request) {
+	runs := []contracts.PipelineRun{}
+	if h.pipelineRunner != nil {
+		runs = h.pipelineRunner.Runs()
+	}
+	writeJSON(w, contracts.PipelineRunsResponse{Runs: runs})
+}
+
+// handleGetPipelineRun returns one pipeline run with per-stage progress.
+func (h *PipelineHandlers) handleGetPipelineRun(w http.ResponseWriter, r *http.Request) {
+	if h.pipelineRunner == nil {
+		writeJSONError(w, "pipelines not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	run, ok := h.pipelineRunner.Get(chi.URLParam(r, "runID"))
+	if !ok {
+		writeJSONError(w, "pipeline run not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, run)
+}
+
+// handleCancelPipelineRun stops a running pipeline. Sessions already spawned
+// are left running.
+func (h *PipelineHandlers) handleCancelPipelineRun(w http.ResponseWriter, r *http.Request) {
+	if h.pipelineRunner == nil {
+		writeJSONError(w, "pipelines not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	runID := chi.URLParam(r, "runID")
+	if err := h.pipelineRunner.Cancel(runID); err != nil {
+		switch {
+		case errors.Is(err, pipeline.ErrRunNotFound):
+			writeJSONError(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, pipeline.ErrRunFinished):
+			writeJSONError(w, err.Error(), http.StatusConflict)
+		default:
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	run, _ := h.pipelineRunner.Get(runID)
+	writeJSON(w, run)
+}
@@ -0,0 +1,85 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sergeknystautas/schmux/internal/agentmsg"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/workspace"
+)
+
+// pushGateTellLines is how many trailing output lines of a failing gate are
+// told to the workspace's agents.
+const pushGateTellLines = 10
+
+// runPushGates runs the workspace's push gates ahead of a push. blocked is
+// true when a gate failed and the request did not override it; the failure
+// has then been told to the workspace's agents if push_gates.tell_on_failure
+// is set. run is nil when the repo has no gates.
+func (h *GitHandlers) runPushGates(workspaceID, trigger string, override bool) (run *contracts.PushGateRun, blocked bool, err error) {
+	run, err = h.workspace.RunPushGates(h.shutdownCtx, workspaceID, trigger, override)
+	if err != nil || run == nil || run.Status != workspace.PushGateFailed || run.Overridden {
+		return run, false, err
+	}
+	if h.config.GetPushGatesTellOnFailure() && h.tellPushGateFailure != nil {
+		h.tellPushGateFailure(workspaceID, run)
+	}
+	return run, true, nil
+}
+
+// writePushGatesError reports a push gate run that could not start.
+func writePushGatesError(w http.ResponseWriter, err error) {
+	if errors.Is(err, workspace.ErrPushGatesRunning) {
+		writeJSONError(w, err.Error(), http.StatusConflict)
+		return
+	}
+	writeJSONError(w, fmt.Sprintf("Failed to run push gates: %v", err), http.StatusInternalServerError)
+}
+
+// failedPushGate returns the gate that failed run.
+func failedPushGate(run *contracts.PushGateRun) contracts.PushGateResult {
+	for _, g := range run.Gates {
+		if g.Status == workspace.PushGateFailed {
+			return g
+		}
+	}
+	return contracts.PushGateResult{}
+}
+
+// pushGateBlockedMessage describes a push blocked by run.
+func pushGateBlockedMessage(run *contracts.PushGateRun) string {
+	g := failedPushGate(run)
+	reason := fmt.Sprintf("exit %d", g.ExitCode)
+	if g.Error != "" {
+		reason = g.Error
+	}
+	return fmt.Sprintf("push blocked: gate %q failed (%s)", g.Name, reason)
+}
+
+// tellPushGateFailure types a failed gate's output into the workspace's
+// agent sessions so they can fix it before the next push.
+func (s *Server) tellPushGateFailure(workspaceID string, run *contracts.PushGateRun) {
+	g := failedPushGate(run)
+	text := fmt.Sprintf("[from schmux] %s. Fix it and commit; the push was not made.", pushGateBlockedMessage(run))
+	if tail := agentmsg.OutputTail(g.Output, pushGateTellLines); tail != "" {
+		text += " Last output: " + tail
+	}
+
+	for _, sess := range s.state.GetSessions() {
+		if sess.WorkspaceID != workspaceID || sess.Status == "disposing" {
+			continue
+		}
+		target, err := s.session.ResolveTarget(context.Background(), sess.Target)
+		if err != nil || !target.Promptable {
+			continue
+		}
+		if err := s.DeliverAgentMessage(sess, text); err != nil {
+			s.logger.Warn("push gates: failed to tell session", "session", sess.ID, "err", err)
+			continue
+		}
+		s.timelapseMarkers.Add(sess.ID, "push gate failed: "+g.Name)
+	}
+}
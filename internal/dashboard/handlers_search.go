@@ -0,0 +1,144 @@
+package dashboard
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/search"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// maxSearchLimit caps the limit query parameter of GET /api/search.
+const maxSearchLimit = 1000
+
+// SetSearchIndexer sets the full-text index GET /api/search queries.
+func (s *Server) SetSearchIndexer(ix *search.Indexer) {
+	s.searchIndex = ix
+}
+
+// handleSearch searches terminal output, events and spawn prompts of all
+// sessions. Query parameters: q (required); repo, target, workspace and
+// session filters; kind (comma-separated output, event, prompt); since and
+// until (RFC3339, or a duration back from now such as 2h); limit.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if s.searchIndex == nil {
+		writeJSONError(w, "search not available", http.StatusServiceUnavailable)
+		return
+	}
+	params := r.URL.Query()
+	q := search.Query{Text: params.Get("q")}
+	if strings.TrimSpace(q.Text) == "" {
+		writeJSONError(w, "q is required", http.StatusBadRequest)
+		return
+	}
+	if kinds := params.Get("kind"); kinds != "" {
+		for _, k := range strings.Split(kinds, ",") {
+			switch kind := search.Kind(strings.TrimSpace(k)); kind {
+			case search.KindOutput, search.KindEvent, search.KindPrompt:
+				q.Kinds = append(q.Kinds, kind)
+			default:
+				writeJSONError(w, fmt.Sprintf("invalid kind: %s", k), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	now := time.Now()
+	var err error
+	if q.Since, err = parseSearchTime(params.Get("since"), now); err != nil {
+		writeJSONError(w, fmt.Sprintf("invalid since: %v", err), http.StatusBadRequest)
+		return
+	}
+	if q.Until, err = parseSearchTime(params.Get("until"), now); err != nil {
+		writeJSONError(w, fmt.Sprintf("invalid until: %v", err), http.StatusBadRequest)
+		return
+	}
+	if limitStr := params.Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 0 {
+			writeJSONError(w, fmt.Sprintf("invalid limit: %s", limitStr), http.StatusBadRequest)
+			return
+		}
+		q.Limit = min(n, maxSearchLimit)
+	}
+
+	repoFilter, targetFilter := params.Get("repo"), params.Get("target")
+	workspaceFilter, sessionFilter := params.Get("workspace"), params.Get("session")
+
+	all := s.state.GetSessions()
+	ids := make([]string, 0, len(all))
+	sessions := make(map[string]state.Session, len(all))
+	repos := make(map[string]string, len(all))
+	var searched []search.Session
+	for _, sess := range all {
+		ids = append(ids, sess.ID)
+		ws, _ := s.state.GetWorkspace(sess.WorkspaceID)
+		repo := ws.Repo
+		if cfgRepo, found := s.config.FindRepoByURL(ws.Repo); found {
+			repo = cfgRepo.Name
+		}
+		if repoFilter != "" && repoFilter != repo && repoFilter != ws.Repo {
+			continue
+		}
+		if targetFilter != "" && targetFilter != sess.Target {
+			continue
+		}
+		if workspaceFilter != "" && workspaceFilter != sess.WorkspaceID {
+			continue
+		}
+		if sessionFilter != "" && sessionFilter != sess.ID && sessionFilter != sess.Nickname {
+			continue
+		}
+		var eventsPath string
+		if sess.RemoteHostID == "" && ws.Path != "" {
+			eventsPath = filepath.Join(state.SchmuxDataDir(ws.Path), "events", sess.ID+".jsonl")
+		}
+		searched = append(searched, search.Session{ID: sess.ID, EventsPath: eventsPath})
+		sessions[sess.ID] = sess
+		repos[sess.ID] = repo
+	}
+	s.searchIndex.Retain(ids)
+
+	matches, truncated, err := s.searchIndex.Search(searched, q)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := contracts.SearchResponse{Results: make([]contracts.SearchResult, 0, len(matches)), Truncated: truncated}
+	for _, m := range matches {
+		sess := sessions[m.SessionID]
+		result := contracts.SearchResult{
+			SessionID:   m.SessionID,
+			Nickname:    sess.Nickname,
+			WorkspaceID: sess.WorkspaceID,
+			Repo:        repos[m.SessionID],
+			Target:      sess.Target,
+			Kind:        string(m.Kind),
+			EventType:   m.EventType,
+			Time:        m.Time,
+			Text:        m.Text,
+		}
+		if m.Kind == search.KindOutput {
+			seq := m.Seq
+			result.Seq = &seq
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	writeJSON(w, resp)
+}
+
+// parseSearchTime parses an RFC3339 time or a duration before now. Empty
+// means unbounded.
+func parseSearchTime(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
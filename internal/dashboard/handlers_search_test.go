@@ -0,0 +1,101 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/search"
+	"github.com/sergeknystautas/schmux/internal/session"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func TestHandleSearch(t *testing.T) {
+	server, cfg, st := newTestServer(t)
+	cfg.Repos = []config.Repo{{Name: "api", URL: "https://example.com/api.git"}}
+
+	wsPath := t.TempDir()
+	st.AddWorkspace(state.Workspace{ID: "ws-1", Repo: "https://example.com/api.git", Path: wsPath})
+	st.AddWorkspace(state.Workspace{ID: "ws-2", Repo: "https://example.com/web.git", Path: t.TempDir()})
+	st.AddSession(state.Session{ID: "s-a", WorkspaceID: "ws-1", Target: "claude", Nickname: "alpha"})
+	st.AddSession(state.Session{ID: "s-b", WorkspaceID: "ws-2", Target: "codex"})
+
+	scrollbackDir := t.TempDir()
+	for _, id := range []string{"s-a", "s-b"} {
+		store, err := session.OpenScrollbackStore(filepath.Join(scrollbackDir, id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.Append([]session.LogEntry{{Seq: 0, Data: []byte("booting\r\n")}, {Seq: 1, Data: []byte("panic: nil map\r\n")}})
+		store.Close()
+	}
+	eventsDir := filepath.Join(state.SchmuxDataDir(wsPath), "events")
+	os.MkdirAll(eventsDir, 0o755)
+	os.WriteFile(filepath.Join(eventsDir, "s-a.jsonl"), []byte(`{"ts":"2026-01-01T10:00:00Z","type":"failure","tool":"Bash","error":"panic in handler"}`+"\n"), 0o644)
+
+	get := func(query string) (*httptest.ResponseRecorder, contracts.SearchResponse) {
+		req := httptest.NewRequest(http.MethodGet, "/api/search?"+query, nil)
+		rr := httptest.NewRecorder()
+		server.handleSearch(rr, req)
+		var resp contracts.SearchResponse
+		if rr.Code == http.StatusOK {
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rr, resp
+	}
+
+	if rr, _ := get("q=panic"); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("without indexer: expected 503, got %d", rr.Code)
+	}
+	server.SetSearchIndexer(search.NewIndexer(scrollbackDir, ""))
+
+	rr, resp := get("q=panic")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("results = %+v", resp.Results)
+	}
+
+	_, resp = get("q=panic&repo=api&kind=output")
+	if len(resp.Results) != 1 {
+		t.Fatalf("repo+kind filter: results = %+v", resp.Results)
+	}
+	r := resp.Results[0]
+	if r.SessionID != "s-a" || r.Nickname != "alpha" || r.Repo != "api" || r.Target != "claude" || r.Text != "panic: nil map" {
+		t.Errorf("result = %+v", r)
+	}
+	if r.Seq == nil || *r.Seq != 1 {
+		t.Errorf("seq = %v, want 1", r.Seq)
+	}
+
+	_, resp = get("q=panic&kind=event")
+	if len(resp.Results) != 1 || resp.Results[0].EventType != "failure" || resp.Results[0].Seq != nil {
+		t.Errorf("event results = %+v", resp.Results)
+	}
+
+	for query, want := range map[string]int{
+		"q=panic&target=codex":   1,
+		"q=panic&workspace=ws-2": 1,
+		"q=panic&session=alpha":  2,
+		"q=panic&since=1h":       2, // the event is from 2026-01-01
+		"q=booting&limit=1":      1,
+	} {
+		if _, resp := get(query); len(resp.Results) != want {
+			t.Errorf("%s: got %d results, want %d", query, len(resp.Results), want)
+		}
+	}
+
+	for _, query := range []string{"", "q=x&kind=logs", "q=x&since=yesterday", "q=x&limit=-1"} {
+		if rr, _ := get(query); rr.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", query, rr.Code)
+		}
+	}
+}
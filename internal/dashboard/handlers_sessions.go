@@ -15,17 +15,21 @@ import (
 	"github.com/charmbracelet/log"
 	"github.com/go-chi/chi/v5"
 
+	"github.com/sergeknystautas/schmux/internal/acceptance"
 	"github.com/sergeknystautas/schmux/internal/api/contracts"
 	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/cost"
 	"github.com/sergeknystautas/schmux/internal/logging"
 	"github.com/sergeknystautas/schmux/internal/models"
 	"github.com/sergeknystautas/schmux/internal/nudgenik"
 	"github.com/sergeknystautas/schmux/internal/oneshot"
 	"github.com/sergeknystautas/schmux/internal/persona"
+	"github.com/sergeknystautas/schmux/internal/pipeline"
 	"github.com/sergeknystautas/schmux/internal/preview"
 	"github.com/sergeknystautas/schmux/internal/remote"
 	"github.com/sergeknystautas/schmux/internal/session"
 	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/tournament"
 	"github.com/sergeknystautas/schmux/internal/workspace"
 	"github.com/sergeknystautas/schmux/internal/workspacestatus"
 )
@@ -58,6 +62,18 @@ type SessionHandlers struct {
 	// workspaceStatus serves cached GitHub CI/PR status per workspace.
 	// written by the build monitor check pass
 	workspaceStatus *workspacestatus.Cache
+
+	// pipelineRunner reports which pipeline stage spawned a session.
+	pipelineRunner *pipeline.Runner
+
+	// tournamentRunner reports which tournament a session competes in.
+	tournamentRunner *tournament.Runner
+
+	// acceptanceRunner reports a session's latest acceptance run.
+	acceptanceRunner *acceptance.Runner
+
+	// costLedger reports each session's accounted spend.
+	costLedger *cost.Ledger
 }
 
 // buildSessionsResponse builds the sessions/workspaces response data.
@@ -69,8 +85,8 @@ func (h *SessionHandlers) buildSessionsResponse() []WorkspaceResponseItem {
 	workspaces := h.state.GetWorkspaces()
 	ctx := context.Background()
 	for _, ws := range workspaces {
-		// Hide recyclable workspaces from the dashboard
-		if ws.Status == state.WorkspaceStatusRecyclable {
+		// Hide recyclable and warm pool workspaces from the dashboard
+		if ws.Status == state.WorkspaceStatusRecyclable || ws.Status == state.WorkspaceStatusReady {
 			continue
 		}
 
@@ -177,8 +193,11 @@ func (h *SessionHandlers) buildSessionsResponse() []WorkspaceResponseItem {
 			RemoteUniqueCommits:     ws.RemoteUniqueCommits,
 			Previews:                []contracts.PreviewResponse{},
 			ResolveConflicts:        ws.ResolveConflicts,
+			PushGates:               ws.PushGates,
+			Setup:                   ws.Setup,
 			Status:                  ws.Status,
 			Backburner:              ws.Backburner,
+			Protected:               ws.Protected,
 			IntentShared:            ws.IntentShared,
 		}
 		if h.previewManager != nil {
@@ -253,6 +272,11 @@ func (h *SessionHandlers) buildSessionsResponse() []WorkspaceResponseItem {
 		runningMap[res.id] = res.running
 	}
 
+	var sessionCosts map[string]float64
+	if h.costLedger != nil {
+		sessionCosts = h.costLedger.SessionCosts()
+	}
+
 	for _, sess := range sessions {
 		// Get workspace info
 		wsResp, ok := workspaceMap[sess.WorkspaceID]
@@ -337,6 +361,38 @@ func (h *SessionHandlers) buildSessionsResponse() []WorkspaceResponseItem {
 			}
 		}
 
+		var pipelineInfo *contracts.SessionPipelineInfo
+		if h.pipelineRunner != nil {
+			if info, ok := h.pipelineRunner.SessionInfo(sess.ID); ok {
+				pipelineInfo = &info
+			}
+		}
+
+		var tournamentInfo *contracts.SessionTournamentInfo
+		if h.tournamentRunner != nil {
+			if info, ok := h.tournamentRunner.SessionInfo(sess.ID); ok {
+				tournamentInfo = &info
+			}
+		}
+
+		var acceptanceInfo *contracts.SessionAcceptanceInfo
+		if h.acceptanceRunner != nil {
+			if info, ok := h.acceptanceRunner.SessionInfo(sess.ID); ok {
+				acceptanceInfo = &info
+			}
+		}
+
+		var queuePosition int
+		var queueETA string
+		if sess.Status == state.SessionStatusQueued && h.session != nil {
+			if info, ok := h.session.QueueInfo(sess.ID); ok {
+				queuePosition = info.Position
+				if !info.ETA.IsZero() {
+					queueETA = info.ETA.Format(time.RFC3339)
+				}
+			}
+		}
+
 		wsResp.Sessions = append(wsResp.Sessions, SessionResponseItem{
 			ID:               sess.ID,
 			Target:           sess.Target,
@@ -351,6 +407,7 @@ func (h *SessionHandlers) buildSessionsResponse() []WorkspaceResponseItem {
 			AttachCmd:        attachCmd,
 			TmuxSocket:       sess.TmuxSocket,
 			TmuxSession:      sess.TmuxSession,
+			Backend:          sess.Backend,
 			NudgeState:       nudgeState,
 			NudgeSummary:     nudgeSummary,
 			NudgeSeq:         sess.NudgeSeq,
@@ -366,6 +423,12 @@ func (h *SessionHandlers) buildSessionsResponse() []WorkspaceResponseItem {
 			StyleID:          sess.StyleID,
 			Fence:            sess.Fence,
 			ResumeID:         sess.ResumeID,
+			Pipeline:         pipelineInfo,
+			Tournament:       tournamentInfo,
+			Acceptance:       acceptanceInfo,
+			QueuePosition:    queuePosition,
+			QueueETA:         queueETA,
+			CostUSD:          sessionCosts[sess.ID],
 		})
 		wsResp.SessionCount = len(wsResp.Sessions)
 	}
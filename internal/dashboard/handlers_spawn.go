@@ -15,6 +15,7 @@ import (
 
 	"github.com/charmbracelet/log"
 
+	"github.com/sergeknystautas/schmux/internal/acceptance"
 	"github.com/sergeknystautas/schmux/internal/api/contracts"
 	"github.com/sergeknystautas/schmux/internal/branchsuggest"
 	"github.com/sergeknystautas/schmux/internal/config"
@@ -23,12 +24,14 @@ import (
 	"github.com/sergeknystautas/schmux/internal/models"
 	"github.com/sergeknystautas/schmux/internal/oneshot"
 	"github.com/sergeknystautas/schmux/internal/persona"
+	"github.com/sergeknystautas/schmux/internal/pipeline"
 	"github.com/sergeknystautas/schmux/internal/remote"
 	"github.com/sergeknystautas/schmux/internal/session"
 	"github.com/sergeknystautas/schmux/internal/spawn"
 	"github.com/sergeknystautas/schmux/internal/spawnlog"
 	"github.com/sergeknystautas/schmux/internal/state"
 	"github.com/sergeknystautas/schmux/internal/style"
+	"github.com/sergeknystautas/schmux/internal/tournament"
 	"github.com/sergeknystautas/schmux/internal/workspace"
 )
 
@@ -50,25 +53,80 @@ type SpawnHandlers struct {
 	clipboardState *clipboardState
 	logger         *log.Logger
 
+	tournamentRunner *tournament.Runner
+	acceptanceRunner *acceptance.Runner
+
 	// Callbacks into Server methods that cannot be extracted.
 	broadcastSessions   func()
 	vcsTypeForWorkspace func(ws state.Workspace) string
 	dependencyReport    func() detect.DependencyReport
 }
 
+// newSpawnHandlers builds the spawn handler group from the server's current
+// dependencies.
+func (s *Server) newSpawnHandlers() *SpawnHandlers {
+	return &SpawnHandlers{
+		config:         s.config,
+		state:          s.state,
+		session:        s.session,
+		workspace:      s.workspace,
+		models:         s.models,
+		remoteManager:  s.remoteManager,
+		personaManager: s.personaManager,
+		styleManager:   s.styleManager,
+		spawnStore:     s.spawnStore,
+		clipboardState: s.clipboardState,
+		logger:         s.logger,
+
+		tournamentRunner: s.tournamentRunner,
+		acceptanceRunner: s.acceptanceRunner,
+
+		broadcastSessions:   s.BroadcastSessions,
+		vcsTypeForWorkspace: s.vcsTypeForWorkspace,
+		dependencyReport:    s.dependencyReport,
+	}
+}
+
+// spawnForPipeline is the pipeline.SpawnFunc: it runs a stage's request
+// through the same validation and spawn path as POST /api/spawn. The stage
+// fails only when no session could be started.
+func (s *Server) spawnForPipeline(_ context.Context, req contracts.SpawnRequest) ([]pipeline.SpawnedSession, error) {
+	results, err := s.newSpawnHandlers().spawnSessions(req)
+	if err != nil {
+		return nil, err
+	}
+	var spawned []pipeline.SpawnedSession
+	var firstErr string
+	for _, r := range results {
+		if r.Error != "" {
+			if firstErr == "" {
+				firstErr = r.Error
+			}
+			continue
+		}
+		spawned = append(spawned, pipeline.SpawnedSession{SessionID: r.SessionID, WorkspaceID: r.WorkspaceID})
+	}
+	if len(spawned) == 0 && firstErr != "" {
+		return nil, errors.New(firstErr)
+	}
+	return spawned, nil
+}
+
 // SpawnRequest is a type alias for contracts.SpawnRequest.
 type SpawnRequest = contracts.SpawnRequest
 
 // SessionResult is one target's spawn outcome, returned to the client and
 // persisted to the spawn log.
 type SessionResult struct {
-	SessionID   string `json:"session_id"`
-	WorkspaceID string `json:"workspace_id"`
-	Target      string `json:"target,omitempty"`
-	Command     string `json:"command,omitempty"`
-	Prompt      string `json:"prompt,omitempty"`
-	Nickname    string `json:"nickname,omitempty"`
-	Error       string `json:"error,omitempty"`
+	SessionID    string `json:"session_id"`
+	WorkspaceID  string `json:"workspace_id"`
+	Target       string `json:"target,omitempty"`
+	Command      string `json:"command,omitempty"`
+	Prompt       string `json:"prompt,omitempty"`
+	Nickname     string `json:"nickname,omitempty"`
+	Queued       bool   `json:"queued,omitempty"`        // held back by concurrency limits; starts when a slot frees
+	TournamentID string `json:"tournament_id,omitempty"` // set on every candidate of a tournament spawn
+	Error        string `json:"error,omitempty"`
 }
 
 // writeSpawnLog persists one resolved spawn request plus its per-target outcome
@@ -105,6 +163,15 @@ func writeSpawnLog(logger *log.Logger, req SpawnRequest, results []SessionResult
 	}
 }
 
+// spawnRequestError is a validation failure from spawnSessions. It carries
+// the HTTP status the handler should respond with.
+type spawnRequestError struct {
+	msg    string
+	status int
+}
+
+func (e *spawnRequestError) Error() string { return e.msg }
+
 // handleSpawnPost handles session spawning requests.
 func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request) {
 	// Spawn requests may include base64-encoded image attachments (up to 5 images).
@@ -117,19 +184,38 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	results, err := h.spawnSessions(req)
+	if err != nil {
+		var reqErr *spawnRequestError
+		if errors.As(err, &reqErr) {
+			writeJSONError(w, reqErr.msg, reqErr.status)
+			return
+		}
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		h.logger.Error("failed to encode response", "handler", "spawn", "err", err)
+	}
+}
+
+// spawnSessions validates and executes one spawn request, returning the
+// per-target outcomes. Request-level validation failures are returned as a
+// *spawnRequestError; per-target spawn failures are reported in the results.
+// Shared by the HTTP handler and programmatic spawners (e.g. pipelines).
+func (h *SpawnHandlers) spawnSessions(req SpawnRequest) ([]SessionResult, error) {
 	if req.QuickLaunchName != "" {
 		if req.Command != "" || len(req.Targets) > 0 {
-			writeJSONError(w, "cannot specify quick_launch_name with command or targets", http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: "cannot specify quick_launch_name with command or targets", status: http.StatusBadRequest}
 		}
 		if req.WorkspaceID == "" {
-			writeJSONError(w, "workspace_id is required for quick_launch_name", http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: "workspace_id is required for quick_launch_name", status: http.StatusBadRequest}
 		}
 		resolved, err := h.resolveQuickLaunchByName(req.WorkspaceID, req.QuickLaunchName)
 		if err != nil {
-			writeJSONError(w, err.Error(), http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: err.Error(), status: http.StatusBadRequest}
 		}
 		if req.Nickname == "" {
 			req.Nickname = resolved.Name
@@ -191,8 +277,7 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 	if req.WorkspaceID == "" && req.RemoteProfileID == "" {
 		// When not spawning into existing workspace and not remote, repo and branch are required
 		if req.Repo == "" {
-			writeJSONError(w, "repo is required (when not using --workspace or remote)", http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: "repo is required (when not using --workspace or remote)", status: http.StatusBadRequest}
 		}
 		if req.Branch == "" {
 			// Sapling repos have no branch concept; allow empty branch when the
@@ -201,53 +286,78 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 			// manager.
 			repoCfg, found := h.config.FindRepoByURL(req.Repo)
 			if !found || repoCfg.VCS != "sapling" {
-				writeJSONError(w, "branch is required (when not using --workspace or remote)", http.StatusBadRequest)
-				return
+				return nil, &spawnRequestError{msg: "branch is required (when not using --workspace or remote)", status: http.StatusBadRequest}
 			}
 		}
 	}
 	// Either command or targets must be provided
 	if req.Command == "" && len(req.Targets) == 0 {
-		writeJSONError(w, "either command or targets is required", http.StatusBadRequest)
-		return
+		return nil, &spawnRequestError{msg: "either command or targets is required", status: http.StatusBadRequest}
 	}
 	if req.Command != "" && len(req.Targets) > 0 {
-		writeJSONError(w, "cannot specify both command and targets", http.StatusBadRequest)
-		return
+		return nil, &spawnRequestError{msg: "cannot specify both command and targets", status: http.StatusBadRequest}
+	}
+
+	if req.Tournament != nil {
+		if msg := validateTournamentRequest(req); msg != "" {
+			return nil, &spawnRequestError{msg: msg, status: http.StatusBadRequest}
+		}
+		if h.tournamentRunner == nil {
+			return nil, &spawnRequestError{msg: "tournaments not initialized", status: http.StatusServiceUnavailable}
+		}
+	}
+
+	if req.Acceptance != nil {
+		if msg := validateAcceptanceRequest(req); msg != "" {
+			return nil, &spawnRequestError{msg: msg, status: http.StatusBadRequest}
+		}
+		if h.acceptanceRunner == nil {
+			return nil, &spawnRequestError{msg: "acceptance not initialized", status: http.StatusServiceUnavailable}
+		}
+	}
+
+	// Refuse up front when over budget rather than failing every target.
+	if h.session != nil {
+		if err := h.session.CheckSpawnGate(req.WorkspaceID); err != nil {
+			return nil, &spawnRequestError{msg: err.Error(), status: http.StatusTooManyRequests}
+		}
 	}
 
 	// Validate resume mode
 	if req.Resume {
 		if req.Command != "" {
-			writeJSONError(w, "cannot use command mode with resume", http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: "cannot use command mode with resume", status: http.StatusBadRequest}
 		}
 		if strings.TrimSpace(req.Prompt) != "" {
-			writeJSONError(w, "cannot use prompt with resume mode", http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: "cannot use prompt with resume mode", status: http.StatusBadRequest}
 		}
 	}
 
 	// Validate image attachments
 	if len(req.ImageAttachments) > 0 {
 		if len(req.ImageAttachments) > 5 {
-			writeJSONError(w, "maximum 5 image attachments allowed", http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: "maximum 5 image attachments allowed", status: http.StatusBadRequest}
 		}
 		if req.Resume {
-			writeJSONError(w, "cannot use image attachments with resume mode", http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: "cannot use image attachments with resume mode", status: http.StatusBadRequest}
 		}
 		if req.Command != "" {
-			writeJSONError(w, "cannot use image attachments with command mode", http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: "cannot use image attachments with command mode", status: http.StatusBadRequest}
 		}
 		if req.RemoteProfileID != "" {
-			writeJSONError(w, "image attachments are not supported for remote spawns", http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: "image attachments are not supported for remote spawns", status: http.StatusBadRequest}
 		}
 	}
 
+	switch req.Backend {
+	case "", state.SessionBackendTmux, state.SessionBackendPTY:
+	default:
+		return nil, &spawnRequestError{msg: fmt.Sprintf("invalid backend: %q (want tmux or pty)", req.Backend), status: http.StatusBadRequest}
+	}
+	if req.Backend == state.SessionBackendPTY && req.RemoteProfileID != "" {
+		return nil, &spawnRequestError{msg: "the pty backend is not supported for remote sessions", status: http.StatusBadRequest}
+	}
+
 	// Fence is local-only and requires the fence dependency. The UI hides the
 	// toggle when unavailable; this is the server-side backstop for API
 	// clients and races. A fence-on spawn that can't be honored hard-fails —
@@ -255,17 +365,14 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 	var fenceCommand string
 	if req.Fence {
 		if req.RemoteProfileID != "" {
-			writeJSONError(w, "fence is not supported for remote sessions", http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: "fence is not supported for remote sessions", status: http.StatusBadRequest}
 		}
 		cmd, errMsg, status := h.fenceCommandOrError()
 		if errMsg != "" {
-			writeJSONError(w, errMsg, status)
-			return
+			return nil, &spawnRequestError{msg: errMsg, status: status}
 		}
 		if modeMsg, modeStatus := h.fenceModeOrError(); modeMsg != "" {
-			writeJSONError(w, modeMsg, modeStatus)
-			return
+			return nil, &spawnRequestError{msg: modeMsg, status: modeStatus}
 		}
 		fenceCommand = cmd
 	}
@@ -285,8 +392,7 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 				BarePath: name + ".git",
 			})
 			if err := h.config.Save(); err != nil {
-				writeJSONError(w, fmt.Sprintf("failed to register repo: %v", err), http.StatusInternalServerError)
-				return
+				return nil, &spawnRequestError{msg: fmt.Sprintf("failed to register repo: %v", err), status: http.StatusInternalServerError}
 			}
 		}
 	}
@@ -298,8 +404,7 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 	if req.Command != "" {
 		// Remote command spawns are not currently supported
 		if req.RemoteProfileID != "" {
-			writeJSONError(w, "remote command spawns are not supported (only target-based spawns work on remote hosts)", http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: "remote command spawns are not supported (only target-based spawns work on remote hosts)", status: http.StatusBadRequest}
 		}
 
 		sessionLog := logging.Sub(h.logger, "session")
@@ -322,6 +427,8 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 			NewBranch:      req.NewBranch,
 			Fence:          req.Fence,
 			FenceCommand:   fenceCommand,
+			Priority:       req.Priority,
+			Backend:        req.Backend,
 		})
 		cancel()
 
@@ -338,6 +445,7 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 				WorkspaceID: sess.WorkspaceID,
 				Command:     req.Command,
 				Nickname:    sess.Nickname,
+				Queued:      sess.Status == state.SessionStatusQueued,
 			})
 			sessionLog.Info("spawn success", "command", req.Command, "session_id", sess.ID, "workspace_id", sess.WorkspaceID)
 		}
@@ -347,12 +455,8 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 			go h.broadcastSessions()
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(results); err != nil {
-			writeJSONError(w, fmt.Sprintf("Failed to encode response: %v", err), http.StatusInternalServerError)
-		}
 		writeSpawnLog(h.logger, req, results)
-		return
+		return results, nil
 	}
 
 	// Handle target-based spawn
@@ -389,8 +493,7 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 	if req.PersonaID != "" {
 		p, err := h.personaManager.Get(req.PersonaID)
 		if err != nil {
-			writeJSONError(w, fmt.Sprintf("persona not found: %s", req.PersonaID), http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: fmt.Sprintf("persona not found: %s", req.PersonaID), status: http.StatusBadRequest}
 		}
 		personaObj = p
 	}
@@ -403,8 +506,7 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 	} else if req.StyleID != "" {
 		st, err := h.styleManager.Get(req.StyleID)
 		if err != nil {
-			writeJSONError(w, fmt.Sprintf("style not found: %s", req.StyleID), http.StatusBadRequest)
-			return
+			return nil, &spawnRequestError{msg: fmt.Sprintf("style not found: %s", req.StyleID), status: http.StatusBadRequest}
 		}
 		explicitStyleObj = st
 	}
@@ -510,6 +612,8 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 					ImageAttachments: req.ImageAttachments,
 					Fence:            req.Fence,
 					FenceCommand:     fenceCommand,
+					Priority:         req.Priority,
+					Backend:          req.Backend,
 				})
 			}
 
@@ -528,6 +632,7 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 					Target:      targetName,
 					Prompt:      req.Prompt,
 					Nickname:    sess.Nickname, // Return actual nickname, not input
+					Queued:      sess.Status == state.SessionStatusQueued,
 				})
 			}
 		}
@@ -546,6 +651,24 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 
 	writeSpawnLog(h.logger, req, results)
 
+	if hasSuccess && req.Tournament != nil {
+		if id := h.startTournament(req, results); id != "" {
+			for i := range results {
+				if results[i].Error == "" {
+					results[i].TournamentID = id
+				}
+			}
+		}
+	}
+
+	if req.Acceptance != nil {
+		for _, r := range results {
+			if r.Error == "" && r.SessionID != "" {
+				h.acceptanceRunner.Register(r.SessionID, r.WorkspaceID, *req.Acceptance)
+			}
+		}
+	}
+
 	// Set intent sharing on workspace if requested
 	if hasSuccess && req.IntentShared {
 		seen := make(map[string]bool)
@@ -586,10 +709,7 @@ func (h *SpawnHandlers) handleSpawnPost(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(results); err != nil {
-		h.logger.Error("failed to encode response", "handler", "spawn", "err", err)
-	}
+	return results, nil
 }
 
 // handleSuggestBranch handles branch name suggestion requests.
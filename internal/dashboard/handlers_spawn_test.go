@@ -92,6 +92,24 @@ func TestHandleSpawnPost_ValidationErrors(t *testing.T) {
 			wantCode:   http.StatusBadRequest,
 			wantSubstr: "cannot specify quick_launch_name with command or targets",
 		},
+		{
+			name:       "tournament with one candidate",
+			body:       SpawnRequest{Repo: "https://github.com/foo/bar", Branch: "main", Targets: map[string]int{"claude": 1}, Prompt: "hello", Tournament: &contracts.TournamentOptions{}},
+			wantCode:   http.StatusBadRequest,
+			wantSubstr: "at least two candidates",
+		},
+		{
+			name:       "tournament into existing workspace",
+			body:       SpawnRequest{WorkspaceID: "ws-1", Targets: map[string]int{"claude": 2}, Prompt: "hello", Tournament: &contracts.TournamentOptions{}},
+			wantCode:   http.StatusBadRequest,
+			wantSubstr: "omit workspace_id",
+		},
+		{
+			name:       "tournament without prompt",
+			body:       SpawnRequest{Repo: "https://github.com/foo/bar", Branch: "main", Targets: map[string]int{"claude": 2}, Tournament: &contracts.TournamentOptions{}},
+			wantCode:   http.StatusBadRequest,
+			wantSubstr: "tournament requires a prompt",
+		},
 		{
 			name:       "quick launch without workspace",
 			body:       SpawnRequest{QuickLaunchName: "preset"},
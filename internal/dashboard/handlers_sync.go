@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
 	"github.com/sergeknystautas/schmux/internal/logging"
 	"github.com/sergeknystautas/schmux/internal/session"
 	"github.com/sergeknystautas/schmux/internal/state"
@@ -197,6 +198,7 @@ func (h *GitHandlers) runLinearSyncFromMain(workspaceID string) {
 // handleLinearSyncToMain handles POST requests to sync commits from branch to origin/main.
 // POST /api/workspaces/{id}/linear-sync-to-main
 //
+// Request body (optional): {"override_gates": true|false}
 // This pushes the current branch's commits directly to main without a merge commit.
 func (h *GitHandlers) handleLinearSyncToMain(w http.ResponseWriter, r *http.Request) {
 	// Extract workspace ID from chi URL param
@@ -206,6 +208,15 @@ func (h *GitHandlers) handleLinearSyncToMain(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	var req struct {
+		OverrideGates bool `json:"override_gates"`
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err.Error() != "EOF" {
+		writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
 	// Get workspace from state
 	_, found := h.state.GetWorkspace(workspaceID)
 	if !found {
@@ -221,6 +232,17 @@ func (h *GitHandlers) handleLinearSyncToMain(w http.ResponseWriter, r *http.Requ
 	workspaceLog := logging.Sub(h.logger, "workspace")
 	workspaceLog.Info("linear-sync-to-main", "workspace_id", workspaceID)
 
+	gates, blocked, err := h.runPushGates(workspaceID, workspace.PushGateTriggerSync, req.OverrideGates)
+	if err != nil {
+		writePushGatesError(w, err)
+		return
+	}
+	if blocked {
+		workspaceLog.Info("linear-sync-to-main", "workspace_id", workspaceID, "result", "blocked by push gates")
+		writeJSON(w, workspace.LinearSyncResult{Success: false, Message: pushGateBlockedMessage(gates), PushGates: gates})
+		return
+	}
+
 	// Perform the sync to main
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(h.config.GetGitCloneTimeoutMs())*time.Millisecond)
 	defer cancel()
@@ -236,6 +258,7 @@ func (h *GitHandlers) handleLinearSyncToMain(w http.ResponseWriter, r *http.Requ
 		})
 		return
 	}
+	result.PushGates = gates
 
 	// Update git status after sync (best-effort, don't block response)
 	if _, err := h.workspace.UpdateVCSStatus(ctx, workspaceID); err != nil {
@@ -285,7 +308,7 @@ func (h *GitHandlers) handleGetBranchDivergence(w http.ResponseWriter, r *http.R
 // handlePushToBranch handles POST requests to push commits to origin/branch.
 // POST /api/workspaces/{id}/push-to-branch
 //
-// Request body: {"confirm": true|false}
+// Request body: {"confirm": true|false, "override_gates": true|false}
 // If branches have diverged and confirm=false, returns needs_confirm=true with divergent commits.
 func (h *GitHandlers) handlePushToBranch(w http.ResponseWriter, r *http.Request) {
 	// Extract workspace ID from chi URL param
@@ -300,6 +323,7 @@ func (h *GitHandlers) handlePushToBranch(w http.ResponseWriter, r *http.Request)
 		Confirm        bool   `json:"confirm"`
 		ExpectedLocal  string `json:"expected_local"`
 		ExpectedRemote string `json:"expected_remote"`
+		OverrideGates  bool   `json:"override_gates"`
 	}
 	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err.Error() != "EOF" {
@@ -322,6 +346,17 @@ func (h *GitHandlers) handlePushToBranch(w http.ResponseWriter, r *http.Request)
 	workspaceLog := logging.Sub(h.logger, "workspace")
 	workspaceLog.Info("push-to-branch", "workspace_id", workspaceID, "confirm", req.Confirm)
 
+	gates, blocked, err := h.runPushGates(workspaceID, workspace.PushGateTriggerBranch, req.OverrideGates)
+	if err != nil {
+		writePushGatesError(w, err)
+		return
+	}
+	if blocked {
+		workspaceLog.Info("push-to-branch", "workspace_id", workspaceID, "result", "blocked by push gates")
+		writeJSON(w, workspace.LinearSyncResult{Success: false, Branch: ws.Branch, Message: pushGateBlockedMessage(gates), PushGates: gates})
+		return
+	}
+
 	// Perform the push to branch
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(h.config.GetGitCloneTimeoutMs())*time.Millisecond)
 	defer cancel()
@@ -337,6 +372,7 @@ func (h *GitHandlers) handlePushToBranch(w http.ResponseWriter, r *http.Request)
 		})
 		return
 	}
+	result.PushGates = gates
 
 	// Update git status after push (best effort)
 	if result.Success {
@@ -364,7 +400,7 @@ func (h *GitHandlers) handlePushToBranch(w http.ResponseWriter, r *http.Request)
 }
 
 // handlePushCommits handles POST /api/workspaces/{id}/push-commits.
-// Request body: {"hash": "<full sha>", "target": "default"|"branch", "per_commit": bool, "confirm": bool}
+// Request body: {"hash": "<full sha>", "target": "default"|"branch", "per_commit": bool, "confirm": bool, "override_gates": bool}
 // Pushes commits up to (and including) hash, in one push or one push per commit.
 // Behavior contract documented in docs/api.md (push-commits endpoint).
 func (h *GitHandlers) handlePushCommits(w http.ResponseWriter, r *http.Request) {
@@ -375,10 +411,11 @@ func (h *GitHandlers) handlePushCommits(w http.ResponseWriter, r *http.Request)
 	}
 
 	var req struct {
-		Hash      string `json:"hash"`
-		Target    string `json:"target"`
-		PerCommit bool   `json:"per_commit"`
-		Confirm   bool   `json:"confirm"`
+		Hash          string `json:"hash"`
+		Target        string `json:"target"`
+		PerCommit     bool   `json:"per_commit"`
+		Confirm       bool   `json:"confirm"`
+		OverrideGates bool   `json:"override_gates"`
 	}
 	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -395,6 +432,26 @@ func (h *GitHandlers) handlePushCommits(w http.ResponseWriter, r *http.Request)
 	workspaceLog := logging.Sub(h.logger, "workspace")
 	workspaceLog.Info("push-commits", "workspace_id", workspaceID, "target", req.Target, "per_commit", req.PerCommit, "confirm", req.Confirm)
 
+	trigger := workspace.PushGateTriggerBranch
+	if req.Target == "default" {
+		trigger = workspace.PushGateTriggerSync
+	}
+	gates, blocked, err := h.runPushGates(workspaceID, trigger, req.OverrideGates)
+	if err != nil {
+		writePushGatesError(w, err)
+		return
+	}
+	if blocked {
+		workspaceLog.Info("push-commits", "workspace_id", workspaceID, "result", "blocked by push gates")
+		writeJSON(w, contracts.PushCommitsResult{
+			PerCommit: req.PerCommit,
+			Reason:    workspace.PushReasonGatesFailed,
+			Message:   pushGateBlockedMessage(gates),
+			PushGates: gates,
+		})
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(h.config.GetGitCloneTimeoutMs())*time.Millisecond)
 	defer cancel()
 
@@ -414,6 +471,7 @@ func (h *GitHandlers) handlePushCommits(w http.ResponseWriter, r *http.Request)
 		writeJSONError(w, fmt.Sprintf("Failed to push commits: %v", err), http.StatusInternalServerError)
 		return
 	}
+	result.PushGates = gates
 
 	// Anything landed on the remote → refresh status and broadcast (same
 	// best-effort pattern as push-to-branch).
@@ -51,24 +51,32 @@ func (s *Server) handleTellSession(w http.ResponseWriter, r *http.Request) {
 	// Prefix with [from FM] server-side
 	text := fmt.Sprintf("[from FM] %s", req.Message)
 
+	if err := s.injectSessionText(sessionID, text); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.timelapseMarkers.Add(sessionID, "tell: "+req.Message)
+
+	writeJSON(w, map[string]string{"status": "ok"})
+}
+
+// injectSessionText types text into a session's agent and submits it. Used
+// by tell and by agent-to-agent messages.
+func (s *Server) injectSessionText(sessionID, text string) error {
 	// Get the runtime (works for both local and remote sessions)
 	runtime, err := s.session.GetTracker(sessionID)
 	if err != nil {
-		writeJSONError(w, fmt.Sprintf("failed to get session runtime: %v", err), http.StatusInternalServerError)
-		return
+		return fmt.Errorf("failed to get session runtime: %w", err)
 	}
 
 	// Clear any partial input before injecting the message to prevent
 	// collision with operator typing. See injector.go for details.
 	_ = runtime.SendTmuxKeyName("C-u")
 	if _, err := runtime.SendInput(text); err != nil {
-		writeJSONError(w, fmt.Sprintf("failed to send message: %v", err), http.StatusInternalServerError)
-		return
+		return fmt.Errorf("failed to send message: %w", err)
 	}
 	if err := runtime.SendTmuxKeyName("Enter"); err != nil {
-		writeJSONError(w, fmt.Sprintf("failed to send Enter: %v", err), http.StatusInternalServerError)
-		return
+		return fmt.Errorf("failed to send Enter: %w", err)
 	}
-
-	writeJSON(w, map[string]string{"status": "ok"})
+	return nil
 }
@@ -557,6 +557,28 @@ func TestParseRenamePath(t *testing.T) {
 	}
 }
 
+func TestParseNameStatusOutput_JJ(t *testing.T) {
+	t.Parallel()
+
+	output := "M\tmain.go\nA\tnew file.go\nD\tgone.go\nR\tpkg/{old.go => new.go}\nC\t{a.go => b.go}\n"
+	got := parseNameStatusOutput(output, "jj")
+	want := []diffFileSummary{
+		{Status: "modified", NewPath: "main.go"},
+		{Status: "added", NewPath: "new file.go"},
+		{Status: "deleted", OldPath: "gone.go"},
+		{Status: "renamed", OldPath: "pkg/old.go", NewPath: "pkg/new.go"},
+		{Status: "added", NewPath: "b.go"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d files, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("file %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
 func TestFormatAgentSystemPrompt(t *testing.T) {
 	t.Parallel()
 
@@ -41,15 +41,23 @@ func (s *Server) handleTimelapseList(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(recordings)
 }
 
+// handleTimelapseExport writes the compressed export of a recording.
+// ?format= selects cast (default), svg, text or markdown; each format is
+// cached in its own file next to the recording.
 func (s *Server) handleTimelapseExport(w http.ResponseWriter, r *http.Request) {
 	recordingID := chi.URLParam(r, "recordingId")
 	if !isValidResourceID(recordingID) {
 		writeJSONError(w, "invalid recording id", http.StatusBadRequest)
 		return
 	}
+	format, err := timelapse.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	dir := s.recordingsDir()
 	recordingPath := filepath.Join(dir, recordingID+".cast")
-	compressedPath := filepath.Join(dir, recordingID+".timelapse.cast")
+	compressedPath := filepath.Join(dir, recordingID+format.Ext())
 
 	if _, err := os.Stat(recordingPath); os.IsNotExist(err) {
 		writeJSONError(w, "recording not found", http.StatusNotFound)
@@ -65,6 +73,7 @@ func (s *Server) handleTimelapseExport(w http.ResponseWriter, r *http.Request) {
 				json.NewEncoder(w).Encode(map[string]string{
 					"exportId":    recordingID,
 					"recordingId": recordingID,
+					"format":      string(format),
 					"status":      "cached",
 				})
 				return
@@ -74,6 +83,7 @@ func (s *Server) handleTimelapseExport(w http.ResponseWriter, r *http.Request) {
 
 	// Run compression synchronously — typically completes in seconds
 	exp := timelapse.NewExporter(recordingPath, compressedPath, nil)
+	exp.SetFormat(format)
 	if err := exp.Export(); err != nil {
 		s.logger.Error("timelapse compression failed", "recording", recordingID, "err", err)
 		writeJSONError(w, "compression failed: "+err.Error(), http.StatusInternalServerError)
@@ -84,6 +94,7 @@ func (s *Server) handleTimelapseExport(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{
 		"exportId":    recordingID,
 		"recordingId": recordingID,
+		"format":      string(format),
 		"status":      "complete",
 	})
 }
@@ -96,12 +107,27 @@ func (s *Server) handleTimelapseDownload(w http.ResponseWriter, r *http.Request)
 	}
 	dir := s.recordingsDir()
 
-	// ?type=timelapse downloads the compressed version
+	// ?type=timelapse downloads the compressed version, in the export
+	// ?format= (default cast)
 	dlType := r.URL.Query().Get("type")
+	contentType := "application/octet-stream"
 	var castPath, filename string
 	if dlType == "timelapse" {
-		castPath = filepath.Join(dir, recordingID+".timelapse.cast")
-		filename = recordingID + ".timelapse.cast"
+		format, err := timelapse.ParseFormat(r.URL.Query().Get("format"))
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		castPath = filepath.Join(dir, recordingID+format.Ext())
+		filename = recordingID + format.Ext()
+		switch format {
+		case timelapse.FormatSVG:
+			contentType = "image/svg+xml"
+		case timelapse.FormatText:
+			contentType = "text/plain; charset=utf-8"
+		case timelapse.FormatMarkdown:
+			contentType = "text/markdown; charset=utf-8"
+		}
 	} else {
 		castPath = filepath.Join(dir, recordingID+".cast")
 		filename = recordingID + ".cast"
@@ -112,11 +138,35 @@ func (s *Server) handleTimelapseDownload(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Type", contentType)
 	w.Header().Set("Content-Disposition", "attachment; filename="+filename)
 	http.ServeFile(w, r, castPath)
 }
 
+// handleTimelapseChapters returns a recording's chapter index. With
+// ?type=timelapse the times are those of the compressed export.
+func (s *Server) handleTimelapseChapters(w http.ResponseWriter, r *http.Request) {
+	recordingID := chi.URLParam(r, "recordingId")
+	if !isValidResourceID(recordingID) {
+		writeJSONError(w, "invalid recording id", http.StatusBadRequest)
+		return
+	}
+	castPath := filepath.Join(s.recordingsDir(), recordingID+".cast")
+	if r.URL.Query().Get("type") == "timelapse" {
+		castPath = filepath.Join(s.recordingsDir(), recordingID+".timelapse.cast")
+	}
+	chapters, err := timelapse.ReadChapters(castPath)
+	if os.IsNotExist(err) {
+		writeJSONError(w, "file not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, chapters)
+}
+
 func (s *Server) handleTimelapseDelete(w http.ResponseWriter, r *http.Request) {
 	recordingID := chi.URLParam(r, "recordingId")
 	if !isValidResourceID(recordingID) {
@@ -126,7 +176,6 @@ func (s *Server) handleTimelapseDelete(w http.ResponseWriter, r *http.Request) {
 	dir := s.recordingsDir()
 
 	castPath := filepath.Join(dir, recordingID+".cast")
-	compressedPath := filepath.Join(dir, recordingID+".timelapse.cast")
 
 	if _, err := os.Stat(castPath); os.IsNotExist(err) {
 		writeJSONError(w, "recording not found", http.StatusNotFound)
@@ -134,7 +183,9 @@ func (s *Server) handleTimelapseDelete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	os.Remove(castPath)
-	os.Remove(compressedPath)
+	for _, format := range timelapse.Formats {
+		os.Remove(filepath.Join(dir, recordingID+format.Ext()))
+	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
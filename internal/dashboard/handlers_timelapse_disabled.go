@@ -16,6 +16,10 @@ func (s *Server) handleTimelapseDownload(w http.ResponseWriter, _ *http.Request)
 	writeJSONError(w, "Timelapse is not available in this build", http.StatusServiceUnavailable)
 }
 
+func (s *Server) handleTimelapseChapters(w http.ResponseWriter, _ *http.Request) {
+	writeJSONError(w, "Timelapse is not available in this build", http.StatusServiceUnavailable)
+}
+
 func (s *Server) handleTimelapseDelete(w http.ResponseWriter, _ *http.Request) {
 	writeJSONError(w, "Timelapse is not available in this build", http.StatusServiceUnavailable)
 }
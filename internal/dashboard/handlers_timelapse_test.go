@@ -157,3 +157,111 @@ func TestTimelapseHandlersRejectInvalidRecordingID(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleTimelapseChapters(t *testing.T) {
+	tmpHome := t.TempDir()
+	schmuxdir.Set(tmpHome)
+	t.Cleanup(func() { schmuxdir.Set("") })
+	recDir := filepath.Join(tmpHome, "recordings")
+	os.MkdirAll(recDir, 0755)
+	os.WriteFile(filepath.Join(recDir, "sess-1.cast"), []byte(`{"version":2,"width":80,"height":24,"timestamp":1711875300}
+[1.000000,"o","hi"]
+[2.500000,"m","needs_input: which database?"]
+`), 0600)
+
+	r := chi.NewRouter()
+	s := &Server{}
+	r.Get("/api/timelapse/{recordingId}/chapters", s.handleTimelapseChapters)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/timelapse/sess-1/chapters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var chapters []timelapse.Chapter
+	json.NewDecoder(resp.Body).Decode(&chapters)
+	if resp.StatusCode != http.StatusOK || len(chapters) != 1 || chapters[0].Time != 2.5 || chapters[0].Label != "needs_input: which database?" {
+		t.Errorf("status %d, chapters %+v", resp.StatusCode, chapters)
+	}
+
+	// Not exported yet.
+	resp, err = http.Get(srv.URL + "/api/timelapse/sess-1/chapters?type=timelapse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("compressed: got %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestHandleTimelapseExport_Formats(t *testing.T) {
+	tmpHome := t.TempDir()
+	schmuxdir.Set(tmpHome)
+	t.Cleanup(func() { schmuxdir.Set("") })
+	recDir := filepath.Join(tmpHome, "recordings")
+	os.MkdirAll(recDir, 0755)
+	os.WriteFile(filepath.Join(recDir, "sess-1.cast"), []byte(`{"version":2,"width":80,"height":24,"timestamp":1711875300}
+[1.000000,"o","hello\r\n"]
+`), 0600)
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(recDir, "sess-1.cast"), old, old)
+
+	r := chi.NewRouter()
+	s := &Server{}
+	r.Get("/api/timelapse/{recordingId}/download", s.handleTimelapseDownload)
+	r.Post("/api/timelapse/{recordingId}/export", s.handleTimelapseExport)
+	r.Delete("/api/timelapse/{recordingId}", s.handleTimelapseDelete)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	export := func(format string) (int, map[string]string) {
+		resp, err := http.Post(srv.URL+"/api/timelapse/sess-1/export?format="+format, "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body map[string]string
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+
+	if code, body := export("svg"); code != http.StatusOK || body["format"] != "svg" || body["status"] != "complete" {
+		t.Fatalf("export svg: %d %v", code, body)
+	}
+	if code, body := export("svg"); code != http.StatusOK || body["status"] != "cached" {
+		t.Errorf("second export svg: %d %v", code, body)
+	}
+	if code, _ := export("gif"); code != http.StatusBadRequest {
+		t.Errorf("export gif: got %d, want 400", code)
+	}
+
+	resp, err := http.Get(srv.URL + "/api/timelapse/sess-1/download?type=timelapse&format=svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/svg+xml" {
+		t.Errorf("download svg: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	// The cast export was never written.
+	resp, err = http.Get(srv.URL + "/api/timelapse/sess-1/download?type=timelapse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("download cast: got %d, want 404", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest("DELETE", srv.URL+"/api/timelapse/sess-1", nil)
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if _, err := os.Stat(filepath.Join(recDir, "sess-1.timelapse.svg")); !os.IsNotExist(err) {
+		t.Error("delete should remove the svg export")
+	}
+}
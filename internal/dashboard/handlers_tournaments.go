@@ -0,0 +1,257 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/go-chi/chi/v5"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/logging"
+	"github.com/sergeknystautas/schmux/internal/tournament"
+)
+
+// TournamentHandlers groups HTTP handlers for best-of-N tournaments.
+type TournamentHandlers struct {
+	tournamentRunner *tournament.Runner
+	logger           *log.Logger
+}
+
+// writeTournamentError maps tournament runner errors to HTTP statuses.
+func writeTournamentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, tournament.ErrNotFound):
+		writeJSONError(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, tournament.ErrFinished), errors.Is(err, tournament.ErrComparing):
+		writeJSONError(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, tournament.ErrNoWinner), errors.Is(err, tournament.ErrNotCandidate):
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// handleListTournaments returns all tracked tournaments, newest first.
+func (h *TournamentHandlers) handleListTournaments(w http.ResponseWriter, r *http.Request) {
+	list := []contracts.Tournament{}
+	if h.tournamentRunner != nil {
+		list = h.tournamentRunner.List()
+	}
+	writeJSON(w, contracts.TournamentsResponse{Tournaments: list})
+}
+
+// handleGetTournament returns one tournament with its candidates.
+func (h *TournamentHandlers) handleGetTournament(w http.ResponseWriter, r *http.Request) {
+	if h.tournamentRunner == nil {
+		writeJSONError(w, "tournaments not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	t, ok := h.tournamentRunner.Get(chi.URLParam(r, "id"))
+	if !ok {
+		writeJSONError(w, "tournament not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, t)
+}
+
+// handleCompareTournament starts a comparison without waiting for every
+// candidate to finish. The comparison runs in the background.
+func (h *TournamentHandlers) handleCompareTournament(w http.ResponseWriter, r *http.Request) {
+	if h.tournamentRunner == nil {
+		writeJSONError(w, "tournaments not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	t, err := h.tournamentRunner.Compare(chi.URLParam(r, "id"))
+	if err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(t)
+}
+
+// handlePromoteTournament declares a winner and disposes the other
+// candidates' workspaces in the background.
+func (h *TournamentHandlers) handlePromoteTournament(w http.ResponseWriter, r *http.Request) {
+	if h.tournamentRunner == nil {
+		writeJSONError(w, "tournaments not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	var req contracts.TournamentPromoteRequest
+	if r.Body != nil {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			writeJSONError(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	t, err := h.tournamentRunner.Promote(chi.URLParam(r, "id"), req.SessionID)
+	if err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+	writeJSON(w, t)
+}
+
+// handleCancelTournament stops tracking a tournament. Candidates are left
+// running.
+func (h *TournamentHandlers) handleCancelTournament(w http.ResponseWriter, r *http.Request) {
+	if h.tournamentRunner == nil {
+		writeJSONError(w, "tournaments not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	if err := h.tournamentRunner.Cancel(id); err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+	t, _ := h.tournamentRunner.Get(id)
+	writeJSON(w, t)
+}
+
+// validateTournamentRequest checks that a spawn request can run as a
+// tournament: every candidate needs a fresh local workspace and the same
+// prompt. Returns an empty string when valid.
+func validateTournamentRequest(req SpawnRequest) string {
+	switch {
+	case req.Command != "":
+		return "tournament requires targets, not a command"
+	case req.WorkspaceID != "":
+		return "tournament candidates need their own workspaces; omit workspace_id"
+	case req.RemoteProfileID != "":
+		return "tournaments are not supported for remote spawns"
+	case req.Resume:
+		return "cannot use resume with a tournament"
+	case strings.TrimSpace(req.Prompt) == "":
+		return "tournament requires a prompt"
+	}
+	total := 0
+	for _, count := range req.Targets {
+		total += count
+	}
+	if total < 2 {
+		return "tournament requires at least two candidates"
+	}
+	return ""
+}
+
+// startTournament registers the successfully spawned sessions of a
+// tournament spawn. A candidate that already reported completed or error
+// before this point is picked up from its nudge. Returns the tournament ID.
+func (h *SpawnHandlers) startTournament(req SpawnRequest, results []SessionResult) string {
+	var candidates []contracts.TournamentCandidate
+	for _, r := range results {
+		if r.Error != "" {
+			continue
+		}
+		c := contracts.TournamentCandidate{
+			SessionID:   r.SessionID,
+			WorkspaceID: r.WorkspaceID,
+			Target:      r.Target,
+			Nickname:    r.Nickname,
+		}
+		if ws, ok := h.state.GetWorkspace(r.WorkspaceID); ok {
+			c.Branch = ws.Branch
+		}
+		if sess, ok := h.state.GetSession(r.SessionID); ok {
+			nudgeState, _ := parseNudgeSummary(sess.Nudge)
+			switch nudgeState {
+			case "Completed":
+				c.State = contracts.TournamentCandidateCompleted
+			case "Error":
+				c.State = contracts.TournamentCandidateError
+			}
+		}
+		candidates = append(candidates, c)
+	}
+	t, err := h.tournamentRunner.Create(req, candidates)
+	if err != nil {
+		logging.Sub(h.logger, "tournament").Error("failed to start tournament", "err", err)
+		return ""
+	}
+	return t.ID
+}
+
+// compareTournamentCandidate is the tournament.CompareFunc: it collects the
+// candidate's branch diff and runs the test command in its workspace.
+func (s *Server) compareTournamentCandidate(ctx context.Context, c contracts.TournamentCandidate, testCommand []string) (tournament.Evaluation, error) {
+	ws, ok := s.state.GetWorkspace(c.WorkspaceID)
+	if !ok {
+		return tournament.Evaluation{}, fmt.Errorf("workspace not found: %s", c.WorkspaceID)
+	}
+	files, err := s.workspace.GetBranchChanges(ctx, c.WorkspaceID)
+	if err != nil {
+		return tournament.Evaluation{}, err
+	}
+	var ev tournament.Evaluation
+	for _, f := range files {
+		ev.Files = append(ev.Files, f.Path)
+		ev.LinesAdded += f.LinesAdded
+		ev.LinesRemoved += f.LinesRemoved
+	}
+	if patch, err := s.workspace.GetBranchPatch(ctx, c.WorkspaceID, tournament.MaxPatchBytes); err == nil {
+		ev.Patch = patch
+	}
+	if len(testCommand) > 0 {
+		ev.Test = tournament.RunTestCommand(ctx, ws.Path, testCommand)
+	}
+	return ev, nil
+}
+
+// judgeTournament is the tournament.JudgeFunc.
+func (s *Server) judgeTournament(ctx context.Context, target, prompt string) (tournament.JudgeResult, error) {
+	return tournament.AskJudge(ctx, s.config, target, prompt)
+}
+
+// disposeTournamentCandidate is the tournament.DisposeFunc: it disposes every
+// session in the losing candidate's workspace, then the workspace itself,
+// like dispose-all.
+func (s *Server) disposeTournamentCandidate(ctx context.Context, c contracts.TournamentCandidate) error {
+	workspaceLog := logging.Sub(s.logger, "workspace")
+	prevWsStatus, markErr := s.workspace.MarkWorkspaceDisposing(c.WorkspaceID)
+	if markErr == nil && prevWsStatus == "disposing" {
+		return nil
+	}
+	var wsSessions []string
+	for _, sess := range s.state.GetSessions() {
+		if sess.WorkspaceID == c.WorkspaceID {
+			wsSessions = append(wsSessions, sess.ID)
+			s.session.MarkSessionDisposing(sess.ID)
+		}
+	}
+	s.BroadcastSessions()
+
+	for _, id := range wsSessions {
+		sessCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		if err := s.session.Dispose(sessCtx, id); err != nil {
+			workspaceLog.Error("tournament dispose session failed", "session_id", id, "err", err)
+		} else {
+			s.rotationLocksMu.Lock()
+			delete(s.rotationLocks, id)
+			s.rotationLocksMu.Unlock()
+		}
+		cancel()
+	}
+
+	if err := s.workspace.DisposeForce(ctx, c.WorkspaceID); err != nil {
+		if markErr == nil {
+			s.workspace.RevertWorkspaceStatus(c.WorkspaceID, prevWsStatus)
+		}
+		go s.BroadcastSessions()
+		return err
+	}
+	if s.previewManager != nil {
+		if err := s.previewManager.DeleteWorkspace(c.WorkspaceID); err != nil {
+			logging.Sub(s.logger, "preview").Warn("tournament cleanup failed", "workspace_id", c.WorkspaceID, "err", err)
+		}
+	}
+	workspaceLog.Info("tournament candidate disposed", "workspace_id", c.WorkspaceID, "sessions_disposed", len(wsSessions))
+	go s.BroadcastSessions()
+	return nil
+}
@@ -0,0 +1,85 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/tournament"
+)
+
+func newTestTournamentHandlers(t *testing.T) (*TournamentHandlers, contracts.Tournament) {
+	t.Helper()
+	runner := tournament.NewRunner(filepath.Join(t.TempDir(), "tournaments.json"), nil)
+	tn, err := runner.Create(
+		contracts.SpawnRequest{Repo: "https://github.com/foo/bar", Prompt: "fix it", Tournament: &contracts.TournamentOptions{}},
+		[]contracts.TournamentCandidate{
+			{SessionID: "s-1", WorkspaceID: "ws-1", Target: "claude"},
+			{SessionID: "s-2", WorkspaceID: "ws-2", Target: "codex"},
+		},
+	)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return &TournamentHandlers{tournamentRunner: runner}, tn
+}
+
+func tournamentRequest(method, id, body string) *http.Request {
+	req := httptest.NewRequest(method, "/api/tournaments/"+id, bytes.NewBufferString(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestTournamentHandlers_ListAndGet(t *testing.T) {
+	h, tn := newTestTournamentHandlers(t)
+
+	rr := httptest.NewRecorder()
+	h.handleListTournaments(rr, httptest.NewRequest("GET", "/api/tournaments", nil))
+	var list contracts.TournamentsResponse
+	if err := json.NewDecoder(rr.Body).Decode(&list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list.Tournaments) != 1 || list.Tournaments[0].ID != tn.ID {
+		t.Fatalf("list = %+v", list)
+	}
+
+	rr = httptest.NewRecorder()
+	h.handleGetTournament(rr, tournamentRequest("GET", "tn-missing", ""))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("get missing status = %d, want 404", rr.Code)
+	}
+}
+
+func TestTournamentHandlers_PromoteAndCancel(t *testing.T) {
+	h, tn := newTestTournamentHandlers(t)
+
+	rr := httptest.NewRecorder()
+	h.handlePromoteTournament(rr, tournamentRequest("POST", tn.ID, ""))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("promote without a winner status = %d, want 400", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.handlePromoteTournament(rr, tournamentRequest("POST", tn.ID, `{"session_id":"s-2"}`))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("promote status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var got contracts.Tournament
+	json.NewDecoder(rr.Body).Decode(&got)
+	if got.Status != contracts.TournamentPromoted || got.WinnerSessionID != "s-2" {
+		t.Errorf("promoted tournament = %+v", got)
+	}
+
+	rr = httptest.NewRecorder()
+	h.handleCancelTournament(rr, tournamentRequest("POST", tn.ID, ""))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("cancel after promote status = %d, want 409", rr.Code)
+	}
+}
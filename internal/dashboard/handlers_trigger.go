@@ -0,0 +1,134 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/logging"
+)
+
+// triggerDefaultBranchTimeout bounds the default-branch lookup for trigger
+// spawns that name no branch.
+const triggerDefaultBranchTimeout = 30 * time.Second
+
+// handleTriggerSpawn starts a quick launch preset in a new workspace on
+// behalf of an external system. It only accepts API token authentication;
+// the token's name is logged with the spawn.
+func (h *SpawnHandlers) handleTriggerSpawn(w http.ResponseWriter, r *http.Request) {
+	tok := apiTokenFromContext(r.Context())
+	if tok == nil {
+		writeJSONError(w, "an API token with the spawn scope is required", http.StatusUnauthorized)
+		return
+	}
+	var req contracts.TriggerSpawnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	spawnReq, err := h.resolveTriggerSpawn(r.Context(), req)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logging.Sub(h.logger, "trigger").Info("trigger spawn", "token", tok.Name, "repo", req.Repo, "branch", spawnReq.Branch, "quick_launch", req.QuickLaunch)
+	results, err := h.spawnSessions(spawnReq)
+	if err != nil {
+		var reqErr *spawnRequestError
+		if errors.As(err, &reqErr) {
+			writeJSONError(w, reqErr.msg, reqErr.status)
+			return
+		}
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, results)
+}
+
+// resolveTriggerSpawn turns a trigger request into a spawn request: the repo
+// is looked up by name or URL, the quick launch preset by name from the
+// global config, and the preset's prompt is rendered with req.Variables.
+// Command presets run unchanged.
+func (h *SpawnHandlers) resolveTriggerSpawn(ctx context.Context, req contracts.TriggerSpawnRequest) (SpawnRequest, error) {
+	if req.Repo == "" {
+		return SpawnRequest{}, errors.New("repo is required")
+	}
+	if req.QuickLaunch == "" {
+		return SpawnRequest{}, errors.New("quick_launch is required")
+	}
+	repo, found := h.config.FindRepo(req.Repo)
+	if !found {
+		repo, found = h.config.FindRepoByURL(req.Repo)
+	}
+	if !found {
+		return SpawnRequest{}, fmt.Errorf("repo not found in config: %s", req.Repo)
+	}
+	preset := h.resolveQuickLaunchFromPresets(adaptQuickLaunch(h.config.GetQuickLaunch()), req.QuickLaunch)
+	if preset == nil {
+		return SpawnRequest{}, fmt.Errorf("quick launch not found: %s", req.QuickLaunch)
+	}
+
+	branch := req.Branch
+	if branch == "" && repo.VCS != "sapling" {
+		lookupCtx, cancel := context.WithTimeout(ctx, triggerDefaultBranchTimeout)
+		defer cancel()
+		defaultBranch, err := h.workspace.GetDefaultBranch(lookupCtx, repo.URL)
+		if err != nil {
+			return SpawnRequest{}, fmt.Errorf("branch is required: failed to resolve default branch: %w", err)
+		}
+		if defaultBranch == "" {
+			return SpawnRequest{}, errors.New("branch is required: default branch unknown")
+		}
+		branch = defaultBranch
+	}
+
+	spawnReq := SpawnRequest{
+		Repo:      repo.URL,
+		Branch:    branch,
+		Nickname:  req.Nickname,
+		PersonaID: preset.PersonaID,
+	}
+	if spawnReq.Nickname == "" {
+		spawnReq.Nickname = preset.Name
+	}
+	if preset.Command != "" {
+		spawnReq.Command = preset.Command
+		return spawnReq, nil
+	}
+	prompt, err := renderTriggerPrompt(preset.Prompt, req.Variables)
+	if err != nil {
+		return SpawnRequest{}, err
+	}
+	spawnReq.Targets = map[string]int{preset.Target: 1}
+	spawnReq.Prompt = prompt
+	return spawnReq, nil
+}
+
+// renderTriggerPrompt expands {{.name}} references in a quick launch prompt.
+// A reference to a variable the caller did not send is an error, so a typo
+// never reaches the agent as "<no value>".
+func renderTriggerPrompt(prompt string, vars map[string]string) (string, error) {
+	if !strings.Contains(prompt, "{{") {
+		return prompt, nil
+	}
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(prompt)
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("failed to render prompt: %w", err)
+	}
+	return buf.String(), nil
+}
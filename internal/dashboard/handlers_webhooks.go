@@ -0,0 +1,172 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/logging"
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/webhook"
+	"github.com/sergeknystautas/schmux/internal/workspace"
+)
+
+// webhookPingTimeout bounds a test delivery made from the API.
+const webhookPingTimeout = 15 * time.Second
+
+// SetWebhooks sets the outbound webhook dispatcher. Endpoints are read from
+// config on every event and secrets from secrets.json on every delivery.
+func (s *Server) SetWebhooks(d *webhook.Dispatcher) {
+	s.webhooks = d
+	if d == nil {
+		return
+	}
+	d.SetEndpointsFunc(s.config.GetWebhooks)
+	d.SetSecretFunc(func(name string) string {
+		secret, err := config.GetWebhookSecret(name)
+		if err != nil {
+			logging.Sub(s.logger, "webhook").Warn("failed to read webhook secret", "endpoint", name, "err", err)
+		}
+		return secret
+	})
+}
+
+// emitWebhook sends ev to matching webhook endpoints. No-op when webhooks
+// are not wired.
+func (s *Server) emitWebhook(ev webhook.Event) {
+	if s.webhooks != nil {
+		s.webhooks.Emit(ev)
+	}
+}
+
+// repoNameForURL returns the configured name of a repo URL, or "" when the
+// repo is not in config.
+func (s *Server) repoNameForURL(repoURL string) string {
+	if repo, ok := s.config.FindRepoByURL(repoURL); ok {
+		return repo.Name
+	}
+	return ""
+}
+
+// emitSessionStatusWebhook reports an accepted agent status change.
+func (s *Server) emitSessionStatusWebhook(sessionID, status, message, intent, blockers string) {
+	if s.webhooks == nil {
+		return
+	}
+	sess, ok := s.state.GetSession(sessionID)
+	if !ok {
+		return
+	}
+	data := map[string]any{
+		"session_id":   sess.ID,
+		"workspace_id": sess.WorkspaceID,
+		"target":       sess.Target,
+		"nickname":     sess.Nickname,
+		"state":        status,
+		"message":      message,
+		"intent":       intent,
+		"blockers":     blockers,
+	}
+	repo := ""
+	if ws, ok := s.state.GetWorkspace(sess.WorkspaceID); ok {
+		repo = s.repoNameForURL(ws.Repo)
+		data["branch"] = ws.Branch
+	}
+	s.emitWebhook(webhook.Event{Type: contracts.WebhookEventSessionStatus, Repo: repo, State: status, Data: data})
+}
+
+// HandleWorkspaceLifecycle is the workspace manager's lifecycle callback; it
+// reports created and disposed workspaces to webhooks.
+func (s *Server) HandleWorkspaceLifecycle(event string, w state.Workspace) {
+	eventType := contracts.WebhookEventWorkspaceCreated
+	if event == workspace.LifecycleDisposed {
+		eventType = contracts.WebhookEventWorkspaceDisposed
+	}
+	repo := s.repoNameForURL(w.Repo)
+	s.emitWebhook(webhook.Event{Type: eventType, Repo: repo, Data: map[string]any{
+		"workspace_id": w.ID,
+		"repo":         repo,
+		"repo_url":     w.Repo,
+		"branch":       w.Branch,
+		"path":         w.Path,
+		"remote":       w.RemoteHostID != "",
+	}})
+}
+
+// handleListWebhooks returns the configured endpoints without their secrets.
+func (s *Server) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	secrets, err := config.LoadSecretsFile()
+	if err != nil {
+		logging.Sub(s.logger, "webhook").Warn("failed to read secrets", "err", err)
+		secrets = &config.SecretsFile{}
+	}
+	resp := contracts.WebhooksResponse{Endpoints: []contracts.WebhookEndpointInfo{}}
+	for _, ep := range s.config.GetWebhooks() {
+		resp.Endpoints = append(resp.Endpoints, contracts.WebhookEndpointInfo{
+			Name:      ep.Name,
+			URL:       ep.URL,
+			Events:    ep.Events,
+			Repos:     ep.Repos,
+			States:    ep.States,
+			Disabled:  ep.Disabled,
+			HasSecret: secrets.Webhooks[ep.Name] != "",
+		})
+	}
+	writeJSON(w, resp)
+}
+
+// handleListWebhookDeliveries returns the delivery log, newest first,
+// optionally filtered by ?endpoint=.
+func (s *Server) handleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries := []contracts.WebhookDelivery{}
+	if s.webhooks != nil {
+		deliveries = s.webhooks.Deliveries(r.URL.Query().Get("endpoint"))
+	}
+	writeJSON(w, contracts.WebhookDeliveriesResponse{Deliveries: deliveries})
+}
+
+// handleTestWebhook sends a ping to one endpoint and returns the delivery.
+func (s *Server) handleTestWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.webhooks == nil {
+		writeJSONError(w, "webhooks not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), webhookPingTimeout)
+	defer cancel()
+	delivery, err := s.webhooks.Ping(ctx, chi.URLParam(r, "name"))
+	if errors.Is(err, webhook.ErrEndpointNotFound) {
+		writeJSONError(w, "webhook not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, delivery)
+}
+
+// handleSetWebhookSecret stores or clears an endpoint's signing secret.
+func (s *Server) handleSetWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if _, ok := s.config.GetWebhookEndpoint(name); !ok {
+		writeJSONError(w, "webhook not found", http.StatusNotFound)
+		return
+	}
+	var req struct {
+		Secret string `json:"secret"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := config.SaveWebhookSecret(name, req.Secret); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]bool{"has_secret": req.Secret != ""})
+}
@@ -0,0 +1,143 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/webhook"
+	"github.com/sergeknystautas/schmux/internal/workspace"
+)
+
+// newWebhookTestServer returns a server whose dispatcher sends every event
+// to a local receiver, along with the bodies it received.
+func newWebhookTestServer(t *testing.T) (*Server, *config.Config, func() []string) {
+	t.Helper()
+	server, cfg, _ := newTestServer(t)
+
+	var mu sync.Mutex
+	var bodies []string
+	rc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+	}))
+	t.Cleanup(rc.Close)
+
+	cfg.Repos = []config.Repo{{Name: "schmux", URL: "https://github.com/user/schmux.git"}}
+	cfg.Webhooks = []config.WebhookEndpoint{{Name: "ci", URL: rc.URL}}
+	server.SetWebhooks(webhook.NewDispatcher(filepath.Join(t.TempDir(), "deliveries.json"), nil))
+	return server, cfg, func() []string {
+		server.webhooks.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), bodies...)
+	}
+}
+
+func TestHandleWorkspaceLifecycle_EmitsWebhook(t *testing.T) {
+	server, _, received := newWebhookTestServer(t)
+
+	server.HandleWorkspaceLifecycle(workspace.LifecycleDisposed, state.Workspace{
+		ID:     "schmux-001",
+		Repo:   "https://github.com/user/schmux.git",
+		Branch: "fix",
+	})
+
+	bodies := received()
+	if len(bodies) != 1 {
+		t.Fatalf("received %d webhooks, want 1", len(bodies))
+	}
+	var p struct {
+		Type string         `json:"type"`
+		Repo string         `json:"repo"`
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(bodies[0]), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Type != contracts.WebhookEventWorkspaceDisposed || p.Repo != "schmux" || p.Data["workspace_id"] != "schmux-001" {
+		t.Errorf("unexpected payload: %s", bodies[0])
+	}
+
+	rr := httptest.NewRecorder()
+	server.handleListWebhookDeliveries(rr, httptest.NewRequest(http.MethodGet, "/api/webhooks/deliveries?endpoint=ci", nil))
+	var resp contracts.WebhookDeliveriesResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Deliveries) != 1 || resp.Deliveries[0].Status != contracts.WebhookDeliveryDelivered {
+		t.Errorf("unexpected deliveries: %+v", resp.Deliveries)
+	}
+}
+
+func TestHandleWebhookEndpoints(t *testing.T) {
+	server, _, received := newWebhookTestServer(t)
+
+	withName := func(r *http.Request, name string) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("name", name)
+		return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("test unknown endpoint", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		server.handleTestWebhook(rr, withName(httptest.NewRequest(http.MethodPost, "/api/webhooks/nope/test", nil), "nope"))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rr.Code)
+		}
+	})
+
+	t.Run("test endpoint", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		server.handleTestWebhook(rr, withName(httptest.NewRequest(http.MethodPost, "/api/webhooks/ci/test", nil), "ci"))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", rr.Code, rr.Body.String())
+		}
+		if bodies := received(); len(bodies) != 1 || !strings.Contains(bodies[0], `"type":"ping"`) {
+			t.Errorf("unexpected ping bodies: %v", bodies)
+		}
+	})
+
+	t.Run("set secret", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/api/webhooks/ci/secret", strings.NewReader(`{"secret":"s3cret"}`))
+		server.handleSetWebhookSecret(rr, withName(req, "ci"))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", rr.Code, rr.Body.String())
+		}
+
+		rr = httptest.NewRecorder()
+		server.handleListWebhooks(rr, httptest.NewRequest(http.MethodGet, "/api/webhooks", nil))
+		var resp contracts.WebhooksResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Endpoints) != 1 || !resp.Endpoints[0].HasSecret {
+			t.Errorf("unexpected endpoints: %+v", resp.Endpoints)
+		}
+		if strings.Contains(rr.Body.String(), "s3cret") {
+			t.Error("secret leaked in list response")
+		}
+	})
+
+	t.Run("set secret on unknown endpoint", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/api/webhooks/nope/secret", strings.NewReader(`{"secret":"x"}`))
+		server.handleSetWebhookSecret(rr, withName(req, "nope"))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rr.Code)
+		}
+	})
+}
@@ -22,10 +22,13 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/websocket"
 	dashboardassets "github.com/sergeknystautas/schmux/assets"
+	"github.com/sergeknystautas/schmux/internal/acceptance"
+	"github.com/sergeknystautas/schmux/internal/agentmsg"
 	"github.com/sergeknystautas/schmux/internal/api/contracts"
 	"github.com/sergeknystautas/schmux/internal/assets"
 	"github.com/sergeknystautas/schmux/internal/autolearn"
 	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/cost"
 	"github.com/sergeknystautas/schmux/internal/detect"
 	"github.com/sergeknystautas/schmux/internal/difftool"
 	"github.com/sergeknystautas/schmux/internal/floormanager"
@@ -33,19 +36,24 @@ import (
 	"github.com/sergeknystautas/schmux/internal/logging"
 	"github.com/sergeknystautas/schmux/internal/models"
 	"github.com/sergeknystautas/schmux/internal/persona"
+	"github.com/sergeknystautas/schmux/internal/pipeline"
 	"github.com/sergeknystautas/schmux/internal/preview"
 	"github.com/sergeknystautas/schmux/internal/remote"
 	"github.com/sergeknystautas/schmux/internal/repofeed"
 	"github.com/sergeknystautas/schmux/internal/schmuxdir"
+	"github.com/sergeknystautas/schmux/internal/search"
 	"github.com/sergeknystautas/schmux/internal/session"
 	"github.com/sergeknystautas/schmux/internal/spawn"
 	"github.com/sergeknystautas/schmux/internal/state"
 	"github.com/sergeknystautas/schmux/internal/style"
 	"github.com/sergeknystautas/schmux/internal/sysstat"
+	"github.com/sergeknystautas/schmux/internal/timelapse"
 	"github.com/sergeknystautas/schmux/internal/tmux"
+	"github.com/sergeknystautas/schmux/internal/tournament"
 	"github.com/sergeknystautas/schmux/internal/tunnel"
 	"github.com/sergeknystautas/schmux/internal/update"
 	"github.com/sergeknystautas/schmux/internal/version"
+	"github.com/sergeknystautas/schmux/internal/webhook"
 	"github.com/sergeknystautas/schmux/internal/workspace"
 	"github.com/sergeknystautas/schmux/internal/workspacestatus"
 )
@@ -121,21 +129,23 @@ type ServerOptions struct {
 
 // Server represents the dashboard HTTP server.
 type Server struct {
-	config       *config.Config
-	state        state.StateStore
-	statePath    string
-	session      *session.Manager
-	workspace    workspace.WorkspaceManager
-	httpServer   *http.Server
-	ipv6Listener net.Listener
-	logger       *log.Logger
-	shutdown     func() // Callback to trigger daemon shutdown
-	devRestart   func() // Callback to trigger dev mode restart (exit code 42)
-	devProxy     bool   // When true, proxy non-API routes to Vite dev server
-	devMode      bool   // When true, dev mode API endpoints are enabled
-	shutdownCtx  context.Context
-	BoundAddr    chan net.Addr // signals the bound address after listener starts; written exactly once
-	tmuxServer   *tmux.TmuxServer
+	config     *config.Config
+	state      state.StateStore
+	statePath  string
+	session    *session.Manager
+	workspace  workspace.WorkspaceManager
+	httpServer *http.Server
+	// metricsServer serves /metrics on network.metrics_listen_address; nil when unset.
+	metricsServer *http.Server
+	ipv6Listener  net.Listener
+	logger        *log.Logger
+	shutdown      func() // Callback to trigger daemon shutdown
+	devRestart    func() // Callback to trigger dev mode restart (exit code 42)
+	devProxy      bool   // When true, proxy non-API routes to Vite dev server
+	devMode       bool   // When true, dev mode API endpoints are enabled
+	shutdownCtx   context.Context
+	BoundAddr     chan net.Addr // signals the bound address after listener starts; written exactly once
+	tmuxServer    *tmux.TmuxServer
 
 	// WebSocket connection registry: sessionID -> active connection (for terminal)
 	// Only one connection per session; new connections displace old ones.
@@ -264,6 +274,32 @@ type Server struct {
 	spawnStore         *spawn.Store
 	spawnMetadataStore *spawn.MetadataStore
 
+	// Pipeline definitions and run tracking
+	pipelineStore  *spawn.PipelineStore
+	pipelineRunner *pipeline.Runner
+
+	tournamentRunner *tournament.Runner
+
+	// Acceptance commands run when an agent reports completed
+	acceptanceRunner *acceptance.Runner
+
+	// Outbound webhooks
+	webhooks *webhook.Dispatcher
+
+	// Token and cost accounting; costBudgetStates holds the last budget
+	// state logged per day and per session.
+	costLedger       *cost.Ledger
+	costBudgetStates sync.Map
+
+	// Agent-to-agent message router
+	agentRouter *agentmsg.Router
+
+	// Full-text index over session output, events and spawn prompts
+	searchIndex *search.Indexer
+
+	// Timelapse chapter markers; tells are marked in the recording
+	timelapseMarkers *timelapse.Markers
+
 	// Subreddit next generation time tracking
 	nextSubredditGeneration atomic.Pointer[time.Time]
 
@@ -497,6 +533,60 @@ func (s *Server) SetSpawnMetadataStore(store *spawn.MetadataStore) {
 	s.spawnMetadataStore = store
 }
 
+// SetPipelines sets the pipeline definition store and run tracker. The runner
+// spawns stages through the same path as POST /api/spawn and rebroadcasts
+// sessions whenever a run advances.
+func (s *Server) SetPipelines(store *spawn.PipelineStore, runner *pipeline.Runner) {
+	s.pipelineStore = store
+	s.pipelineRunner = runner
+	if s.sessionHandlers != nil {
+		s.sessionHandlers.pipelineRunner = runner
+	}
+	if runner != nil {
+		runner.SetSpawnFunc(s.spawnForPipeline)
+		runner.SetChangeCallback(s.BroadcastSessions)
+	}
+}
+
+// SetTournaments sets the best-of-N tournament tracker. The runner compares
+// candidates through the workspace manager, judges them with a oneshot
+// target, and disposes losers like dispose-all.
+func (s *Server) SetTournaments(runner *tournament.Runner) {
+	s.tournamentRunner = runner
+	if s.sessionHandlers != nil {
+		s.sessionHandlers.tournamentRunner = runner
+	}
+	if runner != nil {
+		runner.SetCompareFunc(s.compareTournamentCandidate)
+		runner.SetJudgeFunc(s.judgeTournament)
+		runner.SetDisposeFunc(s.disposeTournamentCandidate)
+		runner.SetChangeCallback(s.BroadcastSessions)
+	}
+}
+
+// SetAcceptance sets the acceptance command tracker. The runner checks a
+// session's workspace when the agent reports completed and types failures
+// back into the session like tell.
+func (s *Server) SetAcceptance(runner *acceptance.Runner) {
+	s.acceptanceRunner = runner
+	if s.sessionHandlers != nil {
+		s.sessionHandlers.acceptanceRunner = runner
+	}
+	if runner != nil {
+		runner.SetPlanFunc(s.planAcceptance)
+		runner.SetCheckFunc(s.runAcceptanceCheck)
+		runner.SetDeliverFunc(s.deliverAcceptanceFailure)
+		runner.SetResultFunc(s.handleAcceptanceResult)
+		runner.SetChangeCallback(s.BroadcastSessions)
+	}
+}
+
+// SetTimelapseMarkers sets where tell injections are reported as timelapse
+// chapter markers.
+func (s *Server) SetTimelapseMarkers(m *timelapse.Markers) {
+	s.timelapseMarkers = m
+}
+
 // SetAutolearnStore sets the autolearn batch store for the dashboard API.
 func (s *Server) SetAutolearnStore(store *autolearn.BatchStore) {
 	s.autolearnStore = store
@@ -668,6 +758,12 @@ func (s *Server) Start() error {
 		r.Get("/auth/me", s.handleAuthMe)
 	})
 
+	// Prometheus scrape endpoint — same auth as /api; read-scoped API tokens work.
+	r.Group(func(r chi.Router) {
+		r.Use(s.authMiddleware)
+		r.Get("/metrics", s.handleMetrics)
+	})
+
 	// WebSocket routes (inline auth, no CORS middleware)
 	r.HandleFunc("/ws/terminal/{id}", s.handleTerminalWebSocket)
 	r.HandleFunc("/ws/provision/{id}", s.handleProvisionWebSocket)
@@ -733,6 +829,7 @@ func (s *Server) Start() error {
 			resumeViteWatch:                          s.resumeViteWatch,
 			requireWorkspace:                         s.requireWorkspace,
 			vcsTypeForWorkspace:                      s.vcsTypeForWorkspace,
+			tellPushGateFailure:                      s.tellPushGateFailure,
 
 			getLinearSyncResolveConflictState:    s.getLinearSyncResolveConflictState,
 			setLinearSyncResolveConflictState:    s.setLinearSyncResolveConflictState,
@@ -767,22 +864,20 @@ func (s *Server) Start() error {
 		}
 
 		// Spawn handler group
-		spawnH := &SpawnHandlers{
+		spawnH := s.newSpawnHandlers()
+
+		// Pipeline handler group
+		pipelineH := &PipelineHandlers{
 			config:         s.config,
-			state:          s.state,
-			session:        s.session,
-			workspace:      s.workspace,
-			models:         s.models,
-			remoteManager:  s.remoteManager,
-			personaManager: s.personaManager,
-			styleManager:   s.styleManager,
-			spawnStore:     s.spawnStore,
-			clipboardState: s.clipboardState,
+			pipelineStore:  s.pipelineStore,
+			pipelineRunner: s.pipelineRunner,
 			logger:         s.logger,
+		}
 
-			broadcastSessions:   s.BroadcastSessions,
-			vcsTypeForWorkspace: s.vcsTypeForWorkspace,
-			dependencyReport:    s.dependencyReport,
+		// Tournament handler group
+		tournamentH := &TournamentHandlers{
+			tournamentRunner: s.tournamentRunner,
+			logger:           s.logger,
 		}
 
 		// Session handler group: reuse the instance built in NewServer.
@@ -803,6 +898,7 @@ func (s *Server) Start() error {
 		r.Get("/commit/prompt", gitH.handleCommitPrompt)
 		r.Get("/diff/*", gitH.handleDiff)
 		r.Get("/diff-file/*", gitH.handleDiffFile)
+		r.Get("/diff-patch/*", gitH.handleDiffPatch)
 		r.Get("/file/*", gitH.handleFile)
 		r.Get("/overlays", wsH.handleOverlays)
 		r.Get("/prs", s.handlePRs)
@@ -840,13 +936,30 @@ func (s *Server) Start() error {
 
 		r.Get("/timelapse", s.handleTimelapseList)
 		r.Get("/timelapse/{recordingId}/download", s.handleTimelapseDownload)
+		r.Get("/timelapse/{recordingId}/chapters", s.handleTimelapseChapters)
 
 		r.Get("/tls/validate", s.handleTLSValidate)
 		r.Get("/debug/tmux-leak", s.handleDebugTmuxLeak)
 
+		r.Get("/pipeline-runs", pipelineH.handleListPipelineRuns)
+		r.Get("/pipeline-runs/{runID}", pipelineH.handleGetPipelineRun)
+		r.Get("/tournaments", tournamentH.handleListTournaments)
+		r.Get("/tournaments/{id}", tournamentH.handleGetTournament)
+		r.Get("/webhooks", s.handleListWebhooks)
+		r.Get("/webhooks/deliveries", s.handleListWebhookDeliveries)
+		r.Get("/tokens", s.handleListAPITokens)
+		r.Get("/costs", s.handleCosts)
+		// Trigger spawns authenticate with an API token only; without cookies
+		// there is nothing for CSRF to protect.
+		r.Post("/trigger/spawn", spawnH.handleTriggerSpawn)
+
 		r.Get("/sessions/{sessionID}/events", s.handleGetSessionEvents)
+		r.Get("/sessions/{sessionID}/acceptance", s.handleGetSessionAcceptance)
+		r.Get("/sessions/{sessionID}/messages", s.handleGetSessionMessages)
 		r.Get("/sessions/{sessionID}/capture", s.handleCaptureSession)
+		r.Get("/search", s.handleSearch)
 		r.Get("/branches", spawnH.handleGetBranches)
+		r.Get("/disk-usage", wsH.handleGetDiskUsage)
 
 		r.Get("/github/status", s.handleGetGitHubStatus)
 		r.Get("/features", configH.handleGetFeatures)
@@ -866,6 +979,7 @@ func (s *Server) Start() error {
 			r.Post("/workspaces/scan", wsH.handleWorkspacesScan)
 			r.Delete("/workspaces/purge", wsH.handlePurgeAll)
 			r.Get("/workspaces/recyclable", wsH.handleGetRecyclableWorkspaces)
+			r.Get("/workspaces/auto-cleanup", wsH.handleGetAutoCleanupReport)
 			r.Post("/suggest-branch", spawnH.handleSuggestBranch)
 			r.Post("/prepare-branch-spawn", spawnH.handlePrepareBranchSpawn)
 			r.Post("/check-branch-conflict", spawnH.handleCheckBranchConflict)
@@ -888,10 +1002,19 @@ func (s *Server) Start() error {
 			r.Delete("/timelapse/{recordingId}", s.handleTimelapseDelete)
 			r.Post("/environment/sync", s.handleSyncEnvironment)
 			r.Post("/repofeed/dismiss", s.handleRepofeedDismiss)
+			r.Post("/pipeline-runs/{runID}/cancel", pipelineH.handleCancelPipelineRun)
+			r.Post("/tournaments/{id}/compare", tournamentH.handleCompareTournament)
+			r.Post("/tournaments/{id}/promote", tournamentH.handlePromoteTournament)
+			r.Post("/tournaments/{id}/cancel", tournamentH.handleCancelTournament)
+			r.Post("/webhooks/{name}/test", s.handleTestWebhook)
+			r.Put("/webhooks/{name}/secret", s.handleSetWebhookSecret)
+			r.Post("/tokens", s.handleCreateAPIToken)
+			r.Delete("/tokens/{id}", s.handleRevokeAPIToken)
 
 			// Session routes
 			r.Post("/sessions/{sessionID}/dispose", wsH.handleDispose)
 			r.Post("/sessions/{sessionID}/tell", s.handleTellSession)
+			r.Post("/sessions/{sessionID}/acceptance/run", s.handleRunSessionAcceptance)
 			r.Post("/sessions/{sessionID}/clipboard", makeClipboardAckHandler(s.clipboardState))
 			r.Post("/sessions/{sessionID}/fence-analyze", spawnH.handleFenceAnalyze)
 			r.Post("/sessions/{sessionID}/restart", spawnH.handleRestart)
@@ -967,6 +1090,11 @@ func (s *Server) Start() error {
 				r.Get("/commit-graph", gitH.handleWorkspaceCommitGraph)
 				r.Get("/commit-detail/{hash}", gitH.handleWorkspaceCommitDetail)
 
+				// Checkpoint routes
+				r.Get("/checkpoints", gitH.handleListCheckpoints)
+				r.Post("/checkpoints", gitH.handleCreateCheckpoint)
+				r.Post("/checkpoints/{seq}/restore", gitH.handleRestoreCheckpoint)
+
 				// Linear sync routes
 				r.Post("/linear-sync-from-main", gitH.handleLinearSyncFromMain)
 				r.Post("/linear-sync-to-main", gitH.handleLinearSyncToMain)
@@ -996,6 +1124,9 @@ func (s *Server) Start() error {
 				// Backburner route
 				r.Post("/backburner", wsH.handleBackburnerWorkspace)
 
+				// Auto-cleanup protection route
+				r.Post("/protect", wsH.handleProtectWorkspace)
+
 				// Share intent route (repofeed)
 				r.Post("/share-intent", wsH.handleShareIntent)
 			})
@@ -1048,6 +1179,13 @@ func (s *Server) Start() error {
 				r.Post("/entries/{id}/dismiss", spawnEntryH.handleDismissSpawnEntry)
 				r.Post("/entries/{id}/use", spawnEntryH.handleRecordSpawnEntryUse)
 				r.Get("/prompt-history", spawnEntryH.handlePromptHistory)
+
+				r.Get("/pipelines", pipelineH.handleListPipelines)
+				r.Post("/pipelines", pipelineH.handleCreatePipeline)
+				r.Get("/pipelines/{id}", pipelineH.handleGetPipeline)
+				r.Put("/pipelines/{id}", pipelineH.handleUpdatePipeline)
+				r.Delete("/pipelines/{id}", pipelineH.handleDeletePipeline)
+				r.Post("/pipelines/{id}/run", pipelineH.handleRunPipeline)
 			})
 		})
 
@@ -1123,6 +1261,10 @@ func (s *Server) Start() error {
 		}
 	}
 
+	if err := s.startMetricsListener(); err != nil {
+		return fmt.Errorf("failed to start metrics listener: %w", err)
+	}
+
 	// Split listen from serve to signal the actual bound address
 	primaryListener, err := net.Listen("tcp", s.httpServer.Addr)
 	if err != nil {
@@ -1186,9 +1328,15 @@ func (s *Server) Stop() error {
 		s.logger.Warn("graceful shutdown timed out, forcing close", "err", err)
 		s.httpServer.Close()
 	}
+	if s.metricsServer != nil {
+		s.metricsServer.Close()
+	}
 	if s.previewManager != nil {
 		s.previewManager.Stop()
 	}
+	if s.webhooks != nil {
+		s.webhooks.Close()
+	}
 	// Stop rate limiter cleanup goroutines
 	s.connectLimiter.Stop()
 	s.remoteAuthLimiter.Stop()
@@ -1864,7 +2012,7 @@ func (s *Server) dispatchClipboardRequest(req session.ClipboardRequest) {
 
 // managedLocalSockets returns the unique set of local tmux socket names schmux
 // is managing: the configured socket plus the sockets of any restored local
-// (non-remote) sessions. "" is normalized to "default", matching the daemon's
+// tmux (non-remote, non-PTY) sessions. "" is normalized to "default", matching the daemon's
 // startup activeSocketSet behavior. Restored local sessions on older sockets are
 // still schmux-managed and must not keep stale clipboard behavior.
 func (s *Server) managedLocalSockets() []string {
@@ -1877,7 +2025,7 @@ func (s *Server) managedLocalSockets() []string {
 	}
 	add(s.config.GetTmuxSocketName())
 	for _, sess := range s.state.GetSessions() {
-		if sess.IsRemoteSession() {
+		if sess.IsRemoteSession() || sess.IsPTYSession() {
 			continue
 		}
 		add(sess.TmuxSocket)
@@ -39,3 +39,17 @@ func TestServer_ManagedLocalSockets(t *testing.T) {
 		}
 	}
 }
+
+func TestServer_ManagedLocalSockets_SkipsPTYSessions(t *testing.T) {
+	server, _, st := newTestServer(t)
+	// PTY sessions have no tmux socket and must not pull in the user's
+	// personal "default" server.
+	if err := st.AddSession(state.Session{ID: "pty", Backend: state.SessionBackendPTY}); err != nil {
+		t.Fatalf("AddSession: %v", err)
+	}
+
+	got := server.managedLocalSockets()
+	if len(got) != 1 || got[0] != "schmux" {
+		t.Errorf("managedLocalSockets = %v, want [schmux]", got)
+	}
+}
@@ -91,11 +91,17 @@ func bootstrapFrameSeq(log *session.OutputLog) uint64 {
 	return log.Append(nil)
 }
 
+// gapReplaySource is where gap replays read missed output from: a session's
+// OutputLog, or its SessionRuntime, which also reaches the on-disk scrollback.
+type gapReplaySource interface {
+	ReplayFrom(fromSeq uint64) []session.LogEntry
+}
+
 // buildGapReplayFrames replays missing events from the output log as sequenced frames.
 // Each entry is sent as its own frame tagged with its original sequence number,
 // ensuring the frontend's per-seq dedup correctly skips already-received entries.
 // Returns nil if the requested data has been evicted from the log.
-func buildGapReplayFrames(log *session.OutputLog, fromSeq uint64) [][]byte {
+func buildGapReplayFrames(log gapReplaySource, fromSeq uint64) [][]byte {
 	entries := log.ReplayFrom(fromSeq)
 	if entries == nil {
 		return nil // data evicted
@@ -761,7 +767,7 @@ drainBootstrap:
 				if err != nil {
 					break
 				}
-				frames := buildGapReplayFrames(tracker.OutputLog(), fromSeq)
+				frames := buildGapReplayFrames(tracker, fromSeq)
 				for _, frame := range frames {
 					if err := conn.WriteMessage(websocket.BinaryMessage, frame); err != nil {
 						tracker.Counters.WsWriteErrors.Add(1)
@@ -772,18 +778,19 @@ drainBootstrap:
 				if !s.devMode {
 					break
 				}
-				// Capture tmux screen via control mode
-				capCtx, capCancel := context.WithTimeout(context.Background(), 2*time.Second)
-				tmuxScreen, err := tracker.CaptureLastLines(capCtx, 0)
-				capCancel()
+				// Capture tmux screen via control mode. This bypasses the
+				// runtime's screen model on purpose: the diagnostic compares
+				// what tmux holds against what the client and the model hold.
+				tmuxScreen, err := tracker.Source().CaptureLines(0)
 				if err != nil {
 					logging.Sub(s.logger, "diagnostic").Debug("capture-pane failed", "session_id", sessionID[:8], "err", err)
 					break
 				}
 				// Capture cursor state
-				curCtx, curCancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
-				cursorState, curErr := tracker.GetCursorState(curCtx)
-				curCancel()
+				cursorState, curErr := tracker.Source().GetCursorState()
+				modelCtx, modelCancel := context.WithTimeout(context.Background(), 2*time.Second)
+				modelScreen, _ := tracker.CaptureLastLines(modelCtx, 0)
+				modelCancel()
 				counters := tracker.DiagnosticCounters()
 				// Enrich counters with output log metrics for findings analysis
 				counters["currentSeq"] = int64(tracker.OutputLog().CurrentSeq())
@@ -805,6 +812,7 @@ drainBootstrap:
 					Rows:              int(tracker.LastTerminalRows.Load()),
 					Counters:          counters,
 					TmuxScreen:        tmuxScreen,
+					ModelScreen:       modelScreen,
 					RingBuffer:        rbSnapshot,
 					Findings:          findings,
 					Verdict:           verdict,
@@ -1116,6 +1124,10 @@ func (s *Server) HandleStatusEvent(sessionID, state, message, intent, blockers s
 
 	logging.Sub(s.logger, "events").Debug("received status event", "session_id", sessionID, "state", state, "seq", seq, "message", message)
 
+	if nudgeChanged {
+		s.emitSessionStatusWebhook(sessionID, state, message, intent, blockers)
+	}
+
 	// Broadcast via debouncer
 	go s.BroadcastSessions()
 }
@@ -1158,7 +1170,8 @@ func nudgeStateTier(displayState string) int {
 	}
 }
 
-// handleProvisionWebSocket streams PTY I/O for remote host provisioning.
+// handleProvisionWebSocket streams PTY I/O for remote host provisioning,
+// and the output of a workspace's setup commands for "setup-<workspaceID>".
 func (s *Server) handleProvisionWebSocket(w http.ResponseWriter, r *http.Request) {
 	provisionID := chi.URLParam(r, "id")
 	if provisionID == "" {
@@ -1176,6 +1189,11 @@ func (s *Server) handleProvisionWebSocket(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	if workspaceID, ok := strings.CutPrefix(provisionID, "setup-"); ok {
+		s.handleSetupOutputWebSocket(w, r, workspaceID)
+		return
+	}
+
 	if s.remoteManager == nil {
 		writeJSONError(w, "remote workspace support not enabled", http.StatusServiceUnavailable)
 		return
@@ -1305,6 +1323,59 @@ func (s *Server) handleProvisionWebSocket(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// handleSetupOutputWebSocket streams a workspace's running setup output as
+// binary messages, starting with what it has printed so far, and closes
+// when setup finishes. Finished runs are read from the workspace's setup
+// field instead.
+func (s *Server) handleSetupOutputWebSocket(w http.ResponseWriter, r *http.Request, workspaceID string) {
+	backlog, output, unsubscribe, ok := s.workspace.SubscribeSetupOutput(workspaceID)
+	if !ok {
+		writeJSONError(w, "workspace setup is not running", http.StatusNotFound)
+		return
+	}
+	defer unsubscribe()
+
+	rawConn, err := s.upgradeWebSocket(w, r, 1024, 1024)
+	if err != nil {
+		return
+	}
+	conn := &wsConn{conn: rawConn}
+	defer conn.Close()
+
+	// Drain client messages so a closed browser tab ends the stream.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := rawConn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	if len(backlog) > 0 {
+		if err := conn.WriteMessage(websocket.BinaryMessage, backlog); err != nil {
+			return
+		}
+	}
+	for {
+		select {
+		case data, ok := <-output:
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "setup finished"))
+				return
+			}
+			if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
 // buildDiagnosticFindings analyzes counters from the session tracker and
 // produces a list of human-readable findings and an overall verdict.
 func buildDiagnosticFindings(counters map[string]int64) (findings []string, verdict string) {
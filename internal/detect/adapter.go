@@ -141,6 +141,11 @@ type ToolAdapter interface {
 	// only when a spawn is fenced. Empty if the agent has no such mode.
 	AutoApproveArgs() []string
 
+	// MCPArgs returns the CLI args that register the schmux MCP server with
+	// the agent, running schmuxBin scoped to workspaceID (empty = unscoped).
+	// Nil if the agent does not take MCP servers on its command line.
+	MCPArgs(schmuxBin, workspaceID string) []string
+
 	// FenceDomains returns the harness's own control-plane domains the fence
 	// must allow (login/subscription auth, update checks, telemetry),
 	// independent of the model provider. Empty if the harness needs none.
@@ -54,7 +54,7 @@ func statusEventWithContextCommand(state, jqField string) string {
 // When hooksDir is empty, falls back to $CLAUDE_PROJECT_DIR/.schmux/hooks/ paths.
 func buildClaudeHooksMap(hooksDir string) map[string][]claudeHookMatcherGroup {
 	// Build stop hook commands using centralized or per-workspace paths
-	var stopStatusCmd, stopAutolearnCmd, captureFailureCmd, captureSessionCmd string
+	var stopStatusCmd, stopAutolearnCmd, captureFailureCmd, captureSessionCmd, captureUsageCmd string
 	if hooksDir != "" {
 		stopStatusPath := filepath.Join(hooksDir, "stop-status-check.sh")
 		stopAutolearnPath := filepath.Join(hooksDir, "stop-autolearn-check.sh")
@@ -64,11 +64,14 @@ func buildClaudeHooksMap(hooksDir string) map[string][]claudeHookMatcherGroup {
 		stopAutolearnCmd = fmt.Sprintf(`[ -f "%s" ] && "%s" || true`, stopAutolearnPath, stopAutolearnPath)
 		captureFailureCmd = fmt.Sprintf(`[ -f "%s" ] && "%s" || true`, captureFailurePath, captureFailurePath)
 		captureSessionCmd = fmt.Sprintf(`[ -f "%s" ] && "%s" || true`, captureSessionPath, captureSessionPath)
+		captureUsagePath := filepath.Join(hooksDir, "capture-usage.sh")
+		captureUsageCmd = fmt.Sprintf(`[ -f "%s" ] && "%s" || true`, captureUsagePath, captureUsagePath)
 	} else {
 		stopStatusCmd = `[ -f "$CLAUDE_PROJECT_DIR/.schmux/hooks/stop-status-check.sh" ] && "$CLAUDE_PROJECT_DIR"/.schmux/hooks/stop-status-check.sh || true`
 		stopAutolearnCmd = `[ -f "$CLAUDE_PROJECT_DIR/.schmux/hooks/stop-autolearn-check.sh" ] && "$CLAUDE_PROJECT_DIR"/.schmux/hooks/stop-autolearn-check.sh || true`
 		captureFailureCmd = `[ -f "$CLAUDE_PROJECT_DIR/.schmux/hooks/capture-failure.sh" ] && "$CLAUDE_PROJECT_DIR"/.schmux/hooks/capture-failure.sh || true`
 		captureSessionCmd = `[ -f "$CLAUDE_PROJECT_DIR/.schmux/hooks/capture-session.sh" ] && "$CLAUDE_PROJECT_DIR"/.schmux/hooks/capture-session.sh || true`
+		captureUsageCmd = `[ -f "$CLAUDE_PROJECT_DIR/.schmux/hooks/capture-usage.sh" ] && "$CLAUDE_PROJECT_DIR"/.schmux/hooks/capture-usage.sh || true`
 	}
 
 	hooks := map[string][]claudeHookMatcherGroup{
@@ -165,6 +168,20 @@ func buildClaudeHooksMap(hooksDir string) map[string][]claudeHookMatcherGroup {
 		})
 	}
 
+	// Report the transcript after every turn so the daemon can account the
+	// session's token usage.
+	if captureUsageCmd != "" {
+		hooks["Stop"] = append(hooks["Stop"], claudeHookMatcherGroup{
+			Hooks: []claudeHookHandler{
+				{
+					Type:          "command",
+					Command:       captureUsageCmd,
+					StatusMessage: "schmux: usage",
+				},
+			},
+		})
+	}
+
 	// Capture the harness session id as a resume_id event on SessionStart and
 	// UserPromptSubmit. UserPromptSubmit is the recurring re-emit that defeats
 	// the event-watcher startup race (a lost startup line is recaptured next
@@ -395,6 +412,9 @@ var claudeCaptureFailureScript []byte
 //go:embed hooks/capture-session.sh
 var claudeCaptureSessionScript []byte
 
+//go:embed hooks/capture-usage.sh
+var claudeCaptureUsageScript []byte
+
 //go:embed hooks/stop-status-check.sh
 var claudeStopStatusCheckScript []byte
 
@@ -413,6 +433,7 @@ func EnsureGlobalHookScripts(homeDir string) (string, error) {
 	scripts := map[string][]byte{
 		"capture-failure.sh":      claudeCaptureFailureScript,
 		"capture-session.sh":      claudeCaptureSessionScript,
+		"capture-usage.sh":        claudeCaptureUsageScript,
 		"stop-status-check.sh":    claudeStopStatusCheckScript,
 		"stop-autolearn-check.sh": claudeStopAutolearnCheckScript,
 	}
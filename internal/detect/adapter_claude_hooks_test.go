@@ -128,8 +128,8 @@ func TestClaudeSetupHooksPreservesUserHooks(t *testing.T) {
 
 	// User's Stop hook should be preserved alongside schmux's
 	stopGroups := hooks["Stop"]
-	if len(stopGroups) != 4 {
-		t.Fatalf("Stop should have 4 matcher groups (user + 3 schmux), got %d", len(stopGroups))
+	if len(stopGroups) != 5 {
+		t.Fatalf("Stop should have 5 matcher groups (user + 4 schmux), got %d", len(stopGroups))
 	}
 	// First should be the user's hook (preserved order)
 	if stopGroups[0].Hooks[0].Command != "echo user-stop-hook" {
@@ -452,7 +452,7 @@ func TestEnsureGlobalHookScripts(t *testing.T) {
 	}
 
 	// Verify all scripts were written
-	for _, name := range []string{"capture-failure.sh", "stop-status-check.sh", "stop-autolearn-check.sh"} {
+	for _, name := range []string{"capture-failure.sh", "capture-usage.sh", "stop-status-check.sh", "stop-autolearn-check.sh"} {
 		path := filepath.Join(hooksDir, name)
 		info, err := os.Stat(path)
 		if err != nil {
@@ -346,6 +346,23 @@ func (a *GenericAdapter) AutoApproveArgs() []string {
 	return a.desc.AutoApproveArgs
 }
 
+// mcpValueEscaper escapes a value substituted into mcp_args, which embed it
+// in a JSON (claude) or TOML (codex) string; both use the same escapes.
+var mcpValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// MCPArgs returns the CLI args that register the schmux MCP server.
+func (a *GenericAdapter) MCPArgs(schmuxBin, workspaceID string) []string {
+	if len(a.desc.MCPArgs) == 0 {
+		return nil
+	}
+	r := strings.NewReplacer("{command}", mcpValueEscaper.Replace(schmuxBin), "{workspace_id}", mcpValueEscaper.Replace(workspaceID))
+	out := make([]string, len(a.desc.MCPArgs))
+	for i, arg := range a.desc.MCPArgs {
+		out[i] = r.Replace(arg)
+	}
+	return out
+}
+
 // FenceDomains returns the harness's own control-plane domains the fence must allow.
 func (a *GenericAdapter) FenceDomains() []string {
 	return a.desc.FenceDomains
@@ -518,3 +518,31 @@ func TestGitExcludePatterns_HomeAbsoluteSettingsFileExcluded(t *testing.T) {
 		t.Errorf("GitExcludePatterns() = %v, want %v (home-absolute settings file must not become a workspace exclude)", got, want)
 	}
 }
+
+func TestGenericAdapterMCPArgs(t *testing.T) {
+	yamlData := []byte(`
+name: testtool
+detect:
+  - type: path_lookup
+    command: testtool
+mcp_args: ['--mcp', '{"command":"{command}","args":["mcp","--workspace","{workspace_id}"]}']
+`)
+	d, err := ParseDescriptor(yamlData)
+	if err != nil {
+		t.Fatalf("ParseDescriptor: %v", err)
+	}
+	a, err := NewGenericAdapter(d)
+	if err != nil {
+		t.Fatalf("NewGenericAdapter: %v", err)
+	}
+	got := a.MCPArgs(`/opt/my "tools"/schmux`, "ws-1")
+	want := `{"command":"/opt/my \"tools\"/schmux","args":["mcp","--workspace","ws-1"]}`
+	if len(got) != 2 || got[0] != "--mcp" || got[1] != want {
+		t.Errorf("MCPArgs() = %v, want [--mcp %s]", got, want)
+	}
+
+	noMCP, _ := NewGenericAdapter(&Descriptor{Name: "plain", Detect: []DetectEntry{{Type: "path_lookup", Command: "plain"}}})
+	if got := noMCP.MCPArgs("/bin/schmux", "ws-1"); got != nil {
+		t.Errorf("MCPArgs() without mcp_args = %v, want nil", got)
+	}
+}
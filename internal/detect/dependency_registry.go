@@ -30,6 +30,17 @@ var nativeDeps = []Dependency{
 		},
 		detect: func(ctx context.Context) DetectionResult { return detectVCSNamed("sapling") },
 	},
+	{
+		ID: "jj", DisplayName: "jj", Group: "vcs",
+		Description: "Jujutsu version control.",
+		Unlocks:     []string{"jj-backed workspaces"},
+		DocsURL:     "https://jj-vcs.github.io/jj",
+		Install: []InstallMethod{
+			{OS: "macos", Label: "Homebrew", Command: "brew install jj", Requires: "homebrew"},
+			{OS: "linux", Label: "Instructions", URL: "https://jj-vcs.github.io/jj/latest/install-and-setup/"},
+		},
+		detect: func(ctx context.Context) DetectionResult { return detectVCSNamed("jj") },
+	},
 	{
 		ID: "tmux", DisplayName: "tmux", Group: "terminal",
 		Description: "Terminal multiplexer schmux runs sessions in.",
@@ -40,6 +40,11 @@ type Descriptor struct {
 	// the model provider, which is allowed separately via the fence "code"
 	// template and runner endpoints.
 	FenceDomains []string `yaml:"fence_domains"`
+	// MCPArgs register the schmux MCP server (`schmux mcp`) with the agent
+	// when it is spawned. {command} is replaced with the schmux binary and
+	// {workspace_id} with the session's workspace, both escaped for a JSON
+	// or TOML string. Empty = the agent is not given the server.
+	MCPArgs []string `yaml:"mcp_args"`
 }
 
 // RunnerEnvDesc describes env vars the adapter emits when spawning a runner.
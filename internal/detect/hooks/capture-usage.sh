@@ -0,0 +1,11 @@
+#!/bin/bash
+# Schmux: report the session transcript as a usage event so the daemon can
+# account its token usage. Called by claude's Stop hook. Reads hook JSON from
+# stdin.
+set -euo pipefail
+[ -n "${SCHMUX_EVENTS_FILE:-}" ] || exit 0
+TRANSCRIPT=$(jq -r '.transcript_path // empty' 2>/dev/null || true)
+[ -n "$TRANSCRIPT" ] || exit 0
+TS=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+jq -n -c --arg ts "$TS" --arg t "$TRANSCRIPT" '{ts:$ts,type:"usage",transcript:$t}' >> "$SCHMUX_EVENTS_FILE"
+exit 0
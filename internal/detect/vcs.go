@@ -24,6 +24,7 @@ func DetectVCS() []VCSTool {
 	}{
 		{"git", "git"},
 		{"sapling", "sl"},
+		{"jj", "jj"},
 	}
 
 	var tools []VCSTool
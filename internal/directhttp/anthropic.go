@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	"strings"
+
+	"github.com/sergeknystautas/schmux/internal/cost"
 )
 
 type anthropicRequest struct {
@@ -25,8 +27,10 @@ type anthropicMessage struct {
 type anthropicResponse struct {
 	Content []anthropicBlock `json:"content"`
 	Usage   struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
+		InputTokens              int64 `json:"input_tokens"`
+		OutputTokens             int64 `json:"output_tokens"`
+		CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
 	} `json:"usage"`
 }
 
@@ -76,14 +80,23 @@ func extractAnthropicText(resp anthropicResponse) string {
 	return out
 }
 
-func callAnthropic(ctx context.Context, p anthropicCallParams) (string, error) {
+func anthropicUsage(resp anthropicResponse) cost.Usage {
+	return cost.Usage{
+		InputTokens:      resp.Usage.InputTokens,
+		OutputTokens:     resp.Usage.OutputTokens,
+		CacheReadTokens:  resp.Usage.CacheReadInputTokens,
+		CacheWriteTokens: resp.Usage.CacheCreationInputTokens,
+	}
+}
+
+func callAnthropic(ctx context.Context, p anthropicCallParams) (string, cost.Usage, error) {
 	body, err := json.Marshal(p.Request)
 	if err != nil {
-		return "", fmt.Errorf("marshal: %w", err)
+		return "", cost.Usage{}, fmt.Errorf("marshal: %w", err)
 	}
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.Endpoint, bytes.NewReader(body))
 	if err != nil {
-		return "", fmt.Errorf("new request: %w", err)
+		return "", cost.Usage{}, fmt.Errorf("new request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("anthropic-version", anthropicAPIVersion)
@@ -95,23 +108,23 @@ func callAnthropic(ctx context.Context, p anthropicCallParams) (string, error) {
 	}
 	resp, err := http.DefaultClient.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("http: %w", err)
+		return "", cost.Usage{}, fmt.Errorf("http: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("read body: %w", err)
+		return "", cost.Usage{}, fmt.Errorf("read body: %w", err)
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		snippet := string(respBody)
 		if len(snippet) > 512 {
 			snippet = snippet[:512]
 		}
-		return "", fmt.Errorf("%w: %d %s: %s", ErrHTTP, resp.StatusCode, resp.Status, snippet)
+		return "", cost.Usage{}, fmt.Errorf("%w: %d %s: %s", ErrHTTP, resp.StatusCode, resp.Status, snippet)
 	}
 	var parsed anthropicResponse
 	if err := json.Unmarshal(respBody, &parsed); err != nil {
-		return "", fmt.Errorf("decode response: %w (body=%s)", err, string(respBody))
+		return "", cost.Usage{}, fmt.Errorf("decode response: %w (body=%s)", err, string(respBody))
 	}
-	return extractAnthropicText(parsed), nil
+	return extractAnthropicText(parsed), anthropicUsage(parsed), nil
 }
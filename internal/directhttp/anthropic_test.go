@@ -68,7 +68,7 @@ func TestCallAnthropic_OAuthTokenUsesBearerAndBetaHeader(t *testing.T) {
 	}))
 	defer server.Close()
 
-	raw, err := callAnthropic(context.Background(), anthropicCallParams{
+	raw, _, err := callAnthropic(context.Background(), anthropicCallParams{
 		Endpoint: server.URL,
 		Token:    "sk-ant-oat-abc123",
 		Request:  buildAnthropicRequest("m", "p", "s", 0),
@@ -104,7 +104,7 @@ func TestCallAnthropic_APIKeyUsesXApiKeyNoBeta(t *testing.T) {
 	}))
 	defer server.Close()
 
-	_, err := callAnthropic(context.Background(), anthropicCallParams{
+	_, _, err := callAnthropic(context.Background(), anthropicCallParams{
 		Endpoint: server.URL,
 		Token:    "sk-ant-api03-notoauth",
 		Request:  buildAnthropicRequest("m", "p", "s", 0),
@@ -130,7 +130,7 @@ func TestCallAnthropic_Non2xxReturnsErrHTTP(t *testing.T) {
 	}))
 	defer server.Close()
 
-	_, err := callAnthropic(context.Background(), anthropicCallParams{
+	_, _, err := callAnthropic(context.Background(), anthropicCallParams{
 		Endpoint: server.URL,
 		Token:    "sk-ant-oat-x",
 		Request:  buildAnthropicRequest("m", "p", "s", 0),
@@ -153,7 +153,7 @@ func TestCallAnthropic_RequestBodyShape(t *testing.T) {
 	defer server.Close()
 
 	req := buildAnthropicRequest("claude-sonnet-4-6", "why is the sky blue?", "be terse", 1024)
-	_, _ = callAnthropic(context.Background(), anthropicCallParams{
+	_, _, _ = callAnthropic(context.Background(), anthropicCallParams{
 		Endpoint: server.URL,
 		Token:    "sk-ant-oat-x",
 		Request:  req,
@@ -165,3 +165,22 @@ func TestCallAnthropic_RequestBodyShape(t *testing.T) {
 		t.Errorf("messages: got %+v", gotBody.Messages)
 	}
 }
+
+func TestCallAnthropic_ReturnsUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"content":[{"type":"text","text":"{}"}],"usage":{"input_tokens":12,"output_tokens":5,"cache_read_input_tokens":3}}`)
+	}))
+	defer server.Close()
+
+	_, usage, err := callAnthropic(context.Background(), anthropicCallParams{
+		Endpoint: server.URL,
+		Token:    "sk-ant-api-key",
+		Request:  buildAnthropicRequest("m", "p", "s", 0),
+	})
+	if err != nil {
+		t.Fatalf("callAnthropic: %v", err)
+	}
+	if usage.InputTokens != 12 || usage.OutputTokens != 5 || usage.CacheReadTokens != 3 {
+		t.Errorf("usage = %+v", usage)
+	}
+}
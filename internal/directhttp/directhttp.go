@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/cost"
 	"github.com/sergeknystautas/schmux/internal/models"
 	"github.com/sergeknystautas/schmux/internal/oneshotdecode"
 	"github.com/sergeknystautas/schmux/internal/schema"
@@ -50,21 +51,21 @@ func ExecuteAPI[T any](
 	targetName, prompt, schemaLabel string,
 	timeout time.Duration,
 	dir string,
-) (T, error) {
+) (T, cost.Usage, error) {
 	var zero T
 	bareID, _ := StripAPISuffix(targetName)
 	if bareID == "" {
-		return zero, fmt.Errorf("%w: target %q has no model id before ::api", ErrModelNotFound, targetName)
+		return zero, cost.Usage{}, fmt.Errorf("%w: target %q has no model id before ::api", ErrModelNotFound, targetName)
 	}
 
 	schemaDoc, err := schema.Get(schemaLabel)
 	if err != nil {
-		return zero, fmt.Errorf("schema %q: %w", schemaLabel, err)
+		return zero, cost.Usage{}, fmt.Errorf("schema %q: %w", schemaLabel, err)
 	}
 
 	kind, modelMeta, err := classifyAPITarget(cfg, bareID)
 	if err != nil {
-		return zero, err
+		return zero, cost.Usage{}, err
 	}
 
 	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
@@ -75,28 +76,29 @@ func ExecuteAPI[T any](
 	case apiKindAnthropic:
 		token, err := config.GetAnthropicOAuthToken()
 		if err != nil {
-			return zero, err
+			return zero, cost.Usage{}, err
 		}
 		if token == "" {
-			return zero, ErrMissingToken
+			return zero, cost.Usage{}, ErrMissingToken
 		}
-		raw, err := callAnthropic(timeoutCtx, anthropicCallParams{
+		raw, usage, err := callAnthropic(timeoutCtx, anthropicCallParams{
 			Endpoint: anthropicEndpoint(),
 			Token:    token,
 			Request: buildAnthropicRequest(
 				bareID, prompt, buildAnthropicSystemPrompt(schemaDoc), 0),
 		})
 		if err != nil {
-			return zero, err
+			return zero, cost.Usage{}, err
 		}
-		return decodeAPIResponse[T](raw)
+		out, err := decodeAPIResponse[T](raw)
+		return out, usage, err
 
 	case apiKindThirdPartyClaudeHarness:
 		token, endpoint, err := thirdPartyAnthropicAuth(cfg, modelMeta)
 		if err != nil {
-			return zero, err
+			return zero, cost.Usage{}, err
 		}
-		raw, err := callAnthropic(timeoutCtx, anthropicCallParams{
+		raw, usage, err := callAnthropic(timeoutCtx, anthropicCallParams{
 			Endpoint: endpoint,
 			Token:    token,
 			Request: buildAnthropicRequest(
@@ -104,9 +106,10 @@ func ExecuteAPI[T any](
 				buildAnthropicSystemPrompt(schemaDoc), 0),
 		})
 		if err != nil {
-			return zero, err
+			return zero, cost.Usage{}, err
 		}
-		return decodeAPIResponse[T](raw)
+		out, err := decodeAPIResponse[T](raw)
+		return out, usage, err
 
 	case apiKindOllama:
 		endpoint := cfg.GetOllamaEndpoint()
@@ -114,18 +117,19 @@ func ExecuteAPI[T any](
 			endpoint = GetOllamaAutoDetectedEndpoint()
 		}
 		if endpoint == "" {
-			return zero, fmt.Errorf("%w: ollama endpoint not configured", ErrMissingToken)
+			return zero, cost.Usage{}, fmt.Errorf("%w: ollama endpoint not configured", ErrMissingToken)
 		}
-		raw, err := callOpenAI(timeoutCtx, openaiCallParams{
+		raw, usage, err := callOpenAI(timeoutCtx, openaiCallParams{
 			Endpoint: strings.TrimRight(endpoint, "/") + "/v1/chat/completions",
 			Request:  buildOpenAIRequest(bareID, prompt, buildAnthropicSystemPrompt(schemaDoc), 0),
 		})
 		if err != nil {
-			return zero, err
+			return zero, cost.Usage{}, err
 		}
-		return decodeAPIResponse[T](raw)
+		out, err := decodeAPIResponse[T](raw)
+		return out, usage, err
 	}
-	return zero, fmt.Errorf("unknown api kind: %v", kind)
+	return zero, cost.Usage{}, fmt.Errorf("unknown api kind: %v", kind)
 }
 
 func classifyAPITarget(cfg *config.Config, bareID string) (apiKind, classifyAPIModelMeta, error) {
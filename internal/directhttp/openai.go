@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/sergeknystautas/schmux/internal/cost"
 )
 
 type openaiRequest struct {
@@ -24,6 +26,10 @@ type openaiResponse struct {
 	Choices []struct {
 		Message openaiMessage `json:"message"`
 	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int64 `json:"prompt_tokens"`
+		CompletionTokens int64 `json:"completion_tokens"`
+	} `json:"usage"`
 }
 
 type openaiCallParams struct {
@@ -42,38 +48,39 @@ func buildOpenAIRequest(modelID, userPrompt, systemPrompt string, maxTokens int)
 	}
 }
 
-func callOpenAI(ctx context.Context, p openaiCallParams) (string, error) {
+func callOpenAI(ctx context.Context, p openaiCallParams) (string, cost.Usage, error) {
 	body, err := json.Marshal(p.Request)
 	if err != nil {
-		return "", fmt.Errorf("marshal: %w", err)
+		return "", cost.Usage{}, fmt.Errorf("marshal: %w", err)
 	}
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.Endpoint, bytes.NewReader(body))
 	if err != nil {
-		return "", fmt.Errorf("new request: %w", err)
+		return "", cost.Usage{}, fmt.Errorf("new request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 	resp, err := http.DefaultClient.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("http: %w", err)
+		return "", cost.Usage{}, fmt.Errorf("http: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("read body: %w", err)
+		return "", cost.Usage{}, fmt.Errorf("read body: %w", err)
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		snippet := string(respBody)
 		if len(snippet) > 512 {
 			snippet = snippet[:512]
 		}
-		return "", fmt.Errorf("%w: %d %s: %s", ErrHTTP, resp.StatusCode, resp.Status, snippet)
+		return "", cost.Usage{}, fmt.Errorf("%w: %d %s: %s", ErrHTTP, resp.StatusCode, resp.Status, snippet)
 	}
 	var parsed openaiResponse
 	if err := json.Unmarshal(respBody, &parsed); err != nil {
-		return "", fmt.Errorf("decode: %w (body=%s)", err, string(respBody))
+		return "", cost.Usage{}, fmt.Errorf("decode: %w (body=%s)", err, string(respBody))
 	}
 	if len(parsed.Choices) == 0 {
-		return "", fmt.Errorf("openai response has no choices (body=%s)", string(respBody))
+		return "", cost.Usage{}, fmt.Errorf("openai response has no choices (body=%s)", string(respBody))
 	}
-	return parsed.Choices[0].Message.Content, nil
+	usage := cost.Usage{InputTokens: parsed.Usage.PromptTokens, OutputTokens: parsed.Usage.CompletionTokens}
+	return parsed.Choices[0].Message.Content, usage, nil
 }
@@ -19,7 +19,7 @@ func TestCallOpenAI_HappyPath(t *testing.T) {
 	}))
 	defer server.Close()
 
-	raw, err := callOpenAI(context.Background(), openaiCallParams{
+	raw, _, err := callOpenAI(context.Background(), openaiCallParams{
 		Endpoint: server.URL,
 		Request:  buildOpenAIRequest("llama3.2:latest", "pick a branch", "be terse", 0),
 	})
@@ -42,7 +42,7 @@ func TestCallOpenAI_Non2xxReturnsErrHTTP(t *testing.T) {
 		w.WriteHeader(502)
 	}))
 	defer server.Close()
-	_, err := callOpenAI(context.Background(), openaiCallParams{Endpoint: server.URL, Request: buildOpenAIRequest("m", "p", "s", 0)})
+	_, _, err := callOpenAI(context.Background(), openaiCallParams{Endpoint: server.URL, Request: buildOpenAIRequest("m", "p", "s", 0)})
 	if !errors.Is(err, ErrHTTP) {
 		t.Fatalf("got %v", err)
 	}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// MessageHandler dispatches message events (an agent addressing another
+// session).
+type MessageHandler struct {
+	send func(sessionID string, ev MessageEvent)
+}
+
+// NewMessageHandler creates a handler that forwards messages with the
+// sending session's ID.
+func NewMessageHandler(send func(sessionID string, ev MessageEvent)) *MessageHandler {
+	return &MessageHandler{send: send}
+}
+
+func (h *MessageHandler) HandleEvent(ctx context.Context, sessionID string, raw RawEvent, data []byte) {
+	if raw.Type != "message" {
+		return
+	}
+	var ev MessageEvent
+	if err := json.Unmarshal(data, &ev); err != nil || ev.To == "" || ev.Text == "" {
+		return
+	}
+	h.send(sessionID, ev)
+}
@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMessageHandler(t *testing.T) {
+	var gotSession string
+	var got MessageEvent
+	h := NewMessageHandler(func(sessionID string, ev MessageEvent) {
+		gotSession, got = sessionID, ev
+	})
+
+	data := []byte(`{"ts":"2026-03-02T14:30:00Z","type":"message","to":"reviewer","text":"API is ready"}`)
+	raw, _ := ParseRawEvent(data)
+	h.HandleEvent(context.Background(), "s1", raw, data)
+	if gotSession != "s1" || got.To != "reviewer" || got.Text != "API is ready" {
+		t.Fatalf("got (%q,%+v)", gotSession, got)
+	}
+
+	// Wrong type, missing recipient and empty text are ignored.
+	gotSession = ""
+	for _, line := range []string{
+		`{"type":"status","state":"working"}`,
+		`{"type":"message","text":"hi"}`,
+		`{"type":"message","to":"reviewer","text":""}`,
+	} {
+		d := []byte(line)
+		r, _ := ParseRawEvent(d)
+		h.HandleEvent(context.Background(), "s1", r, d)
+	}
+	if gotSession != "" {
+		t.Fatalf("event should be ignored, got session %q", gotSession)
+	}
+}
@@ -60,3 +60,13 @@ type FrictionEvent struct {
 	Type string `json:"type"`
 	Text string `json:"text"`
 }
+
+// MessageEvent is a message from one agent to another. To names the
+// recipient: a session ID, a session nickname, or a group (@workspace,
+// @repo, @all).
+type MessageEvent struct {
+	Ts   string `json:"ts"`
+	Type string `json:"type"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
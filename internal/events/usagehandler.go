@@ -0,0 +1,30 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// UsageHandler dispatches usage events (a transcript whose token usage should
+// be accounted).
+type UsageHandler struct {
+	record func(sessionID, transcript string)
+}
+
+// NewUsageHandler creates a handler that forwards reported transcripts.
+func NewUsageHandler(record func(sessionID, transcript string)) *UsageHandler {
+	return &UsageHandler{record: record}
+}
+
+func (h *UsageHandler) HandleEvent(ctx context.Context, sessionID string, raw RawEvent, data []byte) {
+	if raw.Type != "usage" {
+		return
+	}
+	var ev struct {
+		Transcript string `json:"transcript"`
+	}
+	if err := json.Unmarshal(data, &ev); err != nil || ev.Transcript == "" {
+		return
+	}
+	h.record(sessionID, ev.Transcript)
+}
@@ -0,0 +1,31 @@
+package events
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUsageHandler(t *testing.T) {
+	var gotSession, gotTranscript string
+	h := NewUsageHandler(func(sessionID, transcript string) {
+		gotSession, gotTranscript = sessionID, transcript
+	})
+
+	data := []byte(`{"type":"usage","transcript":"/tmp/t.jsonl"}`)
+	raw, _ := ParseRawEvent(data)
+	h.HandleEvent(context.Background(), "s1", raw, data)
+	if gotSession != "s1" || gotTranscript != "/tmp/t.jsonl" {
+		t.Fatalf("got (%q,%q), want (s1,/tmp/t.jsonl)", gotSession, gotTranscript)
+	}
+
+	// Wrong type and empty transcript are ignored.
+	gotSession = ""
+	for _, line := range []string{`{"type":"resume_id","id":"x"}`, `{"type":"usage","transcript":""}`} {
+		d := []byte(line)
+		r, _ := ParseRawEvent(d)
+		h.HandleEvent(context.Background(), "s1", r, d)
+	}
+	if gotSession != "" {
+		t.Fatalf("event should be ignored, got session %q", gotSession)
+	}
+}
@@ -475,6 +475,8 @@ func (m *Manager) buildFMCommand(ctx context.Context, prompt string) (string, er
 		}
 	}
 
+	baseCommand = m.appendMCPFlags(baseCommand, resolved.ToolName)
+
 	// FM gets minimal env: just SCHMUX_ENABLED and SCHMUX_SESSION_ID
 	env := mergeEnv(resolved.Env, map[string]string{
 		"SCHMUX_ENABLED":    "1",
@@ -489,6 +491,19 @@ func (m *Manager) buildFMCommand(ctx context.Context, prompt string) (string, er
 	return fmt.Sprintf("%s %s", buildEnvPrefix(env), baseCommand), nil
 }
 
+// appendMCPFlags gives the FM the unscoped schmux MCP server when its tool
+// takes MCP servers on the command line.
+func (m *Manager) appendMCPFlags(cmd, toolName string) string {
+	adapter := detect.GetAdapter(toolName)
+	if adapter == nil {
+		return cmd
+	}
+	for _, arg := range adapter.MCPArgs(m.schmuxBin, "") {
+		cmd = fmt.Sprintf("%s %s", cmd, shellutil.Quote(arg))
+	}
+	return cmd
+}
+
 // buildFMResumeCommand constructs the resume command for the floor manager.
 func (m *Manager) buildFMResumeCommand(ctx context.Context) (string, error) {
 	resolved, err := m.resolveTarget(ctx)
@@ -510,7 +525,7 @@ func (m *Manager) buildFMResumeCommand(ctx context.Context) (string, error) {
 		return "", fmt.Errorf("resume not supported for target: %w", err)
 	}
 
-	cmd := joinParts(parts)
+	cmd := m.appendMCPFlags(joinParts(parts), toolName)
 
 	env := mergeEnv(resolved.Env, map[string]string{
 		"SCHMUX_ENABLED":    "1",
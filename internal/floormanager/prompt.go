@@ -33,6 +33,8 @@ You are the floor manager for this schmux instance. You orchestrate work across
 - %[1]s branches — bird's-eye view of all workspaces with VCS state and session states
 - %[1]s repofeed [--repo <slug>] [--json] — see what other developers are working on across repos
 
+When the schmux MCP server is connected (tools named mcp__schmux__*), prefer its tools — list_sessions, spawn, tell, capture, read_events, inspect_workspace, get_diff — over the equivalent commands.
+
 ## Signal Handling
 
 You will receive [SIGNAL] messages injected into your terminal by the schmux daemon. Format:
@@ -88,6 +90,8 @@ func GenerateSettings(schmuxBin string) string {
 				fmt.Sprintf("Bash(%s inspect*)", schmuxBin),
 				fmt.Sprintf("Bash(%s branches*)", schmuxBin),
 				fmt.Sprintf("Bash(%s repofeed*)", schmuxBin),
+				// schmux MCP tools (the same operations, typed)
+				"mcp__schmux",
 				"Bash(cat memory.md)",
 				"Bash(echo * > memory.md)",
 				"Bash(printf * > memory.md)",
@@ -0,0 +1,170 @@
+// Package mcp implements a Model Context Protocol server over stdio that
+// exposes schmux orchestration (sessions, spawn, tell, capture, workspace
+// inspection) as typed tools. Requests are forwarded to the daemon's HTTP
+// API; `schmux mcp` runs it.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ProtocolVersion is the newest MCP revision the server speaks. Clients that
+// ask for another revision are answered with this one, as the spec requires.
+const ProtocolVersion = "2025-03-26"
+
+// supportedVersions are the revisions echoed back when a client requests them.
+var supportedVersions = map[string]bool{"2024-11-05": true, "2025-03-26": true, "2025-06-18": true}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// maxLineBytes bounds a single JSON-RPC message.
+const maxLineBytes = 16 << 20
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server serves MCP over newline-delimited JSON-RPC.
+type Server struct {
+	name    string
+	version string
+	tools   *Tools
+
+	mu  sync.Mutex // serializes writes
+	out io.Writer
+}
+
+// NewServer creates a server exposing tools. name and version are reported
+// to the client in serverInfo.
+func NewServer(name, version string, tools *Tools) *Server {
+	return &Server{name: name, version: version, tools: tools}
+}
+
+// Serve reads requests from in and writes responses to out until in is
+// exhausted or ctx is canceled. Requests are handled one at a time.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = out
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		s.handleLine(ctx, line)
+	}
+	return scanner.Err()
+}
+
+func (s *Server) handleLine(ctx context.Context, line []byte) {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		s.write(response{ID: json.RawMessage("null"), Error: &rpcError{codeParseError, "parse error"}})
+		return
+	}
+	// Notifications (no id) never get a response.
+	if len(req.ID) == 0 {
+		return
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		s.write(response{ID: req.ID, Error: &rpcError{codeInvalidRequest, "invalid request"}})
+		return
+	}
+	result, rerr := s.dispatch(ctx, req)
+	if rerr != nil {
+		s.write(response{ID: req.ID, Error: rerr})
+		return
+	}
+	s.write(response{ID: req.ID, Result: result})
+}
+
+func (s *Server) dispatch(ctx context.Context, req request) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &p)
+		version := ProtocolVersion
+		if supportedVersions[p.ProtocolVersion] {
+			version = p.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]string{"name": s.name, "version": s.version},
+			"instructions":    s.tools.Instructions(),
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": s.tools.List()}, nil
+	case "tools/call":
+		var p struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &p); err != nil || p.Name == "" {
+			return nil, &rpcError{codeInvalidParams, "tools/call requires a tool name"}
+		}
+		text, err := s.tools.Call(ctx, p.Name, p.Arguments)
+		if errors.Is(err, ErrUnknownTool) {
+			return nil, &rpcError{codeInvalidParams, err.Error()}
+		}
+		// Tool failures are results, not protocol errors, so the model sees them.
+		if err != nil {
+			return toolResult(err.Error(), true), nil
+		}
+		return toolResult(text, false), nil
+	default:
+		return nil, &rpcError{codeMethodNotFound, fmt.Sprintf("method not found: %s", req.Method)}
+	}
+}
+
+func toolResult(text string, isError bool) map[string]any {
+	return map[string]any{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+func (s *Server) write(resp response) {
+	resp.JSONRPC = "2.0"
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out.Write(append(data, '\n'))
+}
@@ -0,0 +1,197 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/pkg/cli"
+)
+
+type fakeClient struct {
+	baseURL   string
+	sessions  []cli.WorkspaceWithSessions
+	lastSpawn *cli.SpawnRequest
+}
+
+func (f *fakeClient) IsRunning() bool { return true }
+func (f *fakeClient) GetConfig() (*cli.Config, error) {
+	return &cli.Config{Repos: []cli.Repo{{Name: "app", URL: "https://github.com/test/app"}}}, nil
+}
+func (f *fakeClient) GetWorkspaces() ([]cli.Workspace, error)                 { return nil, nil }
+func (f *fakeClient) GetSessions() ([]cli.WorkspaceWithSessions, error)       { return f.sessions, nil }
+func (f *fakeClient) DisposeSession(context.Context, string) error            { return nil }
+func (f *fakeClient) ScanWorkspaces(context.Context) (*cli.ScanResult, error) { return nil, nil }
+func (f *fakeClient) RefreshOverlay(context.Context, string) error            { return nil }
+func (f *fakeClient) BaseURL() string                                         { return f.baseURL }
+func (f *fakeClient) Spawn(_ context.Context, req cli.SpawnRequest) ([]cli.SpawnResult, error) {
+	f.lastSpawn = &req
+	return []cli.SpawnResult{{SessionID: "ws-1-new", WorkspaceID: req.WorkspaceID, Target: "claude"}}, nil
+}
+
+func newFakeClient(t *testing.T) *fakeClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/sessions/ws-1-a/capture":
+			io.WriteString(w, `{"session_id":"ws-1-a","lines":50,"output":"$ make test\nok"}`)
+		case "/api/sessions/ws-1-a/tell":
+			io.WriteString(w, `{"status":"ok"}`)
+		case "/api/workspaces/ws-1/inspect":
+			io.WriteString(w, `{"workspace_id":"ws-1"}`)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return &fakeClient{
+		baseURL: srv.URL,
+		sessions: []cli.WorkspaceWithSessions{
+			{ID: "ws-1", Sessions: []cli.Session{{ID: "ws-1-a", WorkspaceID: "ws-1"}}},
+			{ID: "ws-2", Sessions: []cli.Session{{ID: "ws-2-a", WorkspaceID: "ws-2"}}},
+		},
+	}
+}
+
+// exchange sends each request line to a fresh server and returns the
+// decoded responses.
+func exchange(t *testing.T, tools *Tools, lines ...string) []map[string]any {
+	t.Helper()
+	var out strings.Builder
+	s := NewServer("schmux", "test", tools)
+	if err := s.Serve(context.Background(), strings.NewReader(strings.Join(lines, "\n")+"\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	var resps []map[string]any
+	sc := bufio.NewScanner(strings.NewReader(out.String()))
+	for sc.Scan() {
+		var m map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			t.Fatalf("bad response %q: %v", sc.Text(), err)
+		}
+		resps = append(resps, m)
+	}
+	return resps
+}
+
+func call(name, args string) string {
+	return `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"` + name + `","arguments":` + args + `}}`
+}
+
+// toolText returns a tools/call result's text and isError flag.
+func toolText(t *testing.T, resp map[string]any) (string, bool) {
+	t.Helper()
+	result, ok := resp["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("no result in %v", resp)
+	}
+	content := result["content"].([]any)
+	return content[0].(map[string]any)["text"].(string), result["isError"].(bool)
+}
+
+func TestServer_Protocol(t *testing.T) {
+	tools := NewTools(newFakeClient(t), "")
+	resps := exchange(t, tools,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+		`not json`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"nope"}}`,
+	)
+	if len(resps) != 5 {
+		t.Fatalf("got %d responses, want 5 (notifications are not answered): %v", len(resps), resps)
+	}
+
+	init := resps[0]["result"].(map[string]any)
+	if init["protocolVersion"] != "2024-11-05" {
+		t.Errorf("protocolVersion = %v, want the client's", init["protocolVersion"])
+	}
+
+	list := resps[1]["result"].(map[string]any)["tools"].([]any)
+	names := map[string]bool{}
+	for _, tool := range list {
+		names[tool.(map[string]any)["name"].(string)] = true
+	}
+	for _, want := range []string{"list_sessions", "spawn", "tell", "capture", "read_events", "inspect_workspace", "get_diff"} {
+		if !names[want] {
+			t.Errorf("tools/list missing %s", want)
+		}
+	}
+
+	for i, code := range map[int]float64{2: codeMethodNotFound, 3: codeParseError, 4: codeInvalidParams} {
+		rerr, ok := resps[i]["error"].(map[string]any)
+		if !ok || rerr["code"] != code {
+			t.Errorf("response %d = %v, want error %v", i, resps[i], code)
+		}
+	}
+}
+
+func TestTools_Scoped(t *testing.T) {
+	client := newFakeClient(t)
+	tools := NewTools(client, "ws-1")
+
+	text, isErr := toolText(t, exchange(t, tools, call("list_sessions", `{}`))[0])
+	if isErr || !strings.Contains(text, "ws-1-a") || strings.Contains(text, "ws-2") {
+		t.Errorf("list_sessions = %q, want only ws-1", text)
+	}
+
+	text, isErr = toolText(t, exchange(t, tools, call("capture", `{"session_id":"ws-1-a"}`))[0])
+	if isErr || text != "$ make test\nok" {
+		t.Errorf("capture = %q (error %v)", text, isErr)
+	}
+
+	text, isErr = toolText(t, exchange(t, tools, call("inspect_workspace", `{}`))[0])
+	if isErr || !strings.Contains(text, `"ws-1"`) {
+		t.Errorf("inspect_workspace defaulted = %q (error %v)", text, isErr)
+	}
+
+	for _, c := range []struct{ name, args string }{
+		{"tell", `{"session_id":"ws-2-a","message":"hi"}`},
+		{"capture", `{"session_id":"ws-2-a"}`},
+		{"read_events", `{"session_id":"ws-2-a"}`},
+		{"inspect_workspace", `{"workspace_id":"ws-2"}`},
+		{"get_diff", `{"workspace_id":"ws-2"}`},
+		{"spawn", `{"target":"claude","repo":"app"}`},
+	} {
+		if text, isErr := toolText(t, exchange(t, tools, call(c.name, c.args))[0]); !isErr {
+			t.Errorf("%s %s outside scope succeeded: %q", c.name, c.args, text)
+		}
+	}
+
+	if _, isErr := toolText(t, exchange(t, tools, call("spawn", `{"target":"claude","prompt":"review"}`))[0]); isErr {
+		t.Fatal("scoped spawn failed")
+	}
+	if client.lastSpawn.WorkspaceID != "ws-1" || client.lastSpawn.Targets["claude"] != 1 {
+		t.Errorf("spawn request = %+v, want workspace ws-1", client.lastSpawn)
+	}
+}
+
+func TestTools_Unscoped(t *testing.T) {
+	client := newFakeClient(t)
+	tools := NewTools(client, "")
+
+	text, _ := toolText(t, exchange(t, tools, call("list_sessions", `{}`))[0])
+	if !strings.Contains(text, "ws-2-a") {
+		t.Errorf("list_sessions = %q, want every workspace", text)
+	}
+
+	if _, isErr := toolText(t, exchange(t, tools, call("spawn", `{"target":"claude","repo":"app","branch":"feat"}`))[0]); isErr {
+		t.Fatal("spawn failed")
+	}
+	if client.lastSpawn.Repo != "https://github.com/test/app" || client.lastSpawn.Branch != "feat" {
+		t.Errorf("spawn request = %+v, want repo URL resolved from config", client.lastSpawn)
+	}
+
+	if _, isErr := toolText(t, exchange(t, tools, call("inspect_workspace", `{}`))[0]); !isErr {
+		t.Error("inspect_workspace without workspace_id should fail when unscoped")
+	}
+	if text, isErr := toolText(t, exchange(t, tools, call("tell", `{"session_id":"ws-1-a","message":"hi"}`))[0]); isErr {
+		t.Errorf("tell = %q", text)
+	}
+}
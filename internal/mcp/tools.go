@@ -0,0 +1,390 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/pkg/cli"
+)
+
+// ErrUnknownTool is returned by Call for a tool name that is not registered.
+var ErrUnknownTool = errors.New("unknown tool")
+
+// Tool is the MCP descriptor of one tool.
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+type toolHandler func(ctx context.Context, args json.RawMessage) (string, error)
+
+type toolEntry struct {
+	Tool
+	handler toolHandler
+}
+
+// Tools is the set of schmux tools, optionally scoped to one workspace.
+type Tools struct {
+	client      cli.DaemonClient
+	workspaceID string
+	httpClient  *http.Client
+	entries     []toolEntry
+}
+
+// NewTools creates the tool set. A non-empty workspaceID scopes every tool
+// to that workspace: only its sessions are listed or addressable, spawns
+// land in it, and other workspaces cannot be inspected.
+func NewTools(client cli.DaemonClient, workspaceID string) *Tools {
+	t := &Tools{
+		client:      client,
+		workspaceID: workspaceID,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}
+	t.entries = []toolEntry{
+		{Tool{"list_sessions", "List workspaces and their agent sessions with status.", schema(nil)}, t.listSessions},
+		{Tool{"spawn", "Spawn a new agent session. In a scoped server the session runs in this workspace.", schema(map[string]prop{
+			"target":       {"string", "Run target or model to spawn (e.g. claude)", true},
+			"prompt":       {"string", "Initial prompt for the agent", false},
+			"nickname":     {"string", "Session nickname", false},
+			"workspace_id": {"string", "Existing workspace to spawn into", false},
+			"repo":         {"string", "Repo name or URL for a new workspace", false},
+			"branch":       {"string", "Branch for a new workspace", false},
+		})}, t.spawn},
+		{Tool{"tell", "Type a message into a session's agent (prefixed with [from FM]).", schema(map[string]prop{
+			"session_id": {"string", "Session to message", true},
+			"message":    {"string", "Message text", true},
+		})}, t.tell},
+		{Tool{"capture", "Read the recent terminal output of a session.", schema(map[string]prop{
+			"session_id": {"string", "Session to capture", true},
+			"lines":      {"integer", "Number of lines (default 50)", false},
+		})}, t.capture},
+		{Tool{"read_events", "Read a session's event history (status changes, failures, reflections, messages).", schema(map[string]prop{
+			"session_id": {"string", "Session whose events to read", true},
+			"type":       {"string", "Only events of this type (status, failure, reflection, friction, message)", false},
+			"last":       {"integer", "Only the last N events", false},
+		})}, t.readEvents},
+		{Tool{"inspect_workspace", "VCS state of a workspace: branch, ahead/behind, commits and uncommitted changes.", schema(map[string]prop{
+			"workspace_id": {"string", "Workspace to inspect (default: this workspace)", false},
+		})}, t.inspectWorkspace},
+		{Tool{"get_diff", "List the files a workspace changed against its base, with line counts.", schema(map[string]prop{
+			"workspace_id": {"string", "Workspace to diff (default: this workspace)", false},
+		})}, t.getDiff},
+	}
+	return t
+}
+
+// List returns the tool descriptors.
+func (t *Tools) List() []Tool {
+	out := make([]Tool, len(t.entries))
+	for i, e := range t.entries {
+		out[i] = e.Tool
+	}
+	return out
+}
+
+// Instructions describes the server to the client at initialization.
+func (t *Tools) Instructions() string {
+	if t.workspaceID == "" {
+		return "schmux orchestration tools. Every workspace and session is visible."
+	}
+	return fmt.Sprintf("schmux orchestration tools, scoped to workspace %s: only its sessions are visible and spawns run in it.", t.workspaceID)
+}
+
+// Call runs the named tool and returns its text result.
+func (t *Tools) Call(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	for _, e := range t.entries {
+		if e.Name == name {
+			if len(args) == 0 || string(args) == "null" {
+				args = json.RawMessage("{}")
+			}
+			return e.handler(ctx, args)
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownTool, name)
+}
+
+type prop struct {
+	typ         string
+	description string
+	required    bool
+}
+
+func schema(props map[string]prop) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	for name, p := range props {
+		properties[name] = map[string]string{"type": p.typ, "description": p.description}
+		if p.required {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(required)
+	return map[string]any{"type": "object", "properties": properties, "required": required}
+}
+
+func decodeArgs(args json.RawMessage, v any) error {
+	if err := json.Unmarshal(args, v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+func (t *Tools) listSessions(ctx context.Context, args json.RawMessage) (string, error) {
+	workspaces, err := t.client.GetSessions()
+	if err != nil {
+		return "", err
+	}
+	if t.workspaceID != "" {
+		scoped := []cli.WorkspaceWithSessions{}
+		for _, ws := range workspaces {
+			if ws.ID == t.workspaceID {
+				scoped = append(scoped, ws)
+			}
+		}
+		workspaces = scoped
+	}
+	return marshal(workspaces)
+}
+
+func (t *Tools) spawn(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		Target      string `json:"target"`
+		Prompt      string `json:"prompt"`
+		Nickname    string `json:"nickname"`
+		WorkspaceID string `json:"workspace_id"`
+		Repo        string `json:"repo"`
+		Branch      string `json:"branch"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return "", err
+	}
+	if a.Target == "" {
+		return "", errors.New("target is required")
+	}
+	req := cli.SpawnRequest{
+		Prompt:      a.Prompt,
+		Nickname:    a.Nickname,
+		WorkspaceID: a.WorkspaceID,
+		Branch:      a.Branch,
+		Targets:     map[string]int{a.Target: 1},
+	}
+	if t.workspaceID != "" {
+		if a.Repo != "" || (a.WorkspaceID != "" && a.WorkspaceID != t.workspaceID) {
+			return "", fmt.Errorf("this server is scoped to workspace %s", t.workspaceID)
+		}
+		req.WorkspaceID = t.workspaceID
+		req.Branch = ""
+	} else if req.WorkspaceID == "" {
+		if a.Repo == "" {
+			return "", errors.New("workspace_id or repo is required")
+		}
+		repoURL, err := t.resolveRepo(a.Repo)
+		if err != nil {
+			return "", err
+		}
+		req.Repo = repoURL
+	}
+
+	results, err := t.client.Spawn(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			return "", fmt.Errorf("spawn %s failed: %s", r.Target, r.Error)
+		}
+	}
+	return marshal(results)
+}
+
+// resolveRepo maps a configured repo, by name or URL, to its URL.
+func (t *Tools) resolveRepo(repo string) (string, error) {
+	cfg, err := t.client.GetConfig()
+	if err != nil {
+		return "", err
+	}
+	for _, r := range cfg.Repos {
+		if r.Name == repo || r.URL == repo {
+			return r.URL, nil
+		}
+	}
+	return "", fmt.Errorf("repo not found in config: %s", repo)
+}
+
+func (t *Tools) tell(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		SessionID string `json:"session_id"`
+		Message   string `json:"message"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return "", err
+	}
+	if a.Message == "" {
+		return "", errors.New("message is required")
+	}
+	if err := t.checkSession(a.SessionID); err != nil {
+		return "", err
+	}
+	body, _ := json.Marshal(map[string]string{"message": a.Message})
+	if _, err := t.do(ctx, http.MethodPost, "/api/sessions/"+url.PathEscape(a.SessionID)+"/tell", body); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Message sent to session %s.", a.SessionID), nil
+}
+
+func (t *Tools) capture(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		SessionID string `json:"session_id"`
+		Lines     int    `json:"lines"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return "", err
+	}
+	if err := t.checkSession(a.SessionID); err != nil {
+		return "", err
+	}
+	path := "/api/sessions/" + url.PathEscape(a.SessionID) + "/capture"
+	if a.Lines > 0 {
+		path += "?lines=" + strconv.Itoa(a.Lines)
+	}
+	body, err := t.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Output string `json:"output"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	return resp.Output, nil
+}
+
+func (t *Tools) readEvents(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		SessionID string `json:"session_id"`
+		Type      string `json:"type"`
+		Last      int    `json:"last"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return "", err
+	}
+	if err := t.checkSession(a.SessionID); err != nil {
+		return "", err
+	}
+	params := url.Values{}
+	if a.Type != "" {
+		params.Set("type", a.Type)
+	}
+	if a.Last > 0 {
+		params.Set("last", strconv.Itoa(a.Last))
+	}
+	path := "/api/sessions/" + url.PathEscape(a.SessionID) + "/events"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	body, err := t.do(ctx, http.MethodGet, path, nil)
+	return string(body), err
+}
+
+func (t *Tools) inspectWorkspace(ctx context.Context, args json.RawMessage) (string, error) {
+	workspaceID, err := t.workspaceArg(args)
+	if err != nil {
+		return "", err
+	}
+	body, err := t.do(ctx, http.MethodGet, "/api/workspaces/"+url.PathEscape(workspaceID)+"/inspect", nil)
+	return string(body), err
+}
+
+func (t *Tools) getDiff(ctx context.Context, args json.RawMessage) (string, error) {
+	workspaceID, err := t.workspaceArg(args)
+	if err != nil {
+		return "", err
+	}
+	body, err := t.do(ctx, http.MethodGet, "/api/diff/"+url.PathEscape(workspaceID), nil)
+	return string(body), err
+}
+
+// workspaceArg returns the workspace_id argument, defaulting to and
+// restricted to the scoped workspace.
+func (t *Tools) workspaceArg(args json.RawMessage) (string, error) {
+	var a struct {
+		WorkspaceID string `json:"workspace_id"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return "", err
+	}
+	switch {
+	case t.workspaceID != "" && a.WorkspaceID == "":
+		return t.workspaceID, nil
+	case t.workspaceID != "" && a.WorkspaceID != t.workspaceID:
+		return "", fmt.Errorf("workspace %s is outside this server's scope", a.WorkspaceID)
+	case a.WorkspaceID == "":
+		return "", errors.New("workspace_id is required")
+	}
+	return a.WorkspaceID, nil
+}
+
+// checkSession validates a session_id argument against the scope.
+func (t *Tools) checkSession(sessionID string) error {
+	if sessionID == "" {
+		return errors.New("session_id is required")
+	}
+	if t.workspaceID == "" {
+		return nil
+	}
+	workspaces, err := t.client.GetSessions()
+	if err != nil {
+		return err
+	}
+	for _, ws := range workspaces {
+		for _, sess := range ws.Sessions {
+			if sess.ID == sessionID {
+				if ws.ID != t.workspaceID {
+					break
+				}
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("session %s is not in workspace %s", sessionID, t.workspaceID)
+}
+
+func (t *Tools) do(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, t.client.BaseURL()+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
+
+func marshal(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
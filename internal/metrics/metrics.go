@@ -0,0 +1,187 @@
+// Package metrics renders daemon metrics in the Prometheus text exposition
+// format. It has no registry: the dashboard gathers values from the
+// subsystems that already track them on every scrape and writes them out as
+// families. Histogram is the one stateful collector, for values no
+// subsystem aggregates.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the Content-Type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Type is a metric family type.
+type Type string
+
+const (
+	TypeCounter   Type = "counter"
+	TypeGauge     Type = "gauge"
+	TypeSummary   Type = "summary"
+	TypeHistogram Type = "histogram"
+)
+
+// Labels are a sample's label pairs. Rendered sorted by name.
+type Labels map[string]string
+
+// Sample is one line of a family. Suffix is appended to the family name,
+// e.g. "_bucket" or "_sum".
+type Sample struct {
+	Suffix string
+	Labels Labels
+	Value  float64
+}
+
+// Family is a named group of samples sharing a type and help text.
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Samples []Sample
+}
+
+// Add appends a sample with no suffix.
+func (f *Family) Add(value float64, labels Labels) {
+	f.Samples = append(f.Samples, Sample{Labels: labels, Value: value})
+}
+
+// AddHistogram appends the _bucket, _sum and _count samples of h.
+func (f *Family) AddHistogram(h HistogramSnapshot, labels Labels) {
+	for i, bound := range h.Bounds {
+		f.Samples = append(f.Samples, Sample{Suffix: "_bucket", Labels: withLabel(labels, "le", formatFloat(bound)), Value: float64(h.Cumulative[i])})
+	}
+	f.Samples = append(f.Samples,
+		Sample{Suffix: "_bucket", Labels: withLabel(labels, "le", "+Inf"), Value: float64(h.Count)},
+		Sample{Suffix: "_sum", Labels: labels, Value: h.Sum},
+		Sample{Suffix: "_count", Labels: labels, Value: float64(h.Count)},
+	)
+}
+
+func withLabel(labels Labels, name, value string) Labels {
+	out := make(Labels, len(labels)+1)
+	for k, v := range labels {
+		out[k] = v
+	}
+	out[name] = value
+	return out
+}
+
+// Write renders families in order. Families without samples are skipped.
+func Write(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		if len(f.Samples) == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.Name, escapeHelp(f.Help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.Name, f.Type)
+		for _, s := range f.Samples {
+			bw.WriteString(f.Name)
+			bw.WriteString(s.Suffix)
+			writeLabels(bw, s.Labels)
+			bw.WriteByte(' ')
+			bw.WriteString(formatFloat(s.Value))
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+func writeLabels(bw *bufio.Writer, labels Labels) {
+	if len(labels) == 0 {
+		return
+	}
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	bw.WriteByte('{')
+	for i, k := range names {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString(k)
+		bw.WriteString(`="`)
+		bw.WriteString(escapeLabelValue(labels[k]))
+		bw.WriteByte('"')
+	}
+	bw.WriteByte('}')
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpEscaper.Replace(s) }
+func escapeLabelValue(s string) string { return labelEscaper.Replace(s) }
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// DefaultLatencyBuckets are upper bounds in seconds suited to LLM calls.
+var DefaultLatencyBuckets = []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300}
+
+// Histogram counts observations into fixed buckets. Safe for concurrent use.
+type Histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []uint64 // per bucket, not cumulative; last is +Inf
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram returns a histogram with the given ascending upper bounds.
+func NewHistogram(bounds []float64) *Histogram {
+	return &Histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+// Observe records one value.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	h.mu.Lock()
+	h.counts[i]++
+	h.sum += v
+	h.count++
+	h.mu.Unlock()
+}
+
+// HistogramSnapshot is a point-in-time copy of a Histogram with cumulative
+// bucket counts, as the exposition format expects.
+type HistogramSnapshot struct {
+	Bounds     []float64
+	Cumulative []uint64
+	Sum        float64
+	Count      uint64
+}
+
+// Snapshot returns the current state of h.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	snap := HistogramSnapshot{Bounds: h.bounds, Cumulative: make([]uint64, len(h.bounds)), Sum: h.sum, Count: h.count}
+	var running uint64
+	for i := range h.bounds {
+		running += h.counts[i]
+		snap.Cumulative[i] = running
+	}
+	return snap
+}
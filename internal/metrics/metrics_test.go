@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	sessions := Family{Name: "schmux_sessions", Help: "Sessions by status.", Type: TypeGauge}
+	sessions.Add(2, Labels{"status": "running", "target": "claude"})
+	sessions.Add(1, Labels{"target": `a"b\c`, "status": "stopped"})
+	empty := Family{Name: "schmux_empty", Help: "Never rendered.", Type: TypeCounter}
+	info := Family{Name: "schmux_build_info", Help: "Build info\nsecond line.", Type: TypeGauge}
+	info.Add(1, nil)
+
+	var b strings.Builder
+	if err := Write(&b, []Family{sessions, empty, info}); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP schmux_sessions Sessions by status.
+# TYPE schmux_sessions gauge
+schmux_sessions{status="running",target="claude"} 2
+schmux_sessions{status="stopped",target="a\"b\\c"} 1
+# HELP schmux_build_info Build info\nsecond line.
+# TYPE schmux_build_info gauge
+schmux_build_info 1
+`
+	if b.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram([]float64{1, 5})
+	for _, v := range []float64{0.5, 1, 3, 10} {
+		h.Observe(v)
+	}
+	f := Family{Name: "d", Help: "h", Type: TypeHistogram}
+	f.AddHistogram(h.Snapshot(), Labels{"type": "x"})
+
+	var b strings.Builder
+	if err := Write(&b, []Family{f}); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP d h
+# TYPE d histogram
+d_bucket{le="1",type="x"} 2
+d_bucket{le="5",type="x"} 3
+d_bucket{le="+Inf",type="x"} 4
+d_sum{type="x"} 14.5
+d_count{type="x"} 4
+`
+	if b.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
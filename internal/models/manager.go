@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -16,6 +17,7 @@ import (
 	"github.com/charmbracelet/log"
 	"github.com/sergeknystautas/schmux/internal/api/contracts"
 	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/cost"
 	"github.com/sergeknystautas/schmux/internal/detect"
 )
 
@@ -137,6 +139,29 @@ func (m *Manager) GetRegistryMeta(modelID string) RegistryModel {
 	return RegistryModel{}
 }
 
+// datedModelSuffix matches the release date harnesses and APIs append to
+// model IDs, e.g. "claude-sonnet-4-5-20250929".
+var datedModelSuffix = regexp.MustCompile(`-\d{8}$`)
+
+// PriceFor returns the registry price of a model named as a harness or API
+// reports it, as a catalog ID, or as a legacy alias. ok is false when the
+// registry has no cost for it.
+func (m *Manager) PriceFor(model string) (cost.Price, bool) {
+	candidates := []string{model, datedModelSuffix.ReplaceAllString(model, "")}
+	if found, ok := m.FindModel(model); ok {
+		candidates = append(candidates, found.ID)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, id := range candidates {
+		meta, ok := m.registryMeta[id]
+		if ok && (meta.CostInput > 0 || meta.CostOutput > 0) {
+			return cost.Price{Input: meta.CostInput, Output: meta.CostOutput, CacheRead: meta.CostCacheRead, CacheWrite: meta.CostCacheWrite}, true
+		}
+	}
+	return cost.Price{}, false
+}
+
 // SetOnCatalogUpdated sets the callback for catalog updates.
 func (m *Manager) SetOnCatalogUpdated(callback func()) {
 	m.mu.Lock()
@@ -390,3 +390,23 @@ func TestMergeEnvMaps(t *testing.T) {
 		})
 	}
 }
+
+func TestPriceFor(t *testing.T) {
+	mm := New(&config.Config{}, []detect.Tool{{Name: "claude", Command: "claude"}}, "", testLogger)
+	mm.SetRegistryMeta(map[string]RegistryModel{
+		"claude-sonnet-4-5": {ID: "claude-sonnet-4-5", CostInput: 3, CostOutput: 15, CostCacheRead: 0.3},
+		"free-model":        {ID: "free-model"},
+	})
+
+	for _, model := range []string{"claude-sonnet-4-5", "claude-sonnet-4-5-20250929"} {
+		p, ok := mm.PriceFor(model)
+		if !ok || p.Input != 3 || p.Output != 15 || p.CacheRead != 0.3 {
+			t.Errorf("PriceFor(%q) = %+v, %v", model, p, ok)
+		}
+	}
+	for _, model := range []string{"free-model", "unknown"} {
+		if _, ok := mm.PriceFor(model); ok {
+			t.Errorf("PriceFor(%q) ok, want no price", model)
+		}
+	}
+}
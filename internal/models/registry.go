@@ -22,15 +22,17 @@ const (
 
 // RegistryModel is a model parsed from models.dev with bonus metadata.
 type RegistryModel struct {
-	ID            string // models.dev model ID
-	DisplayName   string
-	Provider      string // models.dev provider key (e.g., "kimi-for-coding")
-	ContextWindow int
-	MaxOutput     int
-	CostInput     float64 // $/million tokens
-	CostOutput    float64
-	Reasoning     bool
-	ReleaseDate   string
+	ID             string // models.dev model ID
+	DisplayName    string
+	Provider       string // models.dev provider key (e.g., "kimi-for-coding")
+	ContextWindow  int
+	MaxOutput      int
+	CostInput      float64 // $/million tokens
+	CostOutput     float64
+	CostCacheRead  float64
+	CostCacheWrite float64
+	Reasoning      bool
+	ReleaseDate    string
 }
 
 // registryJSON mirrors models.dev/api.json structure for parsing.
@@ -65,8 +67,10 @@ type registryLimit struct {
 }
 
 type registryCost struct {
-	Input  float64 `json:"input"`
-	Output float64 `json:"output"`
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheRead  float64 `json:"cache_read"`
+	CacheWrite float64 `json:"cache_write"`
 }
 
 // ParseRegistry parses models.dev JSON and returns filtered models.
@@ -97,15 +101,17 @@ func ParseRegistry(data []byte, cutoff time.Time) ([]RegistryModel, error) {
 			}
 
 			result = append(result, RegistryModel{
-				ID:            m.ID,
-				DisplayName:   m.Name,
-				Provider:      providerKey,
-				ContextWindow: m.Limit.Context,
-				MaxOutput:     m.Limit.Output,
-				CostInput:     m.Cost.Input,
-				CostOutput:    m.Cost.Output,
-				Reasoning:     m.Reasoning,
-				ReleaseDate:   m.ReleaseDate,
+				ID:             m.ID,
+				DisplayName:    m.Name,
+				Provider:       providerKey,
+				ContextWindow:  m.Limit.Context,
+				MaxOutput:      m.Limit.Output,
+				CostInput:      m.Cost.Input,
+				CostOutput:     m.Cost.Output,
+				CostCacheRead:  m.Cost.CacheRead,
+				CostCacheWrite: m.Cost.CacheWrite,
+				Reasoning:      m.Reasoning,
+				ReleaseDate:    m.ReleaseDate,
 			})
 		}
 	}
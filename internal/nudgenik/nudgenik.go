@@ -8,6 +8,7 @@ import (
 
 	"github.com/sergeknystautas/schmux/internal/config"
 	"github.com/sergeknystautas/schmux/internal/oneshot"
+	"github.com/sergeknystautas/schmux/internal/redact"
 	"github.com/sergeknystautas/schmux/internal/schema"
 	"github.com/sergeknystautas/schmux/internal/tmux"
 )
@@ -84,7 +85,9 @@ type Result struct {
 }
 
 // AskForCapture extracts the latest response from a raw tmux capture and asks NudgeNik for feedback.
+// Secrets in the capture are redacted before it is sent.
 func AskForCapture(ctx context.Context, cfg *config.Config, capture string) (Result, error) {
+	capture, _ = redact.String(redact.SiteNudgeNik, capture)
 	extracted, err := ExtractLatestFromCapture(capture)
 	if err != nil {
 		return Result{}, err
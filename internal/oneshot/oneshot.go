@@ -14,11 +14,13 @@ import (
 
 	"github.com/sergeknystautas/schmux/internal/api/contracts"
 	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/cost"
 	"github.com/sergeknystautas/schmux/internal/detect"
 	"github.com/sergeknystautas/schmux/internal/directhttp"
 	"github.com/sergeknystautas/schmux/internal/models"
 	"github.com/sergeknystautas/schmux/internal/oneshotdecode"
 	"github.com/sergeknystautas/schmux/internal/oneshotlog"
+	"github.com/sergeknystautas/schmux/internal/redact"
 	"github.com/sergeknystautas/schmux/internal/schema"
 	"github.com/sergeknystautas/schmux/internal/schmuxdir"
 )
@@ -31,6 +33,24 @@ func SetModelManager(mm *models.Manager) {
 	modelManager = mm
 }
 
+// usageRecorder receives the log record of every ExecuteTarget call, set via
+// SetUsageRecorder.
+var usageRecorder func(contracts.OneshotLogRecord)
+
+// SetUsageRecorder sets a function called with the record of every
+// ExecuteTarget call, after it is logged. Cost accounting uses it. Must be
+// called before any oneshot call.
+func SetUsageRecorder(fn func(contracts.OneshotLogRecord)) {
+	usageRecorder = fn
+}
+
+// callUsage is the token usage an agent or API reported for one call. Model
+// is the model the agent reports it used, when that differs from the target.
+type callUsage struct {
+	Model string
+	cost.Usage
+}
+
 // ErrTargetNotFound is returned when a target name cannot be resolved.
 var ErrTargetNotFound = errors.New("target not found")
 
@@ -115,27 +135,33 @@ const (
 // The model parameter is optional; if provided, it will be used to inject model-specific flags.
 // Returns the parsed response string from the agent.
 func Execute(ctx context.Context, agentName, agentCommand, prompt, schemaLabel string, env map[string]string, dir string, model *detect.Model) (string, error) {
+	out, _, err := execute(ctx, agentName, agentCommand, prompt, schemaLabel, env, dir, model)
+	return out, err
+}
+
+// execute is Execute that also returns the token usage the agent reported.
+func execute(ctx context.Context, agentName, agentCommand, prompt, schemaLabel string, env map[string]string, dir string, model *detect.Model) (string, callUsage, error) {
 	// Validate inputs
 	if agentName == "" {
-		return "", fmt.Errorf("agent name cannot be empty")
+		return "", callUsage{}, fmt.Errorf("agent name cannot be empty")
 	}
 	if agentCommand == "" {
-		return "", fmt.Errorf("agent command cannot be empty")
+		return "", callUsage{}, fmt.Errorf("agent command cannot be empty")
 	}
 	if prompt == "" {
-		return "", fmt.Errorf("prompt cannot be empty")
+		return "", callUsage{}, fmt.Errorf("prompt cannot be empty")
 	}
 
 	// Resolve schema (always required per oneshot contract)
 	schemaPath, err := resolveSchema(schemaLabel)
 	if err != nil {
-		return "", err
+		return "", callUsage{}, err
 	}
 	var schemaArg string
 	if agentName == "claude" {
 		content, err := os.ReadFile(schemaPath)
 		if err != nil {
-			return "", fmt.Errorf("failed to read schema file %s: %w", schemaPath, err)
+			return "", callUsage{}, fmt.Errorf("failed to read schema file %s: %w", schemaPath, err)
 		}
 		schemaArg = string(content)
 	} else {
@@ -145,7 +171,7 @@ func Execute(ctx context.Context, agentName, agentCommand, prompt, schemaLabel s
 	// Build command parts safely
 	cmdParts, err := detect.BuildCommandParts(agentName, agentCommand, detect.ToolModeOneshot, schemaArg, model)
 	if err != nil {
-		return "", err
+		return "", callUsage{}, err
 	}
 
 	// Build exec command - prompt passed via stdin
@@ -164,19 +190,20 @@ func Execute(ctx context.Context, agentName, agentCommand, prompt, schemaLabel s
 
 	// Capture stdout and stderr
 	rawOutput, err := execCmd.CombinedOutput()
+	usage := parseUsage(agentName, string(rawOutput))
 	if err != nil {
 		// The process may have been killed by timeout after producing valid output.
 		// Attempt to parse the output before giving up.
 		if parsed := parseResponse(agentName, string(rawOutput)); parsed != string(rawOutput) {
 			// parseResponse extracted structured data — the output was valid despite exit error
-			return parsed, nil
+			return parsed, usage, nil
 		}
-		return "", fmt.Errorf("agent %s: one-shot execution failed (command: %s): %w\noutput: %s",
+		return "", usage, fmt.Errorf("agent %s: one-shot execution failed (command: %s): %w\noutput: %s",
 			agentName, strings.Join(append(cmdParts, "<prompt>"), " "), err, string(rawOutput))
 	}
 
 	// Parse response based on agent type
-	return parseResponse(agentName, string(rawOutput)), nil
+	return parseResponse(agentName, string(rawOutput)), usage, nil
 }
 
 // ExecuteCommand runs an arbitrary promptable command in one-shot mode, appending the prompt as the final argument.
@@ -216,20 +243,20 @@ func ExecuteCommand(ctx context.Context, command, prompt string, env map[string]
 // executeTargetRaw is the transport-level oneshot call. It returns the raw LLM
 // response string. Only ExecuteTarget[T] (the public generic) calls it.
 // Package-private to prevent features from bypassing schema enforcement.
-func executeTargetRaw(ctx context.Context, cfg *config.Config, targetName, prompt, schemaLabel string, timeout time.Duration, dir string) (string, error) {
+func executeTargetRaw(ctx context.Context, cfg *config.Config, targetName, prompt, schemaLabel string, timeout time.Duration, dir string) (string, callUsage, error) {
 	if targetName == "" {
-		return "", ErrDisabled
+		return "", callUsage{}, ErrDisabled
 	}
 	if prompt == "" {
-		return "", fmt.Errorf("prompt cannot be empty")
+		return "", callUsage{}, fmt.Errorf("prompt cannot be empty")
 	}
 
 	target, err := resolveTarget(cfg, targetName)
 	if err != nil {
-		return "", err
+		return "", callUsage{}, err
 	}
 	if !target.Promptable {
-		return "", fmt.Errorf("target %s must be promptable", targetName)
+		return "", callUsage{}, fmt.Errorf("target %s must be promptable", targetName)
 	}
 
 	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
@@ -237,9 +264,10 @@ func executeTargetRaw(ctx context.Context, cfg *config.Config, targetName, promp
 
 	if target.Kind == targetKindUser {
 		// User-defined targets don't support JSON schema
-		return ExecuteCommand(timeoutCtx, target.Command, prompt, target.Env, dir)
+		out, err := ExecuteCommand(timeoutCtx, target.Command, prompt, target.Env, dir)
+		return out, callUsage{}, err
 	}
-	return Execute(timeoutCtx, target.ToolName, target.Command, prompt, schemaLabel, target.Env, dir, target.Model)
+	return execute(timeoutCtx, target.ToolName, target.Command, prompt, schemaLabel, target.Env, dir, target.Model)
 }
 
 // ExecuteTarget runs the configured oneshot target with prompt and decodes the
@@ -269,20 +297,28 @@ func ExecuteTarget[T any](
 	if targetName == "" {
 		return zero, ErrDisabled
 	}
+	// Prompts often embed terminal output. Secrets are masked before the
+	// prompt leaves the machine, and the oneshot log records it masked too.
+	prompt, _ = redact.String(redact.SiteOneshot, prompt)
 
 	// Capture every attempt below — both transports, every error path — once.
 	// Guards above return before this defer is registered: nothing was sent.
 	start := time.Now()
+	var usage callUsage
 	defer func() {
-		_ = oneshotlog.Append(newOneshotRecord(schemaLabel, targetName, dir, prompt, start, err))
+		rec := newOneshotRecord(schemaLabel, targetName, dir, prompt, start, usage, err)
+		_ = oneshotlog.Append(rec)
+		if usageRecorder != nil {
+			usageRecorder(rec)
+		}
 	}()
 
 	if _, isAPI := directhttp.StripAPISuffix(targetName); isAPI {
-		result, err = directhttp.ExecuteAPI[T](ctx, cfg, targetName, prompt, schemaLabel, timeout, dir)
+		result, usage.Usage, err = directhttp.ExecuteAPI[T](ctx, cfg, targetName, prompt, schemaLabel, timeout, dir)
 		return result, err
 	}
 
-	raw, rawErr := executeTargetRaw(ctx, cfg, targetName, prompt, schemaLabel, timeout, dir)
+	raw, usage, rawErr := executeTargetRaw(ctx, cfg, targetName, prompt, schemaLabel, timeout, dir)
 	if rawErr != nil {
 		return zero, rawErr
 	}
@@ -297,8 +333,11 @@ func ExecuteTarget[T any](
 // newOneshotRecord builds the log record for one ExecuteTarget call. The model
 // and transport come straight off targetName (it is the model id, or
 // <modelid>::api); workspace is the basename of the call's dir.
-func newOneshotRecord(schemaLabel, targetName, dir, prompt string, start time.Time, err error) contracts.OneshotLogRecord {
+func newOneshotRecord(schemaLabel, targetName, dir, prompt string, start time.Time, usage callUsage, err error) contracts.OneshotLogRecord {
 	model, isAPI := directhttp.StripAPISuffix(targetName)
+	if usage.Model != "" {
+		model = usage.Model
+	}
 	transport := "cli"
 	if isAPI {
 		transport = "api"
@@ -321,6 +360,11 @@ func newOneshotRecord(schemaLabel, targetName, dir, prompt string, start time.Ti
 		ElapsedMS:   time.Since(start).Milliseconds(),
 		OK:          err == nil,
 		Error:       errStr,
+
+		InputTokens:      usage.InputTokens,
+		OutputTokens:     usage.OutputTokens,
+		CacheReadTokens:  usage.CacheReadTokens,
+		CacheWriteTokens: usage.CacheWriteTokens,
 	}
 }
 
@@ -363,6 +407,80 @@ func parseResponse(agentName, output string) string {
 	}
 }
 
+// parseUsage extracts token usage from an agent's oneshot output. Agents that
+// report none yield a zero usage.
+func parseUsage(agentName, output string) callUsage {
+	switch agentName {
+	case "claude":
+		return parseClaudeUsage(output)
+	case "codex":
+		return parseCodexUsage(output)
+	default:
+		return callUsage{}
+	}
+}
+
+// parseClaudeUsage reads the usage block of Claude's JSON envelope. When
+// modelUsage names a single model, that model is reported so the call is
+// priced as the model that actually ran.
+func parseClaudeUsage(output string) callUsage {
+	trimmed := strings.TrimSpace(output)
+	start := strings.Index(trimmed, "{")
+	end := strings.LastIndex(trimmed, "}")
+	if start < 0 || end <= start {
+		return callUsage{}
+	}
+	var envelope struct {
+		Usage struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+		} `json:"usage"`
+		ModelUsage map[string]json.RawMessage `json:"modelUsage"`
+	}
+	if err := json.Unmarshal([]byte(trimmed[start:end+1]), &envelope); err != nil {
+		return callUsage{}
+	}
+	u := callUsage{Usage: cost.Usage{
+		InputTokens:      envelope.Usage.InputTokens,
+		OutputTokens:     envelope.Usage.OutputTokens,
+		CacheReadTokens:  envelope.Usage.CacheReadInputTokens,
+		CacheWriteTokens: envelope.Usage.CacheCreationInputTokens,
+	}}
+	if len(envelope.ModelUsage) == 1 {
+		for model := range envelope.ModelUsage {
+			u.Model = model
+		}
+	}
+	return u
+}
+
+// parseCodexUsage sums the usage of Codex's turn.completed events. Codex
+// counts cached tokens inside input_tokens; they are split out here.
+func parseCodexUsage(output string) callUsage {
+	var u callUsage
+	for _, line := range strings.Split(output, "\n") {
+		var event struct {
+			Type  string `json:"type"`
+			Usage struct {
+				InputTokens       int64 `json:"input_tokens"`
+				CachedInputTokens int64 `json:"cached_input_tokens"`
+				OutputTokens      int64 `json:"output_tokens"`
+			} `json:"usage"`
+		}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &event); err != nil || event.Type != "turn.completed" {
+			continue
+		}
+		u.Usage = u.Usage.Add(cost.Usage{
+			InputTokens:     event.Usage.InputTokens - event.Usage.CachedInputTokens,
+			OutputTokens:    event.Usage.OutputTokens,
+			CacheReadTokens: event.Usage.CachedInputTokens,
+		})
+	}
+	return u
+}
+
 // parseClaudeStructuredOutput extracts the response text from Claude's JSON envelope.
 // It prefers structured_output (present when --json-schema is used), falling back to
 // the result field (plain text responses). The result field is JSON-unquoted so that
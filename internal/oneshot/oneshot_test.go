@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/cost"
 	"github.com/sergeknystautas/schmux/internal/detect"
 	"github.com/sergeknystautas/schmux/internal/oneshotlog"
 	"github.com/sergeknystautas/schmux/internal/schmuxdir"
@@ -388,6 +389,48 @@ func TestParseResponse(t *testing.T) {
 	}
 }
 
+func TestParseUsage(t *testing.T) {
+	tests := []struct {
+		name      string
+		agentName string
+		output    string
+		expected  callUsage
+	}{
+		{
+			name:      "claude envelope usage and single model",
+			agentName: "claude",
+			output:    `{"result":"x","usage":{"input_tokens":10,"output_tokens":20,"cache_read_input_tokens":30,"cache_creation_input_tokens":40},"modelUsage":{"claude-haiku-4-5":{}}}`,
+			expected:  callUsage{Model: "claude-haiku-4-5", Usage: cost.Usage{InputTokens: 10, OutputTokens: 20, CacheReadTokens: 30, CacheWriteTokens: 40}},
+		},
+		{
+			name:      "claude ambiguous model keeps target model",
+			agentName: "claude",
+			output:    `{"usage":{"input_tokens":1},"modelUsage":{"a":{},"b":{}}}`,
+			expected:  callUsage{Usage: cost.Usage{InputTokens: 1}},
+		},
+		{
+			name:      "codex splits cached input",
+			agentName: "codex",
+			output: `{"type":"turn.started"}
+{"type":"turn.completed","usage":{"input_tokens":100,"cached_input_tokens":60,"output_tokens":7}}`,
+			expected: callUsage{Usage: cost.Usage{InputTokens: 40, OutputTokens: 7, CacheReadTokens: 60}},
+		},
+		{
+			name:      "unknown agent reports nothing",
+			agentName: "unknown",
+			output:    `{"usage":{"input_tokens":1}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseUsage(tt.agentName, tt.output); got != tt.expected {
+				t.Errorf("parseUsage() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
 // ===== Tests for unified ExecuteTarget[T] generic (rev 2026-04-20) =====
 
 type unifiedTestResult struct {
@@ -629,13 +672,13 @@ func TestExecuteTarget_BareID_StaysOnCLIPath(t *testing.T) {
 func TestNewOneshotRecord(t *testing.T) {
 	start := time.Now()
 
-	ok := newOneshotRecord("commit-message", "claude-sonnet-4-6", "/x/y/schmux-7", "hello", start, nil)
+	ok := newOneshotRecord("commit-message", "claude-sonnet-4-6", "/x/y/schmux-7", "hello", start, callUsage{}, nil)
 	if ok.Type != "commit-message" || ok.Transport != "cli" || ok.Model != "claude-sonnet-4-6" ||
 		ok.Workspace != "schmux-7" || ok.PromptChars != 5 || !ok.OK || ok.Error != "" || ok.TS == "" {
 		t.Fatalf("ok record wrong: %+v", ok)
 	}
 
-	bad := newOneshotRecord("repofeed-intent", "claude-opus::api", "", "p", start, errors.New("boom"))
+	bad := newOneshotRecord("repofeed-intent", "claude-opus::api", "", "p", start, callUsage{}, errors.New("boom"))
 	if bad.Transport != "api" || bad.Model != "claude-opus" || bad.Workspace != "" ||
 		bad.OK || bad.Error != "boom" {
 		t.Fatalf("bad record wrong: %+v", bad)
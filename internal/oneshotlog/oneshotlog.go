@@ -18,9 +18,11 @@ func Path() string {
 	return filepath.Join(schmuxdir.LogsDir(), "oneshot.jsonl")
 }
 
-// Append writes one record as a JSON line to oneshot.jsonl. Best effort — a
-// logging failure must never affect the oneshot call's result.
+// Append writes one record as a JSON line to oneshot.jsonl and counts it in
+// Stats. Best effort — a logging failure must never affect the oneshot call's
+// result.
 func Append(rec contracts.OneshotLogRecord) error {
+	record(rec)
 	path := Path()
 	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
 		return err
@@ -40,3 +40,34 @@ func TestAppendRoundTrip(t *testing.T) {
 		t.Errorf("round-trip mismatch: %+v", got)
 	}
 }
+
+func TestStats(t *testing.T) {
+	schmuxdir.Set(t.TempDir())
+	for _, rec := range []contracts.OneshotLogRecord{
+		{Type: "stats-test", Transport: "cli", ElapsedMS: 1500, OK: true},
+		{Type: "stats-test", Transport: "cli", ElapsedMS: 700, Error: "boom"},
+		{Type: "stats-test", Transport: "api", ElapsedMS: 200, OK: true},
+	} {
+		if err := Append(rec); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	var api, cli *Stat
+	for _, s := range Stats() {
+		if s.Type != "stats-test" {
+			continue
+		}
+		switch s.Transport {
+		case "api":
+			api = &s
+		case "cli":
+			cli = &s
+		}
+	}
+	if cli == nil || cli.Calls != 2 || cli.Errors != 1 || cli.Duration.Count != 2 || cli.Duration.Sum != 2.2 {
+		t.Errorf("cli stat = %+v", cli)
+	}
+	if api == nil || api.Calls != 1 || api.Errors != 0 {
+		t.Errorf("api stat = %+v", api)
+	}
+}
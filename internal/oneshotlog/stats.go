@@ -0,0 +1,66 @@
+package oneshotlog
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/metrics"
+)
+
+// Stat aggregates the oneshot calls of one type and transport recorded since
+// the daemon started.
+type Stat struct {
+	Type      string
+	Transport string
+	Calls     uint64
+	Errors    uint64
+	Duration  metrics.HistogramSnapshot // seconds
+}
+
+type statKey struct{ typ, transport string }
+
+type statEntry struct {
+	calls, errors uint64
+	duration      *metrics.Histogram
+}
+
+var (
+	statsMu sync.Mutex
+	stats   = map[statKey]*statEntry{}
+)
+
+// record adds rec to the in-memory stats behind Stats.
+func record(rec contracts.OneshotLogRecord) {
+	key := statKey{rec.Type, rec.Transport}
+	statsMu.Lock()
+	e, ok := stats[key]
+	if !ok {
+		e = &statEntry{duration: metrics.NewHistogram(metrics.DefaultLatencyBuckets)}
+		stats[key] = e
+	}
+	e.calls++
+	if !rec.OK {
+		e.errors++
+	}
+	statsMu.Unlock()
+	e.duration.Observe(float64(rec.ElapsedMS) / 1000)
+}
+
+// Stats returns per type/transport call counts and latencies for every call
+// appended since the process started, sorted by type then transport.
+func Stats() []Stat {
+	statsMu.Lock()
+	out := make([]Stat, 0, len(stats))
+	for k, e := range stats {
+		out = append(out, Stat{Type: k.typ, Transport: k.transport, Calls: e.calls, Errors: e.errors, Duration: e.duration.Snapshot()})
+	}
+	statsMu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Transport < out[j].Transport
+	})
+	return out
+}
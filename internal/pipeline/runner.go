@@ -0,0 +1,657 @@
+// Package pipeline executes pipeline runs: DAGs of spawn stages whose edges
+// fire on session status events. Definitions live in the spawn package's
+// PipelineStore; this package only tracks runs in flight.
+package pipeline
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/events"
+	"github.com/sergeknystautas/schmux/internal/fileutil"
+)
+
+var (
+	ErrRunNotFound = errors.New("pipeline: run not found")
+	ErrRunFinished = errors.New("pipeline: run already finished")
+	ErrNoSpawnFunc = errors.New("pipeline: spawner not configured")
+	errSpawnedNone = errors.New("no sessions were spawned")
+)
+
+const (
+	// maxFinishedRuns bounds how many completed/failed/canceled runs are kept.
+	maxFinishedRuns = 100
+	// spawnStageTimeout bounds one stage's spawn call (workspace creation
+	// may clone a repo).
+	spawnStageTimeout = 10 * time.Minute
+)
+
+// SpawnedSession is one session created for a stage.
+type SpawnedSession struct {
+	SessionID   string
+	WorkspaceID string
+}
+
+// SpawnFunc starts the sessions for a stage. Per-target failures that still
+// spawned other sessions are reported through the returned slice; an error
+// means no session was started.
+type SpawnFunc func(ctx context.Context, req contracts.SpawnRequest) ([]SpawnedSession, error)
+
+// stageRef locates a stage within a run.
+type stageRef struct {
+	runID   string
+	stageID string
+}
+
+// Runner tracks pipeline runs and advances them as their sessions report
+// status. It implements events.EventHandler and is registered for "status".
+type Runner struct {
+	mu       sync.Mutex
+	path     string
+	runs     map[string]*contracts.PipelineRun
+	sessions map[string]stageRef // sessionID -> owning stage
+	// early buffers statuses from sessions that reported before their
+	// stage's spawn call returned. Cleared once no stage is spawning.
+	early    map[string]string
+	spawning int
+
+	spawn    SpawnFunc
+	onChange func()
+	logger   *log.Logger
+	ctx      context.Context // cancelled on shutdown; stops stage spawns
+	wg       sync.WaitGroup
+}
+
+// NewRunner creates a runner persisting runs to path. Stages spawn under
+// ctx. Runs left mid-spawn by a previous daemon, or cut short by ctx's
+// cancellation, are marked failed, since their spawn never returned.
+func NewRunner(ctx context.Context, path string, logger *log.Logger) *Runner {
+	if logger == nil {
+		logger = log.NewWithOptions(io.Discard, log.Options{})
+	}
+	r := &Runner{
+		path:     path,
+		runs:     make(map[string]*contracts.PipelineRun),
+		sessions: make(map[string]stageRef),
+		early:    make(map[string]string),
+		logger:   logger,
+		ctx:      ctx,
+	}
+	r.load()
+	return r
+}
+
+// SetSpawnFunc sets the function used to start stage sessions.
+func (r *Runner) SetSpawnFunc(fn SpawnFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spawn = fn
+}
+
+// SetChangeCallback sets a callback invoked after any run changes state.
+func (r *Runner) SetChangeCallback(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onChange = fn
+}
+
+func (r *Runner) load() {
+	if r.path == "" {
+		return
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			r.logger.Warn("failed to read pipeline runs", "err", err)
+		}
+		return
+	}
+	var runs []contracts.PipelineRun
+	if err := json.Unmarshal(data, &runs); err != nil {
+		r.logger.Warn("failed to parse pipeline runs", "err", err)
+		return
+	}
+	now := time.Now().UTC()
+	for i := range runs {
+		run := &runs[i]
+		for j := range run.Stages {
+			st := &run.Stages[j]
+			if st.Status == contracts.PipelineStageSpawning {
+				st.Status = contracts.PipelineStageFailed
+				st.Error = "interrupted by daemon restart"
+				st.FinishedAt = &now
+				if run.Status == contracts.PipelineRunRunning {
+					r.haltLocked(run, now)
+				}
+			}
+			for _, id := range st.SessionIDs {
+				r.sessions[id] = stageRef{runID: run.ID, stageID: st.StageID}
+			}
+		}
+		r.runs[run.ID] = run
+	}
+}
+
+// saveLocked persists all runs. Caller must hold r.mu.
+func (r *Runner) saveLocked() {
+	if r.path == "" {
+		return
+	}
+	r.pruneLocked()
+	runs := make([]contracts.PipelineRun, 0, len(r.runs))
+	for _, run := range r.runs {
+		runs = append(runs, *run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].CreatedAt.Before(runs[j].CreatedAt) })
+	data, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		r.logger.Warn("failed to marshal pipeline runs", "err", err)
+		return
+	}
+	if err := fileutil.AtomicWriteFile(r.path, data, 0600); err != nil {
+		r.logger.Warn("failed to save pipeline runs", "err", err)
+	}
+}
+
+// pruneLocked drops the oldest finished runs beyond maxFinishedRuns.
+func (r *Runner) pruneLocked() {
+	var finished []*contracts.PipelineRun
+	for _, run := range r.runs {
+		if run.Status != contracts.PipelineRunRunning {
+			finished = append(finished, run)
+		}
+	}
+	if len(finished) <= maxFinishedRuns {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].CreatedAt.Before(finished[j].CreatedAt) })
+	for _, run := range finished[:len(finished)-maxFinishedRuns] {
+		for _, st := range run.Stages {
+			for _, id := range st.SessionIDs {
+				delete(r.sessions, id)
+			}
+		}
+		delete(r.runs, run.ID)
+	}
+}
+
+func generateRunID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("pr-%x", time.Now().UnixNano())
+	}
+	return "pr-" + hex.EncodeToString(b)
+}
+
+// Start begins a run of p. The caller validates p and fills its Repo and
+// Branch defaults; the definition is snapshotted into the run.
+func (r *Runner) Start(repo string, p contracts.Pipeline) (contracts.PipelineRun, error) {
+	r.mu.Lock()
+	if r.spawn == nil {
+		r.mu.Unlock()
+		return contracts.PipelineRun{}, ErrNoSpawnFunc
+	}
+	run := &contracts.PipelineRun{
+		ID:         generateRunID(),
+		PipelineID: p.ID,
+		Name:       p.Name,
+		Repo:       repo,
+		Status:     contracts.PipelineRunRunning,
+		Pipeline:   p,
+		CreatedAt:  time.Now().UTC(),
+	}
+	for _, st := range p.Stages {
+		run.Stages = append(run.Stages, contracts.PipelineStageRun{
+			StageID: st.ID,
+			Name:    st.Name,
+			Status:  contracts.PipelineStagePending,
+		})
+	}
+	r.runs[run.ID] = run
+	r.advanceLocked(run)
+	r.saveLocked()
+	snapshot := cloneRun(run)
+	r.mu.Unlock()
+	r.notify()
+	return snapshot, nil
+}
+
+// Cancel stops a running run. Pending stages are skipped; sessions already
+// spawned keep running.
+func (r *Runner) Cancel(runID string) error {
+	r.mu.Lock()
+	run, ok := r.runs[runID]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	}
+	if run.Status != contracts.PipelineRunRunning {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrRunFinished, runID)
+	}
+	now := time.Now().UTC()
+	skipPendingLocked(run, now)
+	run.Status = contracts.PipelineRunCanceled
+	run.FinishedAt = &now
+	r.saveLocked()
+	r.mu.Unlock()
+	r.notify()
+	return nil
+}
+
+// Runs returns all tracked runs, newest first.
+func (r *Runner) Runs() []contracts.PipelineRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]contracts.PipelineRun, 0, len(r.runs))
+	for _, run := range r.runs {
+		out = append(out, cloneRun(run))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+// Get returns a run by ID.
+func (r *Runner) Get(runID string) (contracts.PipelineRun, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run, ok := r.runs[runID]
+	if !ok {
+		return contracts.PipelineRun{}, false
+	}
+	return cloneRun(run), true
+}
+
+// SessionInfo reports the pipeline stage that spawned a session, if any.
+func (r *Runner) SessionInfo(sessionID string) (contracts.SessionPipelineInfo, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ref, ok := r.sessions[sessionID]
+	if !ok {
+		return contracts.SessionPipelineInfo{}, false
+	}
+	run, ok := r.runs[ref.runID]
+	if !ok {
+		return contracts.SessionPipelineInfo{}, false
+	}
+	idx := stageIndex(run, ref.stageID)
+	if idx < 0 {
+		return contracts.SessionPipelineInfo{}, false
+	}
+	st := run.Stages[idx]
+	return contracts.SessionPipelineInfo{
+		RunID:        run.ID,
+		PipelineName: run.Name,
+		StageID:      st.StageID,
+		StageName:    st.Name,
+		StageIndex:   idx,
+		StageCount:   len(run.Stages),
+		RunStatus:    run.Status,
+		StageStatus:  st.Status,
+	}, true
+}
+
+// Wait blocks until in-flight stage spawns return. Once the runner's context
+// is cancelled, that is as soon as their spawn calls give up.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+// HandleEvent implements events.EventHandler. Status events from a stage's
+// sessions fire the stage's success or failure edge.
+func (r *Runner) HandleEvent(_ context.Context, sessionID string, raw events.RawEvent, data []byte) {
+	if raw.Type != "status" {
+		return
+	}
+	var evt events.StatusEvent
+	if err := json.Unmarshal(data, &evt); err != nil {
+		return
+	}
+	r.mu.Lock()
+	ref, ok := r.sessions[sessionID]
+	if !ok {
+		if r.spawning > 0 {
+			r.early[sessionID] = evt.State
+		}
+		r.mu.Unlock()
+		return
+	}
+	changed := r.applyStatusLocked(ref, sessionID, evt.State)
+	if changed {
+		r.saveLocked()
+	}
+	r.mu.Unlock()
+	if changed {
+		r.notify()
+	}
+}
+
+// applyStatusLocked records one session status against its stage and
+// advances the run. Returns whether anything changed.
+func (r *Runner) applyStatusLocked(ref stageRef, sessionID, state string) bool {
+	run, ok := r.runs[ref.runID]
+	if !ok || run.Status != contracts.PipelineRunRunning {
+		return false
+	}
+	idx := stageIndex(run, ref.stageID)
+	if idx < 0 {
+		return false
+	}
+	st := &run.Stages[idx]
+	if st.Status != contracts.PipelineStageRunning {
+		return false
+	}
+	def := stageDef(run, ref.stageID)
+	now := time.Now().UTC()
+	switch {
+	case state == string(contracts.PipelineTriggerError):
+		st.Status = contracts.PipelineStageFailed
+		st.Error = fmt.Sprintf("session %s reported error", sessionID)
+		st.FinishedAt = &now
+		r.logger.Info("pipeline stage failed", "run", run.ID, "stage", st.StageID, "session", sessionID)
+	case succeeds(def, state):
+		if !contains(st.Done, sessionID) {
+			st.Done = append(st.Done, sessionID)
+		}
+		if len(st.Done) < len(st.SessionIDs) {
+			return true
+		}
+		st.Status = contracts.PipelineStageSucceeded
+		st.FinishedAt = &now
+		r.logger.Info("pipeline stage succeeded", "run", run.ID, "stage", st.StageID)
+	default:
+		return false
+	}
+	r.advanceLocked(run)
+	return true
+}
+
+// advanceLocked starts every stage whose edges have fired and finishes the
+// run when nothing is left in flight.
+func (r *Runner) advanceLocked(run *contracts.PipelineRun) {
+	if run.Status != contracts.PipelineRunRunning {
+		return
+	}
+	now := time.Now().UTC()
+	alternates := alternateStages(run.Pipeline)
+
+	// Failed stages branch to their alternate, or halt the run.
+	for i := range run.Stages {
+		st := &run.Stages[i]
+		if st.Status != contracts.PipelineStageFailed {
+			continue
+		}
+		def := stageDef(run, st.StageID)
+		if def.OnFailure == "" {
+			r.haltLocked(run, now)
+			return
+		}
+		alt := &run.Stages[stageIndex(run, def.OnFailure)]
+		if alt.Status == contracts.PipelineStagePending {
+			r.startStageLocked(run, alt, st.WorkspaceID)
+		}
+	}
+
+	for i := range run.Stages {
+		st := &run.Stages[i]
+		if st.Status != contracts.PipelineStagePending || alternates[st.StageID] {
+			continue
+		}
+		def := stageDef(run, st.StageID)
+		ready := true
+		for _, dep := range def.After {
+			if !satisfied(run, dep) {
+				ready = false
+				break
+			}
+		}
+		if !ready {
+			continue
+		}
+		workspaceID := ""
+		if len(def.After) > 0 {
+			workspaceID = run.Stages[stageIndex(run, def.After[0])].WorkspaceID
+			if workspaceID == "" {
+				// The upstream stage recovered through its alternate.
+				workspaceID = recoveredWorkspace(run, def.After[0])
+			}
+		}
+		r.startStageLocked(run, st, workspaceID)
+	}
+
+	for _, st := range run.Stages {
+		if st.Status == contracts.PipelineStageSpawning || st.Status == contracts.PipelineStageRunning {
+			return
+		}
+	}
+	skipPendingLocked(run, now)
+	run.Status = contracts.PipelineRunCompleted
+	run.FinishedAt = &now
+	r.logger.Info("pipeline run completed", "run", run.ID, "pipeline", run.Name)
+}
+
+// haltLocked fails the run, skipping every stage that has not started.
+func (r *Runner) haltLocked(run *contracts.PipelineRun, now time.Time) {
+	skipPendingLocked(run, now)
+	run.Status = contracts.PipelineRunFailed
+	run.FinishedAt = &now
+	r.logger.Info("pipeline run halted", "run", run.ID, "pipeline", run.Name)
+}
+
+// startStageLocked marks a stage spawning and spawns its sessions in the
+// background. parentWorkspace is used when the stage reuses a workspace.
+func (r *Runner) startStageLocked(run *contracts.PipelineRun, st *contracts.PipelineStageRun, parentWorkspace string) {
+	def := stageDef(run, st.StageID)
+	req := def.Spawn
+	if req.Repo == "" {
+		req.Repo = run.Pipeline.Repo
+	}
+	if req.Branch == "" {
+		req.Branch = run.Pipeline.Branch
+	}
+	if def.ReuseWorkspace && parentWorkspace != "" {
+		req.WorkspaceID = parentWorkspace
+		req.NewBranch = ""
+	}
+	now := time.Now().UTC()
+	st.Status = contracts.PipelineStageSpawning
+	st.StartedAt = &now
+	r.spawning++
+	spawn := r.spawn
+	runID, stageID := run.ID, st.StageID
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ctx, cancel := context.WithTimeout(r.ctx, spawnStageTimeout)
+		var sessions []SpawnedSession
+		err := ErrNoSpawnFunc
+		if spawn != nil {
+			sessions, err = spawn(ctx, req)
+		}
+		cancel()
+		if r.ctx.Err() != nil {
+			// Leave the stage spawning on disk; the next runner fails it.
+			r.logger.Info("pipeline stage spawn interrupted by shutdown", "run", runID, "stage", stageID)
+			r.mu.Lock()
+			r.spawning--
+			r.mu.Unlock()
+			return
+		}
+		if err == nil && len(sessions) == 0 {
+			err = errSpawnedNone
+		}
+		r.finishSpawn(runID, stageID, sessions, err)
+	}()
+}
+
+// finishSpawn records a stage's spawned sessions and replays any statuses
+// they reported while the spawn was in flight.
+func (r *Runner) finishSpawn(runID, stageID string, sessions []SpawnedSession, err error) {
+	r.mu.Lock()
+	r.spawning--
+	run, ok := r.runs[runID]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	st := &run.Stages[stageIndex(run, stageID)]
+	now := time.Now().UTC()
+	for _, s := range sessions {
+		st.SessionIDs = append(st.SessionIDs, s.SessionID)
+		if st.WorkspaceID == "" {
+			st.WorkspaceID = s.WorkspaceID
+		}
+		r.sessions[s.SessionID] = stageRef{runID: runID, stageID: stageID}
+	}
+	if err != nil {
+		st.Status = contracts.PipelineStageFailed
+		st.Error = err.Error()
+		st.FinishedAt = &now
+		r.logger.Warn("pipeline stage spawn failed", "run", runID, "stage", stageID, "err", err)
+		r.advanceLocked(run)
+	} else {
+		st.Status = contracts.PipelineStageRunning
+		for _, s := range sessions {
+			if state, ok := r.early[s.SessionID]; ok {
+				r.applyStatusLocked(stageRef{runID: runID, stageID: stageID}, s.SessionID, state)
+			}
+		}
+	}
+	for _, s := range sessions {
+		delete(r.early, s.SessionID)
+	}
+	if r.spawning == 0 {
+		r.early = make(map[string]string)
+	}
+	r.saveLocked()
+	r.mu.Unlock()
+	r.notify()
+}
+
+func (r *Runner) notify() {
+	r.mu.Lock()
+	fn := r.onChange
+	r.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// satisfied reports whether a stage's downstream edges may fire: it
+// succeeded, or it failed and its failure branch recovered.
+func satisfied(run *contracts.PipelineRun, stageID string) bool {
+	idx := stageIndex(run, stageID)
+	if idx < 0 {
+		return false
+	}
+	switch run.Stages[idx].Status {
+	case contracts.PipelineStageSucceeded:
+		return true
+	case contracts.PipelineStageFailed:
+		return recovered(run, stageID)
+	}
+	return false
+}
+
+// recovered reports whether a failed stage's alternate chain succeeded.
+func recovered(run *contracts.PipelineRun, stageID string) bool {
+	def := stageDef(run, stageID)
+	if def.OnFailure == "" {
+		return false
+	}
+	return satisfied(run, def.OnFailure)
+}
+
+// recoveredWorkspace returns the workspace of the alternate that recovered
+// stageID, following the failure chain.
+func recoveredWorkspace(run *contracts.PipelineRun, stageID string) string {
+	for next := stageDef(run, stageID).OnFailure; next != ""; next = stageDef(run, next).OnFailure {
+		if ws := run.Stages[stageIndex(run, next)].WorkspaceID; ws != "" {
+			return ws
+		}
+	}
+	return ""
+}
+
+func skipPendingLocked(run *contracts.PipelineRun, now time.Time) {
+	for i := range run.Stages {
+		if run.Stages[i].Status == contracts.PipelineStagePending {
+			run.Stages[i].Status = contracts.PipelineStageSkipped
+			run.Stages[i].FinishedAt = &now
+		}
+	}
+}
+
+func succeeds(def contracts.PipelineStage, state string) bool {
+	if len(def.SucceedOn) == 0 {
+		return state == string(contracts.PipelineTriggerCompleted)
+	}
+	for _, trig := range def.SucceedOn {
+		if string(trig) == state {
+			return true
+		}
+	}
+	return false
+}
+
+func alternateStages(p contracts.Pipeline) map[string]bool {
+	out := make(map[string]bool)
+	for _, st := range p.Stages {
+		if st.OnFailure != "" {
+			out[st.OnFailure] = true
+		}
+	}
+	return out
+}
+
+func stageIndex(run *contracts.PipelineRun, stageID string) int {
+	for i := range run.Stages {
+		if run.Stages[i].StageID == stageID {
+			return i
+		}
+	}
+	return -1
+}
+
+func stageDef(run *contracts.PipelineRun, stageID string) contracts.PipelineStage {
+	for _, st := range run.Pipeline.Stages {
+		if st.ID == stageID {
+			return st
+		}
+	}
+	return contracts.PipelineStage{}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneRun deep-copies the mutable slices of a run for callers.
+func cloneRun(run *contracts.PipelineRun) contracts.PipelineRun {
+	out := *run
+	out.Stages = make([]contracts.PipelineStageRun, len(run.Stages))
+	for i, st := range run.Stages {
+		st.SessionIDs = append([]string(nil), st.SessionIDs...)
+		st.Done = append([]string(nil), st.Done...)
+		out.Stages[i] = st
+	}
+	return out
+}
@@ -0,0 +1,318 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/events"
+)
+
+// fakeSpawner records spawn requests and hands out sequential session IDs.
+type fakeSpawner struct {
+	mu       sync.Mutex
+	reqs     []contracts.SpawnRequest
+	n        int
+	failWith error
+}
+
+func (f *fakeSpawner) spawn(_ context.Context, req contracts.SpawnRequest) ([]SpawnedSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reqs = append(f.reqs, req)
+	if f.failWith != nil {
+		return nil, f.failWith
+	}
+	f.n++
+	ws := req.WorkspaceID
+	if ws == "" {
+		ws = fmt.Sprintf("ws-%d", f.n)
+	}
+	return []SpawnedSession{{SessionID: fmt.Sprintf("s-%d", f.n), WorkspaceID: ws}}, nil
+}
+
+func (f *fakeSpawner) requests() []contracts.SpawnRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]contracts.SpawnRequest(nil), f.reqs...)
+}
+
+func status(r *Runner, sessionID, state string) {
+	data := []byte(fmt.Sprintf(`{"ts":"2026-01-01T00:00:00Z","type":"status","state":%q}`, state))
+	r.HandleEvent(context.Background(), sessionID, events.RawEvent{Type: "status"}, data)
+	r.Wait()
+}
+
+func stage(id string, after ...string) contracts.PipelineStage {
+	return contracts.PipelineStage{
+		ID:    id,
+		After: after,
+		Spawn: contracts.SpawnRequest{Targets: map[string]int{"claude": 1}, Prompt: id},
+	}
+}
+
+func newTestRunner(t *testing.T) (*Runner, *fakeSpawner) {
+	t.Helper()
+	f := &fakeSpawner{}
+	r := NewRunner(context.Background(), filepath.Join(t.TempDir(), "pipeline-runs.json"), nil)
+	r.SetSpawnFunc(f.spawn)
+	return r, f
+}
+
+func stageStatus(t *testing.T, r *Runner, runID, stageID string) contracts.PipelineStageStatus {
+	t.Helper()
+	run, ok := r.Get(runID)
+	if !ok {
+		t.Fatalf("run %s not found", runID)
+	}
+	for _, st := range run.Stages {
+		if st.StageID == stageID {
+			return st.Status
+		}
+	}
+	t.Fatalf("stage %s not found", stageID)
+	return ""
+}
+
+func runStatus(t *testing.T, r *Runner, runID string) contracts.PipelineRunStatus {
+	t.Helper()
+	run, _ := r.Get(runID)
+	return run.Status
+}
+
+func TestRunner_LinearChain(t *testing.T) {
+	r, f := newTestRunner(t)
+	run, err := r.Start("repo", contracts.Pipeline{
+		Name:   "chain",
+		Repo:   "git@example.com:repo.git",
+		Branch: "main",
+		Stages: []contracts.PipelineStage{stage("impl"), stage("review", "impl")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Wait()
+
+	reqs := f.requests()
+	if len(reqs) != 1 || reqs[0].Prompt != "impl" {
+		t.Fatalf("expected only impl spawned, got %+v", reqs)
+	}
+	if reqs[0].Repo != "git@example.com:repo.git" || reqs[0].Branch != "main" {
+		t.Errorf("stage did not inherit pipeline repo/branch: %+v", reqs[0])
+	}
+
+	// Intermediate states do not fire edges.
+	status(r, "s-1", "working")
+	if got := len(f.requests()); got != 1 {
+		t.Fatalf("working status spawned downstream stage")
+	}
+
+	status(r, "s-1", "completed")
+	if got := stageStatus(t, r, run.ID, "impl"); got != contracts.PipelineStageSucceeded {
+		t.Errorf("impl = %s, want succeeded", got)
+	}
+	if got := len(f.requests()); got != 2 {
+		t.Fatalf("expected review spawned, got %d requests", got)
+	}
+
+	status(r, "s-2", "completed")
+	if got := runStatus(t, r, run.ID); got != contracts.PipelineRunCompleted {
+		t.Errorf("run = %s, want completed", got)
+	}
+}
+
+func TestRunner_FanInWaitsForAllUpstream(t *testing.T) {
+	r, f := newTestRunner(t)
+	run, err := r.Start("repo", contracts.Pipeline{
+		Name:   "fan-in",
+		Stages: []contracts.PipelineStage{stage("a"), stage("b"), stage("merge", "a", "b")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Wait()
+	if got := len(f.requests()); got != 2 {
+		t.Fatalf("expected both roots spawned, got %d", got)
+	}
+	status(r, "s-1", "completed")
+	if got := stageStatus(t, r, run.ID, "merge"); got != contracts.PipelineStagePending {
+		t.Errorf("merge = %s before both upstreams finished", got)
+	}
+	status(r, "s-2", "completed")
+	if got := stageStatus(t, r, run.ID, "merge"); got != contracts.PipelineStageRunning {
+		t.Errorf("merge = %s, want running", got)
+	}
+}
+
+func TestRunner_SucceedOnNeedsTesting(t *testing.T) {
+	r, f := newTestRunner(t)
+	impl := stage("impl")
+	impl.SucceedOn = []contracts.PipelineTrigger{contracts.PipelineTriggerNeedsTesting}
+	run, _ := r.Start("repo", contracts.Pipeline{
+		Name:   "nt",
+		Stages: []contracts.PipelineStage{impl, stage("test", "impl")},
+	})
+	r.Wait()
+	status(r, "s-1", "completed")
+	if got := len(f.requests()); got != 1 {
+		t.Fatalf("completed should not fire a needs_testing edge")
+	}
+	status(r, "s-1", "needs_testing")
+	if got := stageStatus(t, r, run.ID, "test"); got != contracts.PipelineStageRunning {
+		t.Errorf("test = %s, want running", got)
+	}
+}
+
+func TestRunner_FailureHalts(t *testing.T) {
+	r, _ := newTestRunner(t)
+	run, _ := r.Start("repo", contracts.Pipeline{
+		Name:   "halt",
+		Stages: []contracts.PipelineStage{stage("impl"), stage("review", "impl")},
+	})
+	r.Wait()
+	status(r, "s-1", "error")
+	if got := runStatus(t, r, run.ID); got != contracts.PipelineRunFailed {
+		t.Errorf("run = %s, want failed", got)
+	}
+	if got := stageStatus(t, r, run.ID, "review"); got != contracts.PipelineStageSkipped {
+		t.Errorf("review = %s, want skipped", got)
+	}
+}
+
+func TestRunner_FailureBranchesToAlternate(t *testing.T) {
+	r, f := newTestRunner(t)
+	impl := stage("impl")
+	impl.OnFailure = "fix"
+	fix := stage("fix")
+	fix.ReuseWorkspace = true
+	run, _ := r.Start("repo", contracts.Pipeline{
+		Name:   "branch",
+		Stages: []contracts.PipelineStage{impl, fix, stage("review", "impl")},
+	})
+	r.Wait()
+	if got := len(f.requests()); got != 1 {
+		t.Fatalf("alternate stage must not start as a root, got %d spawns", got)
+	}
+
+	status(r, "s-1", "error")
+	if got := runStatus(t, r, run.ID); got != contracts.PipelineRunRunning {
+		t.Fatalf("run = %s, want running after branching", got)
+	}
+	reqs := f.requests()
+	if len(reqs) != 2 || reqs[1].Prompt != "fix" {
+		t.Fatalf("expected fix stage spawned, got %+v", reqs)
+	}
+	if reqs[1].WorkspaceID != "ws-1" {
+		t.Errorf("fix should reuse the failed stage's workspace, got %q", reqs[1].WorkspaceID)
+	}
+
+	// The alternate recovering lets downstream of the failed stage proceed.
+	status(r, "s-2", "completed")
+	if got := stageStatus(t, r, run.ID, "review"); got != contracts.PipelineStageRunning {
+		t.Errorf("review = %s, want running", got)
+	}
+	status(r, "s-3", "completed")
+	if got := runStatus(t, r, run.ID); got != contracts.PipelineRunCompleted {
+		t.Errorf("run = %s, want completed", got)
+	}
+}
+
+func TestRunner_SpawnErrorFailsStage(t *testing.T) {
+	r, f := newTestRunner(t)
+	f.failWith = errors.New("boom")
+	run, _ := r.Start("repo", contracts.Pipeline{
+		Name:   "spawn-fail",
+		Stages: []contracts.PipelineStage{stage("impl")},
+	})
+	r.Wait()
+	got, _ := r.Get(run.ID)
+	if got.Status != contracts.PipelineRunFailed {
+		t.Errorf("run = %s, want failed", got.Status)
+	}
+	if got.Stages[0].Error != "boom" {
+		t.Errorf("stage error = %q, want boom", got.Stages[0].Error)
+	}
+}
+
+func TestRunner_Cancel(t *testing.T) {
+	r, _ := newTestRunner(t)
+	run, _ := r.Start("repo", contracts.Pipeline{
+		Name:   "cancel",
+		Stages: []contracts.PipelineStage{stage("impl"), stage("review", "impl")},
+	})
+	r.Wait()
+	if err := r.Cancel(run.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Cancel(run.ID); !errors.Is(err, ErrRunFinished) {
+		t.Errorf("second cancel err = %v, want ErrRunFinished", err)
+	}
+	status(r, "s-1", "completed")
+	if got := stageStatus(t, r, run.ID, "review"); got != contracts.PipelineStageSkipped {
+		t.Errorf("review = %s after cancel, want skipped", got)
+	}
+}
+
+func TestRunner_SessionInfoAndPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pipeline-runs.json")
+	f := &fakeSpawner{}
+	r := NewRunner(context.Background(), path, nil)
+	r.SetSpawnFunc(f.spawn)
+	run, _ := r.Start("repo", contracts.Pipeline{
+		Name:   "persist",
+		Stages: []contracts.PipelineStage{stage("impl"), stage("review", "impl")},
+	})
+	r.Wait()
+
+	info, ok := r.SessionInfo("s-1")
+	if !ok {
+		t.Fatal("expected pipeline info for s-1")
+	}
+	if info.RunID != run.ID || info.StageID != "impl" || info.StageCount != 2 {
+		t.Errorf("unexpected info: %+v", info)
+	}
+
+	// A fresh runner picks the run up and keeps advancing it.
+	r2 := NewRunner(context.Background(), path, nil)
+	r2.SetSpawnFunc(f.spawn)
+	status(r2, "s-1", "completed")
+	if got := stageStatus(t, r2, run.ID, "review"); got != contracts.PipelineStageRunning {
+		t.Errorf("review = %s after reload, want running", got)
+	}
+}
+
+func TestRunner_ShutdownStopsStageSpawn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pipeline-runs.json")
+	ctx, cancel := context.WithCancel(context.Background())
+	r := NewRunner(ctx, path, nil)
+	started := make(chan struct{})
+	r.SetSpawnFunc(func(ctx context.Context, _ contracts.SpawnRequest) ([]SpawnedSession, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	run, err := r.Start("repo", contracts.Pipeline{
+		Name:   "shutdown",
+		Stages: []contracts.PipelineStage{stage("impl")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	cancel()
+	r.Wait()
+
+	// The spawn is left in flight on disk, not failed as a spawn error.
+	if got := stageStatus(t, r, run.ID, "impl"); got != contracts.PipelineStageSpawning {
+		t.Errorf("impl = %s after shutdown, want spawning", got)
+	}
+	r2 := NewRunner(context.Background(), path, nil)
+	got, _ := r2.Get(run.ID)
+	if got.Status != contracts.PipelineRunFailed || got.Stages[0].Error != "interrupted by daemon restart" {
+		t.Errorf("after restart: status %s, stage error %q", got.Status, got.Stages[0].Error)
+	}
+}
@@ -0,0 +1,217 @@
+package ptyhost
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/schmuxdir"
+)
+
+// ErrNoSession is returned when no supervisor is listening for a session.
+var ErrNoSession = errors.New("pty session not found")
+
+// SocketDir returns the directory holding supervisor sockets.
+func SocketDir() string {
+	return filepath.Join(schmuxdir.Get(), "pty")
+}
+
+// SocketPath returns the supervisor socket for a session.
+func SocketPath(sessionID string) string {
+	return filepath.Join(SocketDir(), sessionID+".sock")
+}
+
+// Conn is a client connection to a supervisor.
+type Conn struct {
+	conn  net.Conn
+	r     *bufio.Reader
+	wmu   sync.Mutex
+	hello Hello
+}
+
+// Dial connects to the supervisor at socketPath and reads its hello frame.
+// The next frame is always FrameReplay (or FrameExit if the process ended).
+// Returns ErrNoSession when nothing is listening.
+func Dial(socketPath string) (*Conn, error) {
+	conn, err := net.DialTimeout("unix", socketPath, 2*time.Second)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, fmt.Errorf("%w: %s", ErrNoSession, socketPath)
+		}
+		return nil, err
+	}
+	c := &Conn{conn: conn, r: bufio.NewReaderSize(conn, 64*1024)}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	f, err := ReadFrame(c.r)
+	conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read hello: %w", err)
+	}
+	switch f.Type {
+	case FrameHello:
+		if err := json.Unmarshal(f.Payload, &c.hello); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("invalid hello: %w", err)
+		}
+	case FrameExit:
+		conn.Close()
+		return nil, fmt.Errorf("%w: process exited", ErrNoSession)
+	default:
+		conn.Close()
+		return nil, fmt.Errorf("unexpected first frame %q", f.Type)
+	}
+	return c, nil
+}
+
+// Hello returns the supervisor's description of the process.
+func (c *Conn) Hello() Hello { return c.hello }
+
+// ReadFrame returns the next frame from the supervisor.
+func (c *Conn) ReadFrame() (Frame, error) { return ReadFrame(c.r) }
+
+// Write sends raw input bytes to the PTY.
+func (c *Conn) Write(p []byte) (int, error) {
+	if err := c.send(FrameInput, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Resize changes the PTY window size.
+func (c *Conn) Resize(cols, rows int) error {
+	data, err := json.Marshal(Size{Cols: cols, Rows: rows})
+	if err != nil {
+		return err
+	}
+	return c.send(FrameResize, data)
+}
+
+// Kill asks the supervisor to hang up the agent's process group.
+func (c *Conn) Kill() error { return c.send(FrameKill, nil) }
+
+// Close closes the connection. The supervised process keeps running.
+func (c *Conn) Close() error { return c.conn.Close() }
+
+func (c *Conn) send(typ byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	defer c.conn.SetWriteDeadline(time.Time{})
+	return WriteFrame(c.conn, typ, payload)
+}
+
+// Alive reports whether a supervisor is serving socketPath.
+func Alive(socketPath string) bool {
+	c, err := Dial(socketPath)
+	if err != nil {
+		return false
+	}
+	c.Close()
+	return true
+}
+
+// Kill hangs up the session served at socketPath and waits, bounded by ctx,
+// for the process to exit. A session that is already gone is not an error.
+func Kill(ctx context.Context, socketPath string) error {
+	c, err := Dial(socketPath)
+	if errors.Is(err, ErrNoSession) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		c.conn.SetReadDeadline(deadline)
+	}
+	if err := c.Kill(); err != nil {
+		return fmt.Errorf("failed to send kill: %w", err)
+	}
+	for {
+		f, err := c.ReadFrame()
+		if errors.Is(err, io.EOF) {
+			// The supervisor also drops clients that fall behind, so EOF
+			// alone does not prove the process is gone.
+			if Alive(socketPath) {
+				return fmt.Errorf("pty session still running after kill")
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("waiting for pty session to exit: %w", err)
+		}
+		if f.Type == FrameExit {
+			return nil
+		}
+	}
+}
+
+// SpawnOptions configures Spawn.
+type SpawnOptions struct {
+	Executable string // schmux binary that implements `pty-host`
+	SessionID  string
+	Dir        string
+	Command    string
+	Cols, Rows int
+}
+
+// Spawn launches a detached supervisor (`schmux pty-host`) for a session and
+// waits for its socket to accept connections. The supervisor runs in its own
+// process session so it outlives the daemon. Returns the agent's PID.
+func Spawn(ctx context.Context, opts SpawnOptions) (int, error) {
+	if err := os.MkdirAll(SocketDir(), 0700); err != nil {
+		return 0, fmt.Errorf("failed to create pty socket directory: %w", err)
+	}
+	socketPath := SocketPath(opts.SessionID)
+	args := []string{"pty-host",
+		"--session", opts.SessionID,
+		"--socket", socketPath,
+		"--dir", opts.Dir,
+		"--cols", strconv.Itoa(opts.Cols),
+		"--rows", strconv.Itoa(opts.Rows),
+		"--", opts.Command,
+	}
+	cmd := exec.Command(opts.Executable, args...)
+	cmd.Dir = opts.Dir
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start pty supervisor: %w", err)
+	}
+	exited := make(chan error, 1)
+	// Reap the supervisor if it exits while this process is still alive.
+	go func() { exited <- cmd.Wait() }()
+
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(10 * time.Second)
+	for {
+		if c, err := Dial(socketPath); err == nil {
+			pid := c.Hello().Pid
+			c.Close()
+			return pid, nil
+		}
+		select {
+		case err := <-exited:
+			return 0, fmt.Errorf("pty supervisor exited during startup: %v", err)
+		case <-timeout:
+			cmd.Process.Kill()
+			return 0, fmt.Errorf("pty supervisor did not become ready")
+		case <-ctx.Done():
+			cmd.Process.Kill()
+			return 0, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
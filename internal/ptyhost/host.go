@@ -0,0 +1,335 @@
+package ptyhost
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/creack/pty"
+)
+
+// DefaultReplayBytes is how much recent output the supervisor keeps for
+// clients that connect (or reconnect) after the output was produced.
+const DefaultReplayBytes = 1 << 20
+
+// killGrace is how long a killed process group gets between SIGHUP and SIGKILL.
+const killGrace = 5 * time.Second
+
+// clientQueueFrames bounds the frames buffered for one client. A client that
+// falls this far behind is disconnected; it reconnects and resyncs from the
+// replay buffer instead of stalling the PTY for everyone.
+const clientQueueFrames = 512
+
+// Options configures a supervisor.
+type Options struct {
+	SessionID   string
+	SocketPath  string
+	Dir         string // working directory of the agent
+	Command     string // run via /bin/sh -c, like a tmux pane
+	Cols, Rows  int
+	ReplayBytes int // defaults to DefaultReplayBytes
+	Logger      *log.Logger
+}
+
+// Host owns one PTY and the socket clients use to reach it.
+type Host struct {
+	opts     Options
+	cmd      *exec.Cmd
+	ptmx     *os.File
+	listener net.Listener
+
+	mu      sync.Mutex
+	replay  []byte
+	size    Size
+	clients map[*hostClient]struct{}
+	exited  bool
+	exit    Exit
+}
+
+type hostClient struct {
+	conn  net.Conn
+	queue chan Frame
+	once  sync.Once
+	done  chan struct{}
+}
+
+func (c *hostClient) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// Start launches the command under a PTY and begins listening on the
+// socket. It returns once both are ready; Wait blocks until the command exits.
+func Start(opts Options) (*Host, error) {
+	if opts.Cols <= 0 || opts.Rows <= 0 {
+		opts.Cols, opts.Rows = 80, 24
+	}
+	if opts.ReplayBytes <= 0 {
+		opts.ReplayBytes = DefaultReplayBytes
+	}
+
+	// A leftover socket from a crashed supervisor would make Listen fail.
+	if err := os.Remove(opts.SocketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	listener, err := net.Listen("unix", opts.SocketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", opts.SocketPath, err)
+	}
+	if err := os.Chmod(opts.SocketPath, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", opts.Command)
+	cmd.Dir = opts.Dir
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color")
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: uint16(opts.Cols), Rows: uint16(opts.Rows)})
+	if err != nil {
+		listener.Close()
+		os.Remove(opts.SocketPath)
+		return nil, fmt.Errorf("failed to start command under pty: %w", err)
+	}
+
+	h := &Host{
+		opts:     opts,
+		cmd:      cmd,
+		ptmx:     ptmx,
+		listener: listener,
+		size:     Size{Cols: opts.Cols, Rows: opts.Rows},
+		clients:  make(map[*hostClient]struct{}),
+	}
+	go h.acceptLoop()
+	return h, nil
+}
+
+// Pid returns the supervised process's PID.
+func (h *Host) Pid() int {
+	return h.cmd.Process.Pid
+}
+
+// Wait pumps PTY output until the command exits, notifies connected clients,
+// and tears down the socket. It returns the command's exit code.
+func (h *Host) Wait() int {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := h.ptmx.Read(buf)
+		if n > 0 {
+			h.broadcast(buf[:n])
+		}
+		if err != nil {
+			// EIO once the last process holding the slave side exits.
+			break
+		}
+	}
+
+	code := 0
+	if err := h.cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		} else {
+			code = -1
+		}
+	}
+	h.mu.Lock()
+	// Closed under h.mu so a concurrent resize never sees a closed descriptor.
+	h.ptmx.Close()
+	h.exited = true
+	h.exit = Exit{Code: code}
+	clients := make([]*hostClient, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+
+	h.listener.Close()
+	os.Remove(h.opts.SocketPath)
+
+	// Best-effort exit notice; a client that is not draining is dropped.
+	data, _ := json.Marshal(Exit{Code: code})
+	for _, c := range clients {
+		select {
+		case c.queue <- Frame{Type: FrameExit, Payload: data}:
+		default:
+			c.close()
+		}
+	}
+	deadline := time.After(2 * time.Second)
+	for _, c := range clients {
+		select {
+		case <-c.done:
+		case <-deadline:
+			c.close()
+		}
+	}
+	if h.opts.Logger != nil {
+		h.opts.Logger.Info("pty session exited", "session", h.opts.SessionID, "code", code)
+	}
+	return code
+}
+
+// broadcast appends output to the replay buffer and queues it for clients.
+func (h *Host) broadcast(data []byte) {
+	chunk := append([]byte(nil), data...)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.replay = append(h.replay, chunk...)
+	if over := len(h.replay) - h.opts.ReplayBytes; over > 0 {
+		trimmed := h.replay[over:]
+		// Start the replay at a line boundary so it does not open mid-sequence.
+		if i := bytes.IndexByte(trimmed, '\n'); i >= 0 && i < 4096 {
+			trimmed = trimmed[i+1:]
+		}
+		h.replay = append([]byte(nil), trimmed...)
+	}
+	h.queueLocked(Frame{Type: FrameOutput, Payload: chunk})
+}
+
+// queueLocked queues f for every client, disconnecting any that are full.
+// Callers hold h.mu.
+func (h *Host) queueLocked(f Frame) {
+	for c := range h.clients {
+		select {
+		case c.queue <- f:
+		default:
+			delete(h.clients, c)
+			c.close()
+		}
+	}
+}
+
+func (h *Host) acceptLoop() {
+	for {
+		conn, err := h.listener.Accept()
+		if err != nil {
+			return
+		}
+		go h.serve(conn)
+	}
+}
+
+func (h *Host) serve(conn net.Conn) {
+	c := &hostClient{conn: conn, queue: make(chan Frame, clientQueueFrames), done: make(chan struct{})}
+	defer c.close()
+
+	// Registering and snapshotting under one lock means every byte is either
+	// in the replay or queued live, never both and never neither.
+	h.mu.Lock()
+	if h.exited {
+		exit := h.exit
+		h.mu.Unlock()
+		writeJSONFrame(conn, FrameExit, exit)
+		return
+	}
+	hello := Hello{SessionID: h.opts.SessionID, Pid: h.cmd.Process.Pid, Cols: h.size.Cols, Rows: h.size.Rows}
+	replay := append([]byte(nil), h.replay...)
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		h.mu.Unlock()
+	}()
+
+	go h.readClient(c)
+
+	w := bufio.NewWriter(conn)
+	if err := writeJSONFrame(w, FrameHello, hello); err != nil {
+		return
+	}
+	if err := WriteFrame(w, FrameReplay, replay); err != nil {
+		return
+	}
+	if err := w.Flush(); err != nil {
+		return
+	}
+	for {
+		select {
+		case f := <-c.queue:
+			exit := f.Type == FrameExit
+			if err := WriteFrame(w, f.Type, f.Payload); err != nil {
+				return
+			}
+			// Coalesce whatever else is already queued into one flush.
+			for drained := exit; !drained; {
+				select {
+				case f := <-c.queue:
+					exit = f.Type == FrameExit
+					drained = exit
+					if err := WriteFrame(w, f.Type, f.Payload); err != nil {
+						return
+					}
+				default:
+					drained = true
+				}
+			}
+			if err := w.Flush(); err != nil || exit {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (h *Host) readClient(c *hostClient) {
+	defer c.close()
+	r := bufio.NewReader(c.conn)
+	for {
+		f, err := ReadFrame(r)
+		if err != nil {
+			return
+		}
+		switch f.Type {
+		case FrameInput:
+			if _, err := h.ptmx.Write(f.Payload); err != nil {
+				return
+			}
+		case FrameResize:
+			var sz Size
+			if err := json.Unmarshal(f.Payload, &sz); err != nil || sz.Cols <= 0 || sz.Rows <= 0 {
+				continue
+			}
+			h.mu.Lock()
+			if !h.exited {
+				if err := pty.Setsize(h.ptmx, &pty.Winsize{Cols: uint16(sz.Cols), Rows: uint16(sz.Rows)}); err == nil {
+					// Tell every client, so emulators track a resize made by another.
+					h.size = sz
+					h.queueLocked(f)
+				}
+			}
+			h.mu.Unlock()
+		case FrameKill:
+			h.kill()
+		}
+	}
+}
+
+// kill hangs up the agent's process group, the way tmux kill-session does,
+// and escalates to SIGKILL if it is still alive after killGrace.
+func (h *Host) kill() {
+	pgid := h.cmd.Process.Pid // pty.Start makes the child a session leader
+	syscall.Kill(-pgid, syscall.SIGHUP)
+	go func() {
+		time.Sleep(killGrace)
+		h.mu.Lock()
+		exited := h.exited
+		h.mu.Unlock()
+		if !exited {
+			syscall.Kill(-pgid, syscall.SIGKILL)
+		}
+	}()
+}
@@ -0,0 +1,102 @@
+// Package ptyhost runs a session's agent under a pseudo-terminal owned by a
+// small detached supervisor process instead of tmux. The supervisor
+// (`schmux pty-host`) keeps the PTY open across daemon restarts, retains a
+// bounded replay buffer of recent output, and serves any number of clients
+// (the daemon's session source, `schmux attach`) over a unix socket.
+package ptyhost
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Frame types. Every frame is a one-byte type, a big-endian uint32 payload
+// length, then the payload.
+const (
+	// Supervisor -> client.
+	FrameHello  byte = 'h' // JSON Hello, always the first frame on a connection
+	FrameReplay byte = 'p' // buffered output from before the connection, sent once after hello
+	FrameOutput byte = 'o' // live PTY output
+	FrameExit   byte = 'x' // JSON Exit; the supervisor closes the connection afterwards
+
+	// Both directions: a client requests a size; the supervisor echoes
+	// applied sizes to every client.
+	FrameResize byte = 'r' // JSON Size
+
+	// Client -> supervisor.
+	FrameInput byte = 'i' // raw bytes written to the PTY
+	FrameKill  byte = 'k' // hang up the agent's process group
+)
+
+// maxFramePayload bounds a single frame so a corrupt length cannot make a
+// reader allocate unbounded memory.
+const maxFramePayload = 8 << 20
+
+// Hello describes the supervised process to a newly connected client.
+type Hello struct {
+	SessionID string `json:"session_id"`
+	Pid       int    `json:"pid"`
+	Cols      int    `json:"cols"`
+	Rows      int    `json:"rows"`
+}
+
+// Exit reports how the supervised process ended.
+type Exit struct {
+	Code int `json:"code"`
+}
+
+// Size is a PTY window size.
+type Size struct {
+	Cols int `json:"cols"`
+	Rows int `json:"rows"`
+}
+
+// Frame is one decoded protocol message.
+type Frame struct {
+	Type    byte
+	Payload []byte
+}
+
+// WriteFrame encodes a frame to w.
+func WriteFrame(w io.Writer, typ byte, payload []byte) error {
+	var hdr [5]byte
+	hdr[0] = typ
+	binary.BigEndian.PutUint32(hdr[1:], uint32(len(payload)))
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// writeJSONFrame encodes v as a frame payload.
+func writeJSONFrame(w io.Writer, typ byte, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return WriteFrame(w, typ, data)
+}
+
+// ReadFrame decodes the next frame from r.
+func ReadFrame(r *bufio.Reader) (Frame, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return Frame{}, err
+	}
+	n := binary.BigEndian.Uint32(hdr[1:])
+	if n > maxFramePayload {
+		return Frame{}, fmt.Errorf("frame payload too large: %d bytes", n)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return Frame{}, err
+	}
+	return Frame{Type: hdr[0], Payload: payload}, nil
+}
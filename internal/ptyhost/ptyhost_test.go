@@ -0,0 +1,172 @@
+package ptyhost
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFrame(&buf, FrameOutput, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFrame(&buf, FrameKill, nil); err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(&buf)
+	f, err := ReadFrame(r)
+	if err != nil || f.Type != FrameOutput || string(f.Payload) != "hello" {
+		t.Fatalf("first frame = %q %q, %v", f.Type, f.Payload, err)
+	}
+	f, err = ReadFrame(r)
+	if err != nil || f.Type != FrameKill || len(f.Payload) != 0 {
+		t.Fatalf("second frame = %q %q, %v", f.Type, f.Payload, err)
+	}
+}
+
+func TestReadFrameRejectsOversizedPayload(t *testing.T) {
+	hdr := []byte{FrameOutput, 0xff, 0xff, 0xff, 0xff}
+	if _, err := ReadFrame(bufio.NewReader(bytes.NewReader(hdr))); err == nil {
+		t.Fatal("expected error for oversized frame")
+	}
+}
+
+func startHost(t *testing.T, command string) (*Host, string, <-chan int) {
+	t.Helper()
+	sock := filepath.Join(t.TempDir(), "s.sock")
+	h, err := Start(Options{SessionID: "s1", SocketPath: sock, Dir: t.TempDir(), Command: command, Cols: 100, Rows: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Closed after the exit code is sent, so both a test and the cleanup can wait.
+	done := make(chan int, 1)
+	go func() {
+		done <- h.Wait()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		Kill(context.Background(), sock)
+		<-done
+	})
+	return h, sock, done
+}
+
+// readUntil reads frames until those of type typ contain want. Replay and
+// output both count as output, since a fast process may finish writing
+// before the client connects.
+func readUntil(t *testing.T, c *Conn, typ byte, want string) {
+	t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	defer c.conn.SetReadDeadline(time.Time{})
+	var seen strings.Builder
+	for {
+		f, err := c.ReadFrame()
+		if err != nil {
+			t.Fatalf("waiting for %q in %q frames: %v (saw %q)", want, typ, err, seen.String())
+		}
+		if f.Type != typ && !(typ == FrameOutput && f.Type == FrameReplay) {
+			continue
+		}
+		seen.Write(f.Payload)
+		if strings.Contains(seen.String(), want) {
+			return
+		}
+	}
+}
+
+func TestHost_ReplayInputAndResize(t *testing.T) {
+	h, sock, _ := startHost(t, "echo ready; cat")
+
+	first, err := Dial(sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	if hello := first.Hello(); hello.SessionID != "s1" || hello.Pid != h.Pid() || hello.Cols != 100 || hello.Rows != 30 {
+		t.Errorf("hello = %+v", hello)
+	}
+	readUntil(t, first, FrameOutput, "ready")
+
+	if _, err := first.Write([]byte("ping\r")); err != nil {
+		t.Fatal(err)
+	}
+	readUntil(t, first, FrameOutput, "ping")
+
+	// A later client gets everything so far as replay.
+	second, err := Dial(sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+	readUntil(t, second, FrameReplay, "ping")
+
+	// A resize from one client is echoed to all of them.
+	if err := second.Resize(120, 40); err != nil {
+		t.Fatal(err)
+	}
+	readUntil(t, first, FrameResize, `"cols":120`)
+}
+
+func TestHost_ExitAndKill(t *testing.T) {
+	_, sock, done := startHost(t, "exit 3")
+	select {
+	case code := <-done:
+		if code != 3 {
+			t.Errorf("exit code = %d, want 3", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("host did not exit")
+	}
+	if _, err := Dial(sock); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Dial after exit = %v, want ErrNoSession", err)
+	}
+	if Alive(sock) {
+		t.Error("Alive after exit")
+	}
+
+	_, sock, done = startHost(t, "sleep 60")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := Kill(ctx, sock); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("host still running after Kill")
+	}
+	if err := Kill(ctx, sock); err != nil {
+		t.Errorf("Kill of a gone session = %v, want nil", err)
+	}
+}
+
+func TestHost_ExitFrame(t *testing.T) {
+	_, sock, _ := startHost(t, "read line; exit 7")
+	c, err := Dial(sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.Write([]byte("go\r"))
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		f, err := c.ReadFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Type == FrameExit {
+			var exit Exit
+			if err := json.Unmarshal(f.Payload, &exit); err != nil || exit.Code != 7 {
+				t.Errorf("exit = %s (%v), want code 7", f.Payload, err)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// Package redact masks secrets -- API keys, tokens, private keys -- in text
+// that leaves the terminal: session recordings, captures, and prompts sent
+// to LLMs. Built-in detectors cover common token formats; config adds
+// user regexes under redaction.patterns. Each match is replaced with
+// "[REDACTED:<detector>]" and counted per site and detector.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+)
+
+// Sites where text is redacted. Counters are kept per site.
+const (
+	SiteRecording  = "recording"
+	SiteScrollback = "scrollback"
+	SiteCapture    = "capture"
+	SiteNudgeNik   = "nudgenik"
+	SiteOneshot    = "oneshot"
+	SiteTell       = "tell"
+)
+
+// detector finds one kind of secret. When re has a capture group only the
+// first group is replaced, so "Authorization: Bearer <token>" keeps its
+// header name.
+type detector struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// builtins are checked in order; a match is replaced before later
+// detectors run, so the more specific formats come first.
+var builtins = []detector{
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?s:.*?)-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"aws_secret_key", regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[=:]\s*["']?([A-Za-z0-9/+=]{40})`)},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})`)},
+	{"anthropic_key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+	{"openai_key", regexp.MustCompile(`\bsk-(?:proj-|svcacct-|admin-)?[A-Za-z0-9_-]{32,}`)},
+	{"slack_token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"google_api_key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{"stripe_key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{20,}`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{"bearer_token", regexp.MustCompile(`(?i)\bauthorization:\s*bearer\s+([A-Za-z0-9._~+/=-]{16,})`)},
+	{"url_password", regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^\s:/@]+:([^\s@/]+)@`)},
+}
+
+// Redactor masks the matches of a fixed set of detectors.
+type Redactor struct {
+	detectors []detector
+}
+
+// New returns a redactor with the built-in detectors followed by patterns.
+func New(patterns []config.RedactionPattern) (*Redactor, error) {
+	r := &Redactor{detectors: append([]detector(nil), builtins...)}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("redaction pattern %s: %w", p.Name, err)
+		}
+		r.detectors = append(r.detectors, detector{p.Name, re})
+	}
+	return r, nil
+}
+
+// Redact returns s with every match replaced, and the number of matches by
+// detector name. The map is nil when nothing matched.
+func (r *Redactor) Redact(s string) (string, map[string]int) {
+	parts, hits := r.redactParts(s)
+	return parts[0], hits[0]
+}
+
+// redactParts redacts the concatenation of parts, so a secret split between
+// two of them is still found, and cuts the result back into parts, with the
+// matches of each. The mask of a secret that spans parts goes into the part
+// where the secret starts, and the rest of the secret is dropped from the
+// parts after it.
+func (r *Redactor) redactParts(parts ...string) ([]string, []map[string]int) {
+	s := strings.Join(parts, "")
+	cuts := make([]int, len(parts)-1) // end of each part but the last
+	pos := 0
+	for i := range cuts {
+		pos += len(parts[i])
+		cuts[i] = pos
+	}
+	hits := make([]map[string]int, len(parts))
+	type span struct {
+		start, end int
+		mask       string
+	}
+	for _, d := range r.detectors {
+		var spans []span
+		for _, m := range d.re.FindAllStringSubmatchIndex(s, -1) {
+			start, end := m[0], m[1]
+			if len(m) >= 4 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			if start == end {
+				continue
+			}
+			spans = append(spans, span{start, end, "[REDACTED:" + d.name + "]"})
+			part := 0
+			for part < len(cuts) && cuts[part] <= start {
+				part++
+			}
+			if hits[part] == nil {
+				hits[part] = make(map[string]int)
+			}
+			hits[part][d.name]++
+		}
+		if len(spans) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, sp := range spans {
+			b.WriteString(s[last:sp.start])
+			b.WriteString(sp.mask)
+			last = sp.end
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+		for i, c := range cuts {
+			delta := 0
+			for _, sp := range spans {
+				if sp.start >= c {
+					break
+				}
+				if sp.end > c {
+					delta = sp.start + delta + len(sp.mask) - c
+					break
+				}
+				delta += len(sp.mask) - (sp.end - sp.start)
+			}
+			cuts[i] = c + delta
+		}
+	}
+	out := make([]string, len(parts))
+	last := 0
+	for i, c := range cuts {
+		c = max(c, last)
+		out[i] = s[last:c]
+		last = c
+	}
+	out[len(parts)-1] = s[last:]
+	return out, hits
+}
+
+var (
+	mu       sync.Mutex
+	cfg      *config.Config
+	current  *Redactor
+	patterns []config.RedactionPattern // the patterns current was built from
+	counts   = map[statKey]int64{}
+)
+
+type statKey struct{ site, detector string }
+
+// SetConfig makes String and Bytes follow cfg's redaction settings. It is
+// read on every call, so edits to the config apply without a restart.
+// Before SetConfig is called only the built-in detectors run.
+func SetConfig(c *config.Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	cfg = c
+}
+
+// String redacts s for site, counts the matches, and returns the result
+// with the number of redactions.
+func String(site, s string) (string, int) {
+	r := redactor()
+	if r == nil {
+		return s, 0
+	}
+	out, hits := r.Redact(s)
+	if hits == nil {
+		return s, 0
+	}
+	return out, addCounts(site, hits)
+}
+
+// addCounts adds hits to the counters of site and returns their total.
+func addCounts(site string, hits map[string]int) int {
+	mu.Lock()
+	defer mu.Unlock()
+	n := 0
+	for name, c := range hits {
+		counts[statKey{site, name}] += int64(c)
+		n += c
+	}
+	return n
+}
+
+// Bytes is String for byte slices. b is returned unchanged when nothing
+// matched.
+func Bytes(site string, b []byte) []byte {
+	out, n := String(site, string(b))
+	if n == 0 {
+		return b
+	}
+	return []byte(out)
+}
+
+// redactor returns the redactor for the current config, rebuilding it when
+// the patterns changed, or nil when redaction is disabled. A pattern that
+// does not compile (config validation rejects these) is skipped.
+func redactor() *Redactor {
+	mu.Lock()
+	defer mu.Unlock()
+	var want []config.RedactionPattern
+	if cfg != nil {
+		if !cfg.GetRedactionEnabled() {
+			return nil
+		}
+		want = cfg.GetRedactionPatterns()
+	}
+	if current != nil && slices.Equal(want, patterns) {
+		return current
+	}
+	var valid []config.RedactionPattern
+	for _, p := range want {
+		if _, err := regexp.Compile(p.Regex); err == nil {
+			valid = append(valid, p)
+		}
+	}
+	current, _ = New(valid)
+	patterns = want
+	return current
+}
+
+// Stat is the number of redactions one detector made at one site since the
+// daemon started.
+type Stat struct {
+	Site     string
+	Detector string
+	Count    int64
+}
+
+// Stats returns the redaction counters, sorted by site and detector.
+func Stats() []Stat {
+	mu.Lock()
+	defer mu.Unlock()
+	stats := make([]Stat, 0, len(counts))
+	for k, n := range counts {
+		stats = append(stats, Stat{Site: k.site, Detector: k.detector, Count: n})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Site != stats[j].Site {
+			return stats[i].Site < stats[j].Site
+		}
+		return stats[i].Detector < stats[j].Detector
+	})
+	return stats
+}
@@ -0,0 +1,247 @@
+package redact
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// streamTailMax caps the bytes a Stream or Chunks holds back, and the end of
+// the line kept as context. A longer run of non-space bytes is let through
+// as it comes.
+const streamTailMax = 4096
+
+// pemSkipMax caps how much output after an unterminated private key header
+// is dropped while waiting for its END line, well above any real key.
+const pemSkipMax = 64 << 10
+
+var (
+	pemBegin = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+	pemEnd   = regexp.MustCompile(`-----END [A-Z ]*PRIVATE KEY-----`)
+)
+
+// pemGuard masks private keys that span chunks, which line context cannot
+// catch: from a BEGIN header whose END has not arrived, the chunk is masked
+// and the output after it dropped up to the END line, or pemSkipMax bytes.
+type pemGuard struct {
+	open    bool
+	skipped int
+	tail    string // end of the dropped output, where an END line may start
+}
+
+// filter returns out, the redaction of the raw chunk raw that follows
+// context, with any private key still open masked or dropped, and counts
+// what it masks under site.
+func (p *pemGuard) filter(site, context, raw, out string) string {
+	if p.open {
+		joined := p.tail + out
+		loc := pemEnd.FindStringIndex(joined)
+		if loc == nil {
+			p.skip(joined, len(out))
+			return ""
+		}
+		out = joined[loc[1]:]
+		raw, context = out, ""
+		*p = pemGuard{}
+	}
+	joined := context + raw
+	locs := pemBegin.FindAllStringIndex(joined, -1)
+	if len(locs) == 0 || pemEnd.MatchString(joined[locs[len(locs)-1][1]:]) {
+		return out
+	}
+	start := 0
+	if begins := pemBegin.FindAllStringIndex(out, -1); len(begins) > 0 && locs[len(locs)-1][0] >= len(context) {
+		start = begins[len(begins)-1][0]
+	}
+	p.open = true
+	p.skip(out[start:], len(out)-start)
+	addCounts(site, map[string]int{"private_key": 1})
+	return out[:start] + "[REDACTED:private_key]"
+}
+
+// skip records n dropped bytes ending in dropped, and gives up on the END
+// line past pemSkipMax.
+func (p *pemGuard) skip(dropped string, n int) {
+	p.skipped += n
+	if len(dropped) > 64 {
+		dropped = dropped[len(dropped)-64:]
+	}
+	p.tail = dropped
+	if p.skipped > pemSkipMax {
+		*p = pemGuard{}
+	}
+}
+
+// Stream redacts output that arrives in chunks, such as a terminal's, so a
+// secret split across two chunks is still masked. Write holds back the
+// trailing run of non-space bytes of each chunk, which may be the start of a
+// token the next chunk completes, and matches each chunk together with the
+// end of the line already emitted, for detectors that need the text before
+// a secret (e.g. "Authorization: Bearer"). Private keys, which span lines,
+// go through a pemGuard. A Stream is not safe for concurrent use.
+type Stream struct {
+	site    string
+	context []byte // end of the current line, already emitted
+	tail    []byte // held back by the last Write
+	pem     pemGuard
+}
+
+// NewStream returns a stream that counts its redactions under site.
+func NewStream(site string) *Stream {
+	return &Stream{site: site}
+}
+
+// Write returns the redacted bytes of the held-back tail and data that can
+// be emitted now, and holds back the rest.
+func (s *Stream) Write(data []byte) []byte {
+	buf := make([]byte, 0, len(s.tail)+len(data))
+	buf = append(append(buf, s.tail...), data...)
+	n := len(buf) - tokenTailLen(buf)
+	s.tail = append(s.tail[:0], buf[n:]...)
+	return s.emit(buf[:n])
+}
+
+// Flush returns the held-back tail, redacted.
+func (s *Stream) Flush() []byte {
+	out := s.emit(s.tail)
+	s.tail = s.tail[:0]
+	return out
+}
+
+// Pending reports whether the stream is holding bytes back.
+func (s *Stream) Pending() bool {
+	return len(s.tail) > 0
+}
+
+func (s *Stream) emit(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	out := b
+	if r := redactor(); r != nil {
+		parts, hits := r.redactParts(string(s.context), string(b))
+		addCounts(s.site, hits[1])
+		if filtered := s.pem.filter(s.site, string(s.context), string(b), parts[1]); hits[1] != nil || filtered != string(b) {
+			out = []byte(filtered)
+		}
+	}
+	s.context = lineContext(s.context, b)
+	return out
+}
+
+// Chunks redacts output stored as numbered chunks, such as an output log's
+// entries, without moving bytes between chunks. A chunk that ends in a run
+// of non-space bytes is held until the next one and redacted together with
+// it. The mask of a secret that spans chunks goes into the chunk where the
+// secret starts, and private keys go through a pemGuard. Chunks is not safe
+// for concurrent use.
+type Chunks struct {
+	site    string
+	context []byte // end of the current line, already returned
+	held    []byte // raw data of the held chunk
+	holding bool
+	pem     pemGuard
+}
+
+// NewChunks returns a Chunks that counts its redactions under site.
+func NewChunks(site string) *Chunks {
+	return &Chunks{site: site}
+}
+
+// Add takes the next chunk. If a chunk was held, prev is that chunk,
+// redacted. When data is held in turn, held is true and cur is nil;
+// otherwise cur is data, redacted.
+func (c *Chunks) Add(data []byte) (prev, cur []byte, held bool) {
+	held = tokenTailLen(data) > 0
+	parts, hits := c.redact(c.held, data)
+	if c.holding {
+		prev = c.filter(c.held, parts[1])
+		addCounts(c.site, hits[1])
+		c.context = lineContext(c.context, c.held)
+	}
+	if held {
+		c.held = append(c.held[:0], data...)
+		c.holding = true
+		return prev, nil, true
+	}
+	cur = c.filter(data, parts[2])
+	addCounts(c.site, hits[2])
+	c.context = lineContext(c.context, data)
+	c.held, c.holding = c.held[:0], false
+	return prev, cur, false
+}
+
+// Flush returns the held chunk, redacted, or nil when none is held.
+func (c *Chunks) Flush() []byte {
+	if !c.holding {
+		return nil
+	}
+	parts, hits := c.redact(c.held)
+	out := c.filter(c.held, parts[1])
+	addCounts(c.site, hits[1])
+	c.context = lineContext(c.context, c.held)
+	c.held, c.holding = c.held[:0], false
+	return out
+}
+
+// filter applies the private key guard to out, the redaction of the chunk
+// raw that follows the current context.
+func (c *Chunks) filter(raw, out []byte) []byte {
+	if redactor() == nil {
+		return out
+	}
+	return []byte(c.pem.filter(c.site, string(c.context), string(raw), string(out)))
+}
+
+// Held reports whether a chunk is held.
+func (c *Chunks) Held() bool {
+	return c.holding
+}
+
+// redact redacts chunks after the context. The first part returned is the
+// context.
+func (c *Chunks) redact(chunks ...[]byte) ([][]byte, []map[string]int) {
+	strs := make([]string, 0, len(chunks)+1)
+	strs = append(strs, string(c.context))
+	for _, b := range chunks {
+		strs = append(strs, string(b))
+	}
+	hits := make([]map[string]int, len(strs))
+	if r := redactor(); r != nil {
+		strs, hits = r.redactParts(strs...)
+	}
+	parts := make([][]byte, len(strs))
+	for i, s := range strs {
+		parts[i] = []byte(s)
+	}
+	return parts, hits
+}
+
+// lineContext returns the end of the line that context followed by b
+// leaves open, capped at streamTailMax.
+func lineContext(context, b []byte) []byte {
+	ctx := append(context, b...)
+	if i := bytes.LastIndexAny(ctx, "\r\n"); i >= 0 {
+		ctx = ctx[i+1:]
+	}
+	if len(ctx) > streamTailMax {
+		ctx = ctx[len(ctx)-streamTailMax:]
+	}
+	return append(make([]byte, 0, len(ctx)), ctx...)
+}
+
+// tokenTailLen returns the length of the run of non-space bytes at the end
+// of b, or 0 when it reaches streamTailMax.
+func tokenTailLen(b []byte) int {
+	n := 0
+	for n < len(b) {
+		c := b[len(b)-1-n]
+		if c <= ' ' || c == 0x7f {
+			break
+		}
+		n++
+		if n >= streamTailMax {
+			return 0
+		}
+	}
+	return n
+}
@@ -724,12 +724,15 @@ type CursorState struct {
 	MouseButton   bool // mode 1002: button-event tracking (press/release + drag)
 	MouseAny      bool // mode 1003: any-event tracking (all motion)
 	MouseSGR      bool // mode 1006: SGR extended encoding
+	// Pane size. Zero when the source could not report it.
+	Width  int
+	Height int
 }
 
 // GetCursorState returns the cursor position and visibility for a pane.
 func (c *Client) GetCursorState(ctx context.Context, paneID string) (CursorState, error) {
 	output, _, err := c.Execute(ctx, fmt.Sprintf(
-		"display-message -p -t %s '#{cursor_x} #{cursor_y} #{cursor_flag} #{alternate_on} #{mouse_standard_flag} #{mouse_button_flag} #{mouse_any_flag} #{mouse_sgr_flag}'",
+		"display-message -p -t %s '#{cursor_x} #{cursor_y} #{cursor_flag} #{alternate_on} #{mouse_standard_flag} #{mouse_button_flag} #{mouse_any_flag} #{mouse_sgr_flag} #{pane_width} #{pane_height}'",
 		paneID,
 	))
 	if err != nil {
@@ -759,6 +762,10 @@ func (c *Client) GetCursorState(ctx context.Context, paneID string) (CursorState
 		cs.MouseAny = parts[6] == "1"
 		cs.MouseSGR = parts[7] == "1"
 	}
+	if len(parts) >= 10 {
+		fmt.Sscanf(parts[8], "%d", &cs.Width)
+		fmt.Sscanf(parts[9], "%d", &cs.Height)
+	}
 	return cs, nil
 }
 
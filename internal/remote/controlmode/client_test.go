@@ -775,6 +775,8 @@ func TestGetCursorState(t *testing.T) {
 		wantMouseButton   bool
 		wantMouseAny      bool
 		wantMouseSGR      bool
+		wantWidth         int
+		wantHeight        int
 		wantErr           bool
 		errSubstr         string
 	}{
@@ -817,6 +819,15 @@ func TestGetCursorState(t *testing.T) {
 			wantY:       49,
 			wantVisible: false,
 		},
+		{
+			name:        "with pane size",
+			response:    "3 4 1 0 0 0 0 0 120 40",
+			wantX:       3,
+			wantY:       4,
+			wantVisible: true,
+			wantWidth:   120,
+			wantHeight:  40,
+		},
 		{
 			name:        "three values (backward compat, no extended fields)",
 			response:    "1 2 1",
@@ -923,6 +934,9 @@ func TestGetCursorState(t *testing.T) {
 			if cs.MouseSGR != tt.wantMouseSGR {
 				t.Errorf("MouseSGR = %v, want %v", cs.MouseSGR, tt.wantMouseSGR)
 			}
+			if cs.Width != tt.wantWidth || cs.Height != tt.wantHeight {
+				t.Errorf("size = %dx%d, want %dx%d", cs.Width, cs.Height, tt.wantWidth, tt.wantHeight)
+			}
 		})
 	}
 }
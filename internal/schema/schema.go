@@ -24,6 +24,7 @@ const (
 	LabelAutolearnMerge       = "autolearn-merge"
 	LabelRepofeedIntent       = "repofeed-intent"
 	LabelCompoundMerge        = "compound-merge"
+	LabelTournamentJudge      = "tournament-judge"
 )
 
 // schemaEntry holds a type and optional skip fields for schema generation.
@@ -28,6 +28,7 @@ func ConfigPath() string    { return filepath.Join(Get(), "config.json") }
 func StatePath() string     { return filepath.Join(Get(), "state.json") }
 func PIDPath() string       { return filepath.Join(Get(), "daemon.pid") }
 func RecordingsDir() string { return filepath.Join(Get(), "recordings") }
+func ScrollbackDir() string { return filepath.Join(Get(), "scrollback") }
 func BackupsDir() string    { return filepath.Join(Get(), "backups") }
 func AdaptersDir() string   { return filepath.Join(Get(), "adapters") }
 func LogsDir() string       { return filepath.Join(Get(), "logs") }
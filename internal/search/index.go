@@ -0,0 +1,305 @@
+// Package search keeps a full-text index over what sessions produced —
+// terminal output, agent events and spawn prompts — so a line can be traced
+// back to the session, and the output position, it came from.
+//
+// The index is built incrementally: each search first reads whatever the
+// sources gained since the previous one. Terminal output comes from the
+// sessions' on-disk scrollback (see session.ScanScrollback), events from
+// their JSONL event files, prompts from the spawn log.
+package search
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is the source a matched line came from.
+type Kind string
+
+const (
+	KindOutput Kind = "output"
+	KindEvent  Kind = "event"
+	KindPrompt Kind = "prompt"
+)
+
+// DefaultLimit caps results when a query sets no limit.
+const DefaultLimit = 100
+
+// maxLinesPerSession bounds the memory one session's index uses. Past it the
+// oldest half is dropped.
+const maxLinesPerSession = 200000
+
+// recentLineWindow is how many recent output lines are remembered to skip
+// repeats; TUIs redraw the same lines over and over.
+const recentLineWindow = 256
+
+// Session tells the indexer where one session's sources are.
+type Session struct {
+	ID string
+	// EventsPath is the session's local JSONL event file. Empty when the
+	// file is not reachable from the daemon (remote sessions).
+	EventsPath string
+}
+
+// Query selects lines. Text is matched case-insensitively as a substring; the
+// index finds candidate lines by word prefix, so a match must start at the
+// beginning of a word ("refused" finds "ECONNREFUSED" only as part of
+// "econnrefused").
+type Query struct {
+	Text  string
+	Kinds []Kind // empty means all
+	Since time.Time
+	Until time.Time
+	Limit int // 0 means DefaultLimit
+}
+
+// Match is one matching line.
+type Match struct {
+	SessionID string
+	Kind      Kind
+	Seq       uint64 // output-log seq the line starts at; output matches only
+	EventType string // event matches only
+	Time      time.Time
+	Text      string
+}
+
+// Indexer holds the per-session indexes. It is safe for concurrent use;
+// searches run one at a time.
+type Indexer struct {
+	scrollbackDir string
+	spawnLogPath  string
+
+	mu             sync.Mutex
+	sessions       map[string]*sessionIndex
+	spawnLogOffset int64
+}
+
+// NewIndexer creates an indexer reading output stores under scrollbackDir
+// (one directory per session) and spawn prompts from spawnLogPath.
+func NewIndexer(scrollbackDir, spawnLogPath string) *Indexer {
+	return &Indexer{
+		scrollbackDir: scrollbackDir,
+		spawnLogPath:  spawnLogPath,
+		sessions:      make(map[string]*sessionIndex),
+	}
+}
+
+type line struct {
+	kind      Kind
+	seq       uint64
+	eventType string
+	time      time.Time
+	text      string
+	lower     string
+}
+
+type sessionIndex struct {
+	lines    []line
+	postings map[string][]int32 // word -> ascending line indexes
+	words    []string           // sorted keys of postings; nil when stale
+
+	outputNext   uint64
+	splitter     lineSplitter
+	recent       map[string]int // output line -> occurrences in recentRing
+	recentRing   []string
+	recentPos    int
+	eventsOffset int64
+}
+
+func newSessionIndex() *sessionIndex {
+	si := &sessionIndex{
+		postings:   make(map[string][]int32),
+		recent:     make(map[string]int),
+		recentRing: make([]string, recentLineWindow),
+	}
+	si.splitter.emit = si.addOutputLine
+	return si
+}
+
+// Search refreshes the given sessions' indexes and returns their lines that
+// match q, newest first. The bool reports whether matches were cut off by
+// the limit. Indexes of sessions not passed to Search are kept; use Retain
+// to drop those of sessions that are gone.
+func (ix *Indexer) Search(sessions []Session, q Query) ([]Match, bool, error) {
+	lowerQ := strings.ToLower(strings.TrimSpace(q.Text))
+	if lowerQ == "" {
+		return nil, false, errors.New("empty search query")
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.refreshPrompts()
+
+	words := tokenize(lowerQ)
+	var matches []Match
+	for _, s := range sessions {
+		si := ix.session(s.ID)
+		si.refreshOutput(ix.scrollbackDir, s.ID)
+		si.refreshEvents(s.EventsPath)
+
+		check := func(i int32) {
+			ln := &si.lines[i]
+			if len(q.Kinds) > 0 && !slices.Contains(q.Kinds, ln.kind) {
+				return
+			}
+			if (!q.Since.IsZero() && ln.time.Before(q.Since)) || (!q.Until.IsZero() && ln.time.After(q.Until)) {
+				return
+			}
+			if strings.Contains(ln.lower, lowerQ) {
+				matches = append(matches, Match{
+					SessionID: s.ID,
+					Kind:      ln.kind,
+					Seq:       ln.seq,
+					EventType: ln.eventType,
+					Time:      ln.time,
+					Text:      ln.text,
+				})
+			}
+		}
+		if len(words) == 0 {
+			for i := range si.lines {
+				check(int32(i))
+			}
+			continue
+		}
+		for _, i := range si.candidates(words) {
+			check(i)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if !matches[i].Time.Equal(matches[j].Time) {
+			return matches[i].Time.After(matches[j].Time)
+		}
+		return matches[i].Seq > matches[j].Seq
+	})
+	if len(matches) > limit {
+		return matches[:limit], true, nil
+	}
+	return matches, false, nil
+}
+
+// Retain drops the indexes of sessions not in ids.
+func (ix *Indexer) Retain(ids []string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for id := range ix.sessions {
+		if !slices.Contains(ids, id) {
+			delete(ix.sessions, id)
+		}
+	}
+}
+
+func (ix *Indexer) session(id string) *sessionIndex {
+	si := ix.sessions[id]
+	if si == nil {
+		si = newSessionIndex()
+		ix.sessions[id] = si
+	}
+	return si
+}
+
+// addLine indexes one line under each of its words.
+func (si *sessionIndex) addLine(ln line) {
+	if len(si.lines) >= maxLinesPerSession {
+		si.compact()
+	}
+	ln.lower = strings.ToLower(ln.text)
+	idx := int32(len(si.lines))
+	si.lines = append(si.lines, ln)
+	si.indexWords(idx)
+}
+
+func (si *sessionIndex) indexWords(idx int32) {
+	for _, w := range tokenize(si.lines[idx].lower) {
+		list, ok := si.postings[w]
+		if !ok {
+			si.words = nil
+		}
+		if n := len(list); n == 0 || list[n-1] != idx {
+			si.postings[w] = append(list, idx)
+		}
+	}
+}
+
+// compact drops the oldest half of the lines and rebuilds the postings.
+func (si *sessionIndex) compact() {
+	si.lines = slices.Clone(si.lines[len(si.lines)/2:])
+	si.postings = make(map[string][]int32)
+	si.words = nil
+	for i := range si.lines {
+		si.indexWords(int32(i))
+	}
+}
+
+// candidates returns, in ascending order, the lines that contain a word
+// starting with each of words.
+func (si *sessionIndex) candidates(words []string) []int32 {
+	if si.words == nil {
+		si.words = make([]string, 0, len(si.postings))
+		for w := range si.postings {
+			si.words = append(si.words, w)
+		}
+		sort.Strings(si.words)
+	}
+	var result []int32
+	for i, prefix := range words {
+		var set []int32
+		for j := sort.SearchStrings(si.words, prefix); j < len(si.words) && strings.HasPrefix(si.words[j], prefix); j++ {
+			set = append(set, si.postings[si.words[j]]...)
+		}
+		slices.Sort(set)
+		set = slices.Compact(set)
+		if i == 0 {
+			result = set
+		} else {
+			result = intersect(result, set)
+		}
+		if len(result) == 0 {
+			return nil
+		}
+	}
+	return result
+}
+
+// intersect returns the values in both ascending lists.
+func intersect(a, b []int32) []int32 {
+	var out []int32
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case a[0] > b[0]:
+			b = b[1:]
+		default:
+			out = append(out, a[0])
+			a, b = a[1:], b[1:]
+		}
+	}
+	return out
+}
+
+// addOutputLine indexes a line of terminal output unless it was seen within
+// the last recentLineWindow lines.
+func (si *sessionIndex) addOutputLine(text string, seq uint64, at time.Time) {
+	if si.recent[text] > 0 {
+		return
+	}
+	if old := si.recentRing[si.recentPos]; old != "" {
+		if si.recent[old]--; si.recent[old] <= 0 {
+			delete(si.recent, old)
+		}
+	}
+	si.recentRing[si.recentPos] = text
+	si.recentPos = (si.recentPos + 1) % len(si.recentRing)
+	si.recent[text]++
+	si.addLine(line{kind: KindOutput, seq: seq, time: at, text: text})
+}
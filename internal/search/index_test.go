@@ -0,0 +1,149 @@
+package search
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/session"
+)
+
+func appendOutput(t *testing.T, scrollbackDir, sessionID string, first uint64, chunks ...string) {
+	t.Helper()
+	s, err := session.OpenScrollbackStore(filepath.Join(scrollbackDir, sessionID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	entries := make([]session.LogEntry, len(chunks))
+	for i, c := range chunks {
+		entries[i] = session.LogEntry{Seq: first + uint64(i), Data: []byte(c)}
+	}
+	if err := s.Append(entries); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func appendJSONL(t *testing.T, path string, records ...any) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for _, r := range records {
+		data, _ := json.Marshal(r)
+		f.Write(append(data, '\n'))
+	}
+}
+
+func texts(matches []Match) string {
+	var parts []string
+	for _, m := range matches {
+		parts = append(parts, m.SessionID+"/"+string(m.Kind)+":"+m.Text)
+	}
+	return strings.Join(parts, " | ")
+}
+
+func TestIndexer_SearchesAllSources(t *testing.T) {
+	dir := t.TempDir()
+	scrollback := filepath.Join(dir, "scrollback")
+	spawnLog := filepath.Join(dir, "spawn.jsonl")
+	eventsPath := filepath.Join(dir, "s-1.jsonl")
+
+	appendOutput(t, scrollback, "s-1", 0, "$ go test\r\n", "\x1b[31mFAIL\x1b[0m dial tcp: connection refused\r\n")
+	appendJSONL(t, eventsPath,
+		map[string]string{"ts": "2026-01-01T10:00:00Z", "type": "status", "state": "error", "message": "Postgres connection refused"},
+		map[string]string{"ts": "2026-01-01T10:01:00Z", "type": "spawn", "command": "connection"},
+	)
+	appendJSONL(t, spawnLog, contracts.SpawnLogRecord{
+		TS:     "2026-01-01T09:00:00Z",
+		Prompt: "Fix the flaky test\nthat hits connection limits",
+		Results: []contracts.SpawnLogResult{
+			{SessionID: "s-1"}, {SessionID: "s-2"}, {Target: "codex", Error: "boom"},
+		},
+	})
+
+	ix := NewIndexer(scrollback, spawnLog)
+	sessions := []Session{{ID: "s-1", EventsPath: eventsPath}, {ID: "s-2"}}
+	matches, truncated, err := ix.Search(sessions, Query{Text: "Connection"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if truncated {
+		t.Error("unexpected truncation")
+	}
+	// Output was written just now, so it sorts before the older event and prompts.
+	want := "s-1/output:FAIL dial tcp: connection refused | s-1/event:error: Postgres connection refused | s-1/prompt:that hits connection limits | s-2/prompt:that hits connection limits"
+	if got := texts(matches); got != want {
+		t.Errorf("matches:\n got %s\nwant %s", got, want)
+	}
+	if matches[0].Seq != 1 {
+		t.Errorf("output seq = %d, want 1", matches[0].Seq)
+	}
+	if matches[1].EventType != "status" {
+		t.Errorf("event type = %q, want status", matches[1].EventType)
+	}
+
+	// Kind and time filters.
+	matches, _, _ = ix.Search(sessions, Query{Text: "connection", Kinds: []Kind{KindEvent, KindPrompt}, Until: time.Date(2026, 1, 1, 9, 30, 0, 0, time.UTC)})
+	if want := "s-1/prompt:that hits connection limits | s-2/prompt:that hits connection limits"; texts(matches) != want {
+		t.Errorf("filtered matches = %s, want %s", texts(matches), want)
+	}
+
+	// Substring matches start at a word; phrases must be contiguous.
+	if matches, _, _ = ix.Search(sessions, Query{Text: "nection"}); len(matches) != 0 {
+		t.Errorf("mid-word query matched: %s", texts(matches))
+	}
+	if matches, _, _ = ix.Search(sessions, Query{Text: "refused connection"}); len(matches) != 0 {
+		t.Errorf("out-of-order phrase matched: %s", texts(matches))
+	}
+
+	// Limit.
+	matches, truncated, _ = ix.Search(sessions, Query{Text: "conn", Limit: 2})
+	if len(matches) != 2 || !truncated {
+		t.Errorf("limit 2: got %d matches, truncated=%v", len(matches), truncated)
+	}
+}
+
+func TestIndexer_Incremental(t *testing.T) {
+	dir := t.TempDir()
+	eventsPath := filepath.Join(dir, "s-1.jsonl")
+	ix := NewIndexer(dir, "")
+	sessions := []Session{{ID: "s-1", EventsPath: eventsPath}}
+
+	appendOutput(t, dir, "s-1", 0, "build started\r\nhalf a li")
+	if matches, _, _ := ix.Search(sessions, Query{Text: "line"}); len(matches) != 0 {
+		t.Errorf("unfinished line matched: %s", texts(matches))
+	}
+	// The line completes, and a TUI redraws an already indexed line.
+	appendOutput(t, dir, "s-1", 1, "ne\r\n", "build started\r\n")
+	appendJSONL(t, eventsPath, map[string]string{"ts": "2026-01-01T10:00:00Z", "type": "reflection", "text": "build needs a clean cache"})
+
+	matches, _, err := ix.Search(sessions, Query{Text: "build"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "s-1/output:build started | s-1/event:build needs a clean cache"; texts(matches) != want {
+		t.Errorf("matches = %s, want %s", texts(matches), want)
+	}
+	matches, _, _ = ix.Search(sessions, Query{Text: "half a line"})
+	if len(matches) != 1 || matches[0].Seq != 0 {
+		t.Errorf("split line: got %+v, want one match at seq 0", matches)
+	}
+
+	ix.Retain(nil)
+	if len(ix.sessions) != 0 {
+		t.Errorf("Retain(nil) kept %d sessions", len(ix.sessions))
+	}
+}
+
+func TestIndexer_EmptyQuery(t *testing.T) {
+	if _, _, err := NewIndexer("", "").Search(nil, Query{Text: "  "}); err == nil {
+		t.Error("expected error for empty query")
+	}
+}
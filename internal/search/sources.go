@@ -0,0 +1,158 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/events"
+	"github.com/sergeknystautas/schmux/internal/session"
+)
+
+// refreshOutput indexes output appended to the session's scrollback store
+// since the last refresh. A line still being written is held back until its
+// end arrives.
+func (si *sessionIndex) refreshOutput(scrollbackDir, sessionID string) {
+	if scrollbackDir == "" {
+		return
+	}
+	session.ScanScrollback(filepath.Join(scrollbackDir, sessionID), si.outputNext, func(e session.LogEntry, written time.Time) bool {
+		si.splitter.write(e.Data, e.Seq, written)
+		si.outputNext = e.Seq + 1
+		return true
+	})
+}
+
+// refreshEvents indexes complete lines appended to the event file since the
+// last refresh.
+func (si *sessionIndex) refreshEvents(path string) {
+	if path == "" {
+		return
+	}
+	data, offset, ok := readFrom(path, si.eventsOffset)
+	if !ok {
+		return
+	}
+	si.eventsOffset = offset
+	for _, raw := range bytes.Split(data, []byte("\n")) {
+		if ln, ok := eventLine(raw); ok {
+			si.addLine(ln)
+		}
+	}
+}
+
+// refreshPrompts indexes spawn prompts appended to the spawn log, under each
+// session the spawn created.
+func (ix *Indexer) refreshPrompts() {
+	if ix.spawnLogPath == "" {
+		return
+	}
+	data, offset, ok := readFrom(ix.spawnLogPath, ix.spawnLogOffset)
+	if !ok {
+		return
+	}
+	ix.spawnLogOffset = offset
+	for _, raw := range bytes.Split(data, []byte("\n")) {
+		var rec contracts.SpawnLogRecord
+		if len(raw) == 0 || json.Unmarshal(raw, &rec) != nil || strings.TrimSpace(rec.Prompt) == "" {
+			continue
+		}
+		ts, _ := time.Parse(time.RFC3339, rec.TS)
+		for _, r := range rec.Results {
+			if r.SessionID == "" || r.Error != "" {
+				continue
+			}
+			si := ix.session(r.SessionID)
+			for _, text := range strings.Split(rec.Prompt, "\n") {
+				if text = strings.TrimSpace(text); text != "" {
+					si.addLine(line{kind: KindPrompt, time: ts, text: text})
+				}
+			}
+		}
+	}
+}
+
+// readFrom returns the complete lines of the file at path past offset and the
+// offset just after them. A file that shrank is read again from the start.
+func readFrom(path string, offset int64) ([]byte, int64, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, offset, false
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || info.Size() == offset {
+		return nil, offset, false
+	} else if info.Size() < offset {
+		offset = 0
+	}
+	data, err := io.ReadAll(io.NewSectionReader(f, offset, 1<<62))
+	if err != nil {
+		return nil, offset, false
+	}
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, offset, false
+	}
+	return data[:end], offset + int64(end) + 1, true
+}
+
+// eventLine turns a JSONL event into an indexable line. Event types without
+// free text (spawn markers, token usage, ...) are skipped.
+func eventLine(raw []byte) (line, bool) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return line{}, false
+	}
+	env, err := events.ParseRawEvent(raw)
+	if err != nil {
+		return line{}, false
+	}
+	var text string
+	switch env.Type {
+	case "status":
+		var e events.StatusEvent
+		if json.Unmarshal(raw, &e) != nil {
+			return line{}, false
+		}
+		text = joinNonEmpty(": ", e.State, joinNonEmpty(" — ", e.Message, e.Intent, e.Blockers))
+	case "failure":
+		var e events.FailureEvent
+		if json.Unmarshal(raw, &e) != nil {
+			return line{}, false
+		}
+		text = joinNonEmpty(": ", e.Tool, joinNonEmpty(" — ", e.Error, e.Input))
+	case "reflection", "friction":
+		var e events.ReflectionEvent
+		if json.Unmarshal(raw, &e) != nil {
+			return line{}, false
+		}
+		text = e.Text
+	case "message":
+		var e events.MessageEvent
+		if json.Unmarshal(raw, &e) != nil {
+			return line{}, false
+		}
+		text = joinNonEmpty(": ", "to "+e.To, e.Text)
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return line{}, false
+	}
+	ts, _ := time.Parse(time.RFC3339, env.Ts)
+	return line{kind: KindEvent, eventType: env.Type, time: ts, text: text}, true
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, sep)
+}
@@ -0,0 +1,131 @@
+package search
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxLineBytes bounds one indexed line. Longer runs without a line break
+// (a TUI redrawing in place) are split.
+const maxLineBytes = 4096
+
+// Escape-sequence parser states for lineSplitter.
+const (
+	stText   = iota
+	stEsc    // after ESC
+	stEscInt // after ESC and an intermediate byte, e.g. ESC ( B
+	stCSI    // inside ESC [ ... final
+	stString // inside OSC / DCS / APC / PM, until BEL or ST
+	stStrEsc // ESC inside a string, possibly the start of ST
+)
+
+// lineSplitter turns a raw terminal byte stream into plain text lines. It
+// drops escape sequences, breaks lines at newlines, carriage returns and
+// cursor movements to another row, and turns horizontal cursor jumps into
+// spaces so words drawn with them stay apart. State carries across writes,
+// so sequences split between output chunks are handled.
+type lineSplitter struct {
+	state int
+	buf   []byte
+	// Position of the first byte of buf in the output log.
+	seq  uint64
+	time time.Time
+	emit func(text string, seq uint64, at time.Time)
+}
+
+// write feeds one output-log entry.
+func (l *lineSplitter) write(data []byte, seq uint64, at time.Time) {
+	for _, b := range data {
+		switch l.state {
+		case stText:
+			switch {
+			case b == 0x1b:
+				l.state = stEsc
+			case b == '\n' || b == '\r':
+				l.flush()
+			case b == '\t':
+				l.add(' ', seq, at)
+			case b < 0x20 || b == 0x7f:
+				// other C0 controls draw nothing
+			default:
+				l.add(b, seq, at)
+			}
+		case stEsc:
+			switch {
+			case b == '[':
+				l.state = stCSI
+			case b == ']' || b == 'P' || b == 'X' || b == '^' || b == '_':
+				l.state = stString
+			case b >= 0x20 && b <= 0x2f:
+				l.state = stEscInt
+			default:
+				l.state = stText
+			}
+		case stEscInt:
+			l.state = stText
+		case stCSI:
+			switch {
+			case b == 0x1b:
+				l.state = stEsc
+			case b >= 0x40 && b <= 0x7e:
+				l.state = stText
+				switch b {
+				case 'A', 'B', 'E', 'F', 'H', 'f', 'd', 'J':
+					l.flush()
+				case 'C', 'G':
+					l.add(' ', seq, at)
+				}
+			}
+		case stString:
+			switch b {
+			case 0x07:
+				l.state = stText
+			case 0x1b:
+				l.state = stStrEsc
+			}
+		case stStrEsc:
+			if b == '\\' {
+				l.state = stText
+			} else {
+				l.state = stString
+			}
+		}
+	}
+}
+
+func (l *lineSplitter) add(b byte, seq uint64, at time.Time) {
+	if len(l.buf) >= maxLineBytes && utf8.RuneStart(b) {
+		l.flush()
+	}
+	if len(l.buf) == 0 {
+		if b == ' ' {
+			return
+		}
+		l.seq, l.time = seq, at
+	}
+	l.buf = append(l.buf, b)
+}
+
+// flush emits the buffered line if it has anything searchable.
+func (l *lineSplitter) flush() {
+	if len(l.buf) == 0 {
+		return
+	}
+	text := strings.Join(strings.Fields(strings.ToValidUTF8(string(l.buf), "")), " ")
+	l.buf = l.buf[:0]
+	if strings.IndexFunc(text, isWordRune) >= 0 {
+		l.emit(text, l.seq, l.time)
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// tokenize splits lowercased text into words: maximal runs of letters and
+// digits. Matches always start at one of these word boundaries.
+func tokenize(lower string) []string {
+	return strings.FieldsFunc(lower, func(r rune) bool { return !isWordRune(r) })
+}
@@ -0,0 +1,70 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func splitLines(chunks ...string) []string {
+	var out []string
+	l := lineSplitter{emit: func(text string, _ uint64, _ time.Time) { out = append(out, text) }}
+	for i, c := range chunks {
+		l.write([]byte(c), uint64(i), time.Time{})
+	}
+	l.flush()
+	return out
+}
+
+func TestLineSplitter(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   []string
+	}{
+		{"newlines", []string{"hello\r\nworld\n"}, []string{"hello", "world"}},
+		{"colors stripped", []string{"\x1b[1;31mError:\x1b[0m  build   failed\r\n"}, []string{"Error: build failed"}},
+		{"osc title dropped", []string{"\x1b]0;my title\x07prompt$ ls\n"}, []string{"prompt$ ls"}},
+		{"osc with st", []string{"\x1b]8;;http://x\x1b\\link\x1b]8;;\x1b\\\n"}, []string{"link"}},
+		{"cursor moves break lines", []string{"\x1b[1;1Htop\x1b[2;1Hbottom"}, []string{"top", "bottom"}},
+		{"horizontal jump is a space", []string{"one\x1b[5Ctwo\n"}, []string{"one two"}},
+		{"charset selection", []string{"\x1b(Bplain\n"}, []string{"plain"}},
+		{"split across chunks", []string{"abc\x1b[3", "1mdef\x1b]0;t", "itle\x07ghi\n"}, []string{"abcdefghi"}},
+		{"punctuation only skipped", []string{"────\n>\nok\n"}, []string{"ok"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitLines(tt.chunks...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineSplitter_LineStartSeq(t *testing.T) {
+	type emitted struct {
+		text string
+		seq  uint64
+	}
+	var out []emitted
+	l := lineSplitter{emit: func(text string, seq uint64, _ time.Time) { out = append(out, emitted{text, seq}) }}
+	l.write([]byte("first\n  sec"), 10, time.Time{})
+	l.write([]byte("ond\n"), 11, time.Time{})
+	want := []emitted{{"first", 10}, {"second", 10}}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("emitted %v, want %v", out, want)
+	}
+}
+
+func TestLineSplitter_LongLineSplitsOnRuneBoundary(t *testing.T) {
+	lines := splitLines(strings.Repeat("é", maxLineBytes) + "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	for _, ln := range lines {
+		if strings.ContainsRune(ln, '�') || !strings.HasPrefix(ln, "é") {
+			t.Errorf("line split inside a rune: %q...", ln[:8])
+		}
+	}
+}
@@ -15,6 +15,9 @@ type MockControlSource struct {
 	// CaptureContent is returned by CaptureLines/CaptureVisible when set.
 	CaptureContent string
 	CaptureErr     error
+	// Cursor is returned by GetCursorState; CaptureCalls counts CaptureLines.
+	Cursor       controlmode.CursorState
+	CaptureCalls int
 }
 
 func NewMockControlSource(bufSize int) *MockControlSource {
@@ -29,13 +32,14 @@ func (m *MockControlSource) CaptureVisible() (string, error) {
 	return m.CaptureContent, m.CaptureErr
 }
 func (m *MockControlSource) CaptureLines(n int) (string, error) {
+	m.CaptureCalls++
 	return m.CaptureContent, m.CaptureErr
 }
 func (m *MockControlSource) SendTmuxKeyName(name string) error { return nil }
 func (m *MockControlSource) Resize(cols, rows int) error       { return nil }
 func (m *MockControlSource) IsAttached() bool                  { return !m.closed }
 func (m *MockControlSource) GetCursorState() (controlmode.CursorState, error) {
-	return controlmode.CursorState{}, nil
+	return m.Cursor, nil
 }
 
 func (m *MockControlSource) Close() error {
@@ -24,6 +24,7 @@ import (
 	"github.com/sergeknystautas/schmux/internal/fence"
 	"github.com/sergeknystautas/schmux/internal/logging"
 	"github.com/sergeknystautas/schmux/internal/models"
+	"github.com/sergeknystautas/schmux/internal/ptyhost"
 	"github.com/sergeknystautas/schmux/internal/remote"
 	"github.com/sergeknystautas/schmux/internal/remote/controlmode"
 	"github.com/sergeknystautas/schmux/internal/schmuxdir"
@@ -62,8 +63,13 @@ type Manager struct {
 	terminalCaptureCallback func(sessionID, workspaceID, output string)        // notify on terminal capture before dispose
 	telemetry               telemetry.Telemetry                                // optional, for usage tracking
 	recorderFactory         func(sessionID string, outputLog *OutputLog, gapCh <-chan SourceEvent, width, height int) Runnable
-	queueTimeout            time.Duration // timeout for queued remote sessions; 0 = default (5m)
-	reaper                  *reaper       // terminates fenced session process trees
+	scrollbackDir           string                         // per-session output stores live under here; empty = not persisted
+	queueTimeout            time.Duration                  // timeout for queued remote sessions; 0 = default (5m)
+	reaper                  *reaper                        // terminates fenced session process trees
+	queue                   *spawnQueue                    // spawns held back by concurrency limits
+	queueCallback           func()                         // notified when a queued spawn starts or fails
+	spawnGate               func(workspaceID string) error // vetoes new spawns, e.g. over budget
+	shutdownCtx             context.Context                // cancelled on daemon shutdown; queued spawns run under it
 }
 
 // remoteSignalMonitor holds a watcher pane and its metadata for a remote session.
@@ -114,6 +120,14 @@ func New(cfg *config.Config, st state.StateStore, statePath string, wm workspace
 	if logger == nil {
 		logger = log.NewWithOptions(io.Discard, log.Options{})
 	}
+	queuePath := ""
+	if statePath != "" {
+		queuePath = filepath.Join(filepath.Dir(statePath), "spawn-queue.json")
+	}
+	queue := newSpawnQueue(queuePath)
+	if err := queue.load(); err != nil {
+		logger.Warn("failed to load spawn queue", "err", err)
+	}
 	return &Manager{
 		config:          cfg,
 		state:           st,
@@ -125,6 +139,8 @@ func New(cfg *config.Config, st state.StateStore, statePath string, wm workspace
 		remoteDetectors: make(map[string]*remoteSignalMonitor),
 		remoteManager:   nil,
 		reaper:          newReaper(logger),
+		queue:           queue,
+		shutdownCtx:     context.Background(),
 	}
 }
 
@@ -146,6 +162,12 @@ func (m *Manager) ServerForSocket(socketName string) *tmux.TmuxServer {
 	return m.serverForSocket(socketName)
 }
 
+// SetShutdownContext sets the context queued spawns run under once drained.
+// Must be called before Start() — not safe for concurrent use.
+func (m *Manager) SetShutdownContext(ctx context.Context) {
+	m.shutdownCtx = ctx
+}
+
 // SetRemoteManager sets the remote manager for remote session support.
 // Must be called before Start() — not safe for concurrent use.
 func (m *Manager) SetRemoteManager(rm *remote.Manager) {
@@ -160,6 +182,13 @@ func (m *Manager) SetRecorderFactory(fn func(sessionID string, outputLog *Output
 	m.recorderFactory = fn
 }
 
+// SetScrollbackDir enables on-disk scrollback: each session's output log is
+// persisted under dir/<sessionID> and restored when its tracker is created.
+// Must be called before Start() — not safe for concurrent use.
+func (m *Manager) SetScrollbackDir(dir string) {
+	m.scrollbackDir = dir
+}
+
 // SetModelManager sets the model manager for model resolution.
 // Must be called before Start() — not safe for concurrent use.
 func (m *Manager) SetModelManager(mm *models.Manager) {
@@ -220,6 +249,28 @@ func (m *Manager) SetTerminalCaptureCallback(cb func(sessionID, workspaceID, out
 	m.terminalCaptureCallback = cb
 }
 
+// SetQueueCallback sets the callback invoked when a queued spawn starts or
+// fails. Must be called before Start() — not safe for concurrent use.
+func (m *Manager) SetQueueCallback(cb func()) {
+	m.queueCallback = cb
+}
+
+// SetSpawnGate sets a check run before every new spawn; a non-nil error
+// refuses the spawn. workspaceID is empty when the spawn will create a
+// workspace. Spawns drained from the queue were admitted earlier and are not
+// checked again. Must be called before Start() — not safe for concurrent use.
+func (m *Manager) SetSpawnGate(gate func(workspaceID string) error) {
+	m.spawnGate = gate
+}
+
+// CheckSpawnGate runs the spawn gate, if one is set.
+func (m *Manager) CheckSpawnGate(workspaceID string) error {
+	if m.spawnGate == nil {
+		return nil
+	}
+	return m.spawnGate(workspaceID)
+}
+
 // SetTelemetry sets the telemetry client for usage tracking.
 func (m *Manager) SetTelemetry(t telemetry.Telemetry) {
 	m.telemetry = t
@@ -433,6 +484,9 @@ func (m *Manager) SpawnRemote(ctx context.Context, opts RemoteSpawnOptions) (*st
 	if m.remoteManager == nil {
 		return nil, fmt.Errorf("remote manager not configured")
 	}
+	if err := m.CheckSpawnGate(opts.WorkspaceID); err != nil {
+		return nil, err
+	}
 
 	resolved, err := m.ResolveTarget(ctx, opts.TargetName)
 	if err != nil {
@@ -633,6 +687,7 @@ func (m *Manager) SpawnRemote(ctx context.Context, opts RemoteSpawnOptions) (*st
 						}
 						qTracker := NewSessionRuntime(sessionID, qSource, m.state, "", nil, qOutputCb, m.logger)
 						m.wireRecorder(qTracker, sessionID)
+						m.wireScrollback(qTracker, sessionID)
 						m.mu.Lock()
 						m.trackers[sessionID] = qTracker
 						m.mu.Unlock()
@@ -723,6 +778,7 @@ func (m *Manager) SpawnRemote(ctx context.Context, opts RemoteSpawnOptions) (*st
 	}
 	tracker := NewSessionRuntime(sess.ID, source, m.state, "", nil, outputCb, m.logger)
 	m.wireRecorder(tracker, sess.ID)
+	m.wireScrollback(tracker, sess.ID)
 	m.mu.Lock()
 	m.trackers[sess.ID] = tracker
 	m.mu.Unlock()
@@ -854,6 +910,12 @@ type SpawnOptions struct {
 	ImageAttachments []string // base64-encoded PNGs (decoded and written during spawn)
 	Fence            bool     // OS-level fence sandbox for this spawn (local only)
 	FenceCommand     string   // resolved fence command from the dependency report (internal-only; set by the handler)
+	Priority         int      // queue priority when concurrency limits defer the spawn; higher drains first
+	Backend          string   // session backend: "tmux" or "pty"; empty uses the configured default
+
+	// queuedSessionID is set when a queued spawn is drained: the session
+	// reuses the placeholder's ID and bypasses the limits (its slot is held).
+	queuedSessionID string
 }
 
 // resolveWorkspace resolves the target workspace from SpawnOptions.
@@ -944,14 +1006,27 @@ func (m *Manager) Spawn(ctx context.Context, opts SpawnOptions) (*state.Session,
 		return nil, err
 	}
 
+	backend, err := m.resolveBackend(opts.Backend)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check tmux availability early, before doing workspace resolution and
 	// signaling setup work that would be wasted if tmux is missing.
-	if m.server == nil {
-		return nil, fmt.Errorf("tmux is required to spawn sessions. Install it with: brew install tmux (macOS) or apt install tmux (Linux)")
+	if backend == state.SessionBackendTmux {
+		if m.server == nil {
+			return nil, fmt.Errorf("tmux is required to spawn sessions. Install it with: brew install tmux (macOS) or apt install tmux (Linux)")
+		}
+		if err := m.server.Check(); err != nil {
+			return nil, fmt.Errorf("tmux is required to spawn sessions. Install it with: brew install tmux (macOS) or apt install tmux (Linux)")
+		}
 	}
-	if err := m.server.Check(); err != nil {
-		return nil, fmt.Errorf("tmux is required to spawn sessions. Install it with: brew install tmux (macOS) or apt install tmux (Linux)")
+
+	release, queued, err := m.admitOrEnqueue(ctx, opts, false)
+	if err != nil || queued != nil {
+		return queued, err
 	}
+	defer release()
 
 	w, err := m.resolveWorkspace(ctx, opts)
 	if err != nil {
@@ -1004,8 +1079,11 @@ func (m *Manager) Spawn(ctx context.Context, opts SpawnOptions) (*state.Session,
 	// Use model from resolution (already populated by ResolveTarget)
 	model := resolved.Model
 
-	// Create session ID
+	// Create session ID (queued spawns keep the ID of their placeholder)
 	sessionID := fmt.Sprintf("%s-%s", w.ID, uuid.New().String()[:8])
+	if opts.queuedSessionID != "" {
+		sessionID = opts.queuedSessionID
+	}
 
 	// Inject schmux signaling environment variables
 	resolved.Env = mergeEnvMaps(resolved.Env, map[string]string{
@@ -1069,15 +1147,19 @@ func (m *Manager) Spawn(ctx context.Context, opts SpawnOptions) (*state.Session,
 		}
 	}
 
+	// Give the agent the schmux MCP server, scoped to its workspace
+	command = appendMCPFlags(command, baseTool, w.ID)
+
 	if m.config.GetDebugUI() {
 		m.logger.Info("spawn command", "session", sessionID, "target", opts.TargetName, "command", command)
 	} else {
 		m.logger.Info("spawn command", "session", sessionID, "target", opts.TargetName, "command_len", len(command))
 	}
 
-	// Generate unique nickname if provided (auto-suffix if duplicate)
+	// Generate unique nickname if provided (auto-suffix if duplicate).
+	// Queued spawns already reserved theirs.
 	uniqueNickname := opts.Nickname
-	if opts.Nickname != "" {
+	if opts.Nickname != "" && opts.queuedSessionID == "" {
 		uniqueNickname = m.generateUniqueNickname(opts.Nickname)
 	}
 
@@ -1092,11 +1174,9 @@ func (m *Manager) Spawn(ctx context.Context, opts SpawnOptions) (*state.Session,
 	if err != nil {
 		return nil, err
 	}
-	// CreateSession reports the pane PID atomically from the creation command,
-	// so no follow-up PID query can race the pane's lifecycle.
-	pid, err := m.server.CreateSession(ctx, tmuxSession, w.Path, command)
+	pid, err := m.startSessionProcess(ctx, backend, sessionID, tmuxSession, w.Path, command)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create tmux session: %w", err)
+		return nil, err
 	}
 
 	// Inject prompt via send-keys for tools that need it (runs async).
@@ -1104,12 +1184,9 @@ func (m *Manager) Spawn(ctx context.Context, opts SpawnOptions) (*state.Session,
 	// interactive mode. For these, we wait for the tool's input prompt to
 	// appear and then type the prompt via tmux send-keys.
 	if sendKeysPrompt != "" {
-		go m.sendPromptWhenReady(tmuxSession, sendKeysPrompt)
+		go m.sendPromptWhenReady(sessionID, tmuxSession, backend, sendKeysPrompt)
 	}
 
-	// Configure status bar: process on left, time on right, clear center
-	m.server.ConfigureStatusBar(ctx, tmuxSession)
-
 	// Create session state with cached PID (no Prompt field)
 	sess := state.Session{
 		ID:          sessionID,
@@ -1119,11 +1196,11 @@ func (m *Manager) Spawn(ctx context.Context, opts SpawnOptions) (*state.Session,
 		PersonaID:   opts.PersonaID,
 		StyleID:     opts.StyleID,
 		TmuxSession: tmuxSession,
-		TmuxSocket:  m.server.SocketName(),
 		CreatedAt:   time.Now(),
 		Pid:         pid,
 		Fence:       opts.Fence,
 	}
+	m.setSessionBackend(&sess, backend)
 
 	if err := m.state.AddSession(sess); err != nil {
 		return nil, fmt.Errorf("failed to add session to state: %w", err)
@@ -1151,13 +1228,27 @@ func (m *Manager) Spawn(ctx context.Context, opts SpawnOptions) (*state.Session,
 // SpawnCommand spawns a session running a raw shell command.
 // Used for quick launch presets with a direct command (no target resolution).
 func (m *Manager) SpawnCommand(ctx context.Context, opts SpawnOptions) (*state.Session, error) {
+	backend, err := m.resolveBackend(opts.Backend)
+	if err != nil {
+		return nil, err
+	}
+
+	release, queued, err := m.admitOrEnqueue(ctx, opts, true)
+	if err != nil || queued != nil {
+		return queued, err
+	}
+	defer release()
+
 	w, err := m.resolveWorkspace(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create session ID
+	// Create session ID (queued spawns keep the ID of their placeholder)
 	sessionID := fmt.Sprintf("%s-%s", w.ID, uuid.New().String()[:8])
+	if opts.queuedSessionID != "" {
+		sessionID = opts.queuedSessionID
+	}
 
 	// Ensure schmux events directory exists for event-based signaling
 	eventsDirCmd := filepath.Join(state.SchmuxDataDir(w.Path), "events")
@@ -1174,9 +1265,10 @@ func (m *Manager) SpawnCommand(ctx context.Context, opts SpawnOptions) (*state.S
 	}
 	commandWithEnv := fmt.Sprintf("%s %s", buildEnvPrefix(schmuxEnv), opts.Command)
 
-	// Generate unique nickname if provided (auto-suffix if duplicate)
+	// Generate unique nickname if provided (auto-suffix if duplicate).
+	// Queued spawns already reserved theirs.
 	uniqueNickname := opts.Nickname
-	if opts.Nickname != "" {
+	if opts.Nickname != "" && opts.queuedSessionID == "" {
 		uniqueNickname = m.generateUniqueNickname(opts.Nickname)
 	}
 
@@ -1191,28 +1283,23 @@ func (m *Manager) SpawnCommand(ctx context.Context, opts SpawnOptions) (*state.S
 	if err != nil {
 		return nil, err
 	}
-	// CreateSession reports the pane PID atomically from the creation command,
-	// so no follow-up PID query can race the pane's lifecycle.
-	pid, err := m.server.CreateSession(ctx, tmuxSession, w.Path, commandWithEnv)
+	pid, err := m.startSessionProcess(ctx, backend, sessionID, tmuxSession, w.Path, commandWithEnv)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create tmux session: %w", err)
+		return nil, err
 	}
 
-	// Configure status bar: process on left, time on right, clear center
-	m.server.ConfigureStatusBar(ctx, tmuxSession)
-
 	// Create session state (Target uses a stable value for command-based sessions)
 	sess := state.Session{
 		ID:          sessionID,
 		WorkspaceID: w.ID,
-		Target:      "command",
+		Target:      commandTarget,
 		Nickname:    uniqueNickname,
 		TmuxSession: tmuxSession,
-		TmuxSocket:  m.server.SocketName(),
 		CreatedAt:   time.Now(),
 		Pid:         pid,
 		Fence:       opts.Fence,
 	}
+	m.setSessionBackend(&sess, backend)
 
 	if err := m.state.AddSession(sess); err != nil {
 		return nil, fmt.Errorf("failed to add session to state: %w", err)
@@ -1229,6 +1316,63 @@ func (m *Manager) SpawnCommand(ctx context.Context, opts SpawnOptions) (*state.S
 	return &sess, nil
 }
 
+// resolveBackend returns the session backend for a local spawn: the requested
+// one, or the configured default when none was requested.
+func (m *Manager) resolveBackend(requested string) (string, error) {
+	switch requested {
+	case "":
+		return m.config.GetSessionBackend(), nil
+	case state.SessionBackendTmux, state.SessionBackendPTY:
+		return requested, nil
+	default:
+		return "", fmt.Errorf("unknown session backend: %q", requested)
+	}
+}
+
+// startSessionProcess starts command in dir on the given backend and returns
+// the agent's PID. tmuxSession names the tmux session on the tmux backend.
+func (m *Manager) startSessionProcess(ctx context.Context, backend, sessionID, tmuxSession, dir, command string) (int, error) {
+	if backend == state.SessionBackendPTY {
+		exe, err := os.Executable()
+		if err != nil {
+			return 0, fmt.Errorf("failed to locate schmux binary: %w", err)
+		}
+		pid, err := ptyhost.Spawn(ctx, ptyhost.SpawnOptions{
+			Executable: exe,
+			SessionID:  sessionID,
+			Dir:        dir,
+			Command:    command,
+			Cols:       80,
+			Rows:       24,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to create pty session: %w", err)
+		}
+		return pid, nil
+	}
+
+	// CreateSession reports the pane PID atomically from the creation command,
+	// so no follow-up PID query can race the pane's lifecycle.
+	pid, err := m.server.CreateSession(ctx, tmuxSession, dir, command)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create tmux session: %w", err)
+	}
+
+	// Configure status bar: process on left, time on right, clear center
+	m.server.ConfigureStatusBar(ctx, tmuxSession)
+	return pid, nil
+}
+
+// setSessionBackend records where a new session runs. tmux sessions keep the
+// socket they were created on; pty sessions are addressed by session ID.
+func (m *Manager) setSessionBackend(sess *state.Session, backend string) {
+	if backend == state.SessionBackendPTY {
+		sess.Backend = state.SessionBackendPTY
+		return
+	}
+	sess.TmuxSocket = m.server.SocketName()
+}
+
 // ResolveTarget resolves a target name to a command and env.
 func (m *Manager) ResolveTarget(_ context.Context, targetName string) (ResolvedTarget, error) {
 	// Check if it's a model (handles aliases like "opus", "sonnet", "haiku")
@@ -1487,6 +1631,24 @@ func appendSignalingFlags(cmd, baseTool string, isRemote bool) string {
 	return cmd
 }
 
+// appendMCPFlags registers the schmux MCP server (`schmux mcp --workspace
+// <id>`) via CLI flags for tools whose descriptor declares mcp_args. Local
+// sessions only: the daemon's binary path means nothing on a remote host.
+func appendMCPFlags(cmd, baseTool, workspaceID string) string {
+	adapter := detect.GetAdapter(baseTool)
+	if adapter == nil {
+		return cmd
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return cmd
+	}
+	for _, arg := range adapter.MCPArgs(exe, workspaceID) {
+		cmd = fmt.Sprintf("%s %s", cmd, shellutil.Quote(arg))
+	}
+	return cmd
+}
+
 // appendPersonaFlags injects persona prompt via CLI flag for tools that support it.
 // Only tools with PersonaCLIFlag injection method get flags appended.
 // Other tools use instruction file append or SpawnEnv for persona injection.
@@ -1519,10 +1681,39 @@ func appendPersonaFlags(cmd, baseTool, personaFilePath string) string {
 	}
 }
 
-// sendPromptWhenReady polls the tmux pane until the tool's input prompt appears,
-// then types the user prompt via send-keys. Used for tools that ignore positional
+// sendPromptWhenReady polls the session's screen until the tool's input prompt
+// appears, then types the user prompt. Used for tools that ignore positional
 // prompt args in interactive mode (e.g. Claude Code v2+).
-func (m *Manager) sendPromptWhenReady(session, prompt string) {
+func (m *Manager) sendPromptWhenReady(sessionID, session, backend, prompt string) {
+	capture := func(ctx context.Context) (string, error) {
+		return m.server.CaptureLastLines(ctx, session, 5, false)
+	}
+	send := func(ctx context.Context) error {
+		return m.server.SendText(ctx, session, prompt)
+	}
+	if backend == state.SessionBackendPTY {
+		// The tracker is created once the session is saved, shortly after
+		// this goroutine starts; until then capture fails and polling retries.
+		capture = func(ctx context.Context) (string, error) {
+			tracker, err := m.GetTracker(sessionID)
+			if err != nil {
+				return "", err
+			}
+			return tracker.CaptureLastLines(ctx, 5)
+		}
+		send = func(ctx context.Context) error {
+			tracker, err := m.GetTracker(sessionID)
+			if err != nil {
+				return err
+			}
+			// Like tmux paste-buffer, line feeds are sent as carriage returns.
+			if _, err := tracker.SendInput(strings.ReplaceAll(prompt, "\n", "\r")); err != nil {
+				return err
+			}
+			return tracker.SendTmuxKeyName("Enter")
+		}
+	}
+
 	pollCtx, pollCancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer pollCancel()
 
@@ -1535,7 +1726,7 @@ func (m *Manager) sendPromptWhenReady(session, prompt string) {
 			m.logger.Warn("send-keys prompt: timed out waiting for input prompt", "session", session)
 			// Fall through to send anyway as best-effort
 		case <-ticker.C:
-			output, err := m.server.CaptureLastLines(pollCtx, session, 5, false)
+			output, err := capture(pollCtx)
 			if err != nil {
 				continue
 			}
@@ -1551,7 +1742,7 @@ func (m *Manager) sendPromptWhenReady(session, prompt string) {
 	sendCtx, sendCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer sendCancel()
 
-	if err := m.server.SendText(sendCtx, session, prompt); err != nil {
+	if err := send(sendCtx); err != nil {
 		m.logger.Warn("send-keys prompt: failed to send", "session", session, "err", err)
 	} else {
 		m.logger.Info("send-keys prompt: injected", "session", session, "prompt_len", len(prompt))
@@ -1614,6 +1805,9 @@ func (m *Manager) IsRunning(ctx context.Context, sessionID string) bool {
 	// Local session handling
 	// If we don't have a PID, check if tmux session exists as fallback
 	if sess.Pid == 0 {
+		if sess.IsPTYSession() {
+			return ptyhost.Alive(ptyhost.SocketPath(sess.ID))
+		}
 		server := m.serverForSocket(sess.TmuxSocket)
 		if server != nil {
 			return server.SessionExists(ctx, sess.TmuxSession)
@@ -1675,6 +1869,18 @@ func (m *Manager) Dispose(ctx context.Context, sessionID string) error {
 		return fmt.Errorf("session not found: %s", sessionID)
 	}
 
+	// A queued session has no tmux session yet: drop it from the queue.
+	if m.dequeue(sessionID) {
+		if err := m.state.RemoveSession(sessionID); err != nil {
+			return fmt.Errorf("failed to remove session from state: %w", err)
+		}
+		if err := m.state.Save(); err != nil {
+			return fmt.Errorf("failed to save state: %w", err)
+		}
+		m.logger.Info("disposed queued session", "session", sessionID)
+		return nil
+	}
+
 	// Handle remote sessions
 	if sess.IsRemoteSession() {
 		return m.disposeRemoteSession(ctx, sess)
@@ -1683,12 +1889,7 @@ func (m *Manager) Dispose(ctx context.Context, sessionID string) error {
 	// Capture terminal output BEFORE killing the session
 	if m.terminalCaptureCallback != nil && ctx.Err() == nil {
 		captureCtx, captureCancel := context.WithTimeout(ctx, 5*time.Second)
-		var output string
-		var err error
-		server := m.serverForSocket(sess.TmuxSocket)
-		if server != nil {
-			output, err = server.CaptureOutput(captureCtx, sess.TmuxSession)
-		}
+		output, err := m.GetOutput(captureCtx, sessionID)
 		captureCancel()
 		if err != nil {
 			m.logger.Warn("failed to capture terminal output", "session", sessionID, "err", err)
@@ -1701,7 +1902,9 @@ func (m *Manager) Dispose(ctx context.Context, sessionID string) error {
 	// If session exists but kill fails, return error to avoid orphaning processes
 	disposeServer := m.serverForSocket(sess.TmuxSocket)
 	sessionExists := false
-	if disposeServer != nil {
+	if sess.IsPTYSession() {
+		sessionExists = ptyhost.Alive(ptyhost.SocketPath(sess.ID))
+	} else if disposeServer != nil {
 		sessionExists = disposeServer.SessionExists(ctx, sess.TmuxSession)
 	}
 
@@ -1712,7 +1915,7 @@ func (m *Manager) Dispose(ctx context.Context, sessionID string) error {
 	// sess.Pid is written once at spawn and could since have been reused.
 	var fencedProcs []procIdent
 	if sess.Fence && sessionExists {
-		panePID, err := disposeServer.GetPanePID(ctx, sess.TmuxSession)
+		panePID, err := m.livePanePID(ctx, sess, disposeServer)
 		if err != nil {
 			m.logger.Warn("fenced dispose: cannot resolve pane pid, skipping reap", "session", sessionID, "err", err)
 		} else if fencedProcs, err = m.reaper.enumerate(ctx, panePID); err != nil {
@@ -1728,7 +1931,14 @@ func (m *Manager) Dispose(ctx context.Context, sessionID string) error {
 	}
 
 	if sessionExists {
-		if err := disposeServer.KillSession(ctx, sess.TmuxSession); err != nil {
+		if sess.IsPTYSession() {
+			killCtx, killCancel := context.WithTimeout(ctx, 10*time.Second)
+			err := ptyhost.Kill(killCtx, ptyhost.SocketPath(sess.ID))
+			killCancel()
+			if err != nil {
+				return fmt.Errorf("failed to kill pty session %s: %w", sess.ID, err)
+			}
+		} else if err := disposeServer.KillSession(ctx, sess.TmuxSession); err != nil {
 			return fmt.Errorf("failed to kill tmux session %s: %w", sess.TmuxSession, err)
 		}
 	}
@@ -1758,6 +1968,7 @@ func (m *Manager) Dispose(ctx context.Context, sessionID string) error {
 	}
 
 	m.stopTracker(sessionID)
+	m.removeScrollback(sessionID)
 
 	// Note: workspace is NOT cleaned up on session disposal.
 	// Workspaces persist and are only reset when reused for a new spawn.
@@ -1796,9 +2007,30 @@ func (m *Manager) Dispose(ctx context.Context, sessionID string) error {
 
 	m.logger.Info("disposed session", "session", sessionID)
 
+	// Disposal frees a concurrency slot. Completed sessions already recorded
+	// their run time when they reported completion.
+	if nudgeState(sess.Nudge) != "Completed" {
+		m.queue.recordRunDuration(time.Since(sess.CreatedAt))
+	}
+	m.kickQueue()
+
 	return nil
 }
 
+// livePanePID returns the current PID of the process a local session runs:
+// the tmux pane's, or the one a pty supervisor reports.
+func (m *Manager) livePanePID(ctx context.Context, sess state.Session, server *tmux.TmuxServer) (int, error) {
+	if sess.IsPTYSession() {
+		conn, err := ptyhost.Dial(ptyhost.SocketPath(sess.ID))
+		if err != nil {
+			return 0, err
+		}
+		defer conn.Close()
+		return conn.Hello().Pid, nil
+	}
+	return server.GetPanePID(ctx, sess.TmuxSession)
+}
+
 // disposeRemoteSession disposes of a remote session via control mode.
 func (m *Manager) disposeRemoteSession(ctx context.Context, sess state.Session) error {
 	var warnings []string
@@ -1818,6 +2050,7 @@ func (m *Manager) disposeRemoteSession(ctx context.Context, sess state.Session)
 
 	// Stop tracker (and its recorder) for remote session
 	m.stopTracker(sess.ID)
+	m.removeScrollback(sess.ID)
 
 	// Stop signal monitor for remote session
 	m.StopRemoteSignalMonitor(sess.ID)
@@ -1871,11 +2104,15 @@ func (m *Manager) disposeRemoteSession(ctx context.Context, sess state.Session)
 }
 
 // GetAttachCommand returns the tmux attach command for a session.
+// Sessions on the pty backend attach through `schmux attach`.
 func (m *Manager) GetAttachCommand(sessionID string) (string, error) {
 	sess, found := m.state.GetSession(sessionID)
 	if !found {
 		return "", fmt.Errorf("session not found: %s", sessionID)
 	}
+	if sess.IsPTYSession() {
+		return "schmux attach " + sess.ID, nil
+	}
 
 	server := m.serverForSocket(sess.TmuxSocket)
 	if server != nil {
@@ -1891,6 +2128,9 @@ func (m *Manager) GetAttachArgs(sessionID string) (binary, socketName, sessionNa
 	if !found {
 		return "", "", "", fmt.Errorf("session not found: %s", sessionID)
 	}
+	if sess.IsPTYSession() {
+		return "", "", "", fmt.Errorf("session %s runs on the pty backend, not tmux", sessionID)
+	}
 	server := m.serverForSocket(sess.TmuxSocket)
 	if server == nil {
 		return "", "", "", fmt.Errorf("no tmux server available")
@@ -1904,6 +2144,9 @@ func (m *Manager) GetOutput(ctx context.Context, sessionID string) (string, erro
 	if !found {
 		return "", fmt.Errorf("session not found: %s", sessionID)
 	}
+	if sess.IsPTYSession() {
+		return m.capturePTYScrollback(ctx, sessionID, false)
+	}
 
 	server := m.serverForSocket(sess.TmuxSocket)
 	if server != nil {
@@ -1920,6 +2163,9 @@ func (m *Manager) GetPlainOutput(ctx context.Context, sessionID string) (string,
 	if !found {
 		return "", fmt.Errorf("session not found: %s", sessionID)
 	}
+	if sess.IsPTYSession() {
+		return m.capturePTYScrollback(ctx, sessionID, true)
+	}
 
 	server := m.serverForSocket(sess.TmuxSocket)
 	if server != nil {
@@ -1928,6 +2174,20 @@ func (m *Manager) GetPlainOutput(ctx context.Context, sessionID string) (string,
 	return "", fmt.Errorf("no tmux server available")
 }
 
+// capturePTYScrollback returns a pty session's full retained history from its
+// tracker's screen model, optionally without ANSI formatting.
+func (m *Manager) capturePTYScrollback(ctx context.Context, sessionID string, plain bool) (string, error) {
+	tracker, err := m.GetTracker(sessionID)
+	if err != nil {
+		return "", err
+	}
+	out, err := tracker.CaptureLastLines(ctx, screenHistoryLines)
+	if err != nil || !plain {
+		return out, err
+	}
+	return stripSGR(out), nil
+}
+
 // GetAllSessions returns all sessions.
 func (m *Manager) GetAllSessions() []state.Session {
 	return m.state.GetSessions()
@@ -1962,10 +2222,10 @@ func (m *Manager) RenameSession(ctx context.Context, sessionID, newNickname stri
 		newTmuxName = sanitizeNickname(newNickname)
 	}
 
-	// Rename the tmux session
+	// Rename the tmux session (pty sessions only carry the name in state)
 	var renameErr error
 	server := m.serverForSocket(sess.TmuxSocket)
-	if server != nil {
+	if server != nil && !sess.IsPTYSession() {
 		renameErr = server.RenameSession(ctx, oldTmuxName, newTmuxName)
 	}
 	if renameErr != nil {
@@ -2099,7 +2359,7 @@ func (m *Manager) CaptureLastLines(ctx context.Context, sessionID string, lines
 	}
 	// Fallback to direct tmux CLI
 	sess, ok := m.state.GetSession(sessionID)
-	if !ok {
+	if !ok || sess.IsPTYSession() {
 		return "", trackerErr
 	}
 	server := m.ServerForSocket(sess.TmuxSocket)
@@ -2122,7 +2382,7 @@ func (m *Manager) GetCursorState(ctx context.Context, sessionID string) (control
 	}
 	// Fallback to direct tmux CLI
 	sess, ok := m.state.GetSession(sessionID)
-	if !ok {
+	if !ok || sess.IsPTYSession() {
 		return controlmode.CursorState{}, trackerErr
 	}
 	server := m.ServerForSocket(sess.TmuxSocket)
@@ -2162,6 +2422,51 @@ func (m *Manager) wireRecorder(tracker *SessionRuntime, sessionID string, dims .
 	}
 }
 
+// wireScrollback restores a session's persisted output into the tracker and
+// has the tracker persist further output. Must run before tracker.Start.
+func (m *Manager) wireScrollback(tracker *SessionRuntime, sessionID string) {
+	if m.scrollbackDir == "" {
+		return
+	}
+	store, err := OpenScrollbackStore(filepath.Join(m.scrollbackDir, sessionID))
+	if err != nil {
+		m.logger.Warn("failed to open scrollback store", "session", sessionID, "err", err)
+		return
+	}
+	if err := tracker.RestoreScrollback(store); err != nil {
+		m.logger.Warn("failed to restore scrollback", "session", sessionID, "err", err)
+		store.Close()
+	}
+}
+
+// removeScrollback deletes a disposed session's persisted output. The
+// tracker must already be stopped.
+func (m *Manager) removeScrollback(sessionID string) {
+	if m.scrollbackDir == "" {
+		return
+	}
+	if err := os.RemoveAll(filepath.Join(m.scrollbackDir, sessionID)); err != nil {
+		m.logger.Warn("failed to remove scrollback", "session", sessionID, "err", err)
+	}
+}
+
+// PruneScrollback deletes persisted output of sessions that are no longer in
+// state, e.g. ones removed while the daemon was not running.
+func (m *Manager) PruneScrollback() {
+	if m.scrollbackDir == "" {
+		return
+	}
+	entries, err := os.ReadDir(m.scrollbackDir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if _, found := m.state.GetSession(e.Name()); !found && e.IsDir() {
+			m.removeScrollback(e.Name())
+		}
+	}
+}
+
 func (m *Manager) ensureTrackerFromSession(sess state.Session) *SessionRuntime {
 	m.mu.Lock()
 	if existing := m.trackers[sess.ID]; existing != nil {
@@ -2199,6 +2504,11 @@ func (m *Manager) ensureTrackerFromSession(sess state.Session) *SessionRuntime {
 			source = rs
 		}
 	}
+	if source == nil && sess.IsPTYSession() {
+		ps := NewPTYSource(sess.ID, ptyhost.SocketPath(sess.ID), m.logger)
+		ps.Start()
+		source = ps
+	}
 	if source == nil {
 		ls := NewLocalSource(sess.ID, sess.TmuxSession, m.serverForSocket(sess.TmuxSocket), m.logger)
 		ls.Start()
@@ -2207,8 +2517,8 @@ func (m *Manager) ensureTrackerFromSession(sess state.Session) *SessionRuntime {
 
 	tracker := NewSessionRuntime(sess.ID, source, m.state, eventFilePath, m.eventHandlers, outputCb, m.logger)
 
-	// Query actual tmux pane size for accurate recording dimensions (local sessions only)
-	if srv := m.serverForSocket(sess.TmuxSocket); srv != nil {
+	// Query actual tmux pane size for accurate recording dimensions (local tmux sessions only)
+	if srv := m.serverForSocket(sess.TmuxSocket); srv != nil && !sess.IsPTYSession() {
 		ctx := context.Background()
 		if w, h, err := srv.GetPaneSize(ctx, sess.TmuxSession); err == nil {
 			m.wireRecorder(tracker, sess.ID, w, h)
@@ -2218,6 +2528,7 @@ func (m *Manager) ensureTrackerFromSession(sess state.Session) *SessionRuntime {
 	} else {
 		m.wireRecorder(tracker, sess.ID)
 	}
+	m.wireScrollback(tracker, sess.ID)
 
 	m.trackers[sess.ID] = tracker
 
@@ -2239,6 +2550,22 @@ func (m *Manager) Stop() {
 	}
 }
 
+// RuntimeDiagnostics returns DiagnosticCounters for every session that has a
+// running tracker, keyed by session ID. Unlike GetTracker it never creates one.
+func (m *Manager) RuntimeDiagnostics() map[string]map[string]int64 {
+	m.mu.RLock()
+	trackers := make(map[string]*SessionRuntime, len(m.trackers))
+	for id, t := range m.trackers {
+		trackers[id] = t
+	}
+	m.mu.RUnlock()
+	out := make(map[string]map[string]int64, len(trackers))
+	for id, t := range trackers {
+		out[id] = t.DiagnosticCounters()
+	}
+	return out
+}
+
 func (m *Manager) stopTracker(sessionID string) {
 	m.mu.Lock()
 	tracker := m.trackers[sessionID]
@@ -56,6 +56,24 @@ func (l *OutputLog) Append(data []byte) uint64 {
 	return seq
 }
 
+// Restore loads previously persisted entries into an empty log so sequence
+// numbers continue where they left off. Entries must be in ascending,
+// contiguous seq order; only the newest cap entries are kept. Must be called
+// before the first Append.
+func (l *OutputLog) Restore(entries []LogEntry) {
+	if len(entries) > l.cap {
+		entries = entries[len(entries)-l.cap:]
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	copy(l.entries, entries)
+	l.size = len(entries)
+	l.head = l.size % l.cap
+	if l.size > 0 {
+		l.nextSeq = entries[l.size-1].Seq + 1
+	}
+}
+
 // CurrentSeq returns the next sequence number that will be assigned.
 // If 3 events have been appended (seq 0,1,2), CurrentSeq returns 3.
 func (l *OutputLog) CurrentSeq() uint64 {
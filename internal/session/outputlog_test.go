@@ -275,3 +275,23 @@ func TestOutputLogNotify_WaitForNew_StopCh(t *testing.T) {
 		t.Fatal("WaitForNew did not return within timeout after stopCh closed")
 	}
 }
+
+func TestOutputLog_RestoreContinuesSeq(t *testing.T) {
+	log := NewOutputLog(3)
+	var entries []LogEntry
+	for i := uint64(10); i < 15; i++ {
+		entries = append(entries, LogEntry{Seq: i, Data: []byte{byte('a' + i - 10)}})
+	}
+	log.Restore(entries)
+
+	if log.OldestSeq() != 12 || log.CurrentSeq() != 15 {
+		t.Fatalf("oldest=%d current=%d, want 12 and 15", log.OldestSeq(), log.CurrentSeq())
+	}
+	if seq := log.Append([]byte("f")); seq != 15 {
+		t.Errorf("Append after Restore seq=%d, want 15", seq)
+	}
+	got := log.ReplayFrom(13)
+	if len(got) != 3 || string(got[0].Data) != "d" || string(got[2].Data) != "f" {
+		t.Errorf("ReplayFrom(13) = %v, want d e f", got)
+	}
+}
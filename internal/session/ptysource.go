@@ -0,0 +1,303 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sergeknystautas/schmux/internal/ptyhost"
+	"github.com/sergeknystautas/schmux/internal/remote/controlmode"
+)
+
+// errPTYExited is returned by PTYSource.attach when the supervised process
+// has exited; the source then closes instead of reconnecting.
+var errPTYExited = errors.New("pty session exited")
+
+// PTYSource implements ControlSource for sessions on the pty backend. The
+// agent runs under a PTY owned by a detached `schmux pty-host` supervisor;
+// the source talks to it over a unix socket and keeps an in-process VT
+// emulator so captures and cursor queries never leave the daemon.
+type PTYSource struct {
+	sessionID  string
+	socketPath string
+	logger     *log.Logger
+	events     chan SourceEvent
+	screen     *terminalScreen
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	doneCh   chan struct{}
+
+	mu           sync.RWMutex
+	conn         *ptyhost.Conn
+	hasAttached  bool
+	lastRetryLog time.Time
+}
+
+// NewPTYSource creates a PTYSource for the supervisor listening on socketPath.
+func NewPTYSource(sessionID, socketPath string, logger *log.Logger) *PTYSource {
+	return &PTYSource{
+		sessionID:  sessionID,
+		socketPath: socketPath,
+		logger:     logger,
+		events:     make(chan SourceEvent, 1000),
+		screen:     newTerminalScreen(80, 24),
+		stopCh:     make(chan struct{}),
+		doneCh:     make(chan struct{}),
+	}
+}
+
+func (s *PTYSource) Events() <-chan SourceEvent { return s.events }
+
+// Start launches the connection loop in a background goroutine.
+func (s *PTYSource) Start() {
+	go s.run()
+}
+
+func (s *PTYSource) SendKeys(keys string) (controlmode.SendKeysTimings, error) {
+	conn := s.currentConn()
+	if conn == nil {
+		return controlmode.SendKeysTimings{}, fmt.Errorf("not attached")
+	}
+	start := time.Now()
+	if _, err := conn.Write([]byte(keys)); err != nil {
+		return controlmode.SendKeysTimings{}, err
+	}
+	return controlmode.SendKeysTimings{ExecuteNet: time.Since(start), ExecuteCount: 1}, nil
+}
+
+func (s *PTYSource) SendTmuxKeyName(name string) error {
+	seq, err := ptyKeySequence(name)
+	if err != nil {
+		return err
+	}
+	_, err = s.SendKeys(seq)
+	return err
+}
+
+func (s *PTYSource) CaptureVisible() (string, error) {
+	if !s.attachedOnce() {
+		return "", fmt.Errorf("not attached")
+	}
+	return s.screen.Visible(true), nil
+}
+
+func (s *PTYSource) CaptureLines(n int) (string, error) {
+	if !s.attachedOnce() {
+		return "", fmt.Errorf("not attached")
+	}
+	return s.screen.Lines(n, true), nil
+}
+
+func (s *PTYSource) GetCursorState() (controlmode.CursorState, error) {
+	if !s.attachedOnce() {
+		return controlmode.CursorState{}, fmt.Errorf("not attached")
+	}
+	return s.screen.Cursor(), nil
+}
+
+// Resize resizes the PTY. The local emulator follows when the supervisor
+// echoes the applied size back.
+func (s *PTYSource) Resize(cols, rows int) error {
+	conn := s.currentConn()
+	if conn == nil {
+		return fmt.Errorf("not attached")
+	}
+	return conn.Resize(cols, rows)
+}
+
+// IsAttached reports whether the source is connected to its supervisor.
+func (s *PTYSource) IsAttached() bool {
+	return s.currentConn() != nil
+}
+
+// Close disconnects from the supervisor. The agent keeps running.
+func (s *PTYSource) Close() error {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+	<-s.doneCh
+	return nil
+}
+
+func (s *PTYSource) currentConn() *ptyhost.Conn {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.conn
+}
+
+func (s *PTYSource) attachedOnce() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hasAttached
+}
+
+// run is the connection loop. A missing supervisor or an exited process is
+// permanent and emits SourceClosed; anything else is retried.
+func (s *PTYSource) run() {
+	defer close(s.doneCh)
+	defer close(s.events)
+
+	for {
+		select {
+		case <-s.stopCh:
+			s.emit(SourceEvent{Type: SourceClosed})
+			return
+		default:
+		}
+
+		err := s.attach()
+		if errors.Is(err, ptyhost.ErrNoSession) || errors.Is(err, errPTYExited) {
+			if s.logger != nil {
+				s.logger.Debug("stopping: pty session no longer exists", "session", s.sessionID)
+			}
+			s.emit(SourceEvent{Type: SourceClosed, Err: err})
+			return
+		}
+		if err != nil && s.shouldLogRetry(time.Now()) && s.logger != nil {
+			s.logger.Warn("pty connection failed", "session", s.sessionID, "err", err)
+		}
+
+		if s.waitOrStop(trackerRestartDelay) {
+			s.emit(SourceEvent{Type: SourceClosed})
+			return
+		}
+	}
+}
+
+// attach runs a single supervisor connection lifecycle.
+func (s *PTYSource) attach() error {
+	conn, err := ptyhost.Dial(s.socketPath)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The supervisor sends its replay buffer right after hello. Rebuilding the
+	// emulator from it makes captures correct after a daemon restart.
+	first, err := conn.ReadFrame()
+	if err != nil {
+		return err
+	}
+	if first.Type == ptyhost.FrameExit {
+		return errPTYExited
+	}
+	hello := conn.Hello()
+	s.screen.Reset(hello.Cols, hello.Rows)
+	s.screen.Write(first.Payload)
+
+	s.mu.Lock()
+	reconnect := s.hasAttached
+	s.conn = conn
+	s.hasAttached = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.conn = nil
+		s.mu.Unlock()
+	}()
+
+	select {
+	case <-s.stopCh:
+		return nil
+	default:
+	}
+
+	if reconnect {
+		s.emit(SourceEvent{Type: SourceGap, Reason: "pty_reconnect", Snapshot: s.screen.Visible(true)})
+	} else if len(first.Payload) > 0 {
+		s.emit(SourceEvent{Type: SourceOutput, Data: string(first.Payload)})
+	}
+
+	for {
+		f, err := conn.ReadFrame()
+		if err != nil {
+			return err
+		}
+		switch f.Type {
+		case ptyhost.FrameOutput:
+			s.screen.Write(f.Payload)
+			s.emit(SourceEvent{Type: SourceOutput, Data: string(f.Payload)})
+		case ptyhost.FrameResize:
+			var sz ptyhost.Size
+			if json.Unmarshal(f.Payload, &sz) == nil {
+				s.screen.Resize(sz.Cols, sz.Rows)
+			}
+		case ptyhost.FrameExit:
+			return errPTYExited
+		}
+	}
+}
+
+func (s *PTYSource) emit(e SourceEvent) {
+	select {
+	case s.events <- e:
+	default:
+	}
+}
+
+func (s *PTYSource) shouldLogRetry(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastRetryLog.IsZero() || now.Sub(s.lastRetryLog) >= trackerRetryLogInterval {
+		s.lastRetryLog = now
+		return true
+	}
+	return false
+}
+
+func (s *PTYSource) waitOrStop(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return false
+	case <-s.stopCh:
+		return true
+	}
+}
+
+// ptyNamedKeys maps the tmux key names schmux sends to the bytes a terminal
+// would produce for them.
+var ptyNamedKeys = map[string]string{
+	"Enter":  "\r",
+	"Escape": "\x1b",
+	"Tab":    "\t",
+	"BTab":   "\x1b[Z",
+	"BSpace": "\x7f",
+	"Space":  " ",
+	"Up":     "\x1b[A",
+	"Down":   "\x1b[B",
+	"Right":  "\x1b[C",
+	"Left":   "\x1b[D",
+	"Home":   "\x1b[H",
+	"End":    "\x1b[F",
+	"DC":     "\x1b[3~",
+	"PPage":  "\x1b[5~",
+	"NPage":  "\x1b[6~",
+}
+
+// ptyKeySequence translates a tmux key name ("Enter", "C-u") to raw bytes.
+func ptyKeySequence(name string) (string, error) {
+	if seq, ok := ptyNamedKeys[name]; ok {
+		return seq, nil
+	}
+	if rest, ok := strings.CutPrefix(name, "C-"); ok && len(rest) == 1 {
+		c := rest[0]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		if c >= 'a' && c <= 'z' {
+			return string(rune(c - 'a' + 1)), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported key name: %s", name)
+}
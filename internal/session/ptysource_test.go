@@ -0,0 +1,145 @@
+package session
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/ptyhost"
+)
+
+func TestPTYKeySequence(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Enter", "\r"},
+		{"Escape", "\x1b"},
+		{"Up", "\x1b[A"},
+		{"C-c", "\x03"},
+		{"C-U", "\x15"},
+	}
+	for _, tt := range tests {
+		got, err := ptyKeySequence(tt.name)
+		if err != nil || got != tt.want {
+			t.Errorf("ptyKeySequence(%q) = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+	for _, name := range []string{"F13", "C-", "C-1", "M-x"} {
+		if _, err := ptyKeySequence(name); err == nil {
+			t.Errorf("ptyKeySequence(%q) succeeded, want error", name)
+		}
+	}
+}
+
+func TestPTYSource_MethodsFailWhenNotAttached(t *testing.T) {
+	source := NewPTYSource("s1", filepath.Join(t.TempDir(), "missing.sock"), nil)
+
+	if _, err := source.SendKeys("abc"); err == nil {
+		t.Error("SendKeys should fail when not attached")
+	}
+	if _, err := source.CaptureLines(10); err == nil {
+		t.Error("CaptureLines should fail when not attached")
+	}
+	if _, err := source.GetCursorState(); err == nil {
+		t.Error("GetCursorState should fail when not attached")
+	}
+	if err := source.Resize(80, 24); err == nil {
+		t.Error("Resize should fail when not attached")
+	}
+}
+
+func TestPTYSource_MissingSupervisorCloses(t *testing.T) {
+	source := NewPTYSource("s1", filepath.Join(t.TempDir(), "missing.sock"), nil)
+	source.Start()
+	defer source.Close()
+
+	select {
+	case ev := <-source.Events():
+		if ev.Type != SourceClosed || !errors.Is(ev.Err, ptyhost.ErrNoSession) {
+			t.Errorf("event = %+v, want SourceClosed with ErrNoSession", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event from source")
+	}
+}
+
+func TestPTYSource_AgainstHost(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "s.sock")
+	host, err := ptyhost.Start(ptyhost.Options{SessionID: "s1", SocketPath: sock, Dir: t.TempDir(), Command: "echo ready; cat", Cols: 40, Rows: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan int, 1)
+	go func() { done <- host.Wait() }()
+
+	source := NewPTYSource("s1", sock, nil)
+	source.Start()
+
+	waitForScreen := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if got, err := source.CaptureVisible(); err == nil && strings.Contains(got, want) {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		got, _ := source.CaptureVisible()
+		t.Fatalf("screen never contained %q: %q", want, got)
+	}
+	waitForScreen("ready")
+
+	if err := source.SendTmuxKeyName("Enter"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.SendKeys("hello\r"); err != nil {
+		t.Fatal(err)
+	}
+	waitForScreen("hello")
+
+	if err := source.Resize(60, 12); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for strings.Count(mustCaptureVisible(t, source), "\n") != 12 {
+		if time.Now().After(deadline) {
+			t.Fatal("emulator did not follow resize")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Killing the agent closes the source with an error instead of retrying.
+	if err := ptyhost.Kill(t.Context(), sock); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	for {
+		select {
+		case ev, ok := <-source.Events():
+			if !ok {
+				t.Fatal("events closed without SourceClosed")
+			}
+			if ev.Type == SourceClosed {
+				if ev.Err == nil {
+					t.Error("SourceClosed after exit has no error")
+				}
+				source.Close()
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("source did not close after the agent exited")
+		}
+	}
+}
+
+func mustCaptureVisible(t *testing.T, s *PTYSource) string {
+	t.Helper()
+	out, err := s.CaptureVisible()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
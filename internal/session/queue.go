@@ -0,0 +1,548 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/events"
+	"github.com/sergeknystautas/schmux/internal/fileutil"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+const (
+	// commandTarget is the target name recorded for raw command sessions.
+	commandTarget = "command"
+	// queueDrainInterval is how often the queue re-checks limits even without
+	// a dispose or completion, so raised limits take effect.
+	queueDrainInterval = 30 * time.Second
+	// queuedSpawnTimeout bounds a drained spawn (workspace prep + tmux).
+	queuedSpawnTimeout = 5 * time.Minute
+	// maxRunDurationSamples is how many recent session run times feed ETAs.
+	maxRunDurationSamples = 20
+)
+
+// queuedSpawn is a spawn deferred by concurrency limits. The session ID and
+// workspace are fixed at enqueue time so the placeholder session in state
+// becomes the real session when the entry is drained.
+type queuedSpawn struct {
+	SessionID  string       `json:"session_id"`
+	RepoURL    string       `json:"repo_url"`
+	Target     string       `json:"target"`
+	Command    bool         `json:"command,omitempty"`
+	Priority   int          `json:"priority,omitempty"`
+	EnqueuedAt time.Time    `json:"enqueued_at"`
+	Opts       SpawnOptions `json:"opts"`
+}
+
+// admission is a concurrency slot held by a spawn that has been admitted but
+// has not yet landed in state as a running session.
+type admission struct {
+	repoURL string
+	target  string
+}
+
+// spawnQueue is the persisted priority queue behind the concurrency limits.
+// Entries are ordered by priority (higher first), then FIFO.
+type spawnQueue struct {
+	mu        sync.Mutex
+	drainMu   sync.Mutex // serializes drain passes
+	path      string     // empty = in-memory only
+	entries   []queuedSpawn
+	inflight  map[*admission]struct{}
+	durations []time.Duration // recent session run times, for ETA estimates
+}
+
+func newSpawnQueue(path string) *spawnQueue {
+	return &spawnQueue{path: path, inflight: make(map[*admission]struct{})}
+}
+
+// load reads persisted entries. A missing file is an empty queue.
+func (q *spawnQueue) load() error {
+	if q.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(q.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read spawn queue: %w", err)
+	}
+	var entries []queuedSpawn
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("parse spawn queue: %w", err)
+	}
+	q.mu.Lock()
+	q.entries = entries
+	q.sortLocked()
+	q.mu.Unlock()
+	return nil
+}
+
+// saveLocked persists the queue. Caller must hold q.mu.
+func (q *spawnQueue) saveLocked() error {
+	if q.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(q.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal spawn queue: %w", err)
+	}
+	return fileutil.AtomicWriteFile(q.path, data, 0600)
+}
+
+func (q *spawnQueue) sortLocked() {
+	sort.SliceStable(q.entries, func(i, j int) bool {
+		if q.entries[i].Priority != q.entries[j].Priority {
+			return q.entries[i].Priority > q.entries[j].Priority
+		}
+		return q.entries[i].EnqueuedAt.Before(q.entries[j].EnqueuedAt)
+	})
+}
+
+// hasWaitingAtOrAbove reports whether an entry of at least the given priority
+// is waiting, in which case a fresh spawn must not jump ahead of it.
+func (q *spawnQueue) hasWaitingAtOrAbove(priority int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, e := range q.entries {
+		if e.Priority >= priority {
+			return true
+		}
+	}
+	return false
+}
+
+// recordRunDuration feeds a finished session's run time into ETA estimates.
+func (q *spawnQueue) recordRunDuration(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.durations = append(q.durations, d)
+	if len(q.durations) > maxRunDurationSamples {
+		q.durations = q.durations[len(q.durations)-maxRunDurationSamples:]
+	}
+}
+
+// QueueInfo describes a queued session's place in line.
+type QueueInfo struct {
+	// Position is 1-based within the whole queue.
+	Position int
+	// ETA is the estimated start time; zero when no run times are known yet.
+	ETA time.Time
+}
+
+// activeCountsLocked counts sessions holding a concurrency slot: local sessions that
+// are not queued, failed, stopped, or disposing and whose agent has not
+// reported completed, plus admitted spawns still in flight. Caller must hold
+// m.queue.mu.
+func (m *Manager) activeCountsLocked() (total int, byRepo, byTarget map[string]int) {
+	byRepo = make(map[string]int)
+	byTarget = make(map[string]int)
+	repoOf := make(map[string]string)
+	for _, ws := range m.state.GetWorkspaces() {
+		repoOf[ws.ID] = ws.Repo
+	}
+	for _, sess := range m.state.GetSessions() {
+		if !holdsConcurrencySlot(sess) {
+			continue
+		}
+		total++
+		byRepo[repoOf[sess.WorkspaceID]]++
+		byTarget[sess.Target]++
+	}
+	for a := range m.queue.inflight {
+		total++
+		byRepo[a.repoURL]++
+		byTarget[a.target]++
+	}
+	return total, byRepo, byTarget
+}
+
+// holdsConcurrencySlot reports whether a session counts against limits.
+func holdsConcurrencySlot(sess state.Session) bool {
+	if sess.IsRemoteSession() {
+		return false
+	}
+	switch sess.Status {
+	case state.SessionStatusQueued, state.SessionStatusFailed, state.SessionStatusStopped, state.SessionStatusDisposing:
+		return false
+	}
+	return nudgeState(sess.Nudge) != "Completed"
+}
+
+// nudgeState extracts the display state from a serialized nudge result.
+func nudgeState(nudge string) string {
+	if nudge == "" {
+		return ""
+	}
+	var n struct {
+		State string `json:"state"`
+	}
+	if err := json.Unmarshal([]byte(nudge), &n); err != nil {
+		return ""
+	}
+	return n.State
+}
+
+// repoLimitName maps a repo URL to the name used for per-repo limits.
+func (m *Manager) repoLimitName(repoURL string) string {
+	if r, found := m.config.FindRepoByURL(repoURL); found {
+		return r.Name
+	}
+	return repoURL
+}
+
+// fitsLocked reports whether one more session for repoURL/target stays
+// within limits. Caller must hold m.queue.mu.
+func (m *Manager) fitsLocked(limits config.ConcurrencyLimits, repoURL, target string) bool {
+	total, byRepo, byTarget := m.activeCountsLocked()
+	if limits.Max > 0 && total >= limits.Max {
+		return false
+	}
+	if n := limits.RepoLimit(m.repoLimitName(repoURL)); n > 0 && byRepo[repoURL] >= n {
+		return false
+	}
+	if n := limits.TargetLimit(target); n > 0 && byTarget[target] >= n {
+		return false
+	}
+	return true
+}
+
+// admitLocked reserves a slot. The returned release must be called once the
+// session is in state (or the spawn failed). Caller must hold m.queue.mu.
+func (m *Manager) admitLocked(repoURL, target string) func() {
+	a := &admission{repoURL: repoURL, target: target}
+	m.queue.inflight[a] = struct{}{}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.queue.mu.Lock()
+			delete(m.queue.inflight, a)
+			m.queue.mu.Unlock()
+		})
+	}
+}
+
+// spawnRepoURL resolves the repo a spawn will land in, for per-repo limits.
+func (m *Manager) spawnRepoURL(opts SpawnOptions) string {
+	if opts.WorkspaceID != "" {
+		if ws, found := m.state.GetWorkspace(opts.WorkspaceID); found {
+			return ws.Repo
+		}
+	}
+	return opts.RepoURL
+}
+
+// admitOrEnqueue applies the spawn gate and the concurrency limits to a
+// spawn. It returns either a release func for an admitted spawn, or the
+// placeholder session of a queued one. Spawns drained from the queue already
+// hold their slot.
+func (m *Manager) admitOrEnqueue(ctx context.Context, opts SpawnOptions, command bool) (func(), *state.Session, error) {
+	if opts.queuedSessionID != "" {
+		return func() {}, nil, nil
+	}
+	if err := m.CheckSpawnGate(opts.WorkspaceID); err != nil {
+		return nil, nil, err
+	}
+	limits := m.config.GetConcurrencyLimits()
+	if !limits.Enabled() {
+		return func() {}, nil, nil
+	}
+	target := opts.TargetName
+	if command {
+		target = commandTarget
+	}
+	repoURL := m.spawnRepoURL(opts)
+	if !m.queue.hasWaitingAtOrAbove(opts.Priority) {
+		m.queue.mu.Lock()
+		if m.fitsLocked(limits, repoURL, target) {
+			release := m.admitLocked(repoURL, target)
+			m.queue.mu.Unlock()
+			return release, nil, nil
+		}
+		m.queue.mu.Unlock()
+	}
+	sess, err := m.enqueue(ctx, opts, repoURL, target, command)
+	return nil, sess, err
+}
+
+// enqueue prepares the workspace, records a queued placeholder session, and
+// persists the queue entry.
+func (m *Manager) enqueue(ctx context.Context, opts SpawnOptions, repoURL, target string, command bool) (*state.Session, error) {
+	w, err := m.resolveWorkspace(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	opts.WorkspaceID = w.ID
+	opts.NewBranch = ""
+	if repoURL == "" {
+		repoURL = w.Repo
+	}
+
+	sessionID := fmt.Sprintf("%s-%s", w.ID, uuid.New().String()[:8])
+	if opts.Nickname != "" {
+		opts.Nickname = m.generateUniqueNickname(opts.Nickname)
+	}
+	tmuxSession := sessionID
+	if opts.Nickname != "" {
+		tmuxSession = sanitizeNickname(opts.Nickname)
+	}
+	socket := ""
+	if m.server != nil {
+		socket = m.server.SocketName()
+	}
+	sess := state.Session{
+		ID:          sessionID,
+		WorkspaceID: w.ID,
+		Target:      target,
+		Nickname:    opts.Nickname,
+		PersonaID:   opts.PersonaID,
+		StyleID:     opts.StyleID,
+		TmuxSession: tmuxSession,
+		TmuxSocket:  socket,
+		CreatedAt:   time.Now(),
+		Status:      state.SessionStatusQueued,
+		Fence:       opts.Fence,
+	}
+	if err := m.state.AddSession(sess); err != nil {
+		return nil, fmt.Errorf("failed to add session to state: %w", err)
+	}
+	if err := m.state.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save state: %w", err)
+	}
+
+	m.queue.mu.Lock()
+	m.queue.entries = append(m.queue.entries, queuedSpawn{
+		SessionID:  sessionID,
+		RepoURL:    repoURL,
+		Target:     target,
+		Command:    command,
+		Priority:   opts.Priority,
+		EnqueuedAt: sess.CreatedAt,
+		Opts:       opts,
+	})
+	m.queue.sortLocked()
+	if err := m.queue.saveLocked(); err != nil {
+		m.logger.Warn("failed to persist spawn queue", "err", err)
+	}
+	m.queue.mu.Unlock()
+
+	m.logger.Info("spawn queued", "session", sessionID, "repo", repoURL, "target", target, "priority", opts.Priority)
+	m.kickQueue()
+	return &sess, nil
+}
+
+// dequeue drops a waiting entry. Returns false if the session is not queued.
+func (m *Manager) dequeue(sessionID string) bool {
+	m.queue.mu.Lock()
+	defer m.queue.mu.Unlock()
+	for i, e := range m.queue.entries {
+		if e.SessionID == sessionID {
+			m.queue.entries = append(m.queue.entries[:i], m.queue.entries[i+1:]...)
+			if err := m.queue.saveLocked(); err != nil {
+				m.logger.Warn("failed to persist spawn queue", "err", err)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// kickQueue drains the queue in the background when anything is waiting.
+func (m *Manager) kickQueue() {
+	m.queue.mu.Lock()
+	waiting := len(m.queue.entries) > 0
+	m.queue.mu.Unlock()
+	if !waiting {
+		return
+	}
+	go m.DrainQueue()
+}
+
+// DrainQueue starts every queued spawn that fits within the current limits,
+// in priority order. Entries blocked by a per-repo or per-target limit do not
+// hold back entries for other repos or targets.
+func (m *Manager) DrainQueue() {
+	m.queue.drainMu.Lock()
+	defer m.queue.drainMu.Unlock()
+	limits := m.config.GetConcurrencyLimits()
+	for {
+		m.queue.mu.Lock()
+		var (
+			entry   queuedSpawn
+			release func()
+		)
+		for i, e := range m.queue.entries {
+			if limits.Enabled() && !m.fitsLocked(limits, e.RepoURL, e.Target) {
+				continue
+			}
+			entry = e
+			release = m.admitLocked(e.RepoURL, e.Target)
+			m.queue.entries = append(m.queue.entries[:i], m.queue.entries[i+1:]...)
+			if err := m.queue.saveLocked(); err != nil {
+				m.logger.Warn("failed to persist spawn queue", "err", err)
+			}
+			break
+		}
+		m.queue.mu.Unlock()
+		if release == nil {
+			return
+		}
+		go m.spawnQueued(entry, release)
+	}
+}
+
+// spawnQueued runs a drained entry under the manager's shutdown context. On
+// failure the placeholder is kept with status failed so the user sees what
+// happened.
+func (m *Manager) spawnQueued(entry queuedSpawn, release func()) {
+	defer release()
+	ctx, cancel := context.WithTimeout(m.shutdownCtx, queuedSpawnTimeout)
+	defer cancel()
+
+	opts := entry.Opts
+	opts.queuedSessionID = entry.SessionID
+	var err error
+	if entry.Command {
+		_, err = m.SpawnCommand(ctx, opts)
+	} else {
+		_, err = m.Spawn(ctx, opts)
+	}
+	if err != nil {
+		m.logger.Error("queued spawn failed", "session", entry.SessionID, "err", err)
+		m.state.UpdateSessionFunc(entry.SessionID, func(s *state.Session) {
+			s.Status = state.SessionStatusFailed
+		})
+		if err := m.state.Save(); err != nil {
+			m.logger.Warn("failed to save state", "err", err)
+		}
+	} else {
+		m.logger.Info("queued spawn started", "session", entry.SessionID, "waited", time.Since(entry.EnqueuedAt).Round(time.Second))
+	}
+	if m.queueCallback != nil {
+		m.queueCallback()
+	}
+}
+
+// QueueInfo returns the queue position and estimated start time of a queued
+// session.
+func (m *Manager) QueueInfo(sessionID string) (QueueInfo, bool) {
+	limits := m.config.GetConcurrencyLimits()
+	m.queue.mu.Lock()
+	defer m.queue.mu.Unlock()
+	for i, e := range m.queue.entries {
+		if e.SessionID != sessionID {
+			continue
+		}
+		info := QueueInfo{Position: i + 1}
+		if avg := m.queue.averageRunLocked(); avg > 0 {
+			capacity := limits.Max
+			for _, n := range []int{limits.RepoLimit(m.repoLimitName(e.RepoURL)), limits.TargetLimit(e.Target)} {
+				if n > 0 && (capacity == 0 || n < capacity) {
+					capacity = n
+				}
+			}
+			if capacity <= 0 {
+				capacity = 1
+			}
+			waves := i/capacity + 1
+			info.ETA = time.Now().Add(time.Duration(waves) * avg).Truncate(time.Second)
+		}
+		return info, true
+	}
+	return QueueInfo{}, false
+}
+
+// averageRunLocked returns the mean recent session run time. Caller must hold
+// q.mu.
+func (q *spawnQueue) averageRunLocked() time.Duration {
+	if len(q.durations) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, d := range q.durations {
+		sum += d
+	}
+	return sum / time.Duration(len(q.durations))
+}
+
+// StartQueue reconciles the persisted queue with state and drains it
+// periodically until ctx is done. Entries whose placeholder session is gone
+// are dropped; queued placeholders with no entry are marked failed.
+func (m *Manager) StartQueue(ctx context.Context) {
+	m.queue.mu.Lock()
+	queued := make(map[string]bool, len(m.queue.entries))
+	kept := m.queue.entries[:0]
+	for _, e := range m.queue.entries {
+		if _, found := m.state.GetSession(e.SessionID); found {
+			kept = append(kept, e)
+			queued[e.SessionID] = true
+		}
+	}
+	m.queue.entries = kept
+	if err := m.queue.saveLocked(); err != nil {
+		m.logger.Warn("failed to persist spawn queue", "err", err)
+	}
+	m.queue.mu.Unlock()
+
+	orphaned := false
+	for _, sess := range m.state.GetSessions() {
+		if sess.Status == state.SessionStatusQueued && !queued[sess.ID] {
+			m.state.UpdateSessionFunc(sess.ID, func(s *state.Session) {
+				s.Status = state.SessionStatusFailed
+			})
+			orphaned = true
+		}
+	}
+	if orphaned {
+		if err := m.state.Save(); err != nil {
+			m.logger.Warn("failed to save state", "err", err)
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(queueDrainInterval)
+		defer ticker.Stop()
+		m.DrainQueue()
+		for {
+			select {
+			case <-ticker.C:
+				m.DrainQueue()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// QueueEventHandler returns an event handler that frees concurrency slots
+// when an agent reports completed. Register it after the dashboard status
+// handler so the session's nudge state is already updated.
+func (m *Manager) QueueEventHandler() events.EventHandler {
+	return queueEventHandler{m: m}
+}
+
+type queueEventHandler struct {
+	m *Manager
+}
+
+func (h queueEventHandler) HandleEvent(_ context.Context, sessionID string, _ events.RawEvent, data []byte) {
+	var evt events.StatusEvent
+	if err := json.Unmarshal(data, &evt); err != nil || evt.State != "completed" {
+		return
+	}
+	if sess, found := h.m.state.GetSession(sessionID); found {
+		h.m.queue.recordRunDuration(time.Since(sess.CreatedAt))
+	}
+	h.m.kickQueue()
+}
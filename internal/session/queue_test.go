@@ -0,0 +1,295 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/workspace"
+)
+
+// newLimitedManager returns a test manager with the given global limit and a
+// single workspace ready to spawn into.
+func newLimitedManager(t *testing.T, max int) (*Manager, *state.State) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.WorkspacePath = t.TempDir()
+	cfg.Sessions = &config.SessionsConfig{MaxConcurrent: max}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath, nil)
+	wm := workspace.New(cfg, st, statePath, log.NewWithOptions(io.Discard, log.Options{}))
+	m := New(cfg, st, statePath, wm, nil, nil)
+	if err := st.AddWorkspace(state.Workspace{
+		ID:     "ws-1",
+		Repo:   "https://github.com/user/repo.git",
+		Branch: "main",
+		Path:   t.TempDir(),
+	}); err != nil {
+		t.Fatalf("AddWorkspace: %v", err)
+	}
+	return m, st
+}
+
+func TestHoldsConcurrencySlot(t *testing.T) {
+	tests := []struct {
+		name string
+		sess state.Session
+		want bool
+	}{
+		{"running", state.Session{Status: state.SessionStatusRunning}, true},
+		{"no status", state.Session{}, true},
+		{"working nudge", state.Session{Nudge: `{"state":"Working"}`}, true},
+		{"completed nudge", state.Session{Nudge: `{"state":"Completed"}`}, false},
+		{"queued", state.Session{Status: state.SessionStatusQueued}, false},
+		{"failed", state.Session{Status: state.SessionStatusFailed}, false},
+		{"stopped", state.Session{Status: state.SessionStatusStopped}, false},
+		{"disposing", state.Session{Status: state.SessionStatusDisposing}, false},
+		{"remote", state.Session{RemoteHostID: "host-1"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := holdsConcurrencySlot(tt.sess); got != tt.want {
+				t.Errorf("holdsConcurrencySlot() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdmitOrEnqueue_NoLimits(t *testing.T) {
+	m, _ := newTestManager(t)
+	release, queued, err := m.admitOrEnqueue(context.Background(), SpawnOptions{TargetName: "claude"}, false)
+	if err != nil || queued != nil {
+		t.Fatalf("admitOrEnqueue() = %v, %v; want admitted", queued, err)
+	}
+	release()
+}
+
+func TestAdmitOrEnqueue_SpawnGate(t *testing.T) {
+	m, _ := newTestManager(t)
+	errOver := errors.New("over budget")
+	var gotWorkspace string
+	m.SetSpawnGate(func(workspaceID string) error {
+		gotWorkspace = workspaceID
+		return errOver
+	})
+	if _, _, err := m.admitOrEnqueue(context.Background(), SpawnOptions{TargetName: "claude", WorkspaceID: "ws-1"}, false); !errors.Is(err, errOver) {
+		t.Fatalf("admitOrEnqueue() error = %v, want gate error", err)
+	}
+	if gotWorkspace != "ws-1" {
+		t.Errorf("gate saw workspace %q, want ws-1", gotWorkspace)
+	}
+	// Queued drains were admitted earlier and skip the gate.
+	if _, _, err := m.admitOrEnqueue(context.Background(), SpawnOptions{TargetName: "claude", queuedSessionID: "q-1"}, false); err != nil {
+		t.Fatalf("queued drain error = %v, want nil", err)
+	}
+}
+
+func TestAdmitOrEnqueue_QueuesAtLimit(t *testing.T) {
+	m, st := newLimitedManager(t, 1)
+	ctx := context.Background()
+
+	release, queued, err := m.admitOrEnqueue(ctx, SpawnOptions{WorkspaceID: "ws-1", TargetName: "claude"}, false)
+	if err != nil || queued != nil {
+		t.Fatalf("first spawn should be admitted, got %v, %v", queued, err)
+	}
+
+	// The in-flight admission holds the only slot.
+	_, queued, err = m.admitOrEnqueue(ctx, SpawnOptions{WorkspaceID: "ws-1", TargetName: "claude", Nickname: "second"}, false)
+	if err != nil {
+		t.Fatalf("admitOrEnqueue() error = %v", err)
+	}
+	if queued == nil {
+		t.Fatal("second spawn should be queued")
+	}
+	sess, found := st.GetSession(queued.ID)
+	if !found {
+		t.Fatal("queued placeholder not in state")
+	}
+	if sess.Status != state.SessionStatusQueued || sess.WorkspaceID != "ws-1" || sess.Nickname != "second" {
+		t.Errorf("unexpected placeholder: %+v", sess)
+	}
+	info, ok := m.QueueInfo(queued.ID)
+	if !ok || info.Position != 1 {
+		t.Errorf("QueueInfo() = %+v, %v; want position 1", info, ok)
+	}
+	release()
+}
+
+func TestAdmitOrEnqueue_RunningSessionsCount(t *testing.T) {
+	m, st := newLimitedManager(t, 1)
+	st.AddSession(state.Session{ID: "ws-1-run", WorkspaceID: "ws-1", Target: "claude", Status: state.SessionStatusRunning})
+
+	_, queued, err := m.admitOrEnqueue(context.Background(), SpawnOptions{WorkspaceID: "ws-1", TargetName: "claude"}, false)
+	if err != nil || queued == nil {
+		t.Fatalf("spawn should be queued behind the running session, got %v, %v", queued, err)
+	}
+
+	// Once the agent reports completed, its slot is free.
+	st.UpdateSessionFunc("ws-1-run", func(s *state.Session) { s.Nudge = `{"state":"Completed"}` })
+	m.queue.mu.Lock()
+	fits := m.fitsLocked(m.config.GetConcurrencyLimits(), "https://github.com/user/repo.git", "claude")
+	m.queue.mu.Unlock()
+	if !fits {
+		t.Error("completed session should not hold a slot")
+	}
+}
+
+func TestAdmitOrEnqueue_PerTargetLimit(t *testing.T) {
+	m, st := newLimitedManager(t, 0)
+	m.config.Sessions = &config.SessionsConfig{MaxConcurrentPerTarget: map[string]int{"claude": 1}}
+	st.AddSession(state.Session{ID: "ws-1-run", WorkspaceID: "ws-1", Target: "claude"})
+
+	release, queued, err := m.admitOrEnqueue(context.Background(), SpawnOptions{WorkspaceID: "ws-1", TargetName: "codex"}, false)
+	if err != nil || queued != nil {
+		t.Fatalf("other target should be admitted, got %v, %v", queued, err)
+	}
+	release()
+
+	_, queued, err = m.admitOrEnqueue(context.Background(), SpawnOptions{WorkspaceID: "ws-1", TargetName: "claude"}, false)
+	if err != nil || queued == nil {
+		t.Fatalf("same target should be queued, got %v, %v", queued, err)
+	}
+}
+
+func TestDrainQueue_StartsEntryOnceSlotFrees(t *testing.T) {
+	m, st := newLimitedManager(t, 1)
+	st.AddSession(state.Session{ID: "ws-1-run", WorkspaceID: "ws-1", Target: "claude"})
+	done := make(chan struct{}, 1)
+	m.SetQueueCallback(func() { done <- struct{}{} })
+
+	_, queued, err := m.admitOrEnqueue(context.Background(), SpawnOptions{WorkspaceID: "ws-1", TargetName: "claude"}, false)
+	if err != nil || queued == nil {
+		t.Fatalf("expected queued spawn, got %v, %v", queued, err)
+	}
+	// The enqueue's own drain finds no room.
+	m.DrainQueue()
+	if _, ok := m.QueueInfo(queued.ID); !ok {
+		t.Fatal("entry drained while the slot was held")
+	}
+
+	st.RemoveSession("ws-1-run")
+	m.DrainQueue()
+	if _, ok := m.QueueInfo(queued.ID); ok {
+		t.Error("entry still queued after the slot freed")
+	}
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("drained spawn never finished")
+	}
+	// There is no tmux server here, so the spawn fails and the placeholder
+	// says so.
+	if sess, _ := st.GetSession(queued.ID); sess.Status != state.SessionStatusFailed {
+		t.Errorf("placeholder status = %q, want failed", sess.Status)
+	}
+}
+
+func TestSpawnQueue_Ordering(t *testing.T) {
+	q := newSpawnQueue("")
+	base := time.Now()
+	q.entries = []queuedSpawn{
+		{SessionID: "a", EnqueuedAt: base},
+		{SessionID: "b", EnqueuedAt: base.Add(time.Second), Priority: 5},
+		{SessionID: "c", EnqueuedAt: base.Add(2 * time.Second)},
+		{SessionID: "d", EnqueuedAt: base.Add(3 * time.Second), Priority: 5},
+	}
+	q.sortLocked()
+	want := []string{"b", "d", "a", "c"}
+	for i, e := range q.entries {
+		if e.SessionID != want[i] {
+			t.Fatalf("order = %v, want %v", q.entries, want)
+		}
+	}
+	if !q.hasWaitingAtOrAbove(5) {
+		t.Error("hasWaitingAtOrAbove(5) should be true")
+	}
+	if q.hasWaitingAtOrAbove(6) {
+		t.Error("hasWaitingAtOrAbove(6) should be false")
+	}
+}
+
+func TestSpawnQueue_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spawn-queue.json")
+	q := newSpawnQueue(path)
+	q.entries = []queuedSpawn{{
+		SessionID:  "ws-1-abc",
+		RepoURL:    "https://github.com/user/repo.git",
+		Target:     "claude",
+		Priority:   2,
+		EnqueuedAt: time.Now(),
+		Opts:       SpawnOptions{WorkspaceID: "ws-1", TargetName: "claude", Prompt: "fix it"},
+	}}
+	if err := q.saveLocked(); err != nil {
+		t.Fatalf("saveLocked() error = %v", err)
+	}
+
+	loaded := newSpawnQueue(path)
+	if err := loaded.load(); err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if len(loaded.entries) != 1 {
+		t.Fatalf("loaded %d entries, want 1", len(loaded.entries))
+	}
+	got := loaded.entries[0]
+	if got.SessionID != "ws-1-abc" || got.Priority != 2 || got.Opts.Prompt != "fix it" {
+		t.Errorf("loaded entry = %+v", got)
+	}
+
+	if err := newSpawnQueue(filepath.Join(t.TempDir(), "missing.json")).load(); err != nil {
+		t.Errorf("load() of missing file error = %v", err)
+	}
+}
+
+func TestDispose_QueuedSession(t *testing.T) {
+	m, st := newLimitedManager(t, 1)
+	st.AddSession(state.Session{ID: "ws-1-run", WorkspaceID: "ws-1", Target: "claude"})
+
+	_, queued, err := m.admitOrEnqueue(context.Background(), SpawnOptions{WorkspaceID: "ws-1", TargetName: "claude"}, false)
+	if err != nil || queued == nil {
+		t.Fatalf("expected queued spawn, got %v, %v", queued, err)
+	}
+	if err := m.Dispose(context.Background(), queued.ID); err != nil {
+		t.Fatalf("Dispose() error = %v", err)
+	}
+	if _, found := st.GetSession(queued.ID); found {
+		t.Error("queued session should be removed from state")
+	}
+	if _, ok := m.QueueInfo(queued.ID); ok {
+		t.Error("queued session should be removed from the queue")
+	}
+}
+
+func TestQueueInfo_ETA(t *testing.T) {
+	m, _ := newLimitedManager(t, 2)
+	m.queue.entries = []queuedSpawn{
+		{SessionID: "q1", Target: "claude"},
+		{SessionID: "q2", Target: "claude"},
+		{SessionID: "q3", Target: "claude"},
+	}
+
+	info, ok := m.QueueInfo("q3")
+	if !ok || info.Position != 3 || !info.ETA.IsZero() {
+		t.Errorf("QueueInfo() without run history = %+v, %v", info, ok)
+	}
+
+	m.queue.recordRunDuration(10 * time.Minute)
+	info, _ = m.QueueInfo("q1")
+	if d := time.Until(info.ETA); d < 9*time.Minute || d > 11*time.Minute {
+		t.Errorf("q1 ETA in %v, want ~10m", d)
+	}
+	// Two slots: q3 waits for the second wave.
+	info, _ = m.QueueInfo("q3")
+	if d := time.Until(info.ETA); d < 19*time.Minute || d > 21*time.Minute {
+		t.Errorf("q3 ETA in %v, want ~20m", d)
+	}
+
+	if _, ok := m.QueueInfo("missing"); ok {
+		t.Error("QueueInfo() of unknown session should report false")
+	}
+}
@@ -0,0 +1,344 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/hinshun/vt10x"
+	"github.com/sergeknystautas/schmux/internal/remote/controlmode"
+)
+
+// screenHistoryLines matches the history-limit schmux sets on tmux sessions.
+const screenHistoryLines = 10000
+
+// Glyph attribute bits. vt10x keeps these unexported; the values mirror its
+// attrReverse..attrBlink constants.
+const (
+	glyphReverse   = 1 << 0
+	glyphUnderline = 1 << 1
+	glyphBold      = 1 << 2
+	glyphItalic    = 1 << 4
+	glyphBlink     = 1 << 5
+)
+
+// sgrPattern matches the SGR sequences terminalScreen renders, so rendered
+// lines can be turned back into plain text.
+var sgrPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// stripSGR removes SGR (color and style) sequences from rendered output.
+func stripSGR(s string) string {
+	return sgrPattern.ReplaceAllString(s, "")
+}
+
+// terminalScreen is an in-process VT emulator: the screen model behind
+// SessionRuntime captures and the PTYSource. vt10x models only the visible
+// screen, so rows that a line feed scrolls off the top are rendered into a
+// text scrollback first. Scrolls caused by other means (scroll regions, index
+// sequences, autowrap on the last row) are not captured, which only affects
+// how much history Lines can return.
+type terminalScreen struct {
+	mu         sync.Mutex
+	term       vt10x.Terminal
+	cols, rows int
+	scrollback []string // rendered with SGR sequences
+	// pending holds a UTF-8 sequence split across writes. vt10x drops an
+	// incomplete trailing rune instead of buffering it.
+	pending []byte
+}
+
+func newTerminalScreen(cols, rows int) *terminalScreen {
+	if cols <= 0 || rows <= 0 {
+		cols, rows = 80, 24
+	}
+	return &terminalScreen{term: vt10x.New(vt10x.WithSize(cols, rows)), cols: cols, rows: rows}
+}
+
+// Reset discards all state and starts over with the given size.
+func (s *terminalScreen) Reset(cols, rows int) {
+	if cols <= 0 || rows <= 0 {
+		cols, rows = 80, 24
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.term = vt10x.New(vt10x.WithSize(cols, rows))
+	s.cols, s.rows = cols, rows
+	s.scrollback = nil
+	s.pending = nil
+}
+
+// Write feeds terminal output to the emulator and reports whether the screen
+// contents or the cursor position changed.
+func (s *terminalScreen) Write(data []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) > 0 {
+		data = append(s.pending, data...)
+		s.pending = nil
+	}
+	if tail := incompleteUTF8Tail(data); tail > 0 {
+		s.pending = append([]byte(nil), data[len(data)-tail:]...)
+		data = data[:len(data)-tail]
+	}
+
+	before := s.term.Cursor()
+	changed := false
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			changed = s.writeLocked(data) || changed
+			break
+		}
+		changed = s.writeLocked(data[:i]) || changed
+		if s.term.Cursor().Y == s.rows-1 && s.term.Mode()&vt10x.ModeAltScreen == 0 {
+			s.pushScrollback(s.renderRow(0, true))
+		}
+		changed = s.writeLocked(data[i:i+1]) || changed
+		data = data[i+1:]
+	}
+	after := s.term.Cursor()
+	return changed || before.X != after.X || before.Y != after.Y
+}
+
+// writeLocked writes to the emulator and reports whether any cell changed.
+func (s *terminalScreen) writeLocked(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+	s.term.Write(data)
+	changed := false
+	if st, ok := s.term.(interface{ Changed(vt10x.ChangeFlag) bool }); ok {
+		changed = st.Changed(vt10x.ChangedScreen)
+	}
+	// Unlock resets the change flags; Write takes the same lock internally.
+	s.term.Lock()
+	s.term.Unlock()
+	return changed
+}
+
+// incompleteUTF8Tail returns the length of a truncated multi-byte rune at the
+// end of data, or 0 if data ends on a rune boundary.
+func incompleteUTF8Tail(data []byte) int {
+	for i := 1; i <= utf8.UTFMax-1 && i <= len(data); i++ {
+		b := data[len(data)-i]
+		if b < utf8.RuneSelf {
+			return 0
+		}
+		if utf8.RuneStart(b) {
+			if utf8.FullRune(data[len(data)-i:]) {
+				return 0
+			}
+			return i
+		}
+	}
+	return 0
+}
+
+func (s *terminalScreen) pushScrollback(line string) {
+	s.scrollback = append(s.scrollback, line)
+	if over := len(s.scrollback) - screenHistoryLines; over > 0 {
+		s.scrollback = append([]string(nil), s.scrollback[over:]...)
+	}
+}
+
+// Resize changes the emulator dimensions.
+func (s *terminalScreen) Resize(cols, rows int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.term.Resize(cols, rows)
+	s.cols, s.rows = cols, rows
+}
+
+// Size returns the emulator dimensions.
+func (s *terminalScreen) Size() (cols, rows int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cols, s.rows
+}
+
+// Visible returns the visible screen, one line per row. With escapes, colors
+// and styles are rendered as SGR sequences like `tmux capture-pane -e`.
+func (s *terminalScreen) Visible(escapes bool) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var b strings.Builder
+	s.writeVisible(&b, escapes)
+	return b.String()
+}
+
+// Lines returns up to n lines of scrollback followed by the visible screen,
+// mirroring `tmux capture-pane -S -n`.
+func (s *terminalScreen) Lines(n int, escapes bool) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	history := s.scrollback
+	if n < len(history) {
+		history = history[len(history)-max(n, 0):]
+	}
+	var b strings.Builder
+	for _, line := range history {
+		if !escapes {
+			line = stripSGR(line)
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	s.writeVisible(&b, escapes)
+	return b.String()
+}
+
+// Cursor returns the cursor position, terminal modes, and screen size.
+func (s *terminalScreen) Cursor() controlmode.CursorState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur := s.term.Cursor()
+	mode := s.term.Mode()
+	return controlmode.CursorState{
+		X:             cur.X,
+		Y:             cur.Y,
+		Visible:       s.term.CursorVisible(),
+		AlternateOn:   mode&vt10x.ModeAltScreen != 0,
+		MouseStandard: mode&vt10x.ModeMouseButton != 0,
+		MouseButton:   mode&vt10x.ModeMouseMotion != 0,
+		MouseAny:      mode&vt10x.ModeMouseMany != 0,
+		MouseSGR:      mode&vt10x.ModeMouseSgr != 0,
+		Width:         s.cols,
+		Height:        s.rows,
+	}
+}
+
+// writeVisible writes every visible row to b. Callers hold s.mu.
+func (s *terminalScreen) writeVisible(b *strings.Builder, escapes bool) {
+	for y := 0; y < s.rows; y++ {
+		b.WriteString(s.renderRow(y, escapes))
+		b.WriteByte('\n')
+	}
+}
+
+// renderRow returns row y without trailing blanks. With escapes, every style
+// change is emitted as a full SGR sequence and the row ends with a reset, so
+// each line stands on its own. Callers hold s.mu.
+func (s *terminalScreen) renderRow(y int, escapes bool) string {
+	end := s.cols
+	for end > 0 {
+		g := s.term.Cell(end-1, y)
+		if (g.Char != 0 && g.Char != ' ') || (escapes && styled(g, true)) {
+			break
+		}
+		end--
+	}
+	var b strings.Builder
+	var prev vt10x.Glyph
+	inStyle := false
+	for x := 0; x < end; x++ {
+		g := s.term.Cell(x, y)
+		if escapes {
+			if styled(g, false) {
+				if !inStyle || !sameStyle(g, prev) {
+					b.WriteString(sgr(g))
+					inStyle = true
+				}
+			} else if inStyle {
+				b.WriteString("\x1b[0m")
+				inStyle = false
+			}
+			prev = g
+		}
+		if g.Char == 0 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteRune(g.Char)
+		}
+	}
+	if inStyle {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+// styled reports whether g renders differently from a default cell. A blank
+// cell is only visibly styled by its background or reverse video.
+func styled(g vt10x.Glyph, blankOnly bool) bool {
+	if g.BG != vt10x.DefaultBG || g.Mode&glyphReverse != 0 {
+		return true
+	}
+	if blankOnly {
+		return false
+	}
+	return g.FG != vt10x.DefaultFG || g.Mode&(glyphBold|glyphItalic|glyphUnderline|glyphBlink) != 0
+}
+
+func sameStyle(a, b vt10x.Glyph) bool {
+	return a.FG == b.FG && a.BG == b.BG && a.Mode == b.Mode
+}
+
+// sgr renders g's style as a single SGR sequence starting from a reset.
+func sgr(g vt10x.Glyph) string {
+	params := []string{"0"}
+	for _, attr := range []struct {
+		bit  int16
+		code string
+	}{{glyphBold, "1"}, {glyphItalic, "3"}, {glyphUnderline, "4"}, {glyphBlink, "5"}, {glyphReverse, "7"}} {
+		if g.Mode&attr.bit != 0 {
+			params = append(params, attr.code)
+		}
+	}
+	// vt10x stores reverse-video cells with their colors already swapped;
+	// swap them back so the reverse attribute is not applied twice.
+	fg, bg := g.FG, g.BG
+	if g.Mode&glyphReverse != 0 {
+		fg, bg = bg, fg
+	}
+	if fg != vt10x.DefaultFG {
+		params = append(params, sgrColor(fg, 30, 90, "38"))
+	}
+	if bg != vt10x.DefaultBG {
+		params = append(params, sgrColor(bg, 40, 100, "48"))
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
+
+// sgrColor renders a vt10x color: the 16 ANSI colors use their short codes,
+// the xterm palette uses 5;n, and anything larger is 24-bit RGB.
+func sgrColor(c vt10x.Color, base, brightBase int, extended string) string {
+	switch {
+	case c < 8:
+		return strconv.Itoa(base + int(c))
+	case c < 16:
+		return strconv.Itoa(brightBase + int(c) - 8)
+	case c < 256:
+		return extended + ";5;" + strconv.Itoa(int(c))
+	default:
+		return fmt.Sprintf("%s;2;%d;%d;%d", extended, (c>>16)&0xff, (c>>8)&0xff, c&0xff)
+	}
+}
+
+// seedScreenSequence converts a capture (as returned by CaptureLines) and the
+// matching cursor state into bytes that rebuild the same screen in a fresh
+// emulator of the captured size.
+func seedScreenSequence(capture string, cur controlmode.CursorState) []byte {
+	var b bytes.Buffer
+	if cur.AlternateOn {
+		b.WriteString("\x1b[?1049h")
+	}
+	capture = strings.TrimSuffix(capture, "\n")
+	b.WriteString(strings.ReplaceAll(capture, "\n", "\r\n"))
+	b.WriteString("\x1b[0m")
+	fmt.Fprintf(&b, "\x1b[%d;%dH", cur.Y+1, cur.X+1)
+	if !cur.Visible {
+		b.WriteString("\x1b[?25l")
+	}
+	for _, mode := range []struct {
+		on   bool
+		code string
+	}{{cur.MouseStandard, "1000"}, {cur.MouseButton, "1002"}, {cur.MouseAny, "1003"}, {cur.MouseSGR, "1006"}} {
+		if mode.on {
+			b.WriteString("\x1b[?" + mode.code + "h")
+		}
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,86 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/remote/controlmode"
+)
+
+func TestTerminalScreen_ScrollbackAndLines(t *testing.T) {
+	s := newTerminalScreen(20, 3)
+	s.Write([]byte("one\r\ntwo\r\nthree\r\nfour\r\nfive"))
+
+	if got, want := s.Visible(false), "three\nfour\nfive\n"; got != want {
+		t.Errorf("Visible() = %q, want %q", got, want)
+	}
+	if got, want := s.Lines(1, false), "two\nthree\nfour\nfive\n"; got != want {
+		t.Errorf("Lines(1) = %q, want %q", got, want)
+	}
+	if got, want := s.Lines(100, false), "one\ntwo\nthree\nfour\nfive\n"; got != want {
+		t.Errorf("Lines(100) = %q, want %q", got, want)
+	}
+}
+
+func TestTerminalScreen_AltScreenDoesNotScrollback(t *testing.T) {
+	s := newTerminalScreen(20, 2)
+	s.Write([]byte("\x1b[?1049ha\r\nb\r\nc\r\nd"))
+	if got := s.Lines(100, false); strings.Contains(got, "a\n") {
+		t.Errorf("alt screen rows leaked into scrollback: %q", got)
+	}
+	if cur := s.Cursor(); !cur.AlternateOn {
+		t.Error("Cursor().AlternateOn = false, want true")
+	}
+}
+
+func TestTerminalScreen_Cursor(t *testing.T) {
+	s := newTerminalScreen(20, 5)
+	s.Write([]byte("ab\r\ncde"))
+	cur := s.Cursor()
+	if cur.X != 3 || cur.Y != 1 || !cur.Visible {
+		t.Errorf("Cursor() = %+v, want X=3 Y=1 visible", cur)
+	}
+	s.Write([]byte("\x1b[?25l"))
+	if s.Cursor().Visible {
+		t.Error("cursor still visible after DECTCEM reset")
+	}
+}
+
+func TestTerminalScreen_RendersSGR(t *testing.T) {
+	s := newTerminalScreen(20, 1)
+	s.Write([]byte("a\x1b[1;31mbold\x1b[0m \x1b[48;5;200m  \x1b[0m"))
+
+	if got, want := s.Visible(false), "abold\n"; got != want {
+		t.Errorf("Visible(false) = %q, want %q", got, want)
+	}
+	// Bold red renders as bright red; a colored blank survives trimming.
+	if got, want := s.Visible(true), "a\x1b[0;1;91mbold\x1b[0m \x1b[0;48;5;200m  \x1b[0m\n"; got != want {
+		t.Errorf("Visible(true) = %q, want %q", got, want)
+	}
+}
+
+func TestTerminalScreen_SplitUTF8(t *testing.T) {
+	s := newTerminalScreen(10, 1)
+	b := []byte("❯ ok")
+	s.Write(b[:2])
+	s.Write(b[2:])
+	if got, want := s.Visible(false), "❯ ok\n"; got != want {
+		t.Errorf("Visible() = %q, want %q", got, want)
+	}
+}
+
+func TestSeedScreenSequence(t *testing.T) {
+	s := newTerminalScreen(10, 3)
+	s.Write(seedScreenSequence("\x1b[32mgreen\x1b[0m\nsecond\n", controlmode.CursorState{X: 3, Y: 1, MouseSGR: true}))
+
+	if got, want := s.Lines(10, false), "green\nsecond\n\n"; got != want {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+	if got := s.Visible(true); !strings.HasPrefix(got, "\x1b[0;32mgreen\x1b[0m\n") {
+		t.Errorf("Visible(true) lost colors: %q", got)
+	}
+	cur := s.Cursor()
+	if cur.X != 3 || cur.Y != 1 || cur.Visible || !cur.MouseSGR {
+		t.Errorf("Cursor() = %+v, want X=3 Y=1 hidden with SGR mouse", cur)
+	}
+}
@@ -0,0 +1,492 @@
+package session
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/sergeknystautas/schmux/internal/redact"
+)
+
+// Scrollback segments keep a session's output log on disk so dashboard
+// history and gap replay survive a daemon restart. A store is a directory of
+// append-only segment files named by the seq of their first record. Each
+// record is a big-endian uint64 seq, a big-endian uint32 length, then the
+// data. Records are written with the output log's seqs, so the in-memory log
+// and the store agree on numbering across restarts.
+//
+// Each segment has a sidecar of time marks (same name, scrollbackMarkExt):
+// big-endian uint64 seq and int64 unix nanoseconds, written when a record is
+// appended at least scrollbackMarkInterval after the previous mark. A record's
+// time is that of the newest mark at or before its seq.
+const (
+	scrollbackSegmentBytes = 4 << 20  // rotate to a new segment past this size
+	scrollbackMaxBytes     = 64 << 20 // drop the oldest segments past this total
+	scrollbackRecordHeader = 12
+	scrollbackSegmentExt   = ".seg"
+	scrollbackMarkExt      = ".tim"
+	scrollbackMarkSize     = 16
+	scrollbackMarkInterval = time.Second
+)
+
+// ScrollbackStore is the on-disk output history of one session. It is safe
+// for concurrent use; the tracker appends while gap replays read.
+type ScrollbackStore struct {
+	dir string
+
+	mu       sync.Mutex
+	segments []scrollbackSegment // oldest first
+	file     *os.File            // newest segment, open for appending
+	w        *bufio.Writer
+	marks    *os.File // time marks of the newest segment
+	lastMark time.Time
+	lastSeq  uint64
+	hasLast  bool
+	redact   *redact.Chunks // masks secrets before they reach disk
+	heldSeq  uint64         // seq of the entry redact holds, if any
+}
+
+type scrollbackSegment struct {
+	first uint64 // seq of the first record, also the file name
+	size  int64
+}
+
+// OpenScrollbackStore opens (creating if needed) the store in dir. A record
+// cut short by a crash at the end of the newest segment is truncated away.
+func OpenScrollbackStore(dir string) (*ScrollbackStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create scrollback dir: %w", err)
+	}
+	segments, err := listScrollbackSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	s := &ScrollbackStore{dir: dir, segments: segments, redact: redact.NewChunks(redact.SiteScrollback)}
+
+	// Find the last intact record, discarding segments with none.
+	for len(s.segments) > 0 {
+		seg := &s.segments[len(s.segments)-1]
+		var valid int64
+		var count int
+		err := readScrollbackSegment(s.path(seg.first), func(e LogEntry, end int64) bool {
+			s.lastSeq, s.hasLast = e.Seq, true
+			valid = end
+			count++
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			if valid < seg.size {
+				if err := os.Truncate(s.path(seg.first), valid); err != nil {
+					return nil, err
+				}
+				seg.size = valid
+			}
+			break
+		}
+		if err := os.Remove(s.path(seg.first)); err != nil {
+			return nil, err
+		}
+		os.Remove(scrollbackPath(dir, seg.first, scrollbackMarkExt))
+		s.segments = s.segments[:len(s.segments)-1]
+	}
+	return s, nil
+}
+
+// listScrollbackSegments returns the segments in dir, oldest first.
+func listScrollbackSegments(dir string) ([]scrollbackSegment, error) {
+	names, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []scrollbackSegment
+	for _, e := range names {
+		name, ok := strings.CutSuffix(e.Name(), scrollbackSegmentExt)
+		if !ok || e.IsDir() {
+			continue
+		}
+		first, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, scrollbackSegment{first: first, size: info.Size()})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].first < segments[j].first })
+	return segments, nil
+}
+
+func (s *ScrollbackStore) path(first uint64) string {
+	return scrollbackPath(s.dir, first, scrollbackSegmentExt)
+}
+
+func scrollbackPath(dir string, first uint64, ext string) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", first, ext))
+}
+
+// Append redacts entries, writes them to the newest segment and flushes
+// them. Entries at or below the last stored seq are already on disk and are
+// skipped. An entry that ends in what may be the start of a secret is kept
+// back until the next one arrives or the store is closed, so a secret split
+// between the two is still masked.
+func (s *ScrollbackStore) Append(entries []LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range entries {
+		if s.hasLast && e.Seq <= s.lastSeq || s.redact.Held() && e.Seq <= s.heldSeq {
+			continue
+		}
+		hadHeld := s.redact.Held()
+		prev, cur, held := s.redact.Add(e.Data)
+		if hadHeld {
+			if err := s.writeRecordLocked(LogEntry{Seq: s.heldSeq, Data: prev}); err != nil {
+				return err
+			}
+		}
+		if held {
+			s.heldSeq = e.Seq
+			continue
+		}
+		if err := s.writeRecordLocked(LogEntry{Seq: e.Seq, Data: cur}); err != nil {
+			return err
+		}
+	}
+	if s.w == nil {
+		return nil
+	}
+	return s.w.Flush()
+}
+
+// writeRecordLocked appends e to the newest segment. Callers hold s.mu.
+func (s *ScrollbackStore) writeRecordLocked(e LogEntry) error {
+	if err := s.ensureSegmentLocked(e.Seq); err != nil {
+		return err
+	}
+	if now := time.Now(); now.Sub(s.lastMark) >= scrollbackMarkInterval {
+		s.writeMarkLocked(e.Seq, now)
+	}
+	var hdr [scrollbackRecordHeader]byte
+	binary.BigEndian.PutUint64(hdr[:8], e.Seq)
+	binary.BigEndian.PutUint32(hdr[8:], uint32(len(e.Data)))
+	if _, err := s.w.Write(hdr[:]); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(e.Data); err != nil {
+		return err
+	}
+	s.segments[len(s.segments)-1].size += int64(scrollbackRecordHeader + len(e.Data))
+	s.lastSeq, s.hasLast = e.Seq, true
+	return nil
+}
+
+// ensureSegmentLocked opens the newest segment for appending, starting a new
+// one at seq when there is none or it is full. Callers hold s.mu.
+func (s *ScrollbackStore) ensureSegmentLocked(seq uint64) error {
+	n := len(s.segments)
+	full := n == 0 || s.segments[n-1].size >= scrollbackSegmentBytes
+	if s.file != nil && !full {
+		return nil
+	}
+	if err := s.closeFileLocked(); err != nil {
+		return err
+	}
+	first := seq
+	if !full {
+		first = s.segments[n-1].first
+	}
+	f, err := os.OpenFile(s.path(first), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if full {
+		s.segments = append(s.segments, scrollbackSegment{first: seq})
+		s.pruneLocked()
+		// Every segment starts with a mark so its records stay datable
+		// after older segments are pruned.
+		s.lastMark = time.Time{}
+	}
+	s.marks, _ = os.OpenFile(scrollbackPath(s.dir, first, scrollbackMarkExt), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	s.file = f
+	s.w = bufio.NewWriter(f)
+	return nil
+}
+
+// writeMarkLocked records that seq was written at t. Marks are best effort;
+// a missing one only makes later records look older. Callers hold s.mu.
+func (s *ScrollbackStore) writeMarkLocked(seq uint64, t time.Time) {
+	if s.marks == nil {
+		return
+	}
+	var buf [scrollbackMarkSize]byte
+	binary.BigEndian.PutUint64(buf[:8], seq)
+	binary.BigEndian.PutUint64(buf[8:], uint64(t.UnixNano()))
+	if _, err := s.marks.Write(buf[:]); err == nil {
+		s.lastMark = t
+	}
+}
+
+// pruneLocked removes the oldest segments while the store is over
+// scrollbackMaxBytes. The newest segment is always kept.
+func (s *ScrollbackStore) pruneLocked() {
+	var total int64
+	for _, seg := range s.segments {
+		total += seg.size
+	}
+	for len(s.segments) > 1 && total > scrollbackMaxBytes {
+		total -= s.segments[0].size
+		os.Remove(s.path(s.segments[0].first))
+		os.Remove(scrollbackPath(s.dir, s.segments[0].first, scrollbackMarkExt))
+		s.segments = s.segments[1:]
+	}
+}
+
+func (s *ScrollbackStore) closeFileLocked() error {
+	if s.marks != nil {
+		s.marks.Close()
+		s.marks = nil
+	}
+	if s.file == nil {
+		return nil
+	}
+	err := s.w.Flush()
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+	s.file, s.w = nil, nil
+	return err
+}
+
+// ReadRange returns stored entries with fromSeq <= seq < toSeq, in order.
+// The result may have holes if output was lost before it was written.
+func (s *ScrollbackStore) ReadRange(fromSeq, toSeq uint64) ([]LogEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	start := sort.Search(len(s.segments), func(i int) bool { return s.segments[i].first > fromSeq }) - 1
+	start = max(start, 0)
+	var out []LogEntry
+	for _, seg := range s.segments[start:] {
+		if seg.first >= toSeq {
+			break
+		}
+		err := readScrollbackSegment(s.path(seg.first), func(e LogEntry, _ int64) bool {
+			if e.Seq >= toSeq {
+				return false
+			}
+			if e.Seq >= fromSeq {
+				out = append(out, e)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Tail returns up to n of the newest stored entries: the contiguous run of
+// seqs ending at the last record, so they can be restored into an OutputLog.
+func (s *ScrollbackStore) Tail(n int) ([]LogEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []LogEntry
+	for i := len(s.segments) - 1; i >= 0 && len(out) < n; i-- {
+		var seg []LogEntry
+		err := readScrollbackSegment(s.path(s.segments[i].first), func(e LogEntry, _ int64) bool {
+			seg = append(seg, e)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = append(seg, out...)
+	}
+	if len(out) > n {
+		out = out[len(out)-n:]
+	}
+	for i := len(out) - 1; i > 0; i-- {
+		if out[i-1].Seq+1 != out[i].Seq {
+			return out[i:], nil
+		}
+	}
+	return out, nil
+}
+
+// Close writes the entry kept back by Append, then flushes and closes the
+// open segment.
+func (s *ScrollbackStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.redact.Held() {
+		if err := s.writeRecordLocked(LogEntry{Seq: s.heldSeq, Data: s.redact.Flush()}); err != nil {
+			s.closeFileLocked()
+			return err
+		}
+	}
+	return s.closeFileLocked()
+}
+
+// ScanScrollback calls fn, in seq order, for each record with seq >= fromSeq
+// in the store at dir, along with the time it was written, until fn returns
+// false. It opens no store and never modifies files, so it is safe to run
+// while the session's tracker is appending; a record still being written is
+// not reported. A missing dir is not an error.
+func ScanScrollback(dir string, fromSeq uint64, fn func(e LogEntry, written time.Time) bool) error {
+	segments, err := listScrollbackSegments(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	start := sort.Search(len(segments), func(i int) bool { return segments[i].first > fromSeq }) - 1
+	for _, seg := range segments[max(start, 0):] {
+		marks := readScrollbackMarks(scrollbackPath(dir, seg.first, scrollbackMarkExt))
+		var written time.Time
+		stopped := false
+		err := readScrollbackSegment(scrollbackPath(dir, seg.first, scrollbackSegmentExt), func(e LogEntry, _ int64) bool {
+			for len(marks) > 0 && marks[0].seq <= e.Seq {
+				written = marks[0].at
+				marks = marks[1:]
+			}
+			if e.Seq < fromSeq {
+				return true
+			}
+			stopped = !fn(e, written)
+			return !stopped
+		})
+		if err != nil && !errors.Is(err, os.ErrNotExist) { // pruned since listing
+			return err
+		}
+		if stopped {
+			return nil
+		}
+	}
+	return nil
+}
+
+type scrollbackMark struct {
+	seq uint64
+	at  time.Time
+}
+
+// readScrollbackMarks reads a segment's time marks. Marks are advisory, so a
+// missing or damaged file yields whatever could be read.
+func readScrollbackMarks(path string) []scrollbackMark {
+	data, _ := os.ReadFile(path)
+	var marks []scrollbackMark
+	for len(data) >= scrollbackMarkSize {
+		marks = append(marks, scrollbackMark{
+			seq: binary.BigEndian.Uint64(data[:8]),
+			at:  time.Unix(0, int64(binary.BigEndian.Uint64(data[8:16]))),
+		})
+		data = data[scrollbackMarkSize:]
+	}
+	return marks
+}
+
+// readScrollbackSegment calls fn for each intact record in the segment at
+// path, with the file offset just past the record, until fn returns false. A
+// truncated trailing record ends the scan without error.
+func readScrollbackSegment(path string, fn func(e LogEntry, end int64) bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var hdr [scrollbackRecordHeader]byte
+	var offset int64
+	for {
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return err
+		}
+		n := binary.BigEndian.Uint32(hdr[8:])
+		data := make([]byte, n)
+		if _, err := io.ReadFull(r, data); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return err
+		}
+		offset += scrollbackRecordHeader + int64(n)
+		if !fn(LogEntry{Seq: binary.BigEndian.Uint64(hdr[:8]), Data: data}, offset) {
+			return nil
+		}
+	}
+}
+
+// scrollbackWriter tails an OutputLog into a ScrollbackStore. It runs for
+// the lifetime of the tracker's run loop, like the timelapse recorder.
+type scrollbackWriter struct {
+	store  *ScrollbackStore
+	log    *OutputLog
+	logger *log.Logger
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+func newScrollbackWriter(store *ScrollbackStore, outputLog *OutputLog, logger *log.Logger) *scrollbackWriter {
+	return &scrollbackWriter{
+		store:  store,
+		log:    outputLog,
+		logger: logger,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+}
+
+// Run persists entries as they are appended. On Stop it writes whatever is
+// left and closes the store.
+func (w *scrollbackWriter) Run() {
+	defer close(w.doneCh)
+	defer w.store.Close()
+
+	// Entries restored from the store are skipped by Append.
+	next := w.log.OldestSeq()
+	for {
+		stopped := !w.log.WaitForNew(next, w.stopCh)
+		entries := w.log.ReplayFrom(next)
+		if entries == nil {
+			// Fell behind the ring; the lost range stays a hole on disk.
+			entries = w.log.ReplayAll()
+		}
+		if len(entries) > 0 {
+			if err := w.store.Append(entries); err != nil {
+				if w.logger != nil {
+					w.logger.Warn("scrollback write failed, no longer persisting output", "err", err)
+				}
+				return
+			}
+			next = entries[len(entries)-1].Seq + 1
+		}
+		if stopped {
+			return
+		}
+	}
+}
+
+// Stop signals the writer to finish and waits for it.
+func (w *scrollbackWriter) Stop() {
+	close(w.stopCh)
+	<-w.doneCh
+}
@@ -0,0 +1,283 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func entriesFrom(first uint64, data ...string) []LogEntry {
+	out := make([]LogEntry, len(data))
+	for i, d := range data {
+		out[i] = LogEntry{Seq: first + uint64(i), Data: []byte(d)}
+	}
+	return out
+}
+
+func joinEntries(entries []LogEntry) string {
+	var parts []string
+	for _, e := range entries {
+		parts = append(parts, fmt.Sprintf("%d:%s", e.Seq, e.Data))
+	}
+	return strings.Join(parts, " ")
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 2s")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestScrollbackStore_AppendReopenRead(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenScrollbackStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append(entriesFrom(0, "a", "", "c")); err != nil {
+		t.Fatal(err)
+	}
+	// Already-stored seqs are skipped.
+	if err := s.Append(entriesFrom(2, "c", "d")); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s, err = OpenScrollbackStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	got, err := s.ReadRange(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1: 2:c"; joinEntries(got) != want {
+		t.Errorf("ReadRange(1, 3) = %q, want %q", joinEntries(got), want)
+	}
+	tail, err := s.Tail(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "0:a 1: 2:c 3:d"; joinEntries(tail) != want {
+		t.Errorf("Tail = %q, want %q", joinEntries(tail), want)
+	}
+}
+
+func TestScrollbackStore_TruncatedTail(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenScrollbackStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Append(entriesFrom(0, "hello", "world"))
+	s.Close()
+
+	// Simulate a crash in the middle of writing the last record.
+	path := filepath.Join(dir, fmt.Sprintf("%020d.seg", 0))
+	info, _ := os.Stat(path)
+	if err := os.Truncate(path, info.Size()-2); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = OpenScrollbackStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append(entriesFrom(1, "again")); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	tail, _ := s.Tail(10)
+	if want := "0:hello 1:again"; joinEntries(tail) != want {
+		t.Errorf("Tail = %q, want %q", joinEntries(tail), want)
+	}
+}
+
+func TestScrollbackStore_RotatesAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenScrollbackStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	chunk := strings.Repeat("x", 1<<20)
+	var seq uint64
+	for range scrollbackMaxBytes/(1<<20) + 2*scrollbackSegmentBytes/(1<<20) {
+		if err := s.Append([]LogEntry{{Seq: seq, Data: []byte(chunk)}}); err != nil {
+			t.Fatal(err)
+		}
+		seq++
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	if len(files) < 2 {
+		t.Fatalf("expected rotation into several segments, got %d", len(files))
+	}
+	var total int64
+	for _, f := range files {
+		info, _ := os.Stat(f)
+		total += info.Size()
+	}
+	if total > scrollbackMaxBytes+scrollbackSegmentBytes {
+		t.Errorf("store holds %d bytes, want at most %d", total, scrollbackMaxBytes+scrollbackSegmentBytes)
+	}
+	// The oldest records are gone; the newest are still readable.
+	if got, _ := s.ReadRange(0, 1); len(got) != 0 {
+		t.Errorf("seq 0 still stored after pruning")
+	}
+	if got, _ := s.ReadRange(seq-1, seq); len(got) != 1 {
+		t.Errorf("newest record missing")
+	}
+}
+
+func TestScrollbackStore_TailStopsAtHole(t *testing.T) {
+	s, err := OpenScrollbackStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Append(entriesFrom(0, "a", "b"))
+	s.Append(entriesFrom(5, "f", "g"))
+	s.Close()
+	tail, _ := s.Tail(10)
+	if want := "5:f 6:g"; joinEntries(tail) != want {
+		t.Errorf("Tail = %q, want %q", joinEntries(tail), want)
+	}
+}
+
+func TestScrollbackStore_RedactsSplitSecrets(t *testing.T) {
+	s, err := OpenScrollbackStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Append(entriesFrom(0, "$ echo AKIAIOSF", "ODNN7EXAMPLE\n", "Authorization: Bearer "))
+	// The last entry ends in a space; the next one holds the secret.
+	s.Append(entriesFrom(3, "abcdefghijklmnop1234\n", "done"))
+	if got, _ := s.ReadRange(4, 5); len(got) != 0 {
+		t.Errorf("entry ending in a token stored before the next one: %q", joinEntries(got))
+	}
+	s.Close()
+
+	got, err := s.ReadRange(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "0:$ echo [REDACTED:aws_access_key] 1:\n 2:Authorization: Bearer  3:[REDACTED:bearer_token]\n 4:done"
+	if joinEntries(got) != want {
+		t.Errorf("stored entries = %q, want %q", joinEntries(got), want)
+	}
+}
+
+func TestScanScrollback(t *testing.T) {
+	dir := t.TempDir()
+	before := time.Now()
+	s, err := OpenScrollbackStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.Append(entriesFrom(0, "a", "b", "c", "d"))
+
+	var got []LogEntry
+	err = ScanScrollback(dir, 1, func(e LogEntry, written time.Time) bool {
+		if written.Before(before) || written.After(time.Now()) {
+			t.Errorf("seq %d written at %v, want between %v and now", e.Seq, written, before)
+		}
+		got = append(got, e)
+		return e.Seq < 2
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1:b 2:c"; joinEntries(got) != want {
+		t.Errorf("ScanScrollback = %q, want %q", joinEntries(got), want)
+	}
+
+	if err := ScanScrollback(filepath.Join(dir, "missing"), 0, func(LogEntry, time.Time) bool { return true }); err != nil {
+		t.Errorf("missing dir: %v", err)
+	}
+}
+
+func TestSessionRuntime_ScrollbackSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenScrollbackStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, mock := newTestTracker("s1")
+	if err := tracker.RestoreScrollback(store); err != nil {
+		t.Fatal(err)
+	}
+	tracker.Start()
+	for _, d := range []string{"one", "two", "three"} {
+		mock.Emit(SourceEvent{Type: SourceOutput, Data: d})
+	}
+	waitFor(t, func() bool { return tracker.OutputLog().CurrentSeq() == 3 })
+	tracker.Stop()
+
+	// A new tracker picks up the history and continues the numbering.
+	store, err = OpenScrollbackStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted, mock := newTestTracker("s1")
+	restarted.outputLog = NewOutputLog(2)
+	if err := restarted.RestoreScrollback(store); err != nil {
+		t.Fatal(err)
+	}
+	if got := restarted.OutputLog().CurrentSeq(); got != 3 {
+		t.Fatalf("CurrentSeq after restore = %d, want 3", got)
+	}
+	restarted.Start()
+	defer restarted.Stop()
+	mock.Emit(SourceEvent{Type: SourceOutput, Data: "four"})
+	waitFor(t, func() bool { return restarted.OutputLog().CurrentSeq() == 4 })
+
+	// Seq 0 left the two-entry ring, so it comes from disk.
+	if restarted.OutputLog().ReplayFrom(0) != nil {
+		t.Fatal("expected seq 0 to be evicted from the ring")
+	}
+	if got, want := joinEntries(restarted.ReplayFrom(0)), "0:one 1:two 2:three 3:four"; got != want {
+		t.Errorf("ReplayFrom(0) = %q, want %q", got, want)
+	}
+}
+
+func TestSessionRuntime_ReplayFromWithoutScrollback(t *testing.T) {
+	tracker, _ := newTestTracker("s1")
+	tracker.outputLog = NewOutputLog(1)
+	tracker.outputLog.Append([]byte("a"))
+	tracker.outputLog.Append([]byte("b"))
+	if got := tracker.ReplayFrom(0); got != nil {
+		t.Errorf("ReplayFrom(0) = %v, want nil", got)
+	}
+	if got := joinEntries(tracker.ReplayFrom(1)); got != "1:b" {
+		t.Errorf("ReplayFrom(1) = %q, want %q", got, "1:b")
+	}
+}
+
+func TestManager_PruneScrollback(t *testing.T) {
+	st := state.New("", nil)
+	st.AddSession(state.Session{ID: "live"})
+	dir := t.TempDir()
+	for _, id := range []string{"live", "gone"} {
+		os.MkdirAll(filepath.Join(dir, id), 0700)
+	}
+	m := &Manager{state: st}
+	m.SetScrollbackDir(dir)
+	m.PruneScrollback()
+	if _, err := os.Stat(filepath.Join(dir, "live")); err != nil {
+		t.Errorf("live session's scrollback removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gone")); !os.IsNotExist(err) {
+		t.Errorf("stale scrollback kept: %v", err)
+	}
+}
@@ -21,6 +21,10 @@ type Runnable interface {
 }
 
 const trackerRestartDelay = 500 * time.Millisecond
+
+// outputLogCapacity is the number of entries each session's OutputLog keeps
+// in memory (≈ 5MB at ~100 bytes/event).
+const outputLogCapacity = 50000
 const trackerActivityDebounce = 500 * time.Millisecond
 
 // SequencedOutput wraps an output event with its output log sequence number.
@@ -82,6 +86,21 @@ type SessionRuntime struct {
 	// Sequenced output log for replay-based bootstrap and gap recovery
 	outputLog *OutputLog
 
+	// scrollback persists the output log to disk; nil when not persisting.
+	// Set by RestoreScrollback before Start.
+	scrollback *ScrollbackStore
+
+	// screen is a live VT model of the pane, fed from fanOut in output-log
+	// order. It is only served once seeded from a source capture (see
+	// syncScreen); screenSynced drops back to false whenever the source's
+	// screen may have diverged (reconnect gap, resize reflow).
+	screen           *terminalScreen
+	screenMu         sync.Mutex // guards screenSynced, screenNextSeq; serializes writes with seeding
+	screenSynced     bool
+	screenNextSeq    uint64        // first output seq not yet applied to screen
+	screenChangedSeq atomic.Uint64 // seq of the last output that changed the screen
+	screenSeedMu     sync.Mutex    // one seeding capture at a time
+
 	// gapCh receives Gap and Resize events for the recorder (Phase 1).
 	// nil when recording is not active.
 	gapCh chan SourceEvent
@@ -151,6 +170,53 @@ func (t *SessionRuntime) OutputLog() *OutputLog {
 	return t.outputLog
 }
 
+// RestoreScrollback loads the newest entries of store into the output log, so
+// sequence numbers and replayable history continue from a previous daemon
+// run, and persists all further output to store. Must be called before Start.
+func (t *SessionRuntime) RestoreScrollback(store *ScrollbackStore) error {
+	entries, err := store.Tail(outputLogCapacity)
+	if err != nil {
+		return err
+	}
+	t.outputLog.Restore(entries)
+	t.scrollback = store
+	return nil
+}
+
+// ReplayFrom returns all output entries with seq >= fromSeq. Entries that
+// have been evicted from the in-memory log are read from the on-disk
+// scrollback. Returns nil if part of the range is no longer available, and
+// an empty slice if there is nothing new.
+func (t *SessionRuntime) ReplayFrom(fromSeq uint64) []LogEntry {
+	if entries := t.outputLog.ReplayFrom(fromSeq); entries != nil || t.scrollback == nil {
+		return entries
+	}
+	live := t.outputLog.ReplayAll()
+	if len(live) == 0 {
+		return nil
+	}
+	older, err := t.scrollback.ReadRange(fromSeq, live[0].Seq)
+	if err != nil {
+		if t.logger != nil {
+			t.logger.Warn("scrollback read failed", "session", t.sessionID, "err", err)
+		}
+		return nil
+	}
+	// The disk copy may trail the ring or have holes; only a contiguous
+	// range is a valid replay.
+	next := fromSeq
+	for _, e := range older {
+		if e.Seq != next {
+			return nil
+		}
+		next++
+	}
+	if next != live[0].Seq {
+		return nil
+	}
+	return append(older, live...)
+}
+
 // SyncTrigger returns a channel that fires when the source detects a tmux
 // output pause (via pause-after). Returns nil for sources that don't support it.
 func (t *SessionRuntime) SyncTrigger() <-chan struct{} {
@@ -177,7 +243,8 @@ func NewSessionRuntime(sessionID string, source ControlSource, st state.StateSto
 		state:          st,
 		outputCallback: outputCallback,
 		logger:         logger,
-		outputLog:      NewOutputLog(50000), // 50,000 entries ≈ 5MB at ~100 bytes/event
+		outputLog:      NewOutputLog(outputLogCapacity),
+		screen:         newTerminalScreen(80, 24),
 		stopCh:         make(chan struct{}),
 		doneCh:         make(chan struct{}),
 		HealthProbe:    healthProbe,
@@ -315,6 +382,7 @@ func (t *SessionRuntime) fanOut(event controlmode.OutputEvent) {
 	// Append() to consume a seq so subscribers' gap detection stays contiguous
 	// (the CR/FM zero-length frame fix in Group B handles empty-data forwarding).
 	seq := t.outputLog.Append(stripped)
+	t.applyToScreen(seq, stripped)
 
 	seqEvent := SequencedOutput{
 		OutputEvent: controlmode.OutputEvent{PaneID: event.PaneID, Data: string(stripped)},
@@ -361,6 +429,8 @@ func (t *SessionRuntime) Resize(cols, rows int) error {
 	}
 	t.LastTerminalCols.Store(int32(cols))
 	t.LastTerminalRows.Store(int32(rows))
+	// tmux reflows wrapped lines on resize, which the model cannot reproduce.
+	t.invalidateScreen()
 
 	// Forward resize to timelapse recorder if active
 	if t.gapCh != nil {
@@ -373,30 +443,167 @@ func (t *SessionRuntime) Resize(cols, rows int) error {
 	return t.source.Resize(cols, rows)
 }
 
-// CaptureLastLines captures scrollback via the source.
+// CaptureLastLines returns the last N lines of scrollback plus the visible
+// screen, with SGR formatting like `tmux capture-pane -e`. It is served from
+// the screen model and only reaches the source when the model needs seeding.
 func (t *SessionRuntime) CaptureLastLines(ctx context.Context, lines int) (string, error) {
-	return t.source.CaptureLines(lines)
+	if err := t.syncScreen(); err != nil {
+		return "", err
+	}
+	return t.screen.Lines(lines, true), nil
 }
 
 // CapturePane captures the visible screen of the pane (no scrollback).
 func (t *SessionRuntime) CapturePane(ctx context.Context) (string, error) {
-	return t.source.CaptureVisible()
+	if err := t.syncScreen(); err != nil {
+		return "", err
+	}
+	return t.screen.Visible(true), nil
 }
 
 // GetCursorState returns the cursor position and visibility for the tracked pane.
 func (t *SessionRuntime) GetCursorState(ctx context.Context) (controlmode.CursorState, error) {
-	return t.source.GetCursorState()
+	if err := t.syncScreen(); err != nil {
+		return controlmode.CursorState{}, err
+	}
+	return t.screen.Cursor(), nil
 }
 
 // GetCursorPosition returns the cursor position (x, y) for the tracked pane.
 func (t *SessionRuntime) GetCursorPosition(ctx context.Context) (x, y int, err error) {
-	cs, err := t.source.GetCursorState()
+	cs, err := t.GetCursorState(ctx)
 	if err != nil {
 		return 0, 0, err
 	}
 	return cs.X, cs.Y, nil
 }
 
+// ScreenSnapshot is the screen model's view of a session at one point in the
+// output log.
+type ScreenSnapshot struct {
+	Text   string // visible screen as plain text, one line per row
+	Cursor controlmode.CursorState
+	// Seq is the first output-log seq not reflected in the snapshot. Pass it
+	// to ScreenChangedSince to ask whether later output changed the screen.
+	Seq uint64
+}
+
+// Screen returns the current screen text and cursor from the screen model.
+func (t *SessionRuntime) Screen(ctx context.Context) (ScreenSnapshot, error) {
+	if err := t.syncScreen(); err != nil {
+		return ScreenSnapshot{}, err
+	}
+	t.screenMu.Lock()
+	defer t.screenMu.Unlock()
+	return ScreenSnapshot{
+		Text:   t.screen.Visible(false),
+		Cursor: t.screen.Cursor(),
+		Seq:    t.screenNextSeq,
+	}, nil
+}
+
+// ScreenChangedSince reports whether output with seq >= since changed the
+// screen text or cursor. It answers true while the model is unsynced, since
+// changes cannot be ruled out then.
+func (t *SessionRuntime) ScreenChangedSince(since uint64) bool {
+	t.screenMu.Lock()
+	synced := t.screenSynced
+	t.screenMu.Unlock()
+	if !synced {
+		return true
+	}
+	last := t.screenChangedSeq.Load()
+	return last > 0 && last-1 >= since
+}
+
+// applyToScreen feeds one output-log entry to the screen model. Runs on the
+// fanOut goroutine.
+func (t *SessionRuntime) applyToScreen(seq uint64, data []byte) {
+	t.screenMu.Lock()
+	defer t.screenMu.Unlock()
+	if seq < t.screenNextSeq {
+		return // already applied while seeding
+	}
+	t.screenNextSeq = seq + 1
+	if !t.screenSynced {
+		return
+	}
+	if t.screen.Write(data) {
+		t.screenChangedSeq.Store(seq + 1)
+	}
+}
+
+// invalidateScreen marks the model stale; the next read reseeds it.
+func (t *SessionRuntime) invalidateScreen() {
+	t.screenMu.Lock()
+	t.screenSynced = false
+	t.screenMu.Unlock()
+}
+
+// syncScreen seeds the screen model from a source capture if it is not
+// already in sync. Output appended to the log before the capture returned is
+// treated as reflected in it, the same boundary the dashboard's terminal
+// bootstrap uses; output applied since then is replayed from the log.
+func (t *SessionRuntime) syncScreen() error {
+	t.screenMu.Lock()
+	synced := t.screenSynced
+	t.screenMu.Unlock()
+	if synced {
+		return nil
+	}
+
+	t.screenSeedMu.Lock()
+	defer t.screenSeedMu.Unlock()
+	t.screenMu.Lock()
+	synced = t.screenSynced
+	t.screenMu.Unlock()
+	if synced {
+		return nil
+	}
+
+	capture, err := t.source.CaptureLines(screenHistoryLines)
+	if err != nil {
+		return err
+	}
+	cur, err := t.source.GetCursorState()
+	if err != nil {
+		return err
+	}
+	boundary := t.outputLog.CurrentSeq()
+	cols, rows := cur.Width, cur.Height
+	if cols <= 0 || rows <= 0 {
+		cols, rows = int(t.LastTerminalCols.Load()), int(t.LastTerminalRows.Load())
+	}
+	if cols <= 0 || rows <= 0 {
+		return fmt.Errorf("screen size unknown")
+	}
+
+	t.screenMu.Lock()
+	defer t.screenMu.Unlock()
+	t.screen.Reset(cols, rows)
+	t.screen.Write(seedScreenSequence(capture, cur))
+	if t.screenNextSeq > boundary {
+		entries := t.outputLog.ReplayFrom(boundary)
+		if entries == nil {
+			return fmt.Errorf("output log evicted entries needed to seed the screen")
+		}
+		for _, e := range entries {
+			if e.Seq >= t.screenNextSeq {
+				break
+			}
+			t.screen.Write(e.Data)
+		}
+	} else {
+		t.screenNextSeq = boundary
+	}
+	// The seeded screen reflects all output before screenNextSeq.
+	if t.screenNextSeq > 0 {
+		t.screenChangedSeq.Store(t.screenNextSeq)
+	}
+	t.screenSynced = true
+	return nil
+}
+
 // DiagnosticCounters returns a snapshot of pipeline counters including drop counts
 // at all fan-out layers.
 func (t *SessionRuntime) DiagnosticCounters() map[string]int64 {
@@ -437,6 +644,13 @@ func (t *SessionRuntime) run() {
 		}
 	}
 
+	// Persist output to disk if a scrollback store is attached
+	if t.scrollback != nil {
+		writer := newScrollbackWriter(t.scrollback, t.outputLog, t.logger)
+		go writer.Run()
+		defer writer.Stop()
+	}
+
 	for event := range t.source.Events() {
 		switch event.Type {
 		case SourceOutput:
@@ -459,11 +673,13 @@ func (t *SessionRuntime) run() {
 			}
 
 		case SourceGap:
+			t.invalidateScreen()
 			if t.gapCh != nil {
 				t.gapCh <- event
 			}
 
 		case SourceResize:
+			t.invalidateScreen()
 			if t.gapCh != nil {
 				t.gapCh <- event
 			}
@@ -53,6 +53,7 @@ func TestSessionRuntimeInputResizeWithoutControlMode(t *testing.T) {
 func TestControlSourceInterfaceConformance(t *testing.T) {
 	var _ ControlSource = (*LocalSource)(nil)
 	var _ ControlSource = (*RemoteSource)(nil)
+	var _ ControlSource = (*PTYSource)(nil)
 }
 
 func TestTrackerCounters_Increment(t *testing.T) {
@@ -114,16 +115,87 @@ func TestTrackerCounters_Increment(t *testing.T) {
 	})
 }
 
-func TestCaptureLastLinesDelegatesToSource(t *testing.T) {
+func TestCaptureLastLinesSeedsScreenFromSource(t *testing.T) {
 	tracker, mock := newTestTracker("s1")
 	mock.CaptureContent = "line1\nline2\n❯\n"
+	mock.Cursor = controlmode.CursorState{X: 2, Y: 2, Visible: true, Width: 20, Height: 4}
 
 	content, err := tracker.CaptureLastLines(context.Background(), 100)
 	if err != nil {
 		t.Fatalf("CaptureLastLines error: %v", err)
 	}
-	if content != "line1\nline2\n❯\n" {
-		t.Errorf("CaptureLastLines = %q, want %q", content, "line1\nline2\n❯\n")
+	if want := "line1\nline2\n❯\n\n"; content != want {
+		t.Errorf("CaptureLastLines = %q, want %q", content, want)
+	}
+	cur, err := tracker.GetCursorState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cur.X != 2 || cur.Y != 2 || cur.Width != 20 || cur.Height != 4 {
+		t.Errorf("GetCursorState = %+v, want X=2 Y=2 20x4", cur)
+	}
+	if mock.CaptureCalls != 1 {
+		t.Errorf("source captured %d times, want 1", mock.CaptureCalls)
+	}
+}
+
+func TestScreenModelFollowsOutput(t *testing.T) {
+	tracker, mock := newTestTracker("s1")
+	mock.CaptureContent = "$ \n"
+	mock.Cursor = controlmode.CursorState{X: 2, Visible: true, Width: 20, Height: 3}
+
+	snap, err := tracker.Screen(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tracker.ScreenChangedSince(snap.Seq) {
+		t.Error("ScreenChangedSince before any output = true")
+	}
+
+	// Escapes alone move nothing on screen.
+	tracker.fanOut(controlmode.OutputEvent{Data: "\x1b[0m"})
+	if tracker.ScreenChangedSince(snap.Seq) {
+		t.Error("ScreenChangedSince after a no-op SGR = true")
+	}
+
+	tracker.fanOut(controlmode.OutputEvent{Data: "ls\r\nfile\r\n$ "})
+	if !tracker.ScreenChangedSince(snap.Seq) {
+		t.Error("ScreenChangedSince after output = false")
+	}
+	snap, err = tracker.Screen(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "$ ls\nfile\n$\n"; snap.Text != want {
+		t.Errorf("Screen().Text = %q, want %q", snap.Text, want)
+	}
+	if snap.Cursor.X != 2 || snap.Cursor.Y != 2 {
+		t.Errorf("cursor = (%d,%d), want (2,2)", snap.Cursor.X, snap.Cursor.Y)
+	}
+	if mock.CaptureCalls != 1 {
+		t.Errorf("source captured %d times, want 1", mock.CaptureCalls)
+	}
+}
+
+func TestScreenModelReseedsAfterResize(t *testing.T) {
+	tracker, mock := newTestTracker("s1")
+	mock.CaptureContent = "before\n"
+	mock.Cursor = controlmode.CursorState{Width: 20, Height: 2}
+	if _, err := tracker.CapturePane(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.CaptureContent = "after\n"
+	mock.Cursor = controlmode.CursorState{Width: 30, Height: 2}
+	if err := tracker.Resize(30, 2); err != nil {
+		t.Fatal(err)
+	}
+	got, err := tracker.CapturePane(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "after\n\n" || mock.CaptureCalls != 2 {
+		t.Errorf("CapturePane = %q after %d captures, want reseeded %q", got, mock.CaptureCalls, "after\n\n")
 	}
 }
 
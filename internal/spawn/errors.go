@@ -3,5 +3,6 @@ package spawn
 import "errors"
 
 var (
-	ErrNotFound = errors.New("spawn: not found")
+	ErrNotFound        = errors.New("spawn: not found")
+	ErrInvalidPipeline = errors.New("spawn: invalid pipeline")
 )
@@ -0,0 +1,283 @@
+package spawn
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+)
+
+// PipelineStore manages pipeline definitions per repository. Definitions live
+// next to the spawn entries in <baseDir>/<repo>/pipelines.json.
+type PipelineStore struct {
+	mu        sync.Mutex
+	pipelines map[string][]contracts.Pipeline // keyed by repo name
+	baseDir   string
+}
+
+// NewPipelineStore creates a new pipeline store.
+func NewPipelineStore(baseDir string) *PipelineStore {
+	return &PipelineStore{
+		baseDir:   baseDir,
+		pipelines: make(map[string][]contracts.Pipeline),
+	}
+}
+
+func (s *PipelineStore) filePath(repo string) string {
+	return filepath.Join(s.baseDir, repo, "pipelines.json")
+}
+
+func (s *PipelineStore) load(repo string) error {
+	if _, ok := s.pipelines[repo]; ok {
+		return nil
+	}
+	data, err := os.ReadFile(s.filePath(repo))
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.pipelines[repo] = nil
+			return nil
+		}
+		return fmt.Errorf("read pipelines: %w", err)
+	}
+	var pipelines []contracts.Pipeline
+	if err := json.Unmarshal(data, &pipelines); err != nil {
+		return fmt.Errorf("parse pipelines: %w", err)
+	}
+	s.pipelines[repo] = pipelines
+	return nil
+}
+
+func (s *PipelineStore) save(repo string) error {
+	dir := filepath.Dir(s.filePath(repo))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("create pipelines dir: %w", err)
+	}
+	data, err := json.MarshalIndent(s.pipelines[repo], "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal pipelines: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".pipelines-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	return os.Rename(tmpPath, s.filePath(repo))
+}
+
+func generatePipelineID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("pl-%x", time.Now().UnixNano())
+	}
+	return "pl-" + hex.EncodeToString(b)
+}
+
+// List returns all pipelines for a repo sorted by name.
+func (s *PipelineStore) List(repo string) ([]contracts.Pipeline, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(repo); err != nil {
+		return nil, err
+	}
+	result := make([]contracts.Pipeline, len(s.pipelines[repo]))
+	copy(result, s.pipelines[repo])
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// Get returns a pipeline by ID.
+func (s *PipelineStore) Get(repo, id string) (contracts.Pipeline, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(repo); err != nil {
+		return contracts.Pipeline{}, err
+	}
+	for _, p := range s.pipelines[repo] {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return contracts.Pipeline{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// Create validates and stores a new pipeline, assigning its ID.
+func (s *PipelineStore) Create(repo string, p contracts.Pipeline) (contracts.Pipeline, error) {
+	if err := ValidatePipeline(p); err != nil {
+		return contracts.Pipeline{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(repo); err != nil {
+		return contracts.Pipeline{}, err
+	}
+	now := time.Now().UTC()
+	p.ID = generatePipelineID()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	s.pipelines[repo] = append(s.pipelines[repo], p)
+	if err := s.save(repo); err != nil {
+		s.pipelines[repo] = s.pipelines[repo][:len(s.pipelines[repo])-1]
+		return contracts.Pipeline{}, err
+	}
+	return p, nil
+}
+
+// Update replaces a pipeline's name, defaults, and stages.
+func (s *PipelineStore) Update(repo, id string, p contracts.Pipeline) (contracts.Pipeline, error) {
+	if err := ValidatePipeline(p); err != nil {
+		return contracts.Pipeline{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(repo); err != nil {
+		return contracts.Pipeline{}, err
+	}
+	for i := range s.pipelines[repo] {
+		if s.pipelines[repo][i].ID == id {
+			prev := s.pipelines[repo][i]
+			p.ID = prev.ID
+			p.CreatedAt = prev.CreatedAt
+			p.UpdatedAt = time.Now().UTC()
+			s.pipelines[repo][i] = p
+			if err := s.save(repo); err != nil {
+				s.pipelines[repo][i] = prev
+				return contracts.Pipeline{}, err
+			}
+			return p, nil
+		}
+	}
+	return contracts.Pipeline{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// Delete removes a pipeline by ID.
+func (s *PipelineStore) Delete(repo, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(repo); err != nil {
+		return err
+	}
+	for i, p := range s.pipelines[repo] {
+		if p.ID == id {
+			s.pipelines[repo] = append(s.pipelines[repo][:i], s.pipelines[repo][i+1:]...)
+			return s.save(repo)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// ValidatePipeline checks that a pipeline is a well-formed DAG: stage IDs are
+// unique, every After and OnFailure reference resolves, success triggers are
+// known, and the After edges contain no cycle.
+func ValidatePipeline(p contracts.Pipeline) error {
+	if p.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPipeline)
+	}
+	if len(p.Stages) == 0 {
+		return fmt.Errorf("%w: at least one stage is required", ErrInvalidPipeline)
+	}
+	byID := make(map[string]contracts.PipelineStage, len(p.Stages))
+	for _, st := range p.Stages {
+		if st.ID == "" {
+			return fmt.Errorf("%w: stage id is required", ErrInvalidPipeline)
+		}
+		if _, dup := byID[st.ID]; dup {
+			return fmt.Errorf("%w: duplicate stage id %q", ErrInvalidPipeline, st.ID)
+		}
+		byID[st.ID] = st
+	}
+	alternates := make(map[string]bool)
+	for _, st := range p.Stages {
+		for _, dep := range st.After {
+			if _, ok := byID[dep]; !ok {
+				return fmt.Errorf("%w: stage %q depends on unknown stage %q", ErrInvalidPipeline, st.ID, dep)
+			}
+		}
+		if st.OnFailure != "" {
+			if st.OnFailure == st.ID {
+				return fmt.Errorf("%w: stage %q cannot be its own failure branch", ErrInvalidPipeline, st.ID)
+			}
+			if _, ok := byID[st.OnFailure]; !ok {
+				return fmt.Errorf("%w: stage %q branches to unknown stage %q", ErrInvalidPipeline, st.ID, st.OnFailure)
+			}
+			alternates[st.OnFailure] = true
+		}
+		for _, trig := range st.SucceedOn {
+			if trig != contracts.PipelineTriggerCompleted && trig != contracts.PipelineTriggerNeedsTesting {
+				return fmt.Errorf("%w: stage %q has invalid succeed_on %q", ErrInvalidPipeline, st.ID, trig)
+			}
+		}
+		if st.Spawn.Command == "" && len(st.Spawn.Targets) == 0 && st.Spawn.QuickLaunchName == "" {
+			return fmt.Errorf("%w: stage %q needs a command, targets, or quick_launch_name", ErrInvalidPipeline, st.ID)
+		}
+	}
+	hasRoot := false
+	for _, st := range p.Stages {
+		if len(st.After) == 0 && !alternates[st.ID] {
+			hasRoot = true
+			break
+		}
+	}
+	if !hasRoot {
+		return fmt.Errorf("%w: no stage can start the pipeline", ErrInvalidPipeline)
+	}
+
+	// Failure branches must not loop back on themselves.
+	for _, st := range p.Stages {
+		seen := map[string]bool{st.ID: true}
+		for next := st.OnFailure; next != ""; next = byID[next].OnFailure {
+			if seen[next] {
+				return fmt.Errorf("%w: failure branches loop through stage %q", ErrInvalidPipeline, next)
+			}
+			seen[next] = true
+		}
+	}
+
+	// Depth-first cycle detection over After edges.
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	marks := make(map[string]int, len(p.Stages))
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch marks[id] {
+		case visiting:
+			return fmt.Errorf("%w: cycle through stage %q", ErrInvalidPipeline, id)
+		case done:
+			return nil
+		}
+		marks[id] = visiting
+		for _, dep := range byID[id].After {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		marks[id] = done
+		return nil
+	}
+	for _, st := range p.Stages {
+		if err := visit(st.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package spawn
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+)
+
+func testStage(id string, after ...string) contracts.PipelineStage {
+	return contracts.PipelineStage{
+		ID:    id,
+		After: after,
+		Spawn: contracts.SpawnRequest{Targets: map[string]int{"claude": 1}, Prompt: id},
+	}
+}
+
+func TestPipelineStore_CRUD(t *testing.T) {
+	s := NewPipelineStore(t.TempDir())
+	p, err := s.Create("repo", contracts.Pipeline{
+		Name:   "impl-then-review",
+		Stages: []contracts.PipelineStage{testStage("impl"), testStage("review", "impl")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID == "" || p.CreatedAt.IsZero() {
+		t.Fatalf("expected ID and CreatedAt, got %+v", p)
+	}
+
+	p.Name = "renamed"
+	if _, err := s.Update("repo", p.ID, p); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh store reads what the first one wrote.
+	s2 := NewPipelineStore(s.baseDir)
+	got, err := s2.Get("repo", p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "renamed" || len(got.Stages) != 2 {
+		t.Errorf("unexpected pipeline after reload: %+v", got)
+	}
+
+	if err := s2.Delete("repo", p.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s2.Get("repo", p.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after delete: err = %v, want ErrNotFound", err)
+	}
+	list, err := s2.List("repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 0 {
+		t.Errorf("expected empty list, got %d", len(list))
+	}
+}
+
+func TestValidatePipeline(t *testing.T) {
+	withFailure := func(st contracts.PipelineStage, alt string) contracts.PipelineStage {
+		st.OnFailure = alt
+		return st
+	}
+	tests := []struct {
+		name    string
+		stages  []contracts.PipelineStage
+		wantErr bool
+	}{
+		{"valid chain", []contracts.PipelineStage{testStage("a"), testStage("b", "a")}, false},
+		{"valid alternate", []contracts.PipelineStage{withFailure(testStage("a"), "fix"), testStage("fix"), testStage("b", "a")}, false},
+		{"no stages", nil, true},
+		{"duplicate id", []contracts.PipelineStage{testStage("a"), testStage("a")}, true},
+		{"unknown dependency", []contracts.PipelineStage{testStage("a", "missing")}, true},
+		{"cycle", []contracts.PipelineStage{testStage("root"), testStage("a", "b"), testStage("b", "a")}, true},
+		{"self failure", []contracts.PipelineStage{withFailure(testStage("a"), "a")}, true},
+		{"failure loop", []contracts.PipelineStage{testStage("root"), withFailure(testStage("a", "root"), "b"), withFailure(testStage("b"), "a")}, true},
+		{"no root", []contracts.PipelineStage{testStage("a", "b"), testStage("b", "a")}, true},
+		{"empty spawn", []contracts.PipelineStage{{ID: "a"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePipeline(contracts.Pipeline{Name: "p", Stages: tt.stages})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidPipeline) {
+				t.Errorf("err = %v, want ErrInvalidPipeline", err)
+			}
+		})
+	}
+}
@@ -82,6 +82,12 @@ const (
 	SessionStatusDisposing    = "disposing"
 )
 
+// Session backend constants. The empty string means tmux.
+const (
+	SessionBackendTmux = "tmux"
+	SessionBackendPTY  = "pty"
+)
+
 // Workspace status constants.
 const (
 	WorkspaceStatusProvisioning = "provisioning"
@@ -89,6 +95,9 @@ const (
 	WorkspaceStatusFailed       = "failed"
 	WorkspaceStatusDisposing    = "disposing"
 	WorkspaceStatusRecyclable   = "recyclable"
+	// WorkspaceStatusReady marks a warm pool workspace: provisioned on a
+	// detached checkout of the default branch, waiting for a spawn.
+	WorkspaceStatusReady = "ready"
 )
 
 // SchmuxDataDir returns the schmux data directory within a workspace.
@@ -165,9 +174,14 @@ type Workspace struct {
 	PortBlock               int               `json:"port_block,omitempty"`         // 0 = unassigned; 1-indexed block for stable preview ports
 	Status                  string            `json:"status,omitempty"`
 	ResolveConflicts        []ResolveConflict `json:"resolve_conflicts,omitempty"`
+	PushGates               *PushGateRun      `json:"push_gates,omitempty"` // most recent pre-push gate run
+	Setup                   *SetupRun         `json:"setup,omitempty"`      // most recent setup command run
 	Backburner              bool              `json:"backburner,omitempty"`
+	Protected               bool              `json:"protected,omitempty"` // never disposed by auto-cleanup
 	IntentShared            bool              `json:"intent_shared,omitempty"`
 	CreatedAt               time.Time         `json:"created_at,omitempty"`
+	LastUsedAt              time.Time         `json:"last_used_at,omitempty"` // when the workspace was last recycled; orders disk quota eviction
+	BaseSHA                 string            `json:"base_sha,omitempty"`     // HEAD when the workspace took its branch; auto-cleanup's "merged" needs commits past it
 }
 
 // Tab represents an accessory tab in a workspace (diff, git, preview, markdown, etc.).
@@ -191,6 +205,12 @@ type ResolveConflictResolution = contracts.ResolveConflictResolution
 // ResolveConflict is a type alias for contracts.ResolveConflict.
 type ResolveConflict = contracts.ResolveConflict
 
+// PushGateRun is a type alias for contracts.PushGateRun.
+type PushGateRun = contracts.PushGateRun
+
+// SetupRun is a type alias for contracts.WorkspaceSetupRun.
+type SetupRun = contracts.WorkspaceSetupRun
+
 func copyStringMap(src map[string]string) map[string]string {
 	if len(src) == 0 {
 		return nil
@@ -265,9 +285,39 @@ func CopyResolveConflicts(src []ResolveConflict) []ResolveConflict {
 	return copyResolveConflicts(src)
 }
 
+// CopyPushGateRun returns a deep copy of run, or nil.
+func CopyPushGateRun(run *PushGateRun) *PushGateRun {
+	if run == nil {
+		return nil
+	}
+	dst := *run
+	dst.Gates = append([]contracts.PushGateResult(nil), run.Gates...)
+	if run.FinishedAt != nil {
+		finished := *run.FinishedAt
+		dst.FinishedAt = &finished
+	}
+	return &dst
+}
+
+// CopySetupRun returns a deep copy of run, or nil.
+func CopySetupRun(run *SetupRun) *SetupRun {
+	if run == nil {
+		return nil
+	}
+	dst := *run
+	dst.Commands = append([]contracts.WorkspaceSetupResult(nil), run.Commands...)
+	if run.FinishedAt != nil {
+		finished := *run.FinishedAt
+		dst.FinishedAt = &finished
+	}
+	return &dst
+}
+
 func copyWorkspace(w Workspace) Workspace {
 	w.OverlayManifest = copyStringMap(w.OverlayManifest)
 	w.ResolveConflicts = copyResolveConflicts(w.ResolveConflicts)
+	w.PushGates = CopyPushGateRun(w.PushGates)
+	w.Setup = CopySetupRun(w.Setup)
 	return w
 }
 
@@ -337,6 +387,7 @@ type Session struct {
 	RemoteWindow string `json:"remote_window,omitempty"`  // tmux window ID on remote (e.g., "@3")
 	Status       string `json:"status,omitempty"`         // "provisioning", "running", "failed", "disposing" (used for all sessions during disposal, remote sessions during lifecycle)
 	Fence        bool   `json:"fence,omitempty"`          // True if spawned inside the fence sandbox (set once at spawn, local sessions only)
+	Backend      string `json:"backend,omitempty"`        // Session backend: "" (tmux) or "pty" (schmux-owned PTY supervisor)
 	// ResumeID is the harness-native conversation id (Claude session_id /
 	// OpenCode session id), captured via hooks. Empty until captured. Enables
 	// the Restart action.
@@ -1389,6 +1440,12 @@ func (sess *Session) IsRemoteSession() bool {
 	return sess.RemoteHostID != ""
 }
 
+// IsPTYSession returns true if the session runs under a schmux PTY supervisor
+// rather than tmux.
+func (sess *Session) IsPTYSession() bool {
+	return sess.Backend == SessionBackendPTY
+}
+
 // IsRemoteWorkspace returns true if the workspace is on a remote host.
 func (ws *Workspace) IsRemoteWorkspace() bool {
 	return ws.RemoteHostID != ""
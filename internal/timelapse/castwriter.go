@@ -64,6 +64,13 @@ func (c *CastWriter) WriteResize(timestamp float64, width, height int) error {
 	return err
 }
 
+// WriteMarker writes a marker (chapter) event at the given timestamp.
+func (c *CastWriter) WriteMarker(timestamp float64, label string) error {
+	line := fmt.Sprintf("[%.6f,\"m\",%s]\n", timestamp, jsonEscapeBytes([]byte(label)))
+	_, err := c.w.Write([]byte(line))
+	return err
+}
+
 // jsonEscapeBytes produces a JSON string literal from raw bytes,
 // escaping control characters and quotes but preserving all bytes.
 func jsonEscapeBytes(b []byte) string {
@@ -2,6 +2,8 @@
 
 package timelapse
 
+import "strings"
+
 // IntervalType classifies a time interval in the recording.
 type IntervalType int
 
@@ -39,3 +41,16 @@ func countChangedCells(prev, curr [][]rune, width, height int) int {
 	}
 	return changed
 }
+
+// gridLines returns the rows of a grid as strings without trailing spaces,
+// dropping blank rows at the bottom of the screen.
+func gridLines(grid [][]rune) []string {
+	lines := make([]string, len(grid))
+	for y, row := range grid {
+		lines[y] = strings.TrimRight(strings.ReplaceAll(string(row), "\x00", " "), " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
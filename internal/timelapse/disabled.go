@@ -3,9 +3,11 @@
 package timelapse
 
 import (
+	"context"
 	"io"
 	"time"
 
+	"github.com/sergeknystautas/schmux/internal/events"
 	"github.com/sergeknystautas/schmux/internal/session"
 )
 
@@ -16,6 +18,7 @@ const (
 	RecordHeader RecordType = "header"
 	RecordOutput RecordType = "output"
 	RecordResize RecordType = "resize"
+	RecordMarker RecordType = "marker"
 	RecordGap    RecordType = "gap"
 	RecordEnd    RecordType = "end"
 )
@@ -46,9 +49,19 @@ func NewRecorder(_ string, _ *session.OutputLog, _ <-chan session.SourceEvent, _
 	return &Recorder{}, nil
 }
 
-func (r *Recorder) RecordingID() string { return "" }
-func (r *Recorder) Run()                {}
-func (r *Recorder) Stop()               {}
+func (r *Recorder) RecordingID() string    { return "" }
+func (r *Recorder) SetMarkers(_ *Markers)  {}
+func (r *Recorder) WatchFenceLog(_ string) {}
+func (r *Recorder) Mark(_ string)          {}
+func (r *Recorder) Run()                   {}
+func (r *Recorder) Stop()                  {}
+
+// Markers is a no-op stub.
+type Markers struct{}
+
+func NewMarkers() *Markers                                                              { return &Markers{} }
+func (m *Markers) Add(_, _ string)                                                      {}
+func (m *Markers) HandleEvent(_ context.Context, _ string, _ events.RawEvent, _ []byte) {}
 
 // RecordingInfo holds metadata for a single recording file.
 type RecordingInfo struct {
@@ -63,9 +76,17 @@ type RecordingInfo struct {
 	InProgress    bool
 	HasCompressed bool
 	Path          string
+	Chapters      []Chapter
+}
+
+// Chapter is a marker event in a recording.
+type Chapter struct {
+	Time  float64
+	Label string
 }
 
 func ListRecordings(_ string) ([]RecordingInfo, error) { return nil, nil }
+func ReadChapters(_ string) ([]Chapter, error)         { return nil, nil }
 
 // CastHeader is the asciicast v2 header.
 type CastHeader struct {
@@ -85,7 +106,8 @@ type CastWriter struct{}
 
 func NewCastWriter(_ io.Writer, _ CastHeader) (*CastWriter, error) { return &CastWriter{}, nil }
 
-func (c *CastWriter) WriteEvent(_ float64, _ string) error { return nil }
+func (c *CastWriter) WriteEvent(_ float64, _ string) error  { return nil }
+func (c *CastWriter) WriteMarker(_ float64, _ string) error { return nil }
 
 // IntervalType classifies a time interval in the recording.
 type IntervalType int
@@ -110,10 +132,26 @@ func (e *ScreenEmulator) Write(_ []byte)             {}
 func (e *ScreenEmulator) Resize(_, _ int)            {}
 func (e *ScreenEmulator) CellGrid(_, _ int) [][]rune { return nil }
 
+// Format is an export output format.
+type Format string
+
+const (
+	FormatCast     Format = "cast"
+	FormatSVG      Format = "svg"
+	FormatText     Format = "text"
+	FormatMarkdown Format = "markdown"
+)
+
+var Formats = []Format{FormatCast, FormatSVG, FormatText, FormatMarkdown}
+
+func ParseFormat(_ string) (Format, error) { return FormatCast, nil }
+func (f Format) Ext() string               { return ".timelapse.cast" }
+
 // Exporter is a no-op stub.
 type Exporter struct{}
 
 func NewExporter(_, _ string, _ func(float64)) *Exporter { return &Exporter{} }
+func (e *Exporter) SetFormat(_ Format)                   {}
 func (e *Exporter) Export() error                        { return nil }
 
 func ShowFirstRunNotice(_ string) string { return "" }
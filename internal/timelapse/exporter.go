@@ -4,6 +4,7 @@ package timelapse
 
 import (
 	"fmt"
+	"io"
 	"os"
 )
 
@@ -24,13 +25,61 @@ const (
 	cosmeticCellThreshold = 10
 )
 
+// Format is an export output format.
+type Format string
+
+const (
+	// FormatCast is a time-compressed asciicast v2 file for asciinema-compatible players.
+	FormatCast Format = "cast"
+	// FormatSVG is a self-contained animated SVG of the compressed timeline.
+	FormatSVG Format = "svg"
+	// FormatText is a plain-text transcript of meaningful screen states.
+	FormatText Format = "text"
+	// FormatMarkdown is the transcript as markdown, with chapters as headings.
+	FormatMarkdown Format = "markdown"
+)
+
+// Formats lists every export format.
+var Formats = []Format{FormatCast, FormatSVG, FormatText, FormatMarkdown}
+
+// ParseFormat validates an export format name. An empty name is FormatCast.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(name); f {
+	case "":
+		return FormatCast, nil
+	case FormatCast, FormatSVG, FormatText, FormatMarkdown:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown export format %q (want cast, svg, text or markdown)", name)
+}
+
+// Ext returns the file name suffix for exports in this format, appended to
+// the recording ID, e.g. ".timelapse.cast".
+func (f Format) Ext() string {
+	switch f {
+	case FormatSVG:
+		return ".timelapse.svg"
+	case FormatText:
+		return ".timelapse.txt"
+	case FormatMarkdown:
+		return ".timelapse.md"
+	}
+	return ".timelapse.cast"
+}
+
 // Exporter converts a full timelapse recording (.cast) to a compressed .cast file.
 // All events are preserved (maintaining correct terminal state) but timestamps
 // are rewritten: scroll moments get a visible pause, everything else is near-instant.
+// Markers keep their place between output events, so chapters point at the
+// same screen in the compressed timeline.
+//
+// With SetFormat the same compressed timeline is rendered as an animated SVG
+// or as a transcript instead (see svg.go and transcript.go).
 type Exporter struct {
 	recordingPath string
 	outputPath    string
 	progressFn    func(pct float64)
+	format        Format
 }
 
 // NewExporter creates an exporter for a recording.
@@ -39,10 +88,27 @@ func NewExporter(recordingPath, outputPath string, progressFn func(float64)) *Ex
 		recordingPath: recordingPath,
 		outputPath:    outputPath,
 		progressFn:    progressFn,
+		format:        FormatCast,
 	}
 }
 
-// Export runs the single-pass time-compression pipeline.
+// SetFormat selects the output format. The default is FormatCast.
+func (e *Exporter) SetFormat(f Format) { e.format = f }
+
+// compressedEvent is one recording event placed on the compressed timeline.
+// Grid is the screen after the event; for output events Prev is the screen
+// before it.
+type compressedEvent struct {
+	Record
+	CompressedT   float64
+	Scrolled      bool
+	Cosmetic      bool
+	Prev, Grid    [][]rune
+	Width, Height int
+}
+
+// Export runs the single-pass time-compression pipeline and writes the
+// result in the selected format.
 func (e *Exporter) Export() error {
 	header, err := e.readHeader()
 	if err != nil {
@@ -80,15 +146,51 @@ func (e *Exporter) Export() error {
 	}
 	defer outFile.Close()
 
-	cw, err := NewCastWriter(outFile, CastHeader{
+	switch e.format {
+	case FormatSVG:
+		err = e.writeSVG(outFile, records, width, height)
+	case FormatText, FormatMarkdown:
+		err = e.writeTranscript(outFile, records, width, height, header.RecordingID)
+	default:
+		err = e.writeCast(outFile, records, width, height, header.RecordingID)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.reportProgress(1.0)
+	return nil
+}
+
+// writeCast emits every event with its compressed timestamp.
+func (e *Exporter) writeCast(w io.Writer, records []Record, width, height int, title string) error {
+	cw, err := NewCastWriter(w, CastHeader{
 		Width:  width,
 		Height: height,
-		Title:  header.RecordingID,
+		Title:  title,
 	})
 	if err != nil {
 		return fmt.Errorf("create cast writer: %w", err)
 	}
 
+	e.compress(records, width, height, func(ev compressedEvent) {
+		switch ev.Type {
+		case RecordResize:
+			// Emit resize event as "r" type so the player can call term.resize()
+			cw.WriteResize(ev.CompressedT, ev.Width, ev.Height)
+		case RecordMarker:
+			cw.WriteMarker(ev.CompressedT, ev.D)
+		case RecordOutput:
+			// Emit ALL events with compressed timestamp
+			cw.WriteEvent(ev.CompressedT, ev.D)
+		}
+	})
+	return nil
+}
+
+// compress feeds the output events to a screen emulator and calls fn for
+// every output, resize and marker event with its compressed timestamp.
+func (e *Exporter) compress(records []Record, width, height int, fn func(compressedEvent)) {
 	// Single-pass: feed each event to the emulator, check for scroll,
 	// emit all events with compressed timestamps
 	emu := NewScreenEmulator(width, height)
@@ -110,12 +212,16 @@ func (e *Exporter) Export() error {
 				emu.Resize(rec.Width, rec.Height)
 				width, height = rec.Width, rec.Height
 				prevGrid = emu.CellGrid(width, height)
-				// Emit resize event as "r" type so the player can call term.resize()
-				cw.WriteResize(compressedT, rec.Width, rec.Height)
+				fn(compressedEvent{Record: rec, CompressedT: compressedT, Grid: prevGrid, Width: width, Height: height})
 			}
 			continue
 		}
 
+		if rec.Type == RecordMarker {
+			fn(compressedEvent{Record: rec, CompressedT: compressedT, Grid: prevGrid, Width: width, Height: height})
+			continue
+		}
+
 		if rec.Type != RecordOutput {
 			continue
 		}
@@ -127,26 +233,34 @@ func (e *Exporter) Export() error {
 		// Snapshot and classify: scroll > filler > cosmetic
 		currGrid := emu.CellGrid(width, height)
 		scrolled := detectScrollGrid(prevGrid, currGrid, width, height)
+		cosmetic := false
 
 		if scrolled {
 			compressedT += scrollBeatDuration
 		} else if countChangedCells(prevGrid, currGrid, width, height) > cosmeticCellThreshold {
 			compressedT += fillerEventDuration
+		} else {
+			// cosmetic events (≤threshold cells changed): no timestamp advance
+			cosmetic = true
 		}
-		// cosmetic events (≤threshold cells changed): no timestamp advance
 
-		// Emit ALL events with compressed timestamp
-		cw.WriteEvent(compressedT, rec.D)
+		fn(compressedEvent{
+			Record:      rec,
+			CompressedT: compressedT,
+			Scrolled:    scrolled,
+			Cosmetic:    cosmetic,
+			Prev:        prevGrid,
+			Grid:        currGrid,
+			Width:       width,
+			Height:      height,
+		})
 		prevGrid = currGrid
 
 		// Report progress
 		if totalEvents > 0 && eventIdx%100 == 0 {
-			e.reportProgress(0.3 + 0.7*float64(eventIdx)/float64(totalEvents))
+			e.reportProgress(0.3 + 0.6*float64(eventIdx)/float64(totalEvents))
 		}
 	}
-
-	e.reportProgress(1.0)
-	return nil
 }
 
 func (e *Exporter) readHeader() (Record, error) {
@@ -2,6 +2,7 @@ package timelapse
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -182,6 +183,39 @@ func TestExporter_PreservesResizeEvents(t *testing.T) {
 	}
 }
 
+func TestExporter_PreservesMarkers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "with-markers.cast")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(f, `{"version":2,"width":40,"height":10,"timestamp":1711875300,"env":{"TERM":"xterm-256color"}}`)
+	for i := 0; i < 12; i++ {
+		fmt.Fprintf(f, "[%.6f,\"o\",%s]\n", float64(i), jsonEscapeBytes([]byte(fmt.Sprintf("line %02d of scrolling output\r\n", i))))
+	}
+	fmt.Fprintln(f, `[30.000000,"m","needs_input: which database?"]`)
+	fmt.Fprintln(f, `[31.000000,"o","answer\r\n"]`)
+	f.Close()
+
+	outputPath := filepath.Join(dir, "output.timelapse.cast")
+	if err := NewExporter(path, outputPath, nil).Export(); err != nil {
+		t.Fatal(err)
+	}
+
+	chapters, err := ReadChapters(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chapters) != 1 || chapters[0].Label != "needs_input: which database?" {
+		t.Fatalf("chapters = %+v", chapters)
+	}
+	// The marker keeps its place after the last scroll, in compressed time.
+	if chapters[0].Time >= 30 || chapters[0].Time <= 0 {
+		t.Errorf("chapter time = %v, want compressed time before 30s", chapters[0].Time)
+	}
+}
+
 func TestExporter_CosmeticEventsCollapsed(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "cosmetic.cast")
@@ -279,3 +313,105 @@ func TestExporter_PreservesContent(t *testing.T) {
 		t.Error("should contain 'after idle'")
 	}
 }
+
+func TestExporter_SVG(t *testing.T) {
+	dir := t.TempDir()
+	recordingPath := createSyntheticRecording(t, dir)
+	outputPath := filepath.Join(dir, "output"+FormatSVG.Ext())
+
+	exp := NewExporter(recordingPath, outputPath, nil)
+	exp.SetFormat(FormatSVG)
+	if err := exp.Export(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(outputPath)
+	var doc struct {
+		XMLName xml.Name
+		Width   string `xml:"width,attr"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("not well-formed XML: %v", err)
+	}
+	if doc.XMLName.Local != "svg" || doc.Width != "360.0" {
+		t.Errorf("root = %s width=%s, want svg width=360.0 (40 columns)", doc.XMLName.Local, doc.Width)
+	}
+	svg := string(data)
+	if !strings.Contains(svg, "@keyframes play") || !strings.Contains(svg, "after idle line 000 unique text here") {
+		t.Error("SVG should animate the screens of the recording")
+	}
+	// Cosmetic and filler events fold into the screens around them.
+	frames := strings.Count(svg, `<g transform=`)
+	if frames < 2 || frames > 30 {
+		t.Errorf("frames = %d, want a handful of distinct screens", frames)
+	}
+}
+
+func TestSampleFrames(t *testing.T) {
+	var frames []svgFrame
+	for i := 0; i < 10; i++ {
+		frames = append(frames, svgFrame{t: float64(i)})
+	}
+	got := sampleFrames(frames, 4)
+	if len(got) != 4 || got[0].t != 0 || got[3].t != 9 {
+		t.Errorf("sampled = %+v", got)
+	}
+}
+
+func TestExporter_Transcript(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "transcript.cast")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(f, `{"version":2,"width":40,"height":6,"timestamp":1711875300,"env":{"TERM":"xterm-256color"}}`)
+	// A status bar stays on the last row while output scrolls above it.
+	event := func(ts float64, data string) {
+		fmt.Fprintf(f, "[%.6f,\"o\",%s]\n", ts, jsonEscapeBytes([]byte(data)))
+	}
+	event(0, "\033[6;1H-- status --\033[1;5r\033[1;1H")
+	for i := 0; i < 8; i++ {
+		event(float64(i), fmt.Sprintf("line %02d\r\n", i))
+	}
+	fmt.Fprintln(f, `[65.000000,"m","needs_input: which database?"]`)
+	event(66, "\033[r\033[2J\033[1;1Hfresh screen")
+	f.Close()
+
+	export := func(format Format) string {
+		out := filepath.Join(dir, "out"+format.Ext())
+		exp := NewExporter(path, out, nil)
+		exp.SetFormat(format)
+		if err := exp.Export(); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(out)
+		return string(data)
+	}
+
+	want := "=== Timelapse ===\n\n" +
+		// Each state adds only the lines that scrolled into view.
+		"line 00\nline 01\nline 02\nline 03\n\n-- status --\nline 04\nline 05\nline 06\nline 07\n\n" +
+		"=== 1:05 needs_input: which database? ===\n\n" +
+		"fresh screen\n\n"
+	if got := export(FormatText); got != want {
+		t.Errorf("text transcript:\n%s\nwant:\n%s", got, want)
+	}
+
+	md := export(FormatMarkdown)
+	if !strings.HasPrefix(md, "# Timelapse\n\n```text\nline 00\n") ||
+		!strings.Contains(md, "```\n\n## 1:05 needs_input: which database?\n\n```text\nfresh screen\n```\n") {
+		t.Errorf("markdown transcript:\n%s", md)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"": FormatCast, "cast": FormatCast, "svg": FormatSVG, "text": FormatText, "markdown": FormatMarkdown} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v", name, got, err)
+		}
+	}
+	if _, err := ParseFormat("gif"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
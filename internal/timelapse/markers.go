@@ -0,0 +1,182 @@
+//go:build !notimelapse
+
+package timelapse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/events"
+)
+
+// maxMarkerLabel bounds a marker label in bytes; players show labels in a
+// chapter list, so long messages are cut.
+const maxMarkerLabel = 120
+
+// fenceLogPollInterval is how often a recorder checks its session's fence
+// monitor log for new denials.
+const fenceLogPollInterval = 2 * time.Second
+
+// Markers routes chapter markers to the recorders of running sessions. It
+// handles status events itself (see HandleEvent); other sources call Add. A
+// nil *Markers drops everything.
+type Markers struct {
+	mu         sync.Mutex
+	recorders  map[string]*Recorder
+	lastStatus map[string]string // session ID -> label of the last status marker
+}
+
+// NewMarkers creates an empty marker router.
+func NewMarkers() *Markers {
+	return &Markers{
+		recorders:  make(map[string]*Recorder),
+		lastStatus: make(map[string]string),
+	}
+}
+
+// Add writes a marker into the session's recording. It is dropped when the
+// session has no running recorder.
+func (m *Markers) Add(sessionID, label string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	r := m.recorders[sessionID]
+	m.mu.Unlock()
+	if r != nil {
+		r.Mark(label)
+	}
+}
+
+// HandleEvent implements events.EventHandler. Each status event that
+// changes the session's state or message becomes a marker, so repeated
+// "working" heartbeats do not flood the chapter list.
+func (m *Markers) HandleEvent(ctx context.Context, sessionID string, raw events.RawEvent, data []byte) {
+	if m == nil || raw.Type != "status" {
+		return
+	}
+	var ev events.StatusEvent
+	if err := json.Unmarshal(data, &ev); err != nil || ev.State == "" {
+		return
+	}
+	label := ev.State
+	if msg := strings.TrimSpace(ev.Message); msg != "" {
+		label += ": " + msg
+	}
+	m.mu.Lock()
+	if m.lastStatus[sessionID] == label {
+		m.mu.Unlock()
+		return
+	}
+	m.lastStatus[sessionID] = label
+	m.mu.Unlock()
+	m.Add(sessionID, label)
+}
+
+// attach makes r the recorder markers for its session go to.
+func (m *Markers) attach(r *Recorder) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recorders[r.sessionID] = r
+}
+
+// detach forgets r, unless a newer recorder for the session replaced it.
+func (m *Markers) detach(r *Recorder) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.recorders[r.sessionID] == r {
+		delete(m.recorders, r.sessionID)
+		delete(m.lastStatus, r.sessionID)
+	}
+}
+
+// markerLabel flattens label to one line of at most maxMarkerLabel bytes.
+func markerLabel(label string) string {
+	label = strings.Join(strings.Fields(label), " ")
+	if len(label) <= maxMarkerLabel {
+		return label
+	}
+	cut := maxMarkerLabel - len("…")
+	for cut > 0 && !isRuneStart(label[cut]) {
+		cut--
+	}
+	return label[:cut] + "…"
+}
+
+func isRuneStart(b byte) bool { return b&0xC0 != 0x80 }
+
+// watchFenceLog adds a marker for denials fence appends to the session's
+// monitor log until the recorder stops. Denials already in the log when
+// recording starts are skipped. Several denials seen in one poll share a
+// marker.
+func (r *Recorder) watchFenceLog(path string) {
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+	ticker := time.NewTicker(fenceLogPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+		}
+		var denials []string
+		offset, denials = readFenceDenials(path, offset)
+		if len(denials) == 0 {
+			continue
+		}
+		label := "fence: " + denials[0]
+		if len(denials) > 1 {
+			label += fmt.Sprintf(" (+%d more)", len(denials)-1)
+		}
+		r.Mark(label)
+	}
+}
+
+// readFenceDenials returns the denial messages in complete lines of the
+// fence monitor log past offset, and the offset after them. Lines look like
+// "[fence:<channel>] <time> ✗ <message>"; allowed (✓) lines are skipped.
+func readFenceDenials(path string, offset int64) (int64, []string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return offset, nil
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || info.Size() == offset {
+		return offset, nil
+	} else if info.Size() < offset {
+		offset = 0
+	}
+	data, err := io.ReadAll(io.NewSectionReader(f, offset, 1<<62))
+	if err != nil {
+		return offset, nil
+	}
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return offset, nil
+	}
+	var denials []string
+	for _, line := range strings.Split(string(data[:end]), "\n") {
+		if _, msg, ok := strings.Cut(line, "✗"); ok {
+			if msg = strings.TrimSpace(msg); msg != "" {
+				denials = append(denials, msg)
+			}
+		}
+	}
+	return offset + int64(end) + 1, denials
+}
@@ -0,0 +1,108 @@
+package timelapse
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/events"
+	"github.com/sergeknystautas/schmux/internal/session"
+)
+
+func chapterLabels(t *testing.T, path string) []string {
+	t.Helper()
+	chapters, err := ReadChapters(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels []string
+	for _, c := range chapters {
+		labels = append(labels, c.Label)
+	}
+	return labels
+}
+
+func TestMarkers_RoutedToRunningRecorder(t *testing.T) {
+	dir := t.TempDir()
+	ol := session.NewOutputLog(100)
+	rec, err := NewRecorder("s-1", ol, nil, dir, 0, 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	markers := NewMarkers()
+	rec.SetMarkers(markers)
+
+	markers.Add("s-1", "dropped: recorder not running yet")
+	go rec.Run()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		markers.mu.Lock()
+		attached := markers.recorders["s-1"] == rec
+		markers.mu.Unlock()
+		if attached {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("recorder did not attach")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	status := func(state, message string) {
+		markers.HandleEvent(context.Background(), "s-1", events.RawEvent{Type: "status"},
+			[]byte(`{"ts":"2026-01-01T00:00:00Z","type":"status","state":"`+state+`","message":"`+message+`"}`))
+	}
+	status("working", "")
+	status("working", "") // unchanged: no marker
+	status("needs_input", "which  database?")
+	markers.Add("s-2", "other session")
+	markers.Add("s-1", "tell: use postgres\nplease")
+	ol.Append([]byte("ok\r\n"))
+	time.Sleep(50 * time.Millisecond)
+	rec.Stop()
+	markers.Add("s-1", "after stop")
+
+	want := []string{"working", "needs_input: which database?", "tell: use postgres please"}
+	if got := chapterLabels(t, filepath.Join(dir, "s-1.cast")); !reflect.DeepEqual(got, want) {
+		t.Errorf("chapters = %q, want %q", got, want)
+	}
+	if len(markers.recorders) != 0 {
+		t.Error("stopped recorder still attached")
+	}
+}
+
+func TestMarkerLabel_Truncates(t *testing.T) {
+	label := markerLabel(strings.Repeat("é", maxMarkerLabel))
+	if len(label) > maxMarkerLabel || !strings.HasSuffix(label, "…") || strings.ContainsRune(label, '�') {
+		t.Errorf("label = %q (%d bytes)", label, len(label))
+	}
+}
+
+func TestReadFenceDenials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitor.log")
+	os.WriteFile(path, []byte(
+		"[fence:http] 12:00:01 ✓ CONNECT 200 api.anthropic.com https://api.anthropic.com (20ms)\n"+
+			"[fence:http] 12:00:02 ✗ CONNECT 403 evil.example.com https://evil.example.com (1ms)\n"+
+			"[fence:logstream] 12:00:03 ✗ file-write-create /etc/hosts (sh:42)\n"+
+			"[fence:logstream] 12:00:04 ✗ partial"), 0o600)
+
+	offset, denials := readFenceDenials(path, 0)
+	want := []string{
+		"CONNECT 403 evil.example.com https://evil.example.com (1ms)",
+		"file-write-create /etc/hosts (sh:42)",
+	}
+	if !reflect.DeepEqual(denials, want) {
+		t.Errorf("denials = %q, want %q", denials, want)
+	}
+	// The unterminated line is picked up once it is complete.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	f.WriteString(" line\n")
+	f.Close()
+	if _, denials = readFenceDenials(path, offset); !reflect.DeepEqual(denials, []string{"partial line"}) {
+		t.Errorf("denials after append = %q", denials)
+	}
+}
@@ -7,12 +7,18 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
+	"github.com/sergeknystautas/schmux/internal/redact"
 	"github.com/sergeknystautas/schmux/internal/session"
 )
 
+// redactFlushDelay is how long the recorder holds back the end of the
+// output for the redaction stream when no more output follows.
+const redactFlushDelay = 200 * time.Millisecond
+
 // Recorder writes a continuous asciicast v2 recording (.cast) of session output.
 // It tails the OutputLog using WaitForNew and writes events directly in the
 // asciicast format so recordings are immediately playable with asciinema.
@@ -21,13 +27,18 @@ type Recorder struct {
 	sessionID    string
 	outputLog    *session.OutputLog
 	gapCh        <-chan session.SourceEvent
+	markers      *Markers
+	fenceLogPath string
+	mu           sync.Mutex // guards file, bytesWritten and closed; markers are written from other goroutines
 	file         *os.File
+	closed       bool
 	startTime    time.Time
 	resumed      bool   // true when appending to an existing recording
-	startWaitSeq uint64 // captured at construction to avoid missing entries
+	startWaitSeq uint64 // captured at construction; earlier entries (restored scrollback) are already recorded
 	lastSeq      uint64
-	seenFirst    bool   // true after processing at least one entry
-	utf8Pending  []byte // buffered incomplete UTF-8 sequence from previous chunk
+	seenFirst    bool           // true after processing at least one entry
+	utf8Pending  []byte         // buffered incomplete UTF-8 sequence from previous chunk
+	redactStream *redact.Stream // masks secrets, also ones split across chunks
 	bytesWritten int64
 	maxBytes     int64
 	width        int
@@ -75,7 +86,8 @@ func NewRecorder(
 		gapCh:        gapCh,
 		file:         file,
 		startTime:    time.Now(),
-		startWaitSeq: 0,
+		startWaitSeq: outputLog.CurrentSeq(),
+		redactStream: redact.NewStream(redact.SiteRecording),
 		maxBytes:     maxBytes,
 		width:        width,
 		height:       height,
@@ -154,8 +166,9 @@ func openForResume(
 		file:         file,
 		startTime:    info.StartTime,
 		resumed:      true,
-		startWaitSeq: 0,
+		startWaitSeq: outputLog.CurrentSeq(),
 		bytesWritten: info.FileSize,
+		redactStream: redact.NewStream(redact.SiteRecording),
 		maxBytes:     maxBytes,
 		stopCh:       make(chan struct{}),
 		doneCh:       make(chan struct{}),
@@ -165,25 +178,51 @@ func openForResume(
 // RecordingID returns the unique recording identifier.
 func (r *Recorder) RecordingID() string { return r.recordingID }
 
+// SetMarkers registers the recorder with m while it runs, so markers added
+// for its session land in the recording. Call before Run.
+func (r *Recorder) SetMarkers(m *Markers) { r.markers = m }
+
+// WatchFenceLog makes the recorder mark denials fence appends to the
+// monitor log at path while it runs. Call before Run.
+func (r *Recorder) WatchFenceLog(path string) { r.fenceLogPath = path }
+
+// Mark writes an asciicast marker ("m") event at the current time. Players
+// use markers as chapters. Safe to call from any goroutine; a no-op once the
+// recorder has stopped.
+func (r *Recorder) Mark(label string) {
+	if label = markerLabel(label); label != "" {
+		r.writeEvent("m", jsonEscapeBytes([]byte(label)))
+	}
+}
+
 // Run is the main recording loop. It blocks until Stop is called
 // or the size cap is reached.
 func (r *Recorder) Run() {
 	defer close(r.doneCh)
-	defer r.file.Close()
+	defer r.closeFile()
+	defer r.flushOutput()
 
 	// Write asciicast v2 header only for new recordings — resumed files
 	// already have one.
 	if !r.resumed {
 		header := fmt.Sprintf(`{"version":2,"width":%d,"height":%d,"timestamp":%d,"title":"%s","env":{"TERM":"xterm-256color"}}`,
 			r.width, r.height, r.startTime.Unix(), r.sessionID)
-		r.writeLine(header)
+		r.mu.Lock()
+		r.writeLineLocked(header)
+		r.mu.Unlock()
+	}
+
+	r.markers.attach(r)
+	defer r.markers.detach(r)
+	if r.fenceLogPath != "" {
+		go r.watchFenceLog(r.fenceLogPath)
 	}
 
 	waitSeq := r.startWaitSeq
 
 	for {
 		// Wait for new output
-		if !r.outputLog.WaitForNew(waitSeq, r.stopCh) {
+		if !r.waitForOutput(waitSeq) {
 			return
 		}
 
@@ -192,7 +231,7 @@ func (r *Recorder) Run() {
 		if r.seenFirst {
 			replayFrom = r.lastSeq + 1
 		} else {
-			replayFrom = r.outputLog.OldestSeq()
+			replayFrom = max(r.outputLog.OldestSeq(), r.startWaitSeq)
 		}
 
 		// Check for buffer overrun
@@ -221,12 +260,44 @@ func (r *Recorder) Run() {
 		r.drainGapCh()
 
 		// Check size cap
-		if r.maxBytes > 0 && r.bytesWritten >= r.maxBytes {
+		if r.overCap() {
 			return
 		}
 	}
 }
 
+// waitForOutput waits for output after seq and reports false once the
+// recorder is stopped. While the redaction stream holds bytes back, it
+// writes them after redactFlushDelay without new output, so a prompt that
+// ends in a token is not recorded late.
+func (r *Recorder) waitForOutput(seq uint64) bool {
+	for r.redactStream.Pending() {
+		wake := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-r.stopCh:
+			case <-time.After(redactFlushDelay):
+			case <-done:
+				return
+			}
+			close(wake)
+		}()
+		ok := r.outputLog.WaitForNew(seq, wake)
+		close(done)
+		if ok {
+			return true
+		}
+		select {
+		case <-r.stopCh:
+			return false
+		default:
+		}
+		r.flushOutput()
+	}
+	return r.outputLog.WaitForNew(seq, r.stopCh)
+}
+
 // Stop signals the recorder to stop and waits for it to finish.
 func (r *Recorder) Stop() {
 	close(r.stopCh)
@@ -236,6 +307,11 @@ func (r *Recorder) Stop() {
 // writeOutputEvent writes an asciicast output event, buffering incomplete
 // UTF-8 sequences to avoid splitting multi-byte characters across events.
 func (r *Recorder) writeOutputEvent(data []byte) {
+	// Secrets printed to the terminal are masked before they reach disk.
+	// The stream holds back a trailing token until the next chunk shows
+	// whether it is part of a secret.
+	data = r.redactStream.Write(data)
+
 	// Prepend any pending bytes from the previous chunk
 	if len(r.utf8Pending) > 0 {
 		data = append(r.utf8Pending, data...)
@@ -257,25 +333,54 @@ func (r *Recorder) writeOutputEvent(data []byte) {
 		return
 	}
 
-	t := r.elapsed()
-	escaped := jsonEscapeBytes(data)
-	line := fmt.Sprintf("[%.6f,\"o\",%s]", t, escaped)
-	r.writeLine(line)
+	r.writeEvent("o", jsonEscapeBytes(data))
+}
+
+// flushOutput writes the output still buffered when recording ends.
+func (r *Recorder) flushOutput() {
+	data := append(r.utf8Pending, r.redactStream.Flush()...)
+	r.utf8Pending = nil
+	if len(data) > 0 {
+		r.writeEvent("o", jsonEscapeBytes(data))
+	}
 }
 
 // writeResizeEvent writes an asciicast resize event (custom type "r").
 func (r *Recorder) writeResizeEvent(width, height int) {
-	t := r.elapsed()
-	line := fmt.Sprintf("[%.6f,\"r\",\"%dx%d\"]", t, width, height)
-	r.writeLine(line)
+	r.writeEvent("r", fmt.Sprintf("\"%dx%d\"", width, height))
+}
+
+// writeEvent writes an event of the given asciicast type with an already
+// JSON-encoded data field. The timestamp is taken under the lock so events
+// written from different goroutines stay in time order in the file.
+func (r *Recorder) writeEvent(code, data string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.writeLineLocked(fmt.Sprintf("[%.6f,\"%s\",%s]", r.elapsed(), code, data))
 }
 
-func (r *Recorder) writeLine(line string) {
+func (r *Recorder) writeLineLocked(line string) {
 	n, _ := fmt.Fprintln(r.file, line)
 	r.bytesWritten += int64(n)
 	r.file.Sync() // ensure data is visible to readers immediately
 }
 
+func (r *Recorder) overCap() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.maxBytes > 0 && r.bytesWritten >= r.maxBytes
+}
+
+func (r *Recorder) closeFile() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	r.file.Close()
+}
+
 func (r *Recorder) elapsed() float64 {
 	return time.Since(r.startTime).Seconds()
 }
@@ -22,9 +22,10 @@ func TestRecorder_BasicRecording(t *testing.T) {
 
 	go rec.Run()
 
-	// Append some output
-	ol.Append([]byte("hello"))
-	ol.Append([]byte("world"))
+	// Append some output. Each chunk ends in a line break, so the
+	// redaction stream holds nothing back.
+	ol.Append([]byte("hello\r\n"))
+	ol.Append([]byte("world\r\n"))
 	time.Sleep(50 * time.Millisecond) // let recorder process
 
 	rec.Stop()
@@ -77,10 +78,10 @@ func TestRecorder_BasicRecording(t *testing.T) {
 	if len(outputs) < 2 {
 		t.Fatalf("expected at least 2 output records, got %d", len(outputs))
 	}
-	if outputs[0].D != "hello" {
+	if outputs[0].D != "hello\r\n" {
 		t.Errorf("output 0 data = %q, want hello", outputs[0].D)
 	}
-	if outputs[1].D != "world" {
+	if outputs[1].D != "world\r\n" {
 		t.Errorf("output 1 data = %q, want world", outputs[1].D)
 	}
 
@@ -394,3 +395,73 @@ func TestRecorder_BufferOverrun(t *testing.T) {
 		t.Error("header version should be 2")
 	}
 }
+
+func TestRecorder_SkipsRestoredScrollback(t *testing.T) {
+	dir := t.TempDir()
+	ol := session.NewOutputLog(1000)
+	// History restored from disk after a daemon restart was recorded by the
+	// previous run and must not be written again.
+	ol.Restore([]session.LogEntry{{Seq: 7, Data: []byte("restored")}})
+
+	rec, err := NewRecorder("test-session", ol, nil, dir, 0, 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go rec.Run()
+	ol.Append([]byte("live"))
+	time.Sleep(50 * time.Millisecond)
+	rec.Stop()
+
+	data, _ := os.ReadFile(filepath.Join(dir, "test-session.cast"))
+	if strings.Contains(string(data), "restored") {
+		t.Error("restored output was recorded again")
+	}
+	if !strings.Contains(string(data), "live") {
+		t.Error("live output missing from recording")
+	}
+}
+
+func TestRecorder_RedactsSecrets(t *testing.T) {
+	dir := t.TempDir()
+	ol := session.NewOutputLog(100)
+	rec, err := NewRecorder("s-1", ol, nil, dir, 0, 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go rec.Run()
+	// The token arrives in two chunks, and the last one is still held back
+	// when the recording stops.
+	ol.Append([]byte("export GITHUB_TOKEN=ghp_a1B2"))
+	time.Sleep(50 * time.Millisecond)
+	ol.Append([]byte(strings.Repeat("a1B2", 8) + "\r\n$ echo AKIAIOSF"))
+	time.Sleep(50 * time.Millisecond)
+	ol.Append([]byte("ODNN7EXAMPLE"))
+	time.Sleep(50 * time.Millisecond)
+	rec.Stop()
+
+	data, _ := os.ReadFile(filepath.Join(dir, "s-1.cast"))
+	if strings.Contains(string(data), "ghp_") || !strings.Contains(string(data), "GITHUB_TOKEN=[REDACTED:github_token]") {
+		t.Errorf("split token not redacted:\n%s", data)
+	}
+	if strings.Contains(string(data), "AKIA") || !strings.Contains(string(data), "[REDACTED:aws_access_key]") {
+		t.Errorf("token held back at stop not redacted or not written:\n%s", data)
+	}
+}
+
+func TestRecorder_WritesHeldBackOutputWhenIdle(t *testing.T) {
+	dir := t.TempDir()
+	ol := session.NewOutputLog(100)
+	rec, err := NewRecorder("s-1", ol, nil, dir, 0, 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go rec.Run()
+	defer rec.Stop()
+	ol.Append([]byte("Continue? (y/n)"))
+	time.Sleep(redactFlushDelay + 200*time.Millisecond)
+
+	data, _ := os.ReadFile(filepath.Join(dir, "s-1.cast"))
+	if !strings.Contains(string(data), `"(y/n)"`) {
+		t.Errorf("prompt not recorded while the session is idle:\n%s", data)
+	}
+}
@@ -25,6 +25,33 @@ type RecordingInfo struct {
 	InProgress    bool
 	HasCompressed bool
 	Path          string
+	// Chapters are the recording's markers (status changes, tells, commits,
+	// fence denials) in time order. Times are seconds into the raw recording.
+	Chapters []Chapter
+}
+
+// Chapter is a marker event in a recording.
+type Chapter struct {
+	Time  float64
+	Label string
+}
+
+// ReadChapters returns the markers of the recording at path, in the
+// timeline of that file (raw or compressed).
+func ReadChapters(path string) ([]Chapter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	chapters := []Chapter{}
+	err = ReadCastEvents(f, func(rec Record) bool {
+		if rec.Type == RecordMarker && rec.T != nil {
+			chapters = append(chapters, Chapter{Time: *rec.T, Label: rec.D})
+		}
+		return true
+	})
+	return chapters, err
 }
 
 // ListRecordings returns metadata for all recordings in dir.
@@ -96,6 +123,7 @@ func parseRecordingInfo(path string) (RecordingInfo, error) {
 		Path:          path,
 		InProgress:    true, // assumed until we see events with timestamps
 		HasCompressed: compressedErr == nil,
+		Chapters:      []Chapter{},
 	}
 
 	// Read the asciicast v2 header (first line is a JSON object)
@@ -119,6 +147,11 @@ func parseRecordingInfo(path string) (RecordingInfo, error) {
 				info.InProgress = false // has at least one event
 			}
 			return true
+		case RecordMarker:
+			if rec.T != nil {
+				info.Chapters = append(info.Chapters, Chapter{Time: *rec.T, Label: rec.D})
+			}
+			return true
 		default:
 			return true
 		}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -162,3 +163,21 @@ func TestFilePermissions(t *testing.T) {
 		t.Errorf("file permissions = %o, want 0600", info.Mode().Perm())
 	}
 }
+
+func TestListRecordings_Chapters(t *testing.T) {
+	dir := t.TempDir()
+	path := createTestRecording(t, dir, "s1", "s1", time.Now(), 10.0)
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	fmt.Fprintln(f, `[4.000000,"m","needs_input"]`)
+	fmt.Fprintln(f, `[6.500000,"m","commit abc1234: fix login"]`)
+	f.Close()
+
+	recordings, err := ListRecordings(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Chapter{{Time: 4, Label: "needs_input"}, {Time: 6.5, Label: "commit abc1234: fix login"}}
+	if len(recordings) != 1 || !reflect.DeepEqual(recordings[0].Chapters, want) {
+		t.Errorf("chapters = %+v, want %+v", recordings[0].Chapters, want)
+	}
+}
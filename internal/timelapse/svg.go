@@ -0,0 +1,118 @@
+//go:build !notimelapse
+
+package timelapse
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+const (
+	// svgMaxFrames bounds the number of distinct screens in an SVG export.
+	// Longer timelines are sampled evenly so the file stays small enough to
+	// embed in a pull request.
+	svgMaxFrames = 600
+	// svgMinFrameDuration is the shortest time a screen is shown. Screens
+	// replaced sooner are folded into the next one; filler events are only
+	// a millisecond apart after compression and would never be visible.
+	svgMinFrameDuration = 0.05
+	// svgEndHold is how long the last screen stays up before the animation
+	// loops.
+	svgEndHold = 3.0
+
+	svgFontSize   = 14
+	svgCellWidth  = 8.4 // advance of a 14px monospace glyph
+	svgLineHeight = 17
+	svgPadding    = 12
+	svgBackground = "#1e1e1e"
+	svgForeground = "#d4d4d4"
+)
+
+// svgFrame is a screen shown from time t on the compressed timeline.
+type svgFrame struct {
+	t     float64
+	lines []string
+}
+
+// writeSVG renders the compressed timeline as an animated SVG. Every
+// distinct screen becomes one frame of a vertical film strip, and a CSS
+// animation steps the strip past a terminal-sized viewport at the frames'
+// compressed timestamps. The text is monochrome: colors and attributes are
+// not kept.
+func (e *Exporter) writeSVG(w io.Writer, records []Record, width, height int) error {
+	cols, rows := width, height
+	frames := []svgFrame{{t: 0}}
+	e.compress(records, width, height, func(ev compressedEvent) {
+		cols, rows = max(cols, ev.Width), max(rows, ev.Height)
+		if ev.Type != RecordOutput && ev.Type != RecordResize {
+			return
+		}
+		lines := gridLines(ev.Grid)
+		last := &frames[len(frames)-1]
+		if slices.Equal(last.lines, lines) {
+			return
+		}
+		if ev.CompressedT-last.t < svgMinFrameDuration {
+			last.lines = lines
+			return
+		}
+		frames = append(frames, svgFrame{t: ev.CompressedT, lines: lines})
+	})
+	frames = sampleFrames(frames, svgMaxFrames)
+
+	viewW := float64(cols) * svgCellWidth
+	viewH := rows * svgLineHeight
+	totalW := viewW + 2*svgPadding
+	totalH := viewH + 2*svgPadding
+	duration := frames[len(frames)-1].t + svgEndHold
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%.1f" height="%d" viewBox="0 0 %.1f %d" xml:space="preserve">`+"\n",
+		totalW, totalH, totalW, totalH)
+	bw.WriteString("<style>\n")
+	fmt.Fprintf(bw, `text{font-family:ui-monospace,SFMono-Regular,Menlo,Consolas,"Liberation Mono",monospace;font-size:%dpx;fill:%s;white-space:pre}`+"\n",
+		svgFontSize, svgForeground)
+	if len(frames) > 1 {
+		fmt.Fprintf(bw, "#film{animation:play %.3fs steps(1,end) infinite}\n", duration)
+		bw.WriteString("@keyframes play{")
+		for i, f := range frames {
+			fmt.Fprintf(bw, "%.4f%%{transform:translateY(%dpx)}", f.t/duration*100, -i*viewH)
+		}
+		bw.WriteString("}\n")
+	}
+	bw.WriteString("</style>\n")
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" rx="6" fill="%s"/>`+"\n", svgBackground)
+	fmt.Fprintf(bw, `<svg x="%d" y="%d" width="%.1f" height="%d">`+"\n", svgPadding, svgPadding, viewW, viewH)
+	bw.WriteString(`<g id="film">` + "\n")
+	for i, f := range frames {
+		fmt.Fprintf(bw, `<g transform="translate(0 %d)">`, i*viewH)
+		for y, line := range f.lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			fmt.Fprintf(bw, `<text y="%d">`, y*svgLineHeight+svgFontSize)
+			xml.EscapeText(bw, []byte(line))
+			bw.WriteString("</text>")
+		}
+		bw.WriteString("</g>\n")
+	}
+	bw.WriteString("</g>\n</svg>\n</svg>\n")
+	return bw.Flush()
+}
+
+// sampleFrames keeps at most n frames, evenly spaced and always including
+// the first and last.
+func sampleFrames(frames []svgFrame, n int) []svgFrame {
+	if len(frames) <= n {
+		return frames
+	}
+	sampled := make([]svgFrame, 0, n)
+	for i := 0; i < n-1; i++ {
+		sampled = append(sampled, frames[i*(len(frames)-1)/(n-1)])
+	}
+	return append(sampled, frames[len(frames)-1])
+}
@@ -0,0 +1,175 @@
+//go:build !notimelapse
+
+package timelapse
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// writeTranscript renders the recording as a deduplicated transcript of the
+// screen states worth reading. A state is taken just before an event wipes
+// text off the screen (a scroll, a clear, a redraw), at each marker, and at
+// the end. Only lines that were not on the previous state are written, so
+// text that scrolls up, or a status bar that stays put, appears once.
+// Markers become headings stamped with their original recording time.
+func (e *Exporter) writeTranscript(w io.Writer, records []Record, width, height int, title string) error {
+	tw := &transcriptWriter{w: bufio.NewWriter(w), markdown: e.format == FormatMarkdown}
+	tw.heading(1, strings.TrimSpace("Timelapse "+title))
+
+	var final [][]rune
+	e.compress(records, width, height, func(ev compressedEvent) {
+		switch ev.Type {
+		case RecordMarker:
+			tw.snapshot(ev.Grid)
+			tw.heading(2, formatRecordingTime(ev.T)+" "+ev.D)
+		case RecordOutput:
+			if !ev.Cosmetic && losesText(ev.Prev, ev.Grid) {
+				tw.snapshot(ev.Prev)
+			}
+		}
+		final = ev.Grid
+	})
+	if final != nil {
+		tw.snapshot(final)
+	}
+	tw.closeBlock()
+	return tw.w.Flush()
+}
+
+// transcriptWriter writes screen states as plain text or markdown. Runs of
+// screen lines are blocks: fenced code blocks in markdown, paragraphs
+// separated by a blank line in plain text.
+type transcriptWriter struct {
+	w         *bufio.Writer
+	markdown  bool
+	last      []string // lines of the previous screen state
+	inBlock   bool
+	lastBlank bool // the previous line written to the block was blank
+}
+
+// snapshot writes the lines of grid that are new since the previous state.
+// A screen that shares no lines with the previous one starts a new block.
+func (t *transcriptWriter) snapshot(grid [][]rune) {
+	lines := gridLines(grid)
+	if slices.Equal(lines, t.last) {
+		return
+	}
+	kept := commonLines(t.last, lines)
+	if len(t.last) > 0 && !slices.Contains(kept, true) {
+		t.closeBlock()
+	}
+	t.last = lines
+	for i, line := range lines {
+		if !kept[i] {
+			t.line(line)
+		}
+	}
+}
+
+func (t *transcriptWriter) line(line string) {
+	blank := strings.TrimSpace(line) == ""
+	if blank && (!t.inBlock || t.lastBlank) {
+		return
+	}
+	if !t.inBlock {
+		if t.markdown {
+			t.w.WriteString("```text\n")
+		}
+		t.inBlock = true
+	}
+	t.lastBlank = blank
+	t.w.WriteString(line)
+	t.w.WriteByte('\n')
+}
+
+func (t *transcriptWriter) closeBlock() {
+	if !t.inBlock {
+		return
+	}
+	if t.markdown {
+		t.w.WriteString("```\n")
+	}
+	t.w.WriteByte('\n')
+	t.inBlock = false
+	t.lastBlank = false
+}
+
+func (t *transcriptWriter) heading(level int, text string) {
+	t.closeBlock()
+	if t.markdown {
+		fmt.Fprintf(t.w, "%s %s\n\n", strings.Repeat("#", level), text)
+	} else {
+		fmt.Fprintf(t.w, "=== %s ===\n\n", text)
+	}
+}
+
+// commonLines marks the lines of curr that belong to a longest common
+// subsequence of prev and curr; the unmarked ones are new.
+func commonLines(prev, curr []string) []bool {
+	lcs := make([][]int, len(prev)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(curr)+1)
+	}
+	for i := len(prev) - 1; i >= 0; i-- {
+		for j := len(curr) - 1; j >= 0; j-- {
+			if prev[i] == curr[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	kept := make([]bool, len(curr))
+	for i, j := 0, 0; i < len(prev) && j < len(curr); {
+		switch {
+		case prev[i] == curr[j]:
+			kept[j] = true
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return kept
+}
+
+// losesText reports whether some non-blank line of prev is gone from curr.
+// A line that grew (curr has a line starting with it) is not gone, so text
+// streaming into a line is captured once, when it is complete.
+func losesText(prev, curr [][]rune) bool {
+	currLines := gridLines(curr)
+	for _, line := range gridLines(prev) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		found := false
+		for _, c := range currLines {
+			if strings.HasPrefix(c, line) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return true
+		}
+	}
+	return false
+}
+
+// formatRecordingTime formats a recording timestamp as m:ss or h:mm:ss.
+func formatRecordingTime(t *float64) string {
+	var secs int
+	if t != nil {
+		secs = int(*t)
+	}
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
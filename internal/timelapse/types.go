@@ -18,6 +18,7 @@ const (
 	RecordHeader RecordType = "header"
 	RecordOutput RecordType = "output"
 	RecordResize RecordType = "resize"
+	RecordMarker RecordType = "marker"
 	RecordGap    RecordType = "gap"
 	RecordEnd    RecordType = "end"
 )
@@ -35,7 +36,7 @@ type Record struct {
 	StartTime   string     `json:"startTime,omitempty"`   // header (RFC3339)
 	T           *float64   `json:"t,omitempty"`           // all except header — pointer to avoid omitting t=0.0
 	Seq         uint64     `json:"seq,omitempty"`         // output
-	D           string     `json:"d,omitempty"`           // output (terminal data)
+	D           string     `json:"d,omitempty"`           // output (terminal data), marker (label)
 	Reason      string     `json:"reason,omitempty"`      // gap
 	LostSeqs    [2]uint64  `json:"lostSeqs,omitempty"`    // gap: [first, last]
 	Snapshot    *string    `json:"snapshot,omitempty"`    // gap: nullable screen content
@@ -47,7 +48,8 @@ func IsAvailable() bool { return true }
 // ReadCastEvents reads an asciicast v2 file (.cast format).
 // The first line is a JSON header object; subsequent lines are [timestamp, type, data] arrays.
 // Events are returned as Record structs via the callback.
-// Event type "o" maps to RecordOutput; "r" maps to RecordResize (data is "WxH").
+// Event type "o" maps to RecordOutput; "r" maps to RecordResize (data is "WxH");
+// "m" maps to RecordMarker (data is the label).
 func ReadCastEvents(r io.Reader, fn func(Record) bool) error {
 	scanner := bufio.NewScanner(r)
 	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024) // 10MB max line
@@ -120,6 +122,10 @@ func ReadCastEvents(r io.Reader, fn func(Record) bool) error {
 			if !fn(rec) {
 				return nil
 			}
+		case "m":
+			if !fn(Record{Type: RecordMarker, T: &t, D: data}) {
+				return nil
+			}
 		case "r":
 			rec := Record{
 				Type: RecordResize,
@@ -73,6 +73,25 @@ func TestReadCastEvents_ResizeEvent(t *testing.T) {
 	}
 }
 
+func TestReadCastEvents_MarkerEvent(t *testing.T) {
+	input := `{"version":2,"width":80,"height":24}
+[2.250000,"m","working: writing tests"]
+`
+	var records []Record
+	if err := ReadCastEvents(strings.NewReader(input), func(rec Record) bool {
+		records = append(records, rec)
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1].Type != RecordMarker {
+		t.Fatalf("records = %+v", records)
+	}
+	if records[1].D != "working: writing tests" || records[1].T == nil || *records[1].T != 2.25 {
+		t.Errorf("marker = %+v", records[1])
+	}
+}
+
 func TestReadCastEvents_StopEarly(t *testing.T) {
 	input := `{"version":2,"width":80,"height":24}
 [0.000000,"o","a"]
@@ -0,0 +1,157 @@
+package tournament
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/oneshot"
+	"github.com/sergeknystautas/schmux/internal/schema"
+)
+
+func init() {
+	// Register the JudgeResult type for JSON schema generation.
+	schema.Register(schema.LabelTournamentJudge, JudgeResult{})
+}
+
+const (
+	// JudgePrompt asks a target to rank tournament candidates.
+	JudgePrompt = `
+You are judging competing solutions to the same coding task. Each candidate
+worked independently in its own copy of the repository.
+
+Rank every candidate from best (1) to worst. Judge whether the change solves
+the task first, then the test results, then the quality and scope of the
+change. When candidates are otherwise equal, prefer the smaller change.
+{{CRITERIA}}
+Here is the task:
+<<<
+{{TASK}}
+>>>
+
+{{CANDIDATES}}
+Respond with one ranking entry per candidate, using its letter, with a short
+rationale, and a summary explaining why the winner is best.
+`
+
+	judgeTimeout = 5 * time.Minute
+
+	// MaxPatchBytes caps the diff shown to the judge for each candidate.
+	MaxPatchBytes = 30000
+	// maxTestOutputBytes caps the stored tail of a test command's output.
+	maxTestOutputBytes = 4096
+)
+
+// JudgeRanking is the judge's verdict on one candidate.
+type JudgeRanking struct {
+	Candidate string   `json:"candidate" required:"true"`
+	Rank      int      `json:"rank" required:"true"`
+	Rationale string   `json:"rationale" required:"true"`
+	_         struct{} `additionalProperties:"false"`
+}
+
+// JudgeResult is the parsed judge response.
+// Struct tags control JSON schema generation via swaggest/jsonschema-go.
+type JudgeResult struct {
+	Ranking []JudgeRanking `json:"ranking" required:"true"`
+	Summary string         `json:"summary" required:"true"`
+	_       struct{}       `additionalProperties:"false"`
+}
+
+// AskJudge runs the judge prompt against targetName.
+// Errors surfaced:
+//   - oneshot.ErrTargetNotFound    (target missing)
+//   - oneshot.ErrInvalidResponse   (LLM output not parseable)
+func AskJudge(ctx context.Context, cfg *config.Config, targetName, prompt string) (JudgeResult, error) {
+	return oneshot.ExecuteTarget[JudgeResult](ctx, cfg, targetName, prompt, schema.LabelTournamentJudge, judgeTimeout, "")
+}
+
+// buildJudgePrompt renders the judge prompt for candidates, labelling them
+// A, B, C... so the judge never sees target names. Returns the prompt and a
+// map from label to session ID.
+func buildJudgePrompt(t contracts.Tournament, candidates []contracts.TournamentCandidate, evals map[string]Evaluation) (string, map[string]string) {
+	labels := make(map[string]string, len(candidates))
+	var b strings.Builder
+	for i, c := range candidates {
+		label := candidateLabel(i)
+		labels[label] = c.SessionID
+		ev := evals[c.SessionID]
+
+		fmt.Fprintf(&b, "=== Candidate %s ===\n", label)
+		fmt.Fprintf(&b, "Files changed: %d (+%d -%d)\n", len(ev.Files), ev.LinesAdded, ev.LinesRemoved)
+		switch {
+		case ev.Test == nil:
+			b.WriteString("Tests: not run\n")
+		case ev.Test.Passed:
+			b.WriteString("Tests: passed\n")
+		default:
+			fmt.Fprintf(&b, "Tests: failed (exit %d)\n<<<\n%s\n>>>\n", ev.Test.ExitCode, strings.TrimSpace(ev.Test.Output))
+		}
+		if c.State == contracts.TournamentCandidateError {
+			b.WriteString("The agent reported an error before finishing.\n")
+		}
+		fmt.Fprintf(&b, "Diff:\n<<<\n%s\n>>>\n\n", strings.TrimSpace(ev.Patch))
+	}
+
+	criteria := ""
+	if s := strings.TrimSpace(t.Options.Criteria); s != "" {
+		criteria = "\nAdditional criteria from the user: " + s + "\n"
+	}
+	prompt := strings.NewReplacer(
+		"{{CRITERIA}}", criteria,
+		"{{TASK}}", strings.TrimSpace(t.Prompt),
+		"{{CANDIDATES}}", b.String(),
+	).Replace(JudgePrompt)
+	return prompt, labels
+}
+
+func candidateLabel(i int) string {
+	if i < 26 {
+		return string(rune('A' + i))
+	}
+	return fmt.Sprintf("%c%d", 'A'+i%26, i/26)
+}
+
+// RunTestCommand runs argv in dir and reports the outcome, keeping the tail
+// of its combined output. A command that cannot start counts as failed.
+func RunTestCommand(ctx context.Context, dir string, argv []string) *contracts.TournamentTestResult {
+	start := time.Now()
+	result := &contracts.TournamentTestResult{ExitCode: -1}
+	if len(argv) == 0 {
+		result.Output = "empty test command"
+		return result
+	}
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	result.DurationMs = time.Since(start).Milliseconds()
+	result.Output = tail(out.String(), maxTestOutputBytes)
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		result.Passed = true
+		result.ExitCode = 0
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		result.Output = strings.TrimSpace(result.Output + "\n" + err.Error())
+	}
+	return result
+}
+
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "..." + s[len(s)-n:]
+}
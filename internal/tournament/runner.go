@@ -0,0 +1,616 @@
+// Package tournament tracks best-of-N spawns: sibling sessions given the same
+// prompt, each in its own workspace. Once every candidate reports completed,
+// the runner compares their diffs and test results, optionally asks a judge
+// target to rank them, and on promotion disposes the losers.
+package tournament
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/events"
+	"github.com/sergeknystautas/schmux/internal/fileutil"
+)
+
+var (
+	ErrNotFound      = errors.New("tournament: not found")
+	ErrFinished      = errors.New("tournament: already finished")
+	ErrComparing     = errors.New("tournament: comparison in progress")
+	ErrNoWinner      = errors.New("tournament: no winner to promote")
+	ErrNotCandidate  = errors.New("tournament: session is not a live candidate")
+	ErrNoCandidates  = errors.New("tournament: no candidates")
+	errNoCompareFunc = errors.New("comparison not configured")
+)
+
+const (
+	// maxFinished bounds how many promoted/canceled tournaments are kept.
+	maxFinished = 100
+	// compareTimeout bounds a whole comparison, including test commands.
+	compareTimeout = 30 * time.Minute
+	// disposeTimeout bounds disposing one losing candidate.
+	disposeTimeout = 5 * time.Minute
+)
+
+// Evaluation is what comparison learns about one candidate.
+type Evaluation struct {
+	Files        []string
+	LinesAdded   int
+	LinesRemoved int
+	Test         *contracts.TournamentTestResult
+	// Patch is the candidate's diff. It is shown to the judge but not
+	// persisted.
+	Patch string
+}
+
+// CompareFunc evaluates one candidate's workspace. testCommand is empty when
+// the tournament has no tests.
+type CompareFunc func(ctx context.Context, c contracts.TournamentCandidate, testCommand []string) (Evaluation, error)
+
+// JudgeFunc asks target to rank candidates described by prompt.
+type JudgeFunc func(ctx context.Context, target, prompt string) (JudgeResult, error)
+
+// DisposeFunc tears down a losing candidate's session and workspace.
+type DisposeFunc func(ctx context.Context, c contracts.TournamentCandidate) error
+
+// Runner tracks tournaments and compares them once their candidates finish.
+// It implements events.EventHandler and is registered for "status".
+type Runner struct {
+	mu          sync.Mutex
+	path        string
+	tournaments map[string]*contracts.Tournament
+	sessions    map[string]string // sessionID -> tournamentID
+
+	compare  CompareFunc
+	judge    JudgeFunc
+	dispose  DisposeFunc
+	onChange func()
+	logger   *log.Logger
+	wg       sync.WaitGroup
+}
+
+// NewRunner creates a runner persisting tournaments to path. Comparisons cut
+// short by a previous daemon are put back to running so they can be re-run.
+func NewRunner(path string, logger *log.Logger) *Runner {
+	if logger == nil {
+		logger = log.NewWithOptions(io.Discard, log.Options{})
+	}
+	r := &Runner{
+		path:        path,
+		tournaments: make(map[string]*contracts.Tournament),
+		sessions:    make(map[string]string),
+		logger:      logger,
+	}
+	r.load()
+	return r
+}
+
+// SetCompareFunc sets the function used to evaluate candidates.
+func (r *Runner) SetCompareFunc(fn CompareFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.compare = fn
+}
+
+// SetJudgeFunc sets the function used to rank candidates.
+func (r *Runner) SetJudgeFunc(fn JudgeFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.judge = fn
+}
+
+// SetDisposeFunc sets the function used to dispose losing candidates.
+func (r *Runner) SetDisposeFunc(fn DisposeFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dispose = fn
+}
+
+// SetChangeCallback sets a callback invoked after any tournament changes.
+func (r *Runner) SetChangeCallback(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onChange = fn
+}
+
+func (r *Runner) load() {
+	if r.path == "" {
+		return
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			r.logger.Warn("failed to read tournaments", "err", err)
+		}
+		return
+	}
+	var list []contracts.Tournament
+	if err := json.Unmarshal(data, &list); err != nil {
+		r.logger.Warn("failed to parse tournaments", "err", err)
+		return
+	}
+	for i := range list {
+		t := &list[i]
+		if t.Status == contracts.TournamentComparing {
+			t.Status = contracts.TournamentRunning
+		}
+		for _, c := range t.Candidates {
+			r.sessions[c.SessionID] = t.ID
+		}
+		r.tournaments[t.ID] = t
+	}
+}
+
+// saveLocked persists all tournaments. Caller must hold r.mu.
+func (r *Runner) saveLocked() {
+	if r.path == "" {
+		return
+	}
+	r.pruneLocked()
+	list := make([]contracts.Tournament, 0, len(r.tournaments))
+	for _, t := range r.tournaments {
+		list = append(list, *t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		r.logger.Warn("failed to marshal tournaments", "err", err)
+		return
+	}
+	if err := fileutil.AtomicWriteFile(r.path, data, 0600); err != nil {
+		r.logger.Warn("failed to save tournaments", "err", err)
+	}
+}
+
+// pruneLocked drops the oldest finished tournaments beyond maxFinished.
+func (r *Runner) pruneLocked() {
+	var finished []*contracts.Tournament
+	for _, t := range r.tournaments {
+		if isFinished(t.Status) {
+			finished = append(finished, t)
+		}
+	}
+	if len(finished) <= maxFinished {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].CreatedAt.Before(finished[j].CreatedAt) })
+	for _, t := range finished[:len(finished)-maxFinished] {
+		for _, c := range t.Candidates {
+			delete(r.sessions, c.SessionID)
+		}
+		delete(r.tournaments, t.ID)
+	}
+}
+
+func generateID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("tn-%x", time.Now().UnixNano())
+	}
+	return "tn-" + hex.EncodeToString(b)
+}
+
+// Create starts tracking the candidates spawned for req. Candidates with no
+// State are assumed running; if all of them already finished, comparison
+// starts immediately.
+func (r *Runner) Create(req contracts.SpawnRequest, candidates []contracts.TournamentCandidate) (contracts.Tournament, error) {
+	if len(candidates) == 0 {
+		return contracts.Tournament{}, ErrNoCandidates
+	}
+	t := &contracts.Tournament{
+		ID:         generateID(),
+		Repo:       req.Repo,
+		Branch:     req.Branch,
+		Prompt:     req.Prompt,
+		Status:     contracts.TournamentRunning,
+		Candidates: append([]contracts.TournamentCandidate(nil), candidates...),
+		CreatedAt:  time.Now().UTC(),
+	}
+	if req.Tournament != nil {
+		t.Options = *req.Tournament
+	}
+	for i := range t.Candidates {
+		if t.Candidates[i].State == "" {
+			t.Candidates[i].State = contracts.TournamentCandidateRunning
+		}
+	}
+
+	r.mu.Lock()
+	r.tournaments[t.ID] = t
+	for _, c := range t.Candidates {
+		r.sessions[c.SessionID] = t.ID
+	}
+	if allFinished(t) {
+		r.startCompareLocked(t)
+	}
+	r.saveLocked()
+	snapshot := cloneTournament(t)
+	r.mu.Unlock()
+	r.logger.Info("tournament started", "id", t.ID, "candidates", len(t.Candidates))
+	r.notify()
+	return snapshot, nil
+}
+
+// Compare starts a comparison now, without waiting for every candidate to
+// finish. A ready tournament is compared again.
+func (r *Runner) Compare(id string) (contracts.Tournament, error) {
+	r.mu.Lock()
+	t, err := r.liveLocked(id)
+	if err != nil {
+		r.mu.Unlock()
+		return contracts.Tournament{}, err
+	}
+	r.startCompareLocked(t)
+	r.saveLocked()
+	snapshot := cloneTournament(t)
+	r.mu.Unlock()
+	r.notify()
+	return snapshot, nil
+}
+
+// Promote declares sessionID the winner, or the judge's pick when sessionID
+// is empty, and disposes every other candidate in the background.
+func (r *Runner) Promote(id, sessionID string) (contracts.Tournament, error) {
+	r.mu.Lock()
+	t, err := r.liveLocked(id)
+	if err != nil {
+		r.mu.Unlock()
+		return contracts.Tournament{}, err
+	}
+	if sessionID == "" && t.Judgement != nil {
+		sessionID = t.Judgement.WinnerSessionID
+	}
+	if sessionID == "" {
+		r.mu.Unlock()
+		return contracts.Tournament{}, ErrNoWinner
+	}
+	winner := candidateIndex(t, sessionID)
+	if winner < 0 || t.Candidates[winner].State == contracts.TournamentCandidateDisposed {
+		r.mu.Unlock()
+		return contracts.Tournament{}, fmt.Errorf("%w: %s", ErrNotCandidate, sessionID)
+	}
+	now := time.Now().UTC()
+	t.Status = contracts.TournamentPromoted
+	t.WinnerSessionID = sessionID
+	t.FinishedAt = &now
+
+	var losers []contracts.TournamentCandidate
+	for _, c := range t.Candidates {
+		if c.SessionID != sessionID && c.State != contracts.TournamentCandidateDisposed &&
+			c.WorkspaceID != t.Candidates[winner].WorkspaceID {
+			losers = append(losers, c)
+		}
+	}
+	dispose := r.dispose
+	if dispose != nil && len(losers) > 0 {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.disposeLosers(id, losers, dispose)
+		}()
+	}
+	r.saveLocked()
+	snapshot := cloneTournament(t)
+	r.mu.Unlock()
+	r.logger.Info("tournament promoted", "id", id, "winner", sessionID, "losers", len(losers))
+	r.notify()
+	return snapshot, nil
+}
+
+// Cancel stops tracking a tournament. Every candidate keeps running.
+func (r *Runner) Cancel(id string) error {
+	r.mu.Lock()
+	t, err := r.liveLocked(id)
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	now := time.Now().UTC()
+	t.Status = contracts.TournamentCanceled
+	t.FinishedAt = &now
+	r.saveLocked()
+	r.mu.Unlock()
+	r.notify()
+	return nil
+}
+
+// List returns all tracked tournaments, newest first.
+func (r *Runner) List() []contracts.Tournament {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]contracts.Tournament, 0, len(r.tournaments))
+	for _, t := range r.tournaments {
+		out = append(out, cloneTournament(t))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+// Get returns a tournament by ID.
+func (r *Runner) Get(id string) (contracts.Tournament, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tournaments[id]
+	if !ok {
+		return contracts.Tournament{}, false
+	}
+	return cloneTournament(t), true
+}
+
+// SessionInfo reports the tournament a session competes in, if any.
+func (r *Runner) SessionInfo(sessionID string) (contracts.SessionTournamentInfo, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tournaments[r.sessions[sessionID]]
+	if !ok {
+		return contracts.SessionTournamentInfo{}, false
+	}
+	idx := candidateIndex(t, sessionID)
+	if idx < 0 {
+		return contracts.SessionTournamentInfo{}, false
+	}
+	return contracts.SessionTournamentInfo{
+		TournamentID: t.ID,
+		Status:       t.Status,
+		Rank:         t.Candidates[idx].Rank,
+		Candidates:   len(t.Candidates),
+		Winner:       t.WinnerSessionID == sessionID,
+	}, true
+}
+
+// Wait blocks until in-flight comparisons and disposals return.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+// HandleEvent implements events.EventHandler. Candidate statuses are
+// recorded, and the tournament is compared once none is still running.
+func (r *Runner) HandleEvent(_ context.Context, sessionID string, raw events.RawEvent, data []byte) {
+	if raw.Type != "status" {
+		return
+	}
+	var evt events.StatusEvent
+	if err := json.Unmarshal(data, &evt); err != nil {
+		return
+	}
+	r.mu.Lock()
+	t, ok := r.tournaments[r.sessions[sessionID]]
+	if !ok || t.Status != contracts.TournamentRunning {
+		r.mu.Unlock()
+		return
+	}
+	idx := candidateIndex(t, sessionID)
+	if idx < 0 {
+		r.mu.Unlock()
+		return
+	}
+	state := CandidateState(evt.State)
+	c := &t.Candidates[idx]
+	if c.State == state || c.State == contracts.TournamentCandidateDisposed {
+		r.mu.Unlock()
+		return
+	}
+	c.State = state
+	if allFinished(t) {
+		r.startCompareLocked(t)
+	}
+	r.saveLocked()
+	r.mu.Unlock()
+	r.notify()
+}
+
+// CandidateState maps an agent status to a candidate state. Anything other
+// than completed or error counts as still running.
+func CandidateState(status string) contracts.TournamentCandidateState {
+	switch status {
+	case "completed":
+		return contracts.TournamentCandidateCompleted
+	case "error":
+		return contracts.TournamentCandidateError
+	default:
+		return contracts.TournamentCandidateRunning
+	}
+}
+
+// liveLocked returns a tournament that can still be compared, promoted or
+// canceled.
+func (r *Runner) liveLocked(id string) (*contracts.Tournament, error) {
+	t, ok := r.tournaments[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if isFinished(t.Status) {
+		return nil, fmt.Errorf("%w: %s", ErrFinished, id)
+	}
+	if t.Status == contracts.TournamentComparing {
+		return nil, fmt.Errorf("%w: %s", ErrComparing, id)
+	}
+	return t, nil
+}
+
+// startCompareLocked marks t comparing and evaluates it in the background.
+func (r *Runner) startCompareLocked(t *contracts.Tournament) {
+	t.Status = contracts.TournamentComparing
+	snapshot := cloneTournament(t)
+	compare, judge := r.compare, r.judge
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.evaluate(snapshot, compare, judge)
+	}()
+}
+
+// evaluate compares every live candidate of t, asks the judge when one is
+// configured, and records the results.
+func (r *Runner) evaluate(t contracts.Tournament, compare CompareFunc, judge JudgeFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), compareTimeout)
+	defer cancel()
+
+	evals := make(map[string]Evaluation)
+	errs := make(map[string]string)
+	var judged []contracts.TournamentCandidate
+	for _, c := range t.Candidates {
+		if c.State == contracts.TournamentCandidateDisposed {
+			continue
+		}
+		if compare == nil {
+			errs[c.SessionID] = errNoCompareFunc.Error()
+			continue
+		}
+		ev, err := compare(ctx, c, t.Options.TestCommand)
+		if err != nil {
+			errs[c.SessionID] = err.Error()
+			r.logger.Warn("tournament candidate comparison failed", "id", t.ID, "session", c.SessionID, "err", err)
+			continue
+		}
+		evals[c.SessionID] = ev
+		judged = append(judged, c)
+	}
+
+	var judgement *contracts.TournamentJudgement
+	ranks := make(map[string]JudgeRanking)
+	if t.Options.JudgeTarget != "" && len(judged) > 1 {
+		judgement = &contracts.TournamentJudgement{Target: t.Options.JudgeTarget}
+		if judge == nil {
+			judgement.Error = "judging not configured"
+		} else {
+			prompt, labels := buildJudgePrompt(t, judged, evals)
+			result, err := judge(ctx, t.Options.JudgeTarget, prompt)
+			if err != nil {
+				judgement.Error = err.Error()
+				r.logger.Warn("tournament judge failed", "id", t.ID, "target", t.Options.JudgeTarget, "err", err)
+			} else {
+				judgement.Summary = result.Summary
+				best := 0
+				for _, rk := range result.Ranking {
+					sessionID, ok := labels[rk.Candidate]
+					if !ok || rk.Rank < 1 {
+						continue
+					}
+					ranks[sessionID] = rk
+					if best == 0 || rk.Rank < best {
+						best = rk.Rank
+						judgement.WinnerSessionID = sessionID
+					}
+				}
+			}
+		}
+	}
+
+	r.mu.Lock()
+	live, ok := r.tournaments[t.ID]
+	if !ok || live.Status != contracts.TournamentComparing {
+		// Canceled while comparing.
+		r.mu.Unlock()
+		return
+	}
+	for i := range live.Candidates {
+		c := &live.Candidates[i]
+		c.Error = errs[c.SessionID]
+		if ev, ok := evals[c.SessionID]; ok {
+			c.Files = ev.Files
+			c.LinesAdded = ev.LinesAdded
+			c.LinesRemoved = ev.LinesRemoved
+			c.Test = ev.Test
+		}
+		c.Rank = ranks[c.SessionID].Rank
+		c.Rationale = ranks[c.SessionID].Rationale
+	}
+	now := time.Now().UTC()
+	live.Judgement = judgement
+	live.Status = contracts.TournamentReady
+	live.ComparedAt = &now
+	r.saveLocked()
+	r.mu.Unlock()
+	r.logger.Info("tournament compared", "id", t.ID)
+	r.notify()
+}
+
+// disposeLosers disposes each losing candidate and records the outcome.
+func (r *Runner) disposeLosers(id string, losers []contracts.TournamentCandidate, dispose DisposeFunc) {
+	for _, c := range losers {
+		ctx, cancel := context.WithTimeout(context.Background(), disposeTimeout)
+		err := dispose(ctx, c)
+		cancel()
+		if err != nil {
+			r.logger.Warn("failed to dispose tournament candidate", "id", id, "session", c.SessionID, "err", err)
+		}
+
+		r.mu.Lock()
+		if t, ok := r.tournaments[id]; ok {
+			if idx := candidateIndex(t, c.SessionID); idx >= 0 {
+				if err != nil {
+					t.Candidates[idx].Error = "dispose failed: " + err.Error()
+				} else {
+					t.Candidates[idx].State = contracts.TournamentCandidateDisposed
+				}
+				r.saveLocked()
+			}
+		}
+		r.mu.Unlock()
+		r.notify()
+	}
+}
+
+func (r *Runner) notify() {
+	r.mu.Lock()
+	fn := r.onChange
+	r.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+func isFinished(s contracts.TournamentStatus) bool {
+	return s == contracts.TournamentPromoted || s == contracts.TournamentCanceled
+}
+
+// allFinished reports whether no candidate of t is still running.
+func allFinished(t *contracts.Tournament) bool {
+	for _, c := range t.Candidates {
+		if c.State == contracts.TournamentCandidateRunning {
+			return false
+		}
+	}
+	return true
+}
+
+func candidateIndex(t *contracts.Tournament, sessionID string) int {
+	for i := range t.Candidates {
+		if t.Candidates[i].SessionID == sessionID {
+			return i
+		}
+	}
+	return -1
+}
+
+// cloneTournament deep-copies the mutable parts of a tournament for callers.
+func cloneTournament(t *contracts.Tournament) contracts.Tournament {
+	out := *t
+	out.Options.TestCommand = append([]string(nil), t.Options.TestCommand...)
+	out.Candidates = make([]contracts.TournamentCandidate, len(t.Candidates))
+	for i, c := range t.Candidates {
+		c.Files = append([]string(nil), c.Files...)
+		if c.Test != nil {
+			test := *c.Test
+			c.Test = &test
+		}
+		out.Candidates[i] = c
+	}
+	if t.Judgement != nil {
+		j := *t.Judgement
+		out.Judgement = &j
+	}
+	return out
+}
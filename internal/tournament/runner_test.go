@@ -0,0 +1,313 @@
+package tournament
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/events"
+)
+
+// fakeWorld records compare, judge and dispose calls.
+type fakeWorld struct {
+	mu          sync.Mutex
+	compared    []string
+	prompts     []string
+	disposed    []string
+	ranking     []JudgeRanking
+	judgeErr    error
+	failCompare map[string]bool
+}
+
+func (f *fakeWorld) compare(_ context.Context, c contracts.TournamentCandidate, testCommand []string) (Evaluation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.compared = append(f.compared, c.SessionID)
+	if f.failCompare[c.SessionID] {
+		return Evaluation{}, errors.New("workspace gone")
+	}
+	ev := Evaluation{Files: []string{c.SessionID + ".go"}, LinesAdded: 10, Patch: "+" + c.SessionID}
+	if len(testCommand) > 0 {
+		ev.Test = &contracts.TournamentTestResult{Passed: c.SessionID != "s-2"}
+	}
+	return ev, nil
+}
+
+func (f *fakeWorld) judge(_ context.Context, _ string, prompt string) (JudgeResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prompts = append(f.prompts, prompt)
+	if f.judgeErr != nil {
+		return JudgeResult{}, f.judgeErr
+	}
+	return JudgeResult{Ranking: f.ranking, Summary: "B is cleaner"}, nil
+}
+
+func (f *fakeWorld) dispose(_ context.Context, c contracts.TournamentCandidate) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.disposed = append(f.disposed, c.SessionID)
+	return nil
+}
+
+func newTestRunner(t *testing.T) (*Runner, *fakeWorld) {
+	t.Helper()
+	f := &fakeWorld{}
+	r := NewRunner(filepath.Join(t.TempDir(), "tournaments.json"), nil)
+	r.SetCompareFunc(f.compare)
+	r.SetJudgeFunc(f.judge)
+	r.SetDisposeFunc(f.dispose)
+	return r, f
+}
+
+func status(r *Runner, sessionID, state string) {
+	data := []byte(fmt.Sprintf(`{"ts":"2026-01-01T00:00:00Z","type":"status","state":%q}`, state))
+	r.HandleEvent(context.Background(), sessionID, events.RawEvent{Type: "status"}, data)
+	r.Wait()
+}
+
+func candidates(n int) []contracts.TournamentCandidate {
+	var out []contracts.TournamentCandidate
+	for i := 1; i <= n; i++ {
+		out = append(out, contracts.TournamentCandidate{
+			SessionID:   fmt.Sprintf("s-%d", i),
+			WorkspaceID: fmt.Sprintf("ws-%d", i),
+			Target:      "claude",
+		})
+	}
+	return out
+}
+
+func spawnReq(opts *contracts.TournamentOptions) contracts.SpawnRequest {
+	return contracts.SpawnRequest{Repo: "https://github.com/user/repo.git", Prompt: "fix the bug", Tournament: opts}
+}
+
+func mustGet(t *testing.T, r *Runner, id string) contracts.Tournament {
+	t.Helper()
+	tn, ok := r.Get(id)
+	if !ok {
+		t.Fatalf("tournament %s not found", id)
+	}
+	return tn
+}
+
+func TestRunner_ComparesWhenAllFinish(t *testing.T) {
+	r, f := newTestRunner(t)
+	f.ranking = []JudgeRanking{
+		{Candidate: "A", Rank: 2, Rationale: "fails tests"},
+		{Candidate: "B", Rank: 1, Rationale: "clean"},
+		{Candidate: "Z", Rank: 1, Rationale: "hallucinated"},
+	}
+	tn, err := r.Create(spawnReq(&contracts.TournamentOptions{TestCommand: []string{"go", "test"}, JudgeTarget: "judge"}), candidates(2))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !strings.HasPrefix(tn.ID, "tn-") || tn.Status != contracts.TournamentRunning {
+		t.Fatalf("unexpected tournament: %+v", tn)
+	}
+
+	status(r, "s-1", "completed")
+	if got := mustGet(t, r, tn.ID).Status; got != contracts.TournamentRunning {
+		t.Fatalf("status after one finish = %s, want running", got)
+	}
+	status(r, "s-2", "error")
+
+	got := mustGet(t, r, tn.ID)
+	if got.Status != contracts.TournamentReady || got.ComparedAt == nil {
+		t.Fatalf("status = %s, want ready", got.Status)
+	}
+	if got.Candidates[1].State != contracts.TournamentCandidateError {
+		t.Errorf("s-2 state = %s, want error", got.Candidates[1].State)
+	}
+	if got.Candidates[0].Test == nil || !got.Candidates[0].Test.Passed || got.Candidates[1].Test.Passed {
+		t.Errorf("unexpected test results: %+v / %+v", got.Candidates[0].Test, got.Candidates[1].Test)
+	}
+	if got.Candidates[0].LinesAdded != 10 || len(got.Candidates[0].Files) != 1 {
+		t.Errorf("unexpected diff stats: %+v", got.Candidates[0])
+	}
+	if got.Judgement == nil || got.Judgement.WinnerSessionID != "s-2" || got.Judgement.Summary != "B is cleaner" {
+		t.Fatalf("unexpected judgement: %+v", got.Judgement)
+	}
+	if got.Candidates[0].Rank != 2 || got.Candidates[1].Rank != 1 || got.Candidates[1].Rationale != "clean" {
+		t.Errorf("unexpected ranks: %+v", got.Candidates)
+	}
+	if len(f.prompts) != 1 || !strings.Contains(f.prompts[0], "=== Candidate B ===") || strings.Contains(f.prompts[0], "claude") {
+		t.Errorf("unexpected judge prompt:\n%s", f.prompts)
+	}
+
+	info, ok := r.SessionInfo("s-2")
+	if !ok || info.TournamentID != tn.ID || info.Rank != 1 || info.Candidates != 2 {
+		t.Errorf("SessionInfo() = %+v, %v", info, ok)
+	}
+}
+
+func TestRunner_WorkingResetsCandidate(t *testing.T) {
+	r, _ := newTestRunner(t)
+	tn, _ := r.Create(spawnReq(nil), candidates(2))
+	status(r, "s-1", "completed")
+	status(r, "s-1", "working")
+	status(r, "s-2", "completed")
+	if got := mustGet(t, r, tn.ID).Status; got != contracts.TournamentRunning {
+		t.Errorf("status = %s, want running while s-1 works again", got)
+	}
+}
+
+func TestRunner_CreateWithFinishedCandidates(t *testing.T) {
+	r, f := newTestRunner(t)
+	cands := candidates(2)
+	cands[0].State = contracts.TournamentCandidateCompleted
+	cands[1].State = contracts.TournamentCandidateCompleted
+	tn, _ := r.Create(spawnReq(nil), cands)
+	r.Wait()
+	got := mustGet(t, r, tn.ID)
+	if got.Status != contracts.TournamentReady {
+		t.Errorf("status = %s, want ready", got.Status)
+	}
+	if got.Judgement != nil || len(f.prompts) != 0 {
+		t.Error("judge should not run without a judge target")
+	}
+}
+
+func TestRunner_CompareErrors(t *testing.T) {
+	r, f := newTestRunner(t)
+	f.failCompare = map[string]bool{"s-1": true}
+	f.judgeErr = errors.New("judge down")
+	tn, _ := r.Create(spawnReq(&contracts.TournamentOptions{JudgeTarget: "judge"}), candidates(3))
+
+	// Forcing a comparison does not wait for candidates.
+	if _, err := r.Compare(tn.ID); err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	r.Wait()
+	got := mustGet(t, r, tn.ID)
+	if got.Candidates[0].Error != "workspace gone" {
+		t.Errorf("s-1 error = %q", got.Candidates[0].Error)
+	}
+	if got.Judgement == nil || got.Judgement.Error != "judge down" || got.Judgement.WinnerSessionID != "" {
+		t.Errorf("unexpected judgement: %+v", got.Judgement)
+	}
+	if _, err := r.Promote(tn.ID, ""); !errors.Is(err, ErrNoWinner) {
+		t.Errorf("Promote() without a pick error = %v, want ErrNoWinner", err)
+	}
+}
+
+func TestRunner_Promote(t *testing.T) {
+	r, f := newTestRunner(t)
+	f.ranking = []JudgeRanking{{Candidate: "A", Rank: 1}, {Candidate: "B", Rank: 2}, {Candidate: "C", Rank: 3}}
+	tn, _ := r.Create(spawnReq(&contracts.TournamentOptions{JudgeTarget: "judge"}), candidates(3))
+	for _, id := range []string{"s-1", "s-2", "s-3"} {
+		status(r, id, "completed")
+	}
+
+	if _, err := r.Promote(tn.ID, "s-9"); !errors.Is(err, ErrNotCandidate) {
+		t.Errorf("Promote(unknown) error = %v, want ErrNotCandidate", err)
+	}
+	got, err := r.Promote(tn.ID, "")
+	if err != nil {
+		t.Fatalf("Promote() error = %v", err)
+	}
+	if got.Status != contracts.TournamentPromoted || got.WinnerSessionID != "s-1" || got.FinishedAt == nil {
+		t.Fatalf("unexpected promoted tournament: %+v", got)
+	}
+	r.Wait()
+	if len(f.disposed) != 2 || f.disposed[0] != "s-2" || f.disposed[1] != "s-3" {
+		t.Errorf("disposed = %v, want [s-2 s-3]", f.disposed)
+	}
+	got = mustGet(t, r, tn.ID)
+	if got.Candidates[0].State == contracts.TournamentCandidateDisposed || got.Candidates[2].State != contracts.TournamentCandidateDisposed {
+		t.Errorf("unexpected candidate states: %+v", got.Candidates)
+	}
+	if info, _ := r.SessionInfo("s-1"); !info.Winner {
+		t.Error("SessionInfo(s-1) should report the winner")
+	}
+	if _, err := r.Promote(tn.ID, "s-2"); !errors.Is(err, ErrFinished) {
+		t.Errorf("second Promote() error = %v, want ErrFinished", err)
+	}
+}
+
+func TestRunner_Cancel(t *testing.T) {
+	r, f := newTestRunner(t)
+	tn, _ := r.Create(spawnReq(nil), candidates(2))
+	if err := r.Cancel(tn.ID); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	status(r, "s-1", "completed")
+	status(r, "s-2", "completed")
+	if got := mustGet(t, r, tn.ID).Status; got != contracts.TournamentCanceled {
+		t.Errorf("status = %s, want canceled", got)
+	}
+	if len(f.compared) != 0 || len(f.disposed) != 0 {
+		t.Error("canceled tournament should not compare or dispose")
+	}
+	if err := r.Cancel(tn.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("second Cancel() error = %v, want ErrFinished", err)
+	}
+	if err := r.Cancel("tn-missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRunner_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tournaments.json")
+	r := NewRunner(path, nil)
+	tn, err := r.Create(spawnReq(&contracts.TournamentOptions{Criteria: "small diffs"}), candidates(2))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	r.mu.Lock()
+	r.tournaments[tn.ID].Status = contracts.TournamentComparing
+	r.saveLocked()
+	r.mu.Unlock()
+
+	loaded := NewRunner(path, nil)
+	got := mustGet(t, loaded, tn.ID)
+	if got.Status != contracts.TournamentRunning {
+		t.Errorf("interrupted comparison status = %s, want running", got.Status)
+	}
+	if got.Options.Criteria != "small diffs" || len(got.Candidates) != 2 {
+		t.Errorf("unexpected loaded tournament: %+v", got)
+	}
+	if _, ok := loaded.SessionInfo("s-2"); !ok {
+		t.Error("session index not rebuilt on load")
+	}
+	if len(loaded.List()) != 1 {
+		t.Errorf("List() = %d tournaments, want 1", len(loaded.List()))
+	}
+}
+
+func TestRunner_CreateNoCandidates(t *testing.T) {
+	r, _ := newTestRunner(t)
+	if _, err := r.Create(spawnReq(nil), nil); !errors.Is(err, ErrNoCandidates) {
+		t.Errorf("Create() error = %v, want ErrNoCandidates", err)
+	}
+}
+
+func TestRunTestCommand(t *testing.T) {
+	dir := t.TempDir()
+	ok := RunTestCommand(context.Background(), dir, []string{"sh", "-c", "echo fine"})
+	if !ok.Passed || ok.ExitCode != 0 || !strings.Contains(ok.Output, "fine") {
+		t.Errorf("passing command = %+v", ok)
+	}
+	bad := RunTestCommand(context.Background(), dir, []string{"sh", "-c", "echo broken >&2; exit 3"})
+	if bad.Passed || bad.ExitCode != 3 || !strings.Contains(bad.Output, "broken") {
+		t.Errorf("failing command = %+v", bad)
+	}
+	missing := RunTestCommand(context.Background(), dir, []string{"definitely-not-a-binary-xyz"})
+	if missing.Passed || missing.ExitCode != -1 {
+		t.Errorf("missing command = %+v", missing)
+	}
+}
+
+func TestTail(t *testing.T) {
+	if got := tail("abcdef", 3); got != "...def" {
+		t.Errorf("tail() = %q", got)
+	}
+	if got := tail("abc", 3); got != "abc" {
+		t.Errorf("tail() = %q", got)
+	}
+}
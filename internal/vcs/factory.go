@@ -6,6 +6,8 @@ func NewCommandBuilder(vcsType string) CommandBuilder {
 	switch vcsType {
 	case "sapling":
 		return &SaplingCommandBuilder{}
+	case "jj":
+		return &JJCommandBuilder{}
 	default:
 		return &GitCommandBuilder{}
 	}
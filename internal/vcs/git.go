@@ -71,6 +71,10 @@ func (g *GitCommandBuilder) LogRange(refs []string, forkPoint string) string {
 	return strings.Join(args, " ")
 }
 
+func (g *GitCommandBuilder) LogOneline(rangeSpec string) string {
+	return fmt.Sprintf("git log --oneline %s", shellutil.Quote(rangeSpec))
+}
+
 func (g *GitCommandBuilder) ResolveRef(ref string) string {
 	return fmt.Sprintf("git rev-parse --verify %s", shellutil.Quote(ref))
 }
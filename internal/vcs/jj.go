@@ -0,0 +1,211 @@
+package vcs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sergeknystautas/schmux/pkg/shellutil"
+)
+
+// jjLogTemplate renders the same hash|short|subject|author|timestamp|parents
+// format as the git and sapling builders. The root commit's all-zero id is
+// filtered out of parents by the graph parser, like sapling's null hash.
+const jjLogTemplate = `commit_id ++ "|" ++ commit_id.short() ++ "|" ++ description.first_line() ++ "|" ++ author.name() ++ "|" ++ author.timestamp().format("%Y-%m-%dT%H:%M:%S%:z") ++ "|" ++ parents.map(|c| c.commit_id()).join(" ") ++ "\n"`
+
+// jjBookmarkNamesTemplate lists a commit's bookmark names, one per line.
+const jjBookmarkNamesTemplate = `local_bookmarks.map(|b| b.name()).join("\n")`
+
+var hexRefPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// jjRev converts a git-style ref to a jj revset. In jj the working-copy
+// commit "@" holds uncommitted changes, so git's HEAD is its parent "@-".
+// "origin/<b>" becomes the remote bookmark "<b>"@origin, "X^" becomes "X-",
+// and commit hashes and expressions that are already revsets pass through.
+// Any other name is quoted so bookmarks containing "/" or "-" parse as
+// symbols rather than operators.
+func jjRev(ref string) string {
+	switch {
+	case ref == "" || ref == "HEAD":
+		return "@-"
+	case strings.HasSuffix(ref, "^"):
+		return jjRev(strings.TrimSuffix(ref, "^")) + "-"
+	case hexRefPattern.MatchString(ref), strings.ContainsAny(ref, `"()@`):
+		return ref
+	case strings.HasPrefix(ref, "origin/"):
+		return strconv.Quote(strings.TrimPrefix(ref, "origin/")) + "@origin"
+	default:
+		return strconv.Quote(ref)
+	}
+}
+
+// jjRange converts git-style "A..B" (or a single ref) to a jj revset.
+func jjRange(rangeSpec string) string {
+	parts := strings.Split(rangeSpec, "..")
+	if len(parts) != 2 {
+		return jjRev(rangeSpec)
+	}
+	return jjRev(parts[0]) + ".." + jjRev(parts[1])
+}
+
+// jjRevs joins refs into a single revset union.
+func jjRevs(refs []string) string {
+	if len(refs) == 0 {
+		return "@-"
+	}
+	revs := make([]string, len(refs))
+	for i, ref := range refs {
+		revs[i] = jjRev(ref)
+	}
+	return strings.Join(revs, " | ")
+}
+
+// jjFile returns a fileset matching exactly one path relative to the
+// workspace root, so paths with glob or fileset operators are taken
+// literally.
+func jjFile(path string) string {
+	return shellutil.Quote("root-file:" + strconv.Quote(path))
+}
+
+// JJCommandBuilder implements CommandBuilder for Jujutsu (jj). The diff
+// commands compare the working-copy commit "@" against its parent, which is
+// jj's equivalent of git's working tree against HEAD. jj snapshots the
+// working copy on every command and tracks new files automatically, so there
+// are no untracked files and nothing to stage.
+type JJCommandBuilder struct{}
+
+func (j *JJCommandBuilder) DiffNumstat() string {
+	// jj has no --numstat; git apply computes it from jj's git-format diff
+	// without needing a git repository.
+	return "jj diff --no-pager --color never --git | git apply --numstat 2>/dev/null"
+}
+
+func (j *JJCommandBuilder) DiffNameStatus() string {
+	// jj diff --summary prints "X path" with M/A/D/R/C letters; renames and
+	// copies use "R dir/{old => new}". Re-emit as tab-separated like sapling.
+	return `jj diff --no-pager --color never --summary | while IFS= read -r l; do printf '%s\t%s\n' "${l%% *}" "${l#* }"; done`
+}
+
+func (j *JJCommandBuilder) ShowFile(path, revision string) string {
+	return fmt.Sprintf("jj file show --no-pager -r %s %s | head -2000", shellutil.Quote(jjRev(revision)), jjFile(path))
+}
+
+func (j *JJCommandBuilder) FileContent(path string) string {
+	return fmt.Sprintf("head -2000 %s", shellutil.Quote(path))
+}
+
+func (j *JJCommandBuilder) UntrackedFiles() string {
+	// New files are part of the working-copy commit as soon as jj sees them.
+	return "true"
+}
+
+func (j *JJCommandBuilder) Log(refs []string, maxCount int) string {
+	revset := fmt.Sprintf("::(%s) ~ root()", jjRevs(refs))
+	return fmt.Sprintf("jj log --no-pager --color never --no-graph -r %s --limit %d -T %s", shellutil.Quote(revset), maxCount, shellutil.Quote(jjLogTemplate))
+}
+
+func (j *JJCommandBuilder) LogParseable(refs []string, maxCount int) string {
+	// Like sapling, jj templates are pipe-delimited for both local and remote
+	// use; ParseGitLogOutput's auto-detect handles it.
+	return j.Log(refs, maxCount)
+}
+
+func (j *JJCommandBuilder) LogRange(refs []string, forkPoint string) string {
+	revset := fmt.Sprintf("(%s)-..(%s)", jjRev(forkPoint), jjRevs(refs))
+	return fmt.Sprintf("jj log --no-pager --color never --no-graph -r %s --limit 5000 -T %s", shellutil.Quote(revset), shellutil.Quote(jjLogTemplate))
+}
+
+func (j *JJCommandBuilder) LogOneline(rangeSpec string) string {
+	return fmt.Sprintf(`jj log --no-pager --color never --no-graph -r %s -T 'commit_id.short() ++ " " ++ description.first_line() ++ "\n"'`, shellutil.Quote(jjRange(rangeSpec)))
+}
+
+func (j *JJCommandBuilder) ResolveRef(ref string) string {
+	return fmt.Sprintf("jj log --no-pager --color never --no-graph -r %s --limit 1 -T commit_id", shellutil.Quote(jjRev(ref)))
+}
+
+func (j *JJCommandBuilder) MergeBase(ref1, ref2 string) string {
+	revset := fmt.Sprintf("heads(::%s & ::%s)", jjRev(ref1), jjRev(ref2))
+	return fmt.Sprintf("jj log --no-pager --color never --no-graph -r %s --limit 1 -T commit_id", shellutil.Quote(revset))
+}
+
+func (j *JJCommandBuilder) DefaultBranchRef(branch string) string {
+	return strconv.Quote(branch) + "@origin"
+}
+
+func (j *JJCommandBuilder) DetectDefaultBranch() string {
+	// trunk() resolves to the remote's default bookmark (main, master or
+	// trunk on origin or upstream).
+	return fmt.Sprintf("jj log --no-pager --color never --no-graph -r 'trunk()' --limit 1 -T %s 2>/dev/null | head -1 | grep . || echo main",
+		shellutil.Quote(`remote_bookmarks.map(|b| b.name()).join("\n")`))
+}
+
+func (j *JJCommandBuilder) RevListCount(rangeSpec string) string {
+	return fmt.Sprintf(`jj log --no-pager --color never --no-graph -r %s -T '"x\n"' | wc -l`, shellutil.Quote(jjRange(rangeSpec)))
+}
+
+func (j *JJCommandBuilder) CurrentBranch() string {
+	// jj has no active bookmark; report the nearest bookmark below "@".
+	return fmt.Sprintf("jj log --no-pager --color never --no-graph -r 'heads(::@ & bookmarks())' --limit 1 -T %s | head -1", shellutil.Quote(jjBookmarkNamesTemplate))
+}
+
+func (j *JJCommandBuilder) StatusPorcelain() string {
+	return "jj diff --no-pager --color never --summary"
+}
+
+func (j *JJCommandBuilder) RemoteBranchExists(branch string) string {
+	return fmt.Sprintf("jj log --no-pager --color never --no-graph -r %s --limit 1 -T commit_id 2>/dev/null", shellutil.Quote(j.DefaultBranchRef(branch)))
+}
+
+func (j *JJCommandBuilder) NewestTimestamp(rangeSpec string) string {
+	return fmt.Sprintf(`jj log --no-pager --color never --no-graph -r %s --limit 1 -T 'author.timestamp().format("%%Y-%%m-%%dT%%H:%%M:%%S%%:z") ++ "\n"'`, shellutil.Quote(jjRange(rangeSpec)))
+}
+
+func (j *JJCommandBuilder) OldestHash(rangeSpec string) string {
+	return fmt.Sprintf(`jj log --no-pager --color never --no-graph --reversed -r %s -T 'commit_id ++ "\n"' | head -1`, shellutil.Quote(jjRange(rangeSpec)))
+}
+
+func (j *JJCommandBuilder) AddFiles(files []string) string {
+	// jj tracks new files automatically.
+	return "true"
+}
+
+func (j *JJCommandBuilder) CommitAmendNoEdit() string {
+	return "jj squash"
+}
+
+func (j *JJCommandBuilder) DiscardFile(file string) string {
+	return fmt.Sprintf("jj restore %s", jjFile(file))
+}
+
+func (j *JJCommandBuilder) DiscardAllTracked() string {
+	return "jj restore"
+}
+
+func (j *JJCommandBuilder) CleanUntrackedFile(file string) string {
+	return fmt.Sprintf("rm -f %s", shellutil.Quote(file))
+}
+
+func (j *JJCommandBuilder) CleanAllUntracked() string {
+	// DiscardAllTracked already drops new files; nothing else is untracked.
+	return "true"
+}
+
+func (j *JJCommandBuilder) UnstageNewFile(file string) string {
+	return fmt.Sprintf("jj restore %s", jjFile(file))
+}
+
+func (j *JJCommandBuilder) Uncommit() string {
+	// Fold the parent commit into the working-copy commit, keeping its
+	// changes as uncommitted work.
+	return "jj squash --from @- --into @ --use-destination-message"
+}
+
+func (j *JJCommandBuilder) CheckIgnore(file string) string {
+	// An existing file that jj does not track is ignored.
+	return fmt.Sprintf("test -e %s && test -z \"$(jj file list --no-pager %s 2>/dev/null)\"", shellutil.Quote(file), jjFile(file))
+}
+
+func (j *JJCommandBuilder) DiffUnified() string {
+	return "jj diff --no-pager --color never --git"
+}
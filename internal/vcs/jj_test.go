@@ -0,0 +1,158 @@
+package vcs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJJRev(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"HEAD", "@-"},
+		{"", "@-"},
+		{"HEAD^", "@--"},
+		{"abc1234", "abc1234"},
+		{"0123456789abcdef0123456789abcdef01234567", "0123456789abcdef0123456789abcdef01234567"},
+		{"origin/main", `"main"@origin`},
+		{"origin/feature/x", `"feature/x"@origin`},
+		{`"main"@origin`, `"main"@origin`},
+		{"main@upstream", "main@upstream"},
+		{"feature/login-fix", `"feature/login-fix"`},
+		{"feature^", `"feature"-`},
+	}
+	for _, tt := range tests {
+		if got := jjRev(tt.ref); got != tt.want {
+			t.Errorf("jjRev(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestJJRange(t *testing.T) {
+	if got, want := jjRange(`HEAD.."main"@origin`), `@-.."main"@origin`; got != want {
+		t.Errorf("jjRange() = %q, want %q", got, want)
+	}
+	if got, want := jjRange("origin/main..HEAD"), `"main"@origin..@-`; got != want {
+		t.Errorf("jjRange() = %q, want %q", got, want)
+	}
+	if got, want := jjRange("feature"), `"feature"`; got != want {
+		t.Errorf("jjRange() = %q, want %q", got, want)
+	}
+}
+
+func TestJJDiffNameStatus(t *testing.T) {
+	cb := &JJCommandBuilder{}
+	got := cb.DiffNameStatus()
+	want := `jj diff --no-pager --color never --summary | while IFS= read -r l; do printf '%s\t%s\n' "${l%% *}" "${l#* }"; done`
+	if got != want {
+		t.Errorf("DiffNameStatus() = %q, want %q", got, want)
+	}
+}
+
+func TestJJDiffNumstat(t *testing.T) {
+	cb := &JJCommandBuilder{}
+	got := cb.DiffNumstat()
+	want := "jj diff --no-pager --color never --git | git apply --numstat 2>/dev/null"
+	if got != want {
+		t.Errorf("DiffNumstat() = %q, want %q", got, want)
+	}
+}
+
+func TestJJShowFile(t *testing.T) {
+	cb := &JJCommandBuilder{}
+	got := cb.ShowFile("dir/it's here.go", "HEAD")
+	want := `jj file show --no-pager -r '@-' 'root-file:"dir/it'\''s here.go"' | head -2000`
+	if got != want {
+		t.Errorf("ShowFile() = %q, want %q", got, want)
+	}
+}
+
+func TestJJLog(t *testing.T) {
+	cb := &JJCommandBuilder{}
+	got := cb.Log([]string{"HEAD", `"main"@origin`}, 50)
+	for _, want := range []string{
+		`jj log --no-pager --color never --no-graph -r '::(@- | "main"@origin) ~ root()' --limit 50 -T `,
+		`commit_id ++ "|" ++ commit_id.short()`,
+		`parents.map(|c| c.commit_id()).join(" ")`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Log() = %q, want it to contain %q", got, want)
+		}
+	}
+	if cb.LogParseable([]string{"HEAD"}, 10) != cb.Log([]string{"HEAD"}, 10) {
+		t.Error("LogParseable() should match Log()")
+	}
+}
+
+func TestJJLogRange(t *testing.T) {
+	cb := &JJCommandBuilder{}
+	got := cb.LogRange([]string{"HEAD"}, "abc1234")
+	if !strings.Contains(got, `-r '(abc1234)-..(@-)' --limit 5000`) {
+		t.Errorf("LogRange() = %q, want a bounded range from the fork point's parents", got)
+	}
+}
+
+func TestJJRefCommands(t *testing.T) {
+	cb := &JJCommandBuilder{}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"ResolveRef", cb.ResolveRef("origin/feature"), `jj log --no-pager --color never --no-graph -r '"feature"@origin' --limit 1 -T commit_id`},
+		{"MergeBase", cb.MergeBase("HEAD", `"main"@origin`), `jj log --no-pager --color never --no-graph -r 'heads(::@- & ::"main"@origin)' --limit 1 -T commit_id`},
+		{"DefaultBranchRef", cb.DefaultBranchRef("main"), `"main"@origin`},
+		{"RevListCount", cb.RevListCount(`HEAD.."main"@origin`), `jj log --no-pager --color never --no-graph -r '@-.."main"@origin' -T '"x\n"' | wc -l`},
+		{"OldestHash", cb.OldestHash(`HEAD.."main"@origin`), `jj log --no-pager --color never --no-graph --reversed -r '@-.."main"@origin' -T 'commit_id ++ "\n"' | head -1`},
+		{"RemoteBranchExists", cb.RemoteBranchExists("feature"), `jj log --no-pager --color never --no-graph -r '"feature"@origin' --limit 1 -T commit_id 2>/dev/null`},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s() = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestJJNewestTimestamp(t *testing.T) {
+	cb := &JJCommandBuilder{}
+	got := cb.NewestTimestamp(`HEAD.."main"@origin`)
+	want := `jj log --no-pager --color never --no-graph -r '@-.."main"@origin' --limit 1 -T 'author.timestamp().format("%Y-%m-%dT%H:%M:%S%:z") ++ "\n"'`
+	if got != want {
+		t.Errorf("NewestTimestamp() = %q, want %q", got, want)
+	}
+}
+
+func TestJJDetectDefaultBranch(t *testing.T) {
+	cb := &JJCommandBuilder{}
+	got := cb.DetectDefaultBranch()
+	if !strings.Contains(got, "-r 'trunk()'") || !strings.HasSuffix(got, "|| echo main") {
+		t.Errorf("DetectDefaultBranch() = %q, want trunk() with a main fallback", got)
+	}
+}
+
+func TestJJMutations(t *testing.T) {
+	cb := &JJCommandBuilder{}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"AddFiles", cb.AddFiles([]string{"a.go"}), "true"},
+		{"CommitAmendNoEdit", cb.CommitAmendNoEdit(), "jj squash"},
+		{"DiscardFile", cb.DiscardFile("dir/file name.go"), `jj restore 'root-file:"dir/file name.go"'`},
+		{"DiscardAllTracked", cb.DiscardAllTracked(), "jj restore"},
+		{"CleanUntrackedFile", cb.CleanUntrackedFile("tmp.txt"), "rm -f 'tmp.txt'"},
+		{"CleanAllUntracked", cb.CleanAllUntracked(), "true"},
+		{"UnstageNewFile", cb.UnstageNewFile("new.go"), `jj restore 'root-file:"new.go"'`},
+		{"Uncommit", cb.Uncommit(), "jj squash --from @- --into @ --use-destination-message"},
+		{"UntrackedFiles", cb.UntrackedFiles(), "true"},
+		{"StatusPorcelain", cb.StatusPorcelain(), "jj diff --no-pager --color never --summary"},
+		{"DiffUnified", cb.DiffUnified(), "jj diff --no-pager --color never --git"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s() = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -109,6 +109,18 @@ func (s *SaplingCommandBuilder) LogRange(refs []string, forkPoint string) string
 	return fmt.Sprintf("sl log --pager never -T '{node}|{short(node)}|{desc|firstline}|{author|user}|{date|isodate}|{p1node} {p2node}\\n' -r %s", shellutil.Quote(limitedRevset))
 }
 
+func (s *SaplingCommandBuilder) LogOneline(rangeSpec string) string {
+	exclude, include := parseRangeToRevset(rangeSpec)
+	revset := rangeSpec
+	if exclude != "" {
+		if include == "HEAD" {
+			include = "."
+		}
+		revset = fmt.Sprintf("only(%s, %s)", include, exclude)
+	}
+	return fmt.Sprintf("sl log --pager never -T '{short(node)} {desc|firstline}\\n' -r %s", shellutil.Quote(revset))
+}
+
 func (s *SaplingCommandBuilder) ResolveRef(ref string) string {
 	slRef := ref
 	if ref == "HEAD" {
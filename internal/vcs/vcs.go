@@ -1,6 +1,6 @@
 // Package vcs provides a command builder abstraction for version control systems.
 // It generates shell command strings for VCS operations, allowing the same logic
-// to work across git and sapling by swapping the command builder implementation.
+// to work across git, sapling and jj by swapping the command builder implementation.
 package vcs
 
 // CommandBuilder generates shell command strings for VCS operations.
@@ -35,6 +35,9 @@ type CommandBuilder interface {
 	LogParseable(refs []string, maxCount int) string
 	// LogRange returns the command for log between forkPoint and refs.
 	LogRange(refs []string, forkPoint string) string
+	// LogOneline returns the command for a one-line-per-commit log of a
+	// range (e.g., "origin/main..HEAD"): short hash, space, subject.
+	LogOneline(rangeSpec string) string
 	// ResolveRef returns the command to resolve a ref to a hash.
 	ResolveRef(ref string) string
 	// MergeBase returns the command to find the merge base between two refs.
@@ -236,3 +236,20 @@ func TestGitDiscardFile_QuotesPath(t *testing.T) {
 		t.Errorf("expected quoted path in command: %s", cmd)
 	}
 }
+
+func TestLogOneline(t *testing.T) {
+	tests := []struct {
+		vcsType string
+		want    string
+	}{
+		{"git", "git log --oneline 'origin/main..HEAD'"},
+		{"sapling", `sl log --pager never -T '{short(node)} {desc|firstline}\n' -r 'only(., remote/main)'`},
+		{"jj", `jj log --no-pager --color never --no-graph -r '"main"@origin..@-' -T 'commit_id.short() ++ " " ++ description.first_line() ++ "\n"'`},
+	}
+	for _, tt := range tests {
+		cb := NewCommandBuilder(tt.vcsType)
+		if got := cb.LogOneline(cb.DefaultBranchRef("main") + "..HEAD"); got != tt.want {
+			t.Errorf("%s LogOneline() = %q, want %q", tt.vcsType, got, tt.want)
+		}
+	}
+}
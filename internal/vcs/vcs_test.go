@@ -15,6 +15,7 @@ func TestNewCommandBuilder(t *testing.T) {
 		{"", "*vcs.GitCommandBuilder"},
 		{"unknown", "*vcs.GitCommandBuilder"},
 		{"sapling", "*vcs.SaplingCommandBuilder"},
+		{"jj", "*vcs.JJCommandBuilder"},
 	}
 	for _, tt := range tests {
 		cb := NewCommandBuilder(tt.vcsType)
@@ -27,6 +28,10 @@ func TestNewCommandBuilder(t *testing.T) {
 			if _, ok := cb.(*SaplingCommandBuilder); !ok {
 				t.Errorf("NewCommandBuilder(%q) = %T, want *SaplingCommandBuilder", tt.vcsType, cb)
 			}
+		case "*vcs.JJCommandBuilder":
+			if _, ok := cb.(*JJCommandBuilder); !ok {
+				t.Errorf("NewCommandBuilder(%q) = %T, want *JJCommandBuilder", tt.vcsType, cb)
+			}
 		}
 	}
 }
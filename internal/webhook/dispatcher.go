@@ -0,0 +1,419 @@
+// Package webhook delivers schmux events to user-configured HTTP endpoints.
+// Each delivery is a signed JSON POST retried with exponential backoff; the
+// outcome of every delivery is kept in a bounded, persisted log.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/fileutil"
+)
+
+// ErrEndpointNotFound is returned by Ping for an unknown endpoint name.
+var ErrEndpointNotFound = errors.New("webhook: endpoint not found")
+
+const (
+	// Request headers set on every delivery.
+	HeaderEvent     = "X-Schmux-Event"
+	HeaderDelivery  = "X-Schmux-Delivery"
+	HeaderSignature = "X-Schmux-Signature-256"
+
+	// maxDeliveries bounds the delivery log.
+	maxDeliveries = 200
+	// maxErrorBytes caps the response body kept as a delivery error.
+	maxErrorBytes = 512
+
+	defaultMaxAttempts = 5
+	defaultBackoff     = 2 * time.Second
+	maxBackoff         = time.Minute
+	requestTimeout     = 10 * time.Second
+)
+
+// Event is something that happened in schmux. Repo and State feed the
+// endpoint filters; Data is sent as the payload's "data" field.
+type Event struct {
+	Type  string
+	Repo  string
+	State string
+	Data  any
+}
+
+// Payload is the JSON body POSTed to an endpoint.
+type Payload struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Repo      string    `json:"repo,omitempty"`
+	Data      any       `json:"data"`
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by
+// the hex HMAC-SHA256 of body keyed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Matches reports whether ep wants ev. Disabled endpoints match nothing.
+func Matches(ep config.WebhookEndpoint, ev Event) bool {
+	if ep.Disabled {
+		return false
+	}
+	if len(ep.Events) > 0 && !slices.ContainsFunc(ep.Events, func(pattern string) bool {
+		if prefix, ok := strings.CutSuffix(pattern, ".*"); ok {
+			return strings.HasPrefix(ev.Type, prefix+".")
+		}
+		return pattern == ev.Type
+	}) {
+		return false
+	}
+	if len(ep.Repos) > 0 && ev.Repo != "" && !slices.Contains(ep.Repos, ev.Repo) {
+		return false
+	}
+	if len(ep.States) > 0 && ev.State != "" && !slices.Contains(ep.States, ev.State) {
+		return false
+	}
+	return true
+}
+
+// Dispatcher fans events out to matching endpoints. Deliveries run in the
+// background; Close cancels pending retries and waits for them to stop.
+type Dispatcher struct {
+	mu         sync.Mutex
+	path       string
+	deliveries []contracts.WebhookDelivery // oldest first
+
+	endpoints   func() []config.WebhookEndpoint
+	secret      func(name string) string
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	logger *log.Logger
+}
+
+// NewDispatcher creates a dispatcher persisting its delivery log to path.
+// Deliveries left pending by a previous daemon are marked failed.
+func NewDispatcher(path string, logger *log.Logger) *Dispatcher {
+	if logger == nil {
+		logger = log.NewWithOptions(io.Discard, log.Options{})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		path:        path,
+		endpoints:   func() []config.WebhookEndpoint { return nil },
+		secret:      func(string) string { return "" },
+		client:      &http.Client{Timeout: requestTimeout},
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBackoff,
+		ctx:         ctx,
+		cancel:      cancel,
+		logger:      logger,
+	}
+	d.load()
+	return d
+}
+
+// SetEndpointsFunc sets the source of configured endpoints. It is called
+// for every event, so config reloads take effect immediately.
+func (d *Dispatcher) SetEndpointsFunc(fn func() []config.WebhookEndpoint) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.endpoints = fn
+}
+
+// SetSecretFunc sets the lookup for an endpoint's signing secret. An empty
+// secret sends the delivery unsigned.
+func (d *Dispatcher) SetSecretFunc(fn func(name string) string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.secret = fn
+}
+
+// SetRetryPolicy overrides how many attempts a delivery gets and the delay
+// before the first retry; later retries double the delay, up to a minute.
+func (d *Dispatcher) SetRetryPolicy(maxAttempts int, backoff time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if maxAttempts > 0 {
+		d.maxAttempts = maxAttempts
+	}
+	if backoff > 0 {
+		d.backoff = backoff
+	}
+}
+
+func (d *Dispatcher) load() {
+	if d.path == "" {
+		return
+	}
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			d.logger.Warn("failed to read webhook deliveries", "err", err)
+		}
+		return
+	}
+	var deliveries []contracts.WebhookDelivery
+	if err := json.Unmarshal(data, &deliveries); err != nil {
+		d.logger.Warn("failed to parse webhook deliveries", "err", err)
+		return
+	}
+	now := time.Now().UTC()
+	for i := range deliveries {
+		if deliveries[i].Status == contracts.WebhookDeliveryPending {
+			deliveries[i].Status = contracts.WebhookDeliveryFailed
+			deliveries[i].Error = "interrupted by daemon restart"
+			deliveries[i].FinishedAt = &now
+		}
+	}
+	d.deliveries = deliveries
+}
+
+// saveLocked persists the delivery log. Caller must hold d.mu.
+func (d *Dispatcher) saveLocked() {
+	if d.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(d.deliveries, "", "  ")
+	if err != nil {
+		d.logger.Warn("failed to marshal webhook deliveries", "err", err)
+		return
+	}
+	if err := fileutil.AtomicWriteFile(d.path, data, 0600); err != nil {
+		d.logger.Warn("failed to write webhook deliveries", "err", err)
+	}
+}
+
+// Emit queues ev for every matching endpoint and returns immediately.
+func (d *Dispatcher) Emit(ev Event) {
+	d.mu.Lock()
+	endpoints := d.endpoints()
+	d.mu.Unlock()
+
+	var targets []config.WebhookEndpoint
+	for _, ep := range endpoints {
+		if Matches(ep, ev) {
+			targets = append(targets, ep)
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+	payload := Payload{ID: newID("evt-"), Type: ev.Type, Timestamp: time.Now().UTC(), Repo: ev.Repo, Data: ev.Data}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		d.logger.Error("failed to marshal webhook payload", "type", ev.Type, "err", err)
+		return
+	}
+	for _, ep := range targets {
+		delivery := d.start(ep.Name, payload)
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.deliver(d.ctx, ep, delivery.ID, payload.Type, body, d.maxAttemptsSnapshot())
+		}()
+	}
+}
+
+// Ping sends a single unretried "ping" event to the named endpoint, ignoring
+// its filters, and returns the resulting delivery record.
+func (d *Dispatcher) Ping(ctx context.Context, name string) (contracts.WebhookDelivery, error) {
+	d.mu.Lock()
+	endpoints := d.endpoints()
+	d.mu.Unlock()
+	idx := slices.IndexFunc(endpoints, func(ep config.WebhookEndpoint) bool { return ep.Name == name })
+	if idx < 0 {
+		return contracts.WebhookDelivery{}, ErrEndpointNotFound
+	}
+	ep := endpoints[idx]
+	payload := Payload{ID: newID("evt-"), Type: contracts.WebhookEventPing, Timestamp: time.Now().UTC(), Data: map[string]string{"endpoint": ep.Name}}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return contracts.WebhookDelivery{}, err
+	}
+	delivery := d.start(ep.Name, payload)
+	d.deliver(ctx, ep, delivery.ID, payload.Type, body, 1)
+	dl, _ := d.Get(delivery.ID)
+	return dl, nil
+}
+
+func (d *Dispatcher) maxAttemptsSnapshot() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.maxAttempts
+}
+
+// start records a pending delivery.
+func (d *Dispatcher) start(endpoint string, payload Payload) contracts.WebhookDelivery {
+	delivery := contracts.WebhookDelivery{
+		ID:        newID("whd-"),
+		Endpoint:  endpoint,
+		EventID:   payload.ID,
+		EventType: payload.Type,
+		Status:    contracts.WebhookDeliveryPending,
+		CreatedAt: payload.Timestamp,
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.deliveries = append(d.deliveries, delivery)
+	if len(d.deliveries) > maxDeliveries {
+		d.deliveries = slices.Clone(d.deliveries[len(d.deliveries)-maxDeliveries:])
+	}
+	d.saveLocked()
+	return delivery
+}
+
+// deliver POSTs body until it succeeds, fails permanently, runs out of
+// attempts, or ctx ends, updating the delivery record after each attempt.
+func (d *Dispatcher) deliver(ctx context.Context, ep config.WebhookEndpoint, deliveryID, eventType string, body []byte, maxAttempts int) {
+	d.mu.Lock()
+	secret := d.secret(ep.Name)
+	delay := d.backoff
+	d.mu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		code, err := d.post(ctx, ep.URL, secret, deliveryID, eventType, body)
+		retryable := err != nil && code == 0 || code == http.StatusTooManyRequests || code >= 500
+		done := err == nil || !retryable || attempt >= maxAttempts || ctx.Err() != nil
+		d.record(deliveryID, attempt, code, err, done)
+		if err == nil {
+			return
+		}
+		if done {
+			d.logger.Warn("webhook delivery failed", "endpoint", ep.Name, "event", eventType, "attempts", attempt, "err", err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			d.record(deliveryID, attempt, code, ctx.Err(), true)
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxBackoff)
+	}
+}
+
+// post makes one delivery attempt. A non-2xx response is an error carrying
+// its status code.
+func (d *Dispatcher) post(ctx context.Context, url, secret, deliveryID, eventType string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "schmux-webhook")
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	if secret != "" {
+		req.Header.Set(HeaderSignature, Sign(secret, body))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg := strings.TrimSpace(string(respBody))
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		return resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, msg)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) record(deliveryID string, attempt, code int, err error, done bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	i := slices.IndexFunc(d.deliveries, func(dl contracts.WebhookDelivery) bool { return dl.ID == deliveryID })
+	if i < 0 {
+		return // rotated out of the log
+	}
+	dl := &d.deliveries[i]
+	dl.Attempts = attempt
+	dl.StatusCode = code
+	dl.Error = ""
+	if err != nil {
+		dl.Error = err.Error()
+	}
+	if done {
+		now := time.Now().UTC()
+		dl.FinishedAt = &now
+		dl.Status = contracts.WebhookDeliveryDelivered
+		if err != nil {
+			dl.Status = contracts.WebhookDeliveryFailed
+		}
+	}
+	d.saveLocked()
+}
+
+// Deliveries returns the delivery log newest first, optionally limited to
+// one endpoint.
+func (d *Dispatcher) Deliveries(endpoint string) []contracts.WebhookDelivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make([]contracts.WebhookDelivery, 0, len(d.deliveries))
+	for i := len(d.deliveries) - 1; i >= 0; i-- {
+		if endpoint == "" || d.deliveries[i].Endpoint == endpoint {
+			out = append(out, d.deliveries[i])
+		}
+	}
+	return out
+}
+
+// Get returns one delivery record.
+func (d *Dispatcher) Get(id string) (contracts.WebhookDelivery, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, dl := range d.deliveries {
+		if dl.ID == id {
+			return dl, true
+		}
+	}
+	return contracts.WebhookDelivery{}, false
+}
+
+// Wait blocks until all in-flight deliveries have finished. Intended for
+// tests.
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Close cancels pending retries and waits for in-flight deliveries to stop.
+func (d *Dispatcher) Close() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+func newID(prefix string) string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%s%d", prefix, time.Now().UnixNano())
+	}
+	return prefix + hex.EncodeToString(b)
+}
@@ -0,0 +1,222 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+)
+
+// receiver is a local HTTP stand-in that records requests and answers with
+// the queued status codes, then 200.
+type receiver struct {
+	mu       sync.Mutex
+	codes    []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	code := http.StatusOK
+	if len(rc.codes) > 0 {
+		code, rc.codes = rc.codes[0], rc.codes[1:]
+	}
+	rc.mu.Unlock()
+	w.WriteHeader(code)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+func newTestDispatcher(t *testing.T, endpoints ...config.WebhookEndpoint) *Dispatcher {
+	t.Helper()
+	d := NewDispatcher(filepath.Join(t.TempDir(), "deliveries.json"), nil)
+	d.SetEndpointsFunc(func() []config.WebhookEndpoint { return endpoints })
+	d.SetRetryPolicy(3, time.Millisecond)
+	t.Cleanup(d.Close)
+	return d
+}
+
+func TestDispatcher_SignsPayload(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	d := newTestDispatcher(t, config.WebhookEndpoint{Name: "ci", URL: srv.URL})
+	d.SetSecretFunc(func(name string) string { return "s3cret" })
+	d.Emit(Event{Type: contracts.WebhookEventSessionStatus, Repo: "schmux", State: "completed", Data: map[string]string{"session_id": "s-1"}})
+	d.Wait()
+
+	if rc.count() != 1 {
+		t.Fatalf("requests = %d, want 1", rc.count())
+	}
+	req, body := rc.requests[0], rc.bodies[0]
+	if got := req.Header.Get(HeaderSignature); got != Sign("s3cret", body) {
+		t.Errorf("signature = %q, want %q", got, Sign("s3cret", body))
+	}
+	if got := req.Header.Get(HeaderEvent); got != contracts.WebhookEventSessionStatus {
+		t.Errorf("event header = %q", got)
+	}
+	var p struct {
+		Type string            `json:"type"`
+		Repo string            `json:"repo"`
+		Data map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Type != contracts.WebhookEventSessionStatus || p.Repo != "schmux" || p.Data["session_id"] != "s-1" {
+		t.Errorf("unexpected payload: %s", body)
+	}
+
+	dl := d.Deliveries("")
+	if len(dl) != 1 || dl[0].Status != contracts.WebhookDeliveryDelivered || dl[0].Attempts != 1 || dl[0].StatusCode != 200 {
+		t.Errorf("unexpected delivery log: %+v", dl)
+	}
+	if req.Header.Get(HeaderDelivery) != dl[0].ID {
+		t.Errorf("delivery header = %q, want %q", req.Header.Get(HeaderDelivery), dl[0].ID)
+	}
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		codes        []int
+		wantRequests int
+		wantStatus   contracts.WebhookDeliveryStatus
+	}{
+		{"recovers after server errors", []int{500, 503}, 3, contracts.WebhookDeliveryDelivered},
+		{"retries rate limiting", []int{429}, 2, contracts.WebhookDeliveryDelivered},
+		{"gives up after max attempts", []int{500, 500, 500, 500}, 3, contracts.WebhookDeliveryFailed},
+		{"client errors are permanent", []int{400}, 1, contracts.WebhookDeliveryFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &receiver{codes: tt.codes}
+			srv := httptest.NewServer(rc)
+			defer srv.Close()
+
+			d := newTestDispatcher(t, config.WebhookEndpoint{Name: "ci", URL: srv.URL})
+			d.Emit(Event{Type: contracts.WebhookEventWorkspaceCreated})
+			d.Wait()
+
+			if rc.count() != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", rc.count(), tt.wantRequests)
+			}
+			dl := d.Deliveries("ci")
+			if len(dl) != 1 || dl[0].Status != tt.wantStatus || dl[0].Attempts != tt.wantRequests {
+				t.Errorf("unexpected delivery log: %+v", dl)
+			}
+			if tt.wantStatus == contracts.WebhookDeliveryFailed && dl[0].Error == "" {
+				t.Error("failed delivery has no error")
+			}
+		})
+	}
+}
+
+func TestDispatcher_CloseStopsRetries(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	d := NewDispatcher("", nil)
+	d.SetEndpointsFunc(func() []config.WebhookEndpoint { return []config.WebhookEndpoint{{Name: "ci", URL: srv.URL}} })
+	d.SetRetryPolicy(5, time.Hour)
+	d.Emit(Event{Type: contracts.WebhookEventWorkspaceCreated})
+	for hits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	d.Close()
+
+	dl := d.Deliveries("")
+	if len(dl) != 1 || dl[0].Status != contracts.WebhookDeliveryFailed || dl[0].Error == "" {
+		t.Errorf("unexpected delivery log: %+v", dl)
+	}
+}
+
+func TestDispatcher_LoadMarksPendingFailed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deliveries.json")
+	data, _ := json.Marshal([]contracts.WebhookDelivery{
+		{ID: "whd-1", Endpoint: "ci", Status: contracts.WebhookDeliveryPending},
+		{ID: "whd-2", Endpoint: "ci", Status: contracts.WebhookDeliveryDelivered},
+	})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDispatcher(path, nil)
+	if dl, _ := d.Get("whd-1"); dl.Status != contracts.WebhookDeliveryFailed || dl.FinishedAt == nil {
+		t.Errorf("pending delivery not failed on load: %+v", dl)
+	}
+	if dl, _ := d.Get("whd-2"); dl.Status != contracts.WebhookDeliveryDelivered {
+		t.Errorf("delivered delivery changed on load: %+v", dl)
+	}
+}
+
+func TestDispatcher_Ping(t *testing.T) {
+	rc := &receiver{codes: []int{500}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	// Filters do not apply to pings.
+	d := newTestDispatcher(t, config.WebhookEndpoint{Name: "ci", URL: srv.URL, Events: []string{"build.*"}})
+	dl, err := d.Ping(context.Background(), "ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dl.EventType != contracts.WebhookEventPing || dl.Status != contracts.WebhookDeliveryFailed || dl.StatusCode != 500 || rc.count() != 1 {
+		t.Errorf("unexpected ping delivery: %+v (requests %d)", dl, rc.count())
+	}
+	if _, err := d.Ping(context.Background(), "nope"); !errors.Is(err, ErrEndpointNotFound) {
+		t.Errorf("Ping(unknown) err = %v, want ErrEndpointNotFound", err)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	ev := Event{Type: contracts.WebhookEventSessionStatus, Repo: "schmux", State: "completed"}
+	tests := []struct {
+		name string
+		ep   config.WebhookEndpoint
+		ev   Event
+		want bool
+	}{
+		{"no filters", config.WebhookEndpoint{}, ev, true},
+		{"disabled", config.WebhookEndpoint{Disabled: true}, ev, false},
+		{"event type", config.WebhookEndpoint{Events: []string{"session.status"}}, ev, true},
+		{"other event type", config.WebhookEndpoint{Events: []string{"workspace.created"}}, ev, false},
+		{"event family", config.WebhookEndpoint{Events: []string{"session.*"}}, ev, true},
+		{"family needs dot", config.WebhookEndpoint{Events: []string{"sess.*"}}, ev, false},
+		{"repo", config.WebhookEndpoint{Repos: []string{"other", "schmux"}}, ev, true},
+		{"other repo", config.WebhookEndpoint{Repos: []string{"other"}}, ev, false},
+		{"state", config.WebhookEndpoint{States: []string{"error", "completed"}}, ev, true},
+		{"other state", config.WebhookEndpoint{States: []string{"error"}}, ev, false},
+		{"stateless event passes state filter", config.WebhookEndpoint{States: []string{"error"}}, Event{Type: contracts.WebhookEventWorkspaceCreated, Repo: "schmux"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matches(tt.ep, tt.ev); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
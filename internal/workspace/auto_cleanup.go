@@ -0,0 +1,342 @@
+package workspace
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// autoCleanupInterval is how often MaintainAutoCleanup re-evaluates
+// workspaces. An evaluation runs git in every idle workspace, so it is not
+// done on every poll.
+const autoCleanupInterval = 5 * time.Minute
+
+// Why a workspace's work counts as landed.
+const (
+	AutoCleanupMerged   = "merged"    // the branch's own commits are contained in origin/<default>
+	AutoCleanupPRMerged = "pr_merged" // the branch's cached PR is merged
+	AutoCleanupPRClosed = "pr_closed" // the branch's cached PR is closed
+)
+
+// autoCleanup tracks how long each workspace has been eligible for cleanup.
+// It is in memory only, so a daemon restart starts every grace period over.
+type autoCleanup struct {
+	mu       sync.Mutex
+	eligible map[string]autoCleanupEntry // workspace ID -> eligibility
+	lastRun  time.Time
+
+	running sync.Mutex // held by the running MaintainAutoCleanup
+}
+
+type autoCleanupEntry struct {
+	since    time.Time
+	reported bool // dry run: "would dispose" already logged
+}
+
+// track records the workspaces found eligible now, keeping the time each one
+// was first seen, and forgets the rest: a workspace that stops qualifying
+// starts its grace period over.
+func (a *autoCleanup) track(workspaceIDs []string, now time.Time) map[string]autoCleanupEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	eligible := make(map[string]autoCleanupEntry, len(workspaceIDs))
+	for _, id := range workspaceIDs {
+		e, ok := a.eligible[id]
+		if !ok {
+			e = autoCleanupEntry{since: now}
+		}
+		eligible[id] = e
+	}
+	a.eligible = eligible
+	return eligible
+}
+
+func (a *autoCleanup) get(workspaceID string) (autoCleanupEntry, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	e, ok := a.eligible[workspaceID]
+	return e, ok
+}
+
+func (a *autoCleanup) markReported(workspaceID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if e, ok := a.eligible[workspaceID]; ok {
+		e.reported = true
+		a.eligible[workspaceID] = e
+	}
+}
+
+// reset restarts a workspace's grace period, e.g. when a spawn reuses it.
+func (a *autoCleanup) reset(workspaceID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.eligible, workspaceID)
+}
+
+// due reports whether interval has passed since the last evaluation, and
+// starts a new one if so.
+func (a *autoCleanup) due(now time.Time, interval time.Duration) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if now.Sub(a.lastRun) < interval {
+		return false
+	}
+	a.lastRun = now
+	return true
+}
+
+type autoCleanupCandidate struct {
+	workspace state.Workspace
+	reason    string
+}
+
+// MaintainAutoCleanup disposes workspaces whose work has landed upstream
+// once they have stayed eligible for the configured grace period. A
+// workspace is eligible when its HEAD is contained in the default branch or
+// its cached PR is merged or closed, it is clean, it has no sessions, and
+// neither it nor its repo is opted out. Disposal goes through the normal
+// safety checks and recycles when recycling is on; in dry-run mode it is
+// only logged. The daemon calls it after each poll; evaluations run at most
+// every autoCleanupInterval.
+func (m *Manager) MaintainAutoCleanup(ctx context.Context) {
+	policy := m.config.GetAutoCleanup()
+	if !policy.Enabled {
+		// Grace periods start over when the policy is turned back on.
+		m.autoCleanup.track(nil, time.Now())
+		return
+	}
+	if !m.autoCleanup.running.TryLock() {
+		return
+	}
+	defer m.autoCleanup.running.Unlock()
+	now := time.Now()
+	if !m.autoCleanup.due(now, autoCleanupInterval) {
+		return
+	}
+
+	candidates := m.autoCleanupCandidates(ctx, policy)
+	if ctx.Err() != nil {
+		return
+	}
+	ids := make([]string, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.workspace.ID
+	}
+	eligible := m.autoCleanup.track(ids, now)
+	grace := time.Duration(policy.GraceHours) * time.Hour
+
+	disposed := false
+	for _, c := range candidates {
+		e := eligible[c.workspace.ID]
+		if now.Sub(e.since) < grace || ctx.Err() != nil {
+			continue
+		}
+		if policy.DryRun {
+			if !e.reported {
+				m.logger.Info("auto-cleanup: would dispose workspace (dry run)", "id", c.workspace.ID, "branch", c.workspace.Branch, "reason", c.reason)
+				m.autoCleanup.markReported(c.workspace.ID)
+			}
+			continue
+		}
+		if m.autoCleanupDispose(ctx, c) {
+			disposed = true
+		}
+	}
+	if disposed && m.broadcastFn != nil {
+		m.broadcastFn()
+	}
+}
+
+// AutoCleanupReport evaluates the auto-cleanup policy now and returns the
+// workspaces it would dispose, without disposing anything. It works whether
+// or not the policy is enabled.
+func (m *Manager) AutoCleanupReport(ctx context.Context) contracts.AutoCleanupReport {
+	policy := m.config.GetAutoCleanup()
+	report := contracts.AutoCleanupReport{
+		Enabled:    policy.Enabled,
+		DryRun:     policy.DryRun,
+		GraceHours: policy.GraceHours,
+		Candidates: []contracts.AutoCleanupCandidate{},
+	}
+	grace := time.Duration(policy.GraceHours) * time.Hour
+	for _, c := range m.autoCleanupCandidates(ctx, policy) {
+		candidate := contracts.AutoCleanupCandidate{
+			WorkspaceID: c.workspace.ID,
+			Repo:        c.workspace.Repo,
+			Branch:      c.workspace.Branch,
+			Reason:      c.reason,
+		}
+		if e, ok := m.autoCleanup.get(c.workspace.ID); ok && policy.Enabled {
+			since := e.since
+			cleanupAt := since.Add(grace)
+			candidate.EligibleSince = &since
+			candidate.CleanupAt = &cleanupAt
+		}
+		report.Candidates = append(report.Candidates, candidate)
+	}
+	return report
+}
+
+// autoCleanupCandidates returns the workspaces eligible for cleanup now, with
+// why their work counts as landed.
+func (m *Manager) autoCleanupCandidates(ctx context.Context, policy config.AutoCleanupConfig) []autoCleanupCandidate {
+	excluded := make(map[string]bool, len(policy.ExcludeRepos))
+	for _, name := range policy.ExcludeRepos {
+		excluded[name] = true
+	}
+	prs := m.state.GetPullRequests()
+
+	var candidates []autoCleanupCandidate
+	for _, w := range m.state.GetWorkspaces() {
+		if ctx.Err() != nil {
+			break
+		}
+		if !m.autoCleanupApplies(w, excluded) {
+			continue
+		}
+		reason := m.landedReason(ctx, w, prs)
+		if reason == "" {
+			continue
+		}
+		safety, err := m.checkGitSafety(ctx, w.ID)
+		if err != nil || !safety.Safe {
+			continue
+		}
+		candidates = append(candidates, autoCleanupCandidate{workspace: w, reason: reason})
+	}
+	return candidates
+}
+
+// autoCleanupApplies reports whether the policy covers w at all: a local,
+// active git workspace on a branch, not protected, not in an excluded repo,
+// with no sessions and no sync in progress.
+func (m *Manager) autoCleanupApplies(w state.Workspace, excludedRepos map[string]bool) bool {
+	if w.Status != "" && w.Status != state.WorkspaceStatusRunning {
+		return false
+	}
+	if w.RemoteHostID != "" || w.Protected || !IsGitVCS(w.VCS) || w.Branch == "" {
+		return false
+	}
+	if repo, found := m.findRepoByURL(w.Repo); found && excludedRepos[repo.Name] {
+		return false
+	}
+	return !m.hasActiveSessions(w.ID) && !m.IsWorkspaceLocked(w.ID)
+}
+
+// landedReason returns why w's work counts as landed upstream, or "" if it
+// does not. A workspace on the default branch never qualifies. A squash
+// merged or closed PR leaves HEAD outside the default branch, so for those
+// every commit must still be on a remote. Otherwise the branch must have
+// commits of its own past its BaseSHA, all contained in the default branch:
+// a branch that was never worked on is not "merged", and neither is one
+// whose base was not recorded.
+func (m *Manager) landedReason(ctx context.Context, w state.Workspace, prs []contracts.PullRequest) string {
+	defaultBranch, err := m.GetDefaultBranch(ctx, w.Repo)
+	if err != nil || w.Branch == defaultBranch {
+		return ""
+	}
+	for _, pr := range prs {
+		if pr.RepoURL != w.Repo || pr.SourceBranch != w.Branch || pr.IsFork {
+			continue
+		}
+		reason := ""
+		switch strings.ToLower(pr.State) {
+		case "merged":
+			reason = AutoCleanupPRMerged
+		case "closed":
+			reason = AutoCleanupPRClosed
+		}
+		if reason != "" && m.allCommitsPushed(ctx, w) {
+			return reason
+		}
+	}
+	if w.BaseSHA == "" {
+		return ""
+	}
+	out, err := m.runGit(ctx, w.ID, RefreshTriggerExplicit, w.Path, "rev-list", "--count", w.BaseSHA+"..HEAD")
+	if err != nil || strings.TrimSpace(string(out)) == "0" {
+		return ""
+	}
+	if _, err := m.runGit(ctx, w.ID, RefreshTriggerExplicit, w.Path, "merge-base", "--is-ancestor", "HEAD", "refs/remotes/origin/"+defaultBranch); err != nil {
+		return ""
+	}
+	return AutoCleanupMerged
+}
+
+// headSHA returns the commit w has checked out, or "" for a non-git
+// workspace or when it cannot be read.
+func (m *Manager) headSHA(ctx context.Context, w state.Workspace) string {
+	if !IsGitVCS(w.VCS) {
+		return ""
+	}
+	out, err := m.runGit(ctx, w.ID, RefreshTriggerExplicit, w.Path, "rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// setBaseSHA records the workspace's HEAD as the commit its branch starts
+// from. Spawns call it once a workspace has taken a new branch and synced.
+func (m *Manager) setBaseSHA(ctx context.Context, workspaceID string) {
+	w, found := m.state.GetWorkspace(workspaceID)
+	if !found {
+		return
+	}
+	w.BaseSHA = m.headSHA(ctx, w)
+	if err := m.state.UpdateWorkspace(w); err != nil {
+		m.logger.Warn("failed to record branch base", "id", workspaceID, "err", err)
+		return
+	}
+	m.state.Save()
+}
+
+// advanceBaseSHA moves the base of a branch that had no commits of its own,
+// HEAD being before, to its HEAD now: whatever a sync brought in since came
+// from the default branch.
+func (m *Manager) advanceBaseSHA(ctx context.Context, workspaceID, before string) {
+	w, found := m.state.GetWorkspace(workspaceID)
+	if !found || w.BaseSHA == "" || w.BaseSHA != before {
+		return
+	}
+	if m.headSHA(ctx, w) != before {
+		m.setBaseSHA(ctx, workspaceID)
+	}
+}
+
+// allCommitsPushed reports whether every commit on w's HEAD is on a remote
+// tracking ref, so disposing the workspace cannot lose one.
+func (m *Manager) allCommitsPushed(ctx context.Context, w state.Workspace) bool {
+	out, err := m.runGit(ctx, w.ID, RefreshTriggerExplicit, w.Path, "rev-list", "--count", "HEAD", "--not", "--remotes")
+	return err == nil && strings.TrimSpace(string(out)) == "0"
+}
+
+// autoCleanupDispose disposes a candidate under its repo lock, after checking
+// it was not reused, protected or given a session since it was evaluated.
+func (m *Manager) autoCleanupDispose(ctx context.Context, c autoCleanupCandidate) bool {
+	w := c.workspace
+	lock := m.repoLock(w.Repo)
+	lock.Lock()
+	defer lock.Unlock()
+
+	fresh, found := m.state.GetWorkspace(w.ID)
+	if !found || fresh.Branch != w.Branch || !m.autoCleanupApplies(fresh, nil) {
+		return false
+	}
+	if _, ok := m.autoCleanup.get(w.ID); !ok {
+		return false // reused since it was evaluated
+	}
+	m.logger.Info("auto-cleanup: disposing workspace", "id", w.ID, "branch", w.Branch, "reason", c.reason)
+	if err := m.dispose(ctx, w.ID, false, false); err != nil {
+		m.logger.Warn("auto-cleanup: failed to dispose workspace", "id", w.ID, "err", err)
+		return false
+	}
+	m.autoCleanup.reset(w.ID)
+	return true
+}
@@ -0,0 +1,173 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func setupAutoCleanupTest(t *testing.T, policy config.AutoCleanupConfig) (*Manager, *state.State, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath, nil)
+	repoDir := gitTestWorkTree(t)
+
+	cfg := &config.Config{}
+	cfg.WorkspacePath = t.TempDir()
+	cfg.WorktreeBasePath = t.TempDir()
+	cfg.Repos = []config.Repo{testRepoWithBarePath(t, "test", repoDir)}
+	cfg.AutoCleanup = &policy
+	return New(cfg, st, statePath, testLogger()), st, repoDir
+}
+
+// autoCleanupIDs returns the candidates of a report as workspace ID -> reason.
+func autoCleanupIDs(report contracts.AutoCleanupReport) map[string]string {
+	ids := make(map[string]string)
+	for _, c := range report.Candidates {
+		ids[c.WorkspaceID] = c.Reason
+	}
+	return ids
+}
+
+// backdateAutoCleanup moves a workspace's eligibility back by d and makes
+// the next MaintainAutoCleanup evaluate.
+func backdateAutoCleanup(m *Manager, workspaceID string, d time.Duration) {
+	m.autoCleanup.mu.Lock()
+	defer m.autoCleanup.mu.Unlock()
+	if e, ok := m.autoCleanup.eligible[workspaceID]; ok {
+		e.since = e.since.Add(-d)
+		m.autoCleanup.eligible[workspaceID] = e
+	}
+	m.autoCleanup.lastRun = time.Time{}
+}
+
+// landBranch commits to w's branch, pushes it, fast-forwards the origin's
+// default branch onto it and fetches that into w.
+func landBranch(t *testing.T, w *state.Workspace, repoDir string) {
+	t.Helper()
+	writeFile(t, w.Path, w.Branch+".txt", "work")
+	runGit(t, w.Path, "add", ".")
+	runGit(t, w.Path, "commit", "-m", w.Branch)
+	runGit(t, w.Path, "push", "origin", w.Branch)
+	runGit(t, repoDir, "merge", "--ff-only", w.Branch)
+	runGit(t, w.Path, "fetch", "origin")
+}
+
+func TestAutoCleanupReport_Candidates(t *testing.T) {
+	t.Parallel()
+	m, st, repoDir := setupAutoCleanupTest(t, config.AutoCleanupConfig{})
+	ctx := context.Background()
+	create := func(branch string) *state.Workspace {
+		t.Helper()
+		w, err := m.GetOrCreate(ctx, repoDir, branch)
+		if err != nil {
+			t.Fatalf("GetOrCreate %s failed: %v", branch, err)
+		}
+		return w
+	}
+
+	// Branches with no commits of their own, left behind by the default
+	// branch, are not merged work.
+	unused := create("unused")
+	synced := create("synced")
+
+	landed := create("landed")
+	landBranch(t, landed, repoDir)
+	create("main")
+	wip := create("wip")
+	writeFile(t, wip.Path, "wip.txt", "work")
+	runGit(t, wip.Path, "add", ".")
+	runGit(t, wip.Path, "commit", "-m", "wip")
+	kept := create("kept")
+	landBranch(t, kept, repoDir)
+	kept.Protected = true
+	st.UpdateWorkspace(*kept)
+	busy := create("busy")
+	landBranch(t, busy, repoDir)
+	st.AddSession(state.Session{ID: "s1", WorkspaceID: busy.ID})
+	dirty := create("dirty")
+	landBranch(t, dirty, repoDir)
+	writeFile(t, dirty.Path, "scratch.txt", "notes")
+
+	runGit(t, unused.Path, "fetch", "origin")
+	if _, err := m.LinearSyncFromDefault(ctx, synced.ID); err != nil {
+		t.Fatalf("LinearSyncFromDefault failed: %v", err)
+	}
+
+	// The report previews the policy while it is disabled.
+	got := autoCleanupIDs(m.AutoCleanupReport(ctx))
+	if len(got) != 1 || got[landed.ID] != AutoCleanupMerged {
+		t.Fatalf("candidates = %v, want only %s (merged)", got, landed.ID)
+	}
+
+	// A closed PR qualifies only once every commit is on the remote.
+	st.SetPullRequests([]contracts.PullRequest{{RepoURL: repoDir, SourceBranch: "wip", State: "closed"}})
+	if got := autoCleanupIDs(m.AutoCleanupReport(ctx)); got[wip.ID] != "" {
+		t.Errorf("workspace with unpushed commits is a candidate (%s)", got[wip.ID])
+	}
+	runGit(t, wip.Path, "push", "origin", "wip")
+	if got := autoCleanupIDs(m.AutoCleanupReport(ctx)); got[wip.ID] != AutoCleanupPRClosed {
+		t.Errorf("pushed workspace with closed PR reason = %q, want %q", got[wip.ID], AutoCleanupPRClosed)
+	}
+
+	m.config.AutoCleanup.ExcludeRepos = []string{"test"}
+	if got := autoCleanupIDs(m.AutoCleanupReport(ctx)); len(got) != 0 {
+		t.Errorf("candidates in excluded repo = %v, want none", got)
+	}
+}
+
+func TestMaintainAutoCleanup_GracePeriod(t *testing.T) {
+	t.Parallel()
+	m, st, repoDir := setupAutoCleanupTest(t, config.AutoCleanupConfig{Enabled: true, GraceHours: 1, DryRun: true})
+	ctx := context.Background()
+	w, err := m.GetOrCreate(ctx, repoDir, "landed")
+	if err != nil {
+		t.Fatalf("GetOrCreate failed: %v", err)
+	}
+	landBranch(t, w, repoDir)
+	exists := func() bool {
+		_, found := st.GetWorkspace(w.ID)
+		return found
+	}
+
+	m.MaintainAutoCleanup(ctx)
+	report := m.AutoCleanupReport(ctx)
+	if len(report.Candidates) != 1 || report.Candidates[0].CleanupAt == nil {
+		t.Fatalf("report = %+v, want one tracked candidate", report)
+	}
+	backdateAutoCleanup(m, w.ID, 2*time.Hour)
+	m.MaintainAutoCleanup(ctx)
+	if !exists() {
+		t.Fatal("dry run disposed the workspace")
+	}
+
+	// Reusing the workspace starts its grace period over.
+	m.config.AutoCleanup.DryRun = false
+	if _, err := m.GetOrCreate(ctx, repoDir, "landed"); err != nil {
+		t.Fatalf("GetOrCreate reuse failed: %v", err)
+	}
+	m.autoCleanup.lastRun = time.Time{}
+	m.MaintainAutoCleanup(ctx)
+	if !exists() {
+		t.Fatal("workspace disposed right after it was reused")
+	}
+
+	backdateAutoCleanup(m, w.ID, 2*time.Hour)
+	m.MaintainAutoCleanup(ctx)
+	if exists() {
+		t.Fatal("workspace past its grace period not disposed")
+	}
+	if _, err := os.Stat(w.Path); !os.IsNotExist(err) {
+		t.Errorf("disposed workspace directory still on disk: %v", err)
+	}
+}
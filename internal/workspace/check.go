@@ -0,0 +1,120 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// checkOutputLimit is how much of a check's output is kept: the tail,
+	// where test runners and linters print their failures.
+	checkOutputLimit = 64 * 1024
+	// checkStreamInterval throttles how often streamed output is handed to
+	// the caller.
+	checkStreamInterval = 250 * time.Millisecond
+)
+
+// CheckResult is the outcome of one RunCheck.
+type CheckResult struct {
+	Passed     bool
+	ExitCode   int
+	DurationMs int64
+	// Output is the tail of the combined stdout and stderr.
+	Output string
+	// Error is set when the command could not run or timed out.
+	Error string
+}
+
+// RunCheck runs argv in dir, as push gates and acceptance commands do. The
+// command gets its own process group, which is killed when timeout expires
+// or ctx is done, so a test runner's children don't outlive it. onOutput,
+// when set, is called with the output tail as it grows, at most once per
+// checkStreamInterval.
+func RunCheck(ctx context.Context, dir string, argv []string, timeout time.Duration, onOutput func(tail string)) CheckResult {
+	return runCheck(ctx, dir, argv, timeout, &checkOutput{flush: onOutput})
+}
+
+// runCheck is RunCheck writing the command's output to out.
+func runCheck(ctx context.Context, dir string, argv []string, timeout time.Duration, out *checkOutput) CheckResult {
+	start := time.Now()
+	if len(argv) == 0 {
+		return CheckResult{Error: "empty command"}
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(checkCtx, argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = 3 * time.Second
+
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err := cmd.Run()
+
+	result := CheckResult{
+		DurationMs: time.Since(start).Milliseconds(),
+		Output:     out.String(),
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(checkCtx.Err(), context.DeadlineExceeded):
+		result.Error = fmt.Sprintf("timed out after %s", timeout)
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.Error = err.Error()
+	default:
+		result.Passed = true
+	}
+	return result
+}
+
+// checkOutput keeps the tail of a check's combined output and hands it to
+// flush at most once per checkStreamInterval. Every write is also copied
+// to tee, unthrottled. exec serializes writes when Stdout and Stderr are
+// the same writer.
+type checkOutput struct {
+	buf       []byte
+	truncated bool
+	lastFlush time.Time
+	flush     func(tail string)
+	tee       io.Writer
+}
+
+func (o *checkOutput) Write(p []byte) (int, error) {
+	if o.tee != nil {
+		o.tee.Write(p)
+	}
+	o.buf = append(o.buf, p...)
+	if len(o.buf) > checkOutputLimit {
+		o.buf = o.buf[len(o.buf)-checkOutputLimit:]
+		o.truncated = true
+	}
+	if o.flush != nil && time.Since(o.lastFlush) >= checkStreamInterval {
+		o.lastFlush = time.Now()
+		o.flush(o.String())
+	}
+	return len(p), nil
+}
+
+// String returns the kept output, starting at a line boundary once it has
+// been truncated.
+func (o *checkOutput) String() string {
+	s := string(o.buf)
+	if o.truncated {
+		if i := strings.IndexByte(s, '\n'); i >= 0 {
+			s = s[i+1:]
+		}
+	}
+	return strings.ToValidUTF8(s, "")
+}
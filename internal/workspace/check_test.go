@@ -0,0 +1,65 @@
+package workspace
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCheck(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		argv       []string
+		timeout    time.Duration
+		wantPassed bool
+		wantExit   int
+		wantOutput string
+		wantError  string
+	}{
+		{"passes", []string{"sh", "-c", "echo ok"}, time.Minute, true, 0, "ok", ""},
+		{"exit code", []string{"sh", "-c", "echo bad >&2; exit 4"}, time.Minute, false, 4, "bad", ""},
+		{"timeout", []string{"sleep", "30"}, 100 * time.Millisecond, false, 0, "", "timed out"},
+		{"missing binary", []string{"schmux-no-such-binary"}, time.Minute, false, 0, "", "not found"},
+		{"empty", nil, time.Minute, false, 0, "", "empty command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RunCheck(ctx, dir, tt.argv, tt.timeout, nil)
+			if got.Passed != tt.wantPassed || got.ExitCode != tt.wantExit {
+				t.Errorf("RunCheck() = %+v, want passed=%v exit=%d", got, tt.wantPassed, tt.wantExit)
+			}
+			if !strings.Contains(got.Output, tt.wantOutput) {
+				t.Errorf("Output = %q, want it to contain %q", got.Output, tt.wantOutput)
+			}
+			if !strings.Contains(got.Error, tt.wantError) || (tt.wantError == "" && got.Error != "") {
+				t.Errorf("Error = %q, want %q", got.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestCheckOutput_KeepsTailFromLineBoundary(t *testing.T) {
+	var flushed int
+	out := &checkOutput{flush: func(string) { flushed++ }}
+	line := strings.Repeat("x", 99) + "\n"
+	for i := 0; i < checkOutputLimit/len(line)+10; i++ {
+		out.Write([]byte(line))
+	}
+	out.Write([]byte("last line\n"))
+
+	s := out.String()
+	if len(s) > checkOutputLimit {
+		t.Errorf("kept %d bytes, want at most %d", len(s), checkOutputLimit)
+	}
+	if !strings.HasPrefix(s, line) || !strings.HasSuffix(s, "last line\n") {
+		t.Errorf("output does not start at a line boundary or lost the tail")
+	}
+	if flushed == 0 {
+		t.Error("flush was never called")
+	}
+}
@@ -0,0 +1,496 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/events"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// checkpointRefPrefix is the hidden ref namespace checkpoints live under:
+// refs/schmux/checkpoints/<workspace-id>/<seq>. Worktrees share refs with
+// their base repo, so the workspace ID keeps them apart.
+const checkpointRefPrefix = "refs/schmux/checkpoints/"
+
+// checkpointSubjectPrefix starts the commit subject of every checkpoint; the
+// rest of the subject is the reason.
+const checkpointSubjectPrefix = "schmux checkpoint: "
+
+// checkpointEnv lets commit-tree run in repos without user.name/user.email.
+var checkpointEnv = []string{
+	"GIT_AUTHOR_NAME=schmux",
+	"GIT_AUTHOR_EMAIL=schmux@localhost",
+	"GIT_COMMITTER_NAME=schmux",
+	"GIT_COMMITTER_EMAIL=schmux@localhost",
+}
+
+// checkpointState tracks checkpointing per workspace. The zero value is ready.
+type checkpointState struct {
+	mu        sync.Mutex
+	locks     map[string]*sync.Mutex       // workspace ID -> serializes create/restore
+	timers    map[string]*time.Timer       // workspace ID -> pending debounced checkpoint
+	lastState map[string]map[string]string // workspace ID -> session ID -> last reported agent state
+}
+
+func (c *checkpointState) lock(workspaceID string) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.locks == nil {
+		c.locks = make(map[string]*sync.Mutex)
+	}
+	l, ok := c.locks[workspaceID]
+	if !ok {
+		l = &sync.Mutex{}
+		c.locks[workspaceID] = l
+	}
+	return l
+}
+
+// forget drops a disposed workspace's pending checkpoint and the last
+// reported states of its sessions.
+func (c *checkpointState) forget(workspaceID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.timers[workspaceID]; ok {
+		t.Stop()
+		delete(c.timers, workspaceID)
+	}
+	delete(c.lastState, workspaceID)
+}
+
+// checkpointRef is one checkpoint ref as listed by for-each-ref.
+type checkpointRef struct {
+	seq       int
+	ref       string
+	commit    string
+	tree      string
+	base      string
+	reason    string
+	createdAt time.Time
+}
+
+func checkpointRefName(workspaceID string, seq int) string {
+	return fmt.Sprintf("%s%s/%d", checkpointRefPrefix, workspaceID, seq)
+}
+
+// checkpointWorkspace returns the workspace if it can be checkpointed.
+func (m *Manager) checkpointWorkspace(workspaceID string) (state.Workspace, error) {
+	w, found := m.state.GetWorkspace(workspaceID)
+	if !found {
+		return state.Workspace{}, fmt.Errorf("workspace not found: %s", workspaceID)
+	}
+	if w.RemoteHostID != "" || !IsGitVCS(w.VCS) {
+		return state.Workspace{}, ErrCheckpointsUnsupported
+	}
+	return w, nil
+}
+
+// listCheckpointRefs returns a workspace's checkpoints, oldest first.
+func (m *Manager) listCheckpointRefs(ctx context.Context, w state.Workspace) ([]checkpointRef, error) {
+	prefix := checkpointRefPrefix + w.ID + "/"
+	out, err := m.runGit(ctx, w.ID, RefreshTriggerExplicit, w.Path, "for-each-ref",
+		"--format=%(refname)%09%(objectname)%09%(tree)%09%(parent)%09%(committerdate:unix)%09%(subject)", prefix)
+	if err != nil {
+		return nil, err
+	}
+	var refs []checkpointRef
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.SplitN(line, "\t", 6)
+		if len(fields) != 6 {
+			continue
+		}
+		seq, err := strconv.Atoi(strings.TrimPrefix(fields[0], prefix))
+		if err != nil {
+			continue
+		}
+		unix, _ := strconv.ParseInt(fields[4], 10, 64)
+		refs = append(refs, checkpointRef{
+			seq:       seq,
+			ref:       fields[0],
+			commit:    fields[1],
+			tree:      fields[2],
+			base:      fields[3],
+			reason:    strings.TrimPrefix(fields[5], checkpointSubjectPrefix),
+			createdAt: time.Unix(unix, 0),
+		})
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].seq < refs[j].seq })
+	return refs, nil
+}
+
+// checkpointStats returns files changed and lines added/removed of each
+// commit against its parent (or the empty tree), keyed by commit.
+func (m *Manager) checkpointStats(ctx context.Context, w state.Workspace, commits []string) (map[string][3]int, error) {
+	stats := make(map[string][3]int, len(commits))
+	if len(commits) == 0 {
+		return stats, nil
+	}
+	args := append([]string{"log", "--no-walk=unsorted", "--root", "--no-renames", "--numstat", "--format=%x1e%H"}, commits...)
+	out, err := m.runGit(ctx, w.ID, RefreshTriggerExplicit, w.Path, args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range strings.Split(string(out), "\x1e") {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		if lines[0] == "" {
+			continue
+		}
+		var st [3]int
+		for _, line := range lines[1:] {
+			fields := strings.SplitN(line, "\t", 3)
+			if len(fields) != 3 {
+				continue
+			}
+			st[0]++
+			added, _ := strconv.Atoi(fields[0]) // "-" for binary files
+			removed, _ := strconv.Atoi(fields[1])
+			st[1] += added
+			st[2] += removed
+		}
+		stats[lines[0]] = st
+	}
+	return stats, nil
+}
+
+func checkpointInfo(r checkpointRef, st [3]int) contracts.Checkpoint {
+	return contracts.Checkpoint{
+		Seq:          r.seq,
+		Ref:          r.ref,
+		Commit:       r.commit,
+		Base:         r.base,
+		Reason:       r.reason,
+		CreatedAt:    r.createdAt,
+		FilesChanged: st[0],
+		LinesAdded:   st[1],
+		LinesRemoved: st[2],
+	}
+}
+
+// ListCheckpoints returns a workspace's checkpoints, newest first.
+func (m *Manager) ListCheckpoints(ctx context.Context, workspaceID string) ([]contracts.Checkpoint, error) {
+	w, err := m.checkpointWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := m.listCheckpointRefs(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]string, len(refs))
+	for i, r := range refs {
+		commits[i] = r.commit
+	}
+	stats, err := m.checkpointStats(ctx, w, commits)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]contracts.Checkpoint, 0, len(refs))
+	for i := len(refs) - 1; i >= 0; i-- {
+		out = append(out, checkpointInfo(refs[i], stats[refs[i].commit]))
+	}
+	return out, nil
+}
+
+// CreateCheckpoint snapshots the workspace's working tree, including
+// untracked but not ignored files. The snapshot is built in a temporary
+// index, so the workspace's index, HEAD and branch are untouched. When
+// nothing changed since the latest checkpoint, that checkpoint is returned.
+func (m *Manager) CreateCheckpoint(ctx context.Context, workspaceID, reason string) (*contracts.Checkpoint, error) {
+	w, err := m.checkpointWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	l := m.checkpoints.lock(workspaceID)
+	l.Lock()
+	defer l.Unlock()
+	return m.createCheckpointLocked(ctx, w, reason)
+}
+
+func (m *Manager) createCheckpointLocked(ctx context.Context, w state.Workspace, reason string) (*contracts.Checkpoint, error) {
+	gitDir, err := resolveGitDir(w.Path)
+	if err != nil {
+		return nil, err
+	}
+	// Seed the temporary index from the real one so git add can reuse its
+	// stat cache instead of rehashing every file.
+	tmp, err := os.CreateTemp("", "schmux-checkpoint-index-*")
+	if err != nil {
+		return nil, err
+	}
+	indexPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(indexPath)
+	if data, err := os.ReadFile(filepath.Join(gitDir, "index")); err == nil {
+		if err := os.WriteFile(indexPath, data, 0600); err != nil {
+			return nil, err
+		}
+	} else {
+		os.Remove(indexPath) // git rejects an empty index file; start from none
+	}
+	env := append([]string{"GIT_INDEX_FILE=" + indexPath}, checkpointEnv...)
+	git := func(args ...string) (string, error) {
+		out, err := m.runCmdEnv(ctx, "git", env, w.ID, RefreshTriggerExplicit, w.Path, args...)
+		return strings.TrimSpace(string(out)), err
+	}
+
+	if _, err := git("add", "-A"); err != nil {
+		return nil, fmt.Errorf("failed to stage checkpoint: %w", err)
+	}
+	tree, err := git("write-tree")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write checkpoint tree: %w", err)
+	}
+	head, _ := git("rev-parse", "--verify", "-q", "HEAD")
+
+	refs, err := m.listCheckpointRefs(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+	seq := 1
+	if len(refs) > 0 {
+		last := refs[len(refs)-1]
+		if last.tree == tree && last.base == head {
+			stats, err := m.checkpointStats(ctx, w, []string{last.commit})
+			if err != nil {
+				return nil, err
+			}
+			cp := checkpointInfo(last, stats[last.commit])
+			return &cp, nil
+		}
+		seq = last.seq + 1
+	}
+
+	reason = strings.Join(strings.Fields(reason), " ")
+	args := []string{"commit-tree", tree, "-m", checkpointSubjectPrefix + reason}
+	if head != "" {
+		args = append(args, "-p", head)
+	}
+	commit, err := git(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit checkpoint: %w", err)
+	}
+	ref := checkpointRefName(w.ID, seq)
+	if _, err := git("update-ref", ref, commit, ""); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint ref: %w", err)
+	}
+
+	// Drop the oldest checkpoints beyond the cap.
+	if excess := len(refs) + 1 - m.config.GetCheckpointMaxPerWorkspace(); excess > 0 {
+		for _, r := range refs[:min(excess, len(refs))] {
+			if _, err := git("update-ref", "-d", r.ref); err != nil {
+				m.logger.Warn("failed to prune checkpoint", "workspace_id", w.ID, "ref", r.ref, "err", err)
+			}
+		}
+	}
+
+	created := checkpointRef{seq: seq, ref: ref, commit: commit, tree: tree, base: head, reason: reason, createdAt: time.Now()}
+	stats, err := m.checkpointStats(ctx, w, []string{commit})
+	if err != nil {
+		return nil, err
+	}
+	cp := checkpointInfo(created, stats[commit])
+	m.logger.Debug("checkpoint created", "workspace_id", w.ID, "seq", seq, "reason", reason)
+	return &cp, nil
+}
+
+// RestoreCheckpoint restores the workspace's working tree, or a single file
+// or directory when path is set, to a checkpoint. The current working tree
+// is checkpointed first so the restore can itself be undone. Files that did
+// not exist at the checkpoint are deleted; ignored files are never touched.
+// The index and HEAD are left alone, so restored changes show up as
+// uncommitted changes.
+func (m *Manager) RestoreCheckpoint(ctx context.Context, workspaceID string, seq int, path string) (*contracts.CheckpointRestoreResponse, error) {
+	w, err := m.checkpointWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		clean := filepath.ToSlash(filepath.Clean(path))
+		if filepath.IsAbs(path) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") || clean == ".git" || strings.HasPrefix(clean, ".git/") {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCheckpointPath, path)
+		}
+		path = clean
+	}
+
+	l := m.checkpoints.lock(workspaceID)
+	l.Lock()
+	defer l.Unlock()
+
+	refs, err := m.listCheckpointRefs(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+	var target *checkpointRef
+	for i := range refs {
+		if refs[i].seq == seq {
+			target = &refs[i]
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("%w: %d", ErrCheckpointNotFound, seq)
+	}
+
+	backup, err := m.createCheckpointLocked(ctx, w, fmt.Sprintf("before restoring checkpoint %d", seq))
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkpoint before restore: %w", err)
+	}
+
+	git := func(args ...string) ([]byte, error) {
+		return m.runGit(ctx, w.ID, RefreshTriggerExplicit, w.Path, append([]string{"--literal-pathspecs"}, args...)...)
+	}
+	if path == "" {
+		// Delete files created since the checkpoint; restore brings back the rest.
+		out, err := git("diff", "--name-only", "-z", "--no-renames", "--diff-filter=A", target.commit, backup.Commit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files added since checkpoint: %w", err)
+		}
+		for _, name := range strings.Split(string(out), "\x00") {
+			if name == "" {
+				continue
+			}
+			if err := os.Remove(filepath.Join(w.Path, filepath.FromSlash(name))); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to remove %s: %w", name, err)
+			}
+		}
+		if _, err := git("restore", "--source="+target.commit, "--worktree", "--", "."); err != nil {
+			return nil, fmt.Errorf("failed to restore checkpoint: %w", err)
+		}
+	} else if _, err := git("cat-file", "-e", target.commit+":"+path); err == nil {
+		if _, err := git("restore", "--source="+target.commit, "--worktree", "--", path); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+	} else {
+		// The path did not exist at the checkpoint.
+		full := filepath.Join(w.Path, filepath.FromSlash(path))
+		info, statErr := os.Lstat(full)
+		if statErr != nil {
+			return nil, fmt.Errorf("%w: %s is not in checkpoint %d", ErrInvalidCheckpointPath, path, seq)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("%w: directory %s is not in checkpoint %d", ErrInvalidCheckpointPath, path, seq)
+		}
+		if err := os.Remove(full); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+
+	stats, err := m.checkpointStats(ctx, w, []string{target.commit})
+	if err != nil {
+		return nil, err
+	}
+	restored := checkpointInfo(*target, stats[target.commit])
+	m.logger.Info("checkpoint restored", "workspace_id", w.ID, "seq", seq, "path", path, "backup_seq", backup.Seq)
+	return &contracts.CheckpointRestoreResponse{Restored: &restored, Backup: backup, Path: path}, nil
+}
+
+// deleteCheckpoints removes all of a workspace's checkpoint refs. Called on
+// dispose, since worktree refs outlive the worktree in the shared base repo.
+func (m *Manager) deleteCheckpoints(ctx context.Context, w state.Workspace) {
+	m.checkpoints.forget(w.ID)
+
+	if w.RemoteHostID != "" || !IsGitVCS(w.VCS) {
+		return
+	}
+	refs, err := m.listCheckpointRefs(ctx, w)
+	if err != nil {
+		return
+	}
+	for _, r := range refs {
+		if err := m.runGitErr(ctx, w.ID, RefreshTriggerExplicit, w.Path, "update-ref", "-d", r.ref); err != nil {
+			m.logger.Warn("failed to delete checkpoint", "workspace_id", w.ID, "ref", r.ref, "err", err)
+		}
+	}
+}
+
+// scheduleCheckpoint (re)starts the debounce timer for an automatic
+// checkpoint of a workspace whose status just changed.
+func (m *Manager) scheduleCheckpoint(workspaceID string) {
+	if m.config == nil || !m.config.GetCheckpointsEnabled() {
+		return
+	}
+	debounce := m.config.CheckpointDebounce()
+	m.checkpoints.mu.Lock()
+	defer m.checkpoints.mu.Unlock()
+	if m.checkpoints.timers == nil {
+		m.checkpoints.timers = make(map[string]*time.Timer)
+	}
+	if t, ok := m.checkpoints.timers[workspaceID]; ok {
+		t.Reset(debounce)
+		return
+	}
+	m.checkpoints.timers[workspaceID] = time.AfterFunc(debounce, func() {
+		m.checkpoints.mu.Lock()
+		delete(m.checkpoints.timers, workspaceID)
+		m.checkpoints.mu.Unlock()
+		m.autoCheckpoint(workspaceID, "files changed")
+	})
+}
+
+// autoCheckpoint takes an automatic checkpoint, skipping workspaces that are
+// not running or are locked by a sync.
+func (m *Manager) autoCheckpoint(workspaceID, reason string) {
+	w, err := m.checkpointWorkspace(workspaceID)
+	if err != nil {
+		return
+	}
+	if (w.Status != "" && w.Status != state.WorkspaceStatusRunning) || m.IsWorkspaceLocked(workspaceID) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), m.config.GitStatusTimeout())
+	defer cancel()
+	if _, err := m.CreateCheckpoint(ctx, workspaceID, reason); err != nil {
+		m.logger.Warn("failed to create checkpoint", "workspace_id", workspaceID, "err", err)
+	}
+}
+
+// CheckpointEventHandler returns an event handler that checkpoints a
+// session's workspace whenever its agent reports a different state.
+func (m *Manager) CheckpointEventHandler() events.EventHandler {
+	return checkpointEventHandler{m: m}
+}
+
+type checkpointEventHandler struct {
+	m *Manager
+}
+
+func (h checkpointEventHandler) HandleEvent(_ context.Context, sessionID string, _ events.RawEvent, data []byte) {
+	var evt events.StatusEvent
+	if err := json.Unmarshal(data, &evt); err != nil || evt.State == "" {
+		return
+	}
+	sess, found := h.m.state.GetSession(sessionID)
+	if !found {
+		return
+	}
+	c := &h.m.checkpoints
+	c.mu.Lock()
+	if c.lastState == nil {
+		c.lastState = make(map[string]map[string]string)
+	}
+	sessions := c.lastState[sess.WorkspaceID]
+	if sessions == nil {
+		sessions = make(map[string]string)
+		c.lastState[sess.WorkspaceID] = sessions
+	}
+	prev := sessions[sessionID]
+	sessions[sessionID] = evt.State
+	c.mu.Unlock()
+	if prev == evt.State || !h.m.config.GetCheckpointsEnabled() {
+		return
+	}
+	reason := "agent " + evt.State
+	if prev != "" {
+		reason = fmt.Sprintf("agent %s -> %s", prev, evt.State)
+	}
+	go h.m.autoCheckpoint(sess.WorkspaceID, reason)
+}
@@ -0,0 +1,216 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/events"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func checkpointFixture(t *testing.T) (*Manager, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	wsPath := filepath.Join(tmpDir, "ws")
+	if err := os.MkdirAll(wsPath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	gitInit(t, wsPath)
+	writeFile(t, wsPath, "a.txt", "one\n")
+	runGit(t, wsPath, "add", "a.txt")
+	runGit(t, wsPath, "commit", "-q", "-m", "add a")
+
+	statePath := filepath.Join(tmpDir, "state.json")
+	cfg := config.CreateDefault(filepath.Join(tmpDir, "config.json"))
+	st := state.New(statePath, nil)
+	st.AddWorkspace(state.Workspace{ID: "ws-001", Repo: "test-repo", Branch: "main", Path: wsPath})
+	return New(cfg, st, statePath, testLogger()), wsPath
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCheckpoints_CreateListRestore(t *testing.T) {
+	t.Parallel()
+	m, wsPath := checkpointFixture(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	indexBefore := runGitOut(t, wsPath, "ls-files", "--stage")
+	headBefore := runGitOut(t, wsPath, "rev-parse", "HEAD")
+
+	writeFile(t, wsPath, "a.txt", "one\ntwo\n")
+	writeFile(t, wsPath, "new.txt", "untracked\n")
+	cp1, err := m.CreateCheckpoint(ctx, "ws-001", "manual")
+	if err != nil {
+		t.Fatalf("CreateCheckpoint: %v", err)
+	}
+	if cp1.Seq != 1 || cp1.Ref != "refs/schmux/checkpoints/ws-001/1" || cp1.Reason != "manual" {
+		t.Errorf("checkpoint = %+v", cp1)
+	}
+	if cp1.FilesChanged != 2 || cp1.LinesAdded != 2 || cp1.LinesRemoved != 0 {
+		t.Errorf("stats = %d files +%d -%d, want 2 files +2 -0", cp1.FilesChanged, cp1.LinesAdded, cp1.LinesRemoved)
+	}
+	if strings.TrimSpace(headBefore) != cp1.Base {
+		t.Errorf("Base = %q, want HEAD %q", cp1.Base, headBefore)
+	}
+
+	// The user's index, HEAD and working tree are untouched.
+	if got := runGitOut(t, wsPath, "ls-files", "--stage"); got != indexBefore {
+		t.Errorf("index changed:\n%s\nwant:\n%s", got, indexBefore)
+	}
+	if got := runGitOut(t, wsPath, "rev-parse", "HEAD"); got != headBefore {
+		t.Errorf("HEAD moved to %s", got)
+	}
+	if got := runGitOut(t, wsPath, "status", "--porcelain"); !strings.Contains(got, "?? new.txt") {
+		t.Errorf("new.txt should still be untracked, status:\n%s", got)
+	}
+
+	// Nothing changed: the latest checkpoint is reused.
+	again, err := m.CreateCheckpoint(ctx, "ws-001", "manual")
+	if err != nil {
+		t.Fatalf("CreateCheckpoint: %v", err)
+	}
+	if again.Seq != 1 {
+		t.Errorf("unchanged tree created checkpoint %d, want reuse of 1", again.Seq)
+	}
+
+	writeFile(t, wsPath, "a.txt", "changed\n")
+	writeFile(t, wsPath, "later.txt", "later\n")
+	if err := os.Remove(filepath.Join(wsPath, "new.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restore a single file.
+	resp, err := m.RestoreCheckpoint(ctx, "ws-001", 1, "new.txt")
+	if err != nil {
+		t.Fatalf("RestoreCheckpoint(file): %v", err)
+	}
+	if resp.Backup == nil || resp.Backup.Seq != 2 {
+		t.Errorf("backup = %+v, want checkpoint 2", resp.Backup)
+	}
+	if got := readFile(t, wsPath, "new.txt"); got != "untracked\n" {
+		t.Errorf("new.txt = %q", got)
+	}
+	if got := readFile(t, wsPath, "a.txt"); got != "changed\n" {
+		t.Errorf("single-file restore touched a.txt: %q", got)
+	}
+
+	// Restore the whole tree: files added since are removed.
+	if _, err := m.RestoreCheckpoint(ctx, "ws-001", 1, ""); err != nil {
+		t.Fatalf("RestoreCheckpoint: %v", err)
+	}
+	if got := readFile(t, wsPath, "a.txt"); got != "one\ntwo\n" {
+		t.Errorf("a.txt = %q, want checkpoint contents", got)
+	}
+	if _, err := os.Stat(filepath.Join(wsPath, "later.txt")); !os.IsNotExist(err) {
+		t.Errorf("later.txt should have been removed, stat err = %v", err)
+	}
+	if got := runGitOut(t, wsPath, "ls-files", "--stage"); got != indexBefore {
+		t.Errorf("restore changed the index:\n%s", got)
+	}
+
+	// The pre-restore state is recoverable from the backup.
+	list, err := m.ListCheckpoints(ctx, "ws-001")
+	if err != nil {
+		t.Fatalf("ListCheckpoints: %v", err)
+	}
+	var seqs []int
+	for _, cp := range list {
+		seqs = append(seqs, cp.Seq)
+	}
+	if len(seqs) != 3 || seqs[0] != 3 || seqs[2] != 1 {
+		t.Fatalf("seqs = %v, want [3 2 1]", seqs)
+	}
+	if _, err := m.RestoreCheckpoint(ctx, "ws-001", 2, "later.txt"); err != nil {
+		t.Fatalf("RestoreCheckpoint(backup): %v", err)
+	}
+	if got := readFile(t, wsPath, "later.txt"); got != "later\n" {
+		t.Errorf("later.txt = %q", got)
+	}
+}
+
+func TestCheckpoints_Errors(t *testing.T) {
+	t.Parallel()
+	m, _ := checkpointFixture(t)
+	ctx := context.Background()
+
+	if _, err := m.RestoreCheckpoint(ctx, "ws-001", 9, ""); !errors.Is(err, ErrCheckpointNotFound) {
+		t.Errorf("missing seq: err = %v, want ErrCheckpointNotFound", err)
+	}
+	for _, path := range []string{"../x", "/etc/passwd", ".git/config", "."} {
+		if _, err := m.RestoreCheckpoint(ctx, "ws-001", 1, path); !errors.Is(err, ErrInvalidCheckpointPath) {
+			t.Errorf("path %q: err = %v, want ErrInvalidCheckpointPath", path, err)
+		}
+	}
+
+	m.state.AddWorkspace(state.Workspace{ID: "remote-001", RemoteHostID: "host-1", Path: "/remote"})
+	if _, err := m.ListCheckpoints(ctx, "remote-001"); !errors.Is(err, ErrCheckpointsUnsupported) {
+		t.Errorf("remote workspace: err = %v, want ErrCheckpointsUnsupported", err)
+	}
+}
+
+func TestCheckpoints_PrunesOldest(t *testing.T) {
+	t.Parallel()
+	m, wsPath := checkpointFixture(t)
+	m.config.Checkpoints = &config.CheckpointsConfig{MaxPerWorkspace: 2}
+	ctx := context.Background()
+
+	for i, content := range []string{"x\n", "y\n", "z\n"} {
+		writeFile(t, wsPath, "a.txt", content)
+		if _, err := m.CreateCheckpoint(ctx, "ws-001", "edit"); err != nil {
+			t.Fatalf("CreateCheckpoint %d: %v", i, err)
+		}
+	}
+	list, err := m.ListCheckpoints(ctx, "ws-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Seq != 3 || list[1].Seq != 2 {
+		t.Errorf("checkpoints = %+v, want seqs [3 2]", list)
+	}
+
+	m.deleteCheckpoints(ctx, state.Workspace{ID: "ws-001", Path: wsPath})
+	if out := runGitOut(t, wsPath, "for-each-ref", checkpointRefPrefix); out != "" {
+		t.Errorf("refs left after delete:\n%s", out)
+	}
+}
+
+func TestCheckpoints_DisposeForgetsSessionStates(t *testing.T) {
+	t.Parallel()
+	m, _ := checkpointFixture(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	m.state.AddSession(state.Session{ID: "s-1", WorkspaceID: "ws-001"})
+
+	h := m.CheckpointEventHandler()
+	h.HandleEvent(ctx, "s-1", events.RawEvent{Type: "status"}, []byte(`{"type":"status","state":"working"}`))
+	m.checkpoints.mu.Lock()
+	got := m.checkpoints.lastState["ws-001"]["s-1"]
+	m.checkpoints.mu.Unlock()
+	if got != "working" {
+		t.Fatalf("last state = %q, want working", got)
+	}
+
+	m.state.RemoveSession("s-1")
+	if err := m.dispose(ctx, "ws-001", true, true); err != nil {
+		t.Fatalf("dispose: %v", err)
+	}
+	m.checkpoints.mu.Lock()
+	defer m.checkpoints.mu.Unlock()
+	if _, ok := m.checkpoints.lastState["ws-001"]; ok {
+		t.Error("session states of a disposed workspace kept")
+	}
+}
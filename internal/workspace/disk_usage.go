@@ -0,0 +1,475 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// How long a disk usage measurement stays current. Workspaces with sessions
+// change as agents work in them; idle workspaces and repo bases rarely do.
+const (
+	diskUsageActiveMaxAge = 15 * time.Minute
+	diskUsageIdleMaxAge   = 6 * time.Hour
+)
+
+// diskUsageSweepBudget caps how many directories one sweep walks, so a poll
+// never turns into a full du of the workspace root.
+const diskUsageSweepBudget = 4
+
+// diskUsage caches the measured size of workspace and repo base directories
+// and of the recordings directory.
+type diskUsage struct {
+	mu         sync.Mutex
+	entries    map[string]diskEntry // directory path -> last measurement
+	recordings int64
+
+	sweeping  sync.Mutex // held by the running MaintainDiskUsage
+	enforcing sync.Mutex // serializes EnforceDiskQuota
+}
+
+type diskEntry struct {
+	bytes      int64
+	measuredAt time.Time
+	stale      bool // the directory changed since it was measured
+}
+
+func (d *diskUsage) get(path string) (diskEntry, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	e, ok := d.entries[path]
+	return e, ok
+}
+
+func (d *diskUsage) set(path string, bytes int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.entries == nil {
+		d.entries = make(map[string]diskEntry)
+	}
+	d.entries[path] = diskEntry{bytes: bytes, measuredAt: time.Now()}
+}
+
+// invalidate marks path for re-measurement in the next sweep.
+func (d *diskUsage) invalidate(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if e, ok := d.entries[path]; ok {
+		e.stale = true
+		d.entries[path] = e
+	}
+}
+
+func (d *diskUsage) forget(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.entries, path)
+}
+
+// retain drops the measurements of directories not in paths.
+func (d *diskUsage) retain(paths map[string]time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for path := range d.entries {
+		if _, ok := paths[path]; !ok {
+			delete(d.entries, path)
+		}
+	}
+}
+
+func (d *diskUsage) total() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	total := d.recordings
+	for _, e := range d.entries {
+		total += e.bytes
+	}
+	return total
+}
+
+// diskUsageTargets returns the directories whose usage is tracked, with how
+// long each one's measurement stays current: local workspaces and the git
+// repo bases schmux manages. Sapling bases may be user-managed repos and are
+// left out.
+func (m *Manager) diskUsageTargets() map[string]time.Duration {
+	active := make(map[string]bool)
+	for _, s := range m.state.GetSessions() {
+		active[s.WorkspaceID] = true
+	}
+	targets := make(map[string]time.Duration)
+	for _, w := range m.state.GetWorkspaces() {
+		if w.RemoteHostID != "" || w.Path == "" || w.Status == state.WorkspaceStatusDisposing {
+			continue
+		}
+		targets[w.Path] = diskUsageIdleMaxAge
+		if active[w.ID] {
+			targets[w.Path] = diskUsageActiveMaxAge
+		}
+	}
+	for _, rb := range m.state.GetRepoBases() {
+		if rb.Path != "" && rb.VCS != "sapling" {
+			targets[rb.Path] = diskUsageIdleMaxAge
+		}
+	}
+	return targets
+}
+
+// MaintainDiskUsage re-measures up to diskUsageSweepBudget directories that
+// were never measured, changed, or went stale, then enforces the disk quota,
+// garbage collecting repo bases if that is not enough. The daemon calls it after
+// each poll; a call while one runs is a no-op.
+func (m *Manager) MaintainDiskUsage(ctx context.Context) {
+	if !m.diskUsage.sweeping.TryLock() {
+		return
+	}
+	defer m.diskUsage.sweeping.Unlock()
+
+	m.measureDiskUsage(ctx, diskUsageSweepBudget)
+	if err := m.enforceDiskQuota(ctx, true); err != nil {
+		m.logger.Warn("disk quota", "err", err)
+	}
+}
+
+// measureDiskUsage walks up to budget of the tracked directories that are
+// due, never-measured ones first, then changed ones, then the oldest. The
+// recordings directory is flat and cheap, so it is measured every time.
+func (m *Manager) measureDiskUsage(ctx context.Context, budget int) {
+	targets := m.diskUsageTargets()
+	m.diskUsage.retain(targets)
+
+	type due struct {
+		path       string
+		measured   bool
+		stale      bool
+		measuredAt time.Time
+	}
+	var queue []due
+	for path, maxAge := range targets {
+		if !m.measurementDue(path, maxAge) {
+			continue
+		}
+		e, ok := m.diskUsage.get(path)
+		queue = append(queue, due{path: path, measured: ok, stale: e.stale, measuredAt: e.measuredAt})
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		a, b := queue[i], queue[j]
+		if a.measured != b.measured {
+			return !a.measured
+		}
+		if a.stale != b.stale {
+			return a.stale
+		}
+		return a.measuredAt.Before(b.measuredAt)
+	})
+	for i, d := range queue {
+		if i == budget || ctx.Err() != nil {
+			break
+		}
+		m.measureDir(ctx, d.path)
+	}
+	m.measureRecordings()
+}
+
+// measurementDue reports whether path was never measured, changed since, or
+// was measured longer than maxAge ago.
+func (m *Manager) measurementDue(path string, maxAge time.Duration) bool {
+	e, ok := m.diskUsage.get(path)
+	return !ok || e.stale || time.Since(e.measuredAt) >= maxAge
+}
+
+// measureDir walks path and records its size.
+func (m *Manager) measureDir(ctx context.Context, path string) {
+	bytes, err := dirSize(ctx, path)
+	if os.IsNotExist(err) {
+		m.diskUsage.forget(path)
+		return
+	}
+	if err != nil {
+		m.logger.Debug("disk usage: measure failed", "path", path, "err", err)
+		return
+	}
+	m.diskUsage.set(path, bytes)
+}
+
+// measureRecordings records the total size of the timelapse recordings.
+func (m *Manager) measureRecordings() {
+	var total int64
+	entries, _ := os.ReadDir(m.recordingsDir)
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+	}
+	m.diskUsage.mu.Lock()
+	m.diskUsage.recordings = total
+	m.diskUsage.mu.Unlock()
+}
+
+// dirSize sums the sizes of the regular files under root, without following
+// symlinks. Entries that vanish or cannot be read mid-walk are skipped.
+func dirSize(ctx context.Context, root string) (int64, error) {
+	if _, err := os.Lstat(root); err != nil {
+		return 0, err
+	}
+	var total int64
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+// invalidateDiskUsage marks a workspace directory for re-measurement after
+// an operation that changes its contents.
+func (m *Manager) invalidateDiskUsage(path string) {
+	m.diskUsage.invalidate(path)
+}
+
+// overDiskQuota reports whether measured usage exceeds the disk quota.
+func (m *Manager) overDiskQuota() bool {
+	quota := m.config.GetDiskQuotaBytes()
+	return quota > 0 && m.diskUsage.total() > quota
+}
+
+// DiskUsage returns the last measured disk usage of local workspaces, repo
+// bases and timelapse recordings.
+func (m *Manager) DiskUsage() contracts.DiskUsage {
+	usage := contracts.DiskUsage{
+		TotalBytes: m.diskUsage.total(),
+		QuotaBytes: m.config.GetDiskQuotaBytes(),
+		Workspaces: []contracts.WorkspaceDiskUsage{},
+		RepoBases:  []contracts.RepoBaseDiskUsage{},
+	}
+	m.diskUsage.mu.Lock()
+	usage.RecordingsBytes = m.diskUsage.recordings
+	m.diskUsage.mu.Unlock()
+
+	for _, w := range m.state.GetWorkspaces() {
+		if w.RemoteHostID != "" {
+			continue
+		}
+		wu := contracts.WorkspaceDiskUsage{WorkspaceID: w.ID, Repo: w.Repo, Branch: w.Branch, Status: w.Status}
+		if e, ok := m.diskUsage.get(w.Path); ok {
+			wu.Bytes = e.bytes
+			wu.MeasuredAt = &e.measuredAt
+		}
+		if !w.LastUsedAt.IsZero() {
+			lastUsed := w.LastUsedAt
+			wu.LastUsedAt = &lastUsed
+		}
+		usage.Workspaces = append(usage.Workspaces, wu)
+	}
+	for _, rb := range m.state.GetRepoBases() {
+		ru := contracts.RepoBaseDiskUsage{Repo: rb.RepoURL, Path: rb.Path}
+		if e, ok := m.diskUsage.get(rb.Path); ok {
+			ru.Bytes = e.bytes
+			ru.MeasuredAt = &e.measuredAt
+		}
+		usage.RepoBases = append(usage.RepoBases, ru)
+	}
+	return usage
+}
+
+// EnforceDiskQuota frees space while measured usage is over the configured
+// quota: least recently used recyclable workspaces are purged first, then
+// idle warm pool workspaces, then repo bases nothing uses any more, then the
+// oldest finished timelapse recordings. It returns ErrDiskQuotaExceeded when
+// usage is still over the quota after all of that. Spawns call it; repo bases
+// are only garbage collected by MaintainDiskUsage, since git gc is too slow
+// to wait on.
+//
+// The caller must not hold a repo lock: eviction takes the lock of each
+// workspace's repo in turn.
+func (m *Manager) EnforceDiskQuota(ctx context.Context) error {
+	return m.enforceDiskQuota(ctx, false)
+}
+
+// enforceDiskQuota is EnforceDiskQuota; with compact set, the remaining repo
+// bases are then garbage collected.
+func (m *Manager) enforceDiskQuota(ctx context.Context, compact bool) error {
+	quota := m.config.GetDiskQuotaBytes()
+	if quota <= 0 {
+		return nil
+	}
+	m.diskUsage.enforcing.Lock()
+	defer m.diskUsage.enforcing.Unlock()
+	used := m.diskUsage.total()
+	if used <= quota {
+		return nil
+	}
+
+	m.logger.Warn("disk quota exceeded, freeing space", "used", used, "quota", quota)
+	m.evictUnused(ctx, quota)
+	if m.diskUsage.total() > quota {
+		m.removeUnusedRepoBases(ctx)
+	}
+	if m.diskUsage.total() > quota {
+		m.pruneRecordings(quota)
+	}
+	if compact && m.diskUsage.total() > quota {
+		m.compactRepoBases(ctx)
+	}
+	if m.broadcastFn != nil {
+		m.broadcastFn()
+	}
+	if used := m.diskUsage.total(); used > quota {
+		return fmt.Errorf("%w: using %s of %s", ErrDiskQuotaExceeded, formatGB(used), formatGB(quota))
+	}
+	return nil
+}
+
+// evictUnused purges recyclable workspaces, least recently used first, then
+// ready warm pool workspaces that are not being provisioned, oldest first,
+// until usage is within quota. The pool does not refill while over quota.
+func (m *Manager) evictUnused(ctx context.Context, quota int64) {
+	var unused []state.Workspace
+	for _, w := range m.state.GetWorkspaces() {
+		if (w.Status == state.WorkspaceStatusRecyclable || w.Status == state.WorkspaceStatusReady) && w.RemoteHostID == "" {
+			unused = append(unused, w)
+		}
+	}
+	lastUsed := func(w state.Workspace) time.Time {
+		if w.LastUsedAt.IsZero() {
+			return w.CreatedAt
+		}
+		return w.LastUsedAt
+	}
+	targets := m.diskUsageTargets()
+	sort.SliceStable(unused, func(i, j int) bool {
+		a, b := unused[i], unused[j]
+		if a.Status != b.Status {
+			return a.Status == state.WorkspaceStatusRecyclable
+		}
+		return lastUsed(a).Before(lastUsed(b))
+	})
+
+	for _, w := range unused {
+		if m.diskUsage.total() <= quota || ctx.Err() != nil {
+			return
+		}
+		// Re-measure a missing or stale size before deciding: the purge
+		// frees what was measured, and the workspace may have shrunk.
+		if m.measurementDue(w.Path, targets[w.Path]) {
+			m.measureDir(ctx, w.Path)
+			if m.diskUsage.total() <= quota {
+				return
+			}
+		}
+		lock := m.repoLock(w.Repo)
+		lock.Lock()
+		if fresh, found := m.state.GetWorkspace(w.ID); found && fresh.Status == w.Status && !m.warmPool.isBusy(w.ID) {
+			e, _ := m.diskUsage.get(w.Path)
+			m.logger.Info("disk quota: purging unused workspace", "id", w.ID, "status", w.Status, "bytes", e.bytes)
+			if err := m.dispose(ctx, w.ID, true, true); err != nil {
+				m.logger.Warn("disk quota: failed to purge unused workspace", "id", w.ID, "err", err)
+			}
+		}
+		lock.Unlock()
+	}
+}
+
+// pruneRecordings deletes finished timelapse recordings, oldest first, with
+// their compressed copies and exports, until usage is within quota.
+// Recordings of live sessions are kept.
+func (m *Manager) pruneRecordings(quota int64) {
+	entries, err := os.ReadDir(m.recordingsDir)
+	if err != nil {
+		return
+	}
+	sessions := m.state.GetSessions()
+	live := func(recordingID string) bool {
+		for _, s := range sessions {
+			if recordingID == s.ID || strings.HasPrefix(recordingID, s.ID+"-") {
+				return true
+			}
+		}
+		return false
+	}
+	type recording struct {
+		id      string
+		modTime time.Time
+	}
+	var recordings []recording
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasSuffix(name, ".cast") || strings.HasSuffix(name, ".timelapse.cast") {
+			continue
+		}
+		id := strings.TrimSuffix(name, ".cast")
+		info, err := e.Info()
+		if err != nil || live(id) {
+			continue
+		}
+		recordings = append(recordings, recording{id: id, modTime: info.ModTime()})
+	}
+	sort.Slice(recordings, func(i, j int) bool { return recordings[i].modTime.Before(recordings[j].modTime) })
+
+	for _, r := range recordings {
+		if m.diskUsage.total() <= quota {
+			break
+		}
+		files, _ := filepath.Glob(filepath.Join(m.recordingsDir, r.id+".*"))
+		for _, f := range files {
+			if err := os.Remove(f); err != nil {
+				m.logger.Warn("disk quota: failed to remove recording file", "path", f, "err", err)
+			}
+		}
+		m.logger.Info("disk quota: pruned timelapse recording", "recording", r.id)
+		m.measureRecordings()
+	}
+}
+
+// removeUnusedRepoBases removes repo bases nothing uses any more, e.g. after
+// evicting the last workspace of a repo that is no longer configured, and
+// drops their measurements.
+func (m *Manager) removeUnusedRepoBases(ctx context.Context) {
+	if err := m.CleanupUnusedRepoBases(ctx); err != nil {
+		m.logger.Warn("disk quota: failed to clean up unused repo bases", "err", err)
+	}
+	m.diskUsage.retain(m.diskUsageTargets())
+}
+
+// compactRepoBases prunes worktrees and runs git gc in the git repo bases
+// and re-measures them.
+func (m *Manager) compactRepoBases(ctx context.Context) {
+	for _, rb := range m.state.GetRepoBases() {
+		if ctx.Err() != nil {
+			return
+		}
+		if !IsGitVCS(rb.VCS) || rb.Path == "" {
+			continue
+		}
+		if _, err := os.Stat(rb.Path); err != nil {
+			continue
+		}
+		if _, err := m.runGit(ctx, "", RefreshTriggerExplicit, rb.Path, "worktree", "prune"); err != nil {
+			m.logger.Warn("disk quota: git worktree prune failed", "path", rb.Path, "err", err)
+		}
+		if _, err := m.runGit(ctx, "", RefreshTriggerExplicit, rb.Path, "gc", "--quiet"); err != nil {
+			m.logger.Warn("disk quota: git gc failed", "path", rb.Path, "err", err)
+		}
+		m.measureDir(ctx, rb.Path)
+	}
+}
+
+// formatGB formats a byte count in gigabytes for messages.
+func formatGB(bytes int64) string {
+	return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+}
@@ -0,0 +1,329 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// setupDiskQuotaTest returns a manager with a quota of quotaGB and an empty
+// recordings directory.
+func setupDiskQuotaTest(t *testing.T, quotaGB int) (*Manager, *state.State, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+	cfg := &config.Config{}
+	cfg.WorkspacePath = tmpDir
+	cfg.RecycleWorkspaces = true
+	cfg.DiskQuota = &config.DiskQuotaConfig{MaxGB: quotaGB}
+	st := state.New(statePath, nil)
+	m := New(cfg, st, statePath, testLogger())
+	m.recordingsDir = t.TempDir()
+	return m, st, tmpDir
+}
+
+// addDiskWorkspace adds a local workspace to state and records bytes as its
+// measured size.
+func addDiskWorkspace(t *testing.T, m *Manager, st *state.State, dir, id, status string, lastUsed time.Time, bytes int64) string {
+	t.Helper()
+	path := filepath.Join(dir, id)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	exec.Command("git", "init", "-q", path).Run()
+	st.AddWorkspace(state.Workspace{
+		ID:         id,
+		Repo:       "test",
+		Branch:     id,
+		Path:       path,
+		Status:     status,
+		LastUsedAt: lastUsed,
+	})
+	m.diskUsage.set(path, bytes)
+	return path
+}
+
+func TestDirSize(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeFile(t, dir, "a.txt", "hello")
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	writeFile(t, dir, "sub/b.txt", "world!")
+	outside := t.TempDir()
+	writeFile(t, outside, "big.txt", string(make([]byte, 4096)))
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := dirSize(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("dirSize() error: %v", err)
+	}
+	if got != 11 {
+		t.Errorf("dirSize() = %d, want 11 (symlinks not followed)", got)
+	}
+	if _, err := dirSize(context.Background(), filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("dirSize() of missing dir error = %v, want not exist", err)
+	}
+}
+
+func TestMeasureDiskUsage_BudgetAndOrder(t *testing.T) {
+	t.Parallel()
+	m, st, dir := setupDiskQuotaTest(t, 0)
+	measured := addDiskWorkspace(t, m, st, dir, "ws-measured", state.WorkspaceStatusRunning, time.Time{}, 1)
+	for _, id := range []string{"ws-new-1", "ws-new-2"} {
+		path := filepath.Join(dir, id)
+		os.MkdirAll(path, 0755)
+		st.AddWorkspace(state.Workspace{ID: id, Repo: "test", Path: path, Status: state.WorkspaceStatusRunning})
+	}
+	ctx := context.Background()
+
+	// Never-measured directories go first; the current one is left alone.
+	m.measureDiskUsage(ctx, 2)
+	for _, id := range []string{"ws-new-1", "ws-new-2"} {
+		if _, ok := m.diskUsage.get(filepath.Join(dir, id)); !ok {
+			t.Errorf("%s not measured", id)
+		}
+	}
+	if e, _ := m.diskUsage.get(measured); e.bytes != 1 {
+		t.Errorf("current measurement re-walked: bytes = %d, want 1", e.bytes)
+	}
+
+	// An invalidated directory is re-measured in the next sweep.
+	writeFile(t, filepath.Join(dir, "ws-new-1"), "f.txt", "12345")
+	m.invalidateDiskUsage(filepath.Join(dir, "ws-new-1"))
+	m.measureDiskUsage(ctx, 1)
+	if e, _ := m.diskUsage.get(filepath.Join(dir, "ws-new-1")); e.bytes != 5 || e.stale {
+		t.Errorf("invalidated workspace = %+v, want 5 bytes and current", e)
+	}
+
+	// Measurements of workspaces that leave state are dropped.
+	st.RemoveWorkspace("ws-new-2")
+	m.measureDiskUsage(ctx, 0)
+	if _, ok := m.diskUsage.get(filepath.Join(dir, "ws-new-2")); ok {
+		t.Error("measurement of removed workspace kept")
+	}
+}
+
+func TestEnforceDiskQuota_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	m, st, dir := setupDiskQuotaTest(t, 1)
+	now := time.Now()
+	const size = 600 << 20
+	oldest := addDiskWorkspace(t, m, st, dir, "ws-oldest", state.WorkspaceStatusRecyclable, now.Add(-3*time.Hour), size)
+	newest := addDiskWorkspace(t, m, st, dir, "ws-newest", state.WorkspaceStatusRecyclable, now.Add(-time.Hour), size)
+	middle := addDiskWorkspace(t, m, st, dir, "ws-middle", state.WorkspaceStatusRecyclable, now.Add(-2*time.Hour), size)
+	running := addDiskWorkspace(t, m, st, dir, "ws-running", state.WorkspaceStatusRunning, now.Add(-5*time.Hour), 100<<20)
+
+	if err := m.EnforceDiskQuota(context.Background()); err != nil {
+		t.Fatalf("EnforceDiskQuota() error: %v", err)
+	}
+	for _, path := range []string{oldest, middle} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s not purged: %v", path, err)
+		}
+	}
+	for _, path := range []string{newest, running} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s purged, want kept: %v", path, err)
+		}
+	}
+	if _, found := st.GetWorkspace("ws-newest"); !found {
+		t.Error("most recently used recyclable workspace removed from state")
+	}
+	if got, want := m.diskUsage.total(), int64(size+100<<20); got != want {
+		t.Errorf("total = %d after eviction, want %d", got, want)
+	}
+}
+
+func TestEnforceDiskQuota_PrunesRecordingsThenFails(t *testing.T) {
+	t.Parallel()
+	m, st, dir := setupDiskQuotaTest(t, 1)
+	addDiskWorkspace(t, m, st, dir, "ws-running", state.WorkspaceStatusRunning, time.Time{}, 2<<30)
+	st.AddSession(state.Session{ID: "live", WorkspaceID: "ws-running"})
+
+	old := time.Now().Add(-time.Hour)
+	for _, name := range []string{"done.cast", "done.timelapse.cast", "live-1.cast"} {
+		path := filepath.Join(m.recordingsDir, name)
+		writeFile(t, m.recordingsDir, name, "data")
+		os.Chtimes(path, old, old)
+	}
+	m.measureRecordings()
+
+	err := m.EnforceDiskQuota(context.Background())
+	if !errors.Is(err, ErrDiskQuotaExceeded) {
+		t.Fatalf("EnforceDiskQuota() error = %v, want ErrDiskQuotaExceeded", err)
+	}
+	for _, name := range []string{"done.cast", "done.timelapse.cast"} {
+		if _, err := os.Stat(filepath.Join(m.recordingsDir, name)); !os.IsNotExist(err) {
+			t.Errorf("finished recording file %s not pruned: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(m.recordingsDir, "live-1.cast")); err != nil {
+		t.Errorf("live session recording pruned: %v", err)
+	}
+}
+
+func TestEnforceDiskQuota_EvictsIdleReadyWorkspaces(t *testing.T) {
+	t.Parallel()
+	m, st, dir := setupDiskQuotaTest(t, 1)
+	now := time.Now()
+	const size = 600 << 20
+	recyclable := addDiskWorkspace(t, m, st, dir, "ws-recyclable", state.WorkspaceStatusRecyclable, now, size)
+	ready := addDiskWorkspace(t, m, st, dir, "ws-ready", state.WorkspaceStatusReady, time.Time{}, size)
+	busy := addDiskWorkspace(t, m, st, dir, "ws-busy", state.WorkspaceStatusReady, time.Time{}, size)
+	m.warmPool.setBusy("ws-busy", true)
+	writeFile(t, m.recordingsDir, "done.cast", "data")
+	m.measureRecordings()
+
+	if err := m.EnforceDiskQuota(context.Background()); err != nil {
+		t.Fatalf("EnforceDiskQuota() error: %v", err)
+	}
+	for _, path := range []string{recyclable, ready} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s not purged: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(busy); err != nil {
+		t.Errorf("busy ready workspace purged: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(m.recordingsDir, "done.cast")); err != nil {
+		t.Errorf("recording pruned while a ready workspace could be purged: %v", err)
+	}
+}
+
+func TestEnforceDiskQuota_RemeasuresStaleBeforeEvicting(t *testing.T) {
+	t.Parallel()
+	m, st, dir := setupDiskQuotaTest(t, 1)
+	path := addDiskWorkspace(t, m, st, dir, "ws-recyclable", state.WorkspaceStatusRecyclable, time.Time{}, 2<<30)
+	m.invalidateDiskUsage(path)
+
+	// The cached size is stale; the real directory is tiny, so nothing
+	// needs purging.
+	if err := m.EnforceDiskQuota(context.Background()); err != nil {
+		t.Fatalf("EnforceDiskQuota() error: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("workspace purged on a stale size: %v", err)
+	}
+	if e, _ := m.diskUsage.get(path); e.stale || e.bytes >= 1<<30 {
+		t.Errorf("measurement = %+v, want re-measured", e)
+	}
+}
+
+func TestEnforceDiskQuota_RemovesUnusedRepoBases(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	m, st, _ := setupDiskQuotaTest(t, 1)
+	m.config.WorktreeBasePath = t.TempDir()
+	base := filepath.Join(m.config.WorktreeBasePath, "gone.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", base).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	st.AddRepoBase(state.RepoBase{RepoURL: "https://example.com/gone.git", Path: base})
+	m.diskUsage.set(base, 2<<30)
+
+	// Spawns remove repo bases nothing uses before giving up.
+	if err := m.EnforceDiskQuota(context.Background()); err != nil {
+		t.Fatalf("EnforceDiskQuota() error: %v", err)
+	}
+	if _, err := os.Stat(base); !os.IsNotExist(err) {
+		t.Errorf("unused repo base not removed: %v", err)
+	}
+	if len(st.GetRepoBases()) != 0 {
+		t.Errorf("repo bases = %+v, want none", st.GetRepoBases())
+	}
+	if got := m.diskUsage.total(); got != 0 {
+		t.Errorf("total = %d, want 0 after removing the base", got)
+	}
+}
+
+func TestGetOrCreate_DiskQuotaReusesRecyclable(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath, nil)
+	repoDir := gitTestWorkTree(t)
+	gitTestBranch(t, repoDir, "feature-1")
+
+	cfg := &config.Config{}
+	cfg.WorkspacePath = t.TempDir()
+	cfg.WorktreeBasePath = t.TempDir()
+	cfg.RecycleWorkspaces = true
+	cfg.Repos = []config.Repo{testRepoWithBarePath(t, "test", repoDir)}
+	m := New(cfg, st, statePath, testLogger())
+	m.recordingsDir = t.TempDir()
+	ctx := context.Background()
+
+	ws, err := m.GetOrCreate(ctx, repoDir, "main")
+	if err != nil {
+		t.Fatalf("GetOrCreate main failed: %v", err)
+	}
+	if err := m.Dispose(ctx, ws.ID); err != nil {
+		t.Fatalf("Dispose failed: %v", err)
+	}
+	cfg.DiskQuota = &config.DiskQuotaConfig{MaxGB: 1}
+	m.diskUsage.set(ws.Path, 2<<30)
+
+	// Over quota, the spawn takes the recyclable workspace instead of
+	// purging it to make room.
+	reused, err := m.GetOrCreate(ctx, repoDir, "feature-1")
+	if err != nil {
+		t.Fatalf("GetOrCreate feature-1 over quota failed: %v", err)
+	}
+	if reused.ID != ws.ID {
+		t.Errorf("GetOrCreate feature-1 = %s, want reuse of recyclable %s", reused.ID, ws.ID)
+	}
+}
+
+func TestGetOrCreate_DiskQuotaExceeded(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath, nil)
+	repoDir := gitTestWorkTree(t)
+	gitTestBranch(t, repoDir, "feature-1")
+
+	cfg := &config.Config{}
+	cfg.WorkspacePath = t.TempDir()
+	cfg.WorktreeBasePath = t.TempDir()
+	cfg.Repos = []config.Repo{testRepoWithBarePath(t, "test", repoDir)}
+	m := New(cfg, st, statePath, testLogger())
+	m.recordingsDir = t.TempDir()
+	ctx := context.Background()
+
+	ws, err := m.GetOrCreate(ctx, repoDir, "main")
+	if err != nil {
+		t.Fatalf("GetOrCreate main failed: %v", err)
+	}
+	cfg.DiskQuota = &config.DiskQuotaConfig{MaxGB: 1}
+	m.diskUsage.set(ws.Path, 2<<30)
+
+	if _, err := m.GetOrCreate(ctx, repoDir, "feature-1"); !errors.Is(err, ErrDiskQuotaExceeded) {
+		t.Fatalf("GetOrCreate feature-1 error = %v, want ErrDiskQuotaExceeded", err)
+	}
+	if len(st.GetWorkspaces()) != 1 {
+		t.Errorf("workspaces = %d after failed spawn, want 1", len(st.GetWorkspaces()))
+	}
+	// Reusing the workspace that holds the branch does not need new space.
+	reused, err := m.GetOrCreate(ctx, repoDir, "main")
+	if err != nil {
+		t.Fatalf("GetOrCreate main over quota failed: %v", err)
+	}
+	if reused.ID != ws.ID {
+		t.Errorf("GetOrCreate main = %s, want reuse of %s", reused.ID, ws.ID)
+	}
+}
@@ -218,6 +218,10 @@ func (e *Ensurer) ensureWorkspace(workspacePath string, hookTools []string, repo
 		if err := GitExclude(workspacePath); err != nil {
 			fmt.Printf("[ensure] warning: failed to ensure git exclude: %v\n", err)
 		}
+	} else if vcs == "jj" {
+		if err := JJExclude(workspacePath); err != nil {
+			fmt.Printf("[ensure] warning: failed to ensure jj exclude: %v\n", err)
+		}
 	}
 	return nil
 }
@@ -288,6 +292,20 @@ Schmux can also auto-detect servers from terminal output URLs, but explicit
 registration is more reliable — especially for servers launched via ` + "`nohup`" + ` or ` + "`disown`" + `
 that run outside the session's process tree.
 
+## Messaging Other Agents
+
+To hand something to another agent session, append a message event. ` + "`to`" + ` is a
+session ID, a session nickname, or a group: ` + "`@workspace`" + ` (sessions in this
+workspace), ` + "`@repo`" + ` (sessions on this repo) or ` + "`@all`" + `:
+
+` + "```" + `
+echo '{"ts":"<ISO8601>","type":"message","to":"reviewer","text":"Auth refactor is pushed, ready for review"}' >> "$SCHMUX_EVENTS_FILE"
+` + "```" + `
+
+The text arrives as a single line prefixed with ` + "`[from <your nickname>]`" + `. Messages
+you receive look the same way. Messages are rate limited, so don't use them to chat
+back and forth — send one when there is something to act on.
+
 ## Friction Capture
 
 When you hit a wall — wrong command, missing file, failed build, wrong assumption —
@@ -494,6 +512,42 @@ func GitExclude(workspacePath string) error {
 	return ensureExcludeEntries(excludePath)
 }
 
+// JJExclude ensures the schmux exclude block is present in the info/exclude
+// of the git repo backing a jj workspace, which jj honors like git does.
+// Secondary jj workspaces have a .jj/repo file pointing at the shared repo
+// directory; its store/git_target file points at the git repo.
+func JJExclude(workspacePath string) error {
+	jjDir := filepath.Join(workspacePath, ".jj")
+	repoDir := filepath.Join(jjDir, "repo")
+	info, err := os.Stat(repoDir)
+	if err != nil {
+		return fmt.Errorf("failed to stat jj repo: %w", err)
+	}
+	if !info.IsDir() {
+		target, err := os.ReadFile(repoDir)
+		if err != nil {
+			return fmt.Errorf("failed to read jj repo pointer: %w", err)
+		}
+		repoDir = resolveRelative(jjDir, strings.TrimSpace(string(target)))
+	}
+
+	storeDir := filepath.Join(repoDir, "store")
+	target, err := os.ReadFile(filepath.Join(storeDir, "git_target"))
+	if err != nil {
+		return fmt.Errorf("failed to read jj git target: %w", err)
+	}
+	gitDir := resolveRelative(storeDir, strings.TrimSpace(string(target)))
+	return ensureExcludeEntries(filepath.Join(gitDir, "info", "exclude"))
+}
+
+// resolveRelative joins path onto base unless it is already absolute.
+func resolveRelative(base, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
 // ensureExcludeEntries ensures the schmux exclude block is present and
 // up-to-date in the given exclude file. It creates the file and parent
 // directories if they don't exist, preserves existing user entries, and
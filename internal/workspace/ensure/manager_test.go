@@ -220,6 +220,38 @@ func TestEnsureExcludeEntries_IncludesFencePatterns(t *testing.T) {
 	}
 }
 
+func TestJJExclude_SecondaryWorkspace(t *testing.T) {
+	tmpDir := t.TempDir()
+	// Colocated repo base: base/.jj/repo/store/git_target -> ../../../.git
+	base := filepath.Join(tmpDir, "repo.jj")
+	storeDir := filepath.Join(base, ".jj", "repo", "store")
+	if err := os.MkdirAll(storeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(storeDir, "git_target"), []byte("../../../.git"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Secondary workspace: ws/.jj/repo is a file pointing at the base's repo dir.
+	ws := filepath.Join(tmpDir, "repo-001")
+	if err := os.MkdirAll(filepath.Join(ws, ".jj"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ws, ".jj", "repo"), []byte("../../repo.jj/.jj/repo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := JJExclude(ws); err != nil {
+		t.Fatalf("JJExclude failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(base, ".git", "info", "exclude"))
+	if err != nil {
+		t.Fatalf("exclude not written to the backing git repo: %v", err)
+	}
+	if !strings.Contains(string(content), excludeMarkerStart) {
+		t.Error("should contain SCHMUX:BEGIN marker")
+	}
+}
+
 func TestEnsureExcludeEntries_AppendsToExisting(t *testing.T) {
 	tmpDir := t.TempDir()
 	infoDir := filepath.Join(tmpDir, "info")
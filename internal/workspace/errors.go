@@ -29,4 +29,24 @@ var (
 	// ErrRemoteBranchNotMerged means origin/<branch> holds commits that are not on
 	// the default branch, so deleting it would destroy them.
 	ErrRemoteBranchNotMerged = errors.New("remote branch has commits not on the default branch")
+
+	// ErrPushGatesRunning is returned by RunPushGates while the workspace's
+	// gates are already running for another push.
+	ErrPushGatesRunning = errors.New("push gates are already running for this workspace")
+
+	// ErrSetupFailed wraps the failing command when a workspace's setup
+	// commands fail; the workspace is marked failed.
+	ErrSetupFailed = errors.New("workspace setup failed")
+
+	// ErrDiskQuotaExceeded is returned instead of creating a workspace when
+	// disk usage is over the quota even after freeing what schmux can.
+	ErrDiskQuotaExceeded = errors.New("disk quota exceeded")
+
+	// ErrCheckpointsUnsupported is returned for remote and non-git workspaces.
+	ErrCheckpointsUnsupported = errors.New("checkpoints are only supported for local git workspaces")
+	// ErrCheckpointNotFound is returned when a checkpoint seq does not exist.
+	ErrCheckpointNotFound = errors.New("checkpoint not found")
+	// ErrInvalidCheckpointPath is returned for restore paths outside the
+	// workspace or missing from the checkpoint.
+	ErrInvalidCheckpointPath = errors.New("invalid checkpoint path")
 )
@@ -14,6 +14,7 @@ import (
 	"strings"
 
 	"github.com/sergeknystautas/schmux/internal/difftool"
+	"github.com/sergeknystautas/schmux/internal/state"
 )
 
 // runGitErr is a convenience wrapper around runGit that discards stdout.
@@ -503,6 +504,134 @@ func (m *Manager) GetDirtyFiles(ctx context.Context, dir string) ([]GitChangedFi
 	return files, nil
 }
 
+// GetBranchChanges returns every file the workspace's branch changes relative
+// to where it forked from the default branch: committed work, uncommitted
+// edits, and untracked files, each with line counts. Falls back to diffing
+// against HEAD when the default branch or fork point cannot be resolved.
+func (m *Manager) GetBranchChanges(ctx context.Context, workspaceID string) ([]GitChangedFile, error) {
+	ws, found := m.state.GetWorkspace(workspaceID)
+	if !found {
+		return nil, fmt.Errorf("workspace not found: %s", workspaceID)
+	}
+	dir := ws.Path
+	base := m.branchForkPoint(ctx, ws)
+
+	files := []GitChangedFile{}
+	output, err := m.runGit(ctx, workspaceID, RefreshTriggerExplicit, dir, "diff", "--numstat", "--no-renames", base)
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) < 3 {
+			continue
+		}
+		f := GitChangedFile{Path: parts[2], Status: "modified"}
+		f.LinesAdded, _ = strconv.Atoi(parts[0])
+		f.LinesRemoved, _ = strconv.Atoi(parts[1])
+		if _, err := os.Stat(filepath.Join(dir, f.Path)); os.IsNotExist(err) {
+			f.Status = "deleted"
+		} else if m.runGitErr(ctx, workspaceID, RefreshTriggerExplicit, dir, "cat-file", "-e", base+":"+f.Path) != nil {
+			f.Status = "added"
+		}
+		files = append(files, f)
+	}
+
+	untrackedOutput, err := m.runGit(ctx, workspaceID, RefreshTriggerExplicit, dir, "ls-files", "--others", "--exclude-standard")
+	if err == nil {
+		for _, filePath := range strings.Split(string(untrackedOutput), "\n") {
+			if filePath == "" {
+				continue
+			}
+			f := GitChangedFile{Path: filePath, Status: "untracked"}
+			if !difftool.IsBinaryFile(dir, filePath) {
+				f.LinesAdded, _ = countLinesCapped(filepath.Join(dir, filePath), 1024*1024)
+			}
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// GetBranchPatch returns the unified diff of the workspace's branch against
+// its fork point from the default branch, followed by the contents of
+// untracked files. The result is cut at the last line that fits in maxBytes
+// (0 = unlimited) with a trailing marker; untracked files larger than the
+// remaining budget are listed without their contents and never read.
+func (m *Manager) GetBranchPatch(ctx context.Context, workspaceID string, maxBytes int) (string, error) {
+	ws, found := m.state.GetWorkspace(workspaceID)
+	if !found {
+		return "", fmt.Errorf("workspace not found: %s", workspaceID)
+	}
+	dir := ws.Path
+	base := m.branchForkPoint(ctx, ws)
+
+	output, err := m.runGit(ctx, workspaceID, RefreshTriggerExplicit, dir, "diff", "--no-color", "--no-ext-diff", base)
+	if err != nil {
+		return "", fmt.Errorf("git diff failed: %w", err)
+	}
+	var b strings.Builder
+	b.Write(output)
+
+	untrackedOutput, err := m.runGit(ctx, workspaceID, RefreshTriggerExplicit, dir, "ls-files", "--others", "--exclude-standard")
+	if err == nil {
+		for _, filePath := range strings.Split(string(untrackedOutput), "\n") {
+			if filePath == "" || (maxBytes > 0 && b.Len() >= maxBytes) {
+				continue
+			}
+			fmt.Fprintf(&b, "--- /dev/null\n+++ b/%s (untracked)\n", filePath)
+			if difftool.IsBinaryFile(dir, filePath) {
+				b.WriteString("Binary file\n")
+				continue
+			}
+			info, err := os.Stat(filepath.Join(dir, filePath))
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			if maxBytes > 0 && info.Size() > int64(maxBytes-b.Len()) {
+				fmt.Fprintf(&b, "File too large (%d bytes)\n", info.Size())
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, filePath))
+			if err != nil {
+				continue
+			}
+			for _, line := range strings.SplitAfter(string(data), "\n") {
+				if line != "" {
+					b.WriteString("+" + line)
+				}
+			}
+			if len(data) > 0 && data[len(data)-1] != '\n' {
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	patch := b.String()
+	if maxBytes > 0 && len(patch) > maxBytes {
+		// Cut after a whole line so no diff line or UTF-8 sequence is split.
+		patch = patch[:strings.LastIndexByte(patch[:maxBytes], '\n')+1] + "... (truncated)\n"
+	}
+	return patch, nil
+}
+
+// branchForkPoint returns the commit a workspace's branch forked from the
+// default branch, or "HEAD" when it cannot be resolved.
+func (m *Manager) branchForkPoint(ctx context.Context, ws state.Workspace) string {
+	defaultBranch, err := m.GetDefaultBranch(ctx, ws.Repo)
+	if err != nil {
+		return "HEAD"
+	}
+	out, err := m.runGit(ctx, ws.ID, RefreshTriggerExplicit, ws.Path, "merge-base", "HEAD", "origin/"+defaultBranch)
+	if err != nil {
+		return "HEAD"
+	}
+	if sha := strings.TrimSpace(string(out)); sha != "" {
+		return sha
+	}
+	return "HEAD"
+}
+
 // hasCommonAncestor checks whether HEAD and the given ref share any common ancestor.
 // Returns true if `git merge-base HEAD <ref>` succeeds (i.e., the histories are related).
 // Returns false if there is no common ancestor (e.g., orphaned/force-pushed branch).
@@ -756,6 +885,24 @@ func (m *Manager) checkGitSafety(ctx context.Context, workspaceID string) (*VCSS
 		return status, nil
 	}
 
+	if w.VCS == "jj" {
+		// jj snapshots new files into the working-copy commit, so every
+		// change is a modified file; committed changes live in the shared
+		// repo base and survive disposal.
+		output, err := m.runCmd(ctx, "jj", workspaceID, RefreshTriggerExplicit, w.Path, "--no-pager", "diff", "--summary")
+		if err != nil {
+			status.Safe = false
+			status.Reason = fmt.Sprintf("jj diff failed: %v", err)
+			return status, nil
+		}
+		if trimmed := strings.TrimSpace(string(output)); trimmed != "" {
+			status.ModifiedFiles = len(strings.Split(trimmed, "\n"))
+			status.Safe = false
+			status.Reason = fmt.Sprintf("%d modified file(s)", status.ModifiedFiles)
+		}
+		return status, nil
+	}
+
 	output, err := m.runGit(ctx, workspaceID, RefreshTriggerExplicit, w.Path, "status", "--porcelain", "-u")
 	if err != nil {
 		// Git command failed - this might mean the repo is corrupt, treat as unsafe
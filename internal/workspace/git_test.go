@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/sergeknystautas/schmux/internal/config"
 	"github.com/sergeknystautas/schmux/internal/state"
@@ -1074,6 +1075,92 @@ func TestHasCommonAncestor_NormalBranch(t *testing.T) {
 	}
 }
 
+func TestGetBranchChanges(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	remoteDir := gitTestWorkTree(t)
+	tmpDir := t.TempDir()
+	cloneDir := filepath.Join(tmpDir, "clone")
+	runGit(t, tmpDir, "clone", remoteDir, "clone")
+
+	// One committed change, one uncommitted edit, one untracked file.
+	runGit(t, cloneDir, "checkout", "-b", "feature")
+	writeFile(t, cloneDir, "committed.txt", "a\nb\n")
+	runGit(t, cloneDir, "add", ".")
+	runGit(t, cloneDir, "commit", "-m", "feature commit")
+	writeFile(t, cloneDir, "README.md", "changed\n")
+	writeFile(t, cloneDir, "untracked.txt", "x\ny\nz\n")
+
+	statePath := filepath.Join(tmpDir, "state.json")
+	cfg := &config.Config{}
+	cfg.WorkspacePath = tmpDir
+	st := state.New(statePath, nil)
+	m := New(cfg, st, statePath, testLogger())
+	m.setDefaultBranch(remoteDir, "main")
+	if err := st.AddWorkspace(state.Workspace{ID: "ws-1", Repo: remoteDir, Branch: "feature", Path: cloneDir}); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := m.GetBranchChanges(context.Background(), "ws-1")
+	if err != nil {
+		t.Fatalf("GetBranchChanges() error = %v", err)
+	}
+	got := make(map[string]GitChangedFile)
+	for _, f := range files {
+		got[f.Path] = f
+	}
+	if f := got["committed.txt"]; f.Status != "added" || f.LinesAdded != 2 {
+		t.Errorf("committed.txt = %+v, want added with 2 lines", f)
+	}
+	if f := got["README.md"]; f.Status != "modified" || f.LinesAdded != 1 || f.LinesRemoved != 1 {
+		t.Errorf("README.md = %+v, want modified +1/-1", f)
+	}
+	if f := got["untracked.txt"]; f.Status != "untracked" || f.LinesAdded != 3 {
+		t.Errorf("untracked.txt = %+v, want untracked with 3 lines", f)
+	}
+	if len(files) != 3 {
+		t.Errorf("got %d files, want 3: %+v", len(files), files)
+	}
+
+	if _, err := m.GetBranchChanges(context.Background(), "missing"); err == nil {
+		t.Error("expected error for unknown workspace")
+	}
+
+	patch, err := m.GetBranchPatch(context.Background(), "ws-1", 0)
+	if err != nil {
+		t.Fatalf("GetBranchPatch() error = %v", err)
+	}
+	for _, want := range []string{"+++ b/committed.txt", "+changed", "+++ b/untracked.txt (untracked)", "+z"} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("patch missing %q:\n%s", want, patch)
+		}
+	}
+	if short, _ := m.GetBranchPatch(context.Background(), "ws-1", 10); !strings.HasSuffix(short, "(truncated)\n") {
+		t.Errorf("truncated patch = %q", short)
+	}
+
+	// The cut falls after the last whole line, and an untracked file over
+	// the remaining budget is listed without its contents.
+	writeFile(t, cloneDir, "big.txt", strings.Repeat("é", len(patch)))
+	short, err := m.GetBranchPatch(context.Background(), "ws-1", len(patch)+100)
+	if err != nil {
+		t.Fatalf("GetBranchPatch() error = %v", err)
+	}
+	if !strings.Contains(short, "+++ b/big.txt (untracked)\nFile too large (") || strings.Contains(short, "+é") {
+		t.Errorf("oversized untracked file not stubbed:\n%s", short)
+	}
+	for _, limit := range []int{len(patch) / 2, len(patch) - 1} {
+		cut, _ := m.GetBranchPatch(context.Background(), "ws-1", limit)
+		body := strings.TrimSuffix(cut, "... (truncated)\n")
+		if body == cut || len(body) > limit || (body != "" && !strings.HasSuffix(body, "\n")) || !utf8.ValidString(body) {
+			t.Errorf("limit %d: patch not cut at a line boundary: %q", limit, cut)
+		}
+	}
+}
+
 // TestHasCommonAncestor_OrphanBranch verifies that an orphan branch (no shared history)
 // returns false from hasCommonAncestor.
 func TestHasCommonAncestor_OrphanBranch(t *testing.T) {
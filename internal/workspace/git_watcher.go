@@ -41,6 +41,12 @@ type GitWatcher struct {
 	lastStatusHash   map[string]string
 	lastStatusHashMu sync.Mutex
 
+	// commitFn, when set, is told about commits made on top of a
+	// workspace's previously seen HEAD. lastHead holds that HEAD.
+	commitFn   func(workspaceID, hash, subject string)
+	lastHead   map[string]string
+	lastHeadMu sync.Mutex
+
 	// suppressedPaths tracks short-lived path-prefix suppressions for fsnotify
 	// events caused by schmux's own git commands. Keys are cleaned absolute-ish
 	// path prefixes (e.g. a gitdir or shared refs dir).
@@ -82,6 +88,7 @@ func NewGitWatcher(cfg *config.Config, mgr *Manager, broadcast func(), logger *l
 		watchedPaths:    make(map[string][]string),
 		debounceTimers:  make(map[string]*time.Timer),
 		lastStatusHash:  make(map[string]string),
+		lastHead:        make(map[string]string),
 		suppressedPaths: make(map[string]suppressionState),
 		stopCh:          make(chan struct{}),
 	}
@@ -110,6 +117,13 @@ func (gw *GitWatcher) Stop() {
 	})
 }
 
+// SetCommitFn sets a callback invoked when a refresh finds a new commit whose
+// parent is the HEAD seen at the previous refresh. Branch switches, resets and
+// rebases move HEAD without reporting a commit. Call before adding workspaces.
+func (gw *GitWatcher) SetCommitFn(fn func(workspaceID, hash, subject string)) {
+	gw.commitFn = fn
+}
+
 // AddWorkspace adds filesystem watches for a workspace's git metadata.
 func (gw *GitWatcher) AddWorkspace(workspaceID, workspacePath string) {
 	gitDir, err := resolveGitDir(workspacePath)
@@ -129,6 +143,16 @@ func (gw *GitWatcher) AddWorkspace(workspaceID, workspacePath string) {
 	logsDir := filepath.Join(gitDir, "logs")
 	gw.watchRecursive(logsDir, workspaceID)
 
+	if gw.commitFn != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), gw.cfg.GitStatusTimeout())
+		if head, err := gitOutput(ctx, workspacePath, "rev-parse", "HEAD"); err == nil {
+			gw.lastHeadMu.Lock()
+			gw.lastHead[workspaceID] = head
+			gw.lastHeadMu.Unlock()
+		}
+		cancel()
+	}
+
 	gw.logger.Debug("watching", "workspace_id", workspaceID, "gitdir", gitDir)
 }
 
@@ -151,6 +175,10 @@ func (gw *GitWatcher) RemoveWorkspace(workspaceID string) {
 		gw.watcher.Remove(path)
 	}
 
+	gw.lastHeadMu.Lock()
+	delete(gw.lastHead, workspaceID)
+	gw.lastHeadMu.Unlock()
+
 	gw.debounceTimersMu.Lock()
 	if t, ok := gw.debounceTimers[workspaceID]; ok {
 		t.Stop()
@@ -422,6 +450,10 @@ func (gw *GitWatcher) refreshWorkspace(workspaceID string) {
 		return
 	}
 
+	if gw.commitFn != nil {
+		gw.checkNewCommit(ctx, workspaceID, w.Path)
+	}
+
 	// Hash the git status fields
 	newHash := fmt.Sprintf("%v|%v|%v|%v|%v|%v|%v|%v|%v|%v|%v|%v",
 		w.Dirty, w.Ahead, w.Behind,
@@ -444,6 +476,30 @@ func (gw *GitWatcher) refreshWorkspace(workspaceID string) {
 	}
 }
 
+// checkNewCommit compares HEAD with the one seen at the previous refresh and
+// reports it to commitFn if it is a child of it. Without a previous HEAD
+// (the repo had no commits when added) it only records HEAD.
+func (gw *GitWatcher) checkNewCommit(ctx context.Context, workspaceID, path string) {
+	out, err := gitOutput(ctx, path, "log", "-1", "--format=%H%x00%P%x00%s")
+	if err != nil {
+		return
+	}
+	fields := strings.SplitN(out, "\x00", 3)
+	if len(fields) != 3 {
+		return
+	}
+	head, parents, subject := fields[0], strings.Fields(fields[1]), fields[2]
+
+	gw.lastHeadMu.Lock()
+	prev, seen := gw.lastHead[workspaceID]
+	gw.lastHead[workspaceID] = head
+	gw.lastHeadMu.Unlock()
+
+	if seen && head != prev && len(parents) > 0 && parents[0] == prev {
+		gw.commitFn(workspaceID, head, subject)
+	}
+}
+
 // addWatch adds a filesystem watch and maps the path to a workspace ID.
 func (gw *GitWatcher) addWatch(path string, workspaceID string) {
 	if _, err := os.Stat(path); err != nil {
@@ -1,6 +1,7 @@
 package workspace
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"sync/atomic"
@@ -427,3 +428,35 @@ func TestStopIdempotent(t *testing.T) {
 	gw.Stop()
 	gw.Stop()
 }
+
+func TestCheckNewCommit(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	runGit(t, dir, "-c", "user.email=t@example.com", "-c", "user.name=t", "commit", "-q", "--allow-empty", "-m", "first")
+
+	gw := NewGitWatcher(&config.Config{}, nil, nil, testLogger())
+	if gw == nil {
+		t.Fatal("NewGitWatcher() returned nil")
+	}
+	defer gw.Stop()
+	var reported []string
+	gw.SetCommitFn(func(workspaceID, hash, subject string) {
+		reported = append(reported, workspaceID+" "+subject)
+	})
+	gw.AddWorkspace("ws-1", dir)
+	ctx := context.Background()
+
+	// No new commit yet.
+	gw.checkNewCommit(ctx, "ws-1", dir)
+	runGit(t, dir, "-c", "user.email=t@example.com", "-c", "user.name=t", "commit", "-q", "--allow-empty", "-m", "add feature")
+	gw.checkNewCommit(ctx, "ws-1", dir)
+
+	// Moving HEAD back is not a commit.
+	runGit(t, dir, "reset", "-q", "--hard", "HEAD~1")
+	gw.checkNewCommit(ctx, "ws-1", dir)
+
+	if want := []string{"ws-1 add feature"}; len(reported) != 1 || reported[0] != want[0] {
+		t.Errorf("reported %q, want %q", reported, want)
+	}
+}
@@ -39,6 +39,9 @@ type LinearSyncResult struct {
 	Message         string   `json:"message,omitempty"`          // Human-readable message (e.g., error context)
 	NeedsConfirm    bool     `json:"needs_confirm,omitempty"`    // True if push needs confirmation before proceeding
 	DivergedCommits []string `json:"diverged_commits,omitempty"` // Commits on origin that would be overwritten
+	// PushGates is the gate run for this push. A failed run without an
+	// override blocks the push.
+	PushGates *contracts.PushGateRun `json:"push_gates,omitempty"`
 }
 
 // ConflictResolution represents a single conflict that was resolved during rebase.
@@ -121,6 +124,8 @@ type WorkspaceVCS interface {
 	UpdateVCSStatus(ctx context.Context, workspaceID string) (*state.Workspace, error)
 	UpdateAllVCSStatus(ctx context.Context)
 	GetWorkspaceChangedFiles(ctx context.Context, workspaceID string) ([]GitChangedFile, error)
+	GetBranchChanges(ctx context.Context, workspaceID string) ([]GitChangedFile, error)
+	GetBranchPatch(ctx context.Context, workspaceID string, maxBytes int) (string, error)
 	GetDefaultBranch(ctx context.Context, repoURL string) (string, error)
 	GetGitGraph(ctx context.Context, workspaceID string, maxTotal int, mainContext int) (*contracts.CommitGraphResponse, error)
 	GetCommitDetail(ctx context.Context, workspaceID, commitHash string) (*contracts.CommitDetailResponse, error)
@@ -132,11 +137,15 @@ type WorkspaceVCS interface {
 	GetBranchDivergence(ctx context.Context, workspaceID string) (*contracts.BranchDivergenceResponse, error)
 	PushToBranch(ctx context.Context, workspaceID string, confirm bool, expectedLocal, expectedRemote string) (*LinearSyncResult, error)
 	PushCommits(ctx context.Context, workspaceID, hash, target string, perCommit, confirm bool) (*contracts.PushCommitsResult, error)
+	RunPushGates(ctx context.Context, workspaceID, trigger string, override bool) (*contracts.PushGateRun, error)
 	DeleteRemoteBranch(ctx context.Context, workspaceID string) error
 	CheckoutPR(ctx context.Context, pr contracts.PullRequest) (*state.Workspace, error)
 	DetectGitHubConnect(ctx context.Context, workspaceID string) (*ConnectDetection, error)
 	RunGitHubConnect(ctx context.Context, workspaceID string, req contracts.GitHubConnectRequest, gh GitHubRepoCreator) (*contracts.GitHubConnectResult, error)
 	GetRemoteBranchHead(ctx context.Context, workspaceID string) (RemoteBranchHead, error)
+	ListCheckpoints(ctx context.Context, workspaceID string) ([]contracts.Checkpoint, error)
+	CreateCheckpoint(ctx context.Context, workspaceID, reason string) (*contracts.Checkpoint, error)
+	RestoreCheckpoint(ctx context.Context, workspaceID string, seq int, path string) (*contracts.CheckpointRestoreResponse, error)
 }
 
 // WorkspaceInfra defines infrastructure and overlay operations.
@@ -147,6 +156,9 @@ type WorkspaceInfra interface {
 	RefreshOverlay(ctx context.Context, workspaceID string) error
 	EnsureOverlayDirs(repos []config.Repo) error
 	CleanupUnusedRepoBases(ctx context.Context) error
+	SubscribeSetupOutput(workspaceID string) (backlog []byte, output <-chan []byte, unsubscribe func(), ok bool)
+	DiskUsage() contracts.DiskUsage
+	AutoCleanupReport(ctx context.Context) contracts.AutoCleanupReport
 }
 
 // WorkspaceManager defines the full interface for workspace operations.
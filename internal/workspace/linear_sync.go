@@ -42,6 +42,7 @@ func (m *Manager) LinearSyncFromDefault(ctx context.Context, workspaceID string)
 	if !found {
 		return nil, fmt.Errorf("workspace not found: %s", workspaceID)
 	}
+	defer m.advanceBaseSHA(ctx, workspaceID, m.headSHA(ctx, w))
 
 	// Get the default branch
 	defaultBranch, err := m.GetDefaultBranch(ctx, w.Repo)
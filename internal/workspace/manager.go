@@ -16,6 +16,7 @@ import (
 	"github.com/sergeknystautas/schmux/internal/config"
 	"github.com/sergeknystautas/schmux/internal/difftool"
 	"github.com/sergeknystautas/schmux/internal/models"
+	"github.com/sergeknystautas/schmux/internal/schmuxdir"
 	"github.com/sergeknystautas/schmux/internal/state"
 	"github.com/sergeknystautas/schmux/internal/telemetry"
 	"github.com/sergeknystautas/schmux/internal/vcs"
@@ -61,6 +62,7 @@ type Manager struct {
 	tabCloseHooks          map[string]TabCloseHook                      // kind -> hook for tab close cleanup
 	compoundReconcile      func(workspaceID string)                     // reconcile overlay before dispose
 	syncProgressFn         func(workspaceID string, current, total int) // optional, called during LinearSyncFromDefault
+	lifecycleFn            func(event string, w state.Workspace)        // optional, called after a workspace is created or disposed
 	telemetry              telemetry.Telemetry                          // optional, for usage tracking
 	ioTelemetry            *IOWorkspaceTelemetry                        // optional, for git command I/O telemetry
 	ensuredQueryRepos      map[string]bool                              // repoURL -> true once origin query repo is validated
@@ -70,6 +72,14 @@ type Manager struct {
 	backends               map[string]VCSBackend
 	remoteRunner           RemoteCommandRunner // optional, for remote VCS status polling
 	remotePollCounter      int                 // counts poll cycles; remote workspaces are polled every Nth cycle
+	checkpoints            checkpointState     // automatic working-tree checkpoints
+	pushGates              pushGateRuns        // workspaces whose push gates are running
+	setupStreams           setupStreams        // live output of running setup commands
+	warmPool               warmPool            // warm pool work in flight
+	diskUsage              diskUsage           // measured directory sizes, for the disk quota
+	recordingsDir          string              // timelapse recordings, pruned under disk quota pressure
+	autoCleanup            autoCleanup         // grace periods of workspaces eligible for auto-cleanup
+	shutdownCtx            context.Context     // cancelled on daemon shutdown; background work started here runs under it
 }
 
 // New creates a new workspace manager.
@@ -87,6 +97,8 @@ func New(cfg *config.Config, st state.StateStore, statePath string, logger *log.
 		ensuredQueryRepos:      make(map[string]bool),
 		defaultBranchRefreshAt: make(map[string]time.Time),
 		randSuffix:             defaultRandSuffix,
+		recordingsDir:          schmuxdir.RecordingsDir(),
+		shutdownCtx:            context.Background(),
 	}
 	m.gitBackend = NewGitBackend(m)
 	saplingBackend := NewSaplingBackend(m, cfg.SaplingCommands)
@@ -94,9 +106,14 @@ func New(cfg *config.Config, st state.StateStore, statePath string, logger *log.
 		"git":     m.gitBackend,
 		"":        m.gitBackend,
 		"sapling": saplingBackend,
+		"jj":      NewJJBackend(m),
 	}
 	for _, w := range st.GetWorkspaces() {
 		m.RefreshWorkspaceConfig(w)
+		recoveredGates := recoverPushGateRun(w.PushGates)
+		if recoverSetupRun(&w) || recoveredGates {
+			st.UpdateWorkspace(w)
+		}
 	}
 	return m
 }
@@ -116,7 +133,7 @@ func (m *Manager) repoUsesWorktrees(repoConfig config.Repo) bool {
 	switch repoConfig.VCS {
 	case "git-clone":
 		return false
-	case "sapling":
+	case "sapling", "jj":
 		return false
 	default:
 		return m.config.UseWorktrees()
@@ -224,6 +241,26 @@ func (m *Manager) SetSyncProgressFn(fn func(workspaceID string, current, total i
 	m.syncProgressFn = fn
 }
 
+// Workspace lifecycle events passed to the SetLifecycleFn callback.
+const (
+	LifecycleCreated  = "created"
+	LifecycleDisposed = "disposed"
+)
+
+// SetLifecycleFn sets a callback invoked after a workspace is created (or a
+// recycled one is handed out again) and after one is disposed or recycled.
+func (m *Manager) SetLifecycleFn(fn func(event string, w state.Workspace)) {
+	m.lifecycleFn = fn
+}
+
+// notifyLifecycle reports a lifecycle event. Safe to call with no callback set.
+// Warm pool workspaces are not reported until a spawn claims one.
+func (m *Manager) notifyLifecycle(event string, w state.Workspace) {
+	if m.lifecycleFn != nil && w.Status != state.WorkspaceStatusReady {
+		m.lifecycleFn(event, w)
+	}
+}
+
 // SetBroadcastFn sets the callback invoked after tab mutations to broadcast state.
 func (m *Manager) SetBroadcastFn(fn func()) {
 	m.broadcastFn = fn
@@ -263,6 +300,12 @@ func (m *Manager) SetIOWorkspaceTelemetry(tel *IOWorkspaceTelemetry) {
 	m.ioTelemetry = tel
 }
 
+// SetShutdownContext sets the context background work started by the
+// manager, such as refilling a warm pool after a claim, runs under.
+func (m *Manager) SetShutdownContext(ctx context.Context) {
+	m.shutdownCtx = ctx
+}
+
 // SetRemoteRunner sets the remote command runner for VCS status polling on remote workspaces.
 func (m *Manager) SetRemoteRunner(r RemoteCommandRunner) {
 	m.remoteRunner = r
@@ -457,71 +500,36 @@ func (m *Manager) GetOrCreateWithLabel(ctx context.Context, repoURL, branch, lab
 	lock.Lock()
 	defer lock.Unlock()
 
-	// Tier 0: Reuse a recyclable workspace for the same repo.
-	if m.config.RecycleWorkspaces {
-		for _, w := range m.state.GetWorkspaces() {
-			if w.Status != state.WorkspaceStatusRecyclable || w.Repo != repoURL {
-				continue
-			}
-			// Verify directory still exists
-			if _, err := os.Stat(w.Path); os.IsNotExist(err) {
-				m.logger.Warn("recyclable workspace directory missing, cleaning up", "id", w.ID)
-				m.state.RemoveWorkspace(w.ID)
-				m.state.Save()
-				continue
-			}
-			// Divergence safety check: prevent cross-branch commit pollution.
-			// Skip when the workspace already has the target branch — those
-			// commits are what the caller wants, and remote refs may be stale
-			// (recyclable workspaces are excluded from VCS polling).
-			if w.Branch != branch && IsGitVCS(w.VCS) && !strings.HasPrefix(repoURL, "local:") && !m.isUpToDateWithDefault(ctx, w.Path, repoURL) {
-				m.logger.Info("recyclable workspace diverged, skipping", "id", w.ID, "branch", w.Branch)
-				continue
-			}
-			m.logger.Info("reusing recyclable workspace", "id", w.ID, "old_branch", w.Branch, "new_branch", branch)
-
-			if err := m.prepare(ctx, w.ID, branch); err != nil {
-				m.logger.Warn("failed to prepare recyclable workspace, skipping", "id", w.ID, "err", err)
-				continue
-			}
-			// Re-copy overlay files and clean up stale ones from previous lifecycle.
-			// Overlay files are gitignored, so git clean -fd does not remove them.
-			// Without this cleanup, stale files could influence the new agent or
-			// propagate outdated content through the compound system.
-			if repoConfig, found := m.findRepoByURL(repoURL); found {
-				oldManifest := w.OverlayManifest
-				freshManifest, err := m.copyOverlayFiles(ctx, repoConfig.Name, w.Path)
-				if err != nil {
-					m.logger.Warn("failed to re-copy overlay files", "err", err)
-				} else {
-					if freshManifest == nil {
-						freshManifest = make(map[string]string)
-					}
-					declaredPaths := m.config.GetOverlayPaths(repoConfig.Name)
-					cleanStaleOverlayFiles(oldManifest, freshManifest, w.Path, declaredPaths, m.logger)
-					m.state.UpdateOverlayManifest(w.ID, freshManifest)
-				}
-			}
-			// Promote to running
-			w.Branch = branch
-			w.Status = state.WorkspaceStatusRunning
-			if err := m.state.UpdateWorkspace(w); err != nil {
-				return nil, fmt.Errorf("failed to update workspace: %w", err)
-			}
-			m.state.Save()
-			// Re-add filesystem watches
-			if m.gitWatcher != nil {
-				m.gitWatcher.AddWorkspace(w.ID, w.Path)
-			}
-			// Auto-sync from default branch so the recycled workspace starts at latest main.
-			m.autoSyncFromDefault(ctx, w.ID)
-			return &w, nil
+	// Hand out a ready workspace from the repo's warm pool when there is one.
+	if !strings.HasPrefix(repoURL, "local:") {
+		if w, ok, err := m.claimReady(ctx, repoURL, branch); err != nil {
+			return nil, err
+		} else if ok {
+			return w, nil
 		}
 	}
 
+	// Tier 0: Reuse a recyclable workspace for the same repo.
+	if w, ok, err := m.reuseRecyclable(ctx, repoURL, branch); err != nil {
+		return nil, err
+	} else if ok {
+		return w, nil
+	}
+	lock.Unlock()
+
+	// Free space if over the disk quota. This runs after Tier 0, which would
+	// reuse the recyclable workspaces eviction purges, and without the repo
+	// lock, since it takes other repos' locks; the error only fails the spawn
+	// if it has to create.
+	quotaErr := m.EnforceDiskQuota(ctx)
+	lock.Lock()
+
 	// Handle local repositories — moved after Tier 0 so local repos can be recycled,
 	// but still before Tiers 1–2 which don't apply to local repos.
 	if strings.HasPrefix(repoURL, "local:") {
+		if quotaErr != nil {
+			return nil, quotaErr
+		}
 		repoName := strings.TrimPrefix(repoURL, "local:")
 		return m.CreateLocalRepo(ctx, repoName, branch)
 	}
@@ -537,6 +545,7 @@ func (m *Manager) GetOrCreateWithLabel(ctx context.Context, repoURL, branch, lab
 			// Check if workspace has active sessions
 			if !m.hasActiveSessions(w.ID) {
 				m.logger.Info("reusing existing", "id", w.ID, "path", w.Path, "branch", branch)
+				before := m.headSHA(ctx, w)
 				// Prepare the workspace (fetch/pull/clean)
 				if err := m.prepare(ctx, w.ID, branch); err != nil {
 					return nil, fmt.Errorf("failed to prepare workspace: %w", err)
@@ -556,6 +565,10 @@ func (m *Manager) GetOrCreateWithLabel(ctx context.Context, repoURL, branch, lab
 				}
 				// Auto-sync from default branch so the reused workspace starts at latest main.
 				m.autoSyncFromDefault(ctx, w.ID)
+				m.advanceBaseSHA(ctx, w.ID, before)
+				if err := m.setupWorkspace(ctx, &w); err != nil {
+					return nil, err
+				}
 				return &w, nil
 			}
 		}
@@ -564,8 +577,9 @@ func (m *Manager) GetOrCreateWithLabel(ctx context.Context, repoURL, branch, lab
 	// Try to find any unused workspace for this repo (different branch OK).
 	// Only consider workspaces that are NOT actively running — running workspaces
 	// are part of the user's working set and must not be silently hijacked.
+	// Ready workspaces belong to the warm pool, which claimReady hands out.
 	for _, w := range m.state.GetWorkspaces() {
-		if w.Repo == repoURL && w.Status != state.WorkspaceStatusRunning {
+		if w.Repo == repoURL && w.Status != state.WorkspaceStatusRunning && w.Status != state.WorkspaceStatusReady {
 			// Check if workspace has active sessions
 			if !m.hasActiveSessions(w.ID) {
 				// Check if workspace directory still exists
@@ -623,12 +637,19 @@ func (m *Manager) GetOrCreateWithLabel(ctx context.Context, repoURL, branch, lab
 				}
 				// Auto-sync from default branch so the reused workspace starts at latest main.
 				m.autoSyncFromDefault(ctx, w.ID)
+				m.setBaseSHA(ctx, w.ID)
+				if err := m.setupWorkspace(ctx, &w); err != nil {
+					return nil, err
+				}
 				return &w, nil
 			}
 		}
 	}
 
 	// Create a new workspace
+	if quotaErr != nil {
+		return nil, quotaErr
+	}
 	w, err := m.create(ctx, repoURL, branch, label)
 	if err != nil {
 		// If create failed because a recyclable worktree holds the branch,
@@ -659,10 +680,88 @@ func (m *Manager) GetOrCreateWithLabel(ctx context.Context, repoURL, branch, lab
 
 	// Auto-sync from default branch so the new workspace starts at latest main.
 	m.autoSyncFromDefault(ctx, w.ID)
+	m.setBaseSHA(ctx, w.ID)
 
+	if err := m.setupWorkspace(ctx, w); err != nil {
+		return nil, err
+	}
+	m.notifyLifecycle(LifecycleCreated, *w)
 	return w, nil
 }
 
+// reuseRecyclable is Tier 0: it reuses a recyclable workspace of the repo
+// for branch. ok is false when there is none to reuse. The caller holds the
+// repo lock.
+func (m *Manager) reuseRecyclable(ctx context.Context, repoURL, branch string) (*state.Workspace, bool, error) {
+	if !m.config.RecycleWorkspaces {
+		return nil, false, nil
+	}
+	for _, w := range m.state.GetWorkspaces() {
+		if w.Status != state.WorkspaceStatusRecyclable || w.Repo != repoURL {
+			continue
+		}
+		// Verify directory still exists
+		if _, err := os.Stat(w.Path); os.IsNotExist(err) {
+			m.logger.Warn("recyclable workspace directory missing, cleaning up", "id", w.ID)
+			m.state.RemoveWorkspace(w.ID)
+			m.state.Save()
+			continue
+		}
+		// Divergence safety check: prevent cross-branch commit pollution.
+		// Skip when the workspace already has the target branch — those
+		// commits are what the caller wants, and remote refs may be stale
+		// (recyclable workspaces are excluded from VCS polling).
+		if w.Branch != branch && IsGitVCS(w.VCS) && !strings.HasPrefix(repoURL, "local:") && !m.isUpToDateWithDefault(ctx, w.Path, repoURL) {
+			m.logger.Info("recyclable workspace diverged, skipping", "id", w.ID, "branch", w.Branch)
+			continue
+		}
+		m.logger.Info("reusing recyclable workspace", "id", w.ID, "old_branch", w.Branch, "new_branch", branch)
+
+		if err := m.prepare(ctx, w.ID, branch); err != nil {
+			m.logger.Warn("failed to prepare recyclable workspace, skipping", "id", w.ID, "err", err)
+			continue
+		}
+		// Re-copy overlay files and clean up stale ones from previous lifecycle.
+		// Overlay files are gitignored, so git clean -fd does not remove them.
+		// Without this cleanup, stale files could influence the new agent or
+		// propagate outdated content through the compound system.
+		if repoConfig, found := m.findRepoByURL(repoURL); found {
+			oldManifest := w.OverlayManifest
+			freshManifest, err := m.copyOverlayFiles(ctx, repoConfig.Name, w.Path)
+			if err != nil {
+				m.logger.Warn("failed to re-copy overlay files", "err", err)
+			} else {
+				if freshManifest == nil {
+					freshManifest = make(map[string]string)
+				}
+				declaredPaths := m.config.GetOverlayPaths(repoConfig.Name)
+				cleanStaleOverlayFiles(oldManifest, freshManifest, w.Path, declaredPaths, m.logger)
+				m.state.UpdateOverlayManifest(w.ID, freshManifest)
+			}
+		}
+		// Promote to running
+		w.Branch = branch
+		w.Status = state.WorkspaceStatusRunning
+		if err := m.state.UpdateWorkspace(w); err != nil {
+			return nil, false, fmt.Errorf("failed to update workspace: %w", err)
+		}
+		m.state.Save()
+		// Re-add filesystem watches
+		if m.gitWatcher != nil {
+			m.gitWatcher.AddWorkspace(w.ID, w.Path)
+		}
+		// Auto-sync from default branch so the recycled workspace starts at latest main.
+		m.autoSyncFromDefault(ctx, w.ID)
+		m.setBaseSHA(ctx, w.ID)
+		if err := m.setupWorkspace(ctx, &w); err != nil {
+			return nil, false, err
+		}
+		m.notifyLifecycle(LifecycleCreated, w)
+		return &w, true, nil
+	}
+	return nil, false, nil
+}
+
 // create creates a new workspace directory for the given repoURL using git worktrees.
 // The label parameter is persisted on the resulting workspace as a human-friendly
 // display label (used by sapling workspaces today; empty for git workspaces).
@@ -884,6 +983,7 @@ func (m *Manager) CreateLocalRepo(ctx context.Context, repoName, branch string)
 
 	// Track workspace creation
 	m.trackWorkspaceCreated(w.ID, repoURL, branch)
+	m.notifyLifecycle(LifecycleCreated, w)
 
 	return &w, nil
 }
@@ -906,6 +1006,20 @@ func (m *Manager) prepare(ctx context.Context, workspaceID, branch string) error
 		m.logger.Info("prepared (sapling, no-op)", "id", workspaceID)
 		return nil
 	}
+	if w.VCS == "jj" {
+		jb, ok := m.backendForWorkspace(workspaceID).(*JJBackend)
+		if !ok {
+			return fmt.Errorf("no jj backend for workspace: %s", workspaceID)
+		}
+		if err := jb.Fetch(ctx, w.Path); err != nil {
+			m.logger.Warn("jj git fetch failed", "err", err)
+		}
+		if err := jb.checkoutBranch(ctx, w.Path, branch); err != nil {
+			return err
+		}
+		m.logger.Info("prepared (jj)", "id", workspaceID, "branch", branch)
+		return nil
+	}
 
 	hasOrigin := m.gitHasOriginRemote(ctx, w.Path)
 	if hasOrigin {
@@ -954,6 +1068,8 @@ func (m *Manager) prepare(ctx context.Context, workspaceID, branch string) error
 		m.logger.Debug("no origin remote ref, skipping pull", "branch", branch)
 	}
 
+	m.invalidateDiskUsage(w.Path)
+	m.autoCleanup.reset(workspaceID)
 	m.logger.Info("prepared", "id", workspaceID, "branch", branch)
 	return nil
 }
@@ -1217,6 +1333,11 @@ func (m *Manager) updateGitStatusWithTriggerAndRound(ctx context.Context, worksp
 		return nil, fmt.Errorf("failed to update workspace in state: %w", err)
 	}
 
+	// Checkpoint uncommitted work once it settles; committed work is already recoverable.
+	if dirty && (dirty != w.Dirty || linesAdded != w.LinesAdded || linesRemoved != w.LinesRemoved || filesChanged != w.FilesChanged) {
+		m.scheduleCheckpoint(workspaceID)
+	}
+
 	return &fresh, nil
 }
 
@@ -1332,7 +1453,7 @@ func (m *Manager) UpdateAllVCSStatus(ctx context.Context) {
 	var localWorkspaces []state.Workspace
 	var remoteWorkspaces []state.Workspace
 	for _, w := range workspaces {
-		if w.Status == state.WorkspaceStatusRecyclable || w.Status == state.WorkspaceStatusDisposing {
+		if w.Status == state.WorkspaceStatusRecyclable || w.Status == state.WorkspaceStatusDisposing || w.Status == state.WorkspaceStatusReady {
 			continue
 		}
 		if w.RemoteHostID != "" {
@@ -1555,7 +1676,9 @@ func (m *Manager) dispose(ctx context.Context, workspaceID string, force bool, s
 		if err := m.CleanupUnusedRepoBases(ctx); err != nil {
 			m.logger.Warn("failed to clean up unused repo bases", "err", err)
 		}
+		m.checkpoints.forget(workspaceID)
 		m.logger.Info("disposed (remote)", "id", workspaceID)
+		m.notifyLifecycle(LifecycleDisposed, w)
 		return nil
 	}
 
@@ -1600,6 +1723,11 @@ func (m *Manager) dispose(ctx context.Context, workspaceID string, force bool, s
 		m.gitWatcher.RemoveWorkspace(workspaceID)
 	}
 
+	// Drop checkpoint refs; for worktrees they would outlive the directory
+	if dirExists && vcsExists {
+		m.deleteCheckpoints(ctx, w)
+	}
+
 	// Clean up diff temp dirs (in OS temp, not workspace — no backup churn)
 	if err := difftool.CleanupWorkspaceTempDirs(workspaceID); err != nil {
 		m.logger.Warn("failed to cleanup diff temp dirs", "id", workspaceID, "err", err)
@@ -1610,6 +1738,7 @@ func (m *Manager) dispose(ctx context.Context, workspaceID string, force bool, s
 	// would create a stale entry that Tier 0 can't reuse.
 	if m.config.RecycleWorkspaces && !skipRecycling && dirExists && vcsExists {
 		w.Status = state.WorkspaceStatusRecyclable
+		w.LastUsedAt = time.Now()
 		if err := m.state.UpdateWorkspace(w); err != nil {
 			return fmt.Errorf("failed to mark workspace as recyclable: %w", err)
 		}
@@ -1631,6 +1760,7 @@ func (m *Manager) dispose(ctx context.Context, workspaceID string, force bool, s
 		m.workspaceGatesMu.Unlock()
 
 		m.logger.Info("recycled (directory preserved)", "id", workspaceID)
+		m.notifyLifecycle(LifecycleDisposed, w)
 		return nil
 	}
 
@@ -1645,9 +1775,9 @@ func (m *Manager) dispose(ctx context.Context, workspaceID string, force bool, s
 			} else {
 				m.logger.Info("removed empty zombie workspace directory", "path", w.Path)
 			}
-		} else if w.VCS == "sapling" {
+		} else if w.VCS == "sapling" || w.VCS == "jj" {
 			if err := backend.RemoveWorkspace(ctx, w.Path); err != nil {
-				m.logger.Warn("sapling remove failed, falling back to rm", "err", err)
+				m.logger.Warn(w.VCS+" remove failed, falling back to rm", "err", err)
 				if rmErr := os.RemoveAll(w.Path); rmErr != nil {
 					return fmt.Errorf("failed to delete workspace directory: %w", rmErr)
 				}
@@ -1683,6 +1813,13 @@ func (m *Manager) dispose(ctx context.Context, workspaceID string, force bool, s
 				m.cleanupLocalBranch(ctx, worktreeBasePath, w)
 			}
 		}
+	} else if w.VCS == "jj" {
+		// The directory is gone; forget the jj workspace it belonged to.
+		if rb, found := m.state.GetRepoBaseByURL(w.Repo); found {
+			if err := backend.PruneStale(ctx, rb.Path); err != nil {
+				m.logger.Warn("failed to prune jj workspaces", "err", err)
+			}
+		}
 	} else if w.VCS != "sapling" {
 		worktreeBasePath, worktreeBaseErr := m.findWorktreeBaseForWorkspace(w)
 		if worktreeBaseErr == nil {
@@ -1705,6 +1842,7 @@ func (m *Manager) dispose(ctx context.Context, workspaceID string, force bool, s
 	if err := m.CleanupUnusedRepoBases(ctx); err != nil {
 		m.logger.Warn("failed to clean up unused repo bases", "err", err)
 	}
+	m.diskUsage.forget(w.Path)
 
 	// Clean up per-workspace maps to prevent unbounded growth
 	m.workspaceConfigsMu.Lock()
@@ -1719,7 +1857,10 @@ func (m *Manager) dispose(ctx context.Context, workspaceID string, force bool, s
 	delete(m.workspaceGates, workspaceID)
 	m.workspaceGatesMu.Unlock()
 
+	m.checkpoints.forget(workspaceID)
+
 	m.logger.Info("disposed", "id", workspaceID)
+	m.notifyLifecycle(LifecycleDisposed, w)
 	return nil
 }
 
@@ -1736,6 +1877,9 @@ func (m *Manager) CreateFromWorkspace(ctx context.Context, sourceWorkspaceID, ne
 	if err := ValidateBranchName(newBranch); err != nil {
 		return nil, fmt.Errorf("invalid branch name: %w", err)
 	}
+	if err := m.EnforceDiskQuota(ctx); err != nil {
+		return nil, err
+	}
 
 	// 3. Get source workspace's current branch (use VCS backend for non-git)
 	srcBackend := m.backendForWorkspace(sourceWorkspaceID)
@@ -1854,6 +1998,7 @@ func (m *Manager) CreateFromWorkspace(ctx context.Context, sourceWorkspaceID, ne
 		Path:   workspacePath,
 		VCS:    repoConfig.VCS,
 	}
+	w.BaseSHA = m.headSHA(ctx, w)
 
 	if err := m.AddWorkspaceWithTabs(w); err != nil {
 		return nil, fmt.Errorf("failed to add workspace to state: %w", err)
@@ -1880,6 +2025,10 @@ func (m *Manager) CreateFromWorkspace(ctx context.Context, sourceWorkspaceID, ne
 	// Re-read from state so the returned workspace includes all mutations
 	// (e.g., overlay manifest set by UpdateOverlayManifest after AddWorkspace).
 	current, _ := m.state.GetWorkspace(w.ID)
+	if err := m.setupWorkspace(ctx, &current); err != nil {
+		return nil, err
+	}
+	m.notifyLifecycle(LifecycleCreated, current)
 	return &current, nil
 }
 
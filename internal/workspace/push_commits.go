@@ -22,6 +22,7 @@ const (
 	PushReasonPushRejected    = "push_rejected"
 	PushReasonUnsupported     = "unsupported"
 	PushReasonNoOrigin        = "no_origin"
+	PushReasonGatesFailed     = "gates_failed" // set by the handler when a push gate blocks the push
 )
 
 // fullCommitShaRe matches a full sha1 (40) or sha256 (64) hex object name.
@@ -47,10 +48,10 @@ func (m *Manager) PushCommits(ctx context.Context, workspaceID, hash, target str
 	if !found {
 		return nil, fmt.Errorf("workspace not found: %s", workspaceID)
 	}
-	if w.VCS == "sapling" {
+	if w.VCS == "sapling" || w.VCS == "jj" {
 		return &contracts.PushCommitsResult{
 			Reason:  PushReasonUnsupported,
-			Message: "per-commit push is not supported for sapling workspaces",
+			Message: "per-commit push is not supported for " + w.VCS + " workspaces",
 		}, nil
 	}
 	if !m.LockWorkspace(workspaceID) {
@@ -0,0 +1,229 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/cmdtemplate"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// Push gate run and gate statuses.
+const (
+	PushGatePending = "pending"
+	PushGateRunning = "running"
+	PushGatePassed  = "passed"
+	PushGateFailed  = "failed"
+	PushGateSkipped = "skipped"
+)
+
+// Pushes that run the gates.
+const (
+	PushGateTriggerBranch  = "push_to_branch"
+	PushGateTriggerSync    = "sync_to_main"
+	PushGateTriggerCommits = "push_commits"
+)
+
+// RunPushGates runs the push gates configured for the workspace's repo, in
+// order, in the workspace directory, and records the run on the workspace.
+// The first failing gate stops the run. It returns nil when the repo has no
+// gates. A passing run is reused while HEAD is unchanged, so a push that
+// comes back for confirmation does not run the gates twice.
+//
+// With override the gates are not run: the previous run for HEAD (or a
+// skipped one) is marked overridden and returned, and the caller pushes.
+func (m *Manager) RunPushGates(ctx context.Context, workspaceID, trigger string, override bool) (*contracts.PushGateRun, error) {
+	w, found := m.state.GetWorkspace(workspaceID)
+	if !found {
+		return nil, fmt.Errorf("workspace not found: %s", workspaceID)
+	}
+	repo, found := m.findRepoByURL(w.Repo)
+	if !found {
+		return nil, nil
+	}
+	gates := m.config.GetPushGates(repo.Name)
+	if len(gates) == 0 {
+		return nil, nil
+	}
+
+	if !m.pushGates.start(workspaceID) {
+		return nil, ErrPushGatesRunning
+	}
+	defer m.pushGates.finish(workspaceID)
+
+	head, err := m.runGit(ctx, workspaceID, RefreshTriggerExplicit, w.Path, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("git rev-parse HEAD failed: %w", err)
+	}
+	headSHA := strings.TrimSpace(string(head))
+
+	last := w.PushGates
+	sameHead := last != nil && last.HeadSHA == headSHA && last.Status != PushGateRunning
+	if override {
+		run := state.CopyPushGateRun(last)
+		if !sameHead {
+			now := time.Now()
+			run = &contracts.PushGateRun{Trigger: trigger, Status: PushGateSkipped, HeadSHA: headSHA, StartedAt: now, FinishedAt: &now}
+			for _, g := range gates {
+				run.Gates = append(run.Gates, contracts.PushGateResult{Name: g.Name, Status: PushGateSkipped})
+			}
+		}
+		if run.Status != PushGatePassed {
+			run.Overridden = true
+			m.logger.Warn("push gates overridden", "workspace", workspaceID, "trigger", trigger, "status", run.Status)
+		}
+		m.savePushGateRun(workspaceID, run, true)
+		return run, nil
+	}
+	if sameHead && last.Status == PushGatePassed {
+		return state.CopyPushGateRun(last), nil
+	}
+
+	defaultBranch, err := m.GetDefaultBranch(ctx, w.Repo)
+	if err != nil {
+		defaultBranch = "main"
+	}
+	vars := map[string]string{
+		"WorkspacePath": w.Path,
+		"WorkspaceID":   w.ID,
+		"Branch":        w.Branch,
+		"DefaultBranch": defaultBranch,
+	}
+
+	run := &contracts.PushGateRun{Trigger: trigger, Status: PushGateRunning, HeadSHA: headSHA, StartedAt: time.Now()}
+	for _, g := range gates {
+		run.Gates = append(run.Gates, contracts.PushGateResult{Name: g.Name, Status: PushGatePending})
+	}
+	m.savePushGateRun(workspaceID, run, true)
+	m.logger.Info("push gates: running", "workspace", workspaceID, "trigger", trigger, "gates", len(gates))
+
+	failed := false
+	for i, g := range gates {
+		if failed {
+			run.Gates[i].Status = PushGateSkipped
+			continue
+		}
+		m.runPushGate(ctx, workspaceID, run, i, g, w.Path, vars)
+		failed = run.Gates[i].Status == PushGateFailed
+		m.savePushGateRun(workspaceID, run, true)
+	}
+	run.Status = PushGatePassed
+	if failed {
+		run.Status = PushGateFailed
+	}
+	finished := time.Now()
+	run.FinishedAt = &finished
+	m.savePushGateRun(workspaceID, run, true)
+	m.logger.Info("push gates: done", "workspace", workspaceID, "status", run.Status, "duration", finished.Sub(run.StartedAt))
+
+	if m.telemetry != nil {
+		m.telemetry.Track("push_gates", map[string]any{
+			"workspace_id": workspaceID,
+			"trigger":      trigger,
+			"status":       run.Status,
+			"gates":        len(gates),
+		})
+	}
+	return state.CopyPushGateRun(run), nil
+}
+
+// runPushGate runs gate i of run and records its result, streaming output
+// into the workspace while it runs.
+func (m *Manager) runPushGate(ctx context.Context, workspaceID string, run *contracts.PushGateRun, i int, g config.PushGate, dir string, vars map[string]string) {
+	result := &run.Gates[i]
+	result.Status = PushGateRunning
+	m.savePushGateRun(workspaceID, run, false)
+
+	argv, err := cmdtemplate.Template(g.Command).Render(vars)
+	if err != nil {
+		result.Status = PushGateFailed
+		result.Error = fmt.Sprintf("invalid command: %v", err)
+		return
+	}
+	timeout := time.Duration(g.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = config.DefaultPushGateTimeoutMs * time.Millisecond
+	}
+
+	check := RunCheck(ctx, dir, argv, timeout, func(tail string) {
+		result.Output = tail
+		m.savePushGateRun(workspaceID, run, false)
+	})
+	result.ExitCode = check.ExitCode
+	result.DurationMs = check.DurationMs
+	result.Output = check.Output
+	result.Error = check.Error
+	result.Status = PushGatePassed
+	if !check.Passed {
+		result.Status = PushGateFailed
+	}
+}
+
+// savePushGateRun stores run on the workspace and broadcasts it. Streamed
+// output is only kept in memory; persist writes state to disk.
+func (m *Manager) savePushGateRun(workspaceID string, run *contracts.PushGateRun, persist bool) {
+	fresh, found := m.state.GetWorkspace(workspaceID)
+	if !found {
+		return
+	}
+	fresh.PushGates = state.CopyPushGateRun(run)
+	if err := m.state.UpdateWorkspace(fresh); err != nil {
+		m.logger.Warn("push gates: failed to update workspace", "workspace", workspaceID, "err", err)
+		return
+	}
+	if persist {
+		if err := m.state.Save(); err != nil {
+			m.logger.Warn("push gates: failed to save state", "err", err)
+		}
+	}
+	if m.broadcastFn != nil {
+		m.broadcastFn()
+	}
+}
+
+// recoverPushGateRun fails a run left "running" by a daemon restart.
+func recoverPushGateRun(run *contracts.PushGateRun) bool {
+	if run == nil || run.Status != PushGateRunning {
+		return false
+	}
+	now := time.Now()
+	run.Status = PushGateFailed
+	run.FinishedAt = &now
+	for i := range run.Gates {
+		if run.Gates[i].Status == PushGateRunning || run.Gates[i].Status == PushGatePending {
+			run.Gates[i].Status = PushGateFailed
+			run.Gates[i].Error = "daemon restarted while the gate was running"
+		}
+	}
+	return true
+}
+
+// pushGateRuns tracks the workspaces whose gates are running.
+type pushGateRuns struct {
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+func (p *pushGateRuns) start(workspaceID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running[workspaceID] {
+		return false
+	}
+	if p.running == nil {
+		p.running = make(map[string]bool)
+	}
+	p.running[workspaceID] = true
+	return true
+}
+
+func (p *pushGateRuns) finish(workspaceID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.running, workspaceID)
+}
@@ -0,0 +1,158 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+)
+
+// setupPushGatesTest is setupPushTest with the remote registered as repo
+// "demo" and gates configured for it.
+func setupPushGatesTest(t *testing.T, gates ...config.PushGate) (string, *Manager, string) {
+	t.Helper()
+	remoteDir, cloneDir, m, st, workspaceID := setupPushTest(t)
+	m.config.Repos = []config.Repo{{Name: "demo", URL: remoteDir}}
+	m.config.PushGates = &config.PushGatesConfig{Repos: map[string][]config.PushGate{"demo": gates}}
+	runGit(t, cloneDir, "checkout", "-b", "feature")
+	commitFile(t, cloneDir, "feature.txt", "feature work", "feature commit")
+	addPushWorkspace(t, st, workspaceID, remoteDir, cloneDir, "feature")
+	return cloneDir, m, workspaceID
+}
+
+func TestRunPushGates_NoGates(t *testing.T) {
+	t.Parallel()
+	_, m, workspaceID := setupPushGatesTest(t)
+
+	run, err := m.RunPushGates(context.Background(), workspaceID, PushGateTriggerBranch, false)
+	if err != nil {
+		t.Fatalf("RunPushGates() error: %v", err)
+	}
+	if run != nil {
+		t.Errorf("run = %+v, want nil without gates", run)
+	}
+}
+
+func TestRunPushGates_FailureStopsAndSkipsRest(t *testing.T) {
+	t.Parallel()
+	cloneDir, m, workspaceID := setupPushGatesTest(t,
+		config.PushGate{Name: "lint", Command: config.ShellCommand{"sh", "-c", "echo lint ok"}},
+		config.PushGate{Name: "tests", Command: config.ShellCommand{"sh", "-c", "echo FAIL: TestFoo; exit 3"}},
+		config.PushGate{Name: "secrets", Command: config.ShellCommand{"true"}},
+	)
+
+	run, err := m.RunPushGates(context.Background(), workspaceID, PushGateTriggerBranch, false)
+	if err != nil {
+		t.Fatalf("RunPushGates() error: %v", err)
+	}
+	if run.Status != PushGateFailed || run.Overridden {
+		t.Fatalf("run status = %q overridden=%v, want failed", run.Status, run.Overridden)
+	}
+	want := []string{PushGatePassed, PushGateFailed, PushGateSkipped}
+	for i, g := range run.Gates {
+		if g.Status != want[i] {
+			t.Errorf("gate %s status = %q, want %q", g.Name, g.Status, want[i])
+		}
+	}
+	if g := run.Gates[1]; g.ExitCode != 3 || !strings.Contains(g.Output, "FAIL: TestFoo") {
+		t.Errorf("failed gate = %+v, want exit 3 with output", g)
+	}
+	if head := strings.TrimSpace(runGitOut(t, cloneDir, "rev-parse", "HEAD")); run.HeadSHA != head {
+		t.Errorf("HeadSHA = %q, want %q", run.HeadSHA, head)
+	}
+
+	w, _ := m.state.GetWorkspace(workspaceID)
+	if w.PushGates == nil || w.PushGates.Status != PushGateFailed {
+		t.Errorf("workspace push gates = %+v, want the failed run", w.PushGates)
+	}
+}
+
+func TestRunPushGates_OverrideMarksFailedRun(t *testing.T) {
+	t.Parallel()
+	_, m, workspaceID := setupPushGatesTest(t,
+		config.PushGate{Name: "tests", Command: config.ShellCommand{"false"}},
+	)
+	ctx := context.Background()
+
+	if _, err := m.RunPushGates(ctx, workspaceID, PushGateTriggerSync, false); err != nil {
+		t.Fatalf("RunPushGates() error: %v", err)
+	}
+	run, err := m.RunPushGates(ctx, workspaceID, PushGateTriggerSync, true)
+	if err != nil {
+		t.Fatalf("RunPushGates(override) error: %v", err)
+	}
+	if run.Status != PushGateFailed || !run.Overridden {
+		t.Errorf("run status = %q overridden=%v, want an overridden failed run", run.Status, run.Overridden)
+	}
+	w, _ := m.state.GetWorkspace(workspaceID)
+	if w.PushGates == nil || !w.PushGates.Overridden {
+		t.Errorf("workspace push gates = %+v, want overridden", w.PushGates)
+	}
+}
+
+func TestRunPushGates_PassedRunReusedUntilHeadMoves(t *testing.T) {
+	t.Parallel()
+	counter := filepath.Join(t.TempDir(), "runs")
+	cloneDir, m, workspaceID := setupPushGatesTest(t,
+		config.PushGate{Name: "tests", Command: config.ShellCommand{"sh", "-c", "echo run >> " + counter}},
+	)
+	ctx := context.Background()
+	runs := func() int {
+		data, _ := os.ReadFile(counter)
+		return strings.Count(string(data), "run")
+	}
+
+	for i := 0; i < 2; i++ {
+		run, err := m.RunPushGates(ctx, workspaceID, PushGateTriggerBranch, false)
+		if err != nil {
+			t.Fatalf("RunPushGates() error: %v", err)
+		}
+		if run.Status != PushGatePassed {
+			t.Fatalf("run status = %q, want passed", run.Status)
+		}
+	}
+	if got := runs(); got != 1 {
+		t.Errorf("gate ran %d times for one HEAD, want 1", got)
+	}
+
+	commitFile(t, cloneDir, "more.txt", "more", "another commit")
+	if _, err := m.RunPushGates(ctx, workspaceID, PushGateTriggerBranch, false); err != nil {
+		t.Fatalf("RunPushGates() error: %v", err)
+	}
+	if got := runs(); got != 2 {
+		t.Errorf("gate ran %d times after HEAD moved, want 2", got)
+	}
+}
+
+func TestRunPushGates_Timeout(t *testing.T) {
+	t.Parallel()
+	_, m, workspaceID := setupPushGatesTest(t,
+		config.PushGate{Name: "slow", Command: config.ShellCommand{"sleep", "30"}, TimeoutMs: 200},
+	)
+
+	run, err := m.RunPushGates(context.Background(), workspaceID, PushGateTriggerBranch, false)
+	if err != nil {
+		t.Fatalf("RunPushGates() error: %v", err)
+	}
+	if g := run.Gates[0]; g.Status != PushGateFailed || !strings.Contains(g.Error, "timed out") {
+		t.Errorf("gate = %+v, want a timeout failure", g)
+	}
+}
+
+func TestRunPushGates_AlreadyRunning(t *testing.T) {
+	t.Parallel()
+	_, m, workspaceID := setupPushGatesTest(t,
+		config.PushGate{Name: "tests", Command: config.ShellCommand{"true"}},
+	)
+	m.pushGates.start(workspaceID)
+	defer m.pushGates.finish(workspaceID)
+
+	_, err := m.RunPushGates(context.Background(), workspaceID, PushGateTriggerBranch, false)
+	if !errors.Is(err, ErrPushGatesRunning) {
+		t.Errorf("err = %v, want ErrPushGatesRunning", err)
+	}
+}
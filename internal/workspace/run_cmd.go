@@ -17,6 +17,11 @@ import (
 var ioTelemetryMu sync.Mutex
 
 func (m *Manager) runCmd(ctx context.Context, binary string, workspaceID string, trigger RefreshTrigger, dir string, args ...string) ([]byte, error) {
+	return m.runCmdEnv(ctx, binary, nil, workspaceID, trigger, dir, args...)
+}
+
+// runCmdEnv is runCmd with extra environment variables.
+func (m *Manager) runCmdEnv(ctx context.Context, binary string, env []string, workspaceID string, trigger RefreshTrigger, dir string, args ...string) ([]byte, error) {
 	cmd := exec.CommandContext(ctx, binary, args...)
 	cmd.Dir = dir
 
@@ -32,6 +37,7 @@ func (m *Manager) runCmd(ctx context.Context, binary string, workspaceID string,
 	// Prevent VCS commands from prompting for credentials on a terminal,
 	// which would hang indefinitely in a daemon process.
 	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Env = append(cmd.Env, env...)
 
 	releaseWatcherSuppression := func() {}
 	if m != nil && m.gitWatcher != nil {
@@ -0,0 +1,353 @@
+package workspace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/cmdtemplate"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// Setup run and command statuses.
+const (
+	SetupPending = "pending"
+	SetupRunning = "running"
+	SetupPassed  = "passed"
+	SetupFailed  = "failed"
+	SetupSkipped = "skipped"
+)
+
+// setupStreamLimit caps the output a setup stream keeps for subscribers
+// that connect while it runs.
+const setupStreamLimit = 256 * 1024
+
+// setupWorkspace runs the setup commands configured for the workspace's
+// repo, in order, in the workspace directory. GetOrCreate calls it after the
+// workspace is created or reused and prepared, before a session starts in
+// it, and on warm pool workspaces. The workspace is "provisioning" while
+// the commands run (a ready one stays ready) and gets its previous status
+// back when they pass; their output streams to SubscribeSetupOutput. The first failing command stops
+// the run and marks the workspace failed, with the run's logs kept on it.
+//
+// Setup is skipped when the workspace's last run passed with the same cache
+// key (the commands plus the contents of the cache key files), so a recycled
+// workspace whose lockfiles are unchanged is not reinstalled. *w is
+// refreshed from state on return.
+func (m *Manager) setupWorkspace(ctx context.Context, w *state.Workspace) error {
+	repo, found := m.findRepoByURL(w.Repo)
+	if !found {
+		return nil
+	}
+	setup := m.config.GetWorkspaceSetup(repo.Name)
+	if len(setup.Commands) == 0 {
+		return nil
+	}
+	key := setupCacheKey(w.Path, setup)
+	if last := w.Setup; last != nil && last.Status == SetupPassed && last.CacheKey == key {
+		m.logger.Info("setup: cache key unchanged, skipping", "workspace", w.ID)
+		return nil
+	}
+
+	defaultBranch, err := m.GetDefaultBranch(ctx, w.Repo)
+	if err != nil {
+		defaultBranch = "main"
+	}
+	vars := map[string]string{
+		"WorkspacePath": w.Path,
+		"WorkspaceID":   w.ID,
+		"Branch":        w.Branch,
+		"DefaultBranch": defaultBranch,
+	}
+
+	stream := m.setupStreams.open(w.ID)
+	defer m.setupStreams.close(w.ID)
+
+	run := &contracts.WorkspaceSetupRun{Status: SetupRunning, CacheKey: key, StartedAt: time.Now()}
+	for _, c := range setup.Commands {
+		run.Commands = append(run.Commands, contracts.WorkspaceSetupResult{Name: c.Name, Status: SetupPending})
+	}
+	// Warm pool workspaces stay "ready", and hidden, while they set up.
+	runningStatus := state.WorkspaceStatusProvisioning
+	if w.Status == state.WorkspaceStatusReady {
+		runningStatus = ""
+	}
+	m.saveSetupRun(w.ID, run, runningStatus, true)
+	m.logger.Info("setup: running", "workspace", w.ID, "commands", len(setup.Commands))
+
+	var failed *contracts.WorkspaceSetupResult
+	for i, c := range setup.Commands {
+		if failed != nil {
+			run.Commands[i].Status = SetupSkipped
+			continue
+		}
+		m.runSetupCommand(ctx, w.ID, run, i, c, w.Path, vars, stream)
+		if run.Commands[i].Status == SetupFailed {
+			failed = &run.Commands[i]
+		}
+		m.saveSetupRun(w.ID, run, "", true)
+	}
+	run.Status = SetupPassed
+	status := w.Status
+	if status == "" || status == state.WorkspaceStatusFailed {
+		status = state.WorkspaceStatusRunning
+	}
+	if failed != nil {
+		run.Status = SetupFailed
+		status = state.WorkspaceStatusFailed
+	}
+	finished := time.Now()
+	run.FinishedAt = &finished
+	m.saveSetupRun(w.ID, run, status, true)
+	m.invalidateDiskUsage(w.Path)
+	m.logger.Info("setup: done", "workspace", w.ID, "status", run.Status, "duration", finished.Sub(run.StartedAt))
+
+	if m.telemetry != nil {
+		m.telemetry.Track("workspace_setup", map[string]any{
+			"workspace_id": w.ID,
+			"status":       run.Status,
+			"commands":     len(setup.Commands),
+			"duration_ms":  finished.Sub(run.StartedAt).Milliseconds(),
+		})
+	}
+	if fresh, found := m.state.GetWorkspace(w.ID); found {
+		*w = fresh
+	}
+	if failed != nil {
+		return fmt.Errorf("%w: %s: %s", ErrSetupFailed, failed.Name, setupFailureReason(failed))
+	}
+	return nil
+}
+
+// runSetupCommand runs command i of run and records its result, writing its
+// output to stream as it runs.
+func (m *Manager) runSetupCommand(ctx context.Context, workspaceID string, run *contracts.WorkspaceSetupRun, i int, c config.SetupCommand, dir string, vars map[string]string, stream *setupStream) {
+	result := &run.Commands[i]
+	result.Status = SetupRunning
+	m.saveSetupRun(workspaceID, run, "", false)
+
+	argv, err := cmdtemplate.Template(c.Command).Render(vars)
+	if err != nil {
+		result.Status = SetupFailed
+		result.Error = fmt.Sprintf("invalid command: %v", err)
+		fmt.Fprintf(stream, "==> %s: %s\n", c.Name, result.Error)
+		return
+	}
+	timeout := time.Duration(c.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = config.DefaultSetupTimeoutMs * time.Millisecond
+	}
+
+	fmt.Fprintf(stream, "==> %s: %s\n", c.Name, strings.Join(argv, " "))
+	check := runCheck(ctx, dir, argv, timeout, &checkOutput{
+		flush: func(tail string) {
+			result.Output = tail
+			m.saveSetupRun(workspaceID, run, "", false)
+		},
+		tee: stream,
+	})
+	result.ExitCode = check.ExitCode
+	result.DurationMs = check.DurationMs
+	result.Output = check.Output
+	result.Error = check.Error
+	result.Status = SetupPassed
+	if !check.Passed {
+		result.Status = SetupFailed
+		fmt.Fprintf(stream, "==> %s failed: %s\n", c.Name, setupFailureReason(result))
+	}
+}
+
+// setupFailureReason describes why a setup command failed.
+func setupFailureReason(result *contracts.WorkspaceSetupResult) string {
+	if result.Error != "" {
+		return result.Error
+	}
+	return fmt.Sprintf("exit status %d", result.ExitCode)
+}
+
+// saveSetupRun stores run on the workspace, and status when set, and
+// broadcasts it. Streamed output is only kept in memory; persist writes
+// state to disk.
+func (m *Manager) saveSetupRun(workspaceID string, run *contracts.WorkspaceSetupRun, status string, persist bool) {
+	fresh, found := m.state.GetWorkspace(workspaceID)
+	if !found {
+		return
+	}
+	fresh.Setup = state.CopySetupRun(run)
+	if status != "" {
+		fresh.Status = status
+	}
+	if err := m.state.UpdateWorkspace(fresh); err != nil {
+		m.logger.Warn("setup: failed to update workspace", "workspace", workspaceID, "err", err)
+		return
+	}
+	if persist {
+		if err := m.state.Save(); err != nil {
+			m.logger.Warn("setup: failed to save state", "err", err)
+		}
+	}
+	if m.broadcastFn != nil {
+		m.broadcastFn()
+	}
+}
+
+// setupCacheKey hashes the setup commands and the contents of the files
+// matching its cache key patterns in dir. Missing files are left out, so a
+// lockfile appearing or disappearing changes the key too.
+func setupCacheKey(dir string, setup config.RepoSetup) string {
+	h := sha256.New()
+	for _, c := range setup.Commands {
+		fmt.Fprintf(h, "command %s %q\n", c.Name, []string(c.Command))
+	}
+	fsys := os.DirFS(dir)
+	seen := make(map[string]bool)
+	for _, pattern := range setup.CacheKeyFiles {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			continue
+		}
+		for _, name := range matches {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			data, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(h, "file %s %x\n", name, sha256.Sum256(data))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recoverSetupRun fails a setup left "running" by a daemon restart, and the
+// workspace with it. A ready workspace stays ready: refreshReady sets it up
+// again.
+func recoverSetupRun(w *state.Workspace) bool {
+	run := w.Setup
+	if run == nil || run.Status != SetupRunning {
+		return false
+	}
+	now := time.Now()
+	run.Status = SetupFailed
+	run.FinishedAt = &now
+	for i := range run.Commands {
+		if run.Commands[i].Status == SetupRunning || run.Commands[i].Status == SetupPending {
+			run.Commands[i].Status = SetupFailed
+			run.Commands[i].Error = "daemon restarted while setup was running"
+		}
+	}
+	if w.Status != state.WorkspaceStatusReady {
+		w.Status = state.WorkspaceStatusFailed
+	}
+	return true
+}
+
+// SubscribeSetupOutput returns the output so far of the workspace's running
+// setup and a channel that receives the rest. The channel is closed when
+// setup finishes. ok is false when no setup is running; otherwise
+// unsubscribe must be called once the caller stops reading.
+func (m *Manager) SubscribeSetupOutput(workspaceID string) (backlog []byte, output <-chan []byte, unsubscribe func(), ok bool) {
+	backlog, ch, ok := m.setupStreams.subscribe(workspaceID)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	return backlog, ch, func() { m.setupStreams.unsubscribe(workspaceID, ch) }, true
+}
+
+// setupStreams fans out the output of running setup commands to
+// subscribers, keyed by workspace ID.
+type setupStreams struct {
+	mu      sync.Mutex
+	streams map[string]*setupStream
+}
+
+// setupStream is one workspace's setup output. It keeps the output written
+// so far, up to setupStreamLimit, for subscribers that connect late.
+type setupStream struct {
+	hub  *setupStreams
+	buf  []byte
+	subs []chan []byte
+}
+
+func (s *setupStreams) open(workspaceID string) *setupStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.streams == nil {
+		s.streams = make(map[string]*setupStream)
+	}
+	stream := &setupStream{hub: s}
+	s.streams[workspaceID] = stream
+	return stream
+}
+
+// close ends the workspace's stream and closes its subscribers' channels.
+func (s *setupStreams) close(workspaceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream := s.streams[workspaceID]
+	if stream == nil {
+		return
+	}
+	delete(s.streams, workspaceID)
+	for _, ch := range stream.subs {
+		close(ch)
+	}
+}
+
+func (s *setupStreams) subscribe(workspaceID string) ([]byte, chan []byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream := s.streams[workspaceID]
+	if stream == nil {
+		return nil, nil, false
+	}
+	ch := make(chan []byte, 100)
+	stream.subs = append(stream.subs, ch)
+	return append([]byte(nil), stream.buf...), ch, true
+}
+
+// unsubscribe removes ch and closes it, unless the stream already ended
+// and closed it.
+func (s *setupStreams) unsubscribe(workspaceID string, ch chan []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream := s.streams[workspaceID]
+	if stream == nil {
+		return
+	}
+	for i, sub := range stream.subs {
+		if sub == ch {
+			stream.subs = append(stream.subs[:i], stream.subs[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// Write keeps p for late subscribers and sends it to the current ones,
+// dropping it for any that are not keeping up.
+func (st *setupStream) Write(p []byte) (int, error) {
+	st.hub.mu.Lock()
+	defer st.hub.mu.Unlock()
+	st.buf = append(st.buf, p...)
+	if len(st.buf) > setupStreamLimit {
+		st.buf = st.buf[len(st.buf)-setupStreamLimit:]
+	}
+	for _, ch := range st.subs {
+		select {
+		case ch <- append([]byte(nil), p...):
+		default:
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,188 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// setupSetupTest is setupPushTest with the remote registered as repo "demo"
+// and setup configured for it. It returns the workspace as stored.
+func setupSetupTest(t *testing.T, setup config.RepoSetup) (*Manager, state.Workspace) {
+	t.Helper()
+	remoteDir, cloneDir, m, st, workspaceID := setupPushTest(t)
+	m.config.Repos = []config.Repo{{Name: "demo", URL: remoteDir}}
+	m.config.Setup = &config.SetupConfig{Repos: map[string]config.RepoSetup{"demo": setup}}
+	addPushWorkspace(t, st, workspaceID, remoteDir, cloneDir, "main")
+	w, _ := st.GetWorkspace(workspaceID)
+	return m, w
+}
+
+func TestSetupWorkspace_SkipsWhileCacheKeyFilesUnchanged(t *testing.T) {
+	t.Parallel()
+	counter := filepath.Join(t.TempDir(), "runs")
+	m, w := setupSetupTest(t, config.RepoSetup{
+		Commands:      []config.SetupCommand{{Name: "install", Command: config.ShellCommand{"sh", "-c", "echo run >> " + counter}}},
+		CacheKeyFiles: []string{"*.sum"},
+	})
+	writeFile(t, w.Path, "go.sum", "v1")
+	ctx := context.Background()
+	runs := func() int {
+		data, _ := os.ReadFile(counter)
+		return strings.Count(string(data), "run")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := m.setupWorkspace(ctx, &w); err != nil {
+			t.Fatalf("setupWorkspace() error: %v", err)
+		}
+	}
+	if got := runs(); got != 1 {
+		t.Errorf("setup ran %d times with unchanged lockfiles, want 1", got)
+	}
+	if w.Setup == nil || w.Setup.Status != SetupPassed || w.Status != state.WorkspaceStatusRunning {
+		t.Errorf("workspace status=%q setup=%+v, want running with a passed run", w.Status, w.Setup)
+	}
+
+	writeFile(t, w.Path, "go.sum", "v2")
+	if err := m.setupWorkspace(ctx, &w); err != nil {
+		t.Fatalf("setupWorkspace() error: %v", err)
+	}
+	if got := runs(); got != 2 {
+		t.Errorf("setup ran %d times after the lockfile changed, want 2", got)
+	}
+}
+
+func TestSetupWorkspace_FailureMarksWorkspaceFailed(t *testing.T) {
+	t.Parallel()
+	m, w := setupSetupTest(t, config.RepoSetup{Commands: []config.SetupCommand{
+		{Name: "deps", Command: config.ShellCommand{"sh", "-c", "echo resolving; echo 'ERR! lockfile out of date'; exit 3"}},
+		{Name: "codegen", Command: config.ShellCommand{"true"}},
+	}})
+
+	err := m.setupWorkspace(context.Background(), &w)
+	if !errors.Is(err, ErrSetupFailed) || !strings.Contains(err.Error(), "deps") {
+		t.Fatalf("setupWorkspace() error = %v, want ErrSetupFailed naming deps", err)
+	}
+	if w.Status != state.WorkspaceStatusFailed || w.Setup == nil || w.Setup.Status != SetupFailed {
+		t.Fatalf("workspace status=%q setup=%+v, want failed", w.Status, w.Setup)
+	}
+	deps, codegen := w.Setup.Commands[0], w.Setup.Commands[1]
+	if deps.ExitCode != 3 || !strings.Contains(deps.Output, "lockfile out of date") {
+		t.Errorf("deps = %+v, want exit 3 with its output", deps)
+	}
+	if codegen.Status != SetupSkipped {
+		t.Errorf("codegen status = %q, want skipped", codegen.Status)
+	}
+
+	// A failed run is not a cache hit: the next setup runs again.
+	m.config.Setup.Repos["demo"] = config.RepoSetup{Commands: []config.SetupCommand{
+		{Name: "deps", Command: config.ShellCommand{"true"}},
+	}}
+	if err := m.setupWorkspace(context.Background(), &w); err != nil {
+		t.Fatalf("setupWorkspace() retry error: %v", err)
+	}
+	if w.Status != state.WorkspaceStatusRunning {
+		t.Errorf("status after passing retry = %q, want running", w.Status)
+	}
+}
+
+func TestSetupWorkspace_StreamsOutput(t *testing.T) {
+	t.Parallel()
+	release := filepath.Join(t.TempDir(), "release")
+	m, w := setupSetupTest(t, config.RepoSetup{Commands: []config.SetupCommand{
+		{Name: "install", Command: config.ShellCommand{"sh", "-c", "echo fetching; while [ ! -e " + release + " ]; do sleep 0.05; done; echo installed"}},
+	}})
+
+	workspaceID := w.ID
+	done := make(chan error, 1)
+	go func() { done <- m.setupWorkspace(context.Background(), &w) }()
+
+	var backlog []byte
+	var output <-chan []byte
+	var unsubscribe func()
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		var ok bool
+		if backlog, output, unsubscribe, ok = m.SubscribeSetupOutput(workspaceID); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("setup output stream never opened")
+		}
+	}
+	defer unsubscribe()
+	if err := os.WriteFile(release, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	streamed := string(backlog)
+	for data := range output {
+		streamed += string(data)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("setupWorkspace() error: %v", err)
+	}
+	for _, want := range []string{"==> install: sh -c", "fetching", "installed"} {
+		if !strings.Contains(streamed, want) {
+			t.Errorf("streamed output missing %q:\n%s", want, streamed)
+		}
+	}
+	if _, _, _, ok := m.SubscribeSetupOutput(workspaceID); ok {
+		t.Error("stream still open after setup finished")
+	}
+}
+
+func TestSetupWorkspace_NoSetupConfigured(t *testing.T) {
+	t.Parallel()
+	m, w := setupSetupTest(t, config.RepoSetup{})
+	if err := m.setupWorkspace(context.Background(), &w); err != nil {
+		t.Fatalf("setupWorkspace() error: %v", err)
+	}
+	if w.Setup != nil {
+		t.Errorf("setup = %+v, want no run", w.Setup)
+	}
+}
+
+func TestRecoverSetupRun(t *testing.T) {
+	w := state.Workspace{
+		Status: state.WorkspaceStatusProvisioning,
+		Setup: &state.SetupRun{Status: SetupRunning, Commands: []contracts.WorkspaceSetupResult{
+			{Name: "deps", Status: SetupPassed},
+			{Name: "codegen", Status: SetupRunning},
+		}},
+	}
+	if !recoverSetupRun(&w) {
+		t.Fatal("recoverSetupRun() = false for a running setup")
+	}
+	if w.Status != state.WorkspaceStatusFailed || w.Setup.Status != SetupFailed || w.Setup.FinishedAt == nil {
+		t.Errorf("workspace status=%q setup=%+v, want failed", w.Status, w.Setup)
+	}
+	if w.Setup.Commands[0].Status != SetupPassed || w.Setup.Commands[1].Status != SetupFailed {
+		t.Errorf("commands = %+v, want the running one failed", w.Setup.Commands)
+	}
+	if recoverSetupRun(&w) {
+		t.Error("recoverSetupRun() = true for a finished setup")
+	}
+
+	ready := state.Workspace{
+		Status: state.WorkspaceStatusReady,
+		Setup:  &state.SetupRun{Status: SetupRunning, Commands: []contracts.WorkspaceSetupResult{{Name: "deps", Status: SetupRunning}}},
+	}
+	if !recoverSetupRun(&ready) {
+		t.Fatal("recoverSetupRun() = false for a ready workspace's running setup")
+	}
+	if ready.Status != state.WorkspaceStatusReady || ready.Setup.Status != SetupFailed {
+		t.Errorf("ready workspace status=%q setup=%+v, want ready with setup failed", ready.Status, ready.Setup)
+	}
+	if !setupInterrupted(ready) {
+		t.Error("setupInterrupted() = false for a recovered ready workspace")
+	}
+}
@@ -40,7 +40,7 @@ func (m *Manager) AddWorkspaceWithTabs(ws state.Workspace) error {
 // isVCSCapable returns true if the VCS type supports diff/git tabs.
 func isVCSCapable(vcs string) bool {
 	switch vcs {
-	case "", "git", "git-worktree", "git-clone", "sapling":
+	case "", "git", "git-worktree", "git-clone", "sapling", "jj":
 		return true
 	default:
 		return false
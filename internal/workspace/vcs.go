@@ -58,10 +58,10 @@ type VCSChangedFile struct {
 }
 
 // HasVCSSupport returns true if the VCS type has diff and commit graph support.
-// This is broader than IsGitVCS — it includes Sapling.
+// This is broader than IsGitVCS — it includes Sapling and jj.
 func HasVCSSupport(vcs string) bool {
 	switch vcs {
-	case "", "git", "git-worktree", "git-clone", "sapling":
+	case "", "git", "git-worktree", "git-clone", "sapling", "jj":
 		return true
 	default:
 		return false
@@ -84,8 +84,12 @@ func IsGitVCS(vcs string) bool {
 // For sapling: checks .sl/ or .hg/ directory exists. Eden-backed sapling
 // checkouts use the mercurial-compat .hg/ control dir; matches
 // internal/state/state.go isSaplingWorkspace.
+// For jj: checks .jj/ directory exists.
 func hasVCSMetadata(path, vcs string) bool {
 	switch vcs {
+	case "jj":
+		info, err := os.Stat(filepath.Join(path, ".jj"))
+		return err == nil && info.IsDir()
 	case "sapling":
 		for _, name := range []string{".sl", ".hg"} {
 			if info, err := os.Stat(filepath.Join(path, name)); err == nil && info.IsDir() {
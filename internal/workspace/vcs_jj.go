@@ -0,0 +1,366 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// JJBackend implements VCSBackend for Jujutsu (jj). The repo base is a
+// colocated `jj git clone`; each workspace is a `jj workspace add` of it,
+// named after the workspace directory, so all workspaces share one store
+// the way git worktrees share a bare repo.
+//
+// A workspace's branch is a jj bookmark on its working-copy commit "@".
+// Bookmarks follow rewrites, so the bookmark stays on the agent's change as
+// it is edited and committed. Query repos (branch search, default branch)
+// use the same git query repos as git, keyed by the repo URL.
+type JJBackend struct {
+	manager *Manager
+}
+
+var _ VCSBackend = (*JJBackend)(nil)
+
+func NewJJBackend(m *Manager) *JJBackend {
+	return &JJBackend{manager: m}
+}
+
+// run runs a jj command with paging and color disabled.
+func (j *JJBackend) run(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	args = append([]string{"--no-pager", "--color", "never"}, args...)
+	return j.manager.runCmd(ctx, "jj", "", RefreshTriggerExplicit, dir, args...)
+}
+
+// logTemplate renders one template line per commit in revset, newest first.
+func (j *JJBackend) logTemplate(ctx context.Context, dir, revset, template string) (string, error) {
+	output, err := j.run(ctx, dir, "log", "--no-graph", "-r", revset, "-T", template)
+	return strings.TrimSpace(string(output)), err
+}
+
+// count returns the number of commits in revset, or 0 when it doesn't resolve.
+func (j *JJBackend) count(ctx context.Context, dir, revset string) int {
+	output, err := j.logTemplate(ctx, dir, revset, `"x"`)
+	if err != nil {
+		return 0
+	}
+	return len(output)
+}
+
+// commitID resolves a single-commit revset. Returns "" when it doesn't resolve.
+func (j *JJBackend) commitID(ctx context.Context, dir, revset string) string {
+	output, err := j.run(ctx, dir, "log", "--no-graph", "-r", revset, "--limit", "1", "-T", "commit_id")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// jjSymbol quotes a bookmark name for use in a revset.
+func jjSymbol(name string) string {
+	return strconv.Quote(name)
+}
+
+// jjRemoteBookmark is the revset for a bookmark on origin.
+func jjRemoteBookmark(name string) string {
+	return jjSymbol(name) + "@origin"
+}
+
+func (j *JJBackend) EnsureRepoBase(ctx context.Context, repoIdentifier, basePath string) (string, error) {
+	if rb, found := j.manager.state.GetRepoBaseByURL(repoIdentifier); found && rb.VCS == "jj" {
+		if hasVCSMetadata(rb.Path, "jj") {
+			return rb.Path, nil
+		}
+		j.manager.logger.Warn("jj repo base missing on disk, will recreate", "url", repoIdentifier)
+	}
+
+	if basePath == "" {
+		repo, found := j.manager.config.FindRepoByURL(repoIdentifier)
+		if !found {
+			return "", fmt.Errorf("repo not found in config: %s", repoIdentifier)
+		}
+		name := repo.BarePath
+		if name == "" {
+			name = repo.Name
+		}
+		basePath = filepath.Join(j.manager.config.GetWorktreeBasePath(), name)
+	}
+
+	if !hasVCSMetadata(basePath, "jj") {
+		if _, err := os.Stat(basePath); err == nil {
+			return "", fmt.Errorf("repo base %s already exists but is not a jj repo", basePath)
+		}
+		if err := os.MkdirAll(filepath.Dir(basePath), 0755); err != nil {
+			return "", fmt.Errorf("failed to create parent directory: %w", err)
+		}
+		j.manager.logger.Info("cloning jj repo base", "url", repoIdentifier, "path", basePath)
+		if _, err := j.run(ctx, filepath.Dir(basePath), "git", "clone", "--colocate", repoIdentifier, basePath); err != nil {
+			return "", fmt.Errorf("jj git clone failed: %w", err)
+		}
+	}
+
+	j.manager.state.AddRepoBase(state.RepoBase{
+		RepoURL: repoIdentifier,
+		Path:    basePath,
+		VCS:     "jj",
+	})
+	j.manager.state.Save()
+	return basePath, nil
+}
+
+func (j *JJBackend) CreateWorkspace(ctx context.Context, repoBasePath, branch, destPath string) error {
+	if info, err := os.Stat(destPath); err == nil && info.IsDir() {
+		if !hasVCSMetadata(destPath, "jj") {
+			return fmt.Errorf("create workspace failed: destination %s already exists but is not a jj workspace", destPath)
+		}
+		j.manager.logger.Info("jj workspace already present, switching branch", "dest", destPath)
+		return j.checkoutBranch(ctx, destPath, branch)
+	}
+
+	name := filepath.Base(destPath)
+	if _, err := j.run(ctx, repoBasePath, "workspace", "add", "--name", name, destPath); err != nil {
+		return fmt.Errorf("jj workspace add failed: %w", err)
+	}
+	return j.checkoutBranch(ctx, destPath, branch)
+}
+
+// checkoutBranch starts a new working-copy commit for branch in the
+// workspace at dir. An existing local bookmark is built on; a bookmark only
+// on origin is tracked first; otherwise a new bookmark is created on a fresh
+// change on top of trunk().
+func (j *JJBackend) checkoutBranch(ctx context.Context, dir, branch string) error {
+	local := j.commitID(ctx, dir, "bookmarks(exact:"+jjSymbol(branch)+")")
+	if local == "" && j.commitID(ctx, dir, jjRemoteBookmark(branch)) != "" {
+		if _, err := j.run(ctx, dir, "bookmark", "track", branch+"@origin"); err != nil {
+			return fmt.Errorf("jj bookmark track failed: %w", err)
+		}
+		local = branch
+	}
+	if local != "" {
+		if _, err := j.run(ctx, dir, "new", jjSymbol(branch)); err != nil {
+			return fmt.Errorf("jj new failed: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := j.run(ctx, dir, "new", "trunk()"); err != nil {
+		return fmt.Errorf("jj new failed: %w", err)
+	}
+	if _, err := j.run(ctx, dir, "bookmark", "create", branch, "-r", "@"); err != nil {
+		return fmt.Errorf("jj bookmark create failed: %w", err)
+	}
+	return nil
+}
+
+func (j *JJBackend) RemoveWorkspace(ctx context.Context, workspacePath string) error {
+	if _, err := j.run(ctx, workspacePath, "workspace", "forget"); err != nil {
+		j.manager.logger.Warn("jj workspace forget failed", "path", workspacePath, "err", err)
+	}
+	if err := os.RemoveAll(workspacePath); err != nil {
+		return fmt.Errorf("remove workspace failed: %w", err)
+	}
+	return nil
+}
+
+// PruneStale forgets jj workspaces whose directories were deleted
+// externally, the jj equivalent of `git worktree prune`.
+func (j *JJBackend) PruneStale(ctx context.Context, repoBasePath string) error {
+	output, err := j.run(ctx, repoBasePath, "workspace", "list")
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for _, name := range parseJJWorkspaceNames(string(output)) {
+		if name == "default" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(j.manager.config.GetWorkspacePath(), name)); os.IsNotExist(err) {
+			stale = append(stale, name)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	j.manager.logger.Info("forgetting stale jj workspaces", "names", stale)
+	_, err = j.run(ctx, repoBasePath, append([]string{"workspace", "forget"}, stale...)...)
+	return err
+}
+
+// parseJJWorkspaceNames parses `jj workspace list` output, one
+// "name: <change> <commit> <description>" line per workspace.
+func parseJJWorkspaceNames(output string) []string {
+	var names []string
+	for _, line := range strings.Split(output, "\n") {
+		name, _, found := strings.Cut(line, ":")
+		if !found || name == "" {
+			continue
+		}
+		names = append(names, strings.TrimSpace(name))
+	}
+	return names
+}
+
+func (j *JJBackend) Fetch(ctx context.Context, path string) error {
+	_, err := j.run(ctx, path, "git", "fetch")
+	return err
+}
+
+// IsBranchInUse always reports false: unlike git worktrees, several jj
+// workspaces may build on the same bookmark.
+func (j *JJBackend) IsBranchInUse(ctx context.Context, repoBasePath, branch string) (bool, error) {
+	return false, nil
+}
+
+func (j *JJBackend) GetStatus(ctx context.Context, workspacePath string) (VCSStatus, error) {
+	var status VCSStatus
+
+	output, err := j.run(ctx, workspacePath, "diff", "--summary")
+	if err != nil {
+		return status, err
+	}
+	if trimmed := strings.TrimSpace(string(output)); trimmed != "" {
+		status.Dirty = true
+		status.FilesChanged = len(strings.Split(trimmed, "\n"))
+	}
+
+	if output, err := j.run(ctx, workspacePath, "diff", "--stat"); err == nil {
+		status.LinesAdded, status.LinesRemoved = parseDiffStat(string(output))
+	}
+
+	status.AheadOfDefault = j.count(ctx, workspacePath, "trunk()..@-")
+	status.BehindDefault = j.count(ctx, workspacePath, "@-..trunk()")
+
+	branch, _ := j.GetCurrentBranch(ctx, workspacePath)
+	status.CurrentBranch = branch
+	if branch == "" {
+		return status, nil
+	}
+	remote := jjRemoteBookmark(branch)
+	status.RemoteHeadSHA = j.commitID(ctx, workspacePath, remote)
+	status.RemoteBranchExists = status.RemoteHeadSHA != ""
+	if status.RemoteBranchExists {
+		status.LocalUniqueCommits = j.count(ctx, workspacePath, remote+"..@-")
+		status.RemoteUniqueCommits = j.count(ctx, workspacePath, "@-.."+remote)
+		status.SyncedWithRemote = status.LocalUniqueCommits == 0 && status.RemoteUniqueCommits == 0
+	}
+	return status, nil
+}
+
+func (j *JJBackend) GetChangedFiles(ctx context.Context, workspacePath string) ([]VCSChangedFile, error) {
+	output, err := j.run(ctx, workspacePath, "diff", "--summary")
+	if err != nil {
+		return nil, err
+	}
+	return parseJJSummary(string(output)), nil
+}
+
+// parseJJSummary parses `jj diff --summary` output: "X path" lines with
+// M/A/D letters, and R/C for renames and copies written as
+// "R dir/{old => new}".
+func parseJJSummary(output string) []VCSChangedFile {
+	var files []VCSChangedFile
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if len(line) < 3 {
+			continue
+		}
+		path := strings.TrimSpace(line[2:])
+		status := "modified"
+		switch line[0] {
+		case 'A', 'C':
+			status = "added"
+		case 'D':
+			status = "deleted"
+		case 'R':
+			status = "renamed"
+		}
+		if line[0] == 'R' || line[0] == 'C' {
+			path = jjRenameTarget(path)
+		}
+		files = append(files, VCSChangedFile{Path: path, Status: status})
+	}
+	return files
+}
+
+// jjRenameTarget returns the new path from a "prefix{old => new}suffix" rename.
+func jjRenameTarget(path string) string {
+	open := strings.Index(path, "{")
+	end := strings.Index(path, "}")
+	if open < 0 || end < open {
+		return path
+	}
+	_, newPart, found := strings.Cut(path[open+1:end], " => ")
+	if !found {
+		return path
+	}
+	return filepath.Clean(path[:open] + newPart + path[end+1:])
+}
+
+func (j *JJBackend) GetDefaultBranch(ctx context.Context, repoBasePath string) (string, error) {
+	output, err := j.logTemplate(ctx, repoBasePath, "trunk()", `remote_bookmarks.map(|b| b.name()).join("\n")`)
+	if err != nil || output == "" {
+		return "main", nil // fallback
+	}
+	name, _, _ := strings.Cut(output, "\n")
+	return name, nil
+}
+
+// GetCurrentBranch returns the nearest bookmark at or below "@". When the
+// workspace sits on trunk with a bookmark of its own, that bookmark wins
+// over the default branch's.
+func (j *JJBackend) GetCurrentBranch(ctx context.Context, workspacePath string) (string, error) {
+	output, err := j.logTemplate(ctx, workspacePath, "heads(::@ & bookmarks())", `local_bookmarks.map(|b| b.name()).join("\n") ++ "\n"`)
+	if err != nil {
+		return "", err
+	}
+	names := strings.Fields(output)
+	if len(names) == 0 {
+		return "", nil
+	}
+	if len(names) > 1 {
+		defaultBranch, _ := j.GetDefaultBranch(ctx, workspacePath)
+		for _, name := range names {
+			if name != defaultBranch {
+				return name, nil
+			}
+		}
+	}
+	return names[0], nil
+}
+
+func (j *JJBackend) EnsureQueryRepo(ctx context.Context, repoIdentifier, path string) error {
+	return j.manager.gitBackend.EnsureQueryRepo(ctx, repoIdentifier, path)
+}
+
+func (j *JJBackend) FetchQueryRepo(ctx context.Context, path string) error {
+	return j.manager.gitBackend.FetchQueryRepo(ctx, path)
+}
+
+func (j *JJBackend) ListRecentBranches(ctx context.Context, path string, limit int) ([]RecentBranch, error) {
+	return j.manager.gitBackend.ListRecentBranches(ctx, path, limit)
+}
+
+func (j *JJBackend) GetBranchLog(ctx context.Context, path, branch string, limit int) ([]string, error) {
+	return j.manager.gitBackend.GetBranchLog(ctx, path, branch, limit)
+}
+
+// GetRemoteBranchHead resolves the branch's bookmark on origin.
+func (j *JJBackend) GetRemoteBranchHead(ctx context.Context, workspacePath, branch string) (RemoteBranchHead, error) {
+	sha := j.commitID(ctx, workspacePath, jjRemoteBookmark(branch))
+	if sha == "" {
+		return RemoteBranchHead{}, fmt.Errorf("branch %s has no bookmark on origin", branch)
+	}
+	output, err := j.run(ctx, workspacePath, "git", "remote", "list")
+	if err != nil {
+		return RemoteBranchHead{}, err
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if name, url, found := strings.Cut(strings.TrimSpace(line), " "); found && name == "origin" {
+			return RemoteBranchHead{SHA: sha, RemoteURL: strings.TrimSpace(url)}, nil
+		}
+	}
+	return RemoteBranchHead{}, fmt.Errorf("no origin remote")
+}
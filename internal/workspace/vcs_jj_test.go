@@ -0,0 +1,160 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// installFakeJJ puts a jj stub on PATH that logs its arguments and creates
+// the destination's .jj/ on `workspace add`. Every query prints nothing, so
+// no bookmark resolves.
+func installFakeJJ(t *testing.T) string {
+	t.Helper()
+	binDir := t.TempDir()
+	logPath := filepath.Join(binDir, "jj.log")
+	script := `#!/bin/sh
+echo "$*" >> "` + logPath + `"
+while [ "$1" = "--no-pager" ] || [ "$1" = "--color" ] || [ "$1" = "never" ]; do shift; done
+if [ "$1" = "workspace" ] && [ "$2" = "add" ]; then
+	for last; do :; done
+	mkdir -p "$last/.jj"
+fi
+exit 0
+`
+	if err := os.WriteFile(filepath.Join(binDir, "jj"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logPath
+}
+
+func TestParseJJSummary(t *testing.T) {
+	output := "M main.go\nA docs/new file.md\nD old.go\nR pkg/{a.go => b.go}\nC {x.go => y.go}\n"
+	got := parseJJSummary(output)
+	want := []VCSChangedFile{
+		{Path: "main.go", Status: "modified"},
+		{Path: "docs/new file.md", Status: "added"},
+		{Path: "old.go", Status: "deleted"},
+		{Path: "pkg/b.go", Status: "renamed"},
+		{Path: "y.go", Status: "added"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d files, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("file %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if files := parseJJSummary(""); len(files) != 0 {
+		t.Errorf("empty summary parsed to %+v", files)
+	}
+}
+
+func TestParseJJWorkspaceNames(t *testing.T) {
+	output := "default: qpvuntsm 230dd059 (no description set)\nmyrepo-001: kkmpptxz 3d0dead0 add login\n"
+	got := parseJJWorkspaceNames(output)
+	if strings.Join(got, ",") != "default,myrepo-001" {
+		t.Errorf("names = %v", got)
+	}
+}
+
+func TestBackendFor_SelectsJJBackend(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+	cfg := &config.Config{}
+	cfg.WorkspacePath = filepath.Join(tmpDir, "workspaces")
+	cfg.WorktreeBasePath = filepath.Join(tmpDir, "repos")
+	cfg.Repos = []config.Repo{
+		{Name: "jj-repo", URL: "git@github.com:user/jj.git", VCS: "jj", BarePath: "jj-repo.jj"},
+	}
+	st := state.New(statePath, nil)
+	m := New(cfg, st, statePath, testLogger())
+
+	if _, ok := m.backendFor("git@github.com:user/jj.git").(*JJBackend); !ok {
+		t.Errorf("expected JJBackend for jj repo, got %T", m.backendFor("git@github.com:user/jj.git"))
+	}
+	if m.repoUsesWorktrees(cfg.Repos[0]) {
+		t.Error("jj repos should not use git worktrees")
+	}
+}
+
+func TestHasVCSMetadata_JJ(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if hasVCSMetadata(dir, "jj") {
+		t.Error("empty dir reported as a jj workspace")
+	}
+	os.Mkdir(filepath.Join(dir, ".jj"), 0755)
+	if !hasVCSMetadata(dir, "jj") {
+		t.Error("dir with .jj/ not reported as a jj workspace")
+	}
+}
+
+func TestJJBackend_CreateWorkspace_NewBookmark(t *testing.T) {
+	logPath := installFakeJJ(t)
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+	cfg := &config.Config{}
+	cfg.WorkspacePath = filepath.Join(tmpDir, "workspaces")
+	cfg.WorktreeBasePath = filepath.Join(tmpDir, "repos")
+	st := state.New(statePath, nil)
+	m := New(cfg, st, statePath, testLogger())
+	jb := NewJJBackend(m)
+
+	basePath := filepath.Join(tmpDir, "repos", "myrepo.jj")
+	os.MkdirAll(filepath.Join(basePath, ".jj"), 0755)
+	wsPath := filepath.Join(cfg.WorkspacePath, "myrepo-001")
+	if err := jb.CreateWorkspace(context.Background(), basePath, "feature/login", wsPath); err != nil {
+		t.Fatalf("CreateWorkspace() failed: %v", err)
+	}
+	if !hasVCSMetadata(wsPath, "jj") {
+		t.Fatal("workspace should have .jj/ after create")
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	for _, want := range []string{
+		"workspace add --name myrepo-001 " + wsPath,
+		`bookmarks(exact:"feature/login")`,
+		`"feature/login"@origin`,
+		"new trunk()",
+		"bookmark create feature/login -r @",
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("jj calls missing %q:\n%s", want, log)
+		}
+	}
+}
+
+func TestJJBackend_RemoveWorkspace(t *testing.T) {
+	logPath := installFakeJJ(t)
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+	cfg := &config.Config{}
+	st := state.New(statePath, nil)
+	m := New(cfg, st, statePath, testLogger())
+	jb := NewJJBackend(m)
+
+	wsPath := filepath.Join(tmpDir, "myrepo-001")
+	os.MkdirAll(filepath.Join(wsPath, ".jj"), 0755)
+	if err := jb.RemoveWorkspace(context.Background(), wsPath); err != nil {
+		t.Fatalf("RemoveWorkspace() failed: %v", err)
+	}
+	if _, err := os.Stat(wsPath); !os.IsNotExist(err) {
+		t.Error("workspace directory should be removed")
+	}
+	if data, _ := os.ReadFile(logPath); !strings.Contains(string(data), "workspace forget") {
+		t.Errorf("expected jj workspace forget, got:\n%s", data)
+	}
+}
@@ -0,0 +1,385 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// readyBranch is the placeholder branch warm pool workspaces are created on.
+// It is deleted once the workspace is detached at the default branch.
+const readyBranch = "schmux-ready"
+
+// warmPoolRetryInterval is how long a repo's pool waits before provisioning
+// again after a ready workspace failed to provision.
+const warmPoolRetryInterval = 10 * time.Minute
+
+// warmPool tracks warm pool work in flight. A busy workspace is "ready" but
+// still being provisioned or refreshed, and is not handed out.
+type warmPool struct {
+	mu          sync.Mutex
+	busy        map[string]bool      // workspace ID -> provisioning or refreshing
+	maintaining map[string]bool      // repo URL -> pool being maintained
+	failedAt    map[string]time.Time // repo URL -> last provisioning failure
+}
+
+func (p *warmPool) setBusy(workspaceID string, busy bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.busy == nil {
+		p.busy = make(map[string]bool)
+	}
+	if busy {
+		p.busy[workspaceID] = true
+	} else {
+		delete(p.busy, workspaceID)
+	}
+}
+
+func (p *warmPool) isBusy(workspaceID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.busy[workspaceID]
+}
+
+// begin marks the repo's pool as being maintained. It returns false when
+// it already is.
+func (p *warmPool) begin(repoURL string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.maintaining[repoURL] {
+		return false
+	}
+	if p.maintaining == nil {
+		p.maintaining = make(map[string]bool)
+	}
+	p.maintaining[repoURL] = true
+	return true
+}
+
+func (p *warmPool) end(repoURL string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.maintaining, repoURL)
+}
+
+func (p *warmPool) fail(repoURL string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failedAt == nil {
+		p.failedAt = make(map[string]time.Time)
+	}
+	p.failedAt[repoURL] = time.Now()
+}
+
+func (p *warmPool) backingOff(repoURL string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	failed, ok := p.failedAt[repoURL]
+	return ok && time.Since(failed) < warmPoolRetryInterval
+}
+
+// readyWorkspaces returns the repo's ready workspaces, busy ones included.
+func (m *Manager) readyWorkspaces(repoURL string) []state.Workspace {
+	var ready []state.Workspace
+	for _, w := range m.state.GetWorkspaces() {
+		if w.Repo == repoURL && w.Status == state.WorkspaceStatusReady {
+			ready = append(ready, w)
+		}
+	}
+	return ready
+}
+
+// claimReady hands out one of the repo's ready workspaces with branch
+// checked out, and starts refilling the pool. ok is false when there is
+// none to hand out, or a workspace already holds the branch and should be
+// reused instead. The caller holds the repo lock.
+//
+// Claiming does not fetch: ready workspaces are refreshed after the daemon
+// fetches upstream, so this takes a checkout and nothing else.
+func (m *Manager) claimReady(ctx context.Context, repoURL, branch string) (*state.Workspace, bool, error) {
+	workspaces := m.state.GetWorkspaces()
+	for _, w := range workspaces {
+		if w.Repo == repoURL && w.Branch == branch && w.Status != state.WorkspaceStatusReady {
+			return nil, false, nil
+		}
+	}
+	for _, w := range workspaces {
+		if w.Repo != repoURL || w.Status != state.WorkspaceStatusReady || m.warmPool.isBusy(w.ID) || setupInterrupted(w) {
+			continue
+		}
+		if _, err := os.Stat(w.Path); os.IsNotExist(err) {
+			m.logger.Warn("ready workspace directory missing, cleaning up", "id", w.ID)
+			m.state.RemoveWorkspace(w.ID)
+			m.state.Save()
+			continue
+		}
+		if err := m.checkoutReady(ctx, w.Path, branch); err != nil {
+			m.logger.Warn("failed to check out branch in ready workspace", "id", w.ID, "branch", branch, "err", err)
+			return nil, false, nil
+		}
+		m.logger.Info("claimed ready workspace", "id", w.ID, "branch", branch)
+		m.invalidateDiskUsage(w.Path)
+		w.Branch = branch
+		w.BaseSHA = m.headSHA(ctx, w)
+		w.Status = state.WorkspaceStatusRunning
+		if err := m.state.UpdateWorkspace(w); err != nil {
+			return nil, false, fmt.Errorf("failed to update workspace: %w", err)
+		}
+		m.state.Save()
+		if m.gitWatcher != nil {
+			m.gitWatcher.AddWorkspace(w.ID, w.Path)
+		}
+		go m.maintainWarmPool(m.shutdownCtx, repoURL, false)
+
+		// Setup ran when the workspace joined the pool; this only reruns it
+		// if the cache key changed since.
+		if err := m.setupWorkspace(ctx, &w); err != nil {
+			return nil, false, err
+		}
+		m.notifyLifecycle(LifecycleCreated, w)
+		return &w, true, nil
+	}
+	return nil, false, nil
+}
+
+// checkoutReady checks out branch in a ready workspace: origin/<branch> when
+// it exists, otherwise a new branch at the default branch checkout.
+func (m *Manager) checkoutReady(ctx context.Context, dir, branch string) error {
+	remoteBranchExists, err := m.gitRemoteBranchExists(ctx, dir, branch)
+	if err != nil {
+		return err
+	}
+	if err := m.checkBranchNamespaceConflict(ctx, dir, branch); err != nil {
+		return err
+	}
+	return m.gitCheckoutBranch(ctx, dir, branch, remoteBranchExists)
+}
+
+// MaintainWarmPools brings every repo's warm pool up to date: ready
+// workspaces behind upstream are moved to the latest default branch, pools
+// below their configured size are filled, and ready workspaces beyond it are
+// disposed. The daemon calls it after each upstream fetch.
+func (m *Manager) MaintainWarmPools(ctx context.Context) {
+	repoURLs := make(map[string]bool)
+	for _, repo := range m.config.GetRepos() {
+		if m.config.GetWarmPoolSize(repo.Name) > 0 {
+			repoURLs[repo.URL] = true
+		}
+	}
+	for _, w := range m.state.GetWorkspaces() {
+		if w.Status == state.WorkspaceStatusReady {
+			repoURLs[w.Repo] = true
+		}
+	}
+	for repoURL := range repoURLs {
+		if ctx.Err() != nil {
+			return
+		}
+		m.maintainWarmPool(ctx, repoURL, true)
+	}
+}
+
+// warmPoolSize returns the configured pool size for repoURL. Pools are only
+// kept for git repos.
+func (m *Manager) warmPoolSize(repoURL string) int {
+	repo, found := m.findRepoByURL(repoURL)
+	if !found || !IsGitVCS(repo.VCS) || isLocalRepoURL(repoURL) {
+		return 0
+	}
+	return m.config.GetWarmPoolSize(repo.Name)
+}
+
+// maintainWarmPool fills the repo's pool to its configured size, one ready
+// workspace at a time and not while over the disk quota, or disposes ready
+// workspaces beyond it. With refresh set it first moves ready workspaces to
+// the latest default branch. Only one maintainWarmPool runs per repo at a
+// time; a call while one runs is a no-op.
+func (m *Manager) maintainWarmPool(ctx context.Context, repoURL string, refresh bool) {
+	if !m.warmPool.begin(repoURL) {
+		return
+	}
+	defer m.warmPool.end(repoURL)
+
+	size := m.warmPoolSize(repoURL)
+	if refresh && size > 0 {
+		m.refreshReady(ctx, repoURL)
+	}
+	lock := m.repoLock(repoURL)
+	for ctx.Err() == nil {
+		lock.Lock()
+		ready := m.readyWorkspaces(repoURL)
+		if len(ready) > size {
+			extra := ready[len(ready)-1]
+			m.logger.Info("warm pool: disposing ready workspace beyond pool size", "id", extra.ID, "size", size)
+			err := m.dispose(ctx, extra.ID, true, true)
+			lock.Unlock()
+			if err != nil {
+				m.logger.Warn("warm pool: failed to dispose ready workspace", "id", extra.ID, "err", err)
+				return
+			}
+			continue
+		}
+		if len(ready) == size || m.warmPool.backingOff(repoURL) || m.overDiskQuota() {
+			lock.Unlock()
+			return
+		}
+		w, err := m.createReady(ctx, repoURL)
+		lock.Unlock()
+		if err != nil {
+			m.warmPool.fail(repoURL)
+			m.logger.Warn("warm pool: failed to create ready workspace", "repo", repoURL, "err", err)
+			return
+		}
+		if !m.finishReady(ctx, w) {
+			return
+		}
+	}
+}
+
+// createReady creates a workspace for the pool, detached at the default
+// branch. It is marked ready and busy: finishReady runs its setup and
+// releases it. The caller holds the repo lock.
+func (m *Manager) createReady(ctx context.Context, repoURL string) (*state.Workspace, error) {
+	w, err := m.create(ctx, repoURL, readyBranch, "")
+	if err != nil {
+		return nil, err
+	}
+	if m.gitWatcher != nil {
+		m.gitWatcher.RemoveWorkspace(w.ID)
+	}
+	if err := m.detachReady(ctx, w); err != nil {
+		if disposeErr := m.dispose(ctx, w.ID, true, true); disposeErr != nil {
+			m.logger.Warn("warm pool: failed to dispose workspace", "id", w.ID, "err", disposeErr)
+		}
+		return nil, err
+	}
+	m.warmPool.setBusy(w.ID, true)
+	w.Branch = ""
+	w.Status = state.WorkspaceStatusReady
+	if err := m.state.UpdateWorkspace(*w); err != nil {
+		m.warmPool.setBusy(w.ID, false)
+		return nil, fmt.Errorf("failed to update workspace: %w", err)
+	}
+	m.state.Save()
+	m.logger.Info("warm pool: created ready workspace", "id", w.ID, "repo", repoURL)
+	return w, nil
+}
+
+// detachReady checks out the default branch detached in a pool workspace
+// and deletes the branch it was on, so any spawn can check that branch out.
+func (m *Manager) detachReady(ctx context.Context, w *state.Workspace) error {
+	defaultBranch, err := m.GetDefaultBranch(ctx, w.Repo)
+	if err != nil {
+		return err
+	}
+	if _, err := m.runGit(ctx, w.ID, RefreshTriggerExplicit, w.Path, "checkout", "--detach", "origin/"+defaultBranch); err != nil {
+		return fmt.Errorf("git checkout --detach failed: %w", err)
+	}
+	if w.Branch != "" {
+		if _, err := m.runGit(ctx, w.ID, RefreshTriggerExplicit, w.Path, "branch", "-D", w.Branch); err != nil {
+			m.logger.Debug("warm pool: failed to delete placeholder branch", "branch", w.Branch, "err", err)
+		}
+	}
+	return nil
+}
+
+// finishReady runs setup on a busy ready workspace and releases it to the
+// pool. A workspace whose setup fails is disposed and the repo's pool backs
+// off. It returns whether the workspace made it into the pool.
+func (m *Manager) finishReady(ctx context.Context, w *state.Workspace) bool {
+	defer m.warmPool.setBusy(w.ID, false)
+	if err := m.setupWorkspace(ctx, w); err != nil {
+		m.warmPool.fail(w.Repo)
+		m.logger.Warn("warm pool: setup failed, disposing ready workspace", "id", w.ID, "err", err)
+		if disposeErr := m.dispose(ctx, w.ID, true, true); disposeErr != nil {
+			m.logger.Warn("warm pool: failed to dispose workspace", "id", w.ID, "err", disposeErr)
+		}
+		return false
+	}
+	if m.broadcastFn != nil {
+		m.broadcastFn()
+	}
+	return true
+}
+
+// refreshReady moves the repo's ready workspaces that are behind upstream to
+// the latest default branch, re-running setup if that changed its cache key
+// files. Ready workspaces whose setup a daemon restart cut short are reset
+// and set up again even when up to date. Upstream is read from the origin query repo, which the daemon has
+// just fetched, so up-to-date workspaces cost one rev-parse.
+func (m *Manager) refreshReady(ctx context.Context, repoURL string) {
+	queryRepoPath := m.getQueryRepoPath(repoURL)
+	if queryRepoPath == "" {
+		return
+	}
+	defaultBranch, err := m.GetDefaultBranch(ctx, repoURL)
+	if err != nil {
+		return
+	}
+	out, err := m.runGit(ctx, "", RefreshTriggerPoller, queryRepoPath, "rev-parse", "origin/"+defaultBranch)
+	if err != nil {
+		return
+	}
+	upstream := strings.TrimSpace(string(out))
+
+	lock := m.repoLock(repoURL)
+	for _, w := range m.readyWorkspaces(repoURL) {
+		if ctx.Err() != nil {
+			return
+		}
+		head, err := m.runGit(ctx, w.ID, RefreshTriggerPoller, w.Path, "rev-parse", "HEAD")
+		if err == nil && strings.TrimSpace(string(head)) == upstream && !setupInterrupted(w) {
+			continue
+		}
+		// Re-check under the repo lock: a spawn may have claimed it.
+		lock.Lock()
+		fresh, found := m.state.GetWorkspace(w.ID)
+		if !found || fresh.Status != state.WorkspaceStatusReady || m.warmPool.isBusy(w.ID) {
+			lock.Unlock()
+			continue
+		}
+		m.warmPool.setBusy(w.ID, true)
+		lock.Unlock()
+
+		m.logger.Info("warm pool: refreshing ready workspace", "id", w.ID, "upstream", upstream)
+		if err := m.resetReady(ctx, fresh.Path, defaultBranch); err != nil {
+			m.warmPool.setBusy(w.ID, false)
+			m.warmPool.fail(repoURL)
+			m.logger.Warn("warm pool: refresh failed, disposing ready workspace", "id", w.ID, "err", err)
+			if disposeErr := m.dispose(ctx, w.ID, true, true); disposeErr != nil {
+				m.logger.Warn("warm pool: failed to dispose workspace", "id", w.ID, "err", disposeErr)
+			}
+			continue
+		}
+		m.finishReady(ctx, &fresh)
+	}
+}
+
+// setupInterrupted reports whether a ready workspace's last setup run failed,
+// which for a ready workspace means a daemon restart cut it short: ready
+// workspaces whose setup fails outright are disposed. It is not handed out
+// until refreshReady sets it up again.
+func setupInterrupted(w state.Workspace) bool {
+	return w.Status == state.WorkspaceStatusReady && w.Setup != nil && w.Setup.Status == SetupFailed
+}
+
+// resetReady fetches in a ready workspace and checks out the latest default
+// branch, detached, discarding anything setup left behind in tracked files.
+func (m *Manager) resetReady(ctx context.Context, dir, defaultBranch string) error {
+	if err := m.gitFetch(ctx, dir); err != nil {
+		return err
+	}
+	if err := m.gitCheckoutDot(ctx, dir); err != nil {
+		return fmt.Errorf("git checkout -- . failed: %w", err)
+	}
+	if _, err := m.runGit(ctx, "", RefreshTriggerExplicit, dir, "checkout", "--detach", "origin/"+defaultBranch); err != nil {
+		return fmt.Errorf("git checkout --detach failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// setupWarmPoolTest returns a manager for repo "test" with a pool of size
+// and a setup command that counts its runs in the returned file.
+func setupWarmPoolTest(t *testing.T, size int) (*Manager, string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath, nil)
+	repoDir := gitTestWorkTree(t)
+	counter := filepath.Join(t.TempDir(), "setup-runs")
+
+	cfg := &config.Config{}
+	cfg.WorkspacePath = t.TempDir()
+	cfg.WorktreeBasePath = t.TempDir()
+	cfg.Repos = []config.Repo{testRepoWithBarePath(t, "test", repoDir)}
+	cfg.WarmPool = &config.WarmPoolConfig{Repos: map[string]int{"test": size}}
+	cfg.Setup = &config.SetupConfig{Repos: map[string]config.RepoSetup{"test": {
+		Commands: []config.SetupCommand{{Name: "install", Command: config.ShellCommand{"sh", "-c", "echo run >> " + counter}}},
+	}}}
+	return New(cfg, st, statePath, testLogger()), repoDir, counter
+}
+
+// waitWarmPool waits for background pool maintenance of repoURL to finish
+// and returns the ready workspaces.
+func waitWarmPool(t *testing.T, m *Manager, repoURL string, want int) []state.Workspace {
+	t.Helper()
+	for deadline := time.Now().Add(30 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		m.warmPool.mu.Lock()
+		maintaining := m.warmPool.maintaining[repoURL] || len(m.warmPool.busy) > 0
+		m.warmPool.mu.Unlock()
+		ready := m.readyWorkspaces(repoURL)
+		if !maintaining && len(ready) == want {
+			return ready
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("warm pool has %d ready workspaces (maintaining=%v), want %d", len(ready), maintaining, want)
+		}
+	}
+}
+
+func setupRuns(t *testing.T, counter string) int {
+	t.Helper()
+	data, _ := os.ReadFile(counter)
+	return strings.Count(string(data), "run")
+}
+
+func TestWarmPool_FillClaimRefill(t *testing.T) {
+	t.Parallel()
+	m, repoDir, counter := setupWarmPoolTest(t, 2)
+	ctx := context.Background()
+	var mu sync.Mutex
+	var events []string
+	m.SetLifecycleFn(func(event string, w state.Workspace) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event+" "+w.ID)
+	})
+
+	m.MaintainWarmPools(ctx)
+	ready := waitWarmPool(t, m, repoDir, 2)
+	mainHead := strings.TrimSpace(runGitOut(t, repoDir, "rev-parse", "main"))
+	for _, w := range ready {
+		if w.Branch != "" || w.Setup == nil || w.Setup.Status != SetupPassed {
+			t.Errorf("ready workspace %s branch=%q setup=%+v, want detached with setup passed", w.ID, w.Branch, w.Setup)
+		}
+		if head := strings.TrimSpace(runGitOut(t, w.Path, "rev-parse", "HEAD")); head != mainHead {
+			t.Errorf("ready workspace %s HEAD = %s, want main %s", w.ID, head, mainHead)
+		}
+	}
+	if got := setupRuns(t, counter); got != 2 {
+		t.Errorf("setup ran %d times filling the pool, want 2", got)
+	}
+	mu.Lock()
+	if len(events) != 0 {
+		t.Errorf("lifecycle events while filling the pool: %v", events)
+	}
+	mu.Unlock()
+
+	w, err := m.GetOrCreate(ctx, repoDir, "feature-x")
+	if err != nil {
+		t.Fatalf("GetOrCreate() error: %v", err)
+	}
+	if w.ID != ready[0].ID && w.ID != ready[1].ID {
+		t.Errorf("GetOrCreate() = %s, want one of the ready workspaces", w.ID)
+	}
+	if w.Status != state.WorkspaceStatusRunning || w.Branch != "feature-x" {
+		t.Errorf("claimed workspace status=%q branch=%q, want running on feature-x", w.Status, w.Branch)
+	}
+	if branch := strings.TrimSpace(runGitOut(t, w.Path, "rev-parse", "--abbrev-ref", "HEAD")); branch != "feature-x" {
+		t.Errorf("claimed workspace checkout = %s, want feature-x", branch)
+	}
+	mu.Lock()
+	if len(events) != 1 || events[0] != "created "+w.ID {
+		t.Errorf("lifecycle events = %v, want created %s", events, w.ID)
+	}
+	mu.Unlock()
+
+	// The claim refills the pool in the background.
+	refilled := waitWarmPool(t, m, repoDir, 2)
+	for _, r := range refilled {
+		if r.ID == w.ID {
+			t.Errorf("claimed workspace %s still in the pool", w.ID)
+		}
+	}
+	if got := setupRuns(t, counter); got != 3 {
+		t.Errorf("setup ran %d times after claim and refill, want 3 (claim reuses the pool's run)", got)
+	}
+}
+
+func TestWarmPool_RefreshAndShrink(t *testing.T) {
+	t.Parallel()
+	m, repoDir, _ := setupWarmPoolTest(t, 1)
+	ctx := context.Background()
+
+	m.MaintainWarmPools(ctx)
+	ready := waitWarmPool(t, m, repoDir, 1)
+
+	// Upstream moves: the next maintenance moves the ready workspace to it.
+	writeFile(t, repoDir, "upstream.txt", "new")
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "upstream change")
+	mainHead := strings.TrimSpace(runGitOut(t, repoDir, "rev-parse", "main"))
+	m.FetchOriginQueries(ctx)
+	m.MaintainWarmPools(ctx)
+	refreshed := waitWarmPool(t, m, repoDir, 1)
+	if refreshed[0].ID != ready[0].ID {
+		t.Errorf("refresh replaced workspace %s with %s, want it updated in place", ready[0].ID, refreshed[0].ID)
+	}
+	if head := strings.TrimSpace(runGitOut(t, refreshed[0].Path, "rev-parse", "HEAD")); head != mainHead {
+		t.Errorf("ready workspace HEAD = %s after refresh, want %s", head, mainHead)
+	}
+
+	m.config.WarmPool.Repos["test"] = 0
+	m.MaintainWarmPools(ctx)
+	waitWarmPool(t, m, repoDir, 0)
+	if _, err := os.Stat(ready[0].Path); !os.IsNotExist(err) {
+		t.Errorf("ready workspace beyond pool size not removed from disk: %v", err)
+	}
+}
+
+func TestWarmPool_RerunsSetupCutShortByRestart(t *testing.T) {
+	t.Parallel()
+	m, repoDir, counter := setupWarmPoolTest(t, 1)
+	ctx := context.Background()
+
+	m.MaintainWarmPools(ctx)
+	ready := waitWarmPool(t, m, repoDir, 1)
+
+	// The daemon restarts while the ready workspace's setup runs.
+	w := ready[0]
+	w.Setup.Status = SetupRunning
+	if err := m.state.UpdateWorkspace(w); err != nil {
+		t.Fatal(err)
+	}
+	m = New(m.config, m.state, "", testLogger())
+	recovered, _ := m.state.GetWorkspace(w.ID)
+	if recovered.Status != state.WorkspaceStatusReady {
+		t.Fatalf("ready workspace status = %q after restart, want ready", recovered.Status)
+	}
+	if got, ok, err := m.claimReady(ctx, repoDir, "feature-x"); err != nil || ok {
+		t.Fatalf("claimReady() = %v, %v, %v; want the unfinished workspace skipped", got, ok, err)
+	}
+
+	m.MaintainWarmPools(ctx)
+	refreshed := waitWarmPool(t, m, repoDir, 1)
+	if refreshed[0].ID != w.ID || refreshed[0].Setup == nil || refreshed[0].Setup.Status != SetupPassed {
+		t.Errorf("ready workspace %s setup=%+v after maintenance, want %s set up again", refreshed[0].ID, refreshed[0].Setup, w.ID)
+	}
+	if got := setupRuns(t, counter); got != 2 {
+		t.Errorf("setup ran %d times, want 2", got)
+	}
+}
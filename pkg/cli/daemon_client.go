@@ -381,6 +381,12 @@ type Session struct {
 	AttachCmd    string `json:"attach_cmd"`
 	TmuxSocket   string `json:"tmux_socket,omitempty"`
 	TmuxSession  string `json:"tmux_session,omitempty"`
+	Backend      string `json:"backend,omitempty"`
+	// Status is the lifecycle status, e.g. "queued" while concurrency limits
+	// hold the spawn back.
+	Status        string `json:"status,omitempty"`
+	QueuePosition int    `json:"queue_position,omitempty"`
+	QueueETA      string `json:"queue_eta,omitempty"`
 }
 
 // WorkspaceWithSessions represents a workspace with its sessions.
@@ -411,14 +417,25 @@ type SpawnRequest struct {
 	RemoteProfileID string         `json:"remote_profile_id,omitempty"`
 	RemoteFlavor    string         `json:"remote_flavor,omitempty"`
 	NewBranch       string         `json:"new_branch,omitempty"`
+	Backend         string         `json:"backend,omitempty"`
+	// Tournament makes a multi-target spawn a best-of-N tournament.
+	Tournament *TournamentOptions `json:"tournament,omitempty"`
+}
+
+// TournamentOptions configures how tournament candidates are compared.
+type TournamentOptions struct {
+	TestCommand []string `json:"test_command,omitempty"`
+	JudgeTarget string   `json:"judge_target,omitempty"`
+	Criteria    string   `json:"criteria,omitempty"`
 }
 
 // SpawnResult represents the result of a spawn operation.
 type SpawnResult struct {
-	SessionID   string `json:"session_id,omitempty"`
-	WorkspaceID string `json:"workspace_id,omitempty"`
-	Target      string `json:"target"`
-	Error       string `json:"error,omitempty"`
+	SessionID    string `json:"session_id,omitempty"`
+	WorkspaceID  string `json:"workspace_id,omitempty"`
+	Target       string `json:"target"`
+	TournamentID string `json:"tournament_id,omitempty"`
+	Error        string `json:"error,omitempty"`
 }
 
 // ScanResult represents the result of a workspace scan.